@@ -1,22 +1,77 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
+	"net"
+	"net/http"
 	"os"
-	"time"
+	"strconv"
 
 	"github.com/bruno.lopes/calendar/backend/internal/api"
 	"github.com/bruno.lopes/calendar/backend/internal/database"
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/health"
 	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/sandbox"
+	"github.com/bruno.lopes/calendar/backend/internal/tenants"
 )
 
 func main() {
+	sandboxMode := flag.Bool("sandbox", false, "Use fake AI and canned holiday data (no external calls)")
+	flag.Parse()
+
+	dbPath := "./data/calendar.db"
+	if *sandboxMode {
+		// Keep sandbox data apart from the real database
+		sandbox.Enable()
+		dbPath = "./data/sandbox.db"
+		log.Println("Sandbox mode enabled: using fake AI responses and canned holiday data")
+	}
+
+	// Get port from environment or use default
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9090"
+	}
+
+	// Listen right away so the liveness probe answers during startup. The
+	// readiness probe and the API wait for the steps below.
+	checker := health.New()
+	migrated := checker.Pending("schema")
+	settingsLoaded := checker.Pending("settings")
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("Starting server on port %s", port)
+		serveErr <- http.ListenAndServe(":"+port, checker)
+	}()
+
+	// Host one database per organization instead of the single database
+	if dir := os.Getenv("TENANTS_DIR"); dir != "" {
+		migrated()
+		settingsLoaded()
+		serveTenants(checker, dir, serveErr)
+		return
+	}
+
 	// Initialize database
-	db, err := database.Initialize("./data/calendar.db")
+	db, err := database.Open(dbPath)
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		log.Fatalf("Failed to open database: %v", err)
 	}
 	defer db.Close()
+	checker.Add("database", db.PingContext)
+
+	if err := database.Migrate(db); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	migrated()
 
 	// Load Calendarific API key from settings
 	var calendarificKey string
@@ -26,37 +81,77 @@ func main() {
 		log.Println("Calendarific API key loaded from settings")
 	}
 
-	// Create holiday service for startup pre-fetch
-	holidayService := holidays.NewHolidayService(db)
-	holidayService.SetRetryConfig(5, 30*time.Second) // 5 retries, 30 second interval
+	server := api.NewServer(db)
+	if err := server.LoadSettings(context.Background()); err != nil {
+		log.Fatalf("Failed to load settings: %v", err)
+	}
+	settingsLoaded()
+	checker.SetApp(server.Handler())
+
+	// Serve the gRPC API next to the REST API, unless disabled
+	if grpcPort != "off" {
+		lis, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC: %v", err)
+		}
+		go func() {
+			log.Printf("Starting gRPC server on port %s", grpcPort)
+			serveErr <- server.GRPCServer().Serve(lis)
+		}()
+	}
+
+	// Use the API's holiday service for the startup pre-fetch so its status
+	// and retries are visible through the API
+	holidayService := server.HolidayService()
 
 	// Get work city from settings
 	var workCity string
 	db.QueryRow(`SELECT value FROM settings WHERE key = 'work_city'`).Scan(&workCity)
 
-	// Pre-fetch holidays for current year on startup (non-blocking)
-	currentYear := time.Now().Year()
-	log.Printf("Loading holidays for year %d...", currentYear)
-	
-	go func() {
-		_, err := holidayService.LoadHolidaysForYear(currentYear, workCity)
-		if err != nil {
-			log.Printf("Warning: Failed to pre-fetch holidays: %v (will retry in background)", err)
-		} else {
-			log.Printf("Holidays for %d loaded successfully", currentYear)
-		}
-	}()
+	// Pre-fetch holidays for the current year and the next ones on startup
+	// (non-blocking), since next year's vacations are planned in Q4
+	horizon := 2
+	var horizonSetting string
+	db.QueryRow(`SELECT value FROM settings WHERE key = 'holiday_prefetch_years'`).Scan(&horizonSetting)
+	if years, err := strconv.Atoi(horizonSetting); err == nil && years >= 0 && years <= 10 {
+		horizon = years
+	}
 
-	// Get port from environment or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// The current year is the user's, which differs from the server's on New Year's Eve
+	var timezone string
+	db.QueryRow(`SELECT value FROM settings WHERE key = 'timezone'`).Scan(&timezone)
+	currentYear := dates.Today(dates.Location(timezone)).Year()
+	log.Printf("Loading holidays for years %d-%d...", currentYear, currentYear+horizon)
+
+	go holidayService.Prefetch(currentYear, horizon, workCity)
+
+	if err := <-serveErr; err != nil {
+		log.Fatalf("Failed to start server: %v", err)
 	}
+}
 
-	// Start the server
-	server := api.NewServer(db)
-	log.Printf("Starting server on port %s", port)
-	if err := server.Run(":" + port); err != nil {
+// serveTenants serves the tenants with a database in dir. Each tenant's
+// schema is migrated and its holidays fetched when it is first used, and the
+// gRPC API, which has no tenant selection, is not served.
+func serveTenants(checker *health.Checker, dir string, serveErr chan error) {
+	names, err := tenants.List(dir)
+	if err != nil {
+		log.Fatalf("Failed to read tenants: %v", err)
+	}
+	log.Printf("Multi-tenant mode: %d tenants in %s", len(names), dir)
+
+	// Calendarific fetches are process-wide, so the key is the instance's
+	// rather than a tenant setting
+	if key := os.Getenv("CALENDARIFIC_API_KEY"); key != "" {
+		holidays.SetCalendarificAPIKey(key)
+	}
+
+	server := api.NewTenantServer(dir, os.Getenv("TENANT_DOMAIN"))
+	defer server.Close()
+	checker.Add("tenants", server.Ping)
+	checker.SetApp(server)
+
+	if err := <-serveErr; err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
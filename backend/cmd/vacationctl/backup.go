@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bruno.lopes/calendar/backend/internal/backup"
+	"github.com/bruno.lopes/calendar/backend/internal/database"
+)
+
+// requireDB fails for commands that need the database file when --db is not set
+func requireDB(cmd *cobra.Command) error {
+	if dbPath == "" {
+		return fmt.Errorf("%s works on the database file, pass --db", cmd.CommandPath())
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		return fmt.Errorf("database %s: %w", dbPath, err)
+	}
+	return nil
+}
+
+func backupCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "backup <file>",
+		Short: "Write a consistent copy of the database, safe while the server runs",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := requireDB(cmd); err != nil {
+				return err
+			}
+			target := args[0]
+			if _, err := os.Stat(target); err == nil {
+				return fmt.Errorf("%s already exists", target)
+			}
+
+			db, err := database.Open(dbPath)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			// The online backup API copies a consistent state while the
+			// server keeps writing
+			if err := backup.Snapshot(cmd.Context(), db, target); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Backed up %s to %s\n", dbPath, target)
+			return nil
+		},
+	}
+}
+
+func restoreCommand() *cobra.Command {
+	var confirmed bool
+	cmd := &cobra.Command{
+		Use:   "restore <file>",
+		Short: "Replace the database with a backup (stop the server first)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dbPath == "" {
+				return fmt.Errorf("%s works on the database file, pass --db", cmd.CommandPath())
+			}
+			if !confirmed {
+				return fmt.Errorf("restore replaces %s: stop the server and pass --yes", dbPath)
+			}
+			source := args[0]
+
+			if err := backup.Check(source); err != nil {
+				return err
+			}
+
+			// Copy next to the database and rename, so a failed copy never
+			// leaves a partial database behind
+			tmp := dbPath + ".restore"
+			if err := copyFile(source, tmp); err != nil {
+				os.Remove(tmp)
+				return err
+			}
+			// A write-ahead log left by a crash belongs to the old database
+			// and would be replayed over the restored one
+			for _, suffix := range []string{"-wal", "-shm"} {
+				if err := os.Remove(dbPath + suffix); err != nil && !os.IsNotExist(err) {
+					os.Remove(tmp)
+					return err
+				}
+			}
+			if err := os.Rename(tmp, dbPath); err != nil {
+				os.Remove(tmp)
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Restored %s from %s\n", dbPath, source)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&confirmed, "yes", false, "Confirm replacing the database")
+	return cmd
+}
+
+func copyFile(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/optimizer"
+)
+
+func benchmarkCommand() *cobra.Command {
+	var (
+		from, to int
+		budgets  string
+		workWeek string
+		asJSON   bool
+	)
+
+	currentYear := time.Now().Year()
+	cmd := &cobra.Command{
+		Use:   "benchmark",
+		Short: "Compare the optimizer strategies over a range of years and budgets",
+		Long:  "Run every optimizer strategy that doesn't call the AI for each year and budget on the calculated national holidays, without a server or database, and print the days off and efficiency of each strategy.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := parseYear(strconv.Itoa(from)); err != nil {
+				return err
+			}
+			if _, err := parseYear(strconv.Itoa(to)); err != nil {
+				return err
+			}
+			if from > to {
+				return fmt.Errorf("--from %d is after --to %d", from, to)
+			}
+
+			var budgetList []int
+			for _, field := range strings.Split(budgets, ",") {
+				budget, err := strconv.Atoi(strings.TrimSpace(field))
+				if err != nil || budget < 1 || budget > 365 {
+					return fmt.Errorf("invalid budget %q: must be between 1 and 365", field)
+				}
+				budgetList = append(budgetList, budget)
+			}
+
+			days := strings.Split(workWeek, ",")
+			for i, day := range days {
+				days[i] = strings.ToLower(strings.TrimSpace(day))
+				if !isWeekDay(days[i]) {
+					return fmt.Errorf("invalid work day %q", day)
+				}
+			}
+
+			results := optimizer.Benchmark(from, to, budgetList, days)
+			if asJSON {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(results)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "STRATEGY\tRUNS\tDAYS OFF\tAVG DAYS OFF\tVACATION DAYS\tUNUSED\tEFFICIENCY\tLONGEST\tTIME")
+			for _, r := range results {
+				fmt.Fprintf(w, "%s\t%d\t%d\t%.1f\t%d\t%d\t%.2f\t%d\t%s\n",
+					r.Strategy, r.Runs, r.TotalDaysOff, r.AverageDaysOff, r.VacationDaysUsed, r.UnusedDays, r.Efficiency, r.LongestBlock, r.Duration.Round(time.Millisecond))
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().IntVar(&from, "from", currentYear-10, "First year")
+	cmd.Flags().IntVar(&to, "to", currentYear+5, "Last year")
+	cmd.Flags().StringVar(&budgets, "budgets", "5,11,22,25", "Comma-separated vacation day budgets")
+	cmd.Flags().StringVar(&workWeek, "work-week", "monday,tuesday,wednesday,thursday,friday", "Comma-separated work days")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the results as JSON")
+	return cmd
+}
+
+// isWeekDay reports whether day names a day of the week
+func isWeekDay(day string) bool {
+	for _, d := range models.AllWeekDays {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/tenants"
+)
+
+// clientID identifies vacationctl to the edit locks, so a lock held in the
+// browser blocks its changes
+const clientID = "vacationctl"
+
+// apiClient calls the server's REST API
+type apiClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func newAPIClient() *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimSuffix(serverURL, "/") + "/api",
+		http:    &http.Client{Timeout: 2 * time.Minute}, // Smart optimization waits on the AI
+	}
+}
+
+// do sends a JSON request and decodes the JSON response into out, if given.
+// Error responses are returned with the server's message.
+func (c *apiClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Client-ID", clientID)
+	if tenant != "" {
+		req.Header.Set(tenants.Header, tenant)
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var problem models.Problem
+		json.NewDecoder(resp.Body).Decode(&problem)
+		if problem.Detail == "" {
+			problem.Detail = resp.Status
+		}
+		if problem.Code != "" {
+			return fmt.Errorf("%s %s: %s (%s)", method, path, problem.Detail, problem.Code)
+		}
+		return fmt.Errorf("%s %s: %s", method, path, problem.Detail)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bruno.lopes/calendar/backend/internal/ics"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+func exportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export calendar data",
+	}
+
+	var output string
+	icsCmd := &cobra.Command{
+		Use:   "ics <year>",
+		Short: "Export the vacation blocks of a year (manual and optimized) as an iCalendar file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := requireServer(cmd); err != nil {
+				return err
+			}
+			year, err := parseYear(args[0])
+			if err != nil {
+				return err
+			}
+
+			var calendar models.CalendarResponse
+			if err := newAPIClient().do(cmd.Context(), http.MethodGet, fmt.Sprintf("/calendar/%d", year), nil, &calendar); err != nil {
+				return err
+			}
+
+			var w io.Writer = cmd.OutOrStdout()
+			if output != "" && output != "-" {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			return ics.Write(w, fmt.Sprintf("Vacations %d", year), ics.BlockEvents(calendar.VacationBlocks))
+		},
+	}
+	icsCmd.Flags().StringVarP(&output, "output", "o", "", "Write to this file instead of stdout")
+
+	cmd.AddCommand(icsCmd)
+	return cmd
+}
@@ -0,0 +1,77 @@
+// Command vacationctl administers the vacation planner from scripts and
+// headless environments. Calendar commands talk to a running server; backup,
+// restore and settings can also work on the database file directly.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// Supported range for years, same as the API
+const (
+	minYear = 1970
+	maxYear = 2100
+)
+
+var (
+	serverURL   string
+	accessToken string
+	tenant      string
+	dbPath      string
+)
+
+func main() {
+	root := &cobra.Command{
+		Use:           "vacationctl",
+		Short:         "Administer the vacation planner",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	defaultServer := os.Getenv("VACATIONCTL_SERVER")
+	if defaultServer == "" {
+		defaultServer = "http://localhost:8080"
+	}
+	root.PersistentFlags().StringVar(&serverURL, "server", defaultServer, "Server URL (env VACATIONCTL_SERVER)")
+	root.PersistentFlags().StringVar(&accessToken, "token", os.Getenv("VACATIONCTL_TOKEN"), "Access token, once the server has any (env VACATIONCTL_TOKEN)")
+	root.PersistentFlags().StringVar(&tenant, "tenant", os.Getenv("VACATIONCTL_TENANT"), "Tenant to call on a multi-tenant server (env VACATIONCTL_TENANT)")
+	root.PersistentFlags().StringVar(&dbPath, "db", "", "Use this database file directly instead of the server (backup, restore and settings only)")
+
+	root.AddCommand(
+		vacationsCommand(),
+		optimizeCommand(),
+		benchmarkCommand(),
+		exportCommand(),
+		backupCommand(),
+		restoreCommand(),
+		settingsCommand(),
+		tokensCommand(),
+		tenantsCommand(),
+	)
+
+	if err := root.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// parseYear parses a year argument
+func parseYear(arg string) (int, error) {
+	year, err := strconv.Atoi(arg)
+	if err != nil || year < minYear || year > maxYear {
+		return 0, fmt.Errorf("invalid year %q: must be between %d and %d", arg, minYear, maxYear)
+	}
+	return year, nil
+}
+
+// requireServer fails for commands that need the server when --db is set
+func requireServer(cmd *cobra.Command) error {
+	if dbPath != "" {
+		return fmt.Errorf("%s needs the server, --db is not supported", cmd.CommandPath())
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bruno.lopes/calendar/backend/internal/database"
+	"github.com/bruno.lopes/calendar/backend/internal/settings"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+func settingsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "settings",
+		Short: "Read and change settings",
+	}
+
+	get := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print a setting",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			value, err := getSetting(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), value)
+			return nil
+		},
+	}
+
+	set := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change a setting",
+		Long:  "Change a setting. With --db, a running server picks up the new value within a minute.",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, value := args[0], args[1]
+			if err := setSetting(cmd.Context(), key, value); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Set %s\n", key)
+			return nil
+		},
+	}
+
+	cmd.AddCommand(get, set)
+	return cmd
+}
+
+func getSetting(ctx context.Context, key string) (string, error) {
+	if dbPath == "" {
+		var result map[string]string
+		if err := newAPIClient().do(ctx, http.MethodGet, "/settings/"+key, nil, &result); err != nil {
+			return "", err
+		}
+		return result[key], nil
+	}
+
+	st, closeDB, err := openStore()
+	if err != nil {
+		return "", err
+	}
+	defer closeDB()
+
+	value, err := st.Settings.Get(ctx, key)
+	if errors.Is(err, store.ErrNotFound) {
+		return "", fmt.Errorf("setting %s not found", key)
+	}
+	return value, err
+}
+
+func setSetting(ctx context.Context, key, value string) error {
+	if dbPath == "" {
+		return newAPIClient().do(ctx, http.MethodPut, "/settings/"+key, map[string]string{"value": value}, nil)
+	}
+
+	// Same checks as the API
+	if err := settings.Validate(key, value); err != nil {
+		return err
+	}
+	if def, _ := settings.Lookup(key); def.ReadOnly {
+		return fmt.Errorf("%s is managed by the server", key)
+	}
+
+	st, closeDB, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	return st.Settings.Set(ctx, key, value)
+}
+
+// openStore opens the database given with --db
+func openStore() (*store.Store, func(), error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, nil, fmt.Errorf("database %s: %w", dbPath, err)
+	}
+
+	db, err := database.Initialize(dbPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return store.New(db), func() { db.Close() }, nil
+}
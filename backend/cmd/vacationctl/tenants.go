@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bruno.lopes/calendar/backend/internal/database"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+	"github.com/bruno.lopes/calendar/backend/internal/tenants"
+)
+
+func tenantsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tenants",
+		Short: "Manage the organizations of a multi-tenant server",
+	}
+
+	defaultDir := os.Getenv("TENANTS_DIR")
+	if defaultDir == "" {
+		defaultDir = "./data/tenants"
+	}
+	var dir string
+	cmd.PersistentFlags().StringVar(&dir, "dir", defaultDir, "Tenant database directory (env TENANTS_DIR)")
+
+	create := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a tenant and print its admin token",
+		Long:  "Create a tenant's database with an admin access token, so its API is never open. The server picks it up on its first request.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token, err := createTenant(cmd.Context(), dir, args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), token)
+			return nil
+		},
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List the tenants",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names, err := tenants.List(dir)
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				fmt.Fprintln(cmd.OutOrStdout(), name)
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(create, list)
+	return cmd
+}
+
+func createTenant(ctx context.Context, dir, name string) (string, error) {
+	if err := tenants.ValidName(name); err != nil {
+		return "", err
+	}
+	path := tenants.Path(dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("tenant %s already exists", name)
+	}
+
+	db, err := database.Initialize(path)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	token, err := store.New(db).Tokens.Create(ctx, "Admin", models.RoleAdmin)
+	if err != nil {
+		return "", err
+	}
+	return token.Token, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+func tokensCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tokens",
+		Short: "Manage API access tokens",
+	}
+
+	create := &cobra.Command{
+		Use:   "create <name> <role>",
+		Short: "Create an access token and print it",
+		Long: "Create an access token with a role: admin, manager, member or viewer. The token is only shown once. " +
+			"With --db, this also recovers access when no admin token is left.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token, err := createToken(cmd.Context(), args[0], args[1])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), token.Token)
+			return nil
+		},
+	}
+
+	cmd.AddCommand(create)
+	return cmd
+}
+
+func createToken(ctx context.Context, name, role string) (models.AccessToken, error) {
+	var token models.AccessToken
+	if dbPath == "" {
+		err := newAPIClient().do(ctx, http.MethodPost, "/access/tokens", map[string]string{"name": name, "role": role}, &token)
+		return token, err
+	}
+
+	switch role {
+	case models.RoleAdmin, models.RoleManager, models.RoleMember, models.RoleViewer:
+	default:
+		return token, errors.New("role must be admin, manager, member or viewer")
+	}
+
+	st, closeDB, err := openStore()
+	if err != nil {
+		return token, err
+	}
+	defer closeDB()
+
+	return st.Tokens.Create(ctx, name, role)
+}
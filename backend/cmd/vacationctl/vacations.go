@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+func vacationsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vacations",
+		Short: "List, add and remove manual vacation days",
+	}
+
+	list := &cobra.Command{
+		Use:   "list <year>",
+		Short: "List the manual vacation days of a year",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := requireServer(cmd); err != nil {
+				return err
+			}
+			year, err := parseYear(args[0])
+			if err != nil {
+				return err
+			}
+
+			var vacations []models.VacationDay
+			if err := newAPIClient().do(cmd.Context(), http.MethodGet, fmt.Sprintf("/vacations/%d", year), nil, &vacations); err != nil {
+				return err
+			}
+			for _, v := range vacations {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", v.Date, v.Note)
+			}
+			return nil
+		},
+	}
+
+	var note string
+	add := &cobra.Command{
+		Use:   "add <year> <date>...",
+		Short: "Add vacation days (YYYY-MM-DD)",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := requireServer(cmd); err != nil {
+				return err
+			}
+			year, err := parseYear(args[0])
+			if err != nil {
+				return err
+			}
+
+			client := newAPIClient()
+			for _, date := range args[1:] {
+				body := map[string]string{"date": date, "note": note}
+				if err := client.do(cmd.Context(), http.MethodPost, fmt.Sprintf("/vacations/%d", year), body, nil); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Added %s\n", date)
+			}
+			return nil
+		},
+	}
+	add.Flags().StringVar(&note, "note", "", "Note stored with the days")
+
+	remove := &cobra.Command{
+		Use:   "remove <year> <date>...",
+		Short: "Remove vacation days (YYYY-MM-DD)",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := requireServer(cmd); err != nil {
+				return err
+			}
+			year, err := parseYear(args[0])
+			if err != nil {
+				return err
+			}
+
+			client := newAPIClient()
+			for _, date := range args[1:] {
+				if err := client.do(cmd.Context(), http.MethodDelete, fmt.Sprintf("/vacations/%d/%s", year, date), nil, nil); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", date)
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(list, add, remove)
+	return cmd
+}
+
+func optimizeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "optimize <year>",
+		Short: "Run the optimizer with the year's strategy and print the suggested blocks",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := requireServer(cmd); err != nil {
+				return err
+			}
+			year, err := parseYear(args[0])
+			if err != nil {
+				return err
+			}
+
+			var result struct {
+				Blocks []models.VacationBlock `json:"blocks"`
+			}
+			if err := newAPIClient().do(cmd.Context(), http.MethodPost, fmt.Sprintf("/calendar/%d/optimize", year), nil, &result); err != nil {
+				return err
+			}
+
+			for _, block := range result.Blocks {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s to %s\t%d days off\t%d vacation days\n",
+					block.StartDate, block.EndDate, block.TotalDays, block.VacationDaysUsed)
+			}
+			return nil
+		},
+	}
+}
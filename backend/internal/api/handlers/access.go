@@ -0,0 +1,409 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/auth"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// Access control is off until the first access token is created, so a
+// single-user install keeps working without credentials. Once a token
+// exists, every API request needs one: reads need the viewer role and
+// changes the member role, and RequireRole raises that for single routes.
+//
+// Clients presenting wrong tokens are throttled after each failure and
+// locked out after auth_max_failures of them, and the failures, lockouts and
+// token changes are written to the audit log.
+
+var (
+	// ErrUnauthenticated is returned when access control is on and the
+	// request has no valid token
+	ErrUnauthenticated = errors.New("a valid access token is required")
+	// ErrForbidden is returned when the token's role is too low
+	ErrForbidden = errors.New("forbidden")
+	// ErrTooManyAttempts is returned when a client is throttled or locked
+	// out after failed attempts; RetryAfter tells for how long
+	ErrTooManyAttempts = errors.New("too many failed attempts")
+)
+
+// auditRetention is how long the audit log is kept
+const auditRetention = 90 * 24 * time.Hour
+
+// attemptsError is ErrTooManyAttempts with the wait
+type attemptsError struct {
+	wait   time.Duration
+	locked bool
+}
+
+func (e attemptsError) Error() string {
+	if e.locked {
+		return fmt.Sprintf("%s, locked out for %s", ErrTooManyAttempts, e.wait.Round(time.Second))
+	}
+	return fmt.Sprintf("%s, retry in %s", ErrTooManyAttempts, e.wait.Round(time.Second))
+}
+
+func (e attemptsError) Unwrap() error { return ErrTooManyAttempts }
+
+// RetryAfter returns how long a client rejected with ErrTooManyAttempts
+// must wait, rounded up to whole seconds, and zero for other errors
+func RetryAfter(err error) time.Duration {
+	var attempts attemptsError
+	if !errors.As(err, &attempts) {
+		return 0
+	}
+	return attempts.wait.Truncate(time.Second) + time.Second
+}
+
+// isLockedOut reports whether err rejects a locked out client
+func isLockedOut(err error) bool {
+	var attempts attemptsError
+	return errors.As(err, &attempts) && attempts.locked
+}
+
+// roleLevels orders the roles; each role can do what the lower ones can
+var roleLevels = map[string]int{
+	models.RoleViewer:  1,
+	models.RoleMember:  2,
+	models.RoleManager: 3,
+	models.RoleAdmin:   4,
+}
+
+// accessTokenKey is the gin context key of the request's access token, set
+// only when access control is on
+const accessTokenKey = "access_token"
+
+// hasRole reports whether role includes the permissions of required
+func hasRole(role, required string) bool {
+	return roleLevels[role] >= roleLevels[required]
+}
+
+// authLimits returns the brute-force protection configured in the settings
+func (h *Handler) authLimits(ctx context.Context) auth.Limits {
+	s := h.loadSettings(ctx)
+	return auth.Limits{
+		MaxFailures: s.AuthMaxFailures,
+		Window:      time.Duration(s.AuthFailureWindowMinutes) * time.Minute,
+		Lockout:     time.Duration(s.AuthLockoutMinutes) * time.Minute,
+		Throttle:    time.Duration(s.AuthThrottleSeconds) * time.Second,
+	}
+}
+
+// Authorize returns the token matching secret when its role includes role.
+// enabled is false, and any request allowed, while no tokens exist. client
+// identifies the caller, usually by IP address, for the brute-force
+// protection: a throttled or locked out client gets ErrTooManyAttempts
+// without its token being looked at.
+func (h *Handler) Authorize(ctx context.Context, client, secret, role string) (token models.AccessToken, enabled bool, err error) {
+	if secret != "" {
+		limits := h.authLimits(ctx)
+		if wait, locked := h.guard.Check(client, limits); wait > 0 {
+			return token, true, attemptsError{wait: wait, locked: locked}
+		}
+
+		token, err = h.store.Tokens.Lookup(ctx, secret)
+		if err == nil {
+			h.guard.Succeed(client)
+			if !hasRole(token.Role, role) {
+				return token, true, fmt.Errorf("%w: needs the %s role", ErrForbidden, role)
+			}
+			return token, true, nil
+		}
+		if !errors.Is(err, store.ErrNotFound) {
+			return token, false, err
+		}
+	}
+
+	n, err := h.store.Tokens.Count(ctx, "")
+	if err != nil {
+		return token, false, err
+	}
+	if n == 0 {
+		return token, false, nil
+	}
+	if secret != "" {
+		h.authFailed(ctx, client)
+	}
+	return token, true, ErrUnauthenticated
+}
+
+// authFailed records a wrong token presented by client, locking it out
+// after too many
+func (h *Handler) authFailed(ctx context.Context, client string) {
+	limits := h.authLimits(ctx)
+	h.audit(ctx, models.AuthEvent{Event: models.AuthEventFailure, Client: client})
+	if !h.guard.Fail(client, limits) {
+		return
+	}
+
+	log.Printf("Locked out %s for %s after %d failed access token attempts", client, limits.Lockout, limits.MaxFailures)
+	h.audit(ctx, models.AuthEvent{
+		Event:  models.AuthEventLockout,
+		Client: client,
+		Detail: fmt.Sprintf("%d failed attempts, locked out for %s", limits.MaxFailures, limits.Lockout),
+	})
+}
+
+// audit writes an entry to the audit log. A failure to write it is logged
+// and does not fail the request.
+func (h *Handler) audit(ctx context.Context, event models.AuthEvent) {
+	if err := h.store.Audit.Add(ctx, event); err != nil {
+		log.Printf("Failed to write %s audit entry: %v", event.Event, err)
+	}
+}
+
+// Authenticate checks the bearer token of an API request when access control
+// is on: reads need the viewer role and everything else the member role
+func (h *Handler) Authenticate(c *gin.Context) {
+	role := models.RoleMember
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		role = models.RoleViewer
+	}
+
+	token, enabled, err := h.Authorize(c.Request.Context(), c.ClientIP(), bearerToken(c), role)
+	if err != nil {
+		abortAccess(c, err)
+		return
+	}
+	if enabled {
+		c.Set(accessTokenKey, token)
+	}
+
+	c.Next()
+}
+
+// RequireRole rejects requests whose token's role is below role. It runs
+// after Authenticate and lets every request through while access control
+// is off.
+func (h *Handler) RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token, ok := requestToken(c); ok && !hasRole(token.Role, role) {
+			abortAccess(c, fmt.Errorf("%w: needs the %s role", ErrForbidden, role))
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestToken returns the access token of a request, if access control is on
+func requestToken(c *gin.Context) (models.AccessToken, bool) {
+	value, ok := c.Get(accessTokenKey)
+	if !ok {
+		return models.AccessToken{}, false
+	}
+	token, ok := value.(models.AccessToken)
+	return token, ok
+}
+
+// isAdmin reports whether a request may see and change admin-only data
+func isAdmin(c *gin.Context) bool {
+	token, ok := requestToken(c)
+	return !ok || token.Role == models.RoleAdmin
+}
+
+// bearerToken returns the token of the Authorization header. Browsers can't
+// set headers on WebSocket handshakes, so those may pass it as ?access_token=.
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	if c.IsWebsocket() {
+		return c.Query("access_token")
+	}
+	return ""
+}
+
+func abortAccess(c *gin.Context, err error) {
+	if errors.Is(err, ErrUnauthenticated) {
+		c.Header("WWW-Authenticate", "Bearer")
+	}
+	if wait := RetryAfter(err); wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(wait/time.Second)))
+	}
+	abortError(c, err)
+}
+
+// GetAccess returns the caller's role, so the UI can hide what it may not do
+func (h *Handler) GetAccess(c *gin.Context) {
+	token, ok := requestToken(c)
+	if !ok {
+		c.JSON(http.StatusOK, models.AccessInfo{AccessControl: false, Role: models.RoleAdmin})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AccessInfo{AccessControl: true, Name: token.Name, Role: token.Role})
+}
+
+// GetAccessTokens lists the access tokens, without the tokens themselves
+func (h *Handler) GetAccessTokens(c *gin.Context) {
+	tokens, err := h.store.Tokens.List(c.Request.Context())
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// CreateAccessToken creates a token and returns it, the only time it is
+// shown. The first token turns access control on and must be an admin token.
+func (h *Handler) CreateAccessToken(c *gin.Context) {
+	var input struct {
+		Name string `json:"name" binding:"required"`
+		Role string `json:"role" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+	if _, ok := roleLevels[input.Role]; !ok {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "role must be admin, manager, member or viewer")
+		return
+	}
+
+	ctx := c.Request.Context()
+	var token models.AccessToken
+	err := h.store.InTx(ctx, func(tx *store.Store) error {
+		n, err := tx.Tokens.Count(ctx, "")
+		if err != nil {
+			return err
+		}
+		if n == 0 && input.Role != models.RoleAdmin {
+			return invalidInput(errors.New("the first token must be an admin token"))
+		}
+
+		token, err = tx.Tokens.Create(ctx, input.Name, input.Role)
+		if err != nil {
+			return err
+		}
+		return tx.Audit.Add(ctx, models.AuthEvent{
+			Event:     models.AuthEventTokenCreated,
+			Client:    c.ClientIP(),
+			TokenName: token.Name,
+			Detail:    "role " + token.Role,
+		})
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// DeleteAccessToken revokes a token. The last admin token can only be
+// deleted with the other tokens gone, which turns access control off.
+func (h *Handler) DeleteAccessToken(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid token id")
+		return
+	}
+
+	ctx := c.Request.Context()
+	var lastAdmin bool
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		token, err := tx.Tokens.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if token.Role == models.RoleAdmin {
+			admins, err := tx.Tokens.Count(ctx, models.RoleAdmin)
+			if err != nil {
+				return err
+			}
+			total, err := tx.Tokens.Count(ctx, "")
+			if err != nil {
+				return err
+			}
+			if admins == 1 && total > 1 {
+				lastAdmin = true
+				return nil
+			}
+		}
+
+		if _, err := tx.Tokens.Delete(ctx, id); err != nil {
+			return err
+		}
+		return tx.Audit.Add(ctx, models.AuthEvent{
+			Event:     models.AuthEventTokenDeleted,
+			Client:    c.ClientIP(),
+			TokenName: token.Name,
+			Detail:    "role " + token.Role,
+		})
+	})
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Token not found")
+		return
+	}
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+	if lastAdmin {
+		problem(c, http.StatusConflict, models.CodeConflict, "Cannot delete the last admin token while other tokens exist")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token deleted"})
+}
+
+// GetAuditLog returns the latest audit log entries, newest first
+func (h *Handler) GetAuditLog(c *gin.Context) {
+	limit := 100
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 1000 {
+			problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "limit must be between 1 and 1000")
+			return
+		}
+		limit = n
+	}
+
+	events, err := h.store.Audit.List(c.Request.Context(), limit)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// GetLockouts lists the clients locked out after failed attempts
+func (h *Handler) GetLockouts(c *gin.Context) {
+	lockouts := h.guard.Lockouts()
+	sort.Slice(lockouts, func(i, j int) bool { return lockouts[i].Until.Before(lockouts[j].Until) })
+	c.JSON(http.StatusOK, lockouts)
+}
+
+// DeleteLockout lets a client try again right away, forgetting its failed
+// attempts
+func (h *Handler) DeleteLockout(c *gin.Context) {
+	client := c.Param("client")
+	if !h.guard.Unlock(client) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "No failed attempts recorded for this client")
+		return
+	}
+
+	var by string
+	if token, ok := requestToken(c); ok {
+		by = "by " + token.Name
+	}
+	h.audit(c.Request.Context(), models.AuthEvent{Event: models.AuthEventUnlock, Client: client, Detail: by})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Client unlocked"})
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// aiValidateTimeout bounds the test call of ValidateAI
+const aiValidateTimeout = 30 * time.Second
+
+// rateLimitPrefix starts the quota headers of OpenAI and GitHub Models
+const rateLimitPrefix = "x-ratelimit-"
+
+// ValidateAI makes a minimal chat completion with the configured provider,
+// key and model, and reports whether it worked, how long it took and the
+// provider's rate limit headers. A rejected key or unknown model is a
+// result, not an error: the response is 200 with valid false.
+func (h *Handler) ValidateAI(c *gin.Context) {
+	settings := h.getAISettings(c.Request.Context())
+	if !settings.Configured() {
+		problem(c, http.StatusBadRequest, models.CodeAIUnconfigured, "API key not configured")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), aiValidateTimeout)
+	defer cancel()
+
+	result := models.AIValidation{Provider: settings.Provider, Model: settings.Model}
+	start := time.Now()
+	resp, err := h.newAIClient(settings).CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:    settings.Model,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Reply with OK."}},
+	})
+	result.LatencyMS = time.Since(start).Milliseconds()
+
+	if err != nil {
+		result.Error = err.Error()
+		result.Status = providerStatus(err)
+	} else {
+		result.Valid = true
+		result.Quota = quotaHeaders(resp.Header())
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// providerStatus returns the HTTP status of a failed provider call, or zero
+// when the provider wasn't reached
+func providerStatus(err error) int {
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		return reqErr.HTTPStatusCode
+	}
+	return 0
+}
+
+// quotaHeaders returns the rate limit headers of a provider response keyed
+// without their prefix, such as remaining-requests
+func quotaHeaders(header http.Header) map[string]string {
+	quota := make(map[string]string)
+	for name, values := range header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, rateLimitPrefix) && len(values) > 0 {
+			quota[strings.TrimPrefix(lower, rateLimitPrefix)] = values[0]
+		}
+	}
+	if len(quota) == 0 {
+		return nil
+	}
+	return quota
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/backup"
+	"github.com/bruno.lopes/calendar/backend/internal/database"
+	"github.com/bruno.lopes/calendar/backend/internal/events"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// GetBackups lists the stored backups of the database, newest first
+func (h *Handler) GetBackups(c *gin.Context) {
+	ctx := c.Request.Context()
+	target, prefix, err := h.backupTarget(ctx)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	backups, err := target.List(ctx, prefix)
+	if err != nil {
+		problem(c, http.StatusBadGateway, models.CodeUpstreamError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, backups)
+}
+
+// CreateBackup backs up the database now, instead of waiting for the
+// scheduled backup, and applies the retention settings
+func (h *Handler) CreateBackup(c *gin.Context) {
+	created, pruned, err := h.runBackup(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"backup": created, "pruned": pruned})
+}
+
+// RestoreBackup replaces the database with a stored backup. The current
+// database is backed up first, so the restore can be undone by restoring
+// that backup.
+func (h *Handler) RestoreBackup(c *gin.Context) {
+	name := c.Param("name")
+	if err := backup.ValidName(name); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	target, prefix, err := h.backupTarget(ctx)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	// Tenants may share a backup directory; another database's backups are
+	// not this one's to restore
+	if !backup.HasPrefix(name, prefix) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Backup not found")
+		return
+	}
+
+	tmp, err := os.MkdirTemp("", "vacation-restore-*")
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+	defer os.RemoveAll(tmp)
+
+	file := filepath.Join(tmp, name)
+	err = target.Get(ctx, name, file)
+	if errors.Is(err, backup.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Backup not found")
+		return
+	} else if err != nil {
+		problem(c, http.StatusBadGateway, models.CodeUpstreamError, err.Error())
+		return
+	}
+	if err := backup.Check(file); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	previous, _, err := h.runBackup(ctx)
+	if err != nil {
+		respondError(c, fmt.Errorf("failed to back up the current database: %w", err))
+		return
+	}
+
+	if err := backup.Restore(ctx, h.db, file); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+	// Backups of an older version lack the newer tables and settings
+	if err := database.Migrate(h.db); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+	h.store.Settings.Invalidate()
+	h.applyRetryPolicy(ctx)
+	h.applyBackupSchedule(ctx)
+	// Every year may have changed
+	h.events.Publish(events.BackupRestored, 0, gin.H{"name": name})
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Restored %s", name), "previous": previous})
+}
+
+// backupTarget returns the configured backup target and the prefix of this
+// database's backups, the tenant in multi-tenant mode
+func (h *Handler) backupTarget(ctx context.Context) (backup.Target, string, error) {
+	s := h.loadSettings(ctx)
+	target, err := backup.New(backup.Config{
+		Target:    s.BackupTarget,
+		Dir:       s.BackupDir,
+		Endpoint:  s.BackupS3Endpoint,
+		Region:    s.BackupS3Region,
+		Bucket:    s.BackupS3Bucket,
+		Prefix:    s.BackupS3Prefix,
+		AccessKey: s.BackupS3AccessKey,
+		SecretKey: s.BackupS3SecretKey,
+	})
+	if err != nil {
+		return nil, "", invalidInputCode(models.CodeNotConfigured, err)
+	}
+
+	prefix := backup.DefaultPrefix
+	if h.tenant != "" {
+		prefix = h.tenant
+	}
+	return target, prefix, nil
+}
+
+// runBackup snapshots the database to the backup target, then deletes the
+// backups beyond the retention settings. It returns the new backup and the
+// names deleted.
+func (h *Handler) runBackup(ctx context.Context) (backup.Object, []string, error) {
+	target, prefix, err := h.backupTarget(ctx)
+	if err != nil {
+		return backup.Object{}, nil, err
+	}
+
+	tmp, err := os.MkdirTemp("", "vacation-backup-*")
+	if err != nil {
+		return backup.Object{}, nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	now := time.Now()
+	name := backup.Name(prefix, now)
+	file := filepath.Join(tmp, name)
+	if err := backup.Snapshot(ctx, h.db, file); err != nil {
+		return backup.Object{}, nil, err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return backup.Object{}, nil, err
+	}
+	if err := target.Put(ctx, name, file); err != nil {
+		return backup.Object{}, nil, upstreamFailure(err)
+	}
+	created := backup.Object{Name: name, Size: info.Size(), CreatedAt: now.UTC()}
+
+	s := h.loadSettings(ctx)
+	pruned, err := backup.Prune(ctx, target, prefix, s.BackupKeepCount, time.Duration(s.BackupKeepDays)*24*time.Hour, now)
+	if err != nil {
+		return created, pruned, upstreamFailure(fmt.Errorf("backed up to %s, but pruning old backups failed: %w", name, err))
+	}
+	if pruned == nil {
+		pruned = []string{}
+	}
+	return created, pruned, nil
+}
+
+// backupJob backs up the database, when a backup target is configured
+func (h *Handler) backupJob(ctx context.Context) error {
+	s := h.loadSettings(ctx)
+	if s.BackupTarget == "" || s.BackupTarget == backup.TargetNone {
+		return nil
+	}
+
+	created, pruned, err := h.runBackup(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Backed up the database to %s (%d bytes), deleted %d old backups", created.Name, created.Size, len(pruned))
+	return nil
+}
+
+// applyBackupSchedule moves the backup job to the backup_schedule setting
+func (h *Handler) applyBackupSchedule(ctx context.Context) {
+	schedule := h.loadSettings(ctx).BackupSchedule
+	if err := h.scheduler.Reschedule(jobBackupDatabase, schedule); err != nil {
+		log.Printf("Failed to reschedule backups: %v", err)
+	}
+}
@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/ics"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// maxBlockLabelLength caps the name of a vacation block, in characters
+const maxBlockLabelLength = 100
+
+var blockColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// SetBlockLabel names and colors a vacation block. The label stays with the
+// block containing its first day, so adding days around the block keeps it.
+// An empty label and color remove it.
+func (h *Handler) SetBlockLabel(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	var input struct {
+		Label string `json:"label"`
+		Color string `json:"color"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+	label := strings.TrimSpace(input.Label)
+	if len([]rune(label)) > maxBlockLabelLength {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, fmt.Sprintf("label must be at most %d characters", maxBlockLabelLength))
+		return
+	}
+	if input.Color != "" && !blockColor.MatchString(input.Color) {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "color must be #rrggbb")
+		return
+	}
+
+	ctx := c.Request.Context()
+	block, err := h.findBlock(ctx, year, c.Param("block"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		if label == "" && input.Color == "" {
+			return tx.Blocks.DeleteLabels(ctx, year, block.StartDate, block.EndDate)
+		}
+		return tx.Blocks.SetLabel(ctx, models.BlockLabel{Year: year, Date: block.StartDate, Label: label, Color: strings.ToLower(input.Color)}, block.EndDate)
+	})
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	block.Label = label
+	block.Color = strings.ToLower(input.Color)
+	c.JSON(http.StatusOK, block)
+}
+
+// DeleteBlockLabel removes the name and color of a vacation block
+func (h *Handler) DeleteBlockLabel(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	ctx := c.Request.Context()
+	block, err := h.findBlock(ctx, year, c.Param("block"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := h.store.Blocks.DeleteLabels(ctx, year, block.StartDate, block.EndDate); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Block label removed"})
+}
+
+// DeclineOptimizedBlock turns down the suggested block named by
+// ?block=<blockId> and optimizes the year again without its vacation days.
+// Locked days of the block are kept.
+func (h *Handler) DeclineOptimizedBlock(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+	if c.Query("block") == "" {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "block is required")
+		return
+	}
+
+	ctx := c.Request.Context()
+	block, err := h.findBlock(ctx, year, c.Query("block"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	optimal, err := h.store.Vacations.ListOptimal(ctx, year)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+	locked, err := h.lockedDates(ctx, year)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+	declined := models.DeclinedBlock{Year: year, StartDate: block.StartDate, EndDate: block.EndDate, Dates: []string{}}
+	var lockedDays []string
+	for _, v := range optimal {
+		if !contains(block.Dates, v.Date) {
+			continue
+		}
+		if locked[v.Date] {
+			lockedDays = append(lockedDays, v.Date)
+			continue
+		}
+		declined.Dates = append(declined.Dates, v.Date)
+	}
+	if len(declined.Dates) == 0 {
+		if len(lockedDays) > 0 {
+			err := fmt.Errorf("%w: %s", ErrDateLocked, strings.Join(lockedDays, ", "))
+			respondError(c, err)
+			return
+		}
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "The block has no optimized days to decline")
+		return
+	}
+
+	declined.ID, err = h.store.Blocks.Decline(ctx, declined)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	blocks, results, err := h.Optimize(ctx, year)
+	if err != nil {
+		extensions := gin.H{"declined": declined}
+		if results != nil {
+			extensions["results"] = results
+		}
+		respondError(c, err, extensions)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"declined": declined,
+		"blocks":   blocks,
+		"message":  "Block declined and optimization complete",
+		"results":  results,
+	})
+}
+
+// GetDeclinedBlocks returns the declined blocks of a year
+func (h *Handler) GetDeclinedBlocks(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	declined, err := h.store.Blocks.Declined(c.Request.Context(), year)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, declined)
+}
+
+// DeleteDeclinedBlock lets the optimizer suggest a declined block's days again
+func (h *Handler) DeleteDeclinedBlock(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid declined block id")
+		return
+	}
+
+	err = h.store.Blocks.DeleteDeclined(c.Request.Context(), year, id)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Declined block not found")
+		return
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Declined block removed"})
+}
+
+// ClearDeclinedBlocks removes every declined block of a year
+func (h *Handler) ClearDeclinedBlocks(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	if err := h.store.Blocks.ClearDeclined(c.Request.Context(), year); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Declined blocks cleared"})
+}
+
+// declinedDates returns the vacation days of a year's declined blocks
+func (h *Handler) declinedDates(ctx context.Context, year int) ([]string, error) {
+	declined, err := h.store.Blocks.Declined(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+
+	var excluded []string
+	for _, d := range declined {
+		excluded = append(excluded, d.Dates...)
+	}
+	return excluded, nil
+}
+
+// GetBlockICS returns one vacation block as a calendar file with a single
+// all-day event, to forward to travel companions. The block is named by
+// its position in the calendar's vacation_blocks, 1 for the first, or by
+// its first day.
+func (h *Handler) GetBlockICS(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	// Gin params run to the next slash, so the extension is part of it
+	blockID, ok := strings.CutSuffix(c.Param("block"), ".ics")
+	if !ok {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Block calendars end in .ics")
+		return
+	}
+
+	block, err := h.findBlock(c.Request.Context(), year, blockID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	name := block.Label
+	if name == "" {
+		name = fmt.Sprintf("Vacation %s to %s", block.StartDate, block.EndDate)
+	}
+	var buf bytes.Buffer
+	if err := ics.Write(&buf, name, ics.BlockEvents([]models.VacationBlock{block})); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="vacation-%s.ics"`, block.StartDate))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+// findBlock returns a vacation block of a year by its 1-based position in
+// the calendar or by its first day
+func (h *Handler) findBlock(ctx context.Context, year int, id string) (models.VacationBlock, error) {
+	if err := checkYear(year); err != nil {
+		return models.VacationBlock{}, err
+	}
+
+	calendar, err := h.Calendar(ctx, year)
+	if err != nil {
+		return models.VacationBlock{}, err
+	}
+
+	if n, err := strconv.Atoi(id); err == nil {
+		if n >= 1 && n <= len(calendar.VacationBlocks) {
+			return calendar.VacationBlocks[n-1], nil
+		}
+	} else if _, err := dates.Parse(id); err == nil {
+		for _, block := range calendar.VacationBlocks {
+			if block.StartDate == id {
+				return block, nil
+			}
+		}
+	} else {
+		return models.VacationBlock{}, invalidInput(fmt.Errorf("invalid block %q, expected a number or a YYYY-MM-DD start date", id))
+	}
+
+	return models.VacationBlock{}, fmt.Errorf("no vacation block %s in %d: %w", id, year, store.ErrNotFound)
+}
+
+// labelBlocks gives each vacation block the first label dated within it
+func labelBlocks(blocks []models.VacationBlock, labels []models.BlockLabel) {
+	for i := range blocks {
+		for _, l := range labels {
+			if l.Date >= blocks[i].StartDate && l.Date <= blocks[i].EndDate {
+				blocks[i].Label = l.Label
+				blocks[i].Color = l.Color
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+const (
+	// maxCalendarYears caps the years of one multi-year calendar request
+	maxCalendarYears = 10
+	// maxRangeDays caps the days of one date range calendar request
+	maxRangeDays = 366
+)
+
+// GetCalendars returns the summaries and vacation blocks of the years from
+// ?from= to ?to=, inclusive, so the year switcher loads them in one request.
+// ?days=true adds each year's days.
+func (h *Handler) GetCalendars(c *gin.Context) {
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "from must be a year")
+		return
+	}
+	to := from
+	if c.Query("to") != "" {
+		if to, err = strconv.Atoi(c.Query("to")); err != nil {
+			problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "to must be a year")
+			return
+		}
+	}
+	for _, year := range []int{from, to} {
+		if err := checkYear(year); err != nil {
+			respondError(c, err)
+			return
+		}
+	}
+	if to < from {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "to must not be before from")
+		return
+	}
+	if to-from+1 > maxCalendarYears {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, fmt.Sprintf("at most %d years can be requested at once", maxCalendarYears))
+		return
+	}
+	withDays := c.Query("days") == "true"
+
+	ctx := c.Request.Context()
+	years := make([]models.YearCalendar, 0, to-from+1)
+	for year := from; year <= to; year++ {
+		calendar, err := h.Calendar(ctx, year)
+		if err != nil {
+			respondError(c, fmt.Errorf("%d: %w", year, err))
+			return
+		}
+
+		y := models.YearCalendar{
+			Year:           year,
+			Config:         calendar.Config,
+			Summary:        calendar.Summary,
+			VacationBlocks: calendar.VacationBlocks,
+		}
+		if y.VacationBlocks == nil {
+			y.VacationBlocks = []models.VacationBlock{}
+		}
+		if withDays {
+			y.Days = calendar.Days
+		}
+		years = append(years, y)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"from": from, "to": to, "years": years})
+}
+
+// GetCalendarRange returns the days from ?start= to ?end=, inclusive, with
+// the vacation blocks overlapping them. The window may cross into the next
+// year, such as December 20 to January 10; a block running over New Year's
+// Day is returned as one block.
+func (h *Handler) GetCalendarRange(c *gin.Context) {
+	start, err := dates.Parse(c.Query("start"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidDate, "start must be a YYYY-MM-DD date")
+		return
+	}
+	end, err := dates.Parse(c.Query("end"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidDate, "end must be a YYYY-MM-DD date")
+		return
+	}
+	if end.Before(start) {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "end must not be before start")
+		return
+	}
+	if end.Sub(start).Hours()/24 >= maxRangeDays {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, fmt.Sprintf("A range can span at most %d days", maxRangeDays))
+		return
+	}
+	for _, year := range []int{start.Year(), end.Year()} {
+		if err := checkYear(year); err != nil {
+			respondError(c, err)
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	from, to := dates.Format(start), dates.Format(end)
+	days := []models.CalendarDay{}
+	var blocks []models.VacationBlock
+	for year := start.Year(); year <= end.Year(); year++ {
+		calendar, err := h.Calendar(ctx, year)
+		if err != nil {
+			respondError(c, fmt.Errorf("%d: %w", year, err))
+			return
+		}
+		for _, day := range calendar.Days {
+			if day.Date >= from && day.Date <= to {
+				days = append(days, day)
+			}
+		}
+		blocks = append(blocks, calendar.VacationBlocks...)
+	}
+
+	overlapping := []models.VacationBlock{}
+	for _, block := range joinNewYearBlocks(blocks) {
+		if block.EndDate >= from && block.StartDate <= to {
+			overlapping = append(overlapping, block)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"start": from, "end": to, "days": days, "vacation_blocks": overlapping})
+}
+
+// joinNewYearBlocks sorts the blocks of consecutive years and joins a block
+// ending on December 31 with one starting on January 1
+func joinNewYearBlocks(blocks []models.VacationBlock) []models.VacationBlock {
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].StartDate < blocks[j].StartDate })
+
+	var joined []models.VacationBlock
+	for _, block := range blocks {
+		if n := len(joined); n > 0 {
+			last := &joined[n-1]
+			end, _ := dates.Parse(last.EndDate)
+			if dates.Format(end.AddDate(0, 0, 1)) == block.StartDate && strings.HasSuffix(block.StartDate, "-01-01") {
+				last.EndDate = block.EndDate
+				last.TotalDays += block.TotalDays
+				last.VacationDaysUsed += block.VacationDaysUsed
+				last.Dates = append(last.Dates, block.Dates...)
+				last.Holidays = append(last.Holidays, block.Holidays...)
+				last.Weekends = append(last.Weekends, block.Weekends...)
+				last.Efficiency = models.BlockEfficiency(last.TotalDays, last.VacationDaysUsed)
+				last.Source = joinBlockSources(last.Source, block.Source)
+				if last.TripID == 0 {
+					last.TripID = block.TripID
+				}
+				if last.Label == "" {
+					last.Label, last.Color = block.Label, block.Color
+				}
+				continue
+			}
+		}
+		joined = append(joined, block)
+	}
+	return joined
+}
@@ -3,8 +3,10 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -12,63 +14,234 @@ import (
 	"github.com/gin-gonic/gin"
 	openai "github.com/sashabaranov/go-openai"
 
-	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/events"
 	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/sandbox"
 )
 
-// GitHubModel represents a model from GitHub Models API
+// chatCompleter is the part of the OpenAI client used by the handlers
+type chatCompleter interface {
+	CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
+}
+
+// aiSettings holds the configured AI provider, API key and model, with the
+// models tried in order when it fails
+type aiSettings struct {
+	APIKey    string
+	Provider  string
+	Model     string
+	Fallbacks []aiSettings
+}
+
+// Configured reports whether the AI can be called
+func (s aiSettings) Configured() bool {
+	return s.APIKey != "" || sandbox.Enabled()
+}
+
+// getAISettings loads the AI settings, applying defaults
+func (h *Handler) getAISettings(ctx context.Context) aiSettings {
+	// Unset provider and model default to GitHub Models and gpt-4o-mini
+	stored := h.loadSettings(ctx)
+	settings := aiSettings{
+		APIKey:   stored.OpenAIAPIKey,
+		Provider: stored.AIProvider,
+		Model:    stored.AIModel,
+	}
+
+	settings.Model = publisherModel(settings.Provider, settings.Model)
+
+	// Fallbacks of the other provider use the fallback API key
+	for _, ref := range stored.AIFallbackModels {
+		fallback := aiSettings{APIKey: settings.APIKey, Provider: settings.Provider}
+		if ref.Provider != "" && ref.Provider != settings.Provider {
+			fallback.APIKey, fallback.Provider = stored.AIFallbackAPIKey, ref.Provider
+		}
+		fallback.Model = publisherModel(fallback.Provider, ref.Model)
+		settings.Fallbacks = append(settings.Fallbacks, fallback)
+	}
+
+	return settings
+}
+
+// publisherModel ensures a model has the publisher prefix the GitHub Models
+// API requires
+func publisherModel(provider, model string) string {
+	if provider == "github" && !strings.Contains(model, "/") {
+		return "openai/" + model
+	}
+	return model
+}
+
+// completeChat sends a chat completion to the configured model and, when it
+// fails, to each fallback model in turn. It returns the response and the
+// model that answered, or the last error once every model failed.
+func (h *Handler) completeChat(ctx context.Context, settings aiSettings, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, string, error) {
+	chain := append([]aiSettings{settings}, settings.Fallbacks...)
+
+	var err error
+	for _, candidate := range chain {
+		if candidate.APIKey == "" && !sandbox.Enabled() {
+			continue
+		}
+		request.Model = candidate.Model
+
+		var resp openai.ChatCompletionResponse
+		resp, err = h.newAIClient(candidate).CreateChatCompletion(ctx, request)
+		if err == nil && len(resp.Choices) == 0 {
+			err = errors.New("no response from AI")
+		}
+		if err == nil {
+			return resp, candidate.Provider + ":" + candidate.Model, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		log.Printf("AI model %s of %s failed: %v", candidate.Model, candidate.Provider, err)
+	}
+	if err == nil {
+		err = errors.New("API key not configured")
+	}
+	return openai.ChatCompletionResponse{}, "", err
+}
+
+// newAIClient creates a chat client for the configured provider.
+// In sandbox mode a deterministic fake is returned instead.
+func (h *Handler) newAIClient(settings aiSettings) chatCompleter {
+	if sandbox.Enabled() {
+		return sandbox.NewFakeAI()
+	}
+
+	switch settings.Provider {
+	case "openai":
+		return openai.NewClient(settings.APIKey)
+	default:
+		// GitHub Models API (new endpoint)
+		config := openai.DefaultConfig(settings.APIKey)
+		config.BaseURL = "https://models.github.ai/inference"
+		return openai.NewClientWithConfig(config)
+	}
+}
+
+// minContextTokens is the smallest context window that fits the calendar
+// context the chat and the smart optimizer send
+const minContextTokens = 8000
+
+// GitHubModel represents a model from the GitHub Models catalog
 type GitHubModel struct {
-	Name         string `json:"name"`
-	FriendlyName string `json:"friendly_name"`
-	Publisher    string `json:"publisher"`
-	Task         string `json:"task"`
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Publisher        string   `json:"publisher"`
+	RateLimitTier    string   `json:"rate_limit_tier"`
+	Capabilities     []string `json:"capabilities"`
+	InputModalities  []string `json:"supported_input_modalities"`
+	OutputModalities []string `json:"supported_output_modalities"`
+	Limits           struct {
+		MaxInputTokens  int `json:"max_input_tokens"`
+		MaxOutputTokens int `json:"max_output_tokens"`
+	} `json:"limits"`
+}
+
+// aiModel describes a catalog model and whether the AI features can use it
+func (m GitHubModel) aiModel() models.AIModel {
+	tools := contains(m.Capabilities, "tool-calling")
+	model := models.AIModel{
+		ID:              m.ID,
+		Name:            m.Name,
+		Publisher:       m.Publisher,
+		ContextLength:   m.Limits.MaxInputTokens,
+		MaxOutputTokens: m.Limits.MaxOutputTokens,
+		Tier:            m.RateLimitTier,
+		ToolCalling:     &tools,
+	}
+
+	switch {
+	case strings.Contains(strings.ToLower(m.Name), "embedding"):
+		model.Reason = "embedding model"
+	case !contains(m.OutputModalities, "text"):
+		model.Reason = "no text output"
+	case !contains(m.InputModalities, "text"):
+		model.Reason = "no text input"
+	case model.ContextLength > 0 && model.ContextLength < minContextTokens:
+		model.Reason = fmt.Sprintf("context of %d tokens is too small for the calendar", model.ContextLength)
+	}
+	model.Suitable = model.Reason == ""
+	return model
 }
 
-// GetAvailableModels fetches available models from GitHub Models Catalog API
+// openAIModel describes a model of the OpenAI model list, which has no
+// metadata: whether it can chat is told from its id
+func openAIModel(id string) models.AIModel {
+	model := models.AIModel{ID: id, Name: id, Publisher: "OpenAI"}
+
+	lower := strings.ToLower(id)
+	chat := strings.HasPrefix(lower, "gpt") || strings.HasPrefix(lower, "chatgpt") ||
+		strings.HasPrefix(lower, "o1") || strings.HasPrefix(lower, "o3") || strings.HasPrefix(lower, "o4")
+	if !chat {
+		model.Reason = "not a chat model"
+	}
+	for _, kind := range []string{"audio", "realtime", "transcribe", "tts", "image", "search", "instruct"} {
+		if strings.Contains(lower, kind) {
+			model.Reason = kind + " model"
+			break
+		}
+	}
+	model.Suitable = model.Reason == ""
+	return model
+}
+
+// suitableModels drops the models the chat and the optimizer can't use,
+// unless all are asked for
+func suitableModels(list []models.AIModel, all bool) []models.AIModel {
+	kept := []models.AIModel{}
+	for _, model := range list {
+		if all || model.Suitable {
+			kept = append(kept, model)
+		}
+	}
+	return kept
+}
+
+// GetAvailableModels lists the models of the configured provider that can
+// chat and optimize, with their context length, tier and tool support from
+// the provider's catalog. ?all=true keeps the unsuited ones, with the
+// reason.
 func (h *Handler) GetAvailableModels(c *gin.Context) {
-	// Get API key from settings
-	var apiKey string
-	err := h.db.QueryRow("SELECT value FROM settings WHERE key = 'openai_api_key'").Scan(&apiKey)
-	if err != nil || apiKey == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "API key not configured"})
+	all := c.Query("all") == "true"
+	if sandbox.Enabled() {
+		c.JSON(http.StatusOK, suitableModels(sandbox.Models(), all))
 		return
 	}
 
-	// Get AI provider
-	var aiProvider string
-	err = h.db.QueryRow("SELECT value FROM settings WHERE key = 'ai_provider'").Scan(&aiProvider)
-	if err != nil || aiProvider == "" {
-		aiProvider = "github"
+	// Get API key and provider from settings
+	settings := h.getAISettings(c.Request.Context())
+	if !settings.Configured() {
+		problem(c, http.StatusBadRequest, models.CodeAIUnconfigured, "API key not configured")
+		return
 	}
+	apiKey := settings.APIKey
+	aiProvider := settings.Provider
 
 	if aiProvider == "openai" {
 		// For OpenAI, fetch from OpenAI API
 		client := openai.NewClient(apiKey)
 		modelList, err := client.ListModels(context.Background())
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch models: " + err.Error()})
+			problem(c, http.StatusInternalServerError, models.CodeInternalError, "Failed to fetch models: "+err.Error())
 			return
 		}
 
-		var chatModels []map[string]string
+		var chatModels []models.AIModel
 		for _, model := range modelList.Models {
-			// Filter for chat models
-			if strings.Contains(model.ID, "gpt") || strings.Contains(model.ID, "o1") || strings.Contains(model.ID, "o3") {
-				chatModels = append(chatModels, map[string]string{
-					"id":        model.ID,
-					"name":      model.ID,
-					"publisher": "OpenAI",
-				})
-			}
+			chatModels = append(chatModels, openAIModel(model.ID))
 		}
-		c.JSON(http.StatusOK, chatModels)
+		c.JSON(http.StatusOK, suitableModels(chatModels, all))
 		return
 	}
 
 	// Fetch from GitHub Models Catalog API
 	req, err := http.NewRequest("GET", "https://models.github.ai/catalog/models", nil)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, "Failed to create request")
 		return
 	}
 
@@ -79,61 +252,35 @@ func (h *Handler) GetAvailableModels(c *gin.Context) {
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch models: " + err.Error()})
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, "Failed to fetch models: "+err.Error())
 		return
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response"})
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, "Failed to read response")
 		return
 	}
 
 	if resp.StatusCode != 200 {
-		c.JSON(resp.StatusCode, gin.H{"error": "GitHub API error: " + string(body)})
+		problem(c, resp.StatusCode, models.CodeUpstreamError, "GitHub API error: "+string(body))
 		return
 	}
 
 	// Parse the response
-	var modelsResponse []map[string]interface{}
-	if err := json.Unmarshal(body, &modelsResponse); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse models"})
+	var catalog []GitHubModel
+	if err := json.Unmarshal(body, &catalog); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, "Failed to parse models")
 		return
 	}
 
-	// Filter for chat-capable models and format response
-	var chatModels []map[string]string
-	for _, model := range modelsResponse {
-		// Get supported output modalities to filter for text generation models
-		outputModalities, _ := model["supported_output_modalities"].([]interface{})
-		hasTextOutput := false
-		for _, m := range outputModalities {
-			if m == "text" {
-				hasTextOutput = true
-				break
-			}
-		}
-
-		// Skip embedding-only models
-		name, _ := model["name"].(string)
-		if strings.Contains(strings.ToLower(name), "embedding") {
-			continue
-		}
-
-		if hasTextOutput {
-			id, _ := model["id"].(string)
-			publisher, _ := model["publisher"].(string)
-
-			chatModels = append(chatModels, map[string]string{
-				"id":        id,
-				"name":      name,
-				"publisher": publisher,
-			})
-		}
+	chatModels := make([]models.AIModel, 0, len(catalog))
+	for _, model := range catalog {
+		chatModels = append(chatModels, model.aiModel())
 	}
 
-	c.JSON(http.StatusOK, chatModels)
+	c.JSON(http.StatusOK, suitableModels(chatModels, all))
 }
 
 // Chat handles AI chat interactions
@@ -141,7 +288,7 @@ func (h *Handler) Chat(c *gin.Context) {
 	yearStr := c.Param("year")
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 
@@ -150,64 +297,26 @@ func (h *Handler) Chat(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
 		return
 	}
 
-	// Get API key and provider from settings
-	var apiKey string
-	err = h.db.QueryRow("SELECT value FROM settings WHERE key = 'openai_api_key'").Scan(&apiKey)
-	if err != nil || apiKey == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "API key not configured. Please set it in settings."})
+	// Get API key, provider and model from settings
+	ctx := c.Request.Context()
+	settings := h.getAISettings(ctx)
+	if !settings.Configured() {
+		problem(c, http.StatusBadRequest, models.CodeAIUnconfigured, "API key not configured. Please set it in settings.")
 		return
 	}
 
-	// Get AI provider setting (default to github for GitHub Copilot models)
-	var aiProvider string
-	err = h.db.QueryRow("SELECT value FROM settings WHERE key = 'ai_provider'").Scan(&aiProvider)
-	if err != nil || aiProvider == "" {
-		aiProvider = "github" // Default to GitHub Models
-	}
-
-	// Get selected model (default to openai/gpt-4o-mini)
-	var selectedModel string
-	err = h.db.QueryRow("SELECT value FROM settings WHERE key = 'ai_model'").Scan(&selectedModel)
-	if err != nil || selectedModel == "" {
-		selectedModel = "openai/gpt-4o-mini"
-	}
-
-	// Ensure model has publisher prefix for GitHub Models API
-	if aiProvider == "github" && !strings.Contains(selectedModel, "/") {
-		// Add openai/ prefix if no publisher specified
-		selectedModel = "openai/" + selectedModel
-	}
-
 	// Save user message to history
-	h.db.Exec(`INSERT INTO chat_history (year, role, content) VALUES (?, 'user', ?)`, year, input.Message)
+	h.store.Chat.Add(ctx, year, openai.ChatMessageRoleUser, input.Message, "")
 
 	// Get calendar context
-	calendarContext := h.getCalendarContext(year)
+	calendarContext := h.getCalendarContext(ctx, year)
 
 	// Get chat history for context
-	chatHistory := h.getChatHistoryMessages(year, 10)
-
-	// Create client based on provider
-	var client *openai.Client
-
-	switch aiProvider {
-	case "github":
-		// GitHub Models API (new endpoint)
-		config := openai.DefaultConfig(apiKey)
-		config.BaseURL = "https://models.github.ai/inference"
-		client = openai.NewClientWithConfig(config)
-	case "openai":
-		client = openai.NewClient(apiKey)
-	default:
-		// Default to GitHub
-		config := openai.DefaultConfig(apiKey)
-		config.BaseURL = "https://models.github.ai/inference"
-		client = openai.NewClientWithConfig(config)
-	}
+	chatHistory := h.getChatHistoryMessages(ctx, year, 10)
 
 	// Build messages
 	messages := []openai.ChatCompletionMessage{
@@ -241,6 +350,10 @@ IMPORTANT - Handling vacation day limits:
 - The context shows "Vacation days used" vs "Total available" and "Reserved"
 - Manual vacation days are set directly by the user
 - Optimized vacation days are calculated by the optimizer
+- Locked days were approved and stay as they are; never try to add, remove or move them
+- Blackout periods (such as on-call weeks and releases) are closed to vacation; never propose or add days in them
+- Periods to avoid (such as release weeks) can be booked, but suggest other dates first and mention the clash when the user picks them
+- Past days are leave already taken; actions can't change them
 - Reserved days are kept aside and not planned
 - When all days are taken and user wants changes:
   * Suggest which existing days to remove to make room for new ones
@@ -251,6 +364,7 @@ IMPORTANT - Handling vacation day limits:
 When reorganizing vacations:
 - First remove the days that need to go, then add the new ones
 - You can combine multiple actions: first a remove_vacation, then add_vacation
+- To shift days to other dates (e.g. "move my March block one week later"), use move_vacation: it moves them all or none, and fails on holidays, weekends, booked, past or locked dates
 - If the user wants to completely reorganize, suggest: 1) clear all optimized days, 2) optionally clear manual days, 3) re-optimize
 
 CRITICAL - Response format rules:
@@ -263,6 +377,7 @@ CRITICAL - Response format rules:
 Action formats (include these in your response but don't mention them to the user):
 {"action": "add_vacation", "dates": ["2026-01-06", "2026-01-07"]}
 {"action": "remove_vacation", "dates": ["2026-01-06"]}
+{"action": "move_vacation", "moves": [{"from": "2026-03-09", "to": "2026-03-16"}]}
 {"action": "clear_optimized"}
 {"action": "clear_all_vacations"}
 {"action": "update_config", "vacation_days": 22, "reserved_days": 3, "optimization_strategy": "balanced", "work_week": ["monday","tuesday","wednesday","thursday","friday"]}
@@ -293,38 +408,27 @@ Available work week days: monday, tuesday, wednesday, thursday, friday, saturday
 		Content: input.Message,
 	})
 
-	// Call AI API
-	resp, err := client.CreateChatCompletion(
-		context.Background(),
-		openai.ChatCompletionRequest{
-			Model:    selectedModel,
-			Messages: messages,
-		},
-	)
-
+	// Call AI API, falling back to the next model when one fails
+	resp, model, err := h.completeChat(ctx, settings, openai.ChatCompletionRequest{Messages: messages})
 	if err != nil {
 		fmt.Printf("OpenAI API Error: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get AI response: " + err.Error()})
-		return
-	}
-
-	if len(resp.Choices) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "No response from AI"})
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, "Failed to get AI response: "+err.Error())
 		return
 	}
 
 	assistantMessage := resp.Choices[0].Message.Content
 
 	// Save assistant message to history
-	h.db.Exec(`INSERT INTO chat_history (year, role, content) VALUES (?, 'assistant', ?)`, year, assistantMessage)
+	h.store.Chat.Add(ctx, year, openai.ChatMessageRoleAssistant, assistantMessage, model)
 
 	// Check for actions in the response
-	action := h.parseAndExecuteAction(year, assistantMessage)
+	action := h.parseAndExecuteAction(ctx, year, assistantMessage)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":    assistantMessage,
 		"action":     action,
 		"hasAction":  action != nil,
+		"model":      model,
 	})
 }
 
@@ -333,23 +437,15 @@ func (h *Handler) GetChatHistory(c *gin.Context) {
 	yearStr := c.Param("year")
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 
-	rows, err := h.db.Query(`SELECT id, year, role, content, created_at FROM chat_history WHERE year = ? ORDER BY created_at ASC`, year)
+	messages, err := h.store.Chat.History(c.Request.Context(), year)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
 		return
 	}
-	defer rows.Close()
-
-	var messages []models.ChatMessage
-	for rows.Next() {
-		var msg models.ChatMessage
-		rows.Scan(&msg.ID, &msg.Year, &msg.Role, &msg.Content, &msg.CreatedAt)
-		messages = append(messages, msg)
-	}
 
 	c.JSON(http.StatusOK, messages)
 }
@@ -359,13 +455,12 @@ func (h *Handler) ClearChatHistory(c *gin.Context) {
 	yearStr := c.Param("year")
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 
-	_, err = h.db.Exec(`DELETE FROM chat_history WHERE year = ?`, year)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.store.Chat.Clear(c.Request.Context(), year); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
 		return
 	}
 
@@ -373,12 +468,12 @@ func (h *Handler) ClearChatHistory(c *gin.Context) {
 }
 
 // Helper functions
-func (h *Handler) getCalendarContext(year int) string {
-	config, _ := h.getOrCreateYearConfig(year)
-	workCity := h.getWorkCity()
-	holidayList := holidays.GetPortugueseHolidaysWithCity(year, workCity)
-	manualVacations, _ := h.getVacations(year)
-	optimalVacations, _ := h.getOptimalVacations(year)
+func (h *Handler) getCalendarContext(ctx context.Context, year int) string {
+	config, _ := h.getOrCreateYearConfig(ctx, year)
+	workCity := h.getWorkCity(ctx)
+	holidayList := h.holidaysForYear(ctx, year)
+	manualVacations, _ := h.store.Vacations.List(ctx, year)
+	optimalVacations, _ := h.store.Vacations.ListOptimal(ctx, year)
 
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("Year: %d\n", year))
@@ -386,9 +481,24 @@ func (h *Handler) getCalendarContext(year int) string {
 	sb.WriteString(fmt.Sprintf("Reserved days (for emergencies): %d\n", config.ReservedDays))
 	sb.WriteString(fmt.Sprintf("Optimization strategy: %s\n", config.OptimizationStrategy))
 	sb.WriteString(fmt.Sprintf("Work week: %v\n", config.WorkWeek))
+	for _, change := range config.WorkWeekChanges {
+		sb.WriteString(fmt.Sprintf("Work week from %s: %v\n", change.EffectiveFrom, change.WorkWeek))
+	}
+	if config.HoursMode() {
+		sb.WriteString(fmt.Sprintf("Vacation tracked in hours: %.1f hours, working hours per day: %v\n", config.EntitlementHours(), config.WorkingHours))
+	}
 	if workCity != "" {
 		sb.WriteString(fmt.Sprintf("Work city: %s (includes municipal holidays)\n", workCity))
 	}
+	if next, err := h.NextBreak(ctx); err == nil {
+		sb.WriteString(fmt.Sprintf("Today: %s\n", next.Today))
+		if next.NextDayOff != nil {
+			sb.WriteString(fmt.Sprintf("Next day off: %s (%s, in %d days)\n", next.NextDayOff.Date, next.NextDayOff.Kind, next.NextDayOff.DaysUntil))
+		}
+		if next.NextVacation != nil {
+			sb.WriteString(fmt.Sprintf("Next vacation: %s to %s (in %d days)\n", next.NextVacation.StartDate, next.NextVacation.EndDate, next.DaysUntilVacation))
+		}
+	}
 	
 	sb.WriteString("\nPortuguese Holidays:\n")
 	for _, h := range holidayList {
@@ -424,6 +534,31 @@ func (h *Handler) getCalendarContext(year int) string {
 		sb.WriteString("\nNo optimized vacation days. Run optimization to get suggestions.\n")
 	}
 
+	if len(config.BlackoutPeriods) > 0 {
+		sb.WriteString("\nBlackout periods, closed to vacation:\n")
+		for _, p := range config.BlackoutPeriods {
+			if p.Reason != "" {
+				sb.WriteString(fmt.Sprintf("- %s to %s: %s\n", p.StartDate, p.EndDate, p.Reason))
+			} else {
+				sb.WriteString(fmt.Sprintf("- %s to %s\n", p.StartDate, p.EndDate))
+			}
+		}
+	}
+
+	if len(config.AvoidPeriods) > 0 {
+		sb.WriteString("\nPeriods to avoid, which can be booked but better not (such as release weeks):\n")
+		for _, p := range config.AvoidPeriods {
+			sb.WriteString(fmt.Sprintf("- %s to %s: %s\n", p.StartDate, p.EndDate, p.Reason))
+		}
+	}
+
+	if locked, _ := h.store.Vacations.Locked(ctx, year); len(locked) > 0 {
+		sb.WriteString(fmt.Sprintf("\nLocked days (%d), which no action can change until they are unlocked:\n", len(locked)))
+		for _, l := range locked {
+			sb.WriteString(fmt.Sprintf("- %s\n", l.Date))
+		}
+	}
+
 	sb.WriteString(fmt.Sprintf("\n=== VACATION BUDGET ===\n"))
 	sb.WriteString(fmt.Sprintf("Total vacation days: %d\n", config.VacationDays))
 	sb.WriteString(fmt.Sprintf("Reserved for emergencies: %d\n", config.ReservedDays))
@@ -442,24 +577,21 @@ func (h *Handler) getCalendarContext(year int) string {
 	return sb.String()
 }
 
-func (h *Handler) getChatHistoryMessages(year int, limit int) []openai.ChatCompletionMessage {
-	rows, err := h.db.Query(`SELECT role, content FROM chat_history WHERE year = ? ORDER BY created_at DESC LIMIT ?`, year, limit)
+func (h *Handler) getChatHistoryMessages(ctx context.Context, year int, limit int) []openai.ChatCompletionMessage {
+	history, err := h.store.Chat.Recent(ctx, year, limit)
 	if err != nil {
 		return nil
 	}
-	defer rows.Close()
 
 	var messages []openai.ChatCompletionMessage
-	for rows.Next() {
-		var role, content string
-		rows.Scan(&role, &content)
-		messages = append([]openai.ChatCompletionMessage{{Role: role, Content: content}}, messages...)
+	for _, msg := range history {
+		messages = append(messages, openai.ChatCompletionMessage{Role: msg.Role, Content: msg.Content})
 	}
 
 	return messages
 }
 
-func (h *Handler) parseAndExecuteAction(year int, message string) map[string]interface{} {
+func (h *Handler) parseAndExecuteAction(ctx context.Context, year int, message string) map[string]interface{} {
 	// Find all JSON action blocks in the message
 	var allActions []map[string]interface{}
 	searchStart := 0
@@ -494,7 +626,7 @@ func (h *Handler) parseAndExecuteAction(year int, message string) map[string]int
 		var action map[string]interface{}
 		if err := json.Unmarshal([]byte(jsonStr), &action); err == nil {
 			// Execute this action
-			h.executeSingleAction(year, action)
+			h.executeSingleAction(ctx, year, action)
 			allActions = append(allActions, action)
 		}
 		
@@ -518,56 +650,140 @@ func (h *Handler) parseAndExecuteAction(year int, message string) map[string]int
 	}
 }
 
-func (h *Handler) executeSingleAction(year int, action map[string]interface{}) {
+func (h *Handler) executeSingleAction(ctx context.Context, year int, action map[string]interface{}) {
 	actionType, ok := action["action"].(string)
 	if !ok {
 		return
 	}
 
 	// Get holidays for this year to validate vacation dates
-	workCity := h.getWorkCity()
-	holidayList := holidays.GetPortugueseHolidaysWithCity(year, workCity)
+	holidayList := h.holidaysForYear(ctx, year)
 	holidayDates := make(map[string]bool)
 	for _, hol := range holidayList {
 		holidayDates[hol.Date] = true
 	}
 
+	// Past and locked dates are left as they are
+	from := h.editableFrom(ctx)
+	locked, err := h.lockedDates(ctx, year)
+	if err != nil {
+		action["error"] = err.Error()
+		return
+	}
+	config, err := h.getOrCreateYearConfig(ctx, year)
+	if err != nil {
+		action["error"] = err.Error()
+		return
+	}
+
 	switch actionType {
 	case "add_vacation":
 		if dates, ok := action["dates"].([]interface{}); ok {
-			var skippedHolidays []string
+			var skippedHolidays, skippedInvalid, skippedPast, skippedLocked, skippedBlackout []string
+			var added []string
 			for _, d := range dates {
 				if dateStr, ok := d.(string); ok {
+					// Skip dates outside the year being planned
+					if checkDateInYear(dateStr, year) != nil {
+						skippedInvalid = append(skippedInvalid, dateStr)
+						continue
+					}
 					// Skip if the date is a holiday
 					if holidayDates[dateStr] {
 						skippedHolidays = append(skippedHolidays, dateStr)
 						continue
 					}
-					h.db.Exec(`INSERT OR REPLACE INTO vacation_days (year, date, is_manual) VALUES (?, ?, TRUE)`, year, dateStr)
+					if dateStr < from {
+						skippedPast = append(skippedPast, dateStr)
+						continue
+					}
+					if locked[dateStr] {
+						skippedLocked = append(skippedLocked, dateStr)
+						continue
+					}
+					if config.InBlackout(dateStr) {
+						skippedBlackout = append(skippedBlackout, dateStr)
+						continue
+					}
+					h.store.Vacations.Add(ctx, year, dateStr, "")
+					added = append(added, dateStr)
 				}
 			}
 			if len(skippedHolidays) > 0 {
 				action["skipped_holidays"] = skippedHolidays
 			}
+			if len(skippedInvalid) > 0 {
+				action["skipped_invalid"] = skippedInvalid
+			}
+			if len(skippedPast) > 0 {
+				action["skipped_past"] = skippedPast
+			}
+			if len(skippedLocked) > 0 {
+				action["skipped_locked"] = skippedLocked
+			}
+			if len(skippedBlackout) > 0 {
+				action["skipped_blackout"] = skippedBlackout
+			}
+			if len(added) > 0 {
+				h.events.Publish(events.VacationAdded, year, gin.H{"dates": added, "source": "chat"})
+			}
 		}
 	case "remove_vacation":
 		if dates, ok := action["dates"].([]interface{}); ok {
+			var removed, skippedPast, skippedLocked []string
 			for _, d := range dates {
 				if dateStr, ok := d.(string); ok {
+					if dateStr < from {
+						skippedPast = append(skippedPast, dateStr)
+						continue
+					}
+					if locked[dateStr] {
+						skippedLocked = append(skippedLocked, dateStr)
+						continue
+					}
 					// Remove from both manual and optimized tables
-					h.db.Exec(`DELETE FROM vacation_days WHERE year = ? AND date = ?`, year, dateStr)
-					h.db.Exec(`DELETE FROM optimal_vacations WHERE year = ? AND date = ?`, year, dateStr)
+					h.store.Vacations.Remove(ctx, year, dateStr)
+					h.store.Vacations.RemoveOptimal(ctx, year, dateStr)
+					removed = append(removed, dateStr)
+				}
+			}
+			if len(skippedPast) > 0 {
+				action["skipped_past"] = skippedPast
+			}
+			if len(skippedLocked) > 0 {
+				action["skipped_locked"] = skippedLocked
+			}
+			if len(removed) > 0 {
+				h.events.Publish(events.VacationRemoved, year, gin.H{"dates": removed, "source": "chat"})
+			}
+		}
+	case "move_vacation":
+		if list, ok := action["moves"].([]interface{}); ok {
+			var moves []models.VacationMove
+			for _, m := range list {
+				if entry, ok := m.(map[string]interface{}); ok {
+					fromDate, _ := entry["from"].(string)
+					toDate, _ := entry["to"].(string)
+					moves = append(moves, models.VacationMove{From: fromDate, To: toDate})
 				}
 			}
+			if moved, err := h.MoveVacationDays(ctx, year, moves); err != nil {
+				action["error"] = err.Error()
+			} else {
+				action["moved"] = moved
+			}
 		}
 	case "clear_optimized":
-		// Clear only optimized vacation days, keep manual ones
-		h.db.Exec(`DELETE FROM optimal_vacations WHERE year = ?`, year)
+		// Clear only optimized vacation days, keep manual, past and locked ones
+		h.store.Vacations.ClearOptimalUnlocked(ctx, year, from)
+		h.events.Publish(events.OptimizationCleared, year, gin.H{"source": "chat"})
 		action["cleared"] = "optimized"
 	case "clear_all_vacations":
-		// Clear both manual and optimized vacation days
-		h.db.Exec(`DELETE FROM vacation_days WHERE year = ?`, year)
-		h.db.Exec(`DELETE FROM optimal_vacations WHERE year = ?`, year)
+		// Clear both manual and optimized vacation days, keeping the leave
+		// already taken and locked days
+		h.store.Vacations.ClearUnlocked(ctx, year, from)
+		h.store.Vacations.ClearOptimalUnlocked(ctx, year, from)
+		h.events.Publish(events.VacationsCleared, year, gin.H{"source": "chat"})
 		action["cleared"] = "all"
 	case "update_config":
 		updates := make(map[string]interface{})
@@ -593,7 +809,7 @@ func (h *Handler) executeSingleAction(year int, action map[string]interface{}) {
 
 		if len(updates) > 0 {
 			for key, value := range updates {
-				h.db.Exec(fmt.Sprintf(`UPDATE year_config SET %s = ?, updated_at = CURRENT_TIMESTAMP WHERE year = ?`, key), value, year)
+				h.store.Configs.SetField(ctx, year, key, value)
 			}
 		}
 	case "optimize":
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// maxCommentLength caps the text of a comment, in characters
+const maxCommentLength = 4000
+
+// GetComments returns the comments of a year, or of one date with ?date=,
+// oldest first. Replies carry the id of the comment they answer.
+func (h *Handler) GetComments(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	comments, err := h.store.Comments.List(c.Request.Context(), year, c.Query("date"))
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// AddComment comments on a vacation day or block, or replies to a comment.
+// With access control on, the author is the token's name.
+func (h *Handler) AddComment(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	var input struct {
+		Date     string `json:"date"`
+		Target   string `json:"target"` // day or block, defaults to day
+		ParentID *int64 `json:"parent_id"`
+		Author   string `json:"author"`
+		Body     string `json:"body" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	body, err := commentBody(input.Body)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	comment := models.Comment{
+		Year:   year,
+		Date:   input.Date,
+		Target: input.Target,
+		Author: strings.TrimSpace(input.Author),
+		Body:   body,
+	}
+	if token, ok := requestToken(c); ok {
+		comment.Author = token.Name
+	}
+
+	ctx := c.Request.Context()
+	if input.ParentID != nil {
+		// Replies join the parent's thread
+		parent, err := h.store.Comments.Get(ctx, year, *input.ParentID)
+		if errors.Is(err, store.ErrNotFound) {
+			problem(c, http.StatusNotFound, models.CodeNotFound, "Comment replied to not found")
+			return
+		} else if err != nil {
+			problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+			return
+		}
+		comment.ParentID = &parent.ID
+		comment.Date = parent.Date
+		comment.Target = parent.Target
+	} else {
+		if comment.Target == "" {
+			comment.Target = models.CommentDay
+		}
+		if err := h.checkCommentTarget(ctx, year, comment.Date, comment.Target); err != nil {
+			respondError(c, err)
+			return
+		}
+	}
+
+	comment, err = h.store.Comments.Add(ctx, comment)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// UpdateComment edits the text of a comment
+func (h *Handler) UpdateComment(c *gin.Context) {
+	year, comment, ok := h.ownComment(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Body string `json:"body" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+	body, err := commentBody(input.Body)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	comment, err = h.store.Comments.UpdateBody(c.Request.Context(), year, comment.ID, body)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment removes a comment and the replies under it
+func (h *Handler) DeleteComment(c *gin.Context) {
+	year, comment, ok := h.ownComment(c)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.store.Comments.Delete(c.Request.Context(), year, comment.ID)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted", "deleted": deleted})
+}
+
+// ownComment returns the year and comment a request names, writing the error
+// response when there is none or, with access control on, when the caller
+// is neither its author nor a manager
+func (h *Handler) ownComment(c *gin.Context) (int, models.Comment, bool) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return 0, models.Comment{}, false
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid comment id")
+		return 0, models.Comment{}, false
+	}
+
+	comment, err := h.store.Comments.Get(c.Request.Context(), year, id)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Comment not found")
+		return 0, comment, false
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return 0, comment, false
+	}
+
+	if token, ok := requestToken(c); ok && token.Name != comment.Author && !hasRole(token.Role, models.RoleManager) {
+		problem(c, http.StatusForbidden, models.CodeForbidden, "Only the author or a manager can change a comment")
+		return 0, comment, false
+	}
+
+	return year, comment, true
+}
+
+// commentBody trims the text of a comment and checks its length
+func commentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", invalidInput(errors.New("body is required"))
+	}
+	if len([]rune(body)) > maxCommentLength {
+		return "", invalidInput(fmt.Errorf("body must be at most %d characters", maxCommentLength))
+	}
+	return body, nil
+}
+
+// checkCommentTarget returns an error unless date is a vacation day of the
+// year, for day comments, or the first day of one of its vacation blocks
+func (h *Handler) checkCommentTarget(ctx context.Context, year int, date, target string) error {
+	if err := checkYear(year); err != nil {
+		return err
+	}
+	if err := checkDateInYear(date, year); err != nil {
+		return err
+	}
+
+	calendar, err := h.Calendar(ctx, year)
+	if err != nil {
+		return err
+	}
+
+	switch target {
+	case models.CommentDay:
+		for _, day := range calendar.Days {
+			if day.Date == date && day.IsVacation {
+				return nil
+			}
+		}
+		return invalidInput(fmt.Errorf("%s is not a vacation day", date))
+	case models.CommentBlock:
+		for _, block := range calendar.VacationBlocks {
+			if block.StartDate == date {
+				return nil
+			}
+		}
+		return invalidInput(fmt.Errorf("no vacation block starts on %s", date))
+	default:
+		return invalidInput(fmt.Errorf("target must be %s or %s", models.CommentDay, models.CommentBlock))
+	}
+}
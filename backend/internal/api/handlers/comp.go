@@ -0,0 +1,344 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// GetCompDays returns the compensation day ledger of a year with its balance
+func (h *Handler) GetCompDays(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	config, err := h.getOrCreateYearConfig(ctx, year)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	ledger := h.compPool(ctx, year)
+	ledger.CoverVacation(len(h.vacationDates(ctx, year)), config.VacationDays)
+
+	c.JSON(http.StatusOK, ledger)
+}
+
+// CreditCompDay records a weekend or other non-work day that was worked,
+// earning a compensation day. Worked holidays are credited through the
+// worked holidays instead.
+func (h *Handler) CreditCompDay(c *gin.Context) {
+	h.addCompDay(c, models.CompCredit)
+}
+
+// SpendCompDay takes a work day off from the compensation pool
+func (h *Handler) SpendCompDay(c *gin.Context) {
+	h.addCompDay(c, models.CompSpend)
+}
+
+func (h *Handler) addCompDay(c *gin.Context, kind string) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	var input struct {
+		Date string `json:"date" binding:"required"`
+		Note string `json:"note"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if err := h.checkCompDay(ctx, year, kind, input.Date); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	entry := models.CompDay{Year: year, Date: input.Date, Kind: kind, Note: input.Note}
+	entry.ID, err = h.store.Comp.Add(ctx, entry)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// checkCompDay returns an error unless a ledger entry can be recorded on a
+// date: credits go on non-work days that are not holidays, spends on work
+// days that are not already off, while the pool has days left
+func (h *Handler) checkCompDay(ctx context.Context, year int, kind, date string) error {
+	if err := checkYear(year); err != nil {
+		return err
+	}
+	if err := checkDateInYear(date, year); err != nil {
+		return err
+	}
+
+	config, err := h.getOrCreateYearConfig(ctx, year)
+	if err != nil {
+		return err
+	}
+	parsed, _ := dates.Parse(date)
+
+	// Worked holidays included, a credit on one would count it twice
+	allHolidays := holidays.ApplySubstitution(h.applyHolidayOverrides(ctx, year, h.holidayService.Holidays(year, h.getWorkCity(ctx))), h.getHolidaySubstitution(ctx))
+	for _, hol := range allHolidays {
+		if hol.Date == date {
+			if kind == models.CompCredit {
+				return invalidInputCode(models.CodeHolidayConflict, fmt.Errorf("%s is a holiday, mark it as worked instead", date))
+			}
+			return invalidInputCode(models.CodeHolidayConflict, fmt.Errorf("%s is a holiday", date))
+		}
+	}
+
+	if kind == models.CompCredit {
+		if config.IsWorkDay(parsed) {
+			return invalidInput(fmt.Errorf("%s is a work day", date))
+		}
+		return nil
+	}
+
+	if !config.IsWorkDay(parsed) {
+		return invalidInput(fmt.Errorf("%s is not a work day", date))
+	}
+	for _, hol := range holidays.AddOptional(nil, year, config.OptionalHolidays) {
+		if hol.Date == date {
+			return invalidInputCode(models.CodeHolidayConflict, fmt.Errorf("%s is a company optional holiday", date))
+		}
+	}
+
+	manualVacations, _ := h.store.Vacations.List(ctx, year)
+	for _, v := range manualVacations {
+		if v.Date == date {
+			return invalidInput(fmt.Errorf("%s is already a vacation day", date))
+		}
+	}
+	optimalVacations, _ := h.store.Vacations.ListOptimal(ctx, year)
+	for _, v := range optimalVacations {
+		if v.Date == date {
+			return invalidInput(fmt.Errorf("%s is already a vacation day", date))
+		}
+	}
+
+	ledger := h.compPool(ctx, year)
+	for _, spent := range ledger.SpentDates() {
+		if spent == date {
+			return invalidInput(fmt.Errorf("%s is already a comp day off", date))
+		}
+	}
+	ledger.CoverVacation(len(manualVacations)+len(optimalVacations), config.VacationDays)
+	if ledger.Balance <= 0 {
+		return invalidInputCode(models.CodeBudgetExceeded, fmt.Errorf("no compensation days left in %d", year))
+	}
+
+	return nil
+}
+
+// DeleteCompDay removes an entry from the compensation day ledger
+func (h *Handler) DeleteCompDay(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid id")
+		return
+	}
+
+	err = h.store.Comp.Delete(c.Request.Context(), year, id)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Compensation day not found")
+		return
+	}
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Compensation day removed"})
+}
+
+// maxAdjustmentDays bounds a single balance adjustment either way
+const maxAdjustmentDays = 365
+
+// adjustmentKinds are the accepted kinds of balance adjustments
+var adjustmentKinds = map[string]bool{
+	models.AdjustmentBonus:      true,
+	models.AdjustmentPurchased:  true,
+	models.AdjustmentCorrection: true,
+}
+
+// CreateBalanceAdjustment grants (positive days) or deducts (negative days)
+// vacation days by hand, through the compensation pool, so the optimizer
+// budget follows
+func (h *Handler) CreateBalanceAdjustment(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	var input struct {
+		Date   string `json:"date" binding:"required"`
+		Days   int    `json:"days"`
+		Kind   string `json:"kind" binding:"required"`
+		Reason string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	adjustment := models.BalanceAdjustment{Year: year, Date: input.Date, Days: input.Days, Kind: input.Kind, Reason: strings.TrimSpace(input.Reason)}
+	if err := checkAdjustment(adjustment); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	adjustment.ID, err = h.store.Comp.AddAdjustment(c.Request.Context(), adjustment)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, adjustment)
+}
+
+// checkAdjustment returns an error unless a balance adjustment is dated in
+// its year, moves a bounded number of days and says why
+func checkAdjustment(a models.BalanceAdjustment) error {
+	if err := checkYear(a.Year); err != nil {
+		return err
+	}
+	if err := checkDateInYear(a.Date, a.Year); err != nil {
+		return err
+	}
+	if !adjustmentKinds[a.Kind] {
+		return invalidInput(fmt.Errorf("invalid kind %q, must be bonus, purchased or correction", a.Kind))
+	}
+	if a.Days == 0 || a.Days < -maxAdjustmentDays || a.Days > maxAdjustmentDays {
+		return invalidInput(fmt.Errorf("days must be between %d and %d and not 0", -maxAdjustmentDays, maxAdjustmentDays))
+	}
+	if a.Reason == "" {
+		return invalidInput(errors.New("reason is required"))
+	}
+	return nil
+}
+
+// DeleteBalanceAdjustment removes a balance adjustment
+func (h *Handler) DeleteBalanceAdjustment(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid id")
+		return
+	}
+
+	err = h.store.Comp.DeleteAdjustment(c.Request.Context(), year, id)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Balance adjustment not found")
+		return
+	}
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Balance adjustment removed"})
+}
+
+// compPool returns the compensation days earned and taken off in a year,
+// with the days adjusted by hand. Its balance does not account for vacation
+// days yet, see CoverVacation.
+func (h *Handler) compPool(ctx context.Context, year int) models.CompLedger {
+	entries, err := h.store.Comp.List(ctx, year)
+	if err != nil {
+		entries = []models.CompDay{}
+	}
+	adjustments, err := h.store.Comp.Adjustments(ctx, year)
+	if err != nil {
+		adjustments = []models.BalanceAdjustment{}
+	}
+	worked, _ := h.store.Holidays.CountWorked(ctx, year)
+
+	ledger := models.CompLedger{Year: year, Entries: entries, Adjustments: adjustments, WorkedHolidays: worked, Earned: worked}
+	for _, e := range entries {
+		switch e.Kind {
+		case models.CompCredit:
+			ledger.Earned++
+		case models.CompSpend:
+			ledger.Spent++
+		}
+	}
+	for _, a := range adjustments {
+		ledger.Adjusted += a.Days
+	}
+	ledger.Balance = ledger.Earned - ledger.Spent + ledger.Adjusted
+	return ledger
+}
+
+// daysOffForYear returns the holidays of a year along with the comp days
+// taken off, for planning vacations around both
+func (h *Handler) daysOffForYear(ctx context.Context, year int) []holidays.PortugueseHoliday {
+	return withCompDays(h.holidaysForYear(ctx, year), h.compPool(ctx, year).SpentDates())
+}
+
+// withCompDays adds comp days off to a holiday list, so vacation blocks and
+// the optimizer treat them as days off that cost no vacation
+func withCompDays(holidayList []holidays.PortugueseHoliday, spent []string) []holidays.PortugueseHoliday {
+	if len(spent) == 0 {
+		return holidayList
+	}
+
+	daysOff := append([]holidays.PortugueseHoliday{}, holidayList...)
+	for _, date := range spent {
+		daysOff = append(daysOff, holidays.PortugueseHoliday{Date: date, Name: "Compensation day", Type: "comp_day"})
+	}
+	return daysOff
+}
+
+// markCompDays flags the calendar days taken off from the compensation pool
+func markCompDays(days []models.CalendarDay, spent []string) {
+	spentSet := make(map[string]bool)
+	for _, date := range spent {
+		spentSet[date] = true
+	}
+	for i := range days {
+		days[i].IsCompDay = spentSet[days[i].Date]
+	}
+}
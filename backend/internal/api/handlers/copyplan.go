@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/events"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// mapToYear returns the date a day of one year maps to in another. With
+// CopySameWeekday it is the same weekday nearest to the same date, kept in
+// the target year, so a Monday-to-Friday week stays one.
+func mapToYear(d time.Time, year int, mode string) time.Time {
+	target := time.Date(year, d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+	if mode != models.CopySameWeekday {
+		return target
+	}
+
+	shift := int(d.Weekday()) - int(target.Weekday())
+	if shift > 3 {
+		shift -= 7
+	} else if shift < -3 {
+		shift += 7
+	}
+	target = target.AddDate(0, 0, shift)
+	if target.Year() < year {
+		target = target.AddDate(0, 0, 7)
+	} else if target.Year() > year {
+		target = target.AddDate(0, 0, -7)
+	}
+	return target
+}
+
+// CopyVacationPlan books the manual vacation days of sourceYear on the
+// matching dates of year, with their notes, and reports where each one
+// landed. Days that land on a holiday (most often one that moved, like
+// Easter), a non-work day, a blackout period, a locked or past day or one
+// already booked are skipped, as are those past the vacation and comp days
+// left in year.
+func (h *Handler) CopyVacationPlan(ctx context.Context, year, sourceYear int, mode string) ([]models.CopiedVacation, error) {
+	if mode != models.CopySameWeekday && mode != models.CopySameDate {
+		return nil, invalidInput(fmt.Errorf("unknown vacations mapping %q, must be %s or %s", mode, models.CopySameWeekday, models.CopySameDate))
+	}
+
+	source, err := h.store.Vacations.List(ctx, sourceYear)
+	if err != nil {
+		return nil, err
+	}
+	config, err := h.getOrCreateYearConfig(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+	holidays := make(map[string]string)
+	for _, holiday := range h.holidaysForYear(ctx, year) {
+		holidays[holiday.Date] = holiday.Name
+	}
+	locked, err := h.lockedDates(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+	editableFrom := h.editableFrom(ctx)
+	calendar, err := h.Calendar(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+	available := calendar.Summary.RemainingVacationDays + calendar.Summary.CompDaysRemaining
+
+	results := []models.CopiedVacation{}
+	var added []string
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		booked := make(map[string]bool)
+		current, err := tx.Vacations.List(ctx, year)
+		if err != nil {
+			return err
+		}
+		optimal, err := tx.Vacations.ListOptimal(ctx, year)
+		if err != nil {
+			return err
+		}
+		for _, v := range current {
+			booked[v.Date] = true
+		}
+		for _, v := range optimal {
+			booked[v.Date] = true
+		}
+
+		for _, v := range source {
+			d, err := dates.Parse(v.Date)
+			if err != nil {
+				continue
+			}
+			target := mapToYear(d, year, mode)
+			result := models.CopiedVacation{From: v.Date, To: dates.Format(target)}
+			switch {
+			case target.Year() != year:
+				result.Status, result.To = "outside_year", ""
+			case holidays[result.To] != "":
+				result.Status, result.Holiday = "skipped_holiday", holidays[result.To]
+			case !config.IsWorkDay(target):
+				result.Status = "skipped_non_work_day"
+			case config.InBlackout(result.To):
+				result.Status = "skipped_blackout"
+			case result.To < editableFrom || locked[result.To]:
+				result.Status = "skipped_locked"
+			case booked[result.To]:
+				result.Status = "skipped_duplicate"
+			case available <= 0:
+				result.Status = "over_budget"
+			default:
+				if err := tx.Vacations.Add(ctx, year, result.To, v.Note); err != nil {
+					return err
+				}
+				result.Status = "created"
+				added = append(added, result.To)
+				booked[result.To] = true
+				available--
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(added) > 0 {
+		h.events.Publish(events.VacationAdded, year, gin.H{"dates": added, "source": "copy"})
+	}
+	return results, nil
+}
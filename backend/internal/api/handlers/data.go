@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/events"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// GetStoredYears lists the years with stored data and their row count per
+// table
+func (h *Handler) GetStoredYears(c *gin.Context) {
+	years, err := h.store.Admin.Years(c.Request.Context())
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, years)
+}
+
+// PurgeYear deletes every stored row of a year: its config, plan, holidays,
+// trips, comments and the rest
+func (h *Handler) PurgeYear(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	ctx := c.Request.Context()
+	var deleted map[string]int64
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		deleted, err = tx.Admin.Purge(ctx, year)
+		return err
+	})
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	h.events.Publish(events.YearPurged, year, nil)
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Year %d purged", year), "deleted": deleted})
+}
+
+// ArchiveYears exports past years as gzipped JSON and then purges them. The
+// years are listed in years, or are all the stored years before before.
+func (h *Handler) ArchiveYears(c *gin.Context) {
+	var input struct {
+		Years  []int `json:"years"`
+		Before int   `json:"before"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+	if len(input.Years) == 0 && input.Before == 0 {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "years or before is required")
+		return
+	}
+
+	ctx := c.Request.Context()
+	years := input.Years
+	if input.Before != 0 {
+		stored, err := h.store.Admin.Years(ctx)
+		if err != nil {
+			problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+			return
+		}
+		for _, data := range stored {
+			if data.Year < input.Before && !containsYear(years, data.Year) {
+				years = append(years, data.Year)
+			}
+		}
+	}
+	if len(years) == 0 {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "No stored years to archive")
+		return
+	}
+	sort.Ints(years)
+
+	// Only years already over are archived
+	currentYear := h.today(ctx).Year()
+	for _, year := range years {
+		if year >= currentYear {
+			problem(c, http.StatusBadRequest, models.CodeInvalidRequest, fmt.Sprintf("Only years before %d can be archived", currentYear))
+			return
+		}
+	}
+
+	// The archive is built before anything is deleted, and the purge runs in
+	// one transaction, so a failure keeps the years
+	var buf bytes.Buffer
+	err := h.store.InTx(ctx, func(tx *store.Store) error {
+		archive := models.YearArchive{
+			Years:      years,
+			ArchivedAt: time.Now().UTC().Format(time.RFC3339),
+			Tables:     map[string][]map[string]interface{}{},
+		}
+		for _, year := range years {
+			tables, err := tx.Admin.Export(ctx, year)
+			if err != nil {
+				return err
+			}
+			for name, rows := range tables {
+				archive.Tables[name] = append(archive.Tables[name], rows...)
+			}
+		}
+
+		zw := gzip.NewWriter(&buf)
+		if err := json.NewEncoder(zw).Encode(archive); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+
+		for _, year := range years {
+			if _, err := tx.Admin.Purge(ctx, year); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	names := make([]string, len(years))
+	for i, year := range years {
+		names[i] = strconv.Itoa(year)
+		h.events.Publish(events.YearPurged, year, gin.H{"archived": true})
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="vacation-planner-%s.json.gz"`, strings.Join(names, "-")))
+	c.Data(http.StatusOK, "application/gzip", buf.Bytes())
+}
+
+// containsYear reports whether years holds year
+func containsYear(years []int, year int) bool {
+	for _, y := range years {
+		if y == year {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+var (
+	// ErrDateLocked is returned when a change would touch a locked date
+	ErrDateLocked = errors.New("locked dates can't change until they are unlocked")
+	// ErrPastDate is returned when a change would touch a day further in the
+	// past than the past_edit_grace_days setting allows
+	ErrPastDate = errors.New("past vacation days can't change")
+)
+
+// GetLockedDates returns the locked dates of a year
+func (h *Handler) GetLockedDates(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	locked, err := h.store.Vacations.Locked(c.Request.Context(), year)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, locked)
+}
+
+// LockDates locks dates, or the vacation days of the block named by block,
+// so the optimizer, bulk updates and chat actions leave them as they are
+func (h *Handler) LockDates(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	var input struct {
+		Dates  []string `json:"dates"`
+		Block  string   `json:"block"` // A block's position or first day
+		Reason string   `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+	if len(input.Dates) == 0 && input.Block == "" {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "dates or block is required")
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := checkYear(year); err != nil {
+		respondError(c, err)
+		return
+	}
+	for _, date := range input.Dates {
+		if err := checkDateInYear(date, year); err != nil {
+			respondError(c, err)
+			return
+		}
+	}
+	toLock := input.Dates
+	if input.Block != "" {
+		block, err := h.findBlock(ctx, year, input.Block)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		for _, date := range block.Dates {
+			if !contains(block.Weekends, date) && !contains(block.Holidays, date) {
+				toLock = append(toLock, date)
+			}
+		}
+	}
+
+	var lockedBy string
+	if token, ok := requestToken(c); ok {
+		lockedBy = token.Name
+	}
+	reason := strings.TrimSpace(input.Reason)
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		for _, date := range toLock {
+			if err := tx.Vacations.Lock(ctx, models.LockedDate{Year: year, Date: date, Reason: reason, LockedBy: lockedBy}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	locked, err := h.store.Vacations.Locked(ctx, year)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, locked)
+}
+
+// UnlockDate unlocks a date
+func (h *Handler) UnlockDate(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	unlocked, err := h.store.Vacations.Unlock(c.Request.Context(), year, c.Param("date"))
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+	if !unlocked {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Date is not locked")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Date unlocked"})
+}
+
+// lockedDates returns the set of locked dates of a year
+func (h *Handler) lockedDates(ctx context.Context, year int) (map[string]bool, error) {
+	locked, err := h.store.Vacations.Locked(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]bool, len(locked))
+	for _, l := range locked {
+		set[l.Date] = true
+	}
+	return set, nil
+}
+
+// editableFrom returns the first day whose vacation status may change, the
+// grace period before today, or "" when past days are not protected
+func (h *Handler) editableFrom(ctx context.Context) string {
+	s := h.loadSettings(ctx)
+	if !s.PastEditProtection {
+		return ""
+	}
+	return dates.Format(dates.Today(dates.Location(s.Timezone)).AddDate(0, 0, -s.PastEditGraceDays))
+}
+
+// checkEditable returns an error wrapping ErrPastDate or ErrDateLocked when
+// any of the changed dates is past or locked
+func (h *Handler) checkEditable(ctx context.Context, year int, changed ...string) error {
+	from := h.editableFrom(ctx)
+	var past []string
+	for _, date := range changed {
+		if date < from {
+			past = append(past, date)
+		}
+	}
+	if len(past) > 0 {
+		return fmt.Errorf("%w before %s: %s", ErrPastDate, from, strings.Join(past, ", "))
+	}
+
+	locked, err := h.lockedDates(ctx, year)
+	if err != nil {
+		return err
+	}
+	var found []string
+	for _, date := range changed {
+		if locked[date] {
+			found = append(found, date)
+		}
+	}
+	if len(found) > 0 {
+		return fmt.Errorf("%w: %s", ErrDateLocked, strings.Join(found, ", "))
+	}
+	return nil
+}
+
+// markLockedDays flags the locked days of a calendar
+func markLockedDays(days []models.CalendarDay, locked map[string]bool) {
+	for i := range days {
+		days[i].IsLocked = locked[days[i].Date]
+	}
+}
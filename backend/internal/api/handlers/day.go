@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// GetDay returns what a single date is: a work day or why it is off, its
+// holiday and the vacation block it belongs to
+func (h *Handler) GetDay(c *gin.Context) {
+	day, err := h.Day(c.Request.Context(), c.Param("date"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, day)
+}
+
+// Day classifies a YYYY-MM-DD date of the calendar
+func (h *Handler) Day(ctx context.Context, date string) (models.DayStatus, error) {
+	parsed, err := dates.Parse(date)
+	if err != nil {
+		return models.DayStatus{}, invalidInputCode(models.CodeInvalidDate, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date))
+	}
+	year := parsed.Year()
+	if err := checkYear(year); err != nil {
+		return models.DayStatus{}, err
+	}
+
+	calendar, err := h.Calendar(ctx, year)
+	if err != nil {
+		return models.DayStatus{}, err
+	}
+
+	status := models.DayStatus{Kind: models.DayWorkDay}
+	found := false
+	for _, day := range calendar.Days {
+		if day.Date == date {
+			status.CalendarDay = day
+			found = true
+			break
+		}
+	}
+	if !found {
+		return models.DayStatus{}, fmt.Errorf("%s is not in the calendar: %w", date, store.ErrNotFound)
+	}
+
+	if kind := dayOffKind(status.CalendarDay); kind != "" {
+		status.Kind = kind
+		status.IsDayOff = true
+	}
+	status.IsWorkDay = calendar.Config.IsWorkDay(parsed)
+	status.InBlackout = calendar.Config.InBlackout(date)
+	for _, holiday := range calendar.Holidays {
+		if holiday.Date == date {
+			holiday := holiday
+			status.Holiday = &holiday
+			break
+		}
+	}
+	for _, block := range calendar.VacationBlocks {
+		if block.StartDate <= date && date <= block.EndDate {
+			block := block
+			status.Block = &block
+			break
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// expenseInput is the body of expense create and update requests
+type expenseInput struct {
+	Date        string  `json:"date"`
+	Description string  `json:"description" binding:"required"`
+	Category    string  `json:"category"`
+	Amount      float64 `json:"amount"`
+}
+
+// expense validates the input and returns it as an expense of a trip
+func (in expenseInput) expense(tripID int64) (models.TripExpense, error) {
+	description := strings.TrimSpace(in.Description)
+	if description == "" {
+		return models.TripExpense{}, invalidInput(errors.New("description is required"))
+	}
+	if in.Date != "" {
+		if _, err := dates.Parse(in.Date); err != nil {
+			return models.TripExpense{}, invalidInputCode(models.CodeInvalidDate, errors.New("invalid date, expected YYYY-MM-DD"))
+		}
+	}
+	if in.Amount < 0 {
+		return models.TripExpense{}, invalidInput(errors.New("amount cannot be negative"))
+	}
+
+	return models.TripExpense{
+		TripID:      tripID,
+		Date:        in.Date,
+		Description: description,
+		Category:    strings.ToLower(strings.TrimSpace(in.Category)),
+		Amount:      in.Amount,
+	}, nil
+}
+
+// GetTripExpenses returns the expenses of a trip
+func (h *Handler) GetTripExpenses(c *gin.Context) {
+	trip, ok := h.tripFromParams(c)
+	if !ok {
+		return
+	}
+
+	expenses, err := h.store.Trips.Expenses(c.Request.Context(), trip.ID)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, expenses)
+}
+
+// AddTripExpense records money spent on a trip
+func (h *Handler) AddTripExpense(c *gin.Context) {
+	trip, ok := h.tripFromParams(c)
+	if !ok {
+		return
+	}
+
+	var input expenseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	expense, err := input.expense(trip.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	expense, err = h.store.Trips.AddExpense(c.Request.Context(), expense)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, expense)
+}
+
+// UpdateTripExpense replaces an expense of a trip
+func (h *Handler) UpdateTripExpense(c *gin.Context) {
+	trip, ok := h.tripFromParams(c)
+	if !ok {
+		return
+	}
+	expenseID, ok := expenseParam(c)
+	if !ok {
+		return
+	}
+
+	var input expenseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	expense, err := input.expense(trip.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	expense.ID = expenseID
+
+	expense, err = h.store.Trips.UpdateExpense(c.Request.Context(), expense)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Expense not found")
+		return
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, expense)
+}
+
+// DeleteTripExpense removes an expense of a trip
+func (h *Handler) DeleteTripExpense(c *gin.Context) {
+	trip, ok := h.tripFromParams(c)
+	if !ok {
+		return
+	}
+	expenseID, ok := expenseParam(c)
+	if !ok {
+		return
+	}
+
+	err := h.store.Trips.DeleteExpense(c.Request.Context(), trip.ID, expenseID)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Expense not found")
+		return
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Expense deleted"})
+}
+
+// GetTripBudget returns the budget and spending of each trip of a year, with
+// the year's totals and spending per category
+func (h *Handler) GetTripBudget(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	trips, err := h.store.Trips.List(ctx, year)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+	expenses, err := h.store.Trips.YearExpenses(ctx, year)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	summary := models.TripBudgetSummary{Year: year, Trips: []models.TripBudget{}, ByCategory: map[string]float64{}}
+	for _, trip := range trips {
+		summary.Trips = append(summary.Trips, models.TripBudget{
+			TripID:    trip.ID,
+			Name:      trip.Name,
+			Budget:    trip.Budget,
+			Spent:     trip.Spent,
+			Remaining: trip.Budget - trip.Spent,
+		})
+		summary.TotalBudget += trip.Budget
+		summary.TotalSpent += trip.Spent
+	}
+	summary.TotalRemaining = summary.TotalBudget - summary.TotalSpent
+	for _, e := range expenses {
+		summary.ByCategory[e.Category] += e.Amount
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// tripFromParams loads the trip named by the year and id parameters,
+// answering with an error when there is none
+func (h *Handler) tripFromParams(c *gin.Context) (models.Trip, bool) {
+	year, id, ok := tripParams(c)
+	if !ok {
+		return models.Trip{}, false
+	}
+
+	trip, err := h.store.Trips.Get(c.Request.Context(), year, id)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Trip not found")
+		return trip, false
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return trip, false
+	}
+	return trip, true
+}
+
+func expenseParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("expenseId"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid expense id")
+		return 0, false
+	}
+	return id, true
+}
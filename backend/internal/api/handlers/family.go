@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// maxClosureDays is the longest closure, so a typo in a year can't mark
+// years of days
+const maxClosureDays = 366
+
+// familyMemberInput is the body of family member create and update requests
+type familyMemberInput struct {
+	Name           string `json:"name" binding:"required"`
+	SchoolDistrict string `json:"school_district"`
+	PlanAround     *bool  `json:"plan_around"` // Defaults to true
+}
+
+// member validates the input and returns it as a family member
+func (in familyMemberInput) member() (models.FamilyMember, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return models.FamilyMember{}, invalidInput(errors.New("name is required"))
+	}
+
+	district := strings.TrimSpace(in.SchoolDistrict)
+	if district != "" && !holidays.IsSchoolDistrict(district) {
+		return models.FamilyMember{}, invalidInput(fmt.Errorf("unknown school district %q", district))
+	}
+
+	planAround := true
+	if in.PlanAround != nil {
+		planAround = *in.PlanAround
+	}
+
+	return models.FamilyMember{Name: name, SchoolDistrict: district, PlanAround: planAround}, nil
+}
+
+// GetFamily returns the family members with their closures overlapping a year
+func (h *Handler) GetFamily(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	members, err := h.familyForYear(c.Request.Context(), year)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// CreateFamilyMember adds a family member
+func (h *Handler) CreateFamilyMember(c *gin.Context) {
+	var input familyMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	member, err := input.member()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	member, err = h.store.Family.CreateMember(c.Request.Context(), member)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	member.Closures = []models.FamilyClosure{}
+	c.JSON(http.StatusOK, member)
+}
+
+// UpdateFamilyMember replaces the name, school district and preference of a
+// family member
+func (h *Handler) UpdateFamilyMember(c *gin.Context) {
+	id, ok := familyMemberID(c)
+	if !ok {
+		return
+	}
+
+	var input familyMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	member, err := input.member()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	member.ID = id
+
+	member, err = h.store.Family.UpdateMember(c.Request.Context(), member)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Family member not found")
+		return
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	member.Closures = []models.FamilyClosure{}
+	c.JSON(http.StatusOK, member)
+}
+
+// DeleteFamilyMember removes a family member and their closures
+func (h *Handler) DeleteFamilyMember(c *gin.Context) {
+	id, ok := familyMemberID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	err := h.store.InTx(ctx, func(tx *store.Store) error {
+		return tx.Family.DeleteMember(ctx, id)
+	})
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Family member not found")
+		return
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Family member deleted"})
+}
+
+// AddFamilyClosure adds a period a family member's school or childcare is
+// closed
+func (h *Handler) AddFamilyClosure(c *gin.Context) {
+	id, ok := familyMemberID(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name      string `json:"name" binding:"required"`
+		StartDate string `json:"start_date" binding:"required"`
+		EndDate   string `json:"end_date" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	start, err := dates.Parse(input.StartDate)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidDate, "Invalid start_date: "+input.StartDate)
+		return
+	}
+	end, err := dates.Parse(input.EndDate)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidDate, "Invalid end_date: "+input.EndDate)
+		return
+	}
+	if end.Before(start) {
+		problem(c, http.StatusBadRequest, models.CodeInvalidDate, "end_date must not be before start_date")
+		return
+	}
+	if end.Sub(start).Hours()/24 >= maxClosureDays {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, fmt.Sprintf("A closure can last at most %d days", maxClosureDays))
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := h.store.Family.GetMember(ctx, id); errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Family member not found")
+		return
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	closure, err := h.store.Family.AddClosure(ctx, models.FamilyClosure{
+		MemberID:  id,
+		Name:      strings.TrimSpace(input.Name),
+		StartDate: input.StartDate,
+		EndDate:   input.EndDate,
+	})
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, closure)
+}
+
+// DeleteFamilyClosure removes a closure of a family member
+func (h *Handler) DeleteFamilyClosure(c *gin.Context) {
+	id, ok := familyMemberID(c)
+	if !ok {
+		return
+	}
+	closureID, err := strconv.ParseInt(c.Param("closureId"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid closure id")
+		return
+	}
+
+	err = h.store.Family.DeleteClosure(c.Request.Context(), id, closureID)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Closure not found")
+		return
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Closure deleted"})
+}
+
+func familyMemberID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid family member id")
+		return 0, false
+	}
+	return id, true
+}
+
+// familyForYear returns the family members with their closures overlapping
+// a year: those entered by hand and the breaks of their school district
+func (h *Handler) familyForYear(ctx context.Context, year int) ([]models.FamilyMember, error) {
+	members, err := h.store.Family.Members(ctx)
+	if err != nil || len(members) == 0 {
+		return members, err
+	}
+
+	closures, err := h.store.Family.Closures(ctx, fmt.Sprintf("%d-01-01", year), fmt.Sprintf("%d-12-31", year))
+	if err != nil {
+		return nil, err
+	}
+	byMember := make(map[int64][]models.FamilyClosure)
+	for _, closure := range closures {
+		byMember[closure.MemberID] = append(byMember[closure.MemberID], closure)
+	}
+
+	districtBreaks := make(map[string][]holidays.SchoolBreak)
+	for i := range members {
+		m := &members[i]
+		m.Closures = byMember[m.ID]
+		if m.SchoolDistrict != "" {
+			breaks, ok := districtBreaks[m.SchoolDistrict]
+			if !ok {
+				breaks, _ = h.holidayService.LoadSchoolBreaks(year, m.SchoolDistrict)
+				districtBreaks[m.SchoolDistrict] = breaks
+			}
+			for _, b := range breaks {
+				m.Closures = append(m.Closures, models.FamilyClosure{
+					MemberID:  m.ID,
+					Name:      b.Name,
+					StartDate: b.StartDate,
+					EndDate:   b.EndDate,
+					Source:    models.ClosureSchool,
+				})
+			}
+		}
+		if m.Closures == nil {
+			m.Closures = []models.FamilyClosure{}
+		}
+		sort.SliceStable(m.Closures, func(a, b int) bool { return m.Closures[a].StartDate < m.Closures[b].StartDate })
+	}
+
+	return members, nil
+}
+
+// markFamilyDays lists on each calendar day the family members whose school
+// or childcare is closed
+func markFamilyDays(days []models.CalendarDay, members []models.FamilyMember) {
+	for i := range days {
+		for _, m := range members {
+			for _, closure := range m.Closures {
+				if days[i].Date >= closure.StartDate && days[i].Date <= closure.EndDate {
+					days[i].FamilyOff = append(days[i].FamilyOff, m.Name)
+					break
+				}
+			}
+		}
+	}
+}
+
+// familyBreaks returns the closures of the members planned around, as breaks
+// the optimizer prefers to place vacation in
+func familyBreaks(members []models.FamilyMember) []holidays.SchoolBreak {
+	var breaks []holidays.SchoolBreak
+	for _, m := range members {
+		if !m.PlanAround {
+			continue
+		}
+		for _, closure := range m.Closures {
+			breaks = append(breaks, holidays.SchoolBreak{
+				Name:      fmt.Sprintf("%s: %s", m.Name, closure.Name),
+				StartDate: closure.StartDate,
+				EndDate:   closure.EndDate,
+				Source:    closure.Source,
+			})
+		}
+	}
+	return breaks
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/flights"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// GetFlightPrices returns the upcoming suggested vacation blocks of a year,
+// each with an indicative round-trip price from the home airport, leaving on
+// the block's first day off and returning on its last. ?destination=
+// overrides the flight_destination setting.
+func (h *Handler) GetFlightPrices(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	ctx := c.Request.Context()
+	s := h.loadSettings(ctx)
+
+	provider, err := flights.New(flights.Config{Provider: s.FlightPriceProvider, APIKey: s.FlightAPIKey, APISecret: s.FlightAPISecret})
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeNotConfigured, err.Error())
+		return
+	}
+	if s.HomeAirport == "" {
+		problem(c, http.StatusBadRequest, models.CodeNotConfigured, "home_airport is not set")
+		return
+	}
+	origin, err := flights.NormalizeAirport(s.HomeAirport)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+	destination := c.DefaultQuery("destination", s.FlightDestination)
+	if destination == "" {
+		problem(c, http.StatusBadRequest, models.CodeNotConfigured, "No destination, pass ?destination= or set flight_destination")
+		return
+	}
+	destination, err = flights.NormalizeAirport(destination)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	calendar, err := h.Calendar(ctx, year)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Flights into the past cannot be booked, so only blocks ahead are priced
+	today := h.today(ctx).Format("2006-01-02")
+	blocks := []models.VacationBlock{}
+	for _, block := range calendar.VacationBlocks {
+		if block.Source == "manual" || block.StartDate <= today {
+			continue
+		}
+
+		depart, _ := dates.Parse(block.StartDate)
+		ret, _ := dates.Parse(block.EndDate)
+		quote, err := h.flightQuotes.RoundTrip(ctx, s.FlightPriceProvider, provider, origin, destination, depart, ret)
+		if err != nil && !errors.Is(err, flights.ErrNoOffers) {
+			problem(c, http.StatusBadGateway, models.CodeUpstreamError, err.Error())
+			return
+		}
+		if err == nil {
+			block.FlightPrice = &models.FlightQuote{
+				Origin:      quote.Origin,
+				Destination: quote.Destination,
+				DepartDate:  quote.DepartDate,
+				ReturnDate:  quote.ReturnDate,
+				Price:       quote.Price,
+				Currency:    quote.Currency,
+				Link:        quote.Link,
+			}
+		}
+		blocks = append(blocks, block)
+	}
+
+	c.JSON(http.StatusOK, models.FlightPriceResponse{
+		Year:        year,
+		Provider:    s.FlightPriceProvider,
+		Origin:      origin,
+		Destination: destination,
+		Blocks:      blocks,
+	})
+}
@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"regexp"
 	"sort"
@@ -14,21 +18,44 @@ import (
 
 	"github.com/gin-gonic/gin"
 	openai "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/jsonschema"
 
+	"github.com/bruno.lopes/calendar/backend/internal/auth"
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/events"
+	"github.com/bruno.lopes/calendar/backend/internal/flights"
 	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/locks"
 	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/notifications"
+	"github.com/bruno.lopes/calendar/backend/internal/oidc"
 	"github.com/bruno.lopes/calendar/backend/internal/optimizer"
+	"github.com/bruno.lopes/calendar/backend/internal/scheduler"
+	"github.com/bruno.lopes/calendar/backend/internal/settings"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+	"github.com/bruno.lopes/calendar/backend/internal/webhooks"
 )
 
 type Handler struct {
 	db             *sql.DB
+	store          *store.Store
 	holidayService *holidays.HolidayService
+	locks          *locks.Manager
+	events         *events.Bus
+	webhooks       *webhooks.Dispatcher
+	notifier       *notifications.Notifier
+	scheduler      *scheduler.Scheduler
+	flightQuotes   *flights.Cache
+	sheetSync      *sheetSyncer
+	live           *liveHub
+	guard          *auth.Guard
+	logins         *oidc.Logins // Single sign-ins in progress
+	tenant         string       // Organization served, empty outside multi-tenant mode
 }
 
 // isHoliday checks if a given date string is a holiday
-func (h *Handler) isHoliday(dateStr string, year int) bool {
-	workCity := h.getWorkCity()
-	holidayList := holidays.GetPortugueseHolidaysWithCity(year, workCity)
+func (h *Handler) isHoliday(ctx context.Context, dateStr string, year int) bool {
+	holidayList := h.holidaysForYear(ctx, year)
 	for _, holiday := range holidayList {
 		if holiday.Date == dateStr {
 			return true
@@ -38,17 +65,140 @@ func (h *Handler) isHoliday(dateStr string, year int) bool {
 }
 
 func NewHandler(db *sql.DB) *Handler {
-	return &Handler{
+	h := &Handler{
 		db:             db,
+		store:          store.New(db),
 		holidayService: holidays.NewHolidayService(db),
+		locks:          locks.NewManager(locks.DefaultTTL),
+		events:         events.NewBus(),
+		webhooks:       webhooks.NewDispatcher(db),
+		notifier:       notifications.NewNotifier(db),
+		scheduler:      scheduler.New(),
+		flightQuotes:   flights.NewCache(6 * time.Hour),
+		sheetSync:      newSheetSyncer(),
+		live:           newLiveHub(),
+		guard:          auth.NewGuard(),
+		logins:         oidc.NewLogins(),
+	}
+
+	// Forward calendar events to the configured webhooks, notification channels,
+	// Google Sheet and live clients, and re-evaluate the booking rules when
+	// holidays change
+	h.events.Subscribe(h.webhooks.Handle)
+	h.events.Subscribe(h.notifier.Handle)
+	h.events.Subscribe(h.syncSheetOnChange)
+	h.events.Subscribe(h.evaluatePoliciesOnChange)
+	h.events.Subscribe(h.live.publish)
+	h.webhooks.Start()
+
+	// The notifier stores the VAPID keys it generates
+	h.notifier.SetSettingsChangedHandler(h.store.Settings.Invalidate)
+	h.notifier.SetHolidaySource(h.holidaysForYear)
+
+	h.holidayService.SetRecoveryHandler(func(year int) {
+		h.events.Publish(events.HolidaysRecovered, year, nil)
+	})
+	h.holidayService.SetFailureHandler(func(year int) {
+		h.events.Publish(events.HolidaysFailed, year, nil)
+	})
+	h.applyRetryPolicy(context.Background())
+
+	h.registerJobs()
+	h.scheduler.Start()
+
+	return h
+}
+
+// Close stops the handler's background work: scheduled jobs, webhook
+// deliveries, sheet syncs and holiday retries, and releases its prepared
+// statements
+func (h *Handler) Close() {
+	h.scheduler.Stop()
+	h.webhooks.Stop()
+	h.sheetSync.stop()
+	h.live.stop()
+	h.holidayService.StopAllRetries()
+	h.store.Close()
+}
+
+// SetTenant marks the handler as serving one organization of a
+// multi-tenant instance. Process-wide settings, such as the Calendarific
+// key, then can't be changed through it.
+func (h *Handler) SetTenant(name string) {
+	h.tenant = name
+}
+
+// instanceWide reports whether a setting is shared by every tenant of the
+// instance, and so not changed per tenant
+func (h *Handler) instanceWide(key string) bool {
+	return h.tenant != "" && key == "calendarific_api_key"
+}
+
+// HolidayService returns the handler's holiday service
+func (h *Handler) HolidayService() *holidays.HolidayService {
+	return h.holidayService
+}
+
+// holidaysForYear returns the holidays for the work city, including observed
+// holidays from the configured substitution policy and without the holidays
+// marked as worked
+func (h *Handler) holidaysForYear(ctx context.Context, year int) []holidays.PortugueseHoliday {
+	return h.applyHolidayRules(ctx, year, h.holidayService.Holidays(year, h.getWorkCity(ctx)))
+}
+
+// getHolidaySubstitution returns the policy for holidays that fall on weekends
+func (h *Handler) getHolidaySubstitution(ctx context.Context) string {
+	return h.store.Settings.Value(ctx, "holiday_substitution")
+}
+
+// loadSettings returns the typed settings, with defaults for unset values
+func (h *Handler) loadSettings(ctx context.Context) settings.Settings {
+	values, _ := h.store.Settings.All(ctx)
+	return settings.Parse(values)
+}
+
+// today returns the current date in the user's time zone. Compare it with
+// parsed dates, never with time.Now(), so days near midnight are not
+// counted as past or future by mistake.
+func (h *Handler) today(ctx context.Context) time.Time {
+	return dates.Today(dates.Location(h.loadSettings(ctx).Timezone))
+}
+
+// LoadSettings reads the settings into the cache
+func (h *Handler) LoadSettings(ctx context.Context) error {
+	_, err := h.store.Settings.All(ctx)
+	return err
+}
+
+// applyRetryPolicy configures the holiday service's background retries from
+// the settings
+func (h *Handler) applyRetryPolicy(ctx context.Context) {
+	s := h.loadSettings(ctx)
+	h.holidayService.SetRetryPolicy(holidays.RetryPolicy{
+		MaxRetries: s.HolidayRetryMaxRetries,
+		BaseDelay:  time.Duration(s.HolidayRetryBaseSeconds) * time.Second,
+		MaxDelay:   time.Duration(s.HolidayRetryMaxDelaySeconds) * time.Second,
+	})
+}
+
+// changeWorkCity drops the holidays loaded for the previous work city and
+// loads the new city's holidays in the background, for the current year and
+// the years covered by the startup pre-fetch
+func (h *Handler) changeWorkCity(ctx context.Context, previousCity, city string) {
+	if previousCity == city {
+		return
 	}
+	h.holidayService.ChangeCity(previousCity)
+
+	s := h.loadSettings(ctx)
+	currentYear := dates.Today(dates.Location(s.Timezone)).Year()
+	log.Printf("Work city changed to %q, loading holidays for %d-%d", city, currentYear, currentYear+s.HolidayPrefetchYears)
+	go h.holidayService.Prefetch(currentYear, s.HolidayPrefetchYears, city)
 }
 
 // getWorkCity returns the configured work city for municipal holidays
-func (h *Handler) getWorkCity() string {
-	var city string
-	h.db.QueryRow(`SELECT value FROM settings WHERE key = 'work_city'`).Scan(&city)
-	return city
+func (h *Handler) getWorkCity(ctx context.Context) string {
+	return h.store.Settings.Value(ctx, "work_city")
 }
 
 // GetCalendar returns the full calendar for a year
@@ -56,61 +206,121 @@ func (h *Handler) GetCalendar(c *gin.Context) {
 	yearStr := c.Param("year")
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 
-	// Get or create year config
-	config, err := h.getOrCreateYearConfig(year)
+	response, err := h.Calendar(c.Request.Context(), year)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	c.JSON(http.StatusOK, response)
+}
+
+// Calendar builds the full calendar for a year
+func (h *Handler) Calendar(ctx context.Context, year int) (models.CalendarResponse, error) {
+	if err := checkYear(year); err != nil {
+		return models.CalendarResponse{}, err
+	}
+
+	// Get or create year config
+	config, err := h.getOrCreateYearConfig(ctx, year)
+	if err != nil {
+		return models.CalendarResponse{}, err
+	}
+
 	// Get holidays with work city for municipal holidays
-	workCity := h.getWorkCity()
-	holidayList := holidays.GetPortugueseHolidaysWithCity(year, workCity)
+	workCity := h.getWorkCity(ctx)
+	holidayList := h.holidayService.Holidays(year, workCity)
 	
 	// Store holidays in database
-	for _, hol := range holidayList {
-		h.db.Exec(`INSERT OR IGNORE INTO holidays (year, date, name, type) VALUES (?, ?, ?, ?)`,
-			year, hol.Date, hol.Name, hol.Type)
-	}
+	h.store.Holidays.Cache(ctx, year, holidayList)
+
+	// Add observed holidays and drop worked ones (not stored, they depend on settings)
+	holidayList = h.applyHolidayRules(ctx, year, holidayList)
 
 	// Get manual vacations
-	manualVacations, _ := h.getVacations(year)
+	manualVacations, _ := h.store.Vacations.List(ctx, year)
 
 	// Get optimal vacations
-	optimalVacations, _ := h.getOptimalVacations(year)
+	optimalVacations, _ := h.store.Vacations.ListOptimal(ctx, year)
+
+	// Comp days taken off join the holidays as days off in vacation blocks
+	compDaysOff := h.compPool(ctx, year).SpentDates()
 
 	// Build calendar days
 	days := h.buildCalendarDays(year, config, holidayList, manualVacations, optimalVacations)
+	markCompDays(days, compDaysOff)
+	markBridgeCandidates(days)
+	locked, err := h.lockedDates(ctx, year)
+	if err != nil {
+		return models.CalendarResponse{}, err
+	}
+	markLockedDays(days, locked)
 
-	// Calculate summary
-	summary := h.calculateSummary(config.VacationDays, manualVacations, optimalVacations, holidayList)
+	// Group vacation days into blocks
+	blocks := h.buildVacationBlocks(year, config, withCompDays(holidayList, compDaysOff), manualVacations, optimalVacations)
+
+	// Tie days and blocks to the trips planned over them
+	trips, err := h.tripsForYear(ctx, year)
+	if err != nil {
+		return models.CalendarResponse{}, err
+	}
+	linkTrips(trips, days, blocks)
+
+	// Name and color the blocks
+	labels, err := h.store.Blocks.Labels(ctx, year)
+	if err != nil {
+		return models.CalendarResponse{}, err
+	}
+	labelBlocks(blocks, labels)
+
+	// Overlay the days the family's schools and childcare are closed
+	family, err := h.familyForYear(ctx, year)
+	if err != nil {
+		return models.CalendarResponse{}, err
+	}
+	markFamilyDays(days, family)
+
+	// Show the personal events on their days
+	personalEvents, err := h.store.Events.List(ctx, year)
+	if err != nil {
+		return models.CalendarResponse{}, err
+	}
+	markEventDays(days, personalEvents)
+
+	// Calculate summary (the compensation pool covers vacation beyond the annual leave)
+	summary := h.calculateSummary(ctx, year, config.VacationDays, days, manualVacations, optimalVacations, holidayList, blocks)
+	h.applyHoursBalance(ctx, &summary, year, config, manualVacations, optimalVacations)
+	h.flagUnusedDays(ctx, &summary, year, config, days)
 
 	// Convert holidays to model
 	var modelHolidays []models.Holiday
 	for _, hol := range holidayList {
 		modelHolidays = append(modelHolidays, models.Holiday{
-			Year: year,
-			Date: hol.Date,
-			Name: hol.Name,
-			Type: hol.Type,
+			Year:        year,
+			Date:        hol.Date,
+			Name:        hol.Name,
+			Type:        hol.Type,
+			ObservedFor: hol.ObservedFor,
 		})
 	}
 
-	response := models.CalendarResponse{
+	return models.CalendarResponse{
 		Year:             year,
 		Config:           config,
 		Days:             days,
 		Holidays:         modelHolidays,
+		VacationBlocks:   blocks,
 		ManualVacations:  manualVacations,
 		OptimalVacations: optimalVacations,
+		Trips:            trips,
+		Family:           family,
+		PersonalEvents:   personalEvents,
 		Summary:          summary,
-	}
-
-	c.JSON(http.StatusOK, response)
+	}, nil
 }
 
 // OptimizeVacations calculates optimal vacation days
@@ -118,104 +328,194 @@ func (h *Handler) OptimizeVacations(c *gin.Context) {
 	yearStr := c.Param("year")
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 
-	config, err := h.getOrCreateYearConfig(year)
+	blocks, results, err := h.Optimize(c.Request.Context(), year)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if results != nil {
+			respondError(c, err, gin.H{"results": results})
+		} else {
+			respondError(c, err)
+		}
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"blocks": blocks,
+		"message": "Optimization complete",
+		"results": results,
+	})
+}
+
+// Optimize runs the year's optimization strategy and replaces the stored
+// optimal vacations with the result. On a storage failure the results report
+// which dates were rolled back.
+func (h *Handler) Optimize(ctx context.Context, year int) ([]models.VacationBlock, []models.BulkItemResult, error) {
+	if err := checkYear(year); err != nil {
+		return nil, nil, err
+	}
+
+	config, err := h.getOrCreateYearConfig(ctx, year)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Get manual vacations to exclude
-	manualVacations, _ := h.getVacations(year)
+	manualVacations, _ := h.store.Vacations.List(ctx, year)
 	var manualDates []string
 	for _, v := range manualVacations {
 		manualDates = append(manualDates, v.Date)
 	}
 
+	// Past and locked optimized days stay as they are, like manual ones, and
+	// past or locked working days are never suggested
+	from := h.editableFrom(ctx)
+	locked, err := h.lockedDates(ctx, year)
+	if err != nil {
+		return nil, nil, err
+	}
+	optimalVacations, _ := h.store.Vacations.ListOptimal(ctx, year)
+	for _, v := range optimalVacations {
+		if locked[v.Date] || v.Date < from {
+			manualDates = append(manualDates, v.Date)
+		}
+	}
+
 	// Calculate available days for optimizer (total - reserved - manual)
-	availableDays := config.VacationDays - config.ReservedDays - len(manualDates)
-	if availableDays < 0 {
-		availableDays = 0
+	availableDays := h.availableVacationDays(ctx, year, config, manualDates)
+
+	// Days of declined blocks are not suggested again
+	excludedDates, err := h.declinedDates(ctx, year)
+	if err != nil {
+		return nil, nil, err
+	}
+	for date := range locked {
+		if !contains(manualDates, date) {
+			excludedDates = append(excludedDates, date)
+		}
+	}
+	excludedDates = append(excludedDates, blackoutDates(config)...)
+
+	// Load school breaks when the plan should align with them
+	var schoolBreaks []holidays.SchoolBreak
+	if config.AlignSchoolBreaks {
+		schoolBreaks, _ = h.holidayService.LoadSchoolBreaks(year, h.getSchoolDistrict(ctx))
+	}
+	// Family members planned around add their closures as breaks to align with
+	if family, err := h.familyForYear(ctx, year); err == nil {
+		schoolBreaks = append(schoolBreaks, familyBreaks(family)...)
+	}
+
+	// Personal events draw vacation to their dates or keep it away
+	personalEvents, err := h.store.Events.List(ctx, year)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Run regular optimizer with city-specific holidays
+	heuristic := func(strategy string) []models.VacationBlock {
+		// Built without NewOptimizerWithCity, whose holidays come from the
+		// shared cache rather than this server's database
+		opt := &optimizer.Optimizer{Year: year, VacationDays: availableDays, WorkWeek: config.WorkWeek, Strategy: strategy}
+		opt.SetHolidays(h.daysOffForYear(ctx, year))
+		opt.SetWorkWeekChanges(config.WorkWeekChanges)
+		opt.SetManualVacations(manualDates)
+		opt.SetExcludedDates(excludedDates)
+		opt.SetStartDate(from)
+		opt.SetSchoolBreaks(schoolBreaks)
+		opt.SetTimeBudget(time.Duration(h.loadSettings(ctx).OptimizerTimeBudgetMS) * time.Millisecond)
+		opt.SetScoringWeights(config.ScoringWeights)
+		opt.SetMaxConsecutiveDays(h.maxConsecutiveDays(ctx))
+		opt.SetEventWeights(avoidWeights(eventWeights(personalEvents), config))
+		return opt.Optimize()
 	}
 
 	var blocks []models.VacationBlock
+	var model string
 
-	// Check if using smart AI strategy
-	if config.OptimizationStrategy == models.StrategySmart {
-		blocks, err = h.smartOptimize(year, availableDays, config.WorkWeek, manualDates)
+	switch config.OptimizationStrategy {
+	case models.StrategySmart:
+		blocks, model, err = h.smartOptimize(ctx, year, availableDays, config, manualDates, excludedDates, schoolBreaks, personalEvents)
 		if err != nil {
+			log.Printf("Smart optimization of %d failed, using the balanced strategy: %v", year, err)
 			// Fallback to balanced strategy if AI fails
-			workCity := h.getWorkCity()
-			opt := optimizer.NewOptimizerWithCity(year, availableDays, config.WorkWeek, models.StrategyBalanced, workCity)
-			opt.SetManualVacations(manualDates)
-			blocks = opt.Optimize()
-		}
-	} else {
-		// Run regular optimizer with city-specific holidays
-		workCity := h.getWorkCity()
-		opt := optimizer.NewOptimizerWithCity(year, availableDays, config.WorkWeek, config.OptimizationStrategy, workCity)
-		opt.SetManualVacations(manualDates)
-		blocks = opt.Optimize()
+			blocks = heuristic(models.StrategyBalanced)
+		}
+	case models.StrategyHybrid:
+		// The balanced plan is refined by the AI, and kept when it fails
+		baseline := heuristic(models.StrategyBalanced)
+		blocks, model, err = h.hybridOptimize(ctx, year, availableDays, config, manualDates, excludedDates, baseline)
+		if err != nil {
+			log.Printf("Hybrid refinement of %d failed, keeping the balanced plan: %v", year, err)
+			blocks, model = baseline, ""
+		}
+	default:
+		blocks = heuristic(config.OptimizationStrategy)
 	}
 
-	// Clear previous optimal vacations
-	h.db.Exec("DELETE FROM optimal_vacations WHERE year = ?", year)
+	// Replace the previous optimal vacations in one transaction, so a failure
+	// keeps the old plan instead of leaving it half-stored
+	results := []models.BulkItemResult{}
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		if err := tx.Vacations.ClearOptimalUnlocked(ctx, year, from); err != nil {
+			return err
+		}
 
-	// Store new optimal vacations
-	blockID := 1
-	for _, block := range blocks {
-		for _, date := range block.Dates {
-			// Only store dates that require vacation days
-			if !contains(block.Weekends, date) && !contains(block.Holidays, date) && !contains(manualDates, date) {
-				h.db.Exec(`INSERT OR REPLACE INTO optimal_vacations (year, date, block_id, consecutive_days) VALUES (?, ?, ?, ?)`,
-					year, date, blockID, block.TotalDays)
+		// Store new optimal vacations
+		blockID := 1
+		for _, block := range blocks {
+			for _, date := range block.Dates {
+				// Only store dates that require vacation days
+				if !contains(block.Weekends, date) && !contains(block.Holidays, date) && !contains(manualDates, date) {
+					err := tx.Vacations.AddOptimal(ctx, models.OptimalVacation{Year: year, Date: date, BlockID: blockID, ConsecutiveDays: block.TotalDays})
+					if err != nil {
+						results = append(results, models.BulkItemResult{Date: date, Action: "store", Status: "failed", Error: err.Error()})
+						return err
+					}
+					results = append(results, models.BulkItemResult{Date: date, Action: "store", Status: "applied"})
+				}
 			}
+			blockID++
 		}
-		blockID++
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"blocks": blocks,
-		"message": "Optimization complete",
+		return nil
 	})
-}
-
-// smartOptimize uses AI to find optimal vacation combinations
-func (h *Handler) smartOptimize(year, availableDays int, workWeek, manualDates []string) ([]models.VacationBlock, error) {
-	// Get API key and provider
-	var apiKey string
-	err := h.db.QueryRow("SELECT value FROM settings WHERE key = 'openai_api_key'").Scan(&apiKey)
-	if err != nil || apiKey == "" {
-		return nil, fmt.Errorf("API key not configured")
+	if err != nil {
+		rollBackResults(results)
+		return nil, results, err
 	}
 
-	var aiProvider string
-	h.db.QueryRow("SELECT value FROM settings WHERE key = 'ai_provider'").Scan(&aiProvider)
-	if aiProvider == "" {
-		aiProvider = "github"
+	payload := gin.H{
+		"strategy": config.OptimizationStrategy,
+		"blocks":   blocks,
 	}
-
-	var selectedModel string
-	h.db.QueryRow("SELECT value FROM settings WHERE key = 'ai_model'").Scan(&selectedModel)
-	if selectedModel == "" {
-		selectedModel = "openai/gpt-4o-mini"
+	if model != "" {
+		payload["model"] = model
 	}
+	h.events.Publish(events.OptimizationCompleted, year, payload)
 
-	if aiProvider == "github" && !strings.Contains(selectedModel, "/") {
-		selectedModel = "openai/" + selectedModel
+	return blocks, results, nil
+}
+
+// smartOptimize uses AI to find optimal vacation combinations, returning
+// them with the model that answered
+func (h *Handler) smartOptimize(ctx context.Context, year, availableDays int, config models.YearConfig, manualDates, excludedDates []string, schoolBreaks []holidays.SchoolBreak, personalEvents []models.PersonalEvent) ([]models.VacationBlock, string, error) {
+	workWeek := config.WorkWeek
+
+	// Get API key, provider and model
+	settings := h.getAISettings(ctx)
+	if !settings.Configured() {
+		return nil, "", fmt.Errorf("API key not configured")
 	}
 
-	// Get holidays
-	workCity := h.getWorkCity()
-	holidayList := holidays.GetPortugueseHolidaysWithCity(year, workCity)
+	// Get holidays, comp days off included
+	holidayList := h.daysOffForYear(ctx, year)
 
 	// Build context for AI
 	var holidayInfo strings.Builder
 	for _, h := range holidayList {
-		date, _ := time.Parse("2006-01-02", h.Date)
+		date, _ := dates.Parse(h.Date)
 		holidayInfo.WriteString(fmt.Sprintf("- %s (%s): %s\n", h.Date, date.Weekday().String(), h.Name))
 	}
 
@@ -223,13 +523,66 @@ func (h *Handler) smartOptimize(year, availableDays int, workWeek, manualDates [
 	if len(manualDates) > 0 {
 		manualInfo = fmt.Sprintf("Already scheduled vacation days (do NOT include these): %s\n", strings.Join(manualDates, ", "))
 	}
+	if len(excludedDates) > 0 {
+		manualInfo += fmt.Sprintf("Days the user declined or locked (do NOT include these): %s\n", strings.Join(excludedDates, ", "))
+	}
+	from := h.editableFrom(ctx)
+	if from > fmt.Sprintf("%d-01-01", year) {
+		manualInfo += fmt.Sprintf("Days before %s are in the past (do NOT include these)\n", from)
+	}
+	maxRun := h.maxConsecutiveDays(ctx)
+	if maxRun > 0 {
+		manualInfo += fmt.Sprintf("Company policy: NEVER more than %d consecutive days off, weekends, holidays and scheduled vacation included\n", maxRun)
+	}
 
-	// Get optimizer notes from year config
-	var optimizerNotes string
-	h.db.QueryRow("SELECT COALESCE(optimizer_notes, '') FROM year_config WHERE year = ?", year).Scan(&optimizerNotes)
+	// Optimizer notes from the year config
 	var userNotesInfo string
-	if optimizerNotes != "" {
-		userNotesInfo = fmt.Sprintf("\nUSER PREFERENCES/NOTES (IMPORTANT - follow these instructions):\n%s\n", optimizerNotes)
+	if config.OptimizerNotes != "" {
+		userNotesInfo = fmt.Sprintf("\nUSER PREFERENCES/NOTES (IMPORTANT - follow these instructions):\n%s\n", config.OptimizerNotes)
+	}
+
+	// School breaks the user wants to align with (parents planning around kids)
+	if len(schoolBreaks) > 0 {
+		var schoolInfo strings.Builder
+		schoolInfo.WriteString("\nSCHOOL BREAKS (prefer placing vacation days inside these periods):\n")
+		for _, b := range schoolBreaks {
+			schoolInfo.WriteString(fmt.Sprintf("- %s: %s to %s\n", b.Name, b.StartDate, b.EndDate))
+		}
+		userNotesInfo += schoolInfo.String()
+	}
+
+	// Personal events attract vacation (positive weight) or repel it (negative)
+	var eventInfo strings.Builder
+	for _, event := range personalEvents {
+		switch {
+		case event.Weight > 0:
+			eventInfo.WriteString(fmt.Sprintf("- %s: %s (weight %d, prefer vacation on or around this day)\n", event.Date, event.Name, event.Weight))
+		case event.Weight < 0:
+			eventInfo.WriteString(fmt.Sprintf("- %s: %s (weight %d, keep vacation away from this day)\n", event.Date, event.Name, event.Weight))
+		}
+	}
+	if eventInfo.Len() > 0 {
+		userNotesInfo += "\nPERSONAL EVENTS (higher weights matter more):\n" + eventInfo.String()
+	}
+
+	// Avoid periods, such as release weeks, can be booked but better not
+	if len(config.AvoidPeriods) > 0 {
+		var avoidInfo strings.Builder
+		avoidInfo.WriteString("\nPERIODS TO AVOID (keep vacation out of these unless there is no good alternative, higher weights matter more):\n")
+		for _, p := range config.AvoidPeriods {
+			avoidInfo.WriteString(fmt.Sprintf("- %s to %s: %s (weight %d)\n", p.StartDate, p.EndDate, p.Reason, p.Weight))
+		}
+		userNotesInfo += avoidInfo.String()
+	}
+
+	// Work week changes during the year (the work days below apply until the first one)
+	if len(config.WorkWeekChanges) > 0 {
+		var changeInfo strings.Builder
+		changeInfo.WriteString("\nWORK WEEK CHANGES (from these dates on, ONLY these days are work days):\n")
+		for _, change := range config.WorkWeekChanges {
+			changeInfo.WriteString(fmt.Sprintf("- From %s: %v\n", change.EffectiveFrom, change.WorkWeek))
+		}
+		userNotesInfo += changeInfo.String()
 	}
 
 	// Determine weekend days (days not in work week)
@@ -278,98 +631,372 @@ IMPORTANT VALIDATION RULES:
 - Each date must NOT be a weekend day (%v)
 - Each date must NOT be a holiday
 
-RESPOND WITH ONLY a JSON array of vacation day dates in YYYY-MM-DD format.
-Example: ["2026-01-02", "2026-04-06", "2026-12-28"]
+RESPOND WITH a JSON object of vacation days: each day has its date in YYYY-MM-DD format and a one-sentence rationale.
+Example: {"days": [{"date": "2026-04-06", "rationale": "Bridges Easter Monday into a 4-day weekend"}]}
 
 Analyze each holiday's day of the week and find the optimal bridging strategy.
-Return EXACTLY %d dates as a JSON array, nothing else.`, year, availableDays, workWeek, weekendDays, availableDays, manualInfo, userNotesInfo, holidayInfo.String(), weekendDays, workWeek, weekendDays, availableDays)
-
-	// Create AI client
-	var client *openai.Client
-	switch aiProvider {
-	case "github":
-		config := openai.DefaultConfig(apiKey)
-		config.BaseURL = "https://models.github.ai/inference"
-		client = openai.NewClientWithConfig(config)
-	case "openai":
-		client = openai.NewClient(apiKey)
-	default:
-		config := openai.DefaultConfig(apiKey)
-		config.BaseURL = "https://models.github.ai/inference"
-		client = openai.NewClientWithConfig(config)
+Return EXACTLY %d dates, nothing else.`, year, availableDays, workWeek, weekendDays, availableDays, manualInfo, userNotesInfo, holidayInfo.String(), weekendDays, workWeek, weekendDays, availableDays)
+
+	check := smartCheck{
+		year:          year,
+		availableDays: availableDays,
+		config:        config,
+		holidays:      holidayList,
+		manualDates:   manualDates,
+		excludedDates: excludedDates,
+		from:          from,
+		maxRun:        maxRun,
+	}
+	validDates, rationales, model, err := h.askSmartDays(ctx, settings, prompt, check)
+	if err != nil {
+		return nil, "", err
 	}
 
-	resp, err := client.CreateChatCompletion(
-		context.Background(),
-		openai.ChatCompletionRequest{
-			Model: selectedModel,
-			Messages: []openai.ChatCompletionMessage{
-				{Role: openai.ChatMessageRoleUser, Content: prompt},
-			},
-			Temperature: 0.3, // Lower temperature for more deterministic results
-		},
-	)
+	// Convert dates to vacation blocks, with why each day was picked
+	blocks, err := h.datesToBlocks(year, validDates, holidayList, config)
+	return withRationales(blocks, rationales), model, err
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("AI request failed: %w", err)
+// hybridOptimize asks the AI to adjust the vacation days of a baseline plan
+// to the user's notes, returning the refined blocks with the model that
+// answered. The prompt holds the baseline and the notes rather than the
+// whole optimization brief, and without notes there is nothing to refine:
+// the baseline is returned without calling the AI.
+func (h *Handler) hybridOptimize(ctx context.Context, year, availableDays int, config models.YearConfig, manualDates, excludedDates []string, baseline []models.VacationBlock) ([]models.VacationBlock, string, error) {
+	if strings.TrimSpace(config.OptimizerNotes) == "" {
+		return baseline, "", nil
 	}
 
-	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from AI")
+	settings := h.getAISettings(ctx)
+	if !settings.Configured() {
+		return nil, "", fmt.Errorf("API key not configured")
 	}
 
-	// Parse AI response
-	responseText := resp.Choices[0].Message.Content
-	
-	// Extract JSON array from response
-	jsonRegex := regexp.MustCompile(`\[[\s\S]*?\]`)
-	jsonMatch := jsonRegex.FindString(responseText)
-	if jsonMatch == "" {
-		return nil, fmt.Errorf("could not parse AI response")
+	holidayList := h.daysOffForYear(ctx, year)
+	holidayDates := make([]string, 0, len(holidayList))
+	for _, hol := range holidayList {
+		holidayDates = append(holidayDates, hol.Date)
 	}
 
-	var vacationDates []string
-	if err := json.Unmarshal([]byte(jsonMatch), &vacationDates); err != nil {
-		return nil, fmt.Errorf("failed to parse vacation dates: %w", err)
+	// The days the baseline spends, as stored by Optimize
+	var baselineDays []string
+	for _, block := range baseline {
+		for _, date := range block.Dates {
+			if !contains(block.Weekends, date) && !contains(block.Holidays, date) && !contains(manualDates, date) {
+				day, _ := dates.Parse(date)
+				baselineDays = append(baselineDays, fmt.Sprintf("%s (%s)", date, day.Weekday()))
+			}
+		}
 	}
 
-	// Create work day lookup for validation
-	workDayMap := make(map[string]bool)
-	for _, d := range workWeek {
-		workDayMap[strings.ToLower(d)] = true
+	from := h.editableFrom(ctx)
+	unavailable := append(append([]string{}, manualDates...), excludedDates...)
+	sort.Strings(unavailable)
+	var pastRule string
+	if from > fmt.Sprintf("%d-01-01", year) {
+		pastRule = fmt.Sprintf(", nor before %s", from)
+	}
+	maxRun := h.maxConsecutiveDays(ctx)
+	if maxRun > 0 {
+		pastRule += fmt.Sprintf(". Never more than %d consecutive days off, weekends and holidays included", maxRun)
+	}
+
+	prompt := fmt.Sprintf(`Adjust this vacation plan for year %d to the user's notes. Change only the days the notes call for and keep the rest.
+
+BASELINE PLAN (%d days): %s
+
+USER NOTES:
+%s
+
+RULES: exactly %d days, on work days (%v) only. Not on holidays (%s), nor on unavailable days (%s)%s.
+
+RESPOND WITH a JSON object of vacation days: each day has its date in YYYY-MM-DD format and a one-sentence rationale, saying what changed from the baseline when it did.
+Return EXACTLY %d dates, nothing else.`, year, len(baselineDays), strings.Join(baselineDays, ", "), config.OptimizerNotes,
+		availableDays, config.WorkWeek, strings.Join(holidayDates, ", "), strings.Join(unavailable, ", "), pastRule, availableDays)
+
+	check := smartCheck{
+		year:          year,
+		availableDays: availableDays,
+		config:        config,
+		holidays:      holidayList,
+		manualDates:   manualDates,
+		excludedDates: excludedDates,
+		from:          from,
+		maxRun:        maxRun,
+	}
+	validDates, rationales, model, err := h.askSmartDays(ctx, settings, prompt, check)
+	if err != nil {
+		return nil, "", err
 	}
 
-	// Create holiday lookup for validation
+	blocks, err := h.datesToBlocks(year, validDates, holidayList, config)
+	return withRationales(blocks, rationales), model, err
+}
+
+// smartCheck holds what the vacation days picked by the AI optimizers must
+// respect
+type smartCheck struct {
+	year          int
+	availableDays int
+	config        models.YearConfig
+	holidays      []holidays.PortugueseHoliday
+	manualDates   []string
+	excludedDates []string
+	from          string
+	maxRun        int // Longest run of days off, 0 for no limit
+}
+
+// check keeps the valid days of an answer and says what is wrong with the
+// others and with their count
+func (c smartCheck) check(days []smartDay) (validDates []string, rationales map[string]string, violations []string) {
 	holidayMap := make(map[string]bool)
-	for _, hol := range holidayList {
+	for _, hol := range c.holidays {
 		holidayMap[hol.Date] = true
 	}
 
-	// Filter out any invalid dates (weekends or holidays)
+	rationales = make(map[string]string)
+	seen := make(map[string]bool)
+	for _, day := range days {
+		dateStr := day.Date
+		date, err := dates.Parse(dateStr)
+		switch {
+		case err != nil || date.Year() != c.year:
+			violations = append(violations, fmt.Sprintf("%q is not a date of %d", dateStr, c.year))
+		case seen[dateStr]:
+			violations = append(violations, fmt.Sprintf("%s is listed more than once", dateStr))
+		case !c.config.IsWorkDay(date):
+			// Skip if it's a weekend (not a work day on that date)
+			violations = append(violations, fmt.Sprintf("%s is a %s, not a work day", dateStr, date.Weekday()))
+		case holidayMap[dateStr]:
+			violations = append(violations, fmt.Sprintf("%s is a holiday", dateStr))
+		case contains(c.manualDates, dateStr):
+			violations = append(violations, fmt.Sprintf("%s is already scheduled", dateStr))
+		case contains(c.excludedDates, dateStr):
+			// Days the user declined or locked
+			violations = append(violations, fmt.Sprintf("%s was declined or is locked", dateStr))
+		case dateStr < c.from:
+			violations = append(violations, fmt.Sprintf("%s is in the past", dateStr))
+		default:
+			validDates = append(validDates, dateStr)
+			if day.Rationale != "" {
+				rationales[dateStr] = day.Rationale
+			}
+		}
+		seen[dateStr] = true
+	}
+	if c.maxRun > 0 {
+		var runViolations []string
+		validDates, runViolations = c.dropLongRuns(validDates, holidayMap)
+		violations = append(violations, runViolations...)
+	}
+	if len(validDates) != c.availableDays {
+		violations = append(violations, fmt.Sprintf("%d valid days were returned, EXACTLY %d are needed", len(validDates), c.availableDays))
+	}
+	return validDates, rationales, violations
+}
+
+// dropLongRuns removes the days of the runs of days off longer than maxRun,
+// weekends, holidays and manual vacations included, saying which runs they
+// were
+func (c smartCheck) dropLongRuns(validDates []string, holidayMap map[string]bool) (kept []string, violations []string) {
+	picked := make(map[string]bool, len(validDates))
+	for _, date := range validDates {
+		picked[date] = true
+	}
+
+	drop := make(map[string]bool)
+	start := time.Date(c.year, 1, 1, 0, 0, 0, 0, time.UTC)
+	var run []string
+	closeRun := func() {
+		var vacation []string
+		for _, date := range run {
+			if picked[date] {
+				vacation = append(vacation, date)
+			}
+		}
+		if len(vacation) > 0 && len(run) > c.maxRun {
+			violations = append(violations, fmt.Sprintf("%s to %s is %d consecutive days off, more than the %d allowed", run[0], run[len(run)-1], len(run), c.maxRun))
+			for _, date := range vacation {
+				drop[date] = true
+			}
+		}
+		run = nil
+	}
+	for day := start; day.Year() == c.year; day = day.AddDate(0, 0, 1) {
+		dateStr := dates.Format(day)
+		if !c.config.IsWorkDay(day) || holidayMap[dateStr] || picked[dateStr] || contains(c.manualDates, dateStr) {
+			run = append(run, dateStr)
+			continue
+		}
+		closeRun()
+	}
+	closeRun()
+
+	for _, date := range validDates {
+		if !drop[date] {
+			kept = append(kept, date)
+		}
+	}
+	return kept, violations
+}
+
+// askSmartDays asks the AI for vacation days in the smart days schema and
+// returns the valid ones, why each was picked and the model that answered.
+// Models without structured outputs reject the response format, so they are
+// asked again without it. Invalid days are sent back to the model for
+// correction, up to the configured rounds, and the answer with the most
+// valid days is kept.
+func (h *Handler) askSmartDays(ctx context.Context, settings aiSettings, prompt string, check smartCheck) ([]string, map[string]string, string, error) {
+	request := openai.ChatCompletionRequest{
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, Content: prompt},
+		},
+		Temperature:    0.3, // Lower temperature for more deterministic results
+		ResponseFormat: smartDaysFormat,
+	}
+	rounds := h.loadSettings(ctx).AICorrectionRounds
+
 	var validDates []string
-	for _, dateStr := range vacationDates {
-		date, err := time.Parse("2006-01-02", dateStr)
+	var rationales map[string]string
+	var model string
+	var answered bool
+	for round := 0; ; round++ {
+		resp, answeredBy, err := h.completeChat(ctx, settings, request)
+		if err != nil && request.ResponseFormat != nil && providerStatus(err) == http.StatusBadRequest {
+			request.ResponseFormat = nil
+			resp, answeredBy, err = h.completeChat(ctx, settings, request)
+		}
 		if err != nil {
-			continue
+			if answered {
+				log.Printf("Smart optimization correction of %d failed, keeping the previous answer: %v", check.year, err)
+				break
+			}
+			return nil, nil, "", fmt.Errorf("AI request failed: %w", err)
 		}
-		dayName := strings.ToLower(date.Weekday().String())
-		// Skip if it's a weekend (not a work day)
-		if !workDayMap[dayName] {
-			continue
+		answer := resp.Choices[0].Message.Content
+
+		var violations []string
+		days, err := parseSmartDays(answer)
+		if err != nil {
+			violations = []string{"the answer is not the JSON object of vacation days"}
+		} else {
+			roundDates, roundRationales, roundViolations := check.check(days)
+			if !answered || len(roundDates) > len(validDates) {
+				validDates, rationales, model = roundDates, roundRationales, answeredBy
+			}
+			answered = true
+			violations = roundViolations
 		}
-		// Skip if it's a holiday
-		if holidayMap[dateStr] {
-			continue
+
+		if len(violations) == 0 || round >= rounds {
+			if !answered {
+				return nil, nil, "", err
+			}
+			if len(violations) > 0 {
+				log.Printf("Smart optimization of %d kept %d of %d days after %d corrections: %s", check.year, len(validDates), check.availableDays, rounds, strings.Join(violations, "; "))
+			}
+			break
+		}
+
+		request.Messages = append(request.Messages,
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: answer},
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: fmt.Sprintf(`Your answer breaks the constraints:
+- %s
+
+Correct it: replace the invalid days with valid work days, keep the valid ones, and answer again with the JSON object of vacation days.
+Return EXACTLY %d dates, nothing else.`, strings.Join(violations, "\n- "), check.availableDays)},
+		)
+	}
+
+	// Keep at most the budget when the model picked too many
+	if len(validDates) > check.availableDays {
+		validDates = validDates[:check.availableDays]
+	}
+	return validDates, rationales, model, nil
+}
+
+// withRationales adds to each block why the AI picked its days
+func withRationales(blocks []models.VacationBlock, rationales map[string]string) []models.VacationBlock {
+	for i := range blocks {
+		for _, date := range blocks[i].Dates {
+			if rationale, ok := rationales[date]; ok {
+				if blocks[i].Rationales == nil {
+					blocks[i].Rationales = make(map[string]string)
+				}
+				blocks[i].Rationales[date] = rationale
+			}
 		}
-		validDates = append(validDates, dateStr)
 	}
+	return blocks
+}
 
-	// Convert dates to vacation blocks
-	return h.datesToBlocks(year, validDates, holidayList, workWeek)
+// smartDay is a vacation day picked by the AI optimizer
+type smartDay struct {
+	Date      string `json:"date"`
+	Rationale string `json:"rationale"`
+}
+
+// smartDaysFormat asks for the vacation days as a JSON object matching a
+// strict schema, {"days": [{"date": ..., "rationale": ...}]}
+var smartDaysFormat = &openai.ChatCompletionResponseFormat{
+	Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+	JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+		Name:        "vacation_days",
+		Description: "Vacation days to take, each with why it was picked",
+		Strict:      true,
+		Schema: &jsonschema.Definition{
+			Type:                 jsonschema.Object,
+			AdditionalProperties: false,
+			Required:             []string{"days"},
+			Properties: map[string]jsonschema.Definition{
+				"days": {
+					Type: jsonschema.Array,
+					Items: &jsonschema.Definition{
+						Type:                 jsonschema.Object,
+						AdditionalProperties: false,
+						Required:             []string{"date", "rationale"},
+						Properties: map[string]jsonschema.Definition{
+							"date":      {Type: jsonschema.String, Description: "Vacation day in YYYY-MM-DD format"},
+							"rationale": {Type: jsonschema.String, Description: "One sentence on why the day was picked"},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+// smartArrayRegex finds a bare JSON array in a free text answer
+var smartArrayRegex = regexp.MustCompile(`\[[\s\S]*?\]`)
+
+// parseSmartDays reads the vacation days of an AI optimizer answer: the
+// schema's object, or a JSON array of dates in the text of models that
+// answered without structured outputs
+func parseSmartDays(text string) ([]smartDay, error) {
+	var answer struct {
+		Days []smartDay `json:"days"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(text)), &answer); err == nil && answer.Days != nil {
+		return answer.Days, nil
+	}
+
+	match := smartArrayRegex.FindString(text)
+	if match == "" {
+		return nil, fmt.Errorf("could not parse AI response")
+	}
+	var dateList []string
+	if err := json.Unmarshal([]byte(match), &dateList); err != nil {
+		return nil, fmt.Errorf("failed to parse vacation dates: %w", err)
+	}
+	days := make([]smartDay, 0, len(dateList))
+	for _, date := range dateList {
+		days = append(days, smartDay{Date: date})
+	}
+	return days, nil
 }
 
 // datesToBlocks converts a list of vacation dates to VacationBlock structures
-func (h *Handler) datesToBlocks(year int, vacationDates []string, holidayList []holidays.PortugueseHoliday, workWeek []string) ([]models.VacationBlock, error) {
+func (h *Handler) datesToBlocks(year int, vacationDates []string, holidayList []holidays.PortugueseHoliday, config models.YearConfig) ([]models.VacationBlock, error) {
 	if len(vacationDates) == 0 {
 		return nil, nil
 	}
@@ -380,15 +1007,9 @@ func (h *Handler) datesToBlocks(year int, vacationDates []string, holidayList []
 		holidayMap[hol.Date] = true
 	}
 
-	// Create work day lookup
-	workDayMap := make(map[string]bool)
-	for _, d := range workWeek {
-		workDayMap[strings.ToLower(d)] = true
-	}
-
+	// Days outside the work week in effect on each date
 	isWeekend := func(date time.Time) bool {
-		dayName := strings.ToLower(date.Weekday().String())
-		return !workDayMap[dayName]
+		return !config.IsWorkDay(date)
 	}
 
 	// Sort vacation dates
@@ -398,7 +1019,7 @@ func (h *Handler) datesToBlocks(year int, vacationDates []string, holidayList []
 	var blocks []models.VacationBlock
 	
 	for _, vacDateStr := range vacationDates {
-		vacDate, err := time.Parse("2006-01-02", vacDateStr)
+		vacDate, err := dates.Parse(vacDateStr)
 		if err != nil {
 			continue
 		}
@@ -406,7 +1027,7 @@ func (h *Handler) datesToBlocks(year int, vacationDates []string, holidayList []
 		// Find or create block that this date extends
 		added := false
 		for i := range blocks {
-			blockEnd, _ := time.Parse("2006-01-02", blocks[i].EndDate)
+			blockEnd, _ := dates.Parse(blocks[i].EndDate)
 			
 			// Check if this date extends the block (allowing for weekends/holidays in between)
 			dayAfterBlock := blockEnd.AddDate(0, 0, 1)
@@ -481,7 +1102,7 @@ func (h *Handler) datesToBlocks(year int, vacationDates []string, holidayList []
 
 	// Expand blocks forward to include trailing weekends/holidays
 	for i := range blocks {
-		endDate, _ := time.Parse("2006-01-02", blocks[i].EndDate)
+		endDate, _ := dates.Parse(blocks[i].EndDate)
 		checkDate := endDate.AddDate(0, 0, 1)
 		
 		for {
@@ -504,33 +1125,93 @@ func (h *Handler) datesToBlocks(year int, vacationDates []string, holidayList []
 		}
 	}
 
+	for i := range blocks {
+		blocks[i].Efficiency = models.BlockEfficiency(blocks[i].TotalDays, blocks[i].VacationDaysUsed)
+	}
+
 	return blocks, nil
 }
 
+// buildVacationBlocks groups manual and optimized vacation days into blocks,
+// including the weekends and holidays around them. Manual and optimized days
+// that run into each other make a single "mixed" block, so its consecutive
+// days off are counted once.
+func (h *Handler) buildVacationBlocks(year int, config models.YearConfig, holidayList []holidays.PortugueseHoliday, manualVacations []models.VacationDay, optimalVacations []models.OptimalVacation) []models.VacationBlock {
+	sources := make(map[string]string)
+	for _, v := range manualVacations {
+		sources[v.Date] = "manual"
+	}
+	for _, v := range optimalVacations {
+		if _, ok := sources[v.Date]; !ok {
+			sources[v.Date] = "optimized"
+		}
+	}
+
+	vacationDates := make([]string, 0, len(sources))
+	for date := range sources {
+		vacationDates = append(vacationDates, date)
+	}
+	blocks, _ := h.datesToBlocks(year, vacationDates, holidayList, config)
+	for i := range blocks {
+		for _, date := range blocks[i].Dates {
+			if source, ok := sources[date]; ok {
+				blocks[i].Source = joinBlockSources(blocks[i].Source, source)
+			}
+		}
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].StartDate < blocks[j].StartDate
+	})
+
+	return blocks
+}
+
+// joinBlockSources returns the source of a block holding days of both
+// sources: "mixed" when they differ
+func joinBlockSources(a, b string) string {
+	switch {
+	case a == "" || a == b:
+		return b
+	case b == "":
+		return a
+	}
+	return "mixed"
+}
+
 // GetVacations returns manual vacation days for a year
 func (h *Handler) GetVacations(c *gin.Context) {
 	yearStr := c.Param("year")
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 
-	vacations, err := h.getVacations(year)
+	vacations, err := h.Vacations(c.Request.Context(), year)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	setETag(c, vacationsVersion(vacations))
 	c.JSON(http.StatusOK, vacations)
 }
 
+// Vacations returns the manual vacation days of a year
+func (h *Handler) Vacations(ctx context.Context, year int) ([]models.VacationDay, error) {
+	if err := checkYear(year); err != nil {
+		return nil, err
+	}
+	return h.store.Vacations.List(ctx, year)
+}
+
 // AddVacation adds a manual vacation day
 func (h *Handler) AddVacation(c *gin.Context) {
 	yearStr := c.Param("year")
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 
@@ -540,24 +1221,41 @@ func (h *Handler) AddVacation(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
 		return
 	}
 
-	// Check if the date is a holiday - can't set vacation on a holiday
-	if h.isHoliday(input.Date, year) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot set vacation on a holiday"})
+	if err := h.AddVacationDay(c.Request.Context(), year, input.Date, input.Note); err != nil {
+		respondError(c, err)
 		return
 	}
 
-	_, err = h.db.Exec(`INSERT OR REPLACE INTO vacation_days (year, date, is_manual, note) VALUES (?, ?, TRUE, ?)`,
-		year, input.Date, input.Note)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	c.JSON(http.StatusOK, gin.H{"message": "Vacation day added"})
+}
+
+// AddVacationDay stores a manual vacation day on a date that is not a holiday
+func (h *Handler) AddVacationDay(ctx context.Context, year int, date, note string) error {
+	if err := checkYear(year); err != nil {
+		return err
+	}
+	if err := checkDateInYear(date, year); err != nil {
+		return err
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Vacation day added"})
+	// Check if the date is a holiday - can't set vacation on a holiday
+	if h.isHoliday(ctx, date, year) {
+		return invalidInputCode(models.CodeHolidayConflict, errors.New("Cannot set vacation on a holiday"))
+	}
+	if err := h.checkEditable(ctx, year, date); err != nil {
+		return err
+	}
+
+	if err := h.store.Vacations.Add(ctx, year, date, note); err != nil {
+		return err
+	}
+
+	h.events.Publish(events.VacationAdded, year, gin.H{"dates": []string{date}, "note": note})
+	return nil
 }
 
 // RemoveVacation removes a vacation day
@@ -565,19 +1263,129 @@ func (h *Handler) RemoveVacation(c *gin.Context) {
 	yearStr := c.Param("year")
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 
-	date := c.Param("date")
+	if err := h.RemoveVacationDay(c.Request.Context(), year, c.Param("date")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Vacation day removed"})
+}
+
+// RemoveVacationDay removes a manual vacation day
+func (h *Handler) RemoveVacationDay(ctx context.Context, year int, date string) error {
+	if err := checkYear(year); err != nil {
+		return err
+	}
+	if err := checkDateInYear(date, year); err != nil {
+		return err
+	}
+	if err := h.checkEditable(ctx, year, date); err != nil {
+		return err
+	}
+
+	if _, err := h.store.Vacations.Remove(ctx, year, date); err != nil {
+		return err
+	}
+
+	h.events.Publish(events.VacationRemoved, year, gin.H{"dates": []string{date}})
+	return nil
+}
 
-	_, err = h.db.Exec(`DELETE FROM vacation_days WHERE year = ? AND date = ?`, year, date)
+// RemoveVacationRange removes all vacation days between the start and end
+// query dates, such as those of a cancelled trip
+func (h *Handler) RemoveVacationRange(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Vacation day removed"})
+	start, end := c.Query("start"), c.Query("end")
+	manual, optimized, err := h.RemoveVacationDays(c.Request.Context(), year, start, end)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"start":             start,
+		"end":               end,
+		"removed":           manual,
+		"removed_optimized": optimized,
+	})
+}
+
+// RemoveVacationDays removes the manual and optimized vacation days from
+// start to end in one transaction, returning the dates removed of each. It
+// removes nothing when any of them is locked or past.
+func (h *Handler) RemoveVacationDays(ctx context.Context, year int, start, end string) ([]string, []string, error) {
+	if err := checkYear(year); err != nil {
+		return nil, nil, err
+	}
+	if err := checkDateInYear(start, year); err != nil {
+		return nil, nil, err
+	}
+	if err := checkDateInYear(end, year); err != nil {
+		return nil, nil, err
+	}
+	if end < start {
+		return nil, nil, invalidInputCode(models.CodeInvalidDate, fmt.Errorf("end %s is before start %s", end, start))
+	}
+
+	manualVacations, err := h.store.Vacations.List(ctx, year)
+	if err != nil {
+		return nil, nil, err
+	}
+	optimalVacations, err := h.store.Vacations.ListOptimal(ctx, year)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manual, optimized := []string{}, []string{}
+	for _, v := range manualVacations {
+		if v.Date >= start && v.Date <= end {
+			manual = append(manual, v.Date)
+		}
+	}
+	for _, v := range optimalVacations {
+		if v.Date >= start && v.Date <= end {
+			optimized = append(optimized, v.Date)
+		}
+	}
+	sort.Strings(manual)
+	sort.Strings(optimized)
+
+	removed := append(append([]string{}, manual...), optimized...)
+	if err := h.checkEditable(ctx, year, removed...); err != nil {
+		return nil, nil, err
+	}
+
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		for _, date := range manual {
+			if _, err := tx.Vacations.Remove(ctx, year, date); err != nil {
+				return err
+			}
+		}
+		for _, date := range optimized {
+			if err := tx.Vacations.RemoveOptimal(ctx, year, date); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(removed) > 0 {
+		h.events.Publish(events.VacationRemoved, year, gin.H{"dates": removed})
+	}
+	return manual, optimized, nil
 }
 
 // ClearOptimizedVacations clears all optimized vacation days for a year
@@ -585,17 +1393,100 @@ func (h *Handler) ClearOptimizedVacations(c *gin.Context) {
 	yearStr := c.Param("year")
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 
-	_, err = h.db.Exec(`DELETE FROM optimal_vacations WHERE year = ?`, year)
+	if err := h.ClearOptimized(c.Request.Context(), year); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Optimized vacation days cleared"})
+}
+
+// ClearOptimized removes the optimized vacation days of a year, except the
+// past and locked ones
+func (h *Handler) ClearOptimized(ctx context.Context, year int) error {
+	if err := checkYear(year); err != nil {
+		return err
+	}
+	if err := h.store.Vacations.ClearOptimalUnlocked(ctx, year, h.editableFrom(ctx)); err != nil {
+		return err
+	}
+	h.events.Publish(events.OptimizationCleared, year, nil)
+	return nil
+}
+
+// AcceptOptimizedVacations turns the optimized vacation days of a year, or of
+// one block with ?block=<blockId>, into manual days, so the next
+// optimization keeps them
+func (h *Handler) AcceptOptimizedVacations(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	accepted, err := h.AcceptOptimized(c.Request.Context(), year, c.Query("block"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Optimized vacation days accepted", "accepted": accepted})
+}
+
+// AcceptOptimized moves the optimized vacation days of a year, or of the
+// block named by block, to the manual plan in one transaction, and returns
+// their dates
+func (h *Handler) AcceptOptimized(ctx context.Context, year int, block string) ([]string, error) {
+	if err := checkYear(year); err != nil {
+		return nil, err
+	}
+
+	optimal, err := h.store.Vacations.ListOptimal(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+
+	inBlock := func(string) bool { return true }
+	if block != "" {
+		b, err := h.findBlock(ctx, year, block)
+		if err != nil {
+			return nil, err
+		}
+		inBlock = func(date string) bool { return contains(b.Dates, date) }
+	}
+
+	accepted := []string{}
+	for _, v := range optimal {
+		if inBlock(v.Date) {
+			accepted = append(accepted, v.Date)
+		}
+	}
+	if len(accepted) == 0 {
+		return accepted, nil
+	}
+
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		for _, date := range accepted {
+			if err := tx.Vacations.RemoveOptimal(ctx, year, date); err != nil {
+				return err
+			}
+			if err := tx.Vacations.Add(ctx, year, date, "Accepted suggestion"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+		return nil, err
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Optimized vacation days cleared"})
+	h.events.Publish(events.VacationAdded, year, gin.H{"dates": accepted, "source": "optimizer"})
+	return accepted, nil
 }
 
 // GetVacationSuggestions uses AI to analyze manual vacation days and suggest improvements
@@ -603,45 +1494,30 @@ func (h *Handler) GetVacationSuggestions(c *gin.Context) {
 	yearStr := c.Param("year")
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 
-	// Get language parameter (default to English)
+	ctx := c.Request.Context()
+
+	// Get language parameter (English unless Portuguese is asked for)
 	language := c.Query("language")
-	if language == "" {
+	if language != "pt-PT" {
 		language = "en"
 	}
 
 	// Get AI configuration
-	var apiKey string
-	err = h.db.QueryRow("SELECT value FROM settings WHERE key = 'openai_api_key'").Scan(&apiKey)
-	if err != nil || apiKey == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "API key not configured"})
+	settings := h.getAISettings(ctx)
+	if !settings.Configured() {
+		problem(c, http.StatusBadRequest, models.CodeAIUnconfigured, "API key not configured")
 		return
 	}
 
-	var aiProvider string
-	h.db.QueryRow("SELECT value FROM settings WHERE key = 'ai_provider'").Scan(&aiProvider)
-	if aiProvider == "" {
-		aiProvider = "github"
-	}
-
-	var selectedModel string
-	h.db.QueryRow("SELECT value FROM settings WHERE key = 'ai_model'").Scan(&selectedModel)
-	if selectedModel == "" {
-		selectedModel = "openai/gpt-4o-mini"
-	}
-
-	if aiProvider == "github" && !strings.Contains(selectedModel, "/") {
-		selectedModel = "openai/" + selectedModel
-	}
-
 	// Get year config
-	config, _ := h.getOrCreateYearConfig(year)
+	config, _ := h.getOrCreateYearConfig(ctx, year)
 
 	// Get manual vacations
-	manualVacations, _ := h.getVacations(year)
+	manualVacations, _ := h.store.Vacations.List(ctx, year)
 	if len(manualVacations) == 0 {
 		noVacationMsg := "You haven't set any manual vacation days yet. Add some vacation days first, then I can suggest improvements!"
 		if language == "pt-PT" {
@@ -654,8 +1530,7 @@ func (h *Handler) GetVacationSuggestions(c *gin.Context) {
 	}
 
 	// Get holidays
-	workCity := h.getWorkCity()
-	holidayList := holidays.GetPortugueseHolidaysWithCity(year, workCity)
+	holidayList := h.holidaysForYear(ctx, year)
 
 	// Build holiday set for quick lookup
 	holidaySet := make(map[string]bool)
@@ -672,19 +1547,19 @@ func (h *Handler) GetVacationSuggestions(c *gin.Context) {
 	// Build context
 	var holidayInfo strings.Builder
 	for _, hol := range holidayList {
-		date, _ := time.Parse("2006-01-02", hol.Date)
+		date, _ := dates.Parse(hol.Date)
 		holidayInfo.WriteString(fmt.Sprintf("- %s (%s): %s\n", hol.Date, date.Weekday().String(), hol.Name))
 	}
 
-	// Get current date first
-	today := time.Now()
-	todayStr := today.Format("2006-01-02")
+	// Get current date first, in the user's time zone
+	today := h.today(ctx)
+	todayStr := dates.Format(today)
 
 	var manualInfo strings.Builder
 	manualInfo.WriteString(fmt.Sprintf("(Today is %s - only FUTURE dates can be moved)\n", todayStr))
 	for _, v := range manualVacations {
-		date, _ := time.Parse("2006-01-02", v.Date)
-		if date.After(today) || date.Format("2006-01-02") == todayStr {
+		date, _ := dates.Parse(v.Date)
+		if !date.Before(today) {
 			manualInfo.WriteString(fmt.Sprintf("- %s (%s) - CAN BE MOVED\n", v.Date, date.Weekday().String()))
 		} else {
 			manualInfo.WriteString(fmt.Sprintf("- %s (%s) - IN THE PAST, cannot move\n", v.Date, date.Weekday().String()))
@@ -715,9 +1590,8 @@ func (h *Handler) GetVacationSuggestions(c *gin.Context) {
 		// Go backwards to find start of break
 		for d := vacDate.AddDate(0, 0, -1); ; d = d.AddDate(0, 0, -1) {
 			dStr := d.Format("2006-01-02")
-			wdStr := strings.ToLower(d.Weekday().String())
 			// Only count weekends and holidays, NOT existing vacations
-			isOff := !workDaySet[wdStr] || holidaySet[dStr]
+			isOff := !config.IsWorkDay(d) || holidaySet[dStr]
 			if !isOff {
 				break
 			}
@@ -730,9 +1604,8 @@ func (h *Handler) GetVacationSuggestions(c *gin.Context) {
 		// Go forward to find end of break
 		for d := vacDate.AddDate(0, 0, 1); ; d = d.AddDate(0, 0, 1) {
 			dStr := d.Format("2006-01-02")
-			wdStr := strings.ToLower(d.Weekday().String())
 			// Only count weekends and holidays, NOT existing vacations
-			isOff := !workDaySet[wdStr] || holidaySet[dStr]
+			isOff := !config.IsWorkDay(d) || holidaySet[dStr]
 			if !isOff {
 				break
 			}
@@ -753,7 +1626,7 @@ func (h *Handler) GetVacationSuggestions(c *gin.Context) {
 	var opportunities []bridgeOpp
 	
 	for _, hol := range holidayList {
-		holDate, _ := time.Parse("2006-01-02", hol.Date)
+		holDate, _ := dates.Parse(hol.Date)
 		if holDate.Before(today) {
 			continue
 		}
@@ -764,9 +1637,8 @@ func (h *Handler) GetVacationSuggestions(c *gin.Context) {
 			}
 			checkDate := holDate.AddDate(0, 0, offset)
 			checkDateStr := checkDate.Format("2006-01-02")
-			weekdayStr := strings.ToLower(checkDate.Weekday().String())
 			
-			if workDaySet[weekdayStr] && !holidaySet[checkDateStr] && !vacationSet[checkDateStr] && checkDate.After(today) {
+			if config.IsWorkDay(checkDate) && !holidaySet[checkDateStr] && !vacationSet[checkDateStr] && checkDate.After(today) {
 				breakDays, breakList := calcBreak(checkDate)
 				if breakDays >= 3 { // Only include if it creates at least 3 days off
 					opportunities = append(opportunities, bridgeOpp{
@@ -827,53 +1699,64 @@ Format your response as:
 
 Keep it concise.`, languageInstruction, todayStr, todayWeekday, manualInfo.String(), holidayInfo.String(), bridgeOpportunities.String())
 
-	// Create AI client
-	var client *openai.Client
-	switch aiProvider {
-	case "github":
-		aiConfig := openai.DefaultConfig(apiKey)
-		aiConfig.BaseURL = "https://models.github.ai/inference"
-		client = openai.NewClientWithConfig(aiConfig)
-	case "openai":
-		client = openai.NewClient(apiKey)
-	default:
-		aiConfig := openai.DefaultConfig(apiKey)
-		aiConfig.BaseURL = "https://models.github.ai/inference"
-		client = openai.NewClientWithConfig(aiConfig)
+	// Reuse the last suggestion while its inputs are unchanged: the prompt
+	// holds the vacations, holidays, work week and today's date
+	inputHash := suggestionHash(settings, prompt)
+	if c.Query("force") != "true" {
+		if cached, err := h.store.Chat.Suggestion(ctx, year, language); err == nil && cached.InputHash == inputHash {
+			c.JSON(http.StatusOK, gin.H{
+				"suggestion": cached.Suggestion,
+				"cached":     true,
+				"created_at": cached.CreatedAt,
+				"model":      cached.Model,
+			})
+			return
+		}
 	}
 
-	resp, err := client.CreateChatCompletion(
-		context.Background(),
-		openai.ChatCompletionRequest{
-			Model: selectedModel,
-			Messages: []openai.ChatCompletionMessage{
-				{Role: openai.ChatMessageRoleUser, Content: prompt},
-			},
-			Temperature: 0.3,
+	resp, model, err := h.completeChat(ctx, settings, openai.ChatCompletionRequest{
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, Content: prompt},
 		},
-	)
-
+		Temperature: 0.3,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "AI request failed: " + err.Error()})
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, "AI request failed: "+err.Error())
 		return
 	}
 
-	if len(resp.Choices) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "No response from AI"})
-		return
+	suggestion := models.AISuggestion{
+		Year:       year,
+		Language:   language,
+		InputHash:  inputHash,
+		Suggestion: resp.Choices[0].Message.Content,
+		Model:      model,
+	}
+	if err := h.store.Chat.SaveSuggestion(ctx, suggestion); err != nil {
+		log.Printf("Failed to cache AI suggestion for %d: %v", year, err)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"suggestion": resp.Choices[0].Message.Content,
+		"suggestion": suggestion.Suggestion,
+		"cached":     false,
+		"created_at": time.Now().UTC().Format(time.RFC3339),
+		"model":      suggestion.Model,
 	})
 }
 
+// suggestionHash identifies the inputs of an AI suggestion: the provider,
+// the model and the prompt built from the calendar
+func suggestionHash(settings aiSettings, prompt string) string {
+	sum := sha256.Sum256([]byte(settings.Provider + "\x00" + settings.Model + "\x00" + prompt))
+	return hex.EncodeToString(sum[:])
+}
+
 // BulkUpdateVacations updates multiple vacation days at once
 func (h *Handler) BulkUpdateVacations(c *gin.Context) {
 	yearStr := c.Param("year")
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 
@@ -883,21 +1766,155 @@ func (h *Handler) BulkUpdateVacations(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// With If-Match, only apply the changes to the vacation days it was read from
+	results, err := h.updateVacationDays(ctx, year, ifMatch(c), input.Add, input.Remove)
+	if err != nil {
+		if results != nil {
+			respondError(c, err, gin.H{"results": results})
+		} else {
+			respondError(c, err)
+		}
 		return
 	}
 
-	// Remove vacations
-	for _, date := range input.Remove {
-		h.db.Exec(`DELETE FROM vacation_days WHERE year = ? AND date = ?`, year, date)
+	// Counts of each status, such as {"created": 3, "skipped_holiday": 1}
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[result.Status]++
+	}
+	response := gin.H{"results": results, "counts": counts}
+	if vacations, err := h.store.Vacations.List(ctx, year); err == nil {
+		version := vacationsVersion(vacations)
+		setETag(c, version)
+		response["version"] = version
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateVacationDays adds and removes manual vacation days, applying all
+// changes or none of them
+func (h *Handler) UpdateVacationDays(ctx context.Context, year int, add, remove []string) ([]models.BulkItemResult, error) {
+	return h.updateVacationDays(ctx, year, "", add, remove)
+}
+
+// updateVacationDays is UpdateVacationDays failing with
+// store.ErrVersionConflict unless the vacation days are at version, when
+// version is set
+func (h *Handler) updateVacationDays(ctx context.Context, year int, version string, add, remove []string) ([]models.BulkItemResult, error) {
+	if err := checkYear(year); err != nil {
+		return nil, err
+	}
+
+	// Reject the whole update if any date is outside the year, past or locked
+	changed := append(append([]string{}, add...), remove...)
+	for _, date := range changed {
+		if err := checkDateInYear(date, year); err != nil {
+			return nil, err
+		}
+	}
+	if err := h.checkEditable(ctx, year, changed...); err != nil {
+		return nil, err
+	}
+
+	// Adds are checked like single ones: holidays are skipped, and so are
+	// days past the vacation and comp days left once the removals are done
+	holidaySet := make(map[string]bool)
+	for _, holiday := range h.holidaysForYear(ctx, year) {
+		holidaySet[holiday.Date] = true
+	}
+	calendar, err := h.Calendar(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+	available := calendar.Summary.RemainingVacationDays + calendar.Summary.CompDaysRemaining
+
+	results := []models.BulkItemResult{}
+	var added, removed []string
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		current, err := tx.Vacations.List(ctx, year)
+		if err != nil {
+			return err
+		}
+		if version != "" && vacationsVersion(current) != version {
+			return store.ErrVersionConflict
+		}
+		booked := make(map[string]bool)
+		for _, v := range current {
+			booked[v.Date] = true
+		}
+
+		// Remove vacations
+		for _, date := range remove {
+			ok, err := tx.Vacations.Remove(ctx, year, date)
+			if err != nil {
+				results = append(results, models.BulkItemResult{Date: date, Action: "remove", Status: "failed", Error: err.Error()})
+				return err
+			}
+			status := "applied"
+			if !ok {
+				status = "unchanged"
+			} else {
+				removed = append(removed, date)
+				delete(booked, date)
+				available++
+			}
+			results = append(results, models.BulkItemResult{Date: date, Action: "remove", Status: status})
+		}
+
+		// Add vacations
+		for _, date := range add {
+			result := models.BulkItemResult{Date: date, Action: "add"}
+			switch {
+			case holidaySet[date]:
+				result.Status, result.Error = "skipped_holiday", "Cannot set vacation on a holiday"
+			case booked[date]:
+				result.Status = "skipped_duplicate"
+			case available <= 0:
+				result.Status, result.Error = "over_budget", "No vacation days left"
+			default:
+				if err := tx.Vacations.Add(ctx, year, date, ""); err != nil {
+					results = append(results, models.BulkItemResult{Date: date, Action: "add", Status: "failed", Error: err.Error()})
+					return err
+				}
+				result.Status = "created"
+				added = append(added, date)
+				booked[date] = true
+				available--
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		rollBackResults(results)
+		return results, err
 	}
 
-	// Add vacations
-	for _, date := range input.Add {
-		h.db.Exec(`INSERT OR REPLACE INTO vacation_days (year, date, is_manual) VALUES (?, ?, TRUE)`, year, date)
+	if len(removed) > 0 {
+		h.events.Publish(events.VacationRemoved, year, gin.H{"dates": removed})
 	}
+	if len(added) > 0 {
+		h.events.Publish(events.VacationAdded, year, gin.H{"dates": added})
+	}
+
+	return results, nil
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Vacations updated"})
+// rollBackResults marks the items of a failed transaction that had been
+// applied as rolled back. Skipped items stay as they are.
+func rollBackResults(results []models.BulkItemResult) {
+	for i := range results {
+		switch results[i].Status {
+		case "applied", "unchanged", "created":
+			results[i].Status = "rolled_back"
+		}
+	}
 }
 
 // GetHolidays returns holidays for a year
@@ -905,20 +1922,94 @@ func (h *Handler) GetHolidays(c *gin.Context) {
 	yearStr := c.Param("year")
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	filter, err := parseHolidayFilter(c, year)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// A preview calculates the holidays locally, for years the APIs don't
+	// publish yet. Nothing is persisted or recorded for sync.
+	if c.Query("preview") == "true" {
+		if err := checkYear(year); err != nil {
+			respondError(c, err)
+			return
+		}
+		preview := h.applyHolidayRules(ctx, year, holidays.CalculatedHolidays(year))
+		for i := range preview {
+			preview[i].Provisional = true
+		}
+		c.JSON(http.StatusOK, filterHolidays(preview, filter))
 		return
 	}
 
-	workCity := h.getWorkCity()
+	workCity := h.getWorkCity(ctx)
 	
 	// Use the holiday service which handles DB persistence and retries
 	holidayList, err := h.holidayService.LoadHolidaysForYear(year, workCity)
 	if err != nil {
 		// Even on error, we should have fallback data
-		holidayList = holidays.GetPortugueseHolidaysWithCity(year, workCity)
+		holidayList = h.holidayService.Holidays(year, workCity)
 	}
-	
-	c.JSON(http.StatusOK, holidayList)
+
+	result := h.applyHolidayRules(ctx, year, holidayList)
+
+	// Stamp what changed since the last request, for clients syncing with ?since=
+	var now time.Time
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		now = time.Now()
+		return tx.Holidays.RecordChanges(ctx, year, result, now)
+	})
+
+	sinceStr := c.Query("since")
+	if sinceStr == "" {
+		if err != nil {
+			log.Printf("Failed to record holiday changes for %d: %v", year, err)
+		}
+		c.JSON(http.StatusOK, filterHolidays(result, filter))
+		return
+	}
+
+	since, parseErr := time.Parse(time.RFC3339Nano, sinceStr)
+	if parseErr != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "since must be an RFC 3339 timestamp")
+		return
+	}
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+	changes, err := h.store.Holidays.ChangesSince(ctx, year, since)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+	changes = filterHolidayChanges(changes, filter)
+
+	changed := []models.HolidayChange{}
+	tombstones := []models.HolidayChange{}
+	for _, change := range changes {
+		if change.Deleted {
+			tombstones = append(tombstones, change)
+		} else {
+			changed = append(changed, change)
+		}
+	}
+
+	// Clients pass synced_at as the next since
+	c.JSON(http.StatusOK, gin.H{
+		"year":       year,
+		"since":      sinceStr,
+		"synced_at":  now.UTC().Format(store.ChangeStampFormat),
+		"holidays":   changed,
+		"tombstones": tombstones,
+	})
 }
 
 // GetHolidayStatus returns the current status of holiday data loading
@@ -926,7 +2017,7 @@ func (h *Handler) GetHolidayStatus(c *gin.Context) {
 	yearStr := c.Param("year")
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 	
@@ -973,43 +2064,68 @@ func (h *Handler) GetYearConfig(c *gin.Context) {
 	yearStr := c.Param("year")
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 
-	config, err := h.getOrCreateYearConfig(year)
+	ctx := c.Request.Context()
+
+	config, err := h.getOrCreateYearConfig(ctx, year)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
 		return
 	}
 
+	setETag(c, configVersion(config))
 	c.JSON(http.StatusOK, config)
 }
 
-// UpdateYearConfig updates configuration for a year
+// UpdateYearConfig updates configuration for a year. With an If-Match header
+// or a version in the body, the update is rejected when the config changed
+// since that version was read.
 func (h *Handler) UpdateYearConfig(c *gin.Context) {
 	yearStr := c.Param("year")
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 
+	ctx := c.Request.Context()
+
 	var input struct {
-		VacationDays         *int     `json:"vacation_days"`
-		ReservedDays         *int     `json:"reserved_days"`
-		OptimizationStrategy *string  `json:"optimization_strategy"`
-		WorkWeek             []string `json:"work_week"`
-		OptimizerNotes       *string  `json:"optimizer_notes"`
+		VacationDays         *int                     `json:"vacation_days"`
+		ReservedDays         *int                     `json:"reserved_days"`
+		OptimizationStrategy *string                  `json:"optimization_strategy"`
+		WorkWeek             []string                 `json:"work_week"`
+		OptimizerNotes       *string                  `json:"optimizer_notes"`
+		AlignSchoolBreaks    *bool                    `json:"align_school_breaks"`
+		AccountingMode       *string                  `json:"accounting_mode"`
+		VacationHours        *float64                 `json:"vacation_hours"`
+		WorkingHours         map[string]float64       `json:"working_hours"`
+		OptionalHolidays     *[]string                `json:"optional_holidays"`
+		ScoringWeights       *models.ScoringWeights   `json:"scoring_weights"`
+		BlackoutPeriods      *[]models.BlackoutPeriod `json:"blackout_periods"`
+		AvoidPeriods         *[]models.AvoidPeriod    `json:"avoid_periods"`
+		Version              *int                     `json:"version"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
 		return
 	}
 
 	// Get current config
-	config, _ := h.getOrCreateYearConfig(year)
+	config, _ := h.getOrCreateYearConfig(ctx, year)
+
+	expected := ifMatch(c)
+	if expected == "" && input.Version != nil {
+		expected = strconv.Itoa(*input.Version)
+	}
+	if expected != "" && expected != configVersion(config) {
+		respondError(c, store.ErrVersionConflict, gin.H{"version": config.Version})
+		return
+	}
 
 	// Update fields if provided
 	if input.VacationDays != nil {
@@ -1027,107 +2143,246 @@ func (h *Handler) UpdateYearConfig(c *gin.Context) {
 	if input.OptimizerNotes != nil {
 		config.OptimizerNotes = *input.OptimizerNotes
 	}
+	if input.AlignSchoolBreaks != nil {
+		config.AlignSchoolBreaks = *input.AlignSchoolBreaks
+	}
+	if input.AccountingMode != nil {
+		if *input.AccountingMode != models.AccountingDays && *input.AccountingMode != models.AccountingHours {
+			problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "accounting_mode must be 'days' or 'hours'")
+			return
+		}
+		config.AccountingMode = *input.AccountingMode
+	}
+	if input.VacationHours != nil {
+		config.VacationHours = *input.VacationHours
+	}
+	if input.WorkingHours != nil {
+		for day, hours := range input.WorkingHours {
+			if hours < 0 || hours > 24 {
+				problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid working hours for "+day)
+				return
+			}
+		}
+		config.WorkingHours = input.WorkingHours
+	}
+	if input.OptionalHolidays != nil {
+		enabled := []string{}
+		for _, key := range *input.OptionalHolidays {
+			if !holidays.IsOptionalHoliday(key) {
+				problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Unknown optional holiday "+key)
+				return
+			}
+			if !contains(enabled, key) {
+				enabled = append(enabled, key)
+			}
+		}
+		config.OptionalHolidays = enabled
+	}
+	if input.ScoringWeights != nil {
+		weights := *input.ScoringWeights
+		if weights.Efficiency < 0 || weights.Length < 0 || weights.Distribution < 0 || weights.MonthPreference < 0 {
+			problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Scoring weights can't be negative")
+			return
+		}
+		for _, month := range weights.PreferredMonths {
+			if month < 1 || month > 12 {
+				problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid preferred month "+strconv.Itoa(month))
+				return
+			}
+		}
+		config.ScoringWeights = weights
+	}
+	if input.BlackoutPeriods != nil {
+		periods := []models.BlackoutPeriod{}
+		for _, p := range *input.BlackoutPeriods {
+			if err := checkBlackoutPeriod(p, year); err != nil {
+				respondError(c, err)
+				return
+			}
+			periods = append(periods, p)
+		}
+		config.BlackoutPeriods = periods
+	}
+	if input.AvoidPeriods != nil {
+		periods := []models.AvoidPeriod{}
+		for _, p := range *input.AvoidPeriods {
+			if p.Weight == 0 {
+				p.Weight = models.DefaultAvoidWeight
+			}
+			if err := checkAvoidPeriod(p, year); err != nil {
+				respondError(c, err)
+				return
+			}
+			periods = append(periods, p)
+		}
+		config.AvoidPeriods = periods
+	}
 
-	workWeekJSON, _ := json.Marshal(config.WorkWeek)
-
-	_, err = h.db.Exec(`UPDATE year_config SET vacation_days = ?, reserved_days = ?, optimization_strategy = ?, work_week = ?, optimizer_notes = ?, updated_at = CURRENT_TIMESTAMP WHERE year = ?`,
-		config.VacationDays, config.ReservedDays, config.OptimizationStrategy, string(workWeekJSON), config.OptimizerNotes, year)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// Saving checks the version again, for updates racing this one
+	if err := h.store.Configs.Update(ctx, config); err != nil {
+		respondError(c, err)
 		return
 	}
+	config.Version++
 
+	// The work week, allowance and optional holidays change what the booking
+	// rules call for
+	if _, err := h.evaluatePolicies(ctx, year); err != nil {
+		log.Printf("Booking policies for %d failed: %v", year, err)
+	}
+
+	setETag(c, configVersion(config))
 	c.JSON(http.StatusOK, config)
 }
 
-// CopyYearConfig copies configuration from one year to another
+// CopyYearConfig copies configuration from one year to another, and with
+// ?vacations=same_weekday or same_date its manual vacation days too
 func (h *Handler) CopyYearConfig(c *gin.Context) {
 	yearStr := c.Param("year")
 	sourceYearStr := c.Param("sourceYear")
 
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 
+	ctx := c.Request.Context()
+
 	sourceYear, err := strconv.Atoi(sourceYearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid source year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid source year")
 		return
 	}
 
-	sourceConfig, err := h.getOrCreateYearConfig(sourceYear)
+	sourceConfig, err := h.getOrCreateYearConfig(ctx, sourceYear)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	mode := c.Query("vacations")
+	if mode != "" && mode != models.CopySameWeekday && mode != models.CopySameDate {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "vacations must be "+models.CopySameWeekday+" or "+models.CopySameDate)
+		return
+	}
+
+	if err := h.store.Configs.Copy(ctx, year, sourceConfig); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
 		return
 	}
 
-	workWeekJSON, _ := json.Marshal(sourceConfig.WorkWeek)
+	if mode == "" {
+		c.JSON(http.StatusOK, gin.H{"message": "Configuration copied"})
+		return
+	}
 
-	_, err = h.db.Exec(`INSERT OR REPLACE INTO year_config (year, vacation_days, optimization_strategy, work_week) VALUES (?, ?, ?, ?)`,
-		year, sourceConfig.VacationDays, sourceConfig.OptimizationStrategy, string(workWeekJSON))
+	// Also copy the manual vacation days, reporting the ones that clash
+	copied, err := h.CopyVacationPlan(ctx, year, sourceYear, mode)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Configuration copied"})
+	counts := make(map[string]int)
+	for _, result := range copied {
+		counts[result.Status]++
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Configuration and vacations copied", "vacations": copied, "counts": counts})
 }
 
 // GetSettings returns all settings
 func (h *Handler) GetSettings(c *gin.Context) {
-	rows, err := h.db.Query("SELECT key, value FROM settings")
+	values, err := h.store.Settings.All(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
 		return
 	}
-	defer rows.Close()
 
-	settings := make(map[string]string)
-	for rows.Next() {
-		var key, value string
-		rows.Scan(&key, &value)
-		settings[key] = value
+	// Only admins see keys and passwords
+	if !isAdmin(c) {
+		for key := range values {
+			if def, _ := settings.Lookup(key); def.Secret {
+				values[key] = ""
+			}
+		}
 	}
 
-	c.JSON(http.StatusOK, settings)
+	c.JSON(http.StatusOK, values)
 }
 
 // UpdateSettings updates multiple settings
 func (h *Handler) UpdateSettings(c *gin.Context) {
 	var input map[string]string
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
 		return
 	}
 
+	// Validate everything before saving anything
 	for key, value := range input {
-		h.db.Exec(`INSERT OR REPLACE INTO settings (key, value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)`, key, value)
-		
+		if err := settings.Validate(key, value); err != nil {
+			problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	previousCity := h.getWorkCity(ctx)
+	for key, value := range input {
+		// Server-managed settings are sent back unchanged by the settings form
+		if def, _ := settings.Lookup(key); def.ReadOnly || h.instanceWide(key) {
+			continue
+		}
+
+		if err := h.store.Settings.Set(ctx, key, value); err != nil {
+			problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+			return
+		}
+
 		// Update Calendarific API key if changed
 		if key == "calendarific_api_key" {
 			holidays.SetCalendarificAPIKey(value)
 		}
 	}
 
+	for key := range input {
+		if strings.HasPrefix(key, "holiday_retry_") {
+			h.applyRetryPolicy(ctx)
+			break
+		}
+	}
+	if _, ok := input["backup_schedule"]; ok {
+		h.applyBackupSchedule(ctx)
+	}
+	if city, ok := input["work_city"]; ok {
+		h.changeWorkCity(ctx, previousCity, city)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Settings updated"})
 }
 
+// GetSettingsSchema describes every setting: type, allowed values, default
+// and whether it is a secret
+func (h *Handler) GetSettingsSchema(c *gin.Context) {
+	c.JSON(http.StatusOK, settings.Schema)
+}
+
 // GetSetting returns a single setting
 func (h *Handler) GetSetting(c *gin.Context) {
 	key := c.Param("key")
 
-	var value string
-	err := h.db.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
-	if err == sql.ErrNoRows {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Setting not found"})
+	value, err := h.store.Settings.Get(c.Request.Context(), key)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Setting not found")
 		return
 	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
 		return
 	}
+	if def, _ := settings.Lookup(key); def.Secret && !isAdmin(c) {
+		value = ""
+	}
 
 	c.JSON(http.StatusOK, gin.H{key: value})
 }
@@ -1141,13 +2396,26 @@ func (h *Handler) UpdateSetting(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
 		return
 	}
 
-	_, err := h.db.Exec(`INSERT OR REPLACE INTO settings (key, value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)`, key, input.Value)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := settings.Validate(key, input.Value); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+	if def, _ := settings.Lookup(key); def.ReadOnly {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, key+" is managed by the server")
+		return
+	}
+	if h.instanceWide(key) {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, key+" is set for the whole instance in multi-tenant mode")
+		return
+	}
+
+	previousCity := h.getWorkCity(c.Request.Context())
+	if err := h.store.Settings.Set(c.Request.Context(), key, input.Value); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
 		return
 	}
 
@@ -1155,6 +2423,15 @@ func (h *Handler) UpdateSetting(c *gin.Context) {
 	if key == "calendarific_api_key" {
 		holidays.SetCalendarificAPIKey(input.Value)
 	}
+	if strings.HasPrefix(key, "holiday_retry_") {
+		h.applyRetryPolicy(c.Request.Context())
+	}
+	if key == "backup_schedule" {
+		h.applyBackupSchedule(c.Request.Context())
+	}
+	if key == "work_city" {
+		h.changeWorkCity(c.Request.Context(), previousCity, input.Value)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Setting updated"})
 }
@@ -1164,20 +2441,24 @@ func (h *Handler) RefreshHolidays(c *gin.Context) {
 	yearStr := c.Param("year")
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
 		return
 	}
 
-	workCity := h.getWorkCity()
+	ctx := c.Request.Context()
+
+	workCity := h.getWorkCity(ctx)
 	
 	// Force refresh using the service (clears DB and memory cache)
 	holidayList, err := h.holidayService.ForceRefresh(year, workCity)
 	if err != nil {
 		// Return whatever we have
-		holidayList = holidays.GetPortugueseHolidaysWithCity(year, workCity)
+		holidayList = h.holidayService.Holidays(year, workCity)
 	}
 	
 	status := h.holidayService.GetStatus(year)
+
+	h.events.Publish(events.HolidaysRefreshed, year, gin.H{"holidays": holidayList})
 	
 	response := gin.H{
 		"message":  "Holidays refreshed",
@@ -1197,32 +2478,40 @@ func (h *Handler) GetWorkWeekPresets(c *gin.Context) {
 	c.JSON(http.StatusOK, models.WorkWeekPresets)
 }
 
-// GetOptimizationStrategies returns available optimization strategies
+// aiStrategies are the strategies run by the handlers with the AI, next to
+// the optimizer's registered ones
+var aiStrategies = []optimizer.StrategyInfo{
+	{ID: models.StrategySmart, Name: "Smart (AI)", Description: "Use AI to find the optimal vacation combination based on holidays, efficiency, and personal preferences"},
+	{ID: models.StrategyHybrid, Name: "Hybrid (AI refined)", Description: "Start from the balanced plan and let AI adjust it to your notes, with far fewer tokens than Smart"},
+}
+
+// GetOptimizationStrategies returns available optimization strategies: the
+// optimizer's registered ones, then the AI ones
 func (h *Handler) GetOptimizationStrategies(c *gin.Context) {
-	strategies := []map[string]string{
-		{"id": models.StrategyBridgeHolidays, "name": "Bridge Holidays", "description": "Focus on creating bridges between holidays and weekends for efficient use of vacation days"},
-		{"id": models.StrategyLongestBlocks, "name": "Longest Blocks", "description": "Focus on creating the longest possible consecutive vacation periods"},
-		{"id": models.StrategyBalanced, "name": "Balanced", "description": "Balance between efficiency and length of vacation blocks"},
-		{"id": models.StrategySmart, "name": "Smart (AI)", "description": "Use AI to find the optimal vacation combination based on holidays, efficiency, and personal preferences"},
-	}
-	c.JSON(http.StatusOK, strategies)
+	c.JSON(http.StatusOK, append(optimizer.Strategies(), aiStrategies...))
 }
 
-// Helper functions
-func (h *Handler) getOrCreateYearConfig(year int) (models.YearConfig, error) {
-	var config models.YearConfig
-	var workWeekJSON string
-	var optimizerNotes sql.NullString
+// GetHolidaySubstitutionPolicies returns the policies for holidays on weekends
+func (h *Handler) GetHolidaySubstitutionPolicies(c *gin.Context) {
+	c.JSON(http.StatusOK, holidays.GetSubstitutionPolicies())
+}
 
-	err := h.db.QueryRow(`SELECT id, year, vacation_days, COALESCE(reserved_days, 0), optimization_strategy, work_week, COALESCE(optimizer_notes, '') FROM year_config WHERE year = ?`, year).
-		Scan(&config.ID, &config.Year, &config.VacationDays, &config.ReservedDays, &config.OptimizationStrategy, &workWeekJSON, &optimizerNotes)
+// GetBirthdayRules returns the rules for the birthday day off
+func (h *Handler) GetBirthdayRules(c *gin.Context) {
+	c.JSON(http.StatusOK, holidays.GetBirthdayRules())
+}
 
-	if err == sql.ErrNoRows {
+// Helper functions
+func (h *Handler) getOrCreateYearConfig(ctx context.Context, year int) (models.YearConfig, error) {
+	config, err := h.store.Configs.Get(ctx, year)
+	if errors.Is(err, store.ErrNotFound) {
 		// Try to copy from previous year
-		prevConfig, prevErr := h.getYearConfigOnly(year - 1)
+		prevConfig, prevErr := h.store.Configs.Get(ctx, year-1)
 		if prevErr == nil {
 			config = prevConfig
 			config.Year = year
+			// Carry over the work week in effect at the end of the previous year
+			config.WorkWeek = prevConfig.WorkWeekOn(fmt.Sprintf("%d-12-31", year-1))
 		} else {
 			// Use defaults
 			config = models.YearConfig{
@@ -1232,78 +2521,25 @@ func (h *Handler) getOrCreateYearConfig(year int) (models.YearConfig, error) {
 				OptimizationStrategy: models.StrategyBalanced,
 				WorkWeek:             []string{"monday", "tuesday", "wednesday", "thursday", "friday"},
 				OptimizerNotes:       "",
+				AccountingMode:       models.AccountingDays,
+				OptionalHolidays:     []string{},
 			}
 		}
+		config.WorkWeekChanges = []models.WorkWeekChange{}
+		config.Version = 1
 
-		workWeekJSON, _ := json.Marshal(config.WorkWeek)
-		h.db.Exec(`INSERT INTO year_config (year, vacation_days, reserved_days, optimization_strategy, work_week, optimizer_notes) VALUES (?, ?, ?, ?, ?, ?)`,
-			year, config.VacationDays, config.ReservedDays, config.OptimizationStrategy, string(workWeekJSON), config.OptimizerNotes)
-
-		return config, nil
-	}
-
-	if err != nil {
-		return config, err
-	}
-
-	json.Unmarshal([]byte(workWeekJSON), &config.WorkWeek)
-	if optimizerNotes.Valid {
-		config.OptimizerNotes = optimizerNotes.String
-	}
-	return config, nil
-}
-
-func (h *Handler) getYearConfigOnly(year int) (models.YearConfig, error) {
-	var config models.YearConfig
-	var workWeekJSON string
-	var optimizerNotes sql.NullString
-
-	err := h.db.QueryRow(`SELECT id, year, vacation_days, COALESCE(reserved_days, 0), optimization_strategy, work_week, COALESCE(optimizer_notes, '') FROM year_config WHERE year = ?`, year).
-		Scan(&config.ID, &config.Year, &config.VacationDays, &config.ReservedDays, &config.OptimizationStrategy, &workWeekJSON, &optimizerNotes)
-
-	if err != nil {
-		return config, err
-	}
-
-	json.Unmarshal([]byte(workWeekJSON), &config.WorkWeek)
-	if optimizerNotes.Valid {
-		config.OptimizerNotes = optimizerNotes.String
-	}
-	return config, nil
-}
-
-func (h *Handler) getVacations(year int) ([]models.VacationDay, error) {
-	rows, err := h.db.Query(`SELECT id, year, date, is_manual, COALESCE(note, '') FROM vacation_days WHERE year = ?`, year)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var vacations []models.VacationDay
-	for rows.Next() {
-		var v models.VacationDay
-		rows.Scan(&v.ID, &v.Year, &v.Date, &v.IsManual, &v.Note)
-		vacations = append(vacations, v)
-	}
-
-	return vacations, nil
-}
+		// With an employment start date the allowance follows the seniority
+		// rules instead of being copied from the previous year
+		if entitlement := h.computeEntitlement(ctx, year); entitlement.EmploymentStartDate != "" {
+			config.VacationDays = entitlement.VacationDays
+		}
 
-func (h *Handler) getOptimalVacations(year int) ([]models.OptimalVacation, error) {
-	rows, err := h.db.Query(`SELECT id, year, date, block_id, consecutive_days FROM optimal_vacations WHERE year = ?`, year)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+		h.store.Configs.Create(ctx, config)
 
-	var vacations []models.OptimalVacation
-	for rows.Next() {
-		var v models.OptimalVacation
-		rows.Scan(&v.ID, &v.Year, &v.Date, &v.BlockID, &v.ConsecutiveDays)
-		vacations = append(vacations, v)
+		return config, nil
 	}
 
-	return vacations, nil
+	return config, err
 }
 
 func (h *Handler) buildCalendarDays(year int, config models.YearConfig, holidayList []holidays.PortugueseHoliday, manualVacations []models.VacationDay, optimalVacations []models.OptimalVacation) []models.CalendarDay {
@@ -1325,11 +2561,6 @@ func (h *Handler) buildCalendarDays(year int, config models.YearConfig, holidayL
 		optimalMap[v.Date] = v.BlockID
 	}
 
-	workDaySet := make(map[string]bool)
-	for _, d := range config.WorkWeek {
-		workDaySet[d] = true
-	}
-
 	// Iterate through all days of the year
 	startDate := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
 	endDate := time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC)
@@ -1338,7 +2569,7 @@ func (h *Handler) buildCalendarDays(year int, config models.YearConfig, holidayL
 		dateStr := d.Format("2006-01-02")
 		dayOfWeek := weekdayToString(d.Weekday())
 		
-		isWeekend := !workDaySet[dayOfWeek]
+		isWeekend := !config.IsWorkDay(d)
 		holidayName, isHoliday := holidayMap[dateStr]
 		isManual := manualMap[dateStr]
 		blockID, isOptimal := optimalMap[dateStr]
@@ -1361,86 +2592,126 @@ func (h *Handler) buildCalendarDays(year int, config models.YearConfig, holidayL
 	return days
 }
 
-func (h *Handler) calculateSummary(totalVacation int, manualVacations []models.VacationDay, optimalVacations []models.OptimalVacation, holidayList []holidays.PortugueseHoliday) models.CalendarSummary {
-	usedDays := len(manualVacations) + len(optimalVacations)
-	
-	// Calculate longest block
-	blockDays := make(map[int]int)
-	for _, v := range optimalVacations {
-		if v.ConsecutiveDays > blockDays[v.BlockID] {
-			blockDays[v.BlockID] = v.ConsecutiveDays
+// markBridgeCandidates flags the lone work days, not yet taken off, between
+// two days off within the year when at least one of them is a holiday: a
+// vacation day there joins the holiday to the weekend
+func markBridgeCandidates(days []models.CalendarDay) {
+	nonWorking := func(day models.CalendarDay) bool {
+		return day.IsWeekend || day.IsHoliday
+	}
+	for i := 1; i < len(days)-1; i++ {
+		before, day, after := days[i-1], days[i], days[i+1]
+		if nonWorking(day) || day.IsVacation || day.IsCompDay || !nonWorking(before) || !nonWorking(after) {
+			continue
 		}
+		days[i].IsBridgeCandidate = before.IsHoliday || after.IsHoliday
 	}
+}
+
+func (h *Handler) calculateSummary(ctx context.Context, year, totalVacation int, days []models.CalendarDay, manualVacations []models.VacationDay, optimalVacations []models.OptimalVacation, holidayList []holidays.PortugueseHoliday, blocks []models.VacationBlock) models.CalendarSummary {
+	usedDays := len(manualVacations) + len(optimalVacations)
 	
+	// Calculate longest block, manual and optimized days running into each
+	// other counting as one
 	longestBlock := 0
-	for _, days := range blockDays {
-		if days > longestBlock {
-			longestBlock = days
+	for _, block := range blocks {
+		if block.TotalDays > longestBlock {
+			longestBlock = block.TotalDays
 		}
 	}
 
-	// Calculate total days off including bridged weekends
-	// Collect all special days (vacations and holidays)
-	specialDays := make(map[string]bool)
-	for _, v := range manualVacations {
-		specialDays[v.Date] = true
+	// Vacation days beyond the annual leave come out of the compensation pool
+	comp := h.compPool(ctx, year)
+	comp.CoverVacation(usedDays, totalVacation)
+
+	// Days off are the streaks holding a holiday, vacation or comp day, with
+	// the weekends and other days off they run into
+	totalDaysOff := 0
+	streakCounts := make(map[int]int)
+	for _, streak := range offStreaks(days) {
+		totalDaysOff += streak
+		streakCounts[streak]++
 	}
-	for _, v := range optimalVacations {
-		specialDays[v.Date] = true
+	streaks := []models.StreakCount{}
+	for length, count := range streakCounts {
+		streaks = append(streaks, models.StreakCount{Days: length, Count: count})
 	}
-	for _, h := range holidayList {
-		specialDays[h.Date] = true
+	sort.Slice(streaks, func(i, j int) bool { return streaks[i].Days < streaks[j].Days })
+
+	// Efficiency of the plan overall and per quarter (only days inside vacation blocks count)
+	quarters := make([]models.QuarterSummary, 4)
+	for i := range quarters {
+		quarters[i].Quarter = i + 1
 	}
 
-	// Count weekends that are adjacent to special days (bridged)
-	bridgedWeekends := 0
-	countedWeekends := make(map[string]bool)
-	
-	for dateStr := range specialDays {
-		date, err := time.Parse("2006-01-02", dateStr)
-		if err != nil {
-			continue
+	blockDaysOff := 0
+	blockVacationDays := 0
+	for _, block := range blocks {
+		blockDaysOff += block.TotalDays
+		blockVacationDays += block.VacationDaysUsed
+
+		freeDays := make(map[string]bool)
+		for _, d := range block.Weekends {
+			freeDays[d] = true
 		}
-		
-		// Check adjacent days for weekends
-		for delta := -1; delta <= 1; delta += 2 { // -1 (before) and +1 (after)
-			adjDate := date.AddDate(0, 0, delta)
-			adjStr := adjDate.Format("2006-01-02")
-			
-			// If it's a weekend and not already counted
-			if (adjDate.Weekday() == time.Saturday || adjDate.Weekday() == time.Sunday) && !countedWeekends[adjStr] {
-				// Mark as counted and add to bridged count
-				countedWeekends[adjStr] = true
-				bridgedWeekends++
-				
-				// Also count the other weekend day if adjacent
-				if adjDate.Weekday() == time.Saturday {
-					sunday := adjDate.AddDate(0, 0, 1)
-					sunStr := sunday.Format("2006-01-02")
-					if !countedWeekends[sunStr] {
-						countedWeekends[sunStr] = true
-						bridgedWeekends++
-					}
-				} else if adjDate.Weekday() == time.Sunday {
-					saturday := adjDate.AddDate(0, 0, -1)
-					satStr := saturday.Format("2006-01-02")
-					if !countedWeekends[satStr] {
-						countedWeekends[satStr] = true
-						bridgedWeekends++
-					}
-				}
+		for _, d := range block.Holidays {
+			freeDays[d] = true
+		}
+
+		for _, dateStr := range block.Dates {
+			date, err := dates.Parse(dateStr)
+			if err != nil || date.Year() != year {
+				continue
+			}
+			q := (int(date.Month()) - 1) / 3
+			quarters[q].DaysOff++
+			if !freeDays[dateStr] {
+				quarters[q].VacationDays++
 			}
 		}
 	}
 
+	for i := range quarters {
+		quarters[i].Efficiency = models.BlockEfficiency(quarters[i].DaysOff, quarters[i].VacationDays)
+	}
+
 	return models.CalendarSummary{
 		TotalVacationDays:     totalVacation,
+		CompensationDays:      comp.Earned,
+		CompDaysUsed:          comp.Spent + comp.UsedForVacation,
+		CompDaysRemaining:     max(comp.Balance, 0),
 		UsedVacationDays:      usedDays,
-		RemainingVacationDays: totalVacation - usedDays,
+		RemainingVacationDays: totalVacation - usedDays + comp.UsedForVacation + min(comp.Balance, 0),
 		TotalHolidays:         len(holidayList),
 		LongestVacationBlock:  longestBlock,
-		TotalDaysOff:          usedDays + len(holidayList) + bridgedWeekends,
+		TotalDaysOff:          totalDaysOff,
+		StreakDistribution:    streaks,
+		Efficiency:            models.BlockEfficiency(blockDaysOff, blockVacationDays),
+		QuarterDistribution:   quarters,
+	}
+}
+
+// offStreaks returns the length of each run of consecutive days off in the
+// days of a year that holds at least one holiday, vacation or comp day.
+// Weekends on their own aren't time off.
+func offStreaks(days []models.CalendarDay) []int {
+	var streaks []int
+	run, taken := 0, false
+	for _, day := range days {
+		if !day.IsWeekend && !day.IsHoliday && !day.IsVacation && !day.IsCompDay {
+			if taken {
+				streaks = append(streaks, run)
+			}
+			run, taken = 0, false
+			continue
+		}
+		run++
+		taken = taken || day.IsHoliday || day.IsVacation || day.IsCompDay
+	}
+	if taken {
+		streaks = append(streaks, run)
 	}
+	return streaks
 }
 
 func weekdayToString(day time.Weekday) string {
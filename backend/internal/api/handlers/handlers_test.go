@@ -0,0 +1,2977 @@
+package handlers_test
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/notifications"
+	"github.com/bruno.lopes/calendar/backend/internal/testutil"
+)
+
+func TestAddVacation(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	tests := []struct {
+		name       string
+		path       string
+		date       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"work day", "/api/vacations/2025", "2025-03-04", http.StatusOK, ""},
+		{"date in another year", "/api/vacations/2025", "2026-03-04", http.StatusBadRequest, models.CodeInvalidDate},
+		{"malformed date", "/api/vacations/2025", "2025-13-01", http.StatusBadRequest, models.CodeInvalidDate},
+		{"national holiday", "/api/vacations/2025", "2025-12-25", http.StatusBadRequest, models.CodeHolidayConflict},
+		{"year out of range", "/api/vacations/1800", "1800-03-04", http.StatusBadRequest, models.CodeInvalidYear},
+		{"year not a number", "/api/vacations/next", "2025-03-04", http.StatusBadRequest, models.CodeInvalidYear},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var problem models.Problem
+			status := srv.JSON(http.MethodPost, tt.path, map[string]string{"date": tt.date}, &problem)
+			if status != tt.wantStatus || problem.Code != tt.wantCode {
+				t.Errorf("POST %s %s: status %d, code %q, want %d, %q", tt.path, tt.date, status, problem.Code, tt.wantStatus, tt.wantCode)
+			}
+			if tt.wantCode != "" && (problem.Status != status || problem.Title == "" || problem.Detail == "" || problem.Instance != tt.path) {
+				t.Errorf("POST %s %s: incomplete problem %+v", tt.path, tt.date, problem)
+			}
+		})
+	}
+
+	resp := srv.Do(http.MethodPost, "/api/vacations/2025", map[string]string{"date": "2025-12-25"})
+	resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != models.ProblemContentType {
+		t.Errorf("error Content-Type = %q, want %s", got, models.ProblemContentType)
+	}
+
+	var vacations []models.VacationDay
+	srv.JSON(http.MethodGet, "/api/vacations/2025", nil, &vacations)
+	if len(vacations) != 1 || vacations[0].Date != "2025-03-04" {
+		t.Errorf("stored vacations = %+v, want only 2025-03-04", vacations)
+	}
+}
+
+func TestUpdateSetting(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	tests := []struct {
+		name       string
+		key        string
+		value      string
+		wantStatus int
+	}{
+		{"valid integer", "reminder_days_before", "5", http.StatusOK},
+		{"integer out of range", "reminder_days_before", "99", http.StatusBadRequest},
+		{"not an integer", "reminder_days_before", "soon", http.StatusBadRequest},
+		{"valid enum", "holiday_substitution", "next_monday", http.StatusOK},
+		{"invalid enum", "holiday_substitution", "sometimes", http.StatusBadRequest},
+		{"valid time zone", "timezone", "Europe/Lisbon", http.StatusOK},
+		{"unknown time zone", "timezone", "Europe/Atlantis", http.StatusBadRequest},
+		{"valid fallback models", "ai_fallback_models", "openai/gpt-4.1-mini, openai:gpt-4o", http.StatusOK},
+		{"fallback model of unknown provider", "ai_fallback_models", "anthropic:claude", http.StatusBadRequest},
+		{"too many correction rounds", "ai_correction_rounds", "10", http.StatusBadRequest},
+		{"unknown key", "favourite_colour", "blue", http.StatusBadRequest},
+		{"server managed key", "vapid_public_key", "abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := srv.JSON(http.MethodPut, "/api/settings/"+tt.key, map[string]string{"value": tt.value}, nil)
+			if status != tt.wantStatus {
+				t.Errorf("PUT %s=%q: status %d, want %d", tt.key, tt.value, status, tt.wantStatus)
+			}
+		})
+	}
+
+	var got map[string]string
+	srv.JSON(http.MethodGet, "/api/settings/reminder_days_before", nil, &got)
+	if got["reminder_days_before"] != "5" {
+		t.Errorf("reminder_days_before = %q after updates, want 5", got["reminder_days_before"])
+	}
+}
+
+func TestChangeWorkCity(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22}))
+
+	steps := []struct {
+		city    string
+		want    string // Municipal holiday of the city
+		notWant string // Municipal holiday of the previous city
+	}{
+		{"Lisboa", "2030-06-13", ""},
+		{"Porto", "2030-06-24", "2030-06-13"},
+		{"", "", "2030-06-24"},
+	}
+
+	for _, step := range steps {
+		if status := srv.JSON(http.MethodPut, "/api/settings", map[string]string{"work_city": step.city}, nil); status != http.StatusOK {
+			t.Fatalf("set work_city %q: status %d", step.city, status)
+		}
+
+		var calendar models.CalendarResponse
+		srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+
+		found := make(map[string]bool)
+		for _, hol := range calendar.Holidays {
+			if hol.Type == "municipal" {
+				found[hol.Date] = true
+			}
+		}
+		if step.want != "" && !found[step.want] {
+			t.Errorf("work_city %q: municipal holiday %s missing", step.city, step.want)
+		}
+		if step.notWant != "" && found[step.notWant] {
+			t.Errorf("work_city %q: still has the previous city's holiday %s", step.city, step.notWant)
+		}
+	}
+}
+
+func TestCalendarSummary(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2025, VacationDays: 22, ReservedDays: 2}),
+		// Monday to Wednesday
+		testutil.WithVacations(2025, "2025-03-03", "2025-03-04", "2025-03-05"),
+	)
+
+	var calendar models.CalendarResponse
+	if status := srv.JSON(http.MethodGet, "/api/calendar/2025", nil, &calendar); status != http.StatusOK {
+		t.Fatalf("GET calendar: status %d", status)
+	}
+
+	summary := calendar.Summary
+	if summary.TotalVacationDays != 22 {
+		t.Errorf("total vacation days = %d, want 22", summary.TotalVacationDays)
+	}
+	if summary.UsedVacationDays != 3 {
+		t.Errorf("used vacation days = %d, want 3", summary.UsedVacationDays)
+	}
+	if summary.RemainingVacationDays != 19 {
+		t.Errorf("remaining vacation days = %d, want 19", summary.RemainingVacationDays)
+	}
+	if len(calendar.Days) != 365 {
+		t.Errorf("calendar has %d days, want 365", len(calendar.Days))
+	}
+}
+
+func TestOptimizeVacations(t *testing.T) {
+	manual := []string{"2025-03-03", "2025-03-04"}
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2025, VacationDays: 10, OptimizationStrategy: models.StrategyBridgeHolidays}),
+		testutil.WithVacations(2025, manual...),
+	)
+
+	var result struct {
+		Blocks []models.VacationBlock `json:"blocks"`
+	}
+	if status := srv.JSON(http.MethodPost, "/api/calendar/2025/optimize", nil, &result); status != http.StatusOK {
+		t.Fatalf("POST optimize: status %d", status)
+	}
+	if len(result.Blocks) == 0 {
+		t.Fatal("optimizer returned no blocks")
+	}
+
+	var calendar models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2025", nil, &calendar)
+
+	// Manual days come out of the budget before the optimizer runs
+	if got := len(calendar.OptimalVacations); got == 0 || got > 10-len(manual) {
+		t.Errorf("stored %d optimized days, want between 1 and %d", got, 10-len(manual))
+	}
+	for _, v := range calendar.OptimalVacations {
+		for _, m := range manual {
+			if v.Date == m {
+				t.Errorf("optimized day %s is already a manual vacation day", v.Date)
+			}
+		}
+	}
+}
+
+func TestSmartOptimize(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22, OptimizationStrategy: models.StrategySmart}),
+	)
+
+	var result struct {
+		Blocks []models.VacationBlock `json:"blocks"`
+	}
+	if status := srv.JSON(http.MethodPost, "/api/calendar/2030/optimize", nil, &result); status != http.StatusOK {
+		t.Fatalf("POST optimize: status %d", status)
+	}
+	if len(result.Blocks) == 0 {
+		t.Fatal("smart optimizer returned no blocks")
+	}
+
+	// Only the AI's answer explains each day: the balanced fallback has no
+	// rationales
+	for _, block := range result.Blocks {
+		if len(block.Rationales) == 0 {
+			t.Errorf("block %s to %s has no rationales, want the AI's", block.StartDate, block.EndDate)
+		}
+		for date := range block.Rationales {
+			if date < block.StartDate || date > block.EndDate {
+				t.Errorf("rationale for %s outside the block %s to %s", date, block.StartDate, block.EndDate)
+			}
+		}
+	}
+}
+
+func TestHybridOptimize(t *testing.T) {
+	tests := []struct {
+		name           string
+		notes          string
+		wantRationales bool
+	}{
+		{"without notes keeps the baseline", "", false},
+		{"with notes refines the baseline", "Keep August free", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := testutil.NewServer(t,
+				testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22, OptimizationStrategy: models.StrategyHybrid, OptimizerNotes: tt.notes}),
+			)
+
+			var result struct {
+				Blocks []models.VacationBlock `json:"blocks"`
+			}
+			if status := srv.JSON(http.MethodPost, "/api/calendar/2030/optimize", nil, &result); status != http.StatusOK {
+				t.Fatalf("POST optimize: status %d", status)
+			}
+			if len(result.Blocks) == 0 {
+				t.Fatal("hybrid optimizer returned no blocks")
+			}
+			for _, block := range result.Blocks {
+				if got := len(block.Rationales) > 0; got != tt.wantRationales {
+					t.Errorf("block %s to %s has rationales %v, want %v", block.StartDate, block.EndDate, block.Rationales, tt.wantRationales)
+				}
+			}
+		})
+	}
+}
+
+func TestOptimizeMaxConsecutiveWeeks(t *testing.T) {
+	// Good Friday, 19 April 2030, and Liberty Day, Thursday 25 April, make a
+	// run of 10 days off with the manual days between them: the smart
+	// strategy's bridge on Friday the 26th would make it longer
+	manual := []string{"2030-04-22", "2030-04-23", "2030-04-24"}
+
+	for _, strategy := range []string{models.StrategyLongestBlocks, models.StrategyBalanced, models.StrategySmart} {
+		t.Run(strategy, func(t *testing.T) {
+			srv := testutil.NewServer(t,
+				testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22, OptimizationStrategy: strategy}),
+				testutil.WithVacations(2030, manual...),
+			)
+			rules := []map[string]interface{}{{"name": "One week at most", "kind": "max_consecutive_weeks", "max_weeks": 1}}
+			if status := srv.JSON(http.MethodPut, "/api/policies", rules, nil); status != http.StatusOK {
+				t.Fatalf("save rules: status %d", status)
+			}
+			if status := srv.JSON(http.MethodPost, "/api/calendar/2030/optimize", nil, nil); status != http.StatusOK {
+				t.Fatalf("POST optimize: status %d", status)
+			}
+
+			var calendar models.CalendarResponse
+			srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+			if len(calendar.OptimalVacations) == 0 {
+				t.Fatal("no optimized days stored")
+			}
+
+			run, optimal := 0, false
+			for _, day := range calendar.Days {
+				if !day.IsWeekend && !day.IsHoliday && !day.IsVacation && !day.IsOptimal && !day.IsCompDay {
+					run, optimal = 0, false
+					continue
+				}
+				run++
+				optimal = optimal || day.IsOptimal
+				if optimal && run > 7 {
+					t.Fatalf("%d days off in a row up to %s, want at most 7", run, day.Date)
+				}
+			}
+		})
+	}
+}
+
+func TestVacationSuggestionsCache(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22}),
+		testutil.WithVacations(2030, "2030-07-10"),
+	)
+
+	type suggestion struct {
+		Suggestion string `json:"suggestion"`
+		Cached     bool   `json:"cached"`
+	}
+
+	steps := []struct {
+		name       string
+		path       string
+		addDate    string // vacation day added before the request
+		wantCached bool
+	}{
+		{"first request", "/api/calendar/2030/suggestions", "", false},
+		{"same inputs", "/api/calendar/2030/suggestions", "", true},
+		{"other language", "/api/calendar/2030/suggestions?language=pt-PT", "", false},
+		{"forced", "/api/calendar/2030/suggestions?force=true", "", false},
+		{"after forcing", "/api/calendar/2030/suggestions", "", true},
+		{"vacations changed", "/api/calendar/2030/suggestions", "2030-07-11", false},
+	}
+
+	for _, step := range steps {
+		if step.addDate != "" {
+			if status := srv.JSON(http.MethodPost, "/api/vacations/2030", map[string]string{"date": step.addDate}, nil); status != http.StatusOK {
+				t.Fatalf("%s: add %s: status %d", step.name, step.addDate, status)
+			}
+		}
+
+		var got suggestion
+		if status := srv.JSON(http.MethodGet, step.path, nil, &got); status != http.StatusOK {
+			t.Fatalf("%s: status %d", step.name, status)
+		}
+		if got.Suggestion == "" {
+			t.Errorf("%s: empty suggestion", step.name)
+		}
+		if got.Cached != step.wantCached {
+			t.Errorf("%s: cached = %v, want %v", step.name, got.Cached, step.wantCached)
+		}
+	}
+}
+
+func TestShareLinks(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2025, VacationDays: 22, OptimizerNotes: "private"}),
+		testutil.WithVacations(2025, "2025-08-04", "2025-08-05"),
+	)
+
+	var link models.ShareLink
+	if status := srv.JSON(http.MethodPost, "/api/shares", map[string]interface{}{"year": 2025, "label": "Family"}, &link); status != http.StatusOK {
+		t.Fatalf("create share link: status %d", status)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"page", "/share/" + link.Token, http.StatusOK},
+		{"calendar", "/share/" + link.Token + "/calendar.json", http.StatusOK},
+		{"unknown token", "/share/nope", http.StatusNotFound},
+		{"unknown token calendar", "/share/nope/calendar.json", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := srv.Do(http.MethodGet, tt.path, nil)
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("GET %s: status %d, want %d", tt.path, resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+
+	var shared map[string]interface{}
+	srv.JSON(http.MethodGet, "/share/"+link.Token+"/calendar.json", nil, &shared)
+	for _, private := range []string{"config", "summary", "manual_vacations"} {
+		if _, ok := shared[private]; ok {
+			t.Errorf("shared calendar exposes %q", private)
+		}
+	}
+	if blocks, _ := shared["vacation_blocks"].([]interface{}); len(blocks) != 1 {
+		t.Errorf("shared calendar has %d vacation blocks, want 1", len(blocks))
+	}
+
+	if status := srv.JSON(http.MethodPost, "/api/shares", map[string]interface{}{"year": 2025, "expires_in_days": 400}, nil); status != http.StatusBadRequest {
+		t.Errorf("create share link valid for 400 days: status %d, want 400", status)
+	}
+
+	// Revoked links stop working
+	srv.JSON(http.MethodDelete, fmt.Sprintf("/api/shares/%d", link.ID), nil, nil)
+	resp := srv.Do(http.MethodGet, "/share/"+link.Token, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET revoked share link: status %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestCompDays(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 2}),
+		// Monday to Wednesday, one day beyond the annual leave
+		testutil.WithVacations(2030, "2030-03-04", "2030-03-05", "2030-03-06"),
+	)
+
+	entries := []struct {
+		kind string
+		date string
+		want int
+	}{
+		{"credit", "2030-03-09", http.StatusOK},         // Saturday
+		{"credit", "2030-03-10", http.StatusOK},         // Sunday
+		{"credit", "2030-03-11", http.StatusBadRequest}, // Work day
+		{"credit", "2030-04-25", http.StatusBadRequest}, // Holiday, worked holidays are credited on their own
+		{"spend", "2030-03-16", http.StatusBadRequest},  // Saturday
+		{"spend", "2030-03-04", http.StatusBadRequest},  // Already a vacation day
+		{"spend", "2030-03-12", http.StatusOK},
+		{"spend", "2030-03-13", http.StatusBadRequest}, // The other credit covers the extra vacation day
+	}
+
+	var spent models.CompDay
+	for _, e := range entries {
+		var entry models.CompDay
+		status := srv.JSON(http.MethodPost, "/api/comp-days/2030/"+e.kind, map[string]string{"date": e.date}, &entry)
+		if status != e.want {
+			t.Fatalf("%s %s: status %d, want %d", e.kind, e.date, status, e.want)
+		}
+		if status == http.StatusOK && e.kind == "spend" {
+			spent = entry
+		}
+	}
+
+	var ledger models.CompLedger
+	srv.JSON(http.MethodGet, "/api/comp-days/2030", nil, &ledger)
+	if ledger.Earned != 2 || ledger.Spent != 1 || ledger.UsedForVacation != 1 || ledger.Balance != 0 {
+		t.Errorf("ledger earned %d, spent %d, used for vacation %d, balance %d, want 2, 1, 1, 0",
+			ledger.Earned, ledger.Spent, ledger.UsedForVacation, ledger.Balance)
+	}
+
+	var calendar models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+
+	summary := calendar.Summary
+	if summary.TotalVacationDays != 2 || summary.RemainingVacationDays != 0 {
+		t.Errorf("annual leave total %d, remaining %d, want 2, 0", summary.TotalVacationDays, summary.RemainingVacationDays)
+	}
+	if summary.CompensationDays != 2 || summary.CompDaysUsed != 2 || summary.CompDaysRemaining != 0 {
+		t.Errorf("comp days earned %d, used %d, remaining %d, want 2, 2, 0",
+			summary.CompensationDays, summary.CompDaysUsed, summary.CompDaysRemaining)
+	}
+	for _, day := range calendar.Days {
+		if day.IsCompDay != (day.Date == "2030-03-12") {
+			t.Errorf("%s is comp day = %v", day.Date, day.IsCompDay)
+		}
+	}
+
+	path := fmt.Sprintf("/api/comp-days/2030/%d", spent.ID)
+	if status := srv.JSON(http.MethodDelete, path, nil, nil); status != http.StatusOK {
+		t.Errorf("DELETE comp day: status %d", status)
+	}
+	if status := srv.JSON(http.MethodDelete, path, nil, nil); status != http.StatusNotFound {
+		t.Errorf("DELETE removed comp day: status %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestBalanceAdjustments(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 10}),
+		testutil.WithVacations(2030, "2030-03-04", "2030-03-05", "2030-03-06"),
+	)
+
+	invalid := []map[string]interface{}{
+		{"date": "2030-02-01", "days": 0, "kind": "bonus", "reason": "Nothing"},
+		{"date": "2030-02-01", "days": 2, "kind": "gift", "reason": "Unknown kind"},
+		{"date": "2030-02-01", "days": 2, "kind": "bonus", "reason": " "},
+		{"date": "2031-02-01", "days": 2, "kind": "bonus", "reason": "Other year"},
+		{"date": "2030-02-01", "days": 400, "kind": "purchased", "reason": "Too many"},
+	}
+	for _, body := range invalid {
+		if status := srv.JSON(http.MethodPost, "/api/comp-days/2030/adjustments", body, nil); status != http.StatusBadRequest {
+			t.Errorf("POST %v: status %d, want %d", body, status, http.StatusBadRequest)
+		}
+	}
+
+	var bonus models.BalanceAdjustment
+	body := map[string]interface{}{"date": "2030-02-01", "days": 2, "kind": "bonus", "reason": "Project delivery"}
+	if status := srv.JSON(http.MethodPost, "/api/comp-days/2030/adjustments", body, &bonus); status != http.StatusOK {
+		t.Fatalf("POST bonus: status %d", status)
+	}
+
+	var ledger models.CompLedger
+	srv.JSON(http.MethodGet, "/api/comp-days/2030", nil, &ledger)
+	if len(ledger.Adjustments) != 1 || ledger.Adjusted != 2 || ledger.Balance != 2 {
+		t.Errorf("ledger after bonus: %d adjustments, adjusted %d, balance %d, want 1, 2, 2", len(ledger.Adjustments), ledger.Adjusted, ledger.Balance)
+	}
+
+	// A deduction beyond the pool comes out of the annual leave
+	body = map[string]interface{}{"date": "2030-03-01", "days": -5, "kind": "correction", "reason": "Days taken last year"}
+	if status := srv.JSON(http.MethodPost, "/api/comp-days/2030/adjustments", body, nil); status != http.StatusOK {
+		t.Fatalf("POST correction: status %d", status)
+	}
+
+	var calendar models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+	if summary := calendar.Summary; summary.RemainingVacationDays != 4 || summary.CompDaysRemaining != 0 {
+		t.Errorf("remaining %d, comp days remaining %d, want 4, 0", summary.RemainingVacationDays, summary.CompDaysRemaining)
+	}
+
+	path := fmt.Sprintf("/api/comp-days/2030/adjustments/%d", bonus.ID)
+	if status := srv.JSON(http.MethodDelete, path, nil, nil); status != http.StatusOK {
+		t.Errorf("DELETE adjustment: status %d", status)
+	}
+	if status := srv.JSON(http.MethodDelete, path, nil, nil); status != http.StatusNotFound {
+		t.Errorf("DELETE removed adjustment: status %d, want %d", status, http.StatusNotFound)
+	}
+
+	srv.JSON(http.MethodGet, "/api/comp-days/2030", nil, &ledger)
+	if ledger.Adjusted != -5 || ledger.Balance != -5 {
+		t.Errorf("ledger after removing the bonus: adjusted %d, balance %d, want -5, -5", ledger.Adjusted, ledger.Balance)
+	}
+}
+
+func TestBlackoutPeriods(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22, OptimizationStrategy: "balanced"}))
+
+	invalid := [][]models.BlackoutPeriod{
+		{{StartDate: "2030-06-10", EndDate: "2030-06-01"}},
+		{{StartDate: "2030-12-20", EndDate: "2031-01-05"}},
+		{{StartDate: "June", EndDate: "2030-06-01"}},
+	}
+	for _, periods := range invalid {
+		if status := srv.JSON(http.MethodPut, "/api/config/2030", map[string]interface{}{"blackout_periods": periods}, nil); status != http.StatusBadRequest {
+			t.Errorf("PUT blackout %v: status %d, want %d", periods, status, http.StatusBadRequest)
+		}
+	}
+
+	summary := func() models.CalendarSummary {
+		t.Helper()
+		var calendar models.CalendarResponse
+		if status := srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar); status != http.StatusOK {
+			t.Fatalf("GET calendar: status %d", status)
+		}
+		return calendar.Summary
+	}
+	if s := summary(); s.UnusedDaysAtRisk || s.SchedulableDays < 22 {
+		t.Errorf("open year: schedulable %d, at risk %v, want at least 22 and not at risk", s.SchedulableDays, s.UnusedDaysAtRisk)
+	}
+
+	// Only the second half of December stays open
+	periods := []models.BlackoutPeriod{{StartDate: "2030-01-01", EndDate: "2030-12-15", Reason: "Migration project"}}
+	var config models.YearConfig
+	if status := srv.JSON(http.MethodPut, "/api/config/2030", map[string]interface{}{"blackout_periods": periods}, &config); status != http.StatusOK {
+		t.Fatalf("PUT blackout: status %d", status)
+	}
+	if len(config.BlackoutPeriods) != 1 || config.BlackoutPeriods[0].Reason != "Migration project" {
+		t.Errorf("blackout periods = %+v", config.BlackoutPeriods)
+	}
+
+	// 11 open work days from 16 December, without Christmas, 30% of them
+	if s := summary(); !s.UnusedDaysAtRisk || s.SchedulableDays != 3 {
+		t.Errorf("blacked out year: schedulable %d, at risk %v, want 3 and at risk", s.SchedulableDays, s.UnusedDaysAtRisk)
+	}
+
+	if status := srv.JSON(http.MethodPost, "/api/calendar/2030/optimize", nil, nil); status != http.StatusOK {
+		t.Fatalf("POST optimize: status %d", status)
+	}
+	var calendar models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+	if len(calendar.OptimalVacations) == 0 {
+		t.Fatal("optimizer booked nothing in the open days")
+	}
+	for _, v := range calendar.OptimalVacations {
+		if v.Date <= "2030-12-15" {
+			t.Errorf("optimizer booked %s, in the blackout period", v.Date)
+		}
+	}
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	var prefs notifications.Preferences
+	if status := srv.JSON(http.MethodGet, "/api/notifications/preferences", nil, &prefs); status != http.StatusOK {
+		t.Fatalf("GET preferences: status %d", status)
+	}
+	if len(prefs.Channels) != 0 || len(prefs.Kinds) != 0 || prefs.QuietStart != "" {
+		t.Errorf("default preferences = %+v, want every channel and kind", prefs)
+	}
+
+	invalid := []map[string]interface{}{
+		{"channels": []string{"sms"}},
+		{"kinds": []string{"birthday"}},
+		{"email": "not an address"},
+		{"quiet_start": "22:00"},
+		{"quiet_start": "25:00", "quiet_end": "07:00"},
+	}
+	for _, body := range invalid {
+		if status := srv.JSON(http.MethodPut, "/api/notifications/preferences", body, nil); status != http.StatusBadRequest {
+			t.Errorf("PUT %v: status %d, want %d", body, status, http.StatusBadRequest)
+		}
+	}
+
+	body := map[string]interface{}{
+		"channels":    []string{"email"},
+		"email":       "me@example.com",
+		"kinds":       []string{"vacation_reminder", "unused_days"},
+		"quiet_start": "22:00",
+		"quiet_end":   "07:30",
+	}
+	if status := srv.JSON(http.MethodPut, "/api/notifications/preferences", body, nil); status != http.StatusOK {
+		t.Fatalf("PUT preferences: status %d", status)
+	}
+	srv.JSON(http.MethodGet, "/api/notifications/preferences", nil, &prefs)
+	if len(prefs.Channels) != 1 || prefs.Email != "me@example.com" || len(prefs.Kinds) != 2 || prefs.QuietEnd != "07:30" {
+		t.Errorf("saved preferences = %+v", prefs)
+	}
+}
+
+func TestLiveUpdates(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	if status := srv.JSON(http.MethodGet, "/api/calendar/live", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("GET without upgrade: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/calendar/live?year=2030"
+	ws, err := websocket.Dial(url, "", srv.URL)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer ws.Close()
+
+	// The other year's change is filtered out, so the first event is 2030's
+	srv.JSON(http.MethodPost, "/api/vacations/2031", map[string]string{"date": "2031-03-04"}, nil)
+	srv.JSON(http.MethodPost, "/api/vacations/2030", map[string]string{"date": "2030-03-04"}, nil)
+
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var event struct {
+		Type string `json:"type"`
+		Year int    `json:"year"`
+		Data struct {
+			Dates []string `json:"dates"`
+		} `json:"data"`
+	}
+	if err := websocket.JSON.Receive(ws, &event); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	if event.Type != "vacation.added" || event.Year != 2030 || len(event.Data.Dates) != 1 || event.Data.Dates[0] != "2030-03-04" {
+		t.Errorf("event = %+v, want vacation.added of 2030-03-04", event)
+	}
+
+	// Changes that replace a whole plan are sent too, and so are edit locks;
+	// renewing a lock is not
+	srv.ClientID = "tab-1"
+	srv.JSON(http.MethodPost, "/api/calendar/2030/lock", nil, nil)
+	srv.JSON(http.MethodPost, "/api/calendar/2030/lock", nil, nil)
+	var scenario models.Scenario
+	srv.JSON(http.MethodPost, "/api/scenarios/2030", map[string]string{"name": "Plan B"}, &scenario)
+	srv.JSON(http.MethodPost, fmt.Sprintf("/api/scenarios/2030/%d/activate", scenario.ID), nil, nil)
+	srv.JSON(http.MethodDelete, "/api/calendar/2030/optimized", nil, nil)
+	srv.JSON(http.MethodDelete, "/api/calendar/2030/lock", nil, nil)
+	srv.JSON(http.MethodDelete, "/api/admin/data/2030", nil, nil)
+
+	for _, want := range []string{"editlock.acquired", "scenario.activated", "optimization.cleared", "editlock.released", "year.purged"} {
+		event.Type = ""
+		if err := websocket.JSON.Receive(ws, &event); err != nil {
+			t.Fatalf("receive %s: %v", want, err)
+		}
+		if event.Type != want || event.Year != 2030 {
+			t.Errorf("event = %s of %d, want %s of 2030", event.Type, event.Year, want)
+		}
+	}
+
+	// Other sites' pages can't connect
+	if _, err := websocket.Dial(url, "", "https://elsewhere.example"); err == nil {
+		t.Error("dial from another origin succeeded")
+	}
+}
+
+func TestOptimisticConcurrency(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22}))
+
+	put := func(path, etag string, body interface{}) *http.Response {
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPut, srv.URL+path, strings.NewReader(string(data)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", etag)
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatalf("PUT %s: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := srv.Do(http.MethodGet, "/api/config/2030", nil)
+	resp.Body.Close()
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("GET config: no ETag")
+	}
+
+	// The first client saves, the second one read the same version and loses
+	first := put("/api/config/2030", etag, map[string]int{"vacation_days": 23})
+	if first.StatusCode != http.StatusOK || first.Header.Get("ETag") == etag {
+		t.Errorf("first PUT: status %d, ETag %s, want %d and a new ETag", first.StatusCode, first.Header.Get("ETag"), http.StatusOK)
+	}
+	if second := put("/api/config/2030", etag, map[string]int{"vacation_days": 25}); second.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("stale PUT: status %d, want %d", second.StatusCode, http.StatusPreconditionFailed)
+	}
+
+	var config models.YearConfig
+	srv.JSON(http.MethodGet, "/api/config/2030", nil, &config)
+	if config.VacationDays != 23 {
+		t.Errorf("vacation_days = %d, want 23 from the first PUT", config.VacationDays)
+	}
+	var problem models.Problem
+	if status := srv.JSON(http.MethodPut, "/api/config/2030", map[string]int{"vacation_days": 25, "version": config.Version - 1}, &problem); status != http.StatusPreconditionFailed || problem.Code != models.CodeVersionConflict {
+		t.Errorf("stale version in body: status %d, code %q", status, problem.Code)
+	}
+
+	// Vacation days changed by someone else, here the single day endpoint,
+	// fail a bulk update read before
+	resp = srv.Do(http.MethodGet, "/api/vacations/2030", nil)
+	resp.Body.Close()
+	etag = resp.Header.Get("ETag")
+	srv.JSON(http.MethodPost, "/api/vacations/2030", map[string]string{"date": "2030-03-04"}, nil)
+	if stale := put("/api/vacations/2030/bulk", etag, map[string][]string{"add": {"2030-03-05"}}); stale.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("stale bulk PUT: status %d, want %d", stale.StatusCode, http.StatusPreconditionFailed)
+	}
+
+	resp = srv.Do(http.MethodGet, "/api/vacations/2030", nil)
+	resp.Body.Close()
+	if fresh := put("/api/vacations/2030/bulk", resp.Header.Get("ETag"), map[string][]string{"add": {"2030-03-05"}}); fresh.StatusCode != http.StatusOK {
+		t.Errorf("bulk PUT: status %d, want %d", fresh.StatusCode, http.StatusOK)
+	}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	post := func(key string, body interface{}) (*http.Response, models.PersonalEvent) {
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/personal-events/2030", strings.NewReader(string(data)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", key)
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatalf("POST: %v", err)
+		}
+		defer resp.Body.Close()
+		var event models.PersonalEvent
+		json.NewDecoder(resp.Body).Decode(&event)
+		return resp, event
+	}
+
+	body := map[string]string{"date": "2030-05-10", "name": "Wedding"}
+	first, created := post("retry-1", body)
+	retry, replayed := post("retry-1", body)
+	if first.StatusCode != http.StatusOK || retry.StatusCode != http.StatusOK {
+		t.Fatalf("statuses %d and %d, want %d", first.StatusCode, retry.StatusCode, http.StatusOK)
+	}
+	if retry.Header.Get("Idempotent-Replayed") != "true" || replayed.ID != created.ID {
+		t.Errorf("retry = event %d, replayed %q, want event %d replayed", replayed.ID, retry.Header.Get("Idempotent-Replayed"), created.ID)
+	}
+
+	var events []models.PersonalEvent
+	srv.JSON(http.MethodGet, "/api/personal-events/2030", nil, &events)
+	if len(events) != 1 {
+		t.Errorf("%d events stored, want 1", len(events))
+	}
+
+	if reused, _ := post("retry-1", map[string]string{"date": "2030-06-10", "name": "Party"}); reused.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("key reused for another body: status %d, want %d", reused.StatusCode, http.StatusUnprocessableEntity)
+	}
+
+	// Failures release the key, so a retry runs again
+	if failed, _ := post("retry-2", map[string]string{"date": "2031-05-10", "name": "Trip"}); failed.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid date: status %d, want %d", failed.StatusCode, http.StatusBadRequest)
+	}
+	if again, _ := post("retry-2", map[string]string{"date": "2031-05-10", "name": "Trip"}); again.Header.Get("Idempotent-Replayed") != "" {
+		t.Error("failed request was replayed")
+	}
+}
+
+func TestBulkUpdateVacations(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 3}),
+		testutil.WithVacations(2030, "2030-03-04", "2030-03-11"),
+	)
+
+	var out struct {
+		Results []models.BulkItemResult `json:"results"`
+		Counts  map[string]int          `json:"counts"`
+	}
+	body := map[string][]string{
+		"remove": {"2030-03-11", "2030-03-12"},
+		"add":    {"2030-12-25", "2030-03-04", "2030-03-05", "2030-03-06", "2030-03-07"},
+	}
+	if status := srv.JSON(http.MethodPut, "/api/vacations/2030/bulk", body, &out); status != http.StatusOK {
+		t.Fatalf("PUT bulk: status %d", status)
+	}
+
+	// One day left plus the one removed pays for two new days
+	want := map[string]string{
+		"remove 2030-03-11": "applied",
+		"remove 2030-03-12": "unchanged",
+		"add 2030-12-25":    "skipped_holiday",
+		"add 2030-03-04":    "skipped_duplicate",
+		"add 2030-03-05":    "created",
+		"add 2030-03-06":    "created",
+		"add 2030-03-07":    "over_budget",
+	}
+	if len(out.Results) != len(want) {
+		t.Fatalf("results = %+v, want %d", out.Results, len(want))
+	}
+	for _, r := range out.Results {
+		if got := want[r.Action+" "+r.Date]; r.Status != got {
+			t.Errorf("%s %s: status %q, want %q", r.Action, r.Date, r.Status, got)
+		}
+	}
+	if out.Counts["created"] != 2 || out.Counts["over_budget"] != 1 {
+		t.Errorf("counts = %v", out.Counts)
+	}
+
+	var vacations []models.VacationDay
+	srv.JSON(http.MethodGet, "/api/vacations/2030", nil, &vacations)
+	if len(vacations) != 3 {
+		t.Errorf("stored vacations = %+v, want 03-04, 03-05 and 03-06", vacations)
+	}
+}
+
+func TestRemoveVacationRange(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithVacations(2030, "2030-03-04", "2030-03-05", "2030-03-20", "2030-07-01"))
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{"end before start", "start=2030-03-10&end=2030-03-01", http.StatusBadRequest},
+		{"missing end", "start=2030-03-01", http.StatusBadRequest},
+		{"other year", "start=2031-03-01&end=2031-03-10", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if status := srv.JSON(http.MethodDelete, "/api/vacations/2030/range?"+tt.query, nil, nil); status != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d", tt.name, status, tt.wantStatus)
+		}
+	}
+
+	var out struct {
+		Removed []string `json:"removed"`
+	}
+	if status := srv.JSON(http.MethodDelete, "/api/vacations/2030/range?start=2030-03-01&end=2030-03-10", nil, &out); status != http.StatusOK {
+		t.Fatalf("DELETE range: status %d", status)
+	}
+	if len(out.Removed) != 2 || out.Removed[0] != "2030-03-04" || out.Removed[1] != "2030-03-05" {
+		t.Errorf("removed = %v, want 2030-03-04 and 2030-03-05", out.Removed)
+	}
+
+	// A locked day keeps the whole range
+	srv.JSON(http.MethodPost, "/api/vacations/2030/locked", map[string]interface{}{"dates": []string{"2030-07-01"}}, nil)
+	if status := srv.JSON(http.MethodDelete, "/api/vacations/2030/range?start=2030-03-01&end=2030-07-31", nil, nil); status != http.StatusConflict {
+		t.Errorf("range with a locked day: status %d, want %d", status, http.StatusConflict)
+	}
+
+	var vacations []models.VacationDay
+	srv.JSON(http.MethodGet, "/api/vacations/2030", nil, &vacations)
+	if len(vacations) != 2 {
+		t.Errorf("stored vacations = %+v, want 03-20 and 07-01", vacations)
+	}
+}
+
+func TestMoveVacations(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithVacations(2030, "2030-03-04", "2030-03-05", "2030-06-03"))
+
+	move := func(from, to string) map[string]interface{} {
+		return map[string]interface{}{"moves": []map[string]string{{"from": from, "to": to}}}
+	}
+	tests := []struct {
+		name       string
+		body       interface{}
+		wantStatus int
+		wantCode   string
+	}{
+		{"weekend", move("2030-03-04", "2030-03-09"), http.StatusBadRequest, models.CodeInvalidRequest},
+		{"holiday", move("2030-03-04", "2030-04-25"), http.StatusBadRequest, models.CodeHolidayConflict},
+		{"not a vacation day", move("2030-03-06", "2030-03-07"), http.StatusNotFound, models.CodeNotFound},
+		{"onto a booked day", move("2030-03-04", "2030-06-03"), http.StatusBadRequest, models.CodeInvalidRequest},
+		{"other year", move("2030-03-04", "2031-03-04"), http.StatusBadRequest, models.CodeInvalidDate},
+		{"dates without shift", map[string]interface{}{"dates": []string{"2030-03-04"}}, http.StatusBadRequest, models.CodeInvalidRequest},
+	}
+	for _, tt := range tests {
+		var problem models.Problem
+		if status := srv.JSON(http.MethodPost, "/api/vacations/2030/move", tt.body, &problem); status != tt.wantStatus || problem.Code != tt.wantCode {
+			t.Errorf("%s: status %d, code %q, want %d, %q", tt.name, status, problem.Code, tt.wantStatus, tt.wantCode)
+		}
+	}
+
+	// The March block one week later, then its first day onto its second
+	shift := map[string]interface{}{"dates": []string{"2030-03-04", "2030-03-05"}, "shift_days": 7}
+	if status := srv.JSON(http.MethodPost, "/api/vacations/2030/move", shift, nil); status != http.StatusOK {
+		t.Fatalf("shift: status %d", status)
+	}
+	chain := map[string]interface{}{"moves": []map[string]string{
+		{"from": "2030-03-11", "to": "2030-03-12"},
+		{"from": "2030-03-12", "to": "2030-03-13"},
+	}}
+	if status := srv.JSON(http.MethodPost, "/api/vacations/2030/move", chain, nil); status != http.StatusOK {
+		t.Fatalf("chained moves: status %d", status)
+	}
+
+	var vacations []models.VacationDay
+	srv.JSON(http.MethodGet, "/api/vacations/2030", nil, &vacations)
+	got := map[string]bool{}
+	for _, v := range vacations {
+		got[v.Date] = true
+	}
+	if len(got) != 3 || !got["2030-03-12"] || !got["2030-03-13"] || !got["2030-06-03"] {
+		t.Errorf("vacations after moving = %v, want 03-12, 03-13 and 06-03", got)
+	}
+}
+
+func TestCopyVacationPlan(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2029, VacationDays: 22}),
+		testutil.WithVacations(2029, "2029-04-16", "2029-04-20", "2029-06-21"),
+	)
+
+	var problem models.Problem
+	if status := srv.JSON(http.MethodPost, "/api/config/2030/copy-from/2029?vacations=same_month", nil, &problem); status != http.StatusBadRequest {
+		t.Errorf("unknown mapping: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	var out struct {
+		Vacations []models.CopiedVacation `json:"vacations"`
+		Counts    map[string]int          `json:"counts"`
+	}
+	if status := srv.JSON(http.MethodPost, "/api/config/2030/copy-from/2029?vacations=same_weekday", nil, &out); status != http.StatusOK {
+		t.Fatalf("copy: status %d", status)
+	}
+
+	// Good Friday and Corpus Christi move with Easter, onto the mapped days
+	want := map[string]models.CopiedVacation{
+		"2029-04-16": {To: "2030-04-15", Status: "created"},
+		"2029-04-20": {To: "2030-04-19", Status: "skipped_holiday"},
+		"2029-06-21": {To: "2030-06-20", Status: "skipped_holiday"},
+	}
+	if len(out.Vacations) != len(want) {
+		t.Fatalf("vacations = %+v, want %d", out.Vacations, len(want))
+	}
+	for _, got := range out.Vacations {
+		w := want[got.From]
+		if got.To != w.To || got.Status != w.Status {
+			t.Errorf("%s: to %s, %s, want %s, %s", got.From, got.To, got.Status, w.To, w.Status)
+		}
+		if got.Status == "skipped_holiday" && got.Holiday == "" {
+			t.Errorf("%s: skipped without the holiday's name", got.From)
+		}
+	}
+	if out.Counts["created"] != 1 || out.Counts["skipped_holiday"] != 2 {
+		t.Errorf("counts = %v", out.Counts)
+	}
+
+	var vacations []models.VacationDay
+	srv.JSON(http.MethodGet, "/api/vacations/2030", nil, &vacations)
+	if len(vacations) != 1 || vacations[0].Date != "2030-04-15" {
+		t.Errorf("vacations of 2030 = %+v, want 2030-04-15", vacations)
+	}
+
+	// Copying again finds the day already booked
+	srv.JSON(http.MethodPost, "/api/config/2030/copy-from/2029?vacations=same_weekday", nil, &out)
+	if out.Counts["skipped_duplicate"] != 1 {
+		t.Errorf("counts copying again = %v", out.Counts)
+	}
+}
+
+func TestHolidayOverrides(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22}))
+
+	holidayNames := func() map[string]string {
+		var calendar models.CalendarResponse
+		srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+		names := make(map[string]string)
+		for _, day := range calendar.Days {
+			if day.IsHoliday {
+				names[day.Date] = day.HolidayName
+			}
+		}
+		return names
+	}
+
+	tests := []struct {
+		name     string
+		date     string
+		body     map[string]string
+		wantCode string
+	}{
+		{"not a holiday", "2030-03-12", map[string]string{"action": "suppress"}, models.CodeInvalidDate},
+		{"unknown action", "2030-06-10", map[string]string{"action": "ignore"}, models.CodeInvalidRequest},
+		{"correction without changes", "2030-06-10", map[string]string{"action": "correct"}, models.CodeInvalidRequest},
+		{"correction to another year", "2030-06-10", map[string]string{"action": "correct", "new_date": "2031-06-10"}, models.CodeInvalidDate},
+	}
+	for _, tt := range tests {
+		var problem models.Problem
+		if status := srv.JSON(http.MethodPut, "/api/holidays/2030/overrides/"+tt.date, tt.body, &problem); status != http.StatusBadRequest || problem.Code != tt.wantCode {
+			t.Errorf("%s: status %d, code %q, want 400, %q", tt.name, status, problem.Code, tt.wantCode)
+		}
+	}
+
+	suppress := map[string]string{"action": "suppress", "note": "Not observed"}
+	if status := srv.JSON(http.MethodPut, "/api/holidays/2030/overrides/2030-06-10", suppress, nil); status != http.StatusOK {
+		t.Fatalf("suppress: status %d", status)
+	}
+	correct := map[string]string{"action": "correct", "new_date": "2030-12-09", "name": "Imaculada Conceição (moved)"}
+	if status := srv.JSON(http.MethodPut, "/api/holidays/2030/overrides/2030-12-08", correct, nil); status != http.StatusOK {
+		t.Fatalf("correct: status %d", status)
+	}
+
+	names := holidayNames()
+	if _, ok := names["2030-06-10"]; ok {
+		t.Error("suppressed 2030-06-10 is still a holiday")
+	}
+	if _, ok := names["2030-12-08"]; ok {
+		t.Error("corrected 2030-12-08 is still a holiday")
+	}
+	if names["2030-12-09"] != "Imaculada Conceição (moved)" {
+		t.Errorf("2030-12-09 = %q, want the corrected holiday", names["2030-12-09"])
+	}
+
+	var overrides []models.HolidayOverride
+	srv.JSON(http.MethodGet, "/api/holidays/2030/overrides", nil, &overrides)
+	if len(overrides) != 2 || overrides[0].Date != "2030-06-10" || overrides[0].Note != "Not observed" {
+		t.Errorf("overrides = %+v", overrides)
+	}
+
+	if status := srv.JSON(http.MethodDelete, "/api/holidays/2030/overrides/2030-06-10", nil, nil); status != http.StatusOK {
+		t.Fatalf("remove: status %d", status)
+	}
+	if status := srv.JSON(http.MethodDelete, "/api/holidays/2030/overrides/2030-06-10", nil, nil); status != http.StatusNotFound {
+		t.Errorf("remove again: status %d, want %d", status, http.StatusNotFound)
+	}
+	if _, ok := holidayNames()["2030-06-10"]; !ok {
+		t.Error("2030-06-10 is not a holiday once its override is removed")
+	}
+}
+
+func TestHolidaySources(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22, OptionalHolidays: []string{"carnaval"}}),
+	)
+	if status := srv.JSON(http.MethodPut, "/api/holidays/2030/overrides/2030-12-08", map[string]string{"action": "correct", "name": "Imaculada"}, nil); status != http.StatusOK {
+		t.Fatalf("override: status %d", status)
+	}
+
+	var list []holidays.PortugueseHoliday
+	if status := srv.JSON(http.MethodGet, "/api/holidays/2030", nil, &list); status != http.StatusOK {
+		t.Fatalf("holidays: status %d", status)
+	}
+	want := map[string]string{
+		"2030-01-01": holidays.SourceNager,
+		"2030-03-05": holidays.SourceCustom,
+		"2030-12-08": holidays.SourceOverride,
+	}
+	for _, hol := range list {
+		if source, ok := want[hol.Date]; ok && hol.Source != source {
+			t.Errorf("%s source = %q, want %q", hol.Date, hol.Source, source)
+		}
+		if hol.Source == holidays.SourceNager && hol.FetchedAt == "" {
+			t.Errorf("%s has no fetch time", hol.Date)
+		}
+	}
+
+	srv.JSON(http.MethodGet, "/api/holidays/2030?preview=true", nil, &list)
+	for _, hol := range list {
+		if hol.Type == "national" && hol.Date != "2030-12-08" && hol.Source != holidays.SourceFallback {
+			t.Errorf("preview %s source = %q, want %q", hol.Date, hol.Source, holidays.SourceFallback)
+		}
+	}
+}
+
+func TestHolidayFilters(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithSetting("work_city", "Lisboa"),
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22, OptionalHolidays: []string{"carnaval"}}),
+	)
+
+	dates := func(query string) []string {
+		t.Helper()
+		var list []holidays.PortugueseHoliday
+		if status := srv.JSON(http.MethodGet, "/api/holidays/2030"+query, nil, &list); status != http.StatusOK {
+			t.Fatalf("%s: status %d", query, status)
+		}
+		var got []string
+		for _, hol := range list {
+			got = append(got, hol.Date)
+		}
+		sort.Strings(got)
+		return got
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"?type=municipal", []string{"2030-06-13"}},
+		{"?type=custom", []string{"2030-03-05"}},
+		{"?type=national&from=2030-04-01&to=2030-04-30", []string{"2030-04-19", "2030-04-21", "2030-04-25"}},
+		{"?from=2030-06-01&to=2030-06-15", []string{"2030-06-10", "2030-06-13"}},
+		{"?location=lisboa", []string{"2030-06-13"}},
+		{"?location=Porto", nil},
+	}
+	for _, tt := range tests {
+		if got := dates(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	for query, code := range map[string]string{
+		"?type=religious":                models.CodeInvalidRequest,
+		"?from=2031-01-01":               models.CodeInvalidDate,
+		"?from=2030-06-01&to=2030-05-01": models.CodeInvalidDate,
+	} {
+		var problem models.Problem
+		if status := srv.JSON(http.MethodGet, "/api/holidays/2030"+query, nil, &problem); status != http.StatusBadRequest || problem.Code != code {
+			t.Errorf("%s: status %d, code %q, want 400, %q", query, status, problem.Code, code)
+		}
+	}
+}
+
+func TestGetDay(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22}),
+		testutil.WithVacations(2030, "2030-04-22"),
+	)
+
+	tests := []struct {
+		date    string
+		kind    string
+		workDay bool
+		holiday bool
+		inBlock bool
+	}{
+		{"2030-03-12", models.DayWorkDay, true, false, false},
+		{"2030-03-09", models.DayOffWeekend, false, false, false},
+		{"2030-04-25", models.DayOffHoliday, true, true, false},
+		{"2030-04-22", models.DayOffVacation, true, false, true},
+	}
+	for _, tt := range tests {
+		var day models.DayStatus
+		if status := srv.JSON(http.MethodGet, "/api/calendar/day/"+tt.date, nil, &day); status != http.StatusOK {
+			t.Fatalf("%s: status %d", tt.date, status)
+		}
+		if day.Date != tt.date || day.Kind != tt.kind || day.IsDayOff != (tt.kind != models.DayWorkDay) || day.IsWorkDay != tt.workDay {
+			t.Errorf("%s = %s, day off %v, work day %v, want %s, work day %v", tt.date, day.Kind, day.IsDayOff, day.IsWorkDay, tt.kind, tt.workDay)
+		}
+		if (day.Holiday != nil) != tt.holiday || (tt.holiday && day.Holiday.Name == "") {
+			t.Errorf("%s holiday = %+v", tt.date, day.Holiday)
+		}
+		if (day.Block != nil) != tt.inBlock {
+			t.Errorf("%s block = %+v", tt.date, day.Block)
+		}
+	}
+
+	var problem models.Problem
+	if status := srv.JSON(http.MethodGet, "/api/calendar/day/2030-02-30", nil, &problem); status != http.StatusBadRequest || problem.Code != models.CodeInvalidDate {
+		t.Errorf("invalid date: status %d, code %q", status, problem.Code)
+	}
+}
+
+func TestMixedVacationBlocks(t *testing.T) {
+	// A manual Monday before an optimized Tuesday to Friday, and a lone manual day
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22}),
+		testutil.WithVacations(2030, "2030-07-08", "2030-10-01"),
+		testutil.WithOptimalVacations(2030, "2030-07-09", "2030-07-10", "2030-07-11", "2030-07-12"),
+	)
+
+	var calendar models.CalendarResponse
+	if status := srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar); status != http.StatusOK {
+		t.Fatalf("status %d", status)
+	}
+
+	if len(calendar.VacationBlocks) != 2 {
+		t.Fatalf("blocks = %+v, want 2", calendar.VacationBlocks)
+	}
+	mixed := calendar.VacationBlocks[0]
+	if mixed.StartDate != "2030-07-06" || mixed.EndDate != "2030-07-14" || mixed.TotalDays != 9 || mixed.VacationDaysUsed != 5 || mixed.Source != "mixed" {
+		t.Errorf("first block = %s to %s, %d days off for %d, %q, want 2030-07-06 to 2030-07-14, 9 for 5, mixed",
+			mixed.StartDate, mixed.EndDate, mixed.TotalDays, mixed.VacationDaysUsed, mixed.Source)
+	}
+	if manual := calendar.VacationBlocks[1]; manual.StartDate != "2030-10-01" || manual.TotalDays != 1 || manual.Source != "manual" {
+		t.Errorf("second block = %s, %d days off, %q, want 2030-10-01, 1, manual", manual.StartDate, manual.TotalDays, manual.Source)
+	}
+
+	if got := calendar.Summary.LongestVacationBlock; got != 9 {
+		t.Errorf("longest_vacation_block = %d, want 9", got)
+	}
+	if got := calendar.Summary.Efficiency; got != models.BlockEfficiency(10, 6) {
+		t.Errorf("efficiency = %v, want %v", got, models.BlockEfficiency(10, 6))
+	}
+}
+
+func TestTotalDaysOff(t *testing.T) {
+	// 2030 has six lone weekday holidays, three on a weekend (October 5 and
+	// December 1 and 8) and three long weekends (Easter, June 10 and
+	// November 1), the vacation day on Monday July 8 making a fourth
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22}),
+		testutil.WithVacations(2030, "2030-07-08"),
+	)
+
+	var calendar models.CalendarResponse
+	if status := srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar); status != http.StatusOK {
+		t.Fatalf("status %d", status)
+	}
+
+	want := []models.StreakCount{{Days: 1, Count: 6}, {Days: 2, Count: 3}, {Days: 3, Count: 4}}
+	if got := calendar.Summary.StreakDistribution; !reflect.DeepEqual(got, want) {
+		t.Errorf("streak_distribution = %+v, want %+v", got, want)
+	}
+	if got := calendar.Summary.TotalDaysOff; got != 24 {
+		t.Errorf("total_days_off = %d, want 24", got)
+	}
+}
+
+func TestImportOnCall(t *testing.T) {
+	release := models.BlackoutPeriod{StartDate: "2030-08-01", EndDate: "2030-08-02", Reason: "Release"}
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22, BlackoutPeriods: []models.BlackoutPeriod{release}}),
+		testutil.WithVacations(2030, "2030-03-05"),
+	)
+
+	var values map[string]string
+	srv.JSON(http.MethodGet, "/api/settings", nil, &values)
+	if provider, ok := values["oncall_provider"]; !ok || provider != "none" {
+		t.Errorf("settings oncall_provider = %q (listed %v), want none", provider, ok)
+	}
+
+	if status := srv.JSON(http.MethodPost, "/api/oncall/import/2030", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("without a provider: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	srv.JSON(http.MethodPut, "/api/settings/oncall_provider", map[string]string{"value": "pagerduty"}, nil)
+
+	// Sandbox shifts are the weeks from the first Monday of March and of October
+	march := models.BlackoutPeriod{StartDate: "2030-03-04", EndDate: "2030-03-10", Reason: "On call (PagerDuty)", Source: models.BlackoutOnCall}
+	october := models.BlackoutPeriod{StartDate: "2030-10-07", EndDate: "2030-10-13", Reason: "On call (PagerDuty)", Source: models.BlackoutOnCall}
+	for i := 0; i < 2; i++ {
+		var result models.OnCallImport
+		if status := srv.JSON(http.MethodPost, "/api/oncall/import/2030", nil, &result); status != http.StatusOK {
+			t.Fatalf("import %d: status %d", i+1, status)
+		}
+		if !reflect.DeepEqual(result.Periods, []models.BlackoutPeriod{march, october}) {
+			t.Errorf("import %d periods = %+v, want the March and October weeks", i+1, result.Periods)
+		}
+		if !reflect.DeepEqual(result.Conflicts, []string{"2030-03-05"}) {
+			t.Errorf("import %d conflicts = %v, want [2030-03-05]", i+1, result.Conflicts)
+		}
+
+		// Every import replaces the on-call periods and keeps the others
+		var config models.YearConfig
+		srv.JSON(http.MethodGet, "/api/config/2030", nil, &config)
+		if want := []models.BlackoutPeriod{march, release, october}; !reflect.DeepEqual(config.BlackoutPeriods, want) {
+			t.Errorf("import %d blackout periods = %+v, want %+v", i+1, config.BlackoutPeriods, want)
+		}
+	}
+
+	var day models.DayStatus
+	srv.JSON(http.MethodGet, "/api/calendar/day/2030-10-08", nil, &day)
+	if !day.InBlackout {
+		t.Error("2030-10-08 is not in a blackout period after the import")
+	}
+}
+
+func TestImportMilestones(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22}))
+
+	var values map[string]string
+	srv.JSON(http.MethodGet, "/api/settings", nil, &values)
+	if provider, ok := values["milestone_provider"]; !ok || provider != "none" {
+		t.Errorf("settings milestone_provider = %q (listed %v), want none", provider, ok)
+	}
+
+	if status := srv.JSON(http.MethodPost, "/api/milestones/import/2030", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("without a provider: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	srv.JSON(http.MethodPut, "/api/settings/milestone_provider", map[string]string{"value": "github"}, nil)
+
+	// Sandbox releases are on the second Thursday of June and of November,
+	// each avoided from Monday to Friday of its week
+	june := models.AvoidPeriod{StartDate: "2030-06-10", EndDate: "2030-06-14", Reason: "Release 2030.1", Weight: models.DefaultAvoidWeight, Source: models.AvoidMilestone}
+	november := models.AvoidPeriod{StartDate: "2030-11-11", EndDate: "2030-11-15", Reason: "Release 2030.2", Weight: models.DefaultAvoidWeight, Source: models.AvoidMilestone}
+	var result models.MilestoneImport
+	if status := srv.JSON(http.MethodPost, "/api/milestones/import/2030", nil, &result); status != http.StatusOK {
+		t.Fatalf("import: status %d", status)
+	}
+	if want := []models.AvoidPeriod{june, november}; !reflect.DeepEqual(result.Periods, want) {
+		t.Errorf("import periods = %+v, want %+v", result.Periods, want)
+	}
+
+	// A period entered by hand, without a weight, survives the next import,
+	// which doesn't add the release weeks again
+	var config models.YearConfig
+	srv.JSON(http.MethodGet, "/api/config/2030", nil, &config)
+	offsite := models.AvoidPeriod{StartDate: "2030-09-16", EndDate: "2030-09-18", Reason: "Team offsite"}
+	periods := append(config.AvoidPeriods, offsite)
+	if status := srv.JSON(http.MethodPut, "/api/config/2030", map[string]interface{}{"avoid_periods": periods}, nil); status != http.StatusOK {
+		t.Fatalf("add avoid period: status %d", status)
+	}
+	srv.JSON(http.MethodPost, "/api/milestones/import/2030", nil, nil)
+	config = models.YearConfig{}
+	srv.JSON(http.MethodGet, "/api/config/2030", nil, &config)
+	offsite.Weight = models.DefaultAvoidWeight
+	if want := []models.AvoidPeriod{june, offsite, november}; !reflect.DeepEqual(config.AvoidPeriods, want) {
+		t.Errorf("avoid periods after the second import = %+v, want %+v", config.AvoidPeriods, want)
+	}
+}
+
+func TestAvoidPeriods(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 3, OptimizationStrategy: models.StrategyBalanced}))
+
+	tooHeavy := []models.AvoidPeriod{{StartDate: "2030-09-16", EndDate: "2030-09-18", Weight: models.MaxEventWeight + 1}}
+	if status := srv.JSON(http.MethodPut, "/api/config/2030", map[string]interface{}{"avoid_periods": tooHeavy}, nil); status != http.StatusBadRequest {
+		t.Errorf("avoid period weight %d: status %d, want %d", models.MaxEventWeight+1, status, http.StatusBadRequest)
+	}
+
+	optimize := func() []models.VacationBlock {
+		t.Helper()
+		var result struct {
+			Blocks []models.VacationBlock `json:"blocks"`
+		}
+		if status := srv.JSON(http.MethodPost, "/api/calendar/2030/optimize", nil, &result); status != http.StatusOK {
+			t.Fatalf("POST optimize: status %d", status)
+		}
+		if len(result.Blocks) == 0 {
+			t.Fatal("the optimizer suggested no blocks")
+		}
+		return result.Blocks
+	}
+
+	// Avoiding the weeks of the best block moves the vacation elsewhere
+	best := optimize()[0]
+	avoid := models.AvoidPeriod{StartDate: best.StartDate, EndDate: best.EndDate, Reason: "Release", Weight: models.MaxEventWeight}
+	if status := srv.JSON(http.MethodPut, "/api/config/2030", map[string]interface{}{"avoid_periods": []models.AvoidPeriod{avoid}}, nil); status != http.StatusOK {
+		t.Fatalf("add avoid period: status %d", status)
+	}
+	optimize()
+
+	var calendar models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+	if len(calendar.OptimalVacations) == 0 {
+		t.Fatal("the optimizer suggested no days while a period is avoided")
+	}
+	for _, day := range calendar.OptimalVacations {
+		if day.Date >= avoid.StartDate && day.Date <= avoid.EndDate {
+			t.Errorf("optimized %s, in the avoided period %s to %s", day.Date, avoid.StartDate, avoid.EndDate)
+		}
+	}
+}
+
+func TestOptionalHolidays(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22}))
+
+	isHoliday := func(date string) bool {
+		var calendar models.CalendarResponse
+		srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+		for _, day := range calendar.Days {
+			if day.Date == date {
+				return day.IsHoliday
+			}
+		}
+		return false
+	}
+
+	// Carnaval Tuesday
+	if isHoliday("2030-03-05") {
+		t.Fatal("Carnaval is a holiday before it is enabled")
+	}
+
+	var optional []models.OptionalHoliday
+	if status := srv.JSON(http.MethodPut, "/api/holidays/2030/optional/carnaval", map[string]bool{"enabled": true}, &optional); status != http.StatusOK {
+		t.Fatalf("enable Carnaval: status %d", status)
+	}
+	for _, opt := range optional {
+		if opt.Enabled != (opt.Key == "carnaval") {
+			t.Errorf("%s enabled = %v", opt.Key, opt.Enabled)
+		}
+	}
+	if !isHoliday("2030-03-05") {
+		t.Error("enabled Carnaval is not a holiday")
+	}
+
+	if status := srv.JSON(http.MethodPut, "/api/holidays/2030/optional/carnaval", map[string]bool{"enabled": false}, nil); status != http.StatusOK {
+		t.Fatalf("disable Carnaval: status %d", status)
+	}
+	if isHoliday("2030-03-05") {
+		t.Error("disabled Carnaval is still a holiday")
+	}
+
+	if status := srv.JSON(http.MethodPut, "/api/holidays/2030/optional/easter_monday", map[string]bool{"enabled": true}, nil); status != http.StatusNotFound {
+		t.Errorf("unknown optional holiday: status %d, want %d", status, http.StatusNotFound)
+	}
+	if status := srv.JSON(http.MethodPut, "/api/config/2030", map[string][]string{"optional_holidays": {"easter_monday"}}, nil); status != http.StatusBadRequest {
+		t.Errorf("config with unknown optional holiday: status %d, want %d", status, http.StatusBadRequest)
+	}
+}
+
+func TestMonthlyDigestHolidays(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22}))
+
+	// The digest lists the holidays the calendar shows: with Carnaval
+	// enabled and Liberty Day worked
+	srv.JSON(http.MethodPut, "/api/holidays/2030/optional/carnaval", map[string]bool{"enabled": true}, nil)
+	if status := srv.JSON(http.MethodPost, "/api/holidays/2030/worked", map[string]string{"date": "2030-04-25"}, nil); status != http.StatusOK {
+		t.Fatalf("work Liberty Day: status %d", status)
+	}
+
+	digest := func(month string) string {
+		resp := srv.Do(http.MethodGet, "/api/notifications/digest/monthly?format=text&month="+month, nil)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("digest of %s: status %d", month, resp.StatusCode)
+		}
+		return string(body)
+	}
+	if text := digest("2030-03"); !strings.Contains(text, "Tue 5 Mar") {
+		t.Errorf("March digest misses Carnaval:\n%s", text)
+	}
+	if text := digest("2030-04"); strings.Contains(text, "Thu 25 Apr") {
+		t.Errorf("April digest lists the worked Liberty Day:\n%s", text)
+	}
+}
+
+func TestTrips(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22}),
+		testutil.WithVacations(2030, "2030-07-01", "2030-07-02", "2030-09-02"),
+	)
+
+	invalid := []map[string]interface{}{
+		{"name": "", "start_date": "2030-07-01", "end_date": "2030-07-07"},
+		{"name": "Azores", "start_date": "2030-07-07", "end_date": "2030-07-01"},
+		{"name": "Azores", "start_date": "2030-07-01", "end_date": "2031-01-02"},
+		{"name": "Azores", "start_date": "2030-07-01", "end_date": "2030-07-07", "budget": -1},
+	}
+	for _, body := range invalid {
+		if status := srv.JSON(http.MethodPost, "/api/trips/2030", body, nil); status != http.StatusBadRequest {
+			t.Errorf("create trip %v: status %d, want %d", body, status, http.StatusBadRequest)
+		}
+	}
+
+	var trip models.Trip
+	body := map[string]interface{}{"name": "Azores", "destination": "São Miguel", "start_date": "2030-06-29", "end_date": "2030-07-07", "budget": 1200}
+	if status := srv.JSON(http.MethodPost, "/api/trips/2030", body, &trip); status != http.StatusOK {
+		t.Fatalf("create trip: status %d", status)
+	}
+	if len(trip.VacationDates) != 2 {
+		t.Errorf("trip groups %v, want the two July vacation days", trip.VacationDates)
+	}
+
+	var calendar models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+	if len(calendar.Trips) != 1 || calendar.Trips[0].ID != trip.ID {
+		t.Fatalf("calendar trips = %+v, want the created trip", calendar.Trips)
+	}
+	for _, block := range calendar.VacationBlocks {
+		wantTrip := block.StartDate < "2030-08-01"
+		if (block.TripID == trip.ID) != wantTrip {
+			t.Errorf("block %s-%s trip = %d", block.StartDate, block.EndDate, block.TripID)
+		}
+	}
+
+	path := fmt.Sprintf("/api/trips/2030/%d", trip.ID)
+	body["end_date"] = "2030-06-30"
+	if status := srv.JSON(http.MethodPut, path, body, &trip); status != http.StatusOK {
+		t.Fatalf("update trip: status %d", status)
+	}
+	if len(trip.VacationDates) != 0 {
+		t.Errorf("shortened trip groups %v, want none", trip.VacationDates)
+	}
+
+	if status := srv.JSON(http.MethodDelete, path, nil, nil); status != http.StatusOK {
+		t.Errorf("delete trip: status %d", status)
+	}
+	if status := srv.JSON(http.MethodPut, path, body, nil); status != http.StatusNotFound {
+		t.Errorf("update deleted trip: status %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestPersonalEvents(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 3, OptimizationStrategy: models.StrategyBalanced}))
+
+	invalid := []map[string]interface{}{
+		{"name": "", "date": "2030-10-16"},
+		{"name": "Marathon", "date": "2031-10-16"},
+		{"name": "Marathon", "date": "2030-10-16", "weight": 6},
+	}
+	for _, body := range invalid {
+		if status := srv.JSON(http.MethodPost, "/api/personal-events/2030", body, nil); status != http.StatusBadRequest {
+			t.Errorf("create event %v: status %d, want %d", body, status, http.StatusBadRequest)
+		}
+	}
+
+	// A race on a Wednesday far from any holiday
+	var event models.PersonalEvent
+	body := map[string]interface{}{"name": "Marathon", "date": "2030-10-16", "weight": 4}
+	if status := srv.JSON(http.MethodPost, "/api/personal-events/2030", body, &event); status != http.StatusOK {
+		t.Fatalf("create event: status %d", status)
+	}
+
+	var calendar models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+	if len(calendar.PersonalEvents) != 1 || calendar.PersonalEvents[0].ID != event.ID {
+		t.Fatalf("calendar events = %+v, want the created event", calendar.PersonalEvents)
+	}
+	for _, day := range calendar.Days {
+		if got := len(day.Events) > 0; got != (day.Date == "2030-10-16") {
+			t.Errorf("%s has events %v", day.Date, day.Events)
+		}
+	}
+
+	// The optimizer draws vacation to the event
+	var result struct {
+		Blocks []models.VacationBlock `json:"blocks"`
+	}
+	if status := srv.JSON(http.MethodPost, "/api/calendar/2030/optimize", nil, &result); status != http.StatusOK {
+		t.Fatalf("POST optimize: status %d", status)
+	}
+	found := false
+	for _, block := range result.Blocks {
+		found = found || (block.StartDate <= "2030-10-16" && block.EndDate >= "2030-10-16")
+	}
+	if !found {
+		t.Errorf("no optimized block holds the event on 2030-10-16")
+	}
+
+	path := fmt.Sprintf("/api/personal-events/2030/%d", event.ID)
+	body["weight"] = -2
+	if status := srv.JSON(http.MethodPut, path, body, &event); status != http.StatusOK || event.Weight != -2 {
+		t.Fatalf("update event: status %d, weight %d", status, event.Weight)
+	}
+	if status := srv.JSON(http.MethodDelete, path, nil, nil); status != http.StatusOK {
+		t.Errorf("delete event: status %d", status)
+	}
+	if status := srv.JSON(http.MethodPut, path, body, nil); status != http.StatusNotFound {
+		t.Errorf("update deleted event: status %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestTripBudget(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22}))
+
+	var trip models.Trip
+	srv.JSON(http.MethodPost, "/api/trips/2030", map[string]interface{}{"name": "Madeira", "start_date": "2030-05-01", "end_date": "2030-05-05", "budget": 1000}, &trip)
+	expensesPath := fmt.Sprintf("/api/trips/2030/%d/expenses", trip.ID)
+
+	var flights models.TripExpense
+	if status := srv.JSON(http.MethodPost, expensesPath, map[string]interface{}{"description": "Flights", "category": "Flights", "amount": 300}, &flights); status != http.StatusOK {
+		t.Fatalf("add expense: status %d", status)
+	}
+	srv.JSON(http.MethodPost, expensesPath, map[string]interface{}{"description": "Hotel", "category": "lodging", "amount": 500}, nil)
+	if status := srv.JSON(http.MethodPost, expensesPath, map[string]interface{}{"description": "Refund", "amount": -50}, nil); status != http.StatusBadRequest {
+		t.Errorf("negative expense: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	flightsPath := fmt.Sprintf("%s/%d", expensesPath, flights.ID)
+	if status := srv.JSON(http.MethodPut, flightsPath, map[string]interface{}{"description": "Flights", "category": "flights", "amount": 350}, nil); status != http.StatusOK {
+		t.Fatalf("update expense: status %d", status)
+	}
+
+	var summary models.TripBudgetSummary
+	srv.JSON(http.MethodGet, "/api/trips/2030/budget", nil, &summary)
+	if summary.TotalBudget != 1000 || summary.TotalSpent != 850 || summary.TotalRemaining != 150 {
+		t.Errorf("budget %v, spent %v, remaining %v, want 1000, 850, 150", summary.TotalBudget, summary.TotalSpent, summary.TotalRemaining)
+	}
+	if summary.ByCategory["flights"] != 350 || summary.ByCategory["lodging"] != 500 {
+		t.Errorf("spending by category = %v", summary.ByCategory)
+	}
+
+	if status := srv.JSON(http.MethodDelete, flightsPath, nil, nil); status != http.StatusOK {
+		t.Errorf("delete expense: status %d", status)
+	}
+	var trips []models.Trip
+	srv.JSON(http.MethodGet, "/api/trips/2030", nil, &trips)
+	if len(trips) != 1 || trips[0].Spent != 500 {
+		t.Errorf("trips = %+v, want one with 500 spent", trips)
+	}
+
+	if status := srv.JSON(http.MethodGet, "/api/trips/2030/999/expenses", nil, nil); status != http.StatusNotFound {
+		t.Errorf("expenses of a missing trip: status %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestFlightPrices(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 10, OptimizationStrategy: models.StrategyBridgeHolidays}),
+		testutil.WithVacations(2030, "2030-03-04"),
+		testutil.WithSetting("home_airport", "lis"),
+	)
+
+	if status := srv.JSON(http.MethodGet, "/api/calendar/2030/flight-prices?destination=FNC", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("without a provider: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	srv.JSON(http.MethodPut, "/api/settings/flight_price_provider", map[string]string{"value": "kiwi"}, nil)
+	srv.JSON(http.MethodPost, "/api/calendar/2030/optimize", nil, nil)
+
+	if status := srv.JSON(http.MethodGet, "/api/calendar/2030/flight-prices", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("without a destination: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	var prices models.FlightPriceResponse
+	if status := srv.JSON(http.MethodGet, "/api/calendar/2030/flight-prices?destination=fnc", nil, &prices); status != http.StatusOK {
+		t.Fatalf("flight prices: status %d", status)
+	}
+	if prices.Origin != "LIS" || prices.Destination != "FNC" {
+		t.Errorf("route %s-%s, want LIS-FNC", prices.Origin, prices.Destination)
+	}
+	if len(prices.Blocks) == 0 {
+		t.Fatal("no suggested blocks priced")
+	}
+	for _, block := range prices.Blocks {
+		if block.Source != "optimized" {
+			t.Errorf("block %s has source %q, want only suggested blocks", block.StartDate, block.Source)
+		}
+		if block.FlightPrice == nil || block.FlightPrice.Price <= 0 {
+			t.Errorf("block %s has no flight price", block.StartDate)
+			continue
+		}
+		if block.FlightPrice.DepartDate != block.StartDate || block.FlightPrice.ReturnDate != block.EndDate {
+			t.Errorf("flight %s to %s does not span block %s to %s", block.FlightPrice.DepartDate, block.FlightPrice.ReturnDate, block.StartDate, block.EndDate)
+		}
+	}
+}
+
+func TestNextBreak(t *testing.T) {
+	// A Wednesday in mid-March next year, never a holiday
+	start := time.Date(time.Now().Year()+1, 3, 8, 0, 0, 0, 0, time.UTC)
+	for start.Weekday() != time.Wednesday {
+		start = start.AddDate(0, 0, 1)
+	}
+	vacation := []string{start.Format("2006-01-02"), start.AddDate(0, 0, 1).Format("2006-01-02")}
+	srv := testutil.NewServer(t, testutil.WithVacations(start.Year(), vacation...))
+
+	var next models.NextBreak
+	if status := srv.JSON(http.MethodGet, "/api/calendar/next-break", nil, &next); status != http.StatusOK {
+		t.Fatalf("next break: status %d", status)
+	}
+
+	// A weekend is never more than a week away
+	if next.NextDayOff == nil || next.NextDayOff.Kind == "" || next.NextDayOff.DaysUntil < 0 || next.NextDayOff.DaysUntil > 6 {
+		t.Errorf("next day off = %+v, want one within a week", next.NextDayOff)
+	}
+	if next.NextVacation == nil || next.NextVacation.StartDate != vacation[0] {
+		t.Fatalf("next vacation = %+v, want the block starting %s", next.NextVacation, vacation[0])
+	}
+	today, _ := time.Parse("2006-01-02", next.Today)
+	if want := int(start.Sub(today).Hours() / 24); next.DaysUntilVacation != want {
+		t.Errorf("days until vacation = %d, want %d", next.DaysUntilVacation, want)
+	}
+}
+
+func TestLongWeekends(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22}))
+
+	var weekends []models.LongWeekend
+	if status := srv.JSON(http.MethodGet, "/api/calendar/2030/long-weekends", nil, &weekends); status != http.StatusOK {
+		t.Fatalf("long weekends: status %d", status)
+	}
+
+	find := func(start, end string) *models.LongWeekend {
+		for i := range weekends {
+			if weekends[i].StartDate == start && weekends[i].EndDate == end {
+				return &weekends[i]
+			}
+		}
+		return nil
+	}
+
+	// Good Friday, 19 April 2030, is a long weekend as it is
+	if easter := find("2030-04-19", "2030-04-21"); easter == nil || easter.VacationDays != 0 || easter.TotalDays != 3 {
+		t.Errorf("Easter weekend = %+v, want 3 days for no vacation day", easter)
+	}
+	// Liberty Day, Thursday 25 April, takes the Friday
+	if liberty := find("2030-04-25", "2030-04-28"); liberty == nil || liberty.VacationDays != 1 || liberty.VacationDate != "2030-04-26" {
+		t.Errorf("Liberty Day bridge = %+v, want 4 days taking 2030-04-26", liberty)
+	}
+
+	for i, weekend := range weekends {
+		if weekend.TotalDays < 3 || len(weekend.Holidays) == 0 || weekend.VacationDays > 1 {
+			t.Errorf("%s to %s: %d days, %d vacation days, holidays %v", weekend.StartDate, weekend.EndDate, weekend.TotalDays, weekend.VacationDays, weekend.Holidays)
+		}
+		if i > 0 && weekend.VacationDays < weekends[i-1].VacationDays {
+			t.Errorf("%s to %s costs less than the stretch before it", weekend.StartDate, weekend.EndDate)
+		}
+	}
+}
+
+func TestBridgeCandidates(t *testing.T) {
+	// Corpus Christi, Thursday 20 June 2030, leaves Friday the 21st alone;
+	// the Friday after Liberty Day is already taken
+	srv := testutil.NewServer(t, testutil.WithVacations(2030, "2030-04-26"))
+
+	var calendar models.CalendarResponse
+	if status := srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar); status != http.StatusOK {
+		t.Fatalf("calendar: status %d", status)
+	}
+
+	candidates := make(map[string]bool)
+	for _, day := range calendar.Days {
+		if day.IsBridgeCandidate {
+			candidates[day.Date] = true
+			if day.IsWeekend || day.IsHoliday || day.IsVacation {
+				t.Errorf("%s is a bridge candidate but not a free work day", day.Date)
+			}
+		}
+	}
+	if !candidates["2030-06-21"] {
+		t.Errorf("bridge candidates %v, want 2030-06-21", candidates)
+	}
+	if candidates["2030-04-26"] || candidates["2030-06-14"] {
+		t.Errorf("bridge candidates %v, want neither a booked day nor a plain Friday", candidates)
+	}
+}
+
+func TestHistoricalStats(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2020, VacationDays: 22}),
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22}),
+		testutil.WithVacations(2020, "2020-08-03", "2020-08-04", "2020-08-05"),
+		testutil.WithVacations(2030, "2030-08-05", "2030-12-23"),
+	)
+
+	var stats models.HistoricalStats
+	if status := srv.JSON(http.MethodGet, "/api/stats", nil, &stats); status != http.StatusOK {
+		t.Fatalf("stats: status %d", status)
+	}
+
+	if len(stats.Years) != 2 || stats.Years[0].Year != 2020 || stats.Years[1].Year != 2030 {
+		t.Fatalf("years = %+v, want 2020 and 2030", stats.Years)
+	}
+	if stats.TotalDaysTaken != 5 {
+		t.Errorf("total days taken = %d, want 5", stats.TotalDaysTaken)
+	}
+
+	// The 2020 leftovers expired with the 2021 carryover, 2030 is still ahead
+	past, future := stats.Years[0], stats.Years[1]
+	if past.UnusedDays != 19 || !past.Lost {
+		t.Errorf("2020 unused %d lost %v, want 19 lost", past.UnusedDays, past.Lost)
+	}
+	if future.Lost {
+		t.Error("2030 unused days counted as lost")
+	}
+	if stats.UnusedDaysLost != 19 {
+		t.Errorf("unused days lost = %d, want 19", stats.UnusedDaysLost)
+	}
+
+	if len(stats.MostCommonMonths) != 2 || stats.MostCommonMonths[0] != (models.MonthCount{Month: 8, VacationDays: 4}) {
+		t.Errorf("most common months = %+v, want August first with 4 days", stats.MostCommonMonths)
+	}
+}
+
+func TestExportSheet(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22}),
+		testutil.WithVacations(2030, "2030-08-05", "2030-08-06", "2030-12-23"),
+	)
+
+	if status := srv.JSON(http.MethodPost, "/api/sheets/2030/export", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("without a spreadsheet: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	srv.JSON(http.MethodPut, "/api/settings/google_sheets_spreadsheet_id", map[string]string{"value": "sheet-id"}, nil)
+
+	var result struct {
+		Sheet string `json:"sheet"`
+		Rows  int    `json:"rows"`
+	}
+	if status := srv.JSON(http.MethodPost, "/api/sheets/2030/export", nil, &result); status != http.StatusOK {
+		t.Fatalf("export blocks: status %d", status)
+	}
+	if result.Sheet != "2030" || result.Rows != 3 {
+		t.Errorf("exported %d rows to sheet %q, want a header and 2 blocks to 2030", result.Rows, result.Sheet)
+	}
+
+	srv.JSON(http.MethodPost, "/api/sheets/2030/export?layout=day", nil, &result)
+	if result.Rows != 366 {
+		t.Errorf("exported %d rows by day, want a header and 365 days", result.Rows)
+	}
+
+	if status := srv.JSON(http.MethodPost, "/api/sheets/2030/export?layout=week", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("unknown layout: status %d, want %d", status, http.StatusBadRequest)
+	}
+}
+
+func TestImportLeave(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22}),
+		testutil.WithVacations(2030, "2030-08-06"),
+		testutil.WithSetting("hr_employee_id", "42"),
+	)
+
+	if status := srv.JSON(http.MethodPost, "/api/hr/import/2030", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("without a provider: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	srv.JSON(http.MethodPut, "/api/settings/hr_provider", map[string]string{"value": "bamboohr"}, nil)
+
+	// Sandbox leave is the week of the first Monday of August
+	var result models.LeaveImport
+	if status := srv.JSON(http.MethodPost, "/api/hr/import/2030", nil, &result); status != http.StatusOK {
+		t.Fatalf("import: status %d", status)
+	}
+	if len(result.Imported) != 4 || result.Imported[0] != "2030-08-05" || result.Existing != 1 {
+		t.Errorf("imported %v with %d existing, want 4 new days from 2030-08-05 and 1 existing", result.Imported, result.Existing)
+	}
+
+	var vacations []models.VacationDay
+	srv.JSON(http.MethodGet, "/api/vacations/2030", nil, &vacations)
+	if len(vacations) != 5 {
+		t.Errorf("got %d vacation days after the import, want 5", len(vacations))
+	}
+
+	srv.JSON(http.MethodPost, "/api/hr/import/2030", nil, &result)
+	if len(result.Imported) != 0 || result.Existing != 5 {
+		t.Errorf("second import added %v with %d existing, want nothing new and 5 existing", result.Imported, result.Existing)
+	}
+}
+
+func TestAccessControl(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithSetting("openai_api_key", "sk-test"))
+
+	var access models.AccessInfo
+	srv.JSON(http.MethodGet, "/api/access", nil, &access)
+	if access.AccessControl {
+		t.Fatal("access control is on before any token exists")
+	}
+
+	if status := srv.JSON(http.MethodPost, "/api/access/tokens", map[string]string{"name": "Kid", "role": models.RoleViewer}, nil); status != http.StatusBadRequest {
+		t.Errorf("first token as viewer: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	tokens := make(map[string]string)
+	for _, role := range []string{models.RoleAdmin, models.RoleMember, models.RoleViewer} {
+		var token models.AccessToken
+		if status := srv.JSON(http.MethodPost, "/api/access/tokens", map[string]string{"name": role, "role": role}, &token); status != http.StatusOK || token.Token == "" {
+			t.Fatalf("create %s token: status %d, token %q", role, status, token.Token)
+		}
+		tokens[role] = token.Token
+		// The admin token turns access control on
+		srv.Token = tokens[models.RoleAdmin]
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		method     string
+		path       string
+		body       interface{}
+		wantStatus int
+	}{
+		{"no token", "", http.MethodGet, "/api/calendar/2030", nil, http.StatusUnauthorized},
+		{"unknown token", "nope", http.MethodGet, "/api/calendar/2030", nil, http.StatusUnauthorized},
+		{"health stays open", "", http.MethodGet, "/api/health", nil, http.StatusOK},
+		{"viewer reads", tokens[models.RoleViewer], http.MethodGet, "/api/calendar/2030", nil, http.StatusOK},
+		{"viewer plans", tokens[models.RoleViewer], http.MethodPost, "/api/vacations/2030", map[string]string{"date": "2030-03-05"}, http.StatusForbidden},
+		{"member plans", tokens[models.RoleMember], http.MethodPost, "/api/vacations/2030", map[string]string{"date": "2030-03-05"}, http.StatusOK},
+		{"member credits comp day", tokens[models.RoleMember], http.MethodPost, "/api/comp-days/2030/credit", map[string]string{"date": "2030-03-09"}, http.StatusForbidden},
+		{"member changes settings", tokens[models.RoleMember], http.MethodPut, "/api/settings/work_city", map[string]string{"value": "Porto"}, http.StatusForbidden},
+		{"admin changes settings", tokens[models.RoleAdmin], http.MethodPut, "/api/settings/work_city", map[string]string{"value": "Porto"}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv.Token = tt.token
+			if status := srv.JSON(tt.method, tt.path, tt.body, nil); status != tt.wantStatus {
+				t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, status, tt.wantStatus)
+			}
+		})
+	}
+
+	srv.Token = tokens[models.RoleMember]
+	var values map[string]string
+	srv.JSON(http.MethodGet, "/api/settings", nil, &values)
+	if values["openai_api_key"] != "" || values["work_city"] != "Porto" {
+		t.Errorf("member sees openai_api_key %q and work_city %q, want the key hidden", values["openai_api_key"], values["work_city"])
+	}
+
+	srv.Token = tokens[models.RoleAdmin]
+	var list []models.AccessToken
+	srv.JSON(http.MethodGet, "/api/access/tokens", nil, &list)
+	if len(list) != 3 || list[0].Token != "" {
+		t.Fatalf("listed %d tokens, want 3 without their secrets", len(list))
+	}
+	if status := srv.JSON(http.MethodDelete, fmt.Sprintf("/api/access/tokens/%d", list[0].ID), nil, nil); status != http.StatusConflict {
+		t.Errorf("delete the last admin token: status %d, want %d", status, http.StatusConflict)
+	}
+}
+
+func TestAuthLockout(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithSetting("auth_max_failures", "3"), testutil.WithSetting("auth_throttle_seconds", "0"))
+
+	var admin models.AccessToken
+	if status := srv.JSON(http.MethodPost, "/api/access/tokens", map[string]string{"name": "Admin", "role": models.RoleAdmin}, &admin); status != http.StatusOK {
+		t.Fatalf("create admin token: status %d", status)
+	}
+
+	srv.Token = "nope"
+	for i := 0; i < 3; i++ {
+		if status := srv.JSON(http.MethodGet, "/api/calendar/2030", nil, nil); status != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: status %d, want %d", i+1, status, http.StatusUnauthorized)
+		}
+	}
+
+	// Locked out, even with the right token
+	srv.Token = admin.Token
+	resp := srv.Do(http.MethodGet, "/api/calendar/2030", nil)
+	var problem models.Problem
+	json.NewDecoder(resp.Body).Decode(&problem)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || problem.Code != models.CodeLockedOut || resp.Header.Get("Retry-After") == "" {
+		t.Fatalf("after 3 failures: status %d, code %q, Retry-After %q, want %d locked_out", resp.StatusCode, problem.Code, resp.Header.Get("Retry-After"), http.StatusTooManyRequests)
+	}
+
+	// Every attempt and the lockout are in the audit log
+	events, err := srv.Store.Audit.List(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, e := range events {
+		kinds = append(kinds, e.Event)
+	}
+	want := []string{models.AuthEventLockout, models.AuthEventFailure, models.AuthEventFailure, models.AuthEventFailure, models.AuthEventTokenCreated}
+	if strings.Join(kinds, ",") != strings.Join(want, ",") {
+		t.Errorf("audit log %v, want %v", kinds, want)
+	}
+}
+
+func TestSingleSignOn(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	// A provider signing in a member of the given groups
+	var groups []string
+	var nonce, challenge string
+	provider := httptest.NewServer(nil)
+	defer provider.Close()
+	provider.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                 provider.URL,
+				"authorization_endpoint": provider.URL + "/authorize",
+				"token_endpoint":         provider.URL + "/token",
+			})
+		case "/token":
+			sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+			if r.FormValue("code") != "the-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+				http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+			claims, _ := json.Marshal(map[string]interface{}{
+				"iss": provider.URL, "aud": "planner", "sub": "42", "email": "ana@example.com",
+				"exp": time.Now().Add(time.Hour).Unix(), "nonce": nonce, "groups": groups,
+			})
+			json.NewEncoder(w).Encode(map[string]string{"id_token": "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".sig"})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	var admin models.AccessToken
+	srv.JSON(http.MethodPost, "/api/access/tokens", map[string]string{"name": "Admin", "role": models.RoleAdmin}, &admin)
+	srv.Token = admin.Token
+	for key, value := range map[string]string{
+		"oidc_issuer":       provider.URL,
+		"oidc_client_id":    "planner",
+		"oidc_redirect_url": srv.URL + "/api/auth/oidc/callback",
+		"oidc_role_mapping": "planner-admins=admin, staff=member",
+	} {
+		if status := srv.JSON(http.MethodPut, "/api/settings/"+key, map[string]string{"value": value}, nil); status != http.StatusOK {
+			t.Fatalf("set %s: status %d", key, status)
+		}
+	}
+
+	jar, _ := cookiejar.New(nil)
+	browser := &http.Client{Jar: jar, CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	signIn := func() *http.Response {
+		t.Helper()
+		resp, err := browser.Get(srv.URL + "/api/auth/oidc/login")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		location, _ := url.Parse(resp.Header.Get("Location"))
+		query := location.Query()
+		if resp.StatusCode != http.StatusFound || !strings.HasPrefix(location.String(), provider.URL+"/authorize") || query.Get("code_challenge_method") != "S256" {
+			t.Fatalf("login: status %d, redirect to %q", resp.StatusCode, location)
+		}
+		nonce, challenge = query.Get("nonce"), query.Get("code_challenge")
+
+		resp, err = browser.Get(srv.URL + "/api/auth/oidc/callback?code=the-code&state=" + url.QueryEscape(query.Get("state")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	// A staff member gets a member session token
+	groups = []string{"everyone", "staff"}
+	resp := signIn()
+	token, found := strings.CutPrefix(resp.Header.Get("Location"), "/#access_token=")
+	if resp.StatusCode != http.StatusFound || !found {
+		t.Fatalf("callback: status %d, redirect to %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	srv.Token = token
+	var access models.AccessInfo
+	srv.JSON(http.MethodGet, "/api/access", nil, &access)
+	if access.Role != models.RoleMember || access.Name != "sso:ana@example.com" {
+		t.Errorf("signed in as %q with role %q, want sso:ana@example.com as member", access.Name, access.Role)
+	}
+
+	// Users in no mapped group are refused, and a state is only used once
+	groups = []string{"guests"}
+	if resp := signIn(); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("unmapped groups: status %d, want %d", resp.StatusCode, http.StatusForbidden)
+	}
+	resp, err := browser.Get(srv.URL + "/api/auth/oidc/callback?code=the-code&state=reused")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown state: status %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+
+	var list []models.AccessToken
+	srv.Token = admin.Token
+	srv.JSON(http.MethodGet, "/api/access/tokens", nil, &list)
+	if len(list) != 2 || list[1].ExpiresAt == "" {
+		t.Errorf("tokens %+v, want the admin token and an expiring session", list)
+	}
+}
+
+func TestSchoolHolidays(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	var breaks []holidays.SchoolBreak
+	if status := srv.JSON(http.MethodGet, "/api/school-holidays/2030?district=Açores", nil, &breaks); status != http.StatusOK {
+		t.Fatalf("GET: status %d", status)
+	}
+	found := false
+	for _, b := range breaks {
+		found = found || (b.Name == "Dia dos Açores" && b.StartDate == "2030-06-10")
+	}
+	if !found {
+		t.Errorf("Açores breaks = %+v, want the Dia dos Açores on 2030-06-10", breaks)
+	}
+	var stored int
+	srv.DB.QueryRow(`SELECT COUNT(*) FROM school_holidays`).Scan(&stored)
+	if stored != 0 {
+		t.Errorf("GET stored %d school breaks, want none", stored)
+	}
+
+	if status := srv.JSON(http.MethodGet, "/api/school-holidays/2030?district=Atlantis", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("unknown district: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	custom := []map[string]string{{"name": "Férias de Verão", "start_date": "2030-06-24", "end_date": "2030-09-13"}}
+	srv.ClientID = "tab-1"
+	srv.JSON(http.MethodPost, "/api/calendar/2030/lock", nil, nil)
+	srv.ClientID = "tab-2"
+	if status := srv.JSON(http.MethodPut, "/api/school-holidays/2030?district=Porto", custom, nil); status != http.StatusConflict {
+		t.Errorf("PUT while another client edits: status %d, want %d", status, http.StatusConflict)
+	}
+	if status := srv.JSON(http.MethodDelete, "/api/school-holidays/2030?district=Porto", nil, nil); status != http.StatusConflict {
+		t.Errorf("DELETE while another client edits: status %d, want %d", status, http.StatusConflict)
+	}
+
+	srv.ClientID = "tab-1"
+	if status := srv.JSON(http.MethodPut, "/api/school-holidays/2030?district=Porto", custom, nil); status != http.StatusOK {
+		t.Fatalf("PUT: status %d", status)
+	}
+	breaks = nil
+	srv.JSON(http.MethodGet, "/api/school-holidays/2030?district=Porto", nil, &breaks)
+	if len(breaks) != 1 || breaks[0].Source != "custom" {
+		t.Errorf("Porto breaks = %+v, want the custom summer", breaks)
+	}
+
+	srv.JSON(http.MethodDelete, "/api/school-holidays/2030?district=Porto", nil, nil)
+	breaks = nil
+	srv.JSON(http.MethodGet, "/api/school-holidays/2030?district=Porto", nil, &breaks)
+	if len(breaks) < 2 || breaks[0].Source != "calculated" {
+		t.Errorf("Porto breaks after reset = %+v, want the calculated calendar", breaks)
+	}
+}
+
+func TestFamilyMembers(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 5, OptimizationStrategy: models.StrategyLongestBlocks}))
+
+	var member models.FamilyMember
+	if status := srv.JSON(http.MethodPost, "/api/family", map[string]string{"name": "Ana"}, &member); status != http.StatusOK || !member.PlanAround {
+		t.Fatalf("create member: status %d, plan_around %v", status, member.PlanAround)
+	}
+	if status := srv.JSON(http.MethodPost, "/api/family", map[string]string{"name": "Rui", "school_district": "Atlantis"}, nil); status != http.StatusBadRequest {
+		t.Errorf("unknown district: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	closures := fmt.Sprintf("/api/family/%d/closures", member.ID)
+	if status := srv.JSON(http.MethodPost, closures, map[string]string{"name": "Daycare", "start_date": "2030-08-19", "end_date": "2030-08-12"}, nil); status != http.StatusBadRequest {
+		t.Errorf("closure ending before it starts: status %d, want %d", status, http.StatusBadRequest)
+	}
+	if status := srv.JSON(http.MethodPost, closures, map[string]string{"name": "Daycare", "start_date": "2030-08-12", "end_date": "2030-08-23"}, nil); status != http.StatusOK {
+		t.Fatalf("add closure: status %d", status)
+	}
+
+	var calendar models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+	if len(calendar.Family) != 1 || len(calendar.Family[0].Closures) != 1 {
+		t.Fatalf("calendar family = %+v, want Ana with one closure", calendar.Family)
+	}
+	for _, day := range calendar.Days {
+		off := len(day.FamilyOff) == 1 && day.FamilyOff[0] == "Ana"
+		if want := day.Date >= "2030-08-12" && day.Date <= "2030-08-23"; off != want {
+			t.Errorf("%s: family off %v, want Ana off %v", day.Date, day.FamilyOff, want)
+		}
+	}
+
+	// With a week of vacation, the optimizer picks the closure
+	var result struct {
+		Blocks []models.VacationBlock `json:"blocks"`
+	}
+	if status := srv.JSON(http.MethodPost, "/api/calendar/2030/optimize", nil, &result); status != http.StatusOK || len(result.Blocks) == 0 {
+		t.Fatalf("optimize: status %d, %d blocks", status, len(result.Blocks))
+	}
+	if block := result.Blocks[0]; block.StartDate < "2030-08-10" || block.EndDate > "2030-08-25" {
+		t.Errorf("first block %s..%s, want it inside the closure", block.StartDate, block.EndDate)
+	}
+}
+
+func TestBookingPolicies(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 22, OptimizationStrategy: models.StrategyLongestBlocks}))
+
+	invalid := []map[string]interface{}{{"name": "Bridges", "kind": "bridge", "max_gap": 5}}
+	if status := srv.JSON(http.MethodPut, "/api/policies", invalid, nil); status != http.StatusBadRequest {
+		t.Errorf("max_gap too wide: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	rules := []map[string]interface{}{
+		{"name": "Thursday bridges", "kind": "bridge", "holiday_weekdays": []string{"Thursday"}},
+		{"name": "Two weeks at most", "kind": "max_consecutive_weeks", "max_weeks": 2},
+	}
+	var saved []models.BookingRule
+	if status := srv.JSON(http.MethodPut, "/api/policies", rules, &saved); status != http.StatusOK || len(saved) != 2 {
+		t.Fatalf("save rules: status %d, %d rules", status, len(saved))
+	}
+	if saved[0].MaxGap != 1 || saved[0].HolidayWeekdays[0] != "thursday" || !saved[0].Enabled {
+		t.Errorf("bridge rule = %+v, want max_gap 1 on thursdays, enabled", saved[0])
+	}
+
+	// Liberty Day, 25 April 2030, is a Thursday
+	var proposals []models.BookingProposal
+	if status := srv.JSON(http.MethodPost, "/api/policies/2030/evaluate", nil, &proposals); status != http.StatusOK {
+		t.Fatalf("evaluate: status %d", status)
+	}
+	find := func(date string) (models.BookingProposal, bool) {
+		for _, p := range proposals {
+			if p.Date == date {
+				return p, true
+			}
+		}
+		return models.BookingProposal{}, false
+	}
+	liberty, ok := find("2030-04-26")
+	if !ok || liberty.Status != models.ProposalPending || liberty.RuleName != "Thursday bridges" {
+		t.Fatalf("proposals = %+v, want 2030-04-26 pending by Thursday bridges", proposals)
+	}
+	for _, p := range proposals {
+		if parsed, _ := time.Parse("2006-01-02", p.Date); parsed.Weekday() != time.Friday {
+			t.Errorf("proposal on %s, a %s, want Fridays after Thursday holidays", p.Date, parsed.Weekday())
+		}
+	}
+
+	dismiss := fmt.Sprintf("/api/policies/2030/proposals/%d/dismiss", liberty.ID)
+	if status := srv.JSON(http.MethodPost, dismiss, nil, nil); status != http.StatusOK {
+		t.Fatalf("dismiss: status %d", status)
+	}
+	if status := srv.JSON(http.MethodPost, dismiss, nil, nil); status != http.StatusConflict {
+		t.Errorf("dismiss twice: status %d, want %d", status, http.StatusConflict)
+	}
+
+	// Dismissed days stay dismissed, and auto-applied rules book the rest
+	rules[0]["auto_apply"] = true
+	srv.JSON(http.MethodPut, "/api/policies", rules, nil)
+	srv.JSON(http.MethodPost, "/api/policies/2030/evaluate", nil, &proposals)
+	if p, _ := find("2030-04-26"); p.Status != models.ProposalDismissed {
+		t.Errorf("2030-04-26 status %q, want dismissed", p.Status)
+	}
+
+	var vacations []models.VacationDay
+	srv.JSON(http.MethodGet, "/api/vacations/2030", nil, &vacations)
+	booked := make(map[string]string)
+	for _, v := range vacations {
+		booked[v.Date] = v.Note
+	}
+	for _, p := range proposals {
+		if p.Date == "2030-04-26" {
+			if _, ok := booked[p.Date]; ok {
+				t.Errorf("dismissed %s was booked", p.Date)
+			}
+			continue
+		}
+		if p.Status != models.ProposalApplied || booked[p.Date] != "Policy: Thursday bridges" {
+			t.Errorf("%s: status %q, note %q, want applied and booked", p.Date, p.Status, booked[p.Date])
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithVacations(2030, "2030-08-12", "2030-08-13", "2030-08-14"))
+
+	if status := srv.JSON(http.MethodPost, "/api/comments/2030", map[string]string{"date": "2030-08-20", "body": "Hi"}, nil); status != http.StatusBadRequest {
+		t.Errorf("comment on a work day: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	var calendar models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+	if len(calendar.VacationBlocks) != 1 {
+		t.Fatalf("%d vacation blocks, want 1", len(calendar.VacationBlocks))
+	}
+	blockStart := calendar.VacationBlocks[0].StartDate
+
+	var thread models.Comment
+	if status := srv.JSON(http.MethodPost, "/api/comments/2030", map[string]string{"date": blockStart, "target": "block", "author": "Ana", "body": "Beach week?"}, &thread); status != http.StatusOK {
+		t.Fatalf("comment on block: status %d", status)
+	}
+	var reply models.Comment
+	srv.JSON(http.MethodPost, "/api/comments/2030", map[string]interface{}{"parent_id": thread.ID, "author": "Rui", "body": "Mountains"}, &reply)
+	if reply.ParentID == nil || *reply.ParentID != thread.ID || reply.Date != blockStart || reply.Target != models.CommentBlock {
+		t.Errorf("reply = %+v, want it in the block's thread", reply)
+	}
+	if status := srv.JSON(http.MethodPost, "/api/comments/2030", map[string]string{"date": "2030-08-13", "body": "Dentist in the morning"}, nil); status != http.StatusOK {
+		t.Errorf("comment on day: status %d", status)
+	}
+
+	var updated models.Comment
+	srv.JSON(http.MethodPut, fmt.Sprintf("/api/comments/2030/%d", reply.ID), map[string]string{"body": "Mountains!"}, &updated)
+	if updated.Body != "Mountains!" || updated.Author != "Rui" {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	var comments []models.Comment
+	srv.JSON(http.MethodGet, "/api/comments/2030?date="+blockStart, nil, &comments)
+	if len(comments) != 2 {
+		t.Fatalf("%d comments on %s, want 2", len(comments), blockStart)
+	}
+
+	// Deleting a comment removes its replies
+	var deleted struct {
+		Deleted int `json:"deleted"`
+	}
+	if status := srv.JSON(http.MethodDelete, fmt.Sprintf("/api/comments/2030/%d", thread.ID), nil, &deleted); status != http.StatusOK || deleted.Deleted != 2 {
+		t.Errorf("delete thread: status %d, %d deleted, want 2", status, deleted.Deleted)
+	}
+	srv.JSON(http.MethodGet, "/api/comments/2030", nil, &comments)
+	if len(comments) != 1 || comments[0].Date != "2030-08-13" {
+		t.Errorf("comments after delete = %+v, want the day comment", comments)
+	}
+}
+
+func TestBlockICS(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithVacations(2030, "2030-03-04", "2030-03-05", "2030-08-12", "2030-08-13"))
+
+	var calendar models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+	if len(calendar.VacationBlocks) != 2 {
+		t.Fatalf("%d vacation blocks, want 2", len(calendar.VacationBlocks))
+	}
+	second := calendar.VacationBlocks[1]
+
+	for _, id := range []string{"2", second.StartDate} {
+		resp := srv.Do(http.MethodGet, "/api/calendar/2030/blocks/"+id+".ics", nil)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/calendar") {
+			t.Fatalf("block %s: status %d, content type %q", id, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		if n := strings.Count(string(body), "BEGIN:VEVENT"); n != 1 {
+			t.Errorf("block %s: %d events, want 1", id, n)
+		}
+		if start := "DTSTART;VALUE=DATE:" + strings.ReplaceAll(second.StartDate, "-", ""); !strings.Contains(string(body), start) {
+			t.Errorf("block %s: no %s in\n%s", id, start, body)
+		}
+	}
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/api/calendar/2030/blocks/3.ics", http.StatusNotFound},
+		{"/api/calendar/2030/blocks/2030-03-05.ics", http.StatusNotFound},
+		{"/api/calendar/2030/blocks/first.ics", http.StatusBadRequest},
+		{"/api/calendar/2030/blocks/1", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		resp := srv.Do(http.MethodGet, tt.path, nil)
+		resp.Body.Close()
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("GET %s: status %d, want %d", tt.path, resp.StatusCode, tt.wantStatus)
+		}
+	}
+}
+
+func TestDraftOutOfOffice(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithVacations(2030, "2030-08-12", "2030-08-13"))
+
+	var calendar models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+	if len(calendar.VacationBlocks) != 1 {
+		t.Fatalf("%d vacation blocks, want 1", len(calendar.VacationBlocks))
+	}
+	block := calendar.VacationBlocks[0]
+
+	for _, language := range []string{"en", "pt-PT"} {
+		var draft models.OutOfOfficeDraft
+		input := map[string]string{"language": language, "contact": "Ana Silva (ana@example.com)"}
+		if status := srv.JSON(http.MethodPost, "/api/calendar/2030/blocks/"+block.StartDate+"/out-of-office", input, &draft); status != http.StatusOK {
+			t.Fatalf("%s: status %d", language, status)
+		}
+		if draft.StartDate != block.StartDate || draft.EndDate != block.EndDate || draft.ReturnDate != "2030-08-14" {
+			t.Errorf("%s: dates %s to %s back %s, want the block's and 2030-08-14", language, draft.StartDate, draft.EndDate, draft.ReturnDate)
+		}
+		if draft.Language != language || draft.Subject == "" || draft.Handover == "" || !strings.Contains(draft.AutoReply, "Ana Silva") {
+			t.Errorf("%s: draft %+v", language, draft)
+		}
+	}
+
+	var draft models.OutOfOfficeDraft
+	if status := srv.JSON(http.MethodPost, "/api/calendar/2030/blocks/1/out-of-office", nil, &draft); status != http.StatusOK {
+		t.Fatalf("without a body: status %d", status)
+	}
+	if draft.Language != "en" {
+		t.Errorf("default language = %q, want en", draft.Language)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		input      map[string]string
+		wantStatus int
+	}{
+		{"unknown language", "/api/calendar/2030/blocks/1/out-of-office", map[string]string{"language": "fr"}, http.StatusBadRequest},
+		{"notes too long", "/api/calendar/2030/blocks/1/out-of-office", map[string]string{"notes": strings.Repeat("x", 4001)}, http.StatusBadRequest},
+		{"no such block", "/api/calendar/2030/blocks/2/out-of-office", nil, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if status := srv.JSON(http.MethodPost, tt.path, tt.input, nil); status != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d", tt.name, status, tt.wantStatus)
+		}
+	}
+}
+
+func TestBlockLabels(t *testing.T) {
+	// Tuesday and Wednesday
+	srv := testutil.NewServer(t, testutil.WithVacations(2030, "2030-08-13", "2030-08-14"))
+
+	if status := srv.JSON(http.MethodPut, "/api/calendar/2030/blocks/1", map[string]string{"label": "Summer trip", "color": "red"}, nil); status != http.StatusBadRequest {
+		t.Errorf("invalid color: status %d, want %d", status, http.StatusBadRequest)
+	}
+	var block models.VacationBlock
+	if status := srv.JSON(http.MethodPut, "/api/calendar/2030/blocks/1", map[string]string{"label": "Summer trip", "color": "#FFAA00"}, &block); status != http.StatusOK {
+		t.Fatalf("label block: status %d", status)
+	}
+	if block.Label != "Summer trip" || block.Color != "#ffaa00" {
+		t.Errorf("block = %+v, want Summer trip in #ffaa00", block)
+	}
+
+	// The label stays with the block when it grows earlier
+	srv.JSON(http.MethodPost, "/api/vacations/2030", map[string]string{"date": "2030-08-12"}, nil)
+	var calendar models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+	if len(calendar.VacationBlocks) != 1 || calendar.VacationBlocks[0].Label != "Summer trip" {
+		t.Fatalf("blocks = %+v, want one labeled Summer trip", calendar.VacationBlocks)
+	}
+
+	resp := srv.Do(http.MethodGet, "/api/calendar/2030/blocks/1.ics", nil)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "SUMMARY:Summer trip (") {
+		t.Errorf("block calendar has no labeled summary:\n%s", body)
+	}
+
+	srv.JSON(http.MethodDelete, "/api/calendar/2030/blocks/1", nil, nil)
+	var cleared models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &cleared)
+	if cleared.VacationBlocks[0].Label != "" || cleared.VacationBlocks[0].Color != "" {
+		t.Errorf("block after delete = %+v, want no label", cleared.VacationBlocks[0])
+	}
+
+	// Labels are year data, closed while another client holds the edit lock
+	srv.ClientID = "other-tab"
+	if status := srv.JSON(http.MethodPost, "/api/calendar/2030/lock", nil, nil); status != http.StatusOK {
+		t.Fatalf("acquire lock: status %d", status)
+	}
+	srv.ClientID = ""
+	if status := srv.JSON(http.MethodPut, "/api/calendar/2030/blocks/1", map[string]string{"label": "Summer trip"}, nil); status != http.StatusConflict {
+		t.Errorf("label while locked: status %d, want %d", status, http.StatusConflict)
+	}
+	if status := srv.JSON(http.MethodDelete, "/api/calendar/2030/blocks/1", nil, nil); status != http.StatusConflict {
+		t.Errorf("delete label while locked: status %d, want %d", status, http.StatusConflict)
+	}
+}
+
+func TestAcceptOptimized(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 10, OptimizationStrategy: models.StrategyLongestBlocks}))
+
+	var result struct {
+		Blocks []models.VacationBlock `json:"blocks"`
+	}
+	if status := srv.JSON(http.MethodPost, "/api/calendar/2030/optimize", nil, &result); status != http.StatusOK || len(result.Blocks) < 2 {
+		t.Fatalf("optimize: status %d, %d blocks, want at least 2", status, len(result.Blocks))
+	}
+
+	var calendar models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+	first := calendar.VacationBlocks[0]
+
+	var accepted struct {
+		Accepted []string `json:"accepted"`
+	}
+	if status := srv.JSON(http.MethodPost, "/api/calendar/2030/optimized/accept?block="+first.StartDate, nil, &accepted); status != http.StatusOK || len(accepted.Accepted) == 0 {
+		t.Fatalf("accept block: status %d, %d days", status, len(accepted.Accepted))
+	}
+	for _, date := range accepted.Accepted {
+		if date < first.StartDate || date > first.EndDate {
+			t.Errorf("accepted %s outside the block %s..%s", date, first.StartDate, first.EndDate)
+		}
+	}
+
+	// Accepted days survive clearing the optimized plan
+	srv.JSON(http.MethodDelete, "/api/calendar/2030/optimized", nil, nil)
+	var vacations []models.VacationDay
+	srv.JSON(http.MethodGet, "/api/vacations/2030", nil, &vacations)
+	if len(vacations) != len(accepted.Accepted) {
+		t.Errorf("%d manual days after clearing, want the %d accepted", len(vacations), len(accepted.Accepted))
+	}
+
+	if status := srv.JSON(http.MethodPost, "/api/calendar/2030/optimized/accept?block=99", nil, nil); status != http.StatusNotFound {
+		t.Errorf("unknown block: status %d, want %d", status, http.StatusNotFound)
+	}
+
+	// Without a block, the whole optimized plan is accepted
+	srv.JSON(http.MethodPost, "/api/calendar/2030/optimize", nil, nil)
+	srv.JSON(http.MethodPost, "/api/calendar/2030/optimized/accept", nil, &accepted)
+	var after models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &after)
+	if len(after.OptimalVacations) != 0 || len(after.ManualVacations) != 10 {
+		t.Errorf("after accepting all: %d optimized, %d manual days, want 0 and 10", len(after.OptimalVacations), len(after.ManualVacations))
+	}
+}
+
+func TestDeclineOptimizedBlock(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 5, OptimizationStrategy: models.StrategyBridgeHolidays}))
+
+	if status := srv.JSON(http.MethodPost, "/api/calendar/2030/optimize", nil, nil); status != http.StatusOK {
+		t.Fatalf("optimize: status %d", status)
+	}
+	var calendar models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+	if len(calendar.VacationBlocks) == 0 {
+		t.Fatal("no vacation blocks")
+	}
+	first := calendar.VacationBlocks[0]
+
+	var result struct {
+		Declined models.DeclinedBlock   `json:"declined"`
+		Blocks   []models.VacationBlock `json:"blocks"`
+	}
+	if status := srv.JSON(http.MethodPost, "/api/calendar/2030/optimized/decline?block=1", nil, &result); status != http.StatusOK {
+		t.Fatalf("decline: status %d", status)
+	}
+	if result.Declined.StartDate != first.StartDate || len(result.Declined.Dates) == 0 {
+		t.Errorf("declined = %+v, want the block starting %s", result.Declined, first.StartDate)
+	}
+	for _, block := range result.Blocks {
+		for _, date := range result.Declined.Dates {
+			for _, d := range block.Dates {
+				if d == date {
+					t.Errorf("new block %s..%s takes declined %s", block.StartDate, block.EndDate, date)
+				}
+			}
+		}
+	}
+
+	var declined []models.DeclinedBlock
+	srv.JSON(http.MethodGet, "/api/calendar/2030/declined", nil, &declined)
+	if len(declined) != 1 {
+		t.Fatalf("%d declined blocks, want 1", len(declined))
+	}
+	if status := srv.JSON(http.MethodDelete, fmt.Sprintf("/api/calendar/2030/declined/%d", declined[0].ID), nil, nil); status != http.StatusOK {
+		t.Errorf("delete declined: status %d", status)
+	}
+
+	// Manual days can't be declined
+	srv.JSON(http.MethodDelete, "/api/calendar/2030/optimized", nil, nil)
+	srv.JSON(http.MethodPost, "/api/vacations/2030", map[string]string{"date": "2030-03-04"}, nil)
+	if status := srv.JSON(http.MethodPost, "/api/calendar/2030/optimized/decline?block=2030-03-02", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("decline manual block: status %d, want %d", status, http.StatusBadRequest)
+	}
+}
+
+func TestLockedDates(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2030, VacationDays: 10, OptimizationStrategy: models.StrategyLongestBlocks}),
+		testutil.WithVacations(2030, "2030-03-04"),
+	)
+
+	srv.JSON(http.MethodPost, "/api/calendar/2030/optimize", nil, nil)
+	var calendar models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &calendar)
+	if len(calendar.OptimalVacations) == 0 {
+		t.Fatal("no optimized days")
+	}
+	suggested := calendar.OptimalVacations[0].Date
+
+	// Lock the manual day, a suggested day and a working day to keep free
+	var locked []models.LockedDate
+	body := map[string]interface{}{"dates": []string{"2030-03-04", suggested, "2030-06-12"}, "reason": "Approved"}
+	if status := srv.JSON(http.MethodPost, "/api/vacations/2030/locked", body, &locked); status != http.StatusOK || len(locked) != 3 {
+		t.Fatalf("lock: status %d, %d locked dates, want 3", status, len(locked))
+	}
+
+	for _, tt := range []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+	}{
+		{"remove", http.MethodDelete, "/api/vacations/2030/2030-03-04", nil},
+		{"add", http.MethodPost, "/api/vacations/2030", map[string]string{"date": "2030-06-12"}},
+		{"bulk", http.MethodPut, "/api/vacations/2030/bulk", map[string][]string{"add": {"2030-06-11"}, "remove": {"2030-03-04"}}},
+	} {
+		if status := srv.JSON(tt.method, tt.path, tt.body, nil); status != http.StatusConflict {
+			t.Errorf("%s locked date: status %d, want %d", tt.name, status, http.StatusConflict)
+		}
+	}
+
+	// Re-optimizing and clearing keep the locked suggestion and never take
+	// the locked working day
+	srv.JSON(http.MethodPost, "/api/calendar/2030/optimize", nil, nil)
+	srv.JSON(http.MethodDelete, "/api/calendar/2030/optimized", nil, nil)
+	var after models.CalendarResponse
+	srv.JSON(http.MethodGet, "/api/calendar/2030", nil, &after)
+	if len(after.OptimalVacations) != 1 || after.OptimalVacations[0].Date != suggested {
+		t.Errorf("optimized days after clearing = %+v, want only the locked %s", after.OptimalVacations, suggested)
+	}
+	for _, day := range after.Days {
+		if day.Date == "2030-06-12" && (day.IsVacation || !day.IsLocked) {
+			t.Errorf("locked working day = %+v", day)
+		}
+	}
+
+	if status := srv.JSON(http.MethodDelete, "/api/vacations/2030/locked/2030-03-04", nil, nil); status != http.StatusOK {
+		t.Errorf("unlock: status %d", status)
+	}
+	if status := srv.JSON(http.MethodDelete, "/api/vacations/2030/2030-03-04", nil, nil); status != http.StatusOK {
+		t.Errorf("remove unlocked date: status %d", status)
+	}
+	if status := srv.JSON(http.MethodDelete, "/api/vacations/2030/locked/2030-03-04", nil, nil); status != http.StatusNotFound {
+		t.Errorf("unlock again: status %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestPastDateProtection(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithSetting("past_edit_protection", "true"),
+		testutil.WithVacations(2020, "2020-03-04"),
+	)
+
+	if status := srv.JSON(http.MethodPost, "/api/vacations/2020", map[string]string{"date": "2020-03-05"}, nil); status != http.StatusConflict {
+		t.Errorf("add past day: status %d, want %d", status, http.StatusConflict)
+	}
+	if status := srv.JSON(http.MethodDelete, "/api/vacations/2020/2020-03-04", nil, nil); status != http.StatusConflict {
+		t.Errorf("remove past day: status %d, want %d", status, http.StatusConflict)
+	}
+	if status := srv.JSON(http.MethodPut, "/api/vacations/2020/bulk", map[string][]string{"remove": {"2020-03-04"}}, nil); status != http.StatusConflict {
+		t.Errorf("bulk remove past day: status %d, want %d", status, http.StatusConflict)
+	}
+
+	srv.JSON(http.MethodPut, "/api/settings/past_edit_protection", map[string]string{"value": "false"}, nil)
+	if status := srv.JSON(http.MethodDelete, "/api/vacations/2020/2020-03-04", nil, nil); status != http.StatusOK {
+		t.Errorf("remove past day without protection: status %d", status)
+	}
+}
+
+func TestActivateScenarioKeepsProtectedDays(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithSetting("past_edit_protection", "true"),
+		testutil.WithVacations(2020, "2020-03-04"),
+		testutil.WithVacations(2030, "2030-03-04", "2030-03-05"),
+	)
+	srv.JSON(http.MethodPost, "/api/vacations/2030/locked", map[string][]string{"dates": {"2030-03-04"}}, nil)
+
+	for _, tt := range []struct {
+		year int
+		want []string
+	}{
+		{2020, []string{"2020-03-04"}}, // Past
+		{2030, []string{"2030-03-04"}}, // Locked, while the unlocked day is swapped out
+	} {
+		// A new scenario is empty
+		var scenario models.Scenario
+		srv.JSON(http.MethodPost, fmt.Sprintf("/api/scenarios/%d", tt.year), map[string]string{"name": "Empty"}, &scenario)
+		if status := srv.JSON(http.MethodPost, fmt.Sprintf("/api/scenarios/%d/%d/activate", tt.year, scenario.ID), nil, nil); status != http.StatusOK {
+			t.Fatalf("activate %d: status %d", tt.year, status)
+		}
+
+		var vacations []models.VacationDay
+		srv.JSON(http.MethodGet, fmt.Sprintf("/api/vacations/%d", tt.year), nil, &vacations)
+		var got []string
+		for _, v := range vacations {
+			got = append(got, v.Date)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d vacations after activating an empty scenario = %v, want %v", tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestDataManagement(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2020, VacationDays: 22}),
+		testutil.WithVacations(2020, "2020-03-04", "2020-03-05"),
+		testutil.WithVacations(2030, "2030-03-04"),
+	)
+	srv.JSON(http.MethodPost, "/api/trips/2020", map[string]string{"name": "Lisbon", "start_date": "2020-03-04", "end_date": "2020-03-05"}, nil)
+	srv.JSON(http.MethodPost, "/api/trips/2020/1/expenses", map[string]interface{}{"date": "2020-03-04", "description": "Hotel", "amount": 120}, nil)
+
+	var years []models.YearData
+	if status := srv.JSON(http.MethodGet, "/api/admin/data", nil, &years); status != http.StatusOK || len(years) != 2 {
+		t.Fatalf("stored years: status %d, %+v, want 2020 and 2030", status, years)
+	}
+	if years[0].Year != 2020 || years[0].Tables["vacation_days"] != 2 || years[0].Tables["trip_expenses"] != 1 {
+		t.Errorf("2020 = %+v, want 2 vacation days and 1 trip expense", years[0])
+	}
+
+	if status := srv.JSON(http.MethodPost, "/api/admin/data/archive", map[string]int{"before": 2100}, nil); status != http.StatusBadRequest {
+		t.Errorf("archive a year not over: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	resp := srv.Do(http.MethodPost, "/api/admin/data/archive", map[string]int{"before": 2025})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/gzip" {
+		t.Fatalf("archive: status %d, content type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("archive is not gzipped: %v", err)
+	}
+	var archive models.YearArchive
+	if err := json.NewDecoder(zr).Decode(&archive); err != nil {
+		t.Fatalf("decode archive: %v", err)
+	}
+	if len(archive.Years) != 1 || archive.Years[0] != 2020 || len(archive.Tables["vacation_days"]) != 2 || len(archive.Tables["trip_expenses"]) != 1 {
+		t.Errorf("archive has years %v, %d vacation days and %d trip expenses", archive.Years, len(archive.Tables["vacation_days"]), len(archive.Tables["trip_expenses"]))
+	}
+	if date := archive.Tables["vacation_days"][0]["date"]; date != "2020-03-04" && date != "2020-03-05" {
+		t.Errorf("archived vacation day date = %v", date)
+	}
+
+	// Archived years are purged; purging removes the rest
+	var after []models.YearData
+	srv.JSON(http.MethodGet, "/api/admin/data", nil, &after)
+	if len(after) != 1 || after[0].Year != 2030 {
+		t.Errorf("stored years after archiving = %+v, want only 2030", after)
+	}
+	if status := srv.JSON(http.MethodDelete, "/api/admin/data/2030", nil, nil); status != http.StatusOK {
+		t.Errorf("purge: status %d", status)
+	}
+	var purged []models.YearData
+	srv.JSON(http.MethodGet, "/api/admin/data", nil, &purged)
+	if len(purged) != 0 {
+		t.Errorf("stored years after purging = %+v, want none", purged)
+	}
+}
+
+func TestBackups(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithVacations(2030, "2030-03-04"))
+
+	if status := srv.JSON(http.MethodPost, "/api/admin/backups", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("backup without a target: status %d, want %d", status, http.StatusBadRequest)
+	}
+	if status := srv.JSON(http.MethodPut, "/api/settings/backup_schedule", map[string]string{"value": "every night"}, nil); status != http.StatusBadRequest {
+		t.Errorf("invalid backup schedule: status %d, want %d", status, http.StatusBadRequest)
+	}
+	dir := t.TempDir()
+	settings := map[string]string{"backup_target": "filesystem", "backup_dir": dir, "backup_schedule": "0 4 * * *"}
+	if status := srv.JSON(http.MethodPut, "/api/settings", settings, nil); status != http.StatusOK {
+		t.Fatalf("configure backups: status %d", status)
+	}
+
+	var jobs []struct {
+		Name     string `json:"name"`
+		Schedule string `json:"schedule"`
+	}
+	srv.JSON(http.MethodGet, "/api/admin/jobs", nil, &jobs)
+	for _, job := range jobs {
+		if job.Name == "backup_database" && job.Schedule != "0 4 * * *" {
+			t.Errorf("backup job schedule = %q, want the setting", job.Schedule)
+		}
+	}
+
+	var created struct {
+		Backup struct {
+			Name string `json:"name"`
+			Size int64  `json:"size"`
+		} `json:"backup"`
+	}
+	if status := srv.JSON(http.MethodPost, "/api/admin/backups", nil, &created); status != http.StatusOK || created.Backup.Size == 0 {
+		t.Fatalf("backup: status %d, %+v", status, created)
+	}
+
+	// Changes after the backup are undone by restoring it
+	srv.JSON(http.MethodPost, "/api/vacations/2030", map[string]string{"date": "2030-03-05"}, nil)
+	var restored struct {
+		Previous struct {
+			Name string `json:"name"`
+		} `json:"previous"`
+	}
+	if status := srv.JSON(http.MethodPost, "/api/admin/backups/"+created.Backup.Name+"/restore", nil, &restored); status != http.StatusOK {
+		t.Fatalf("restore: status %d", status)
+	}
+	var vacations []models.VacationDay
+	srv.JSON(http.MethodGet, "/api/vacations/2030", nil, &vacations)
+	if len(vacations) != 1 || vacations[0].Date != "2030-03-04" {
+		t.Errorf("vacations after restore = %+v, want only 2030-03-04", vacations)
+	}
+
+	// The database before the restore was backed up too
+	var backups []struct {
+		Name string `json:"name"`
+	}
+	srv.JSON(http.MethodGet, "/api/admin/backups", nil, &backups)
+	if len(backups) != 2 || backups[0].Name != restored.Previous.Name {
+		t.Errorf("backups = %+v, want the pre-restore backup %s first", backups, restored.Previous.Name)
+	}
+
+	if status := srv.JSON(http.MethodPost, "/api/admin/backups/vacation-planner-20300101T000000.000Z.db/restore", nil, nil); status != http.StatusNotFound {
+		t.Errorf("restore a missing backup: status %d, want %d", status, http.StatusNotFound)
+	}
+	if status := srv.JSON(http.MethodPost, "/api/admin/backups/calendar.db/restore", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("restore an invalid name: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	// Another tenant's backup in a shared directory can't be restored
+	data, err := os.ReadFile(filepath.Join(dir, created.Backup.Name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := "otherorg-20300101T000000.000Z.db"
+	if err := os.WriteFile(filepath.Join(dir, other), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if status := srv.JSON(http.MethodPost, "/api/admin/backups/"+other+"/restore", nil, nil); status != http.StatusNotFound {
+		t.Errorf("restore another prefix's backup: status %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestGetCalendars(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithYearConfig(models.YearConfig{Year: 2031, VacationDays: 25}),
+		testutil.WithVacations(2031, "2031-03-04", "2031-03-05"),
+	)
+
+	var response struct {
+		From  int                   `json:"from"`
+		To    int                   `json:"to"`
+		Years []models.YearCalendar `json:"years"`
+	}
+	if status := srv.JSON(http.MethodGet, "/api/calendar?from=2030&to=2032", nil, &response); status != http.StatusOK {
+		t.Fatalf("status %d", status)
+	}
+	if len(response.Years) != 3 || response.Years[0].Year != 2030 || response.Years[2].Year != 2032 {
+		t.Fatalf("years = %+v, want 2030 to 2032", response.Years)
+	}
+	y := response.Years[1]
+	if y.Summary.TotalVacationDays != 25 || y.Summary.UsedVacationDays != 2 || len(y.VacationBlocks) != 1 || y.Days != nil {
+		t.Errorf("2031 = %+v, want 25 days, 2 used in 1 block and no days", y)
+	}
+
+	var withDays struct {
+		Years []models.YearCalendar `json:"years"`
+	}
+	srv.JSON(http.MethodGet, "/api/calendar?from=2032&days=true", nil, &withDays)
+	if len(withDays.Years) != 1 || len(withDays.Years[0].Days) != 366 {
+		t.Errorf("2032 with days: %d years", len(withDays.Years))
+	}
+
+	for _, query := range []string{"", "?from=next", "?from=2030&to=2029", "?from=2030&to=2045", "?from=1800"} {
+		if status := srv.JSON(http.MethodGet, "/api/calendar"+query, nil, nil); status != http.StatusBadRequest {
+			t.Errorf("GET /api/calendar%s: status %d, want %d", query, status, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestGetCalendarRange(t *testing.T) {
+	srv := testutil.NewServer(t,
+		testutil.WithVacations(2030, "2030-12-30", "2030-12-31"),
+		testutil.WithVacations(2031, "2031-01-01", "2031-01-02", "2031-03-04"),
+	)
+
+	var response struct {
+		Start          string                 `json:"start"`
+		End            string                 `json:"end"`
+		Days           []models.CalendarDay   `json:"days"`
+		VacationBlocks []models.VacationBlock `json:"vacation_blocks"`
+	}
+	if status := srv.JSON(http.MethodGet, "/api/calendar/range?start=2030-12-20&end=2031-01-10", nil, &response); status != http.StatusOK {
+		t.Fatalf("status %d", status)
+	}
+	if len(response.Days) != 22 || response.Days[0].Date != "2030-12-20" || response.Days[21].Date != "2031-01-10" {
+		t.Fatalf("got %d days, want 2030-12-20 to 2031-01-10", len(response.Days))
+	}
+	// The block over New Year's Day comes back whole; the March one is out of range
+	if len(response.VacationBlocks) != 1 {
+		t.Fatalf("blocks = %+v, want 1", response.VacationBlocks)
+	}
+	b := response.VacationBlocks[0]
+	if b.StartDate != "2030-12-28" || b.EndDate != "2031-01-02" || b.TotalDays != 6 || len(b.Dates) != 6 {
+		t.Errorf("block = %+v, want 2030-12-28 to 2031-01-02", b)
+	}
+
+	for _, query := range []string{"", "?start=2030-12-20", "?start=2031-01-10&end=2030-12-20", "?start=2030-01-01&end=2031-06-01", "?start=1800-01-01&end=1800-01-02"} {
+		if status := srv.JSON(http.MethodGet, "/api/calendar/range"+query, nil, nil); status != http.StatusBadRequest {
+			t.Errorf("GET /api/calendar/range%s: status %d, want %d", query, status, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestGetHolidaysSince(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	type sync struct {
+		SyncedAt   string                 `json:"synced_at"`
+		Holidays   []models.HolidayChange `json:"holidays"`
+		Tombstones []models.HolidayChange `json:"tombstones"`
+	}
+	get := func(since string) sync {
+		t.Helper()
+		var response sync
+		if status := srv.JSON(http.MethodGet, "/api/holidays/2030?since="+since, nil, &response); status != http.StatusOK {
+			t.Fatalf("since %s: status %d", since, status)
+		}
+		return response
+	}
+
+	first := get("2000-01-01T00:00:00Z")
+	if len(first.Holidays) == 0 || len(first.Tombstones) != 0 {
+		t.Fatalf("first sync = %+v, want every holiday", first)
+	}
+	if unchanged := get(first.SyncedAt); len(unchanged.Holidays) != 0 || len(unchanged.Tombstones) != 0 {
+		t.Errorf("sync without changes = %+v", unchanged)
+	}
+
+	srv.JSON(http.MethodPut, "/api/holidays/2030/optional/carnaval", map[string]bool{"enabled": true}, nil)
+	added := get(first.SyncedAt)
+	if len(added.Holidays) != 1 || added.Holidays[0].Date != "2030-03-05" || len(added.Tombstones) != 0 {
+		t.Errorf("after enabling Carnaval = %+v", added)
+	}
+
+	srv.JSON(http.MethodPut, "/api/holidays/2030/optional/carnaval", map[string]bool{"enabled": false}, nil)
+	removed := get(added.SyncedAt)
+	if len(removed.Holidays) != 0 || len(removed.Tombstones) != 1 || removed.Tombstones[0].Date != "2030-03-05" || !removed.Tombstones[0].Deleted {
+		t.Errorf("after disabling Carnaval = %+v", removed)
+	}
+
+	if status := srv.JSON(http.MethodGet, "/api/holidays/2030?since=yesterday", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("invalid since: status %d, want %d", status, http.StatusBadRequest)
+	}
+}
+
+func TestGetHolidaysPreview(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	var preview []struct {
+		Date        string `json:"date"`
+		Name        string `json:"name"`
+		Provisional bool   `json:"provisional"`
+	}
+	if status := srv.JSON(http.MethodGet, "/api/holidays/2031?preview=true", nil, &preview); status != http.StatusOK {
+		t.Fatalf("preview: status %d", status)
+	}
+
+	dates := make(map[string]bool)
+	for _, hol := range preview {
+		if !hol.Provisional {
+			t.Errorf("%s %s is not flagged provisional", hol.Date, hol.Name)
+		}
+		dates[hol.Date] = true
+	}
+	// Easter 2031 falls on April 13
+	for _, date := range []string{"2031-01-01", "2031-04-11", "2031-04-13", "2031-06-12", "2031-12-25"} {
+		if !dates[date] {
+			t.Errorf("preview is missing %s", date)
+		}
+	}
+
+	if status := srv.JSON(http.MethodGet, "/api/holidays/1800?preview=true", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("preview of 1800: status %d, want %d", status, http.StatusBadRequest)
+	}
+}
+
+func TestValidateAI(t *testing.T) {
+	// The test server runs in sandbox mode, whose fake provider needs no key
+	srv := testutil.NewServer(t, testutil.WithSetting("ai_model", "openai/gpt-4o-mini"))
+
+	var result models.AIValidation
+	if status := srv.JSON(http.MethodPost, "/api/settings/ai/validate", nil, &result); status != http.StatusOK {
+		t.Fatalf("validate: status %d", status)
+	}
+	if !result.Valid || result.Provider != "github" || result.Model != "openai/gpt-4o-mini" || result.Error != "" {
+		t.Errorf("validation %+v, want a valid call to openai/gpt-4o-mini on github", result)
+	}
+}
+
+func TestGetAvailableModels(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	var list []models.AIModel
+	if status := srv.JSON(http.MethodGet, "/api/models", nil, &list); status != http.StatusOK {
+		t.Fatalf("models: status %d", status)
+	}
+	if len(list) != 1 || !list[0].Suitable || list[0].ContextLength == 0 || list[0].ToolCalling == nil {
+		t.Errorf("models %+v, want the sandbox model with its metadata", list)
+	}
+}
+
+func TestChatRecordsModel(t *testing.T) {
+	srv := testutil.NewServer(t, testutil.WithSetting("ai_fallback_models", "openai:gpt-4o"))
+
+	var reply struct {
+		Message string `json:"message"`
+		Model   string `json:"model"`
+	}
+	if status := srv.JSON(http.MethodPost, "/api/chat/2030", map[string]string{"message": "Hello"}, &reply); status != http.StatusOK {
+		t.Fatalf("chat: status %d", status)
+	}
+	if reply.Model != "github:openai/gpt-4o-mini" {
+		t.Errorf("answered by %q, want the configured model", reply.Model)
+	}
+
+	var history []models.ChatMessage
+	srv.JSON(http.MethodGet, "/api/chat/2030/history", nil, &history)
+	if len(history) != 2 || history[0].Model != "" || history[1].Model != reply.Model {
+		t.Errorf("history %+v, want the user message without a model and the answer with %q", history, reply.Model)
+	}
+}
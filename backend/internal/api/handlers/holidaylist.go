@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// holidayTypeCustom selects the holidays generated by the settings rather
+// than loaded from the APIs
+const holidayTypeCustom = "custom"
+
+// holidayFilterTypes are the types ?type= accepts, by the holiday types they
+// select
+var holidayFilterTypes = map[string][]string{
+	"national":                   {"national"},
+	"municipal":                  {"municipal"},
+	holidayTypeCustom:            {holidays.HolidayTypeObserved, holidays.HolidayTypeOptional, holidays.HolidayTypeBirthday},
+	holidays.HolidayTypeObserved: {holidays.HolidayTypeObserved},
+	holidays.HolidayTypeOptional: {holidays.HolidayTypeOptional},
+	holidays.HolidayTypeBirthday: {holidays.HolidayTypeBirthday},
+}
+
+// holidayFilter narrows a year's holidays by type, date range and location
+type holidayFilter struct {
+	types    map[string]bool // Empty for every type
+	from, to string          // Inclusive, empty for no bound
+	location string
+}
+
+// parseHolidayFilter reads the ?type= (comma separated), ?from=, ?to= and
+// ?location= filters of a holidays request for a year
+func parseHolidayFilter(c *gin.Context, year int) (holidayFilter, error) {
+	f := holidayFilter{
+		types:    make(map[string]bool),
+		from:     c.Query("from"),
+		to:       c.Query("to"),
+		location: strings.TrimSpace(c.Query("location")),
+	}
+
+	if value := c.Query("type"); value != "" {
+		for _, name := range strings.Split(value, ",") {
+			types, ok := holidayFilterTypes[strings.TrimSpace(name)]
+			if !ok {
+				return f, invalidInput(fmt.Errorf("unknown holiday type %q, must be national, municipal or custom", name))
+			}
+			for _, t := range types {
+				f.types[t] = true
+			}
+		}
+	}
+
+	for _, date := range []string{f.from, f.to} {
+		if date == "" {
+			continue
+		}
+		if err := checkDateInYear(date, year); err != nil {
+			return f, err
+		}
+	}
+	if f.from != "" && f.to != "" && f.to < f.from {
+		return f, invalidInputCode(models.CodeInvalidDate, fmt.Errorf("to %s is before from %s", f.to, f.from))
+	}
+	return f, nil
+}
+
+// empty reports whether the filter lets every holiday through
+func (f holidayFilter) empty() bool {
+	return len(f.types) == 0 && f.from == "" && f.to == "" && f.location == ""
+}
+
+// match reports whether a holiday on a date, of a type and location passes
+// the filter. Only municipal holidays have a location, so ?location= leaves
+// out the others.
+func (f holidayFilter) match(date, typ, location string) bool {
+	switch {
+	case len(f.types) > 0 && !f.types[typ]:
+		return false
+	case f.from != "" && date < f.from:
+		return false
+	case f.to != "" && date > f.to:
+		return false
+	case f.location != "" && !holidays.InLocation(location, f.location):
+		return false
+	}
+	return true
+}
+
+// filterHolidays returns the holidays that pass a filter
+func filterHolidays(holidayList []holidays.PortugueseHoliday, f holidayFilter) []holidays.PortugueseHoliday {
+	if f.empty() {
+		return holidayList
+	}
+	filtered := []holidays.PortugueseHoliday{}
+	for _, hol := range holidayList {
+		if f.match(hol.Date, hol.Type, hol.Location) {
+			filtered = append(filtered, hol)
+		}
+	}
+	return filtered
+}
+
+// filterHolidayChanges returns the holiday changes that pass a filter
+func filterHolidayChanges(changes []models.HolidayChange, f holidayFilter) []models.HolidayChange {
+	if f.empty() {
+		return changes
+	}
+	filtered := []models.HolidayChange{}
+	for _, change := range changes {
+		if f.match(change.Date, change.Type, change.Location) {
+			filtered = append(filtered, change)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"math"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// applyHoursBalance fills in the hours balance of a summary when the year
+// tracks vacation in hours
+func (h *Handler) applyHoursBalance(ctx context.Context, summary *models.CalendarSummary, year int, config models.YearConfig, manualVacations []models.VacationDay, optimalVacations []models.OptimalVacation) {
+	if !config.HoursMode() {
+		return
+	}
+
+	var dates []string
+	for _, v := range manualVacations {
+		dates = append(dates, v.Date)
+	}
+	for _, v := range optimalVacations {
+		dates = append(dates, v.Date)
+	}
+
+	total := config.EntitlementHours() + h.compensationHours(ctx, year, config)
+	used := bookedHours(config, dates)
+
+	summary.TotalVacationHours = roundHours(total)
+	summary.UsedVacationHours = roundHours(used)
+	summary.RemainingVacationHours = roundHours(total - used)
+}
+
+// availableVacationDays returns how many vacation days the optimizer can
+// place. In hours mode the remaining hours are converted to days using the
+// average length of a work day.
+func (h *Handler) availableVacationDays(ctx context.Context, year int, config models.YearConfig, manualDates []string) int {
+	var available int
+	if config.HoursMode() {
+		average := config.AverageWorkingHours()
+		remaining := config.EntitlementHours() + h.compensationHours(ctx, year, config) -
+			float64(config.ReservedDays)*average - bookedHours(config, manualDates)
+		if average > 0 {
+			available = int(math.Floor(remaining / average))
+		}
+	} else {
+		available = config.VacationDays + h.compPool(ctx, year).Balance - config.ReservedDays - len(manualDates)
+	}
+
+	if available < 0 {
+		available = 0
+	}
+	return available
+}
+
+// compensationHours returns the hours left in the compensation pool: one
+// work day for each worked holiday and credited day, less the comp days taken
+// off, with the days adjusted by hand
+func (h *Handler) compensationHours(ctx context.Context, year int, config models.YearConfig) float64 {
+	var earned, spent []string
+	worked, _ := h.store.Holidays.Worked(ctx, year)
+	for _, w := range worked {
+		earned = append(earned, w.Date)
+	}
+	entries, _ := h.store.Comp.List(ctx, year)
+	for _, e := range entries {
+		if e.Kind == models.CompCredit {
+			earned = append(earned, e.Date)
+		} else {
+			spent = append(spent, e.Date)
+		}
+	}
+
+	total := 0.0
+	for _, dateStr := range earned {
+		date, err := dates.Parse(dateStr)
+		if err != nil {
+			continue
+		}
+		if hours := config.HoursOn(date); hours > 0 {
+			total += hours
+		} else {
+			total += config.AverageWorkingHours()
+		}
+	}
+	// Adjusted days count as average work days
+	adjustments, _ := h.store.Comp.Adjustments(ctx, year)
+	for _, a := range adjustments {
+		total += float64(a.Days) * config.AverageWorkingHours()
+	}
+	return total - bookedHours(config, spent)
+}
+
+// bookedHours returns the working hours covered by vacation dates
+func bookedHours(config models.YearConfig, days []string) float64 {
+	seen := make(map[string]bool)
+	total := 0.0
+	for _, dateStr := range days {
+		if seen[dateStr] {
+			continue
+		}
+		seen[dateStr] = true
+
+		date, err := dates.Parse(dateStr)
+		if err != nil {
+			continue
+		}
+		total += config.HoursOn(date)
+	}
+	return total
+}
+
+func roundHours(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/events"
+	"github.com/bruno.lopes/calendar/backend/internal/hr"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// ImportLeave imports the approved leave of a year from the HR system now,
+// instead of waiting for the nightly job
+func (h *Handler) ImportLeave(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	result, err := h.importLeave(c.Request.Context(), year)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// importLeave adds the approved leave of a year in the HR system as manual
+// vacation days. Approved work days replace optimized days on the same date;
+// days off in the planner (weekends, holidays) are skipped. Days removed in
+// the HR system are left in the planner.
+func (h *Handler) importLeave(ctx context.Context, year int) (models.LeaveImport, error) {
+	if err := checkYear(year); err != nil {
+		return models.LeaveImport{}, err
+	}
+
+	s := h.loadSettings(ctx)
+	importer, err := hr.New(hr.Config{
+		Provider:   s.HRProvider,
+		APIKey:     s.HRAPIKey,
+		APISecret:  s.HRAPISecret,
+		Company:    s.HRCompany,
+		EmployeeID: s.HREmployeeID,
+	})
+	if err != nil {
+		return models.LeaveImport{}, invalidInputCode(models.CodeNotConfigured, err)
+	}
+
+	config, err := h.getOrCreateYearConfig(ctx, year)
+	if err != nil {
+		return models.LeaveImport{}, err
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	leave, err := importer.ApprovedLeave(ctx, from, to)
+	if err != nil {
+		return models.LeaveImport{}, upstreamFailure(err)
+	}
+
+	holidayDates := make(map[string]bool)
+	for _, hol := range h.holidaysForYear(ctx, year) {
+		holidayDates[hol.Date] = true
+	}
+	planned := make(map[string]bool)
+	manualVacations, _ := h.store.Vacations.List(ctx, year)
+	for _, v := range manualVacations {
+		planned[v.Date] = true
+	}
+
+	result := models.LeaveImport{Year: year, Provider: s.HRProvider, Imported: []string{}}
+	counted := make(map[string]bool)
+	notes := make(map[string]string)
+	for _, l := range leave {
+		for _, date := range l.Dates {
+			parsed, err := dates.Parse(date)
+			if err != nil || parsed.Year() != year || counted[date] {
+				continue
+			}
+			counted[date] = true
+
+			switch {
+			case planned[date]:
+				result.Existing++
+			case holidayDates[date] || !config.IsWorkDay(parsed):
+				result.Skipped++
+			default:
+				notes[date] = fmt.Sprintf("%s: %s", hr.ProviderName(s.HRProvider), l.Type)
+				result.Imported = append(result.Imported, date)
+			}
+		}
+	}
+	sort.Strings(result.Imported)
+
+	if len(result.Imported) == 0 {
+		return result, nil
+	}
+
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		for _, date := range result.Imported {
+			if err := tx.Vacations.RemoveOptimal(ctx, year, date); err != nil {
+				return err
+			}
+			if err := tx.Vacations.Add(ctx, year, date, notes[date]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return models.LeaveImport{}, err
+	}
+
+	h.events.Publish(events.VacationAdded, year, gin.H{"dates": result.Imported, "source": s.HRProvider})
+	return result, nil
+}
+
+// importLeaveJob imports the approved leave of the current and next year,
+// when an HR system is configured
+func (h *Handler) importLeaveJob(ctx context.Context) error {
+	s := h.loadSettings(ctx)
+	if s.HRProvider == "" || s.HRProvider == hr.ProviderNone {
+		return nil
+	}
+
+	currentYear := dates.Today(dates.Location(s.Timezone)).Year()
+	var errs []error
+	for year := currentYear; year <= currentYear+1; year++ {
+		if _, err := h.importLeave(ctx, year); err != nil {
+			errs = append(errs, fmt.Errorf("%d: %w", year, err))
+		}
+	}
+	return errors.Join(errs...)
+}
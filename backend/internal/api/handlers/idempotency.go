@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader marks a response replayed to a retry
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	// idempotencyRetention is how long retries get the stored response
+	idempotencyRetention = 24 * time.Hour
+	// idempotencyAbandoned is how long a request may run before a retry
+	// takes over its key, in case the server stopped while running it
+	idempotencyAbandoned = 10 * time.Minute
+)
+
+// idempotencyRecorder keeps a copy of the response body written by the
+// handlers
+type idempotencyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *idempotencyRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *idempotencyRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes POST and PUT requests sent with an Idempotency-Key safe
+// to retry: the first one runs and, once it succeeds, its response is
+// replayed to every retry with the same key for a day instead of running it
+// again. A key is the caller's, so tokens can't see each other's responses.
+// Failed requests release their key, and a key reused for another request is
+// rejected.
+func (h *Handler) Idempotency(c *gin.Context) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	if key == "" || (c.Request.Method != http.MethodPost && c.Request.Method != http.MethodPut) {
+		c.Next()
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		abortProblem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Idempotency-Key is longer than "+strconv.Itoa(maxIdempotencyKeyLength)+" characters")
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		abortProblem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+	hash.Write(body)
+
+	request := models.IdempotentRequest{
+		Scope:       idempotencyScope(c),
+		Key:         key,
+		Method:      c.Request.Method,
+		Path:        c.Request.URL.RequestURI(),
+		RequestHash: hex.EncodeToString(hash.Sum(nil)),
+	}
+
+	// The response is stored even if the client gave up waiting for it
+	ctx := context.WithoutCancel(c.Request.Context())
+	now := time.Now()
+	stored, begun, err := h.store.Requests.Begin(ctx, request, now.Add(-idempotencyRetention), now.Add(-idempotencyAbandoned))
+	if err != nil {
+		abortError(c, err)
+		return
+	}
+	if !begun {
+		switch {
+		case stored.RequestHash != request.RequestHash:
+			abortProblem(c, http.StatusUnprocessableEntity, models.CodeInvalidRequest, "Idempotency-Key was already used for a different request")
+		case stored.Status == 0:
+			c.Header("Retry-After", "1")
+			abortProblem(c, http.StatusConflict, models.CodeConflict, "A request with this Idempotency-Key is still running")
+		default:
+			c.Header(idempotentReplayedHeader, "true")
+			c.Data(stored.Status, stored.ContentType, stored.Body)
+			c.Abort()
+		}
+		return
+	}
+
+	recorder := &idempotencyRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	c.Next()
+
+	if status := recorder.Status(); status >= 200 && status < 300 {
+		err := h.store.Requests.Complete(ctx, request.Scope, key, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+		if err == nil {
+			return
+		}
+		log.Printf("Failed to store the response to Idempotency-Key %q: %v", key, err)
+	}
+	if err := h.store.Requests.Release(ctx, request.Scope, key); err != nil {
+		log.Printf("Failed to release Idempotency-Key %q: %v", key, err)
+	}
+}
+
+// idempotencyScope is the owner of a request's idempotency keys: its access
+// token, or everyone while the API is open
+func idempotencyScope(c *gin.Context) string {
+	if token, ok := requestToken(c); ok {
+		return strconv.FormatInt(token.ID, 10)
+	}
+	return ""
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/scheduler"
+)
+
+// Background job names
+const (
+	jobRefreshHolidays   = "refresh_holidays"
+	jobPruneCaches       = "prune_caches"
+	jobSendNotifications = "send_notifications"
+	jobImportLeave       = "import_leave"
+	jobImportOnCall      = "import_oncall"
+	jobImportMilestones  = "import_milestones"
+	jobBackupDatabase    = "backup_database"
+)
+
+// registerJobs adds the periodic background jobs to the scheduler
+func (h *Handler) registerJobs() {
+	jobs := []scheduler.Job{
+		{
+			Name:        jobRefreshHolidays,
+			Description: "Fetch the holidays of the current and pre-fetched years again",
+			Schedule:    "0 3 * * *",
+			Run:         h.refreshHolidaysJob,
+		},
+		{
+			Name:        jobPruneCaches,
+			Description: "Drop expired holiday cache entries, edit locks, share links, failed login records, idempotency keys and old audit entries",
+			Schedule:    "30 * * * *",
+			Run:         h.pruneCachesJob,
+		},
+		{
+			Name:        jobSendNotifications,
+			Description: "Send due digests, vacation reminders, carryover and unused days alerts",
+			Schedule:    "0 * * * *",
+			Run: func(ctx context.Context) error {
+				h.notifier.RunScheduled(time.Now())
+				return nil
+			},
+		},
+		{
+			Name:        jobImportLeave,
+			Description: "Import approved leave of the current and next year from the HR system",
+			Schedule:    "0 4 * * *",
+			Run:         h.importLeaveJob,
+		},
+		{
+			Name:        jobImportOnCall,
+			Description: "Import the on-call shifts of the current and next year as blackout periods",
+			Schedule:    "15 4 * * *",
+			Run:         h.importOnCallJob,
+		},
+		{
+			Name:        jobImportMilestones,
+			Description: "Import the project milestones of the current and next year as periods to avoid",
+			Schedule:    "30 4 * * *",
+			Run:         h.importMilestonesJob,
+		},
+		{
+			Name:        jobBackupDatabase,
+			Description: "Back up the database to the backup target and delete backups past the retention",
+			Schedule:    h.loadSettings(context.Background()).BackupSchedule,
+			Run:         h.backupJob,
+		},
+	}
+
+	for _, job := range jobs {
+		if err := h.scheduler.Add(job); err != nil {
+			log.Printf("Failed to schedule job: %v", err)
+		}
+	}
+}
+
+// refreshHolidaysJob refreshes the holidays of the current year and the
+// years covered by the startup pre-fetch
+func (h *Handler) refreshHolidaysJob(ctx context.Context) error {
+	s := h.loadSettings(ctx)
+	currentYear := dates.Today(dates.Location(s.Timezone)).Year()
+
+	var errs []error
+	for year := currentYear; year <= currentYear+s.HolidayPrefetchYears; year++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := h.holidayService.Refresh(year, s.WorkCity); err != nil {
+			errs = append(errs, fmt.Errorf("%d: %w", year, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pruneCachesJob drops expired entries from the in-memory caches and
+// expired share links and session tokens
+func (h *Handler) pruneCachesJob(ctx context.Context) error {
+	pruned := h.holidayService.PruneCache() + holidays.PruneCache() + h.locks.Prune() + h.guard.Prune(h.authLimits(ctx).Window) + h.logins.Prune()
+	if pruned > 0 {
+		log.Printf("Pruned %d expired cache entries", pruned)
+	}
+
+	shares, err := h.store.Shares.DeleteExpired(ctx)
+	if shares > 0 {
+		log.Printf("Deleted %d expired share links", shares)
+	}
+	if err != nil {
+		return err
+	}
+
+	sessions, err := h.store.Tokens.DeleteExpired(ctx)
+	if sessions > 0 {
+		log.Printf("Deleted %d expired session tokens", sessions)
+	}
+	if err != nil {
+		return err
+	}
+
+	audited, err := h.store.Audit.DeleteBefore(ctx, time.Now().Add(-auditRetention))
+	if audited > 0 {
+		log.Printf("Deleted %d audit entries older than %s", audited, auditRetention)
+	}
+	if err != nil {
+		return err
+	}
+
+	requests, err := h.store.Requests.DeleteBefore(ctx, time.Now().Add(-idempotencyRetention))
+	if requests > 0 {
+		log.Printf("Deleted %d stored idempotent responses", requests)
+	}
+	return err
+}
+
+// GetJobs lists the background jobs with their schedule and last run
+func (h *Handler) GetJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, h.scheduler.Jobs())
+}
+
+// RunJob starts a background job right away
+func (h *Handler) RunJob(c *gin.Context) {
+	err := h.scheduler.RunNow(c.Param("name"))
+	if errors.Is(err, scheduler.ErrUnknownJob) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Job not found")
+		return
+	}
+	if errors.Is(err, scheduler.ErrJobRunning) {
+		problem(c, http.StatusConflict, models.CodeConflict, err.Error())
+		return
+	}
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Job started"})
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	"github.com/bruno.lopes/calendar/backend/internal/events"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// liveClientBuffer is how many events a live updates client may fall behind
+// before it is disconnected
+const liveClientBuffer = 32
+
+// liveHub fans calendar events out to the clients connected to the live
+// updates WebSocket
+type liveHub struct {
+	mu      sync.Mutex
+	clients map[chan events.Event]struct{}
+}
+
+func newLiveHub() *liveHub {
+	return &liveHub{clients: make(map[chan events.Event]struct{})}
+}
+
+// publish hands an event to every client. It is subscribed to the events
+// bus, so a client too far behind is dropped rather than holding up the
+// publisher.
+func (l *liveHub) publish(event events.Event) {
+	if event.Type == events.Ping {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for client := range l.clients {
+		select {
+		case client <- event:
+		default:
+			delete(l.clients, client)
+			close(client)
+		}
+	}
+}
+
+// join registers a client and returns the channel its events arrive on
+func (l *liveHub) join() chan events.Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	client := make(chan events.Event, liveClientBuffer)
+	l.clients[client] = struct{}{}
+	return client
+}
+
+// leave unregisters a client, unless it was already dropped
+func (l *liveHub) leave(client chan events.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.clients[client]; ok {
+		delete(l.clients, client)
+		close(client)
+	}
+}
+
+// stop disconnects every client
+func (l *liveHub) stop() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for client := range l.clients {
+		delete(l.clients, client)
+		close(client)
+	}
+}
+
+// LiveUpdates upgrades the request to a WebSocket that streams calendar
+// events as JSON as they happen, whether from another tab, the chat or a
+// sync job, so open clients can reload what changed. ?year= narrows the
+// stream to one year's events, plus those of no year in particular.
+func (h *Handler) LiveUpdates(c *gin.Context) {
+	if !c.IsWebsocket() {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Expected a WebSocket upgrade")
+		return
+	}
+
+	year := 0
+	if value := c.Query("year"); value != "" {
+		var err error
+		if year, err = strconv.Atoi(value); err != nil {
+			problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+			return
+		}
+	}
+
+	server := websocket.Server{Handshake: sameOrigin, Handler: func(ws *websocket.Conn) {
+		updates := h.live.join()
+		defer h.live.leave(updates)
+
+		// Clients send nothing; reading only notices when they go away
+		closed := make(chan struct{})
+		go func() {
+			io.Copy(io.Discard, ws)
+			close(closed)
+		}()
+
+		for {
+			select {
+			case event, ok := <-updates:
+				if !ok {
+					return
+				}
+				if year != 0 && event.Year != 0 && event.Year != year {
+					continue
+				}
+				if err := websocket.JSON.Send(ws, event); err != nil {
+					return
+				}
+			case <-closed:
+				return
+			}
+		}
+	}}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// sameOrigin accepts WebSocket handshakes from pages served by this host, so
+// another site can't open the live updates in a visitor's browser. Clients
+// that send no Origin, such as scripts, are not browsers and are accepted.
+func sameOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host != req.Host {
+		return fmt.Errorf("origin %q is not allowed", origin)
+	}
+	config.Origin = u
+	return nil
+}
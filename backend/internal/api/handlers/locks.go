@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/events"
+	"github.com/bruno.lopes/calendar/backend/internal/locks"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// clientIDHeader identifies the browser session making a request
+const clientIDHeader = "X-Client-ID"
+
+// GetEditLock returns the current edit lock for a year
+func (h *Handler) GetEditLock(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	lock, held := h.locks.Get(year)
+	if !held {
+		c.JSON(http.StatusOK, gin.H{"locked": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"locked":    true,
+		"lock":      lock,
+		"is_holder": lock.ClientID == c.GetHeader(clientIDHeader),
+	})
+}
+
+// AcquireEditLock takes or renews the edit lock for a year.
+// Clients call this periodically as a heartbeat while the editor is open.
+func (h *Handler) AcquireEditLock(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	var input struct {
+		HolderName string `json:"holder_name"`
+	}
+	// Body is optional
+	c.ShouldBindJSON(&input)
+
+	clientID := c.GetHeader(clientIDHeader)
+	if clientID == "" {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Missing "+clientIDHeader+" header")
+		return
+	}
+
+	previous, held := h.locks.Get(year)
+	lock, ok := h.locks.Acquire(year, clientID, input.HolderName)
+	if !ok {
+		problem(c, http.StatusConflict, models.CodeEditLocked, "Calendar is being edited by someone else", gin.H{"lock": lock})
+		return
+	}
+	// Heartbeats renew the lock without telling anyone
+	if !held || previous.ClientID != clientID {
+		h.events.Publish(events.EditLockAcquired, year, lock)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"locked": true, "lock": lock, "is_holder": true})
+}
+
+// ReleaseEditLock releases the edit lock for a year
+func (h *Handler) ReleaseEditLock(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	clientID := c.GetHeader(clientIDHeader)
+	if !h.locks.Release(year, clientID) {
+		problem(c, http.StatusConflict, models.CodeConflict, "Lock is not held by this client")
+		return
+	}
+	h.events.Publish(events.EditLockReleased, year, gin.H{"client_id": clientID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lock released"})
+}
+
+// RequireEditLock rejects mutations while another client holds the year's lock,
+// so simultaneous planning sessions can't silently overwrite each other.
+func (h *Handler) RequireEditLock(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		// Let the handler report the invalid year
+		c.Next()
+		return
+	}
+
+	if lock, ok := h.locks.CanEdit(year, c.GetHeader(clientIDHeader)); !ok {
+		abortProblem(c, http.StatusConflict, models.CodeEditLocked, "Calendar is being edited by someone else", gin.H{"lock": lock})
+		return
+	}
+
+	c.Next()
+}
+
+// CanEdit reports whether a client may change a year, returning the lock held
+// by another client when it may not
+func (h *Handler) CanEdit(year int, clientID string) (locks.Lock, bool) {
+	return h.locks.CanEdit(year, clientID)
+}
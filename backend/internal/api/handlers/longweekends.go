@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// minLongWeekend is the fewest days off in a row that make a long weekend
+const minLongWeekend = 3
+
+// GetLongWeekends lists the stretches of days off around holidays that take
+// no vacation day or one, cheapest first
+func (h *Handler) GetLongWeekends(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	calendar, err := h.Calendar(c.Request.Context(), year)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, longWeekends(calendar.Days))
+}
+
+// longWeekends finds in a year's calendar the runs of days off holding a
+// holiday that are already long weekends, and the work days that make one
+// when taken off. Runs without a holiday are left out, or every weekend
+// would be one. Sorted by vacation days, then longest and earliest first.
+func longWeekends(days []models.CalendarDay) []models.LongWeekend {
+	off := make([]bool, len(days))
+	for i, day := range days {
+		off[i] = dayOffKind(day) != ""
+	}
+
+	// stretch describes the days first..last, or returns false when they
+	// are too few or hold no holiday
+	stretch := func(first, last int) (models.LongWeekend, bool) {
+		weekend := models.LongWeekend{
+			StartDate: days[first].Date,
+			EndDate:   days[last].Date,
+			TotalDays: last - first + 1,
+			Holidays:  []string{},
+		}
+		for i := first; i <= last; i++ {
+			if days[i].IsHoliday {
+				weekend.Holidays = append(weekend.Holidays, days[i].HolidayName)
+			}
+		}
+		return weekend, weekend.TotalDays >= minLongWeekend && len(weekend.Holidays) > 0
+	}
+
+	weekends := []models.LongWeekend{}
+	for i := 0; i < len(days); i++ {
+		if off[i] {
+			// A run of days off, taken as it is
+			last := i
+			for last+1 < len(days) && off[last+1] {
+				last++
+			}
+			if weekend, ok := stretch(i, last); ok {
+				weekends = append(weekends, weekend)
+			}
+			i = last
+			continue
+		}
+
+		// A work day joining the days off on either side
+		first, last := i, i
+		for first > 0 && off[first-1] {
+			first--
+		}
+		for last+1 < len(days) && off[last+1] {
+			last++
+		}
+		if weekend, ok := stretch(first, last); ok {
+			weekend.VacationDays = 1
+			weekend.VacationDate = days[i].Date
+			weekends = append(weekends, weekend)
+		}
+	}
+
+	sort.SliceStable(weekends, func(i, j int) bool {
+		if weekends[i].VacationDays != weekends[j].VacationDays {
+			return weekends[i].VacationDays < weekends[j].VacationDays
+		}
+		if weekends[i].TotalDays != weekends[j].TotalDays {
+			return weekends[i].TotalDays > weekends[j].TotalDays
+		}
+		return weekends[i].StartDate < weekends[j].StartDate
+	})
+	return weekends
+}
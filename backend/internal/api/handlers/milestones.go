@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/milestones"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// ImportMilestones imports the project milestones of a year as avoid periods
+// now, instead of waiting for the nightly job
+func (h *Handler) ImportMilestones(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	result, err := h.importMilestones(c.Request.Context(), year)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// importMilestones replaces the milestone avoid periods of a year with the
+// weeks of the project's milestones, which the optimizer then penalizes.
+// Avoid periods entered by hand are kept.
+func (h *Handler) importMilestones(ctx context.Context, year int) (models.MilestoneImport, error) {
+	if err := checkYear(year); err != nil {
+		return models.MilestoneImport{}, err
+	}
+
+	s := h.loadSettings(ctx)
+	reader, err := milestones.New(milestones.Config{
+		Provider: s.MilestoneProvider,
+		APIKey:   s.MilestoneAPIKey,
+		User:     s.MilestoneUser,
+		Project:  s.MilestoneProject,
+		URL:      s.MilestoneURL,
+		Location: dates.Location(s.Timezone),
+	})
+	if err != nil {
+		return models.MilestoneImport{}, invalidInputCode(models.CodeNotConfigured, err)
+	}
+
+	config, err := h.getOrCreateYearConfig(ctx, year)
+	if err != nil {
+		return models.MilestoneImport{}, err
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	list, err := reader.Milestones(ctx, from, to)
+	if err != nil {
+		return models.MilestoneImport{}, upstreamFailure(err)
+	}
+
+	periods := milestonePeriods(list, year)
+	avoid := []models.AvoidPeriod{}
+	for _, p := range config.AvoidPeriods {
+		if p.Source != models.AvoidMilestone {
+			avoid = append(avoid, p)
+		}
+	}
+	avoid = append(avoid, periods...)
+	sort.SliceStable(avoid, func(i, j int) bool { return avoid[i].StartDate < avoid[j].StartDate })
+
+	if !slices.Equal(avoid, config.AvoidPeriods) {
+		config.AvoidPeriods = avoid
+		if err := h.store.Configs.Update(ctx, config); err != nil {
+			return models.MilestoneImport{}, err
+		}
+	}
+
+	return models.MilestoneImport{Year: year, Provider: s.MilestoneProvider, Periods: periods}, nil
+}
+
+// milestonePeriods returns the weeks of the milestones within a year as avoid
+// periods: Monday to Friday of the week a milestone falls in, or of the weeks
+// it spans. Milestones in overlapping weeks share a period.
+func milestonePeriods(list []milestones.Milestone, year int) []models.AvoidPeriod {
+	first, last := fmt.Sprintf("%d-01-01", year), fmt.Sprintf("%d-12-31", year)
+
+	var weeks []models.AvoidPeriod
+	for _, m := range list {
+		start, err1 := dates.Parse(m.StartDate)
+		end, err2 := dates.Parse(m.EndDate)
+		if err1 != nil || err2 != nil || end.Before(start) {
+			continue
+		}
+		start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+		friday := end.AddDate(0, 0, -(int(end.Weekday())+6)%7+4)
+		if friday.After(end) {
+			end = friday
+		}
+
+		period := models.AvoidPeriod{
+			StartDate: max(dates.Format(start), first),
+			EndDate:   min(dates.Format(end), last),
+			Reason:    m.Name,
+			Weight:    models.DefaultAvoidWeight,
+			Source:    models.AvoidMilestone,
+		}
+		if period.Reason == "" {
+			period.Reason = "Milestone"
+		}
+		if period.StartDate <= period.EndDate {
+			weeks = append(weeks, period)
+		}
+	}
+
+	sort.SliceStable(weeks, func(i, j int) bool { return weeks[i].StartDate < weeks[j].StartDate })
+	periods := []models.AvoidPeriod{}
+	for _, week := range weeks {
+		if n := len(periods); n > 0 && week.StartDate <= periods[n-1].EndDate {
+			prev := &periods[n-1]
+			prev.EndDate = max(prev.EndDate, week.EndDate)
+			if !strings.Contains(prev.Reason, week.Reason) {
+				prev.Reason += ", " + week.Reason
+			}
+			continue
+		}
+		periods = append(periods, week)
+	}
+	return periods
+}
+
+// importMilestonesJob imports the milestones of the current and next year,
+// when a milestone provider is configured
+func (h *Handler) importMilestonesJob(ctx context.Context) error {
+	s := h.loadSettings(ctx)
+	if s.MilestoneProvider == "" || s.MilestoneProvider == milestones.ProviderNone {
+		return nil
+	}
+
+	currentYear := dates.Today(dates.Location(s.Timezone)).Year()
+	var errs []error
+	for year := currentYear; year <= currentYear+1; year++ {
+		if _, err := h.importMilestones(ctx, year); err != nil {
+			errs = append(errs, fmt.Errorf("%d: %w", year, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/events"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// MoveVacations reschedules vacation days, given as moves or as dates
+// shifted by a number of days ("one week later" is {"dates": [...],
+// "shift_days": 7})
+func (h *Handler) MoveVacations(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	var input struct {
+		Moves     []models.VacationMove `json:"moves"`
+		Dates     []string              `json:"dates"`
+		ShiftDays int                   `json:"shift_days"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	moves := input.Moves
+	if len(input.Dates) > 0 {
+		if len(moves) > 0 || input.ShiftDays == 0 {
+			problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Send either moves, or dates with a non-zero shift_days")
+			return
+		}
+		for _, date := range input.Dates {
+			d, err := dates.Parse(date)
+			if err != nil {
+				problem(c, http.StatusBadRequest, models.CodeInvalidDate, fmt.Sprintf("Invalid date %q, expected YYYY-MM-DD", date))
+				return
+			}
+			moves = append(moves, models.VacationMove{From: date, To: dates.Format(d.AddDate(0, 0, input.ShiftDays))})
+		}
+	}
+
+	moved, err := h.MoveVacationDays(c.Request.Context(), year, moves)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"moved": moved})
+}
+
+// MoveVacationDays moves vacation days to other dates in one transaction,
+// all or none. Each day moves to a free work day of the year that is not a
+// holiday or in a blackout period, and neither date may be locked or past.
+// A moved day becomes a manual one and keeps its note. The number of days
+// booked doesn't change; in hours mode, moves to longer days must fit in the
+// hours left.
+func (h *Handler) MoveVacationDays(ctx context.Context, year int, moves []models.VacationMove) ([]models.VacationMove, error) {
+	if err := checkYear(year); err != nil {
+		return nil, err
+	}
+	if len(moves) == 0 {
+		return nil, invalidInput(errors.New("no vacation days to move"))
+	}
+
+	config, err := h.getOrCreateYearConfig(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+	manualVacations, err := h.store.Vacations.List(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+	optimalVacations, err := h.store.Vacations.ListOptimal(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+
+	notes := make(map[string]string)
+	booked := make(map[string]bool)
+	for _, v := range manualVacations {
+		notes[v.Date] = v.Note
+		booked[v.Date] = true
+	}
+	for _, v := range optimalVacations {
+		booked[v.Date] = true
+	}
+	holidaySet := make(map[string]bool)
+	for _, holiday := range h.holidaysForYear(ctx, year) {
+		holidaySet[holiday.Date] = true
+	}
+
+	from := make(map[string]bool)
+	to := make(map[string]bool)
+	var changed []string
+	var extraHours float64
+	for _, move := range moves {
+		if err := checkDateInYear(move.From, year); err != nil {
+			return nil, err
+		}
+		if err := checkDateInYear(move.To, year); err != nil {
+			return nil, err
+		}
+		if !booked[move.From] {
+			return nil, fmt.Errorf("%s is not a vacation day: %w", move.From, store.ErrNotFound)
+		}
+		if from[move.From] || to[move.To] {
+			return nil, invalidInput(fmt.Errorf("%s or %s is in more than one move", move.From, move.To))
+		}
+		from[move.From], to[move.To] = true, true
+		changed = append(changed, move.From, move.To)
+
+		target, _ := dates.Parse(move.To)
+		switch {
+		case holidaySet[move.To]:
+			return nil, invalidInputCode(models.CodeHolidayConflict, fmt.Errorf("cannot move %s to %s, a holiday", move.From, move.To))
+		case !config.IsWorkDay(target):
+			return nil, invalidInput(fmt.Errorf("cannot move %s to %s, not a work day", move.From, move.To))
+		case config.InBlackout(move.To):
+			return nil, invalidInput(fmt.Errorf("cannot move %s to %s, in a blackout period", move.From, move.To))
+		}
+
+		source, _ := dates.Parse(move.From)
+		extraHours += config.HoursOn(target) - config.HoursOn(source)
+	}
+	// A day may move onto one that moves away itself
+	for date := range to {
+		if booked[date] && !from[date] {
+			return nil, invalidInput(fmt.Errorf("%s is already a vacation day", date))
+		}
+	}
+	if err := h.checkEditable(ctx, year, changed...); err != nil {
+		return nil, err
+	}
+
+	if config.HoursMode() && extraHours > 0 {
+		calendar, err := h.Calendar(ctx, year)
+		if err != nil {
+			return nil, err
+		}
+		if extraHours > calendar.Summary.RemainingVacationHours {
+			return nil, invalidInputCode(models.CodeBudgetExceeded, fmt.Errorf("the moves need %.1f more hours, %.1f left", extraHours, calendar.Summary.RemainingVacationHours))
+		}
+	}
+
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		for _, move := range moves {
+			if _, err := tx.Vacations.Remove(ctx, year, move.From); err != nil {
+				return err
+			}
+			if err := tx.Vacations.RemoveOptimal(ctx, year, move.From); err != nil {
+				return err
+			}
+		}
+		for _, move := range moves {
+			if err := tx.Vacations.Add(ctx, year, move.To, notes[move.From]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var removed, added []string
+	for _, move := range moves {
+		removed = append(removed, move.From)
+		added = append(added, move.To)
+	}
+	h.events.Publish(events.VacationRemoved, year, gin.H{"dates": removed, "source": "move"})
+	h.events.Publish(events.VacationAdded, year, gin.H{"dates": added, "source": "move"})
+	return moves, nil
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// GetNextBreak returns the next day off and the next vacation block from
+// the user's current day
+func (h *Handler) GetNextBreak(c *gin.Context) {
+	next, err := h.NextBreak(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, next)
+}
+
+// NextBreak finds the next day off (holiday, vacation, comp day or a day
+// outside the work week) and the next vacation block, looking into next
+// year when the current one has none left. Today counts when it is off.
+func (h *Handler) NextBreak(ctx context.Context) (models.NextBreak, error) {
+	today := h.today(ctx)
+	todayStr := dates.Format(today)
+	next := models.NextBreak{Today: todayStr}
+
+	for year := today.Year(); year <= today.Year()+1; year++ {
+		calendar, err := h.Calendar(ctx, year)
+		if err != nil {
+			return next, err
+		}
+
+		if next.NextDayOff == nil {
+			for _, day := range calendar.Days {
+				if day.Date < todayStr {
+					continue
+				}
+				if kind := dayOffKind(day); kind != "" {
+					date, _ := dates.Parse(day.Date)
+					next.NextDayOff = &models.DayOff{Date: day.Date, Kind: kind, DaysUntil: daysBetween(today, date)}
+					if kind == models.DayOffHoliday {
+						next.NextDayOff.Name = day.HolidayName
+					}
+					break
+				}
+			}
+		}
+
+		if next.NextVacation == nil {
+			for _, block := range calendar.VacationBlocks {
+				if block.EndDate < todayStr {
+					continue
+				}
+				block := block
+				next.NextVacation = &block
+				if block.StartDate > todayStr {
+					start, _ := dates.Parse(block.StartDate)
+					next.DaysUntilVacation = daysBetween(today, start)
+				}
+				break
+			}
+		}
+
+		if next.NextDayOff != nil && next.NextVacation != nil {
+			break
+		}
+	}
+
+	return next, nil
+}
+
+// dayOffKind returns why a calendar day is off, or "" for a work day
+func dayOffKind(day models.CalendarDay) string {
+	switch {
+	case day.IsHoliday:
+		return models.DayOffHoliday
+	case day.IsVacation:
+		return models.DayOffVacation
+	case day.IsCompDay:
+		return models.DayOffCompDay
+	case day.IsWeekend:
+		return models.DayOffWeekend
+	}
+	return ""
+}
+
+// daysBetween returns the whole days from one civil date to another
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
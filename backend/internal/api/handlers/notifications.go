@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/notifications"
+)
+
+// TestNotifications sends a test message to all configured notification channels
+func (h *Handler) TestNotifications(c *gin.Context) {
+	if err := h.notifier.SendTest(); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Test notification sent"})
+}
+
+// GetPushPublicKey returns the VAPID public key used to subscribe to push notifications
+func (h *Handler) GetPushPublicKey(c *gin.Context) {
+	publicKey, err := h.notifier.VAPIDPublicKey()
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"public_key": publicKey})
+}
+
+// SubscribePush stores a browser push subscription
+func (h *Handler) SubscribePush(c *gin.Context) {
+	var input notifications.PushSubscription
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	if err := h.notifier.Subscribe(input); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Subscribed to push notifications"})
+}
+
+// UnsubscribePush removes a browser push subscription
+func (h *Handler) UnsubscribePush(c *gin.Context) {
+	var input struct {
+		Endpoint string `json:"endpoint" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	if err := h.notifier.Unsubscribe(input.Endpoint); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Unsubscribed from push notifications"})
+}
+
+// SendDigest sends a digest right away: the weekly "your next days off"
+// digest, or the monthly digest with ?type=monthly
+func (h *Handler) SendDigest(c *gin.Context) {
+	var err error
+	if c.Query("type") == "monthly" {
+		err = h.notifier.SendMonthlyDigest(h.notifier.Now())
+	} else {
+		err = h.notifier.SendWeeklyDigest(h.notifier.Now())
+	}
+
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Digest sent"})
+}
+
+// PreviewMonthlyDigest renders the monthly digest (?month=YYYY-MM, default
+// the current month) as HTML, or as plain text with ?format=text
+func (h *Handler) PreviewMonthlyDigest(c *gin.Context) {
+	month := h.notifier.Now()
+	if m := c.Query("month"); m != "" {
+		parsed, err := time.Parse("2006-01", m)
+		if err != nil {
+			problem(c, http.StatusBadRequest, models.CodeInvalidDate, "Invalid month, expected YYYY-MM")
+			return
+		}
+		month = parsed
+	}
+
+	digest, err := h.notifier.BuildMonthlyDigest(month)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+	html, text, err := notifications.RenderMonthlyDigest(digest)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	if c.Query("format") == "text" {
+		c.String(http.StatusOK, text)
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
+}
+
+// GetNotificationPreferences returns the notification settings of the
+// caller's access token, or of the owner while the API is open
+func (h *Handler) GetNotificationPreferences(c *gin.Context) {
+	prefs, err := h.notifier.Preferences(preferencesTokenID(c))
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// UpdateNotificationPreferences replaces the caller's notification channels,
+// kinds, email address and quiet hours
+func (h *Handler) UpdateNotificationPreferences(c *gin.Context) {
+	var prefs notifications.Preferences
+	if err := c.ShouldBindJSON(&prefs); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+	prefs.TokenID = preferencesTokenID(c)
+
+	if err := prefs.Validate(); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+	if err := h.notifier.SetPreferences(prefs); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	saved, err := h.notifier.Preferences(prefs.TokenID)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, saved)
+}
+
+// preferencesTokenID returns whose notification preferences a request is
+// about: its access token, or 0 for the owner while access control is off
+func preferencesTokenID(c *gin.Context) int64 {
+	if token, ok := requestToken(c); ok {
+		return token.ID
+	}
+	return 0
+}
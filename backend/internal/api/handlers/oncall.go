@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/oncall"
+)
+
+// ImportOnCall imports the on-call shifts of a year as blackout periods now,
+// instead of waiting for the nightly job
+func (h *Handler) ImportOnCall(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	result, err := h.importOnCall(c.Request.Context(), year)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// importOnCall replaces the on-call blackout periods of a year with the
+// shifts in the on-call schedule, so neither the optimizer nor the chat puts
+// vacation in them. Blackout periods entered by hand are kept, and vacation
+// days already booked in a shift are reported, not removed.
+func (h *Handler) importOnCall(ctx context.Context, year int) (models.OnCallImport, error) {
+	if err := checkYear(year); err != nil {
+		return models.OnCallImport{}, err
+	}
+
+	s := h.loadSettings(ctx)
+	reader, err := oncall.New(oncall.Config{
+		Provider: s.OnCallProvider,
+		APIKey:   s.OnCallAPIKey,
+		User:     s.OnCallUser,
+		Schedule: s.OnCallScheduleID,
+		URL:      s.OnCallICSURL,
+		Location: dates.Location(s.Timezone),
+	})
+	if err != nil {
+		return models.OnCallImport{}, invalidInputCode(models.CodeNotConfigured, err)
+	}
+
+	config, err := h.getOrCreateYearConfig(ctx, year)
+	if err != nil {
+		return models.OnCallImport{}, err
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	shifts, err := reader.Shifts(ctx, from, to)
+	if err != nil {
+		return models.OnCallImport{}, upstreamFailure(err)
+	}
+
+	periods := onCallPeriods(shifts, year, "On call ("+oncall.ProviderName(s.OnCallProvider)+")")
+	blackout := []models.BlackoutPeriod{}
+	for _, p := range config.BlackoutPeriods {
+		if p.Source != models.BlackoutOnCall {
+			blackout = append(blackout, p)
+		}
+	}
+	blackout = append(blackout, periods...)
+	sort.SliceStable(blackout, func(i, j int) bool { return blackout[i].StartDate < blackout[j].StartDate })
+
+	if !slices.Equal(blackout, config.BlackoutPeriods) {
+		config.BlackoutPeriods = blackout
+		if err := h.store.Configs.Update(ctx, config); err != nil {
+			return models.OnCallImport{}, err
+		}
+	}
+
+	result := models.OnCallImport{Year: year, Provider: s.OnCallProvider, Periods: periods, Conflicts: []string{}}
+	onCall := models.YearConfig{BlackoutPeriods: periods}
+	manualVacations, _ := h.store.Vacations.List(ctx, year)
+	optimalVacations, _ := h.store.Vacations.ListOptimal(ctx, year)
+	for _, v := range manualVacations {
+		if onCall.InBlackout(v.Date) {
+			result.Conflicts = append(result.Conflicts, v.Date)
+		}
+	}
+	for _, v := range optimalVacations {
+		if onCall.InBlackout(v.Date) {
+			result.Conflicts = append(result.Conflicts, v.Date)
+		}
+	}
+	sort.Strings(result.Conflicts)
+
+	return result, nil
+}
+
+// onCallPeriods returns the shifts within a year as blackout periods, with
+// overlapping and back-to-back shifts joined
+func onCallPeriods(shifts []oncall.Shift, year int, reason string) []models.BlackoutPeriod {
+	first, last := fmt.Sprintf("%d-01-01", year), fmt.Sprintf("%d-12-31", year)
+
+	sort.Slice(shifts, func(i, j int) bool { return shifts[i].StartDate < shifts[j].StartDate })
+	periods := []models.BlackoutPeriod{}
+	for _, shift := range shifts {
+		start, end := max(shift.StartDate, first), min(shift.EndDate, last)
+		if start > end {
+			continue
+		}
+		if n := len(periods); n > 0 {
+			prev := &periods[n-1]
+			prevEnd, err := dates.Parse(prev.EndDate)
+			if err == nil && start <= dates.Format(prevEnd.AddDate(0, 0, 1)) {
+				prev.EndDate = max(prev.EndDate, end)
+				continue
+			}
+		}
+		periods = append(periods, models.BlackoutPeriod{StartDate: start, EndDate: end, Reason: reason, Source: models.BlackoutOnCall})
+	}
+	return periods
+}
+
+// importOnCallJob imports the on-call shifts of the current and next year,
+// when an on-call provider is configured
+func (h *Handler) importOnCallJob(ctx context.Context) error {
+	s := h.loadSettings(ctx)
+	if s.OnCallProvider == "" || s.OnCallProvider == oncall.ProviderNone {
+		return nil
+	}
+
+	currentYear := dates.Today(dates.Location(s.Timezone)).Year()
+	var errs []error
+	for year := currentYear; year <= currentYear+1; year++ {
+		if _, err := h.importOnCall(ctx, year); err != nil {
+			errs = append(errs, fmt.Errorf("%d: %w", year, err))
+		}
+	}
+	return errors.Join(errs...)
+}
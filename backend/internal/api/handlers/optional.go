@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// GetOptionalHolidays lists the company optional holidays of a year and
+// which ones the year grants
+func (h *Handler) GetOptionalHolidays(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	config, err := h.getOrCreateYearConfig(c.Request.Context(), year)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, optionalHolidays(year, config))
+}
+
+// SetOptionalHoliday enables or disables a company optional holiday for a
+// year. Enabled ones are days off in the calendar and the optimizer.
+func (h *Handler) SetOptionalHoliday(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	key := c.Param("key")
+	if !holidays.IsOptionalHoliday(key) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Optional holiday not found")
+		return
+	}
+
+	var input struct {
+		Enabled *bool `json:"enabled" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	config, err := h.getOrCreateYearConfig(ctx, year)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	enabled := []string{}
+	for _, k := range config.OptionalHolidays {
+		if k != key {
+			enabled = append(enabled, k)
+		}
+	}
+	if *input.Enabled {
+		enabled = append(enabled, key)
+	}
+	config.OptionalHolidays = enabled
+
+	if err := h.store.Configs.Update(ctx, config); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, optionalHolidays(year, config))
+}
+
+// optionalHolidays returns the company optional holidays of a year with the
+// ones enabled in its config
+func optionalHolidays(year int, config models.YearConfig) []models.OptionalHoliday {
+	var result []models.OptionalHoliday
+	for _, opt := range holidays.GetOptionalHolidays(year) {
+		result = append(result, models.OptionalHoliday{
+			Key:     opt.Key,
+			Date:    opt.Date,
+			Name:    opt.Name,
+			Enabled: contains(config.OptionalHolidays, opt.Key),
+		})
+	}
+	return result
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/jsonschema"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// maxHandoverNotes bounds the notes on ongoing work sent to the AI
+const maxHandoverNotes = 4000
+
+// DraftOutOfOffice asks the AI for an out-of-office auto-reply and a handover
+// summary for one vacation block, named like GetBlockICS by its position or
+// first day. The draft is returned to be pasted into a mail client.
+func (h *Handler) DraftOutOfOffice(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	var input struct {
+		Language string `json:"language"` // "en" (default) or "pt-PT"
+		Name     string `json:"name"`     // Who is away, to sign the reply
+		Contact  string `json:"contact"`  // Who to reach meanwhile, such as "Ana Silva (ana@example.com)"
+		Notes    string `json:"notes"`    // Ongoing work for the handover
+	}
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+	if input.Language == "" {
+		input.Language = "en"
+	}
+	if input.Language != "en" && input.Language != "pt-PT" {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "language must be en or pt-PT")
+		return
+	}
+	if len(input.Notes) > maxHandoverNotes {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, fmt.Sprintf("notes must be at most %d characters", maxHandoverNotes))
+		return
+	}
+
+	ctx := c.Request.Context()
+	settings := h.getAISettings(ctx)
+	if !settings.Configured() {
+		problem(c, http.StatusBadRequest, models.CodeAIUnconfigured, "API key not configured")
+		return
+	}
+
+	block, err := h.findBlock(ctx, year, c.Param("block"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Blocks run to the last day off, so work resumes the day after
+	end, err := dates.Parse(block.EndDate)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	draft := models.OutOfOfficeDraft{
+		Year:       year,
+		StartDate:  block.StartDate,
+		EndDate:    block.EndDate,
+		ReturnDate: dates.Format(end.AddDate(0, 0, 1)),
+		Language:   input.Language,
+	}
+
+	languageInstruction := "Write in English."
+	if input.Language == "pt-PT" {
+		languageInstruction = "Write in Portuguese (Portugal). Use European Portuguese, not Brazilian Portuguese."
+	}
+
+	var details strings.Builder
+	fmt.Fprintf(&details, "- Away from %s to %s, back at work on %s\n", draft.StartDate, draft.EndDate, draft.ReturnDate)
+	if block.Label != "" {
+		fmt.Fprintf(&details, "- Occasion: %s\n", block.Label)
+	}
+	if input.Name != "" {
+		fmt.Fprintf(&details, "- Name: %s\n", input.Name)
+	}
+	if input.Contact != "" {
+		fmt.Fprintf(&details, "- Contact while away: %s\n", input.Contact)
+	}
+	if input.Notes != "" {
+		fmt.Fprintf(&details, "- Ongoing work to hand over:\n%s\n", input.Notes)
+	}
+
+	prompt := fmt.Sprintf(`Draft an out-of-office auto-reply and a handover summary for a vacation. %s
+
+%s
+Return a JSON object with:
+- "subject": the subject line of the auto-reply
+- "auto_reply": a short, polite auto-reply with the dates away, the return date and who to contact meanwhile. Leave out the occasion and any private detail
+- "handover": a handover summary for colleagues, listing the ongoing work, what needs attention while away and who covers it
+
+Write the dates in a natural way for the language. Do not invent work, names or contacts that are not given.`, languageInstruction, details.String())
+
+	request := openai.ChatCompletionRequest{
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, Content: prompt},
+		},
+		Temperature:    0.5,
+		ResponseFormat: outOfOfficeFormat,
+	}
+	// Models without structured outputs reject the response format
+	resp, model, err := h.completeChat(ctx, settings, request)
+	if err != nil && providerStatus(err) == http.StatusBadRequest {
+		request.ResponseFormat = nil
+		resp, model, err = h.completeChat(ctx, settings, request)
+	}
+	if err != nil {
+		problem(c, http.StatusBadGateway, models.CodeUpstreamError, "AI request failed: "+err.Error())
+		return
+	}
+
+	if err := parseOutOfOffice(resp.Choices[0].Message.Content, &draft); err != nil {
+		problem(c, http.StatusBadGateway, models.CodeUpstreamError, err.Error())
+		return
+	}
+	draft.Model = model
+
+	c.JSON(http.StatusOK, draft)
+}
+
+// outOfOfficeFormat asks for the draft as a JSON object matching a strict
+// schema, {"subject": ..., "auto_reply": ..., "handover": ...}
+var outOfOfficeFormat = &openai.ChatCompletionResponseFormat{
+	Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+	JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+		Name:        "out_of_office",
+		Description: "Out-of-office auto-reply and handover summary",
+		Strict:      true,
+		Schema: &jsonschema.Definition{
+			Type:                 jsonschema.Object,
+			AdditionalProperties: false,
+			Required:             []string{"subject", "auto_reply", "handover"},
+			Properties: map[string]jsonschema.Definition{
+				"subject":    {Type: jsonschema.String, Description: "Subject line of the auto-reply"},
+				"auto_reply": {Type: jsonschema.String, Description: "Body of the auto-reply"},
+				"handover":   {Type: jsonschema.String, Description: "Handover summary for colleagues"},
+			},
+		},
+	},
+}
+
+// parseOutOfOffice reads the AI's JSON draft into the subject, auto-reply
+// and handover of a draft, also when the model wrapped it in a code fence
+func parseOutOfOffice(text string, draft *models.OutOfOfficeDraft) error {
+	text = strings.TrimSpace(text)
+	if start, end := strings.Index(text, "{"), strings.LastIndex(text, "}"); start >= 0 && end > start {
+		text = text[start : end+1]
+	}
+
+	var answer struct {
+		Subject   string `json:"subject"`
+		AutoReply string `json:"auto_reply"`
+		Handover  string `json:"handover"`
+	}
+	if err := json.Unmarshal([]byte(text), &answer); err != nil || answer.AutoReply == "" {
+		return fmt.Errorf("the AI answer is not the out-of-office draft")
+	}
+
+	draft.Subject = answer.Subject
+	draft.AutoReply = answer.AutoReply
+	draft.Handover = answer.Handover
+	return nil
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// GetHolidayOverrides returns the holiday overrides of a year
+func (h *Handler) GetHolidayOverrides(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	overrides, err := h.store.Holidays.Overrides(c.Request.Context(), year)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, overrides)
+}
+
+// SetHolidayOverride corrects the holiday on a date, moving it to new_date
+// or renaming it, or suppresses it when the employer doesn't observe it
+func (h *Handler) SetHolidayOverride(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	var input struct {
+		Action  string `json:"action" binding:"required"`
+		NewDate string `json:"new_date"`
+		Name    string `json:"name"`
+		Note    string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	override := models.HolidayOverride{
+		Year:    year,
+		Date:    c.Param("date"),
+		Action:  input.Action,
+		NewDate: input.NewDate,
+		Name:    input.Name,
+		Note:    input.Note,
+	}
+	if err := h.checkHolidayOverride(ctx, override); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := h.store.Holidays.SetOverride(ctx, override); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Holiday overridden"})
+}
+
+// RemoveHolidayOverride restores a holiday as the APIs return it
+func (h *Handler) RemoveHolidayOverride(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	ctx := c.Request.Context()
+	removed, err := h.store.Holidays.RemoveOverride(ctx, year, c.Param("date"))
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+	if !removed {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Holiday override not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Holiday restored"})
+}
+
+// checkHolidayOverride returns an error unless an override is for a holiday
+// of its year the APIs return, and a correction gives a new date in the year
+// or a new name
+func (h *Handler) checkHolidayOverride(ctx context.Context, o models.HolidayOverride) error {
+	if err := checkDateInYear(o.Date, o.Year); err != nil {
+		return err
+	}
+	switch o.Action {
+	case holidays.OverrideSuppress:
+		if o.NewDate != "" || o.Name != "" {
+			return invalidInput(errors.New("a suppressed holiday takes no new_date or name"))
+		}
+	case holidays.OverrideCorrect:
+		if o.NewDate == "" && o.Name == "" {
+			return invalidInput(errors.New("a correction needs a new_date or a name"))
+		}
+		if o.NewDate != "" {
+			if err := checkDateInYear(o.NewDate, o.Year); err != nil {
+				return err
+			}
+		}
+	default:
+		return invalidInput(fmt.Errorf("unknown action %q, must be %s or %s", o.Action, holidays.OverrideCorrect, holidays.OverrideSuppress))
+	}
+
+	for _, hol := range h.holidayService.Holidays(o.Year, h.getWorkCity(ctx)) {
+		if hol.Date == o.Date {
+			return nil
+		}
+	}
+	return invalidInputCode(models.CodeInvalidDate, fmt.Errorf("%s is not a holiday", o.Date))
+}
+
+// applyHolidayOverrides corrects and suppresses the holidays of a year as
+// its overrides say
+func (h *Handler) applyHolidayOverrides(ctx context.Context, year int, holidayList []holidays.PortugueseHoliday) []holidays.PortugueseHoliday {
+	stored, err := h.store.Holidays.Overrides(ctx, year)
+	if err != nil {
+		log.Printf("Failed to load the holiday overrides of %d: %v", year, err)
+		return holidayList
+	}
+	overrides := make([]holidays.Override, 0, len(stored))
+	for _, o := range stored {
+		overrides = append(overrides, holidays.Override{Date: o.Date, Action: o.Action, NewDate: o.NewDate, Name: o.Name})
+	}
+	return holidays.ApplyOverrides(holidayList, overrides)
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// personalEventInput is the body of personal event create and update requests
+type personalEventInput struct {
+	Date   string `json:"date" binding:"required"`
+	Name   string `json:"name" binding:"required"`
+	Notes  string `json:"notes"`
+	Weight int    `json:"weight"`
+}
+
+// event validates the input and returns it as a personal event of a year
+func (in personalEventInput) event(year int) (models.PersonalEvent, error) {
+	if err := checkYear(year); err != nil {
+		return models.PersonalEvent{}, err
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return models.PersonalEvent{}, invalidInput(errors.New("name is required"))
+	}
+	if err := checkDateInYear(in.Date, year); err != nil {
+		return models.PersonalEvent{}, err
+	}
+	if in.Weight < -models.MaxEventWeight || in.Weight > models.MaxEventWeight {
+		return models.PersonalEvent{}, invalidInput(fmt.Errorf("weight must be between %d and %d", -models.MaxEventWeight, models.MaxEventWeight))
+	}
+
+	return models.PersonalEvent{
+		Year:   year,
+		Date:   in.Date,
+		Name:   name,
+		Notes:  in.Notes,
+		Weight: in.Weight,
+	}, nil
+}
+
+// GetPersonalEvents returns the personal events of a year
+func (h *Handler) GetPersonalEvents(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	events, err := h.store.Events.List(c.Request.Context(), year)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// CreatePersonalEvent adds an important date to a year
+func (h *Handler) CreatePersonalEvent(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	var input personalEventInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	event, err := input.event(year)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	event, err = h.store.Events.Create(c.Request.Context(), event)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+// UpdatePersonalEvent replaces the date, details and weight of a personal
+// event
+func (h *Handler) UpdatePersonalEvent(c *gin.Context) {
+	year, id, ok := personalEventParams(c)
+	if !ok {
+		return
+	}
+
+	var input personalEventInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	event, err := input.event(year)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	event.ID = id
+
+	event, err = h.store.Events.Update(c.Request.Context(), event)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Event not found")
+		return
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+// DeletePersonalEvent removes a personal event
+func (h *Handler) DeletePersonalEvent(c *gin.Context) {
+	year, id, ok := personalEventParams(c)
+	if !ok {
+		return
+	}
+
+	err := h.store.Events.Delete(c.Request.Context(), year, id)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Event not found")
+		return
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Event deleted"})
+}
+
+func personalEventParams(c *gin.Context) (int, int64, bool) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid event id")
+		return 0, 0, false
+	}
+
+	return year, id, true
+}
+
+// markEventDays lists on each calendar day the personal events on it
+func markEventDays(days []models.CalendarDay, events []models.PersonalEvent) {
+	for i := range days {
+		for _, event := range events {
+			if event.Date == days[i].Date {
+				days[i].Events = append(days[i].Events, event.Name)
+			}
+		}
+	}
+}
+
+// eventWeights sums the weights of the personal events by date, for the
+// optimizer
+func eventWeights(events []models.PersonalEvent) map[string]float64 {
+	weights := make(map[string]float64)
+	for _, event := range events {
+		if event.Weight != 0 {
+			weights[event.Date] += float64(event.Weight)
+		}
+	}
+	return weights
+}
+
+// avoidWeights adds to the weights of the personal events by date those of
+// a year's avoid periods, negative on each of their days, for the optimizer
+func avoidWeights(weights map[string]float64, config models.YearConfig) map[string]float64 {
+	for _, p := range config.AvoidPeriods {
+		start, err1 := dates.Parse(p.StartDate)
+		end, err2 := dates.Parse(p.EndDate)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			weights[dates.Format(d)] -= float64(p.Weight)
+		}
+	}
+	return weights
+}
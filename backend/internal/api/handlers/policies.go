@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/events"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/policy"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// GetBookingRules returns the booking rules
+func (h *Handler) GetBookingRules(c *gin.Context) {
+	rules, err := h.store.Policies.Rules(c.Request.Context())
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// UpdateBookingRules replaces the booking rules and evaluates them against
+// the current and next year
+func (h *Handler) UpdateBookingRules(c *gin.Context) {
+	var input []struct {
+		Name            string   `json:"name"`
+		Kind            string   `json:"kind"`
+		MaxGap          int      `json:"max_gap"`
+		HolidayWeekdays []string `json:"holiday_weekdays"`
+		MaxWeeks        int      `json:"max_weeks"`
+		AutoApply       bool     `json:"auto_apply"`
+		Enabled         *bool    `json:"enabled"` // Defaults to true
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	var rules []models.BookingRule
+	for _, r := range input {
+		rule := models.BookingRule{
+			Name:      strings.TrimSpace(r.Name),
+			Kind:      r.Kind,
+			MaxWeeks:  r.MaxWeeks,
+			AutoApply: r.AutoApply,
+			Enabled:   r.Enabled == nil || *r.Enabled,
+		}
+		if rule.Kind == models.RuleBridge {
+			rule.MaxGap = r.MaxGap
+			if rule.MaxGap == 0 {
+				rule.MaxGap = 1
+			}
+			for _, day := range r.HolidayWeekdays {
+				rule.HolidayWeekdays = append(rule.HolidayWeekdays, strings.ToLower(strings.TrimSpace(day)))
+			}
+		}
+		if err := policy.Validate(rule); err != nil {
+			problem(c, http.StatusBadRequest, models.CodeInvalidRequest, fmt.Sprintf("Rule %q: %v", rule.Name, err))
+			return
+		}
+		rules = append(rules, rule)
+	}
+
+	ctx := c.Request.Context()
+	err := h.store.InTx(ctx, func(tx *store.Store) error {
+		return tx.Policies.ReplaceRules(ctx, rules)
+	})
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	currentYear := dates.Today(dates.Location(h.loadSettings(ctx).Timezone)).Year()
+	for year := currentYear; year <= currentYear+1; year++ {
+		if _, err := h.evaluatePolicies(ctx, year); err != nil {
+			log.Printf("Booking policies for %d failed: %v", year, err)
+		}
+	}
+
+	saved, _ := h.store.Policies.Rules(ctx)
+	c.JSON(http.StatusOK, saved)
+}
+
+// GetBookingProposals returns the proposals of a year, of every status
+func (h *Handler) GetBookingProposals(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	proposals, err := h.store.Policies.Proposals(c.Request.Context(), year)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, proposals)
+}
+
+// EvaluateBookingPolicies evaluates the booking rules against a year now and
+// returns its proposals
+func (h *Handler) EvaluateBookingPolicies(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+	if err := checkYear(year); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	proposals, err := h.evaluatePolicies(c.Request.Context(), year)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, proposals)
+}
+
+// ApplyBookingProposal books a pending proposal as a manual vacation day
+func (h *Handler) ApplyBookingProposal(c *gin.Context) {
+	year, proposal, ok := h.pendingProposal(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.applyProposal(ctx, year, proposal); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	proposal.Status = models.ProposalApplied
+	c.JSON(http.StatusOK, proposal)
+}
+
+// DismissBookingProposal rejects a pending proposal. Later evaluations don't
+// propose its date again.
+func (h *Handler) DismissBookingProposal(c *gin.Context) {
+	year, proposal, ok := h.pendingProposal(c)
+	if !ok {
+		return
+	}
+
+	if err := h.store.Policies.SetProposalStatus(c.Request.Context(), year, proposal.ID, models.ProposalDismissed); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	proposal.Status = models.ProposalDismissed
+	c.JSON(http.StatusOK, proposal)
+}
+
+// pendingProposal returns the year and pending proposal a request names,
+// writing the error response when there is none
+func (h *Handler) pendingProposal(c *gin.Context) (int, models.BookingProposal, bool) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return 0, models.BookingProposal{}, false
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid proposal id")
+		return 0, models.BookingProposal{}, false
+	}
+
+	proposal, err := h.store.Policies.GetProposal(c.Request.Context(), year, id)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Proposal not found")
+		return 0, proposal, false
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return 0, proposal, false
+	}
+	if proposal.Status != models.ProposalPending {
+		problem(c, http.StatusConflict, models.CodeConflict, "Proposal is already "+proposal.Status)
+		return 0, proposal, false
+	}
+
+	return year, proposal, true
+}
+
+// applyProposal books a proposal as a manual vacation day and marks it applied
+func (h *Handler) applyProposal(ctx context.Context, year int, proposal models.BookingProposal) error {
+	if err := h.AddVacationDay(ctx, year, proposal.Date, "Policy: "+proposal.RuleName); err != nil {
+		return err
+	}
+	return h.store.Policies.SetProposalStatus(ctx, year, proposal.ID, models.ProposalApplied)
+}
+
+// evaluatePolicies evaluates the booking rules against a year's calendar and
+// returns its proposals. New proposals are stored as pending, pending ones no
+// longer called for are dropped, and applied or dismissed ones are kept so
+// their dates are not proposed again. Proposals of auto_apply rules are
+// booked right away while vacation days remain.
+func (h *Handler) evaluatePolicies(ctx context.Context, year int) ([]models.BookingProposal, error) {
+	rules, err := h.store.Policies.Rules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []policy.Proposal
+	var remaining int
+	if len(rules) > 0 {
+		calendar, err := h.Calendar(ctx, year)
+		if err != nil {
+			return nil, err
+		}
+		remaining = calendar.Summary.RemainingVacationDays
+
+		// Only propose days still ahead
+		today := dates.Format(dates.Today(dates.Location(h.loadSettings(ctx).Timezone)))
+		for _, p := range policy.Evaluate(calendar.Days, rules) {
+			if p.Date >= today {
+				found = append(found, p)
+			}
+		}
+	}
+
+	ruleByID := make(map[int64]models.BookingRule, len(rules))
+	for _, rule := range rules {
+		ruleByID[rule.ID] = rule
+	}
+
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		existing, err := tx.Policies.Proposals(ctx, year)
+		if err != nil {
+			return err
+		}
+
+		// Pending proposals are replaced when another rule, or a rule saved
+		// again, now calls for their date
+		wanted := make(map[string]int64, len(found))
+		for _, p := range found {
+			wanted[p.Date] = p.RuleID
+		}
+		for _, p := range existing {
+			if p.Status == models.ProposalPending && wanted[p.Date] != p.RuleID {
+				if err := tx.Policies.DeleteProposal(ctx, p.ID); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, p := range found {
+			proposal := models.BookingProposal{
+				Year:     year,
+				Date:     p.Date,
+				RuleID:   p.RuleID,
+				RuleName: ruleByID[p.RuleID].Name,
+				Reason:   p.Reason,
+			}
+			if _, err := tx.Policies.AddProposal(ctx, proposal); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	proposals, err := h.store.Policies.Proposals(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+	applied := false
+	for _, p := range proposals {
+		if p.Status != models.ProposalPending || !ruleByID[p.RuleID].AutoApply || remaining <= 0 {
+			continue
+		}
+		if err := h.applyProposal(ctx, year, p); err != nil {
+			log.Printf("Failed to apply booking proposal for %s: %v", p.Date, err)
+			continue
+		}
+		applied = true
+		remaining--
+	}
+	if !applied {
+		return proposals, nil
+	}
+
+	return h.store.Policies.Proposals(ctx, year)
+}
+
+// evaluatePoliciesOnChange re-evaluates the booking rules of a year when its
+// holidays change. It is subscribed to the events bus.
+// maxConsecutiveDays returns the longest run of days off the booking rules
+// allow the optimizers to plan, 0 for no limit
+func (h *Handler) maxConsecutiveDays(ctx context.Context) int {
+	rules, err := h.store.Policies.Rules(ctx)
+	if err != nil {
+		log.Printf("Loading booking rules failed, planning without a consecutive days limit: %v", err)
+		return 0
+	}
+	return policy.MaxConsecutiveDays(rules)
+}
+
+func (h *Handler) evaluatePoliciesOnChange(event events.Event) {
+	switch event.Type {
+	case events.HolidaysRefreshed, events.HolidaysRecovered:
+	default:
+		return
+	}
+
+	year := event.Year
+	go func() {
+		if _, err := h.evaluatePolicies(context.Background(), year); err != nil {
+			log.Printf("Booking policies for %d failed: %v", year, err)
+		}
+	}()
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// problemBody builds a problem with extension members, such as the lock
+// held by another client, next to the standard ones
+func problemBody(c *gin.Context, status int, code, detail string, extensions []gin.H) gin.H {
+	body := gin.H{
+		"type":     "about:blank",
+		"title":    http.StatusText(status),
+		"status":   status,
+		"instance": c.Request.URL.Path,
+		"code":     code,
+	}
+	if detail != "" {
+		body["detail"] = detail
+	}
+	for _, ext := range extensions {
+		for k, v := range ext {
+			body[k] = v
+		}
+	}
+	return body
+}
+
+// problem sends a problem+json error response
+func problem(c *gin.Context, status int, code, detail string, extensions ...gin.H) {
+	c.Header("Content-Type", models.ProblemContentType)
+	c.JSON(status, problemBody(c, status, code, detail, extensions))
+}
+
+// abortProblem is problem for middleware, stopping the handlers after it
+func abortProblem(c *gin.Context, status int, code, detail string, extensions ...gin.H) {
+	c.Header("Content-Type", models.ProblemContentType)
+	c.AbortWithStatusJSON(status, problemBody(c, status, code, detail, extensions))
+}
+
+// respondError sends the problem for an error of the context-based methods
+func respondError(c *gin.Context, err error, extensions ...gin.H) {
+	problem(c, errorStatus(err), errorCode(err), err.Error(), extensions...)
+}
+
+// abortError is respondError for middleware
+func abortError(c *gin.Context, err error) {
+	abortProblem(c, errorStatus(err), errorCode(err), err.Error())
+}
+
+// errorCode returns the problem code for an error of the context-based
+// methods, the counterpart of errorStatus
+func errorCode(err error) string {
+	var input inputError
+	var upstream upstreamError
+	switch {
+	case errors.As(err, &input):
+		return input.code
+	case errors.As(err, &upstream):
+		return models.CodeUpstreamError
+	case errors.Is(err, ErrUnauthenticated):
+		return models.CodeUnauthenticated
+	case errors.Is(err, ErrForbidden):
+		return models.CodeForbidden
+	case isLockedOut(err):
+		return models.CodeLockedOut
+	case errors.Is(err, ErrTooManyAttempts):
+		return models.CodeRateLimited
+	case errors.Is(err, store.ErrNotFound):
+		return models.CodeNotFound
+	case errors.Is(err, ErrDateLocked):
+		return models.CodeDateLocked
+	case errors.Is(err, ErrPastDate):
+		return models.CodePastDate
+	case errors.Is(err, store.ErrVersionConflict):
+		return models.CodeVersionConflict
+	}
+	return models.CodeInternalError
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/render"
+)
+
+// RenderCalendarPNG returns the year's calendar as a PNG image. The optional
+// scale query parameter (1-4) enlarges it for printing.
+func (h *Handler) RenderCalendarPNG(c *gin.Context) {
+	scale := 1
+	if value := c.Query("scale"); value != "" {
+		var err error
+		scale, err = strconv.Atoi(value)
+		if err != nil || scale < 1 || scale > render.MaxScale {
+			problem(c, http.StatusBadRequest, models.CodeInvalidRequest, fmt.Sprintf("scale must be between 1 and %d", render.MaxScale))
+			return
+		}
+	}
+
+	h.renderCalendar(c, "image/png", func(buf *bytes.Buffer, year int) error {
+		calendar, err := h.Calendar(c.Request.Context(), year)
+		if err != nil {
+			return err
+		}
+		return render.PNG(buf, calendar, scale)
+	})
+}
+
+// RenderCalendarSVG returns the year's calendar as an SVG image
+func (h *Handler) RenderCalendarSVG(c *gin.Context) {
+	h.renderCalendar(c, "image/svg+xml", func(buf *bytes.Buffer, year int) error {
+		calendar, err := h.Calendar(c.Request.Context(), year)
+		if err != nil {
+			return err
+		}
+		return render.SVG(buf, calendar)
+	})
+}
+
+// renderCalendar renders into a buffer first, so a failure still gets a JSON error
+func (h *Handler) renderCalendar(c *gin.Context, contentType string, draw func(buf *bytes.Buffer, year int) error) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := draw(&buf, year); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Embedded images should follow the plan without going stale for long
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
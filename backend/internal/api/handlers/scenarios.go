@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/events"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// The active scenario of a year lives in the vacation_days and
+// optimal_vacations tables, so the rest of the app works on it unchanged.
+// Inactive scenarios are stored as snapshots in scenario_days and swapped in
+// when activated.
+
+// defaultScenarioName is used for the plan that existed before any scenario was created
+const defaultScenarioName = "Default"
+
+// GetScenarios lists the named plans for a year
+func (h *Handler) GetScenarios(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.ensureDefaultScenario(ctx, year); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	scenarios, err := h.store.Scenarios.List(ctx, year)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, scenarios)
+}
+
+// CreateScenario creates a new, empty named plan for a year
+func (h *Handler) CreateScenario(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	var input struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.ensureDefaultScenario(ctx, year); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	id, err := h.store.Scenarios.Create(ctx, year, input.Name, false)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeConflict, "A scenario with this name already exists")
+		return
+	}
+
+	scenario, err := h.store.Scenarios.Get(ctx, year, id)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, scenario)
+}
+
+// ActivateScenario makes a named plan the current one. The plan that was
+// active is saved back to its scenario first. Past and locked days are kept,
+// so only the days still editable are swapped.
+func (h *Handler) ActivateScenario(c *gin.Context) {
+	year, id, ok := scenarioParams(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	target, err := h.store.Scenarios.Get(ctx, year, id)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Scenario not found")
+		return
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	if target.IsActive {
+		c.JSON(http.StatusOK, target)
+		return
+	}
+
+	from := h.editableFrom(ctx)
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		if activeID, err := tx.Scenarios.ActiveID(ctx, year); err == nil {
+			if err := tx.Scenarios.Snapshot(ctx, year, activeID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Scenarios.Restore(ctx, year, id, from); err != nil {
+			return err
+		}
+		return tx.Scenarios.SetActive(ctx, year, id)
+	})
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	target.IsActive = true
+	h.events.Publish(events.ScenarioActivated, year, gin.H{"id": target.ID, "name": target.Name})
+	c.JSON(http.StatusOK, target)
+}
+
+// DuplicateScenario copies a named plan, including its vacation days, under a new name
+func (h *Handler) DuplicateScenario(c *gin.Context) {
+	year, id, ok := scenarioParams(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	source, err := h.store.Scenarios.Get(ctx, year, id)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Scenario not found")
+		return
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	var newID int64
+	var duplicateName bool
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		// The active scenario's days live in the working tables
+		if source.IsActive {
+			if err := tx.Scenarios.Snapshot(ctx, year, id); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		newID, err = tx.Scenarios.Create(ctx, year, input.Name, false)
+		if err != nil {
+			duplicateName = true
+			return err
+		}
+
+		return tx.Scenarios.CopyDays(ctx, id, newID)
+	})
+	if duplicateName {
+		problem(c, http.StatusBadRequest, models.CodeConflict, "A scenario with this name already exists")
+		return
+	}
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	scenario, err := h.store.Scenarios.Get(ctx, year, newID)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, scenario)
+}
+
+// DeleteScenario deletes an inactive named plan
+func (h *Handler) DeleteScenario(c *gin.Context) {
+	year, id, ok := scenarioParams(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	scenario, err := h.store.Scenarios.Get(ctx, year, id)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Scenario not found")
+		return
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	if scenario.IsActive {
+		problem(c, http.StatusBadRequest, models.CodeConflict, "Cannot delete the active scenario")
+		return
+	}
+
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		return tx.Scenarios.Delete(ctx, id)
+	})
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Scenario deleted"})
+}
+
+// scenarioParams parses the year and scenario id route parameters
+func scenarioParams(c *gin.Context) (int, int64, bool) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid scenario id")
+		return 0, 0, false
+	}
+
+	return year, id, true
+}
+
+// ensureDefaultScenario creates the active "Default" scenario for a year
+// that has none yet, so the existing plan becomes a named scenario
+func (h *Handler) ensureDefaultScenario(ctx context.Context, year int) error {
+	return h.store.Scenarios.EnsureActive(ctx, year, defaultScenarioName)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// getSchoolDistrict returns the configured district for school holidays
+func (h *Handler) getSchoolDistrict(ctx context.Context) string {
+	return h.store.Settings.Value(ctx, "school_district")
+}
+
+// schoolDistrictParam returns the district named by ?district=, or the
+// configured one. It writes a 400 and returns false for an unknown district.
+func (h *Handler) schoolDistrictParam(c *gin.Context) (string, bool) {
+	district := c.Query("district")
+	if district == "" {
+		return h.getSchoolDistrict(c.Request.Context()), true
+	}
+	if !holidays.IsSchoolDistrict(district) {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, fmt.Sprintf("Unknown school district %q", district))
+		return "", false
+	}
+	return district, true
+}
+
+// GetSchoolHolidays returns school breaks for a year and district
+func (h *Handler) GetSchoolHolidays(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	district, ok := h.schoolDistrictParam(c)
+	if !ok {
+		return
+	}
+
+	breaks, err := h.holidayService.LoadSchoolBreaks(year, district)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, breaks)
+}
+
+// UpdateSchoolHolidays replaces the school breaks for a year and district
+// with custom dates (e.g. from the official despacho)
+func (h *Handler) UpdateSchoolHolidays(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	var input []struct {
+		Name      string `json:"name" binding:"required"`
+		StartDate string `json:"start_date" binding:"required"`
+		EndDate   string `json:"end_date" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	district, ok := h.schoolDistrictParam(c)
+	if !ok {
+		return
+	}
+
+	var breaks []holidays.SchoolBreak
+	for _, b := range input {
+		start, err := dates.Parse(b.StartDate)
+		if err != nil {
+			problem(c, http.StatusBadRequest, models.CodeInvalidDate, "Invalid start_date: "+b.StartDate)
+			return
+		}
+		end, err := dates.Parse(b.EndDate)
+		if err != nil {
+			problem(c, http.StatusBadRequest, models.CodeInvalidDate, "Invalid end_date: "+b.EndDate)
+			return
+		}
+		if end.Before(start) {
+			problem(c, http.StatusBadRequest, models.CodeInvalidDate, "end_date must not be before start_date")
+			return
+		}
+
+		breaks = append(breaks, holidays.SchoolBreak{
+			Name:      b.Name,
+			StartDate: b.StartDate,
+			EndDate:   b.EndDate,
+			District:  district,
+			Source:    "custom",
+		})
+	}
+
+	if err := h.holidayService.SaveSchoolBreaks(year, district, breaks); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, breaks)
+}
+
+// ResetSchoolHolidays discards custom school breaks, restoring the calculated calendar
+func (h *Handler) ResetSchoolHolidays(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	district, ok := h.schoolDistrictParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.holidayService.ResetSchoolBreaks(year, district); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "School holidays reset"})
+}
+
+// GetSchoolDistricts returns the districts with configurable school calendars
+func (h *Handler) GetSchoolDistricts(c *gin.Context) {
+	c.JSON(http.StatusOK, holidays.GetSchoolDistricts())
+}
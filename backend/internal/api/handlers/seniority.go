@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// GetSeniorityRules returns the extra vacation days by years of service
+func (h *Handler) GetSeniorityRules(c *gin.Context) {
+	rules, err := h.store.Configs.SeniorityRules(c.Request.Context())
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// UpdateSeniorityRules replaces the seniority rules
+func (h *Handler) UpdateSeniorityRules(c *gin.Context) {
+	var input []struct {
+		YearsOfService int `json:"years_of_service"`
+		ExtraDays      int `json:"extra_days"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	seen := make(map[int]bool)
+	var rules []models.SeniorityRule
+	for _, r := range input {
+		if r.YearsOfService < 0 || r.ExtraDays < 0 {
+			problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "years_of_service and extra_days must not be negative")
+			return
+		}
+		if seen[r.YearsOfService] {
+			problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Duplicate rule for "+strconv.Itoa(r.YearsOfService)+" years of service")
+			return
+		}
+		seen[r.YearsOfService] = true
+		rules = append(rules, models.SeniorityRule{YearsOfService: r.YearsOfService, ExtraDays: r.ExtraDays})
+	}
+
+	ctx := c.Request.Context()
+	err := h.store.InTx(ctx, func(tx *store.Store) error {
+		return tx.Configs.ReplaceSeniorityRules(ctx, rules)
+	})
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	saved, _ := h.store.Configs.SeniorityRules(ctx)
+	c.JSON(http.StatusOK, saved)
+}
+
+// GetEntitlement returns the vacation days computed for a year from the
+// default allowance, the employment start date and the seniority rules
+func (h *Handler) GetEntitlement(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.computeEntitlement(c.Request.Context(), year))
+}
+
+// computeEntitlement works out the default vacation days of a year. The
+// rule with the most years of service reached on January 1 applies.
+func (h *Handler) computeEntitlement(ctx context.Context, year int) models.Entitlement {
+	stored := h.loadSettings(ctx)
+	entitlement := models.Entitlement{
+		Year:                year,
+		BaseDays:            stored.DefaultVacationDays,
+		EmploymentStartDate: stored.EmploymentStartDate,
+	}
+
+	if start, err := dates.Parse(entitlement.EmploymentStartDate); err == nil {
+		entitlement.YearsOfService = completedYears(start, time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC))
+	}
+
+	rules, _ := h.store.Configs.SeniorityRules(ctx)
+	for _, r := range rules {
+		if r.YearsOfService <= entitlement.YearsOfService {
+			entitlement.ExtraDays = r.ExtraDays
+		}
+	}
+
+	entitlement.VacationDays = entitlement.BaseDays + entitlement.ExtraDays
+	return entitlement
+}
+
+// completedYears returns the full years between start and date, 0 when the
+// date is before the start
+func completedYears(start, date time.Time) int {
+	years := date.Year() - start.Year()
+	if date.Month() < start.Month() || (date.Month() == start.Month() && date.Day() < start.Day()) {
+		years--
+	}
+	if years < 0 {
+		return 0
+	}
+	return years
+}
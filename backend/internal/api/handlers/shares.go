@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// Share links give read-only access to one year's calendar without the API:
+// the token in /share/:token is the only credential, so links are revoked
+// by deleting them.
+
+// maxShareDays is the longest validity of an expiring share link
+const maxShareDays = 365
+
+//go:embed templates/share.html
+var shareHTML string
+
+var shareTemplate = template.Must(template.New("share").Parse(shareHTML))
+
+// GetShareLinks lists the share links, newest first
+func (h *Handler) GetShareLinks(c *gin.Context) {
+	links, err := h.store.Shares.List(c.Request.Context())
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+// CreateShareLink creates a share link for a year, optionally expiring after
+// a number of days
+func (h *Handler) CreateShareLink(c *gin.Context) {
+	var input struct {
+		Year          int    `json:"year" binding:"required"`
+		Label         string `json:"label"`
+		ExpiresInDays int    `json:"expires_in_days"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+	if err := checkYear(input.Year); err != nil {
+		respondError(c, err)
+		return
+	}
+	if input.ExpiresInDays < 0 || input.ExpiresInDays > maxShareDays {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, fmt.Sprintf("expires_in_days must be between 0 (never) and %d", maxShareDays))
+		return
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	link := models.ShareLink{Token: token, Year: input.Year, Label: input.Label}
+	if input.ExpiresInDays > 0 {
+		link.ExpiresAt = time.Now().UTC().AddDate(0, 0, input.ExpiresInDays).Format(time.RFC3339)
+	}
+
+	link, err = h.store.Shares.Create(c.Request.Context(), link)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// DeleteShareLink revokes a share link
+func (h *Handler) DeleteShareLink(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid share link id")
+		return
+	}
+
+	deleted, err := h.store.Shares.Delete(c.Request.Context(), id)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+	if !deleted {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Share link not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Share link deleted"})
+}
+
+// ViewShare renders the calendar of a share link as a read-only page
+func (h *Handler) ViewShare(c *gin.Context) {
+	shared, err := h.sharedCalendar(c.Request.Context(), c.Param("token"))
+	setShareHeaders(c)
+	if errors.Is(err, store.ErrNotFound) {
+		c.String(http.StatusNotFound, "This share link does not exist or has expired.")
+		return
+	} else if err != nil {
+		c.String(http.StatusInternalServerError, "The calendar could not be loaded.")
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := shareTemplate.Execute(c.Writer, newSharePage(shared)); err != nil {
+		c.Error(err)
+	}
+}
+
+// GetSharedCalendar returns the calendar of a share link as JSON
+func (h *Handler) GetSharedCalendar(c *gin.Context) {
+	shared, err := h.sharedCalendar(c.Request.Context(), c.Param("token"))
+	setShareHeaders(c)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Share link not found or expired")
+		return
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, shared)
+}
+
+// sharedCalendar returns the read-only calendar for an active share token
+func (h *Handler) sharedCalendar(ctx context.Context, token string) (models.SharedCalendar, error) {
+	link, err := h.store.Shares.Active(ctx, token)
+	if err != nil {
+		return models.SharedCalendar{}, err
+	}
+
+	calendar, err := h.Calendar(ctx, link.Year)
+	if err != nil {
+		return models.SharedCalendar{}, err
+	}
+
+	// Family members and personal events are not shared
+	for i := range calendar.Days {
+		calendar.Days[i].FamilyOff = nil
+		calendar.Days[i].Events = nil
+	}
+
+	return models.SharedCalendar{
+		Year:           calendar.Year,
+		Label:          link.Label,
+		Days:           calendar.Days,
+		Holidays:       calendar.Holidays,
+		VacationBlocks: calendar.VacationBlocks,
+	}, nil
+}
+
+// setShareHeaders keeps shared calendars out of search engines and keeps the
+// token out of the Referer header of outgoing links
+func setShareHeaders(c *gin.Context) {
+	c.Header("X-Robots-Tag", "noindex, nofollow")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("Cache-Control", "no-store")
+}
+
+// newShareToken returns a random URL-safe token
+func newShareToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// sharePage is the data of the share page template
+type sharePage struct {
+	Title  string
+	Months []shareMonth
+	Blocks []shareBlock
+}
+
+type shareMonth struct {
+	Name  string
+	Weeks [][]shareDay
+}
+
+// shareDay is a cell of the month grid; Day is 0 for padding cells
+type shareDay struct {
+	Day   int
+	Class string
+	Title string
+}
+
+type shareBlock struct {
+	Dates   string
+	DaysOff int
+}
+
+// newSharePage lays out a shared calendar as month grids with weeks
+// starting on Monday
+func newSharePage(shared models.SharedCalendar) sharePage {
+	page := sharePage{Title: fmt.Sprintf("Vacations %d", shared.Year)}
+	if shared.Label != "" {
+		page.Title = fmt.Sprintf("%s · %d", shared.Label, shared.Year)
+	}
+
+	days := make(map[string]models.CalendarDay, len(shared.Days))
+	for _, day := range shared.Days {
+		days[day.Date] = day
+	}
+
+	for month := time.January; month <= time.December; month++ {
+		first := time.Date(shared.Year, month, 1, 0, 0, 0, 0, time.UTC)
+		m := shareMonth{Name: month.String()}
+
+		// Pad the first week up to the 1st
+		week := make([]shareDay, (int(first.Weekday())+6)%7)
+		for date := first; date.Month() == month; date = date.AddDate(0, 0, 1) {
+			day := days[date.Format("2006-01-02")]
+			cell := shareDay{Day: date.Day()}
+			switch {
+			case day.IsVacation:
+				cell.Class, cell.Title = "vacation", "Vacation"
+			case day.IsHoliday:
+				cell.Class, cell.Title = "holiday", day.HolidayName
+			case day.IsWeekend:
+				cell.Class = "weekend"
+			}
+
+			week = append(week, cell)
+			if len(week) == 7 {
+				m.Weeks = append(m.Weeks, week)
+				week = nil
+			}
+		}
+		if len(week) > 0 {
+			m.Weeks = append(m.Weeks, append(week, make([]shareDay, 7-len(week))...))
+		}
+		page.Months = append(page.Months, m)
+	}
+
+	for _, block := range shared.VacationBlocks {
+		start, err1 := dates.Parse(block.StartDate)
+		end, err2 := dates.Parse(block.EndDate)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		dates := start.Format("Mon 2 Jan")
+		if !end.Equal(start) {
+			dates += " – " + end.Format("Mon 2 Jan")
+		}
+		page.Blocks = append(page.Blocks, shareBlock{Dates: dates, DaysOff: block.TotalDays})
+	}
+
+	return page
+}
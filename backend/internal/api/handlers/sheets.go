@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/events"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/sheets"
+)
+
+// Google Sheets layouts
+const (
+	sheetLayoutBlock = "block"
+	sheetLayoutDay   = "day"
+)
+
+// ExportSheet writes a year plan to the configured Google Sheet, in a sheet
+// named after the year. ?layout=day or ?layout=block overrides the setting.
+func (h *Handler) ExportSheet(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	layout := c.Query("layout")
+	if layout != "" && layout != sheetLayoutBlock && layout != sheetLayoutDay {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "layout must be block or day")
+		return
+	}
+
+	rows, err := h.exportSheet(c.Request.Context(), year, layout)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sheet updated", "sheet": strconv.Itoa(year), "rows": rows})
+}
+
+// exportSheet writes a year plan to the configured spreadsheet and returns
+// the number of rows written, header included. An empty layout uses the
+// google_sheets_layout setting.
+func (h *Handler) exportSheet(ctx context.Context, year int, layout string) (int, error) {
+	s := h.loadSettings(ctx)
+	if s.GoogleSheetsSpreadsheetID == "" {
+		return 0, invalidInputCode(models.CodeNotConfigured, errors.New("google_sheets_spreadsheet_id is not set"))
+	}
+	writer, err := sheets.New(s.GoogleSheetsCredentials)
+	if err != nil {
+		return 0, invalidInputCode(models.CodeNotConfigured, err)
+	}
+	if layout == "" {
+		layout = s.GoogleSheetsLayout
+	}
+
+	calendar, err := h.Calendar(ctx, year)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := sheetRows(calendar, layout)
+	if err := writer.WriteSheet(ctx, s.GoogleSheetsSpreadsheetID, strconv.Itoa(year), rows); err != nil {
+		return 0, upstreamFailure(err)
+	}
+	return len(rows), nil
+}
+
+// sheetRows lays out a year plan as a header and one row per vacation block
+// or per day
+func sheetRows(calendar models.CalendarResponse, layout string) [][]string {
+	tripNames := make(map[int64]string)
+	for _, trip := range calendar.Trips {
+		tripNames[trip.ID] = trip.Name
+	}
+
+	if layout == sheetLayoutDay {
+		rows := [][]string{{"Date", "Weekday", "Status", "Holiday", "Source", "Trip"}}
+		for _, day := range calendar.Days {
+			status := dayOffKind(day)
+			if status == "" {
+				status = "work"
+			}
+			source := ""
+			if day.IsManual {
+				source = "manual"
+			} else if day.IsOptimal {
+				source = "optimized"
+			}
+			rows = append(rows, []string{day.Date, day.DayOfWeek, status, day.HolidayName, source, tripNames[day.TripID]})
+		}
+		return rows
+	}
+
+	rows := [][]string{{"Start", "End", "Days off", "Vacation days", "Efficiency", "Source", "Holidays", "Trip", "Label"}}
+	for _, block := range calendar.VacationBlocks {
+		rows = append(rows, []string{
+			block.StartDate,
+			block.EndDate,
+			strconv.Itoa(block.TotalDays),
+			strconv.Itoa(block.VacationDaysUsed),
+			fmt.Sprintf("%.2f", block.Efficiency),
+			block.Source,
+			strings.Join(block.Holidays, ", "),
+			tripNames[block.TripID],
+			block.Label,
+		})
+	}
+	return rows
+}
+
+// sheetSyncDelay is how long after the last change of a year its sheet is
+// written, so a bulk update or an optimization writes it once
+const sheetSyncDelay = 5 * time.Second
+
+// sheetSyncer rewrites the sheets of changed years when
+// google_sheets_sync_on_change is on
+type sheetSyncer struct {
+	mu     sync.Mutex
+	timers map[int]*time.Timer
+}
+
+func newSheetSyncer() *sheetSyncer {
+	return &sheetSyncer{timers: make(map[int]*time.Timer)}
+}
+
+// schedule runs sync for a year after sheetSyncDelay, postponing a run
+// already scheduled for it
+func (s *sheetSyncer) schedule(year int, sync func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if timer, ok := s.timers[year]; ok {
+		timer.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(sheetSyncDelay, func() {
+		s.mu.Lock()
+		if s.timers[year] == timer {
+			delete(s.timers, year)
+		}
+		s.mu.Unlock()
+		sync()
+	})
+	s.timers[year] = timer
+}
+
+// stop cancels the scheduled runs
+func (s *sheetSyncer) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for year, timer := range s.timers {
+		timer.Stop()
+		delete(s.timers, year)
+	}
+}
+
+// syncSheetOnChange schedules a sheet export when a year's vacation days
+// change. It is subscribed to the events bus.
+func (h *Handler) syncSheetOnChange(event events.Event) {
+	switch event.Type {
+	case events.VacationAdded, events.VacationRemoved, events.OptimizationCompleted:
+	default:
+		return
+	}
+	if !h.loadSettings(context.Background()).GoogleSheetsSyncOnChange {
+		return
+	}
+
+	year := event.Year
+	h.sheetSync.schedule(year, func() {
+		if _, err := h.exportSheet(context.Background(), year, ""); err != nil {
+			log.Printf("Google Sheets sync for %d failed: %v", year, err)
+		}
+	})
+}
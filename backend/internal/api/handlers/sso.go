@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/oidc"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// Single sign-on hands out access tokens: a user signing in with the
+// company's OpenID Connect provider gets a token with the highest role their
+// groups map to, which expires after oidc_session_hours. Like a created
+// token, the first one turns access control on and must then be an admin's.
+
+// oidcStateCookie binds a sign-in to the browser that started it, so a link
+// to another user's callback can't sign someone in as them
+const oidcStateCookie = "oidc_state"
+
+// oidcConfig returns the identity provider configured in the settings
+func (h *Handler) oidcConfig(ctx context.Context) oidc.Config {
+	s := h.loadSettings(ctx)
+	return oidc.Config{
+		Issuer:       s.OIDCIssuer,
+		ClientID:     s.OIDCClientID,
+		ClientSecret: s.OIDCClientSecret,
+		RedirectURL:  s.OIDCRedirectURL,
+		GroupsClaim:  s.OIDCGroupsClaim,
+	}
+}
+
+// OIDCLogin sends the browser to the identity provider to sign in
+func (h *Handler) OIDCLogin(c *gin.Context) {
+	ctx := c.Request.Context()
+	config := h.oidcConfig(ctx)
+	if !config.Configured() {
+		problem(c, http.StatusBadRequest, models.CodeNotConfigured, "Single sign-on is not configured")
+		return
+	}
+
+	provider, err := oidc.Discover(ctx, config)
+	if err != nil {
+		respondError(c, upstreamFailure(err))
+		return
+	}
+	login, err := provider.Begin()
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+	h.logins.Add(login)
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, login.State, int(oidc.LoginTTL/time.Second), "/api/auth/oidc", "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, login.URL)
+}
+
+// OIDCCallback completes a sign-in when the provider sends the user back,
+// and sends them on to the app with a session token in the URL fragment
+func (h *Handler) OIDCCallback(c *gin.Context) {
+	if reason := c.Query("error"); reason != "" {
+		detail := c.Query("error_description")
+		if detail == "" {
+			detail = reason
+		}
+		problem(c, http.StatusUnauthorized, models.CodeUnauthenticated, "The identity provider refused the sign-in: "+detail)
+		return
+	}
+
+	state := c.Query("state")
+	cookie, _ := c.Cookie(oidcStateCookie)
+	c.SetCookie(oidcStateCookie, "", -1, "/api/auth/oidc", "", c.Request.TLS != nil, true)
+	login, ok := h.logins.Take(state)
+	if !ok || cookie != state {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Unknown or expired sign-in, start again")
+		return
+	}
+
+	ctx := c.Request.Context()
+	provider, err := oidc.Discover(ctx, h.oidcConfig(ctx))
+	if err != nil {
+		respondError(c, upstreamFailure(err))
+		return
+	}
+	identity, err := provider.Exchange(ctx, c.Query("code"), login)
+	if err != nil {
+		respondError(c, upstreamFailure(err))
+		return
+	}
+
+	s := h.loadSettings(ctx)
+	name := "sso:" + identity.DisplayName()
+	role := ssoRole(identity.Groups, s.OIDCRoleMapping, s.OIDCDefaultRole)
+	if role == "" {
+		h.audit(ctx, models.AuthEvent{Event: models.AuthEventSSODenied, Client: c.ClientIP(), TokenName: name, Detail: groupsDetail(identity.Groups)})
+		problem(c, http.StatusForbidden, models.CodeForbidden, "None of your groups has a role in the planner")
+		return
+	}
+
+	var token models.AccessToken
+	err = h.store.InTx(ctx, func(tx *store.Store) error {
+		n, err := tx.Tokens.Count(ctx, "")
+		if err != nil {
+			return err
+		}
+		if n == 0 && role != models.RoleAdmin {
+			return invalidInput(errors.New("the first token must be an admin token, so an admin has to sign in first"))
+		}
+
+		token, err = tx.Tokens.CreateExpiring(ctx, name, role, time.Now().Add(time.Duration(s.OIDCSessionHours)*time.Hour))
+		if err != nil {
+			return err
+		}
+		return tx.Audit.Add(ctx, models.AuthEvent{
+			Event:     models.AuthEventSSOLogin,
+			Client:    c.ClientIP(),
+			TokenName: token.Name,
+			Detail:    "role " + role + ", " + groupsDetail(identity.Groups),
+		})
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/#access_token="+url.QueryEscape(token.Token))
+}
+
+// ssoRole returns the highest role the groups map to, or fallback when none
+// of them is mapped
+func ssoRole(groups []string, mapping map[string]string, fallback string) string {
+	role := ""
+	for _, group := range groups {
+		if mapped, ok := mapping[group]; ok && roleLevels[mapped] > roleLevels[role] {
+			role = mapped
+		}
+	}
+	if role == "" {
+		return fallback
+	}
+	return role
+}
+
+// groupsDetail lists the groups of a user for the audit log
+func groupsDetail(groups []string) string {
+	if len(groups) == 0 {
+		return "no groups"
+	}
+	sorted := append([]string(nil), groups...)
+	sort.Strings(sorted)
+	return "groups " + strings.Join(sorted, ", ")
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// GetCalendarStats returns the monthly and quarterly breakdown of a year
+func (h *Handler) GetCalendarStats(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	config, err := h.getOrCreateYearConfig(ctx, year)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	comp := h.compPool(ctx, year)
+	holidayList := h.holidaysForYear(ctx, year)
+	manualVacations, _ := h.store.Vacations.List(ctx, year)
+	optimalVacations, _ := h.store.Vacations.ListOptimal(ctx, year)
+
+	days := h.buildCalendarDays(year, config, holidayList, manualVacations, optimalVacations)
+	markCompDays(days, comp.SpentDates())
+	blocks := h.buildVacationBlocks(year, config, withCompDays(holidayList, comp.SpentDates()), manualVacations, optimalVacations)
+	summary := h.calculateSummary(ctx, year, config.VacationDays, days, manualVacations, optimalVacations, holidayList, blocks)
+	h.applyHoursBalance(ctx, &summary, year, config, manualVacations, optimalVacations)
+
+	c.JSON(http.StatusOK, models.CalendarStats{
+		Year:     year,
+		Months:   calculateMonthlySummary(days, config.VacationDays+comp.Balance),
+		Quarters: summary.QuarterDistribution,
+		Summary:  summary,
+	})
+}
+
+// calculateMonthlySummary counts vacation days, holidays and streaks of days
+// off per month, along with the vacation budget left at the end of each month
+func calculateMonthlySummary(days []models.CalendarDay, totalVacation int) []models.MonthSummary {
+	months := make([]models.MonthSummary, 12)
+	for i := range months {
+		months[i].Month = i + 1
+	}
+
+	streak := 0
+	for _, day := range days {
+		date, err := dates.Parse(day.Date)
+		if err != nil {
+			continue
+		}
+		m := &months[int(date.Month())-1]
+
+		// Streaks are counted within the month
+		if date.Day() == 1 {
+			streak = 0
+		}
+
+		if day.IsHoliday {
+			m.Holidays++
+		}
+		if day.IsVacation {
+			m.VacationDays++
+		}
+
+		if day.IsWeekend || day.IsHoliday || day.IsVacation || day.IsCompDay {
+			m.DaysOff++
+			streak++
+			if streak > m.LongestStreak {
+				m.LongestStreak = streak
+			}
+		} else {
+			streak = 0
+		}
+	}
+
+	remaining := totalVacation
+	for i := range months {
+		remaining -= months[i].VacationDays
+		months[i].RemainingBudget = remaining
+	}
+
+	return months
+}
+
+// GetHistoricalStats aggregates every year with vacation days: days taken,
+// block lengths, efficiency over time, favourite months and unused days lost
+func (h *Handler) GetHistoricalStats(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	years, err := h.store.Vacations.Years(ctx)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	today := h.today(ctx)
+	expiry := h.loadSettings(ctx).CarryoverExpiry
+
+	stats := models.HistoricalStats{Years: []models.YearHistory{}, MostCommonMonths: []models.MonthCount{}}
+	monthDays := make([]int, 12)
+	totalBlocks, totalBlockDays := 0, 0
+
+	for _, year := range years {
+		calendar, err := h.Calendar(ctx, year)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		history := models.YearHistory{
+			Year:         year,
+			VacationDays: calendar.Summary.TotalVacationDays,
+			DaysTaken:    calendar.Summary.UsedVacationDays,
+			Blocks:       len(calendar.VacationBlocks),
+			Efficiency:   calendar.Summary.Efficiency,
+		}
+
+		blockDays := 0
+		for _, block := range calendar.VacationBlocks {
+			blockDays += block.TotalDays
+		}
+		if history.Blocks > 0 {
+			history.AverageBlockLength = math.Round(float64(blockDays)/float64(history.Blocks)*100) / 100
+		}
+		totalBlocks += history.Blocks
+		totalBlockDays += blockDays
+
+		for _, day := range calendar.Days {
+			if day.IsVacation {
+				date, _ := dates.Parse(day.Date)
+				monthDays[date.Month()-1]++
+			}
+		}
+
+		// Days left over are carried into the next year until the carryover expiry
+		if calendar.Summary.RemainingVacationDays > 0 {
+			history.UnusedDays = calendar.Summary.RemainingVacationDays
+			expires, err := dates.Parse(fmt.Sprintf("%d-%s", year+1, expiry))
+			if err == nil && today.After(expires) {
+				history.Lost = true
+				stats.UnusedDaysLost += history.UnusedDays
+			}
+		}
+
+		stats.TotalDaysTaken += history.DaysTaken
+		stats.Years = append(stats.Years, history)
+	}
+
+	if totalBlocks > 0 {
+		stats.AverageBlockLength = math.Round(float64(totalBlockDays)/float64(totalBlocks)*100) / 100
+	}
+	stats.EfficiencyTrend = efficiencyTrend(stats.Years)
+
+	for i, count := range monthDays {
+		if count > 0 {
+			stats.MostCommonMonths = append(stats.MostCommonMonths, models.MonthCount{Month: i + 1, VacationDays: count})
+		}
+	}
+	sort.SliceStable(stats.MostCommonMonths, func(i, j int) bool {
+		return stats.MostCommonMonths[i].VacationDays > stats.MostCommonMonths[j].VacationDays
+	})
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// efficiencyTrend returns the least squares slope of efficiency per year,
+// over the years that have vacation blocks. Positive means improving.
+func efficiencyTrend(years []models.YearHistory) float64 {
+	var n, sumX, sumY, sumXY, sumXX float64
+	for _, y := range years {
+		if y.Blocks == 0 {
+			continue
+		}
+		x := float64(y.Year)
+		n++
+		sumX += x
+		sumY += y.Efficiency
+		sumXY += x * y.Efficiency
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if n < 2 || denominator == 0 {
+		return 0
+	}
+	return math.Round((n*sumXY-sumX*sumY)/denominator*100) / 100
+}
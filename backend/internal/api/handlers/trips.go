@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// tripInput is the body of trip create and update requests
+type tripInput struct {
+	Name        string  `json:"name" binding:"required"`
+	Destination string  `json:"destination"`
+	StartDate   string  `json:"start_date" binding:"required"`
+	EndDate     string  `json:"end_date" binding:"required"`
+	Notes       string  `json:"notes"`
+	Budget      float64 `json:"budget"`
+}
+
+// trip validates the input and returns it as a trip of a year
+func (in tripInput) trip(year int) (models.Trip, error) {
+	if err := checkYear(year); err != nil {
+		return models.Trip{}, err
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return models.Trip{}, invalidInput(errors.New("name is required"))
+	}
+	if err := checkDateInYear(in.StartDate, year); err != nil {
+		return models.Trip{}, err
+	}
+	if err := checkDateInYear(in.EndDate, year); err != nil {
+		return models.Trip{}, err
+	}
+	if in.EndDate < in.StartDate {
+		return models.Trip{}, invalidInputCode(models.CodeInvalidDate, fmt.Errorf("end_date %s is before start_date %s", in.EndDate, in.StartDate))
+	}
+	if in.Budget < 0 {
+		return models.Trip{}, invalidInput(errors.New("budget cannot be negative"))
+	}
+
+	return models.Trip{
+		Year:        year,
+		Name:        name,
+		Destination: strings.TrimSpace(in.Destination),
+		StartDate:   in.StartDate,
+		EndDate:     in.EndDate,
+		Notes:       in.Notes,
+		Budget:      in.Budget,
+	}, nil
+}
+
+// GetTrips returns the trips of a year with the vacation days they group
+func (h *Handler) GetTrips(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	trips, err := h.tripsForYear(c.Request.Context(), year)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, trips)
+}
+
+// CreateTrip plans a trip over a date range of a year
+func (h *Handler) CreateTrip(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	var input tripInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	trip, err := input.trip(year)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	trip, err = h.store.Trips.Create(ctx, trip)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, h.withVacationDates(ctx, trip))
+}
+
+// UpdateTrip replaces the details and date range of a trip
+func (h *Handler) UpdateTrip(c *gin.Context) {
+	year, id, ok := tripParams(c)
+	if !ok {
+		return
+	}
+
+	var input tripInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	trip, err := input.trip(year)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	trip.ID = id
+
+	ctx := c.Request.Context()
+	trip, err = h.store.Trips.Update(ctx, trip)
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Trip not found")
+		return
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, h.withVacationDates(ctx, trip))
+}
+
+// DeleteTrip removes a trip and its expenses. Its vacation days are kept.
+func (h *Handler) DeleteTrip(c *gin.Context) {
+	year, id, ok := tripParams(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	err := h.store.InTx(ctx, func(tx *store.Store) error {
+		return tx.Trips.Delete(ctx, year, id)
+	})
+	if errors.Is(err, store.ErrNotFound) {
+		problem(c, http.StatusNotFound, models.CodeNotFound, "Trip not found")
+		return
+	} else if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Trip deleted"})
+}
+
+func tripParams(c *gin.Context) (int, int64, bool) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid trip id")
+		return 0, 0, false
+	}
+
+	return year, id, true
+}
+
+// tripsForYear returns the trips of a year with the vacation days in their
+// date ranges
+func (h *Handler) tripsForYear(ctx context.Context, year int) ([]models.Trip, error) {
+	trips, err := h.store.Trips.List(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+
+	vacationDates := h.vacationDates(ctx, year)
+	for i := range trips {
+		trips[i].VacationDates = datesInTrip(trips[i], vacationDates)
+	}
+	return trips, nil
+}
+
+// withVacationDates fills in the vacation days in a trip's date range
+func (h *Handler) withVacationDates(ctx context.Context, trip models.Trip) models.Trip {
+	trip.VacationDates = datesInTrip(trip, h.vacationDates(ctx, trip.Year))
+	return trip
+}
+
+// vacationDates returns the manual and optimal vacation days of a year
+func (h *Handler) vacationDates(ctx context.Context, year int) []string {
+	var result []string
+	manualVacations, _ := h.store.Vacations.List(ctx, year)
+	for _, v := range manualVacations {
+		result = append(result, v.Date)
+	}
+	optimalVacations, _ := h.store.Vacations.ListOptimal(ctx, year)
+	for _, v := range optimalVacations {
+		result = append(result, v.Date)
+	}
+	return result
+}
+
+// datesInTrip returns the dates within a trip's range, sorted
+func datesInTrip(trip models.Trip, candidates []string) []string {
+	result := []string{}
+	for _, date := range candidates {
+		if trip.Contains(date) {
+			result = append(result, date)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// linkTrips ties calendar days and vacation blocks to the trip they fall in.
+// When trips overlap, the one starting first wins.
+func linkTrips(trips []models.Trip, days []models.CalendarDay, blocks []models.VacationBlock) {
+	for i := range days {
+		for _, trip := range trips {
+			if trip.Contains(days[i].Date) {
+				days[i].TripID = trip.ID
+				break
+			}
+		}
+	}
+
+	for i := range blocks {
+		for _, trip := range trips {
+			if trip.StartDate <= blocks[i].EndDate && trip.EndDate >= blocks[i].StartDate {
+				blocks[i].TripID = trip.ID
+				break
+			}
+		}
+	}
+}
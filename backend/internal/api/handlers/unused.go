@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// checkBlackoutPeriod returns an error unless a blackout period is a range of
+// dates in year
+func checkBlackoutPeriod(p models.BlackoutPeriod, year int) error {
+	if err := checkDateInYear(p.StartDate, year); err != nil {
+		return err
+	}
+	if err := checkDateInYear(p.EndDate, year); err != nil {
+		return err
+	}
+	if p.EndDate < p.StartDate {
+		return invalidInputCode(models.CodeInvalidDate, fmt.Errorf("blackout period ends on %s, before it starts on %s", p.EndDate, p.StartDate))
+	}
+	return nil
+}
+
+// blackoutDates returns the work days of a year's blackout periods, which
+// the optimizer must not take as vacation
+func blackoutDates(config models.YearConfig) []string {
+	var blackout []string
+	for _, p := range config.BlackoutPeriods {
+		start, err1 := dates.Parse(p.StartDate)
+		end, err2 := dates.Parse(p.EndDate)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			if config.IsWorkDay(d) {
+				blackout = append(blackout, dates.Format(d))
+			}
+		}
+	}
+	return blackout
+}
+
+// checkAvoidPeriod returns an error unless an avoid period is a range of
+// dates in year with a weight from 1 to MaxEventWeight
+func checkAvoidPeriod(p models.AvoidPeriod, year int) error {
+	if err := checkDateInYear(p.StartDate, year); err != nil {
+		return err
+	}
+	if err := checkDateInYear(p.EndDate, year); err != nil {
+		return err
+	}
+	if p.EndDate < p.StartDate {
+		return invalidInputCode(models.CodeInvalidDate, fmt.Errorf("avoid period ends on %s, before it starts on %s", p.EndDate, p.StartDate))
+	}
+	if p.Weight < 1 || p.Weight > models.MaxEventWeight {
+		return invalidInput(fmt.Errorf("avoid period weight must be between 1 and %d", models.MaxEventWeight))
+	}
+	return nil
+}
+
+// flagUnusedDays works out how many vacation days can realistically still be
+// booked in a year, a share of its open work days from today on, and flags
+// the summary when more days are left than that. Past years are over and
+// are not flagged.
+func (h *Handler) flagUnusedDays(ctx context.Context, summary *models.CalendarSummary, year int, config models.YearConfig, days []models.CalendarDay) {
+	today := h.today(ctx)
+	if year < today.Year() {
+		return
+	}
+
+	from := dates.Format(today)
+	open := 0
+	for _, day := range days {
+		if day.Date < from || day.IsWeekend || day.IsHoliday || day.IsVacation || day.IsCompDay {
+			continue
+		}
+		if !config.InBlackout(day.Date) {
+			open++
+		}
+	}
+
+	share := h.loadSettings(ctx).UnusedDaysShare
+	if share <= 0 {
+		share = models.DefaultUnusedDaysShare
+	}
+	summary.SchedulableDays = models.SchedulableDays(open, share)
+	summary.UnusedDaysAtRisk = summary.RemainingVacationDays+summary.CompDaysRemaining > summary.SchedulableDays
+}
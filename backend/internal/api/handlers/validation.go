@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// Supported range for years in request paths
+const (
+	minYear = 1970
+	maxYear = 2100
+)
+
+// ValidateYear rejects requests whose :year or :sourceYear path segment is
+// not a year in the supported range, before any handler runs
+func (h *Handler) ValidateYear(c *gin.Context) {
+	for _, param := range []string{"year", "sourceYear"} {
+		value := c.Param(param)
+		if value == "" {
+			continue
+		}
+		year, err := strconv.Atoi(value)
+		if err == nil {
+			err = checkYear(year)
+		}
+		if err != nil {
+			abortProblem(c, http.StatusBadRequest, models.CodeInvalidYear, fmt.Sprintf("Invalid year: must be between %d and %d", minYear, maxYear))
+			return
+		}
+	}
+	c.Next()
+}
+
+// inputError is an error caused by the request rather than the server,
+// with the problem code of its response
+type inputError struct {
+	err  error
+	code string
+}
+
+func (e inputError) Error() string { return e.err.Error() }
+func (e inputError) Unwrap() error { return e.err }
+
+// invalidInput marks err as caused by invalid input
+func invalidInput(err error) error {
+	return inputError{err, models.CodeInvalidRequest}
+}
+
+// invalidInputCode is invalidInput with a more specific problem code, such
+// as models.CodeInvalidDate
+func invalidInputCode(code string, err error) error {
+	return inputError{err, code}
+}
+
+// IsInvalidInput reports whether err was caused by invalid input, such as a
+// date outside the year, rather than by a server failure
+func IsInvalidInput(err error) bool {
+	var target inputError
+	return errors.As(err, &target)
+}
+
+// upstreamError is an error returned by an external service, such as
+// Google Sheets or an HR system
+type upstreamError struct {
+	err error
+}
+
+func (e upstreamError) Error() string { return e.err.Error() }
+func (e upstreamError) Unwrap() error { return e.err }
+
+// upstreamFailure marks err as a failure of an external service
+func upstreamFailure(err error) error {
+	return upstreamError{err}
+}
+
+// errorStatus returns the HTTP status for an error of the context-based methods
+func errorStatus(err error) int {
+	var upstream upstreamError
+	switch {
+	case IsInvalidInput(err):
+		return http.StatusBadRequest
+	case errors.As(err, &upstream):
+		return http.StatusBadGateway
+	case errors.Is(err, ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrTooManyAttempts):
+		return http.StatusTooManyRequests
+	case errors.Is(err, store.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrDateLocked), errors.Is(err, ErrPastDate):
+		return http.StatusConflict
+	case errors.Is(err, store.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusInternalServerError
+}
+
+// checkYear returns an error unless year is in the supported range
+func checkYear(year int) error {
+	if year < minYear || year > maxYear {
+		return invalidInputCode(models.CodeInvalidYear, fmt.Errorf("invalid year %d, must be between %d and %d", year, minYear, maxYear))
+	}
+	return nil
+}
+
+// checkDateInYear returns an error unless date is a YYYY-MM-DD date in year
+func checkDateInYear(date string, year int) error {
+	parsed, err := dates.Parse(date)
+	if err != nil {
+		return invalidInputCode(models.CodeInvalidDate, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date))
+	}
+	if parsed.Year() != year {
+		return invalidInputCode(models.CodeInvalidDate, fmt.Errorf("date %s is not in %d", date, year))
+	}
+	return nil
+}
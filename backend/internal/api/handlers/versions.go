@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// ifMatch returns the version in a request's If-Match header, "" when it has
+// none or takes any version ("*")
+func ifMatch(c *gin.Context) string {
+	value := strings.TrimPrefix(strings.TrimSpace(c.GetHeader("If-Match")), "W/")
+	if value == "*" {
+		return ""
+	}
+	return strings.Trim(value, `"`)
+}
+
+// setETag sends the version of the resource in a response
+func setETag(c *gin.Context, version string) {
+	c.Header("ETag", `"`+version+`"`)
+}
+
+// configVersion is the ETag of a year's config
+func configVersion(config models.YearConfig) string {
+	return strconv.Itoa(config.Version)
+}
+
+// vacationsVersion is the ETag of a year's manual vacation days. It changes
+// whenever a day is added or removed or its note changes, whoever makes the
+// change.
+func vacationsVersion(vacations []models.VacationDay) string {
+	sorted := append([]models.VacationDay(nil), vacations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date < sorted[j].Date })
+
+	hash := sha256.New()
+	for _, v := range sorted {
+		hash.Write([]byte(v.Date + "\t" + v.Note + "\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
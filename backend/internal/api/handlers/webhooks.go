@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/events"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// GetWebhookDeliveries returns the most recent webhook deliveries and their status
+func (h *Handler) GetWebhookDeliveries(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+
+	deliveries, err := h.webhooks.Deliveries(limit)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// TestWebhooks sends a ping event to all configured webhook URLs
+func (h *Handler) TestWebhooks(c *gin.Context) {
+	h.events.Publish(events.Ping, 0, gin.H{"message": "Webhook test from vacation planner"})
+	c.JSON(http.StatusOK, gin.H{"message": "Test event queued"})
+}
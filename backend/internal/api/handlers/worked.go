@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// GetWorkedHolidays returns the holidays marked as working days for a year
+func (h *Handler) GetWorkedHolidays(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	worked, err := h.store.Holidays.Worked(c.Request.Context(), year)
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, worked)
+}
+
+// AddWorkedHoliday marks a holiday as a working day. It is no longer counted
+// as a day off and credits a day to the compensation pool.
+func (h *Handler) AddWorkedHoliday(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	var input struct {
+		Date string `json:"date" binding:"required"`
+		Note string `json:"note"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Only actual holidays can be worked (observed ones included)
+	var name string
+	allHolidays := holidays.ApplySubstitution(h.applyHolidayOverrides(ctx, year, h.holidayService.Holidays(year, h.getWorkCity(ctx))), h.getHolidaySubstitution(ctx))
+	for _, hol := range allHolidays {
+		if hol.Date == input.Date {
+			name = hol.Name
+			break
+		}
+	}
+	if name == "" {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Date is not a holiday")
+		return
+	}
+
+	err = h.store.Holidays.AddWorked(ctx, models.WorkedHoliday{Year: year, Date: input.Date, Name: name, Note: input.Note})
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Holiday marked as worked"})
+}
+
+// RemoveWorkedHoliday turns a worked holiday back into a day off
+func (h *Handler) RemoveWorkedHoliday(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	date := c.Param("date")
+
+	if err := h.store.Holidays.RemoveWorked(c.Request.Context(), year, date); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Holiday restored"})
+}
+
+// applyHolidayRules applies the holiday overrides, adds observed holidays
+// from the substitution policy, drops holidays marked as worked and adds the
+// company optional holidays enabled for the year and the birthday day off
+func (h *Handler) applyHolidayRules(ctx context.Context, year int, holidayList []holidays.PortugueseHoliday) []holidays.PortugueseHoliday {
+	holidayList = h.applyHolidayOverrides(ctx, year, holidayList)
+
+	worked := make(map[string]bool)
+	workedList, _ := h.store.Holidays.Worked(ctx, year)
+	for _, w := range workedList {
+		worked[w.Date] = true
+	}
+
+	// Worked holidays don't get an observed substitute either
+	var kept []holidays.PortugueseHoliday
+	for _, hol := range holidayList {
+		if !worked[hol.Date] {
+			kept = append(kept, hol)
+		}
+	}
+
+	var result []holidays.PortugueseHoliday
+	for _, hol := range holidays.ApplySubstitution(kept, h.getHolidaySubstitution(ctx)) {
+		if !worked[hol.Date] {
+			result = append(result, hol)
+		}
+	}
+
+	schedule, err := h.store.Configs.Get(ctx, year)
+	if err != nil {
+		schedule = models.YearConfig{WorkWeek: []string{"monday", "tuesday", "wednesday", "thursday", "friday"}}
+	}
+
+	result = holidays.AddOptional(result, year, schedule.OptionalHolidays)
+
+	// The birthday day off lands on a work day, so it needs the year's work week
+	birthday := h.store.Settings.Value(ctx, "birthday")
+	rule := h.store.Settings.Value(ctx, "birthday_day_off")
+	if rule != "" && rule != holidays.BirthdayNone {
+		result = holidays.AddBirthday(result, year, birthday, rule, schedule.IsWorkDay)
+	}
+
+	return result
+}
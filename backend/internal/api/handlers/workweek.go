@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// GetWorkWeekChanges returns the dated work week changes of a year
+func (h *Handler) GetWorkWeekChanges(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.getWorkWeekChanges(c.Request.Context(), year))
+}
+
+// SetWorkWeekChange switches to a different work week from a date onwards.
+// A change on the same date is replaced.
+func (h *Handler) SetWorkWeekChange(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	var input struct {
+		EffectiveFrom string   `json:"effective_from" binding:"required"`
+		WorkWeek      []string `json:"work_week" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	if err := checkDateInYear(input.EffectiveFrom, year); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	validDays := make(map[string]bool)
+	for _, d := range models.AllWeekDays {
+		validDays[d] = true
+	}
+	var workWeek []string
+	for _, d := range input.WorkWeek {
+		d = strings.ToLower(d)
+		if !validDays[d] {
+			problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Invalid work day: "+d)
+			return
+		}
+		workWeek = append(workWeek, d)
+	}
+	if len(workWeek) == 0 {
+		problem(c, http.StatusBadRequest, models.CodeInvalidRequest, "Work week must have at least one day")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Make sure the year has a config for the base work week
+	if _, err := h.getOrCreateYearConfig(ctx, year); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	err = h.store.Configs.SetWorkWeekChange(ctx, models.WorkWeekChange{Year: year, EffectiveFrom: input.EffectiveFrom, WorkWeek: workWeek})
+	if err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, h.getWorkWeekChanges(ctx, year))
+}
+
+// RemoveWorkWeekChange removes the work week change starting on a date
+func (h *Handler) RemoveWorkWeekChange(c *gin.Context) {
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		problem(c, http.StatusBadRequest, models.CodeInvalidYear, "Invalid year")
+		return
+	}
+
+	date := c.Param("date")
+	ctx := c.Request.Context()
+
+	if err := h.store.Configs.RemoveWorkWeekChange(ctx, year, date); err != nil {
+		problem(c, http.StatusInternalServerError, models.CodeInternalError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, h.getWorkWeekChanges(ctx, year))
+}
+
+// getWorkWeekChanges loads the work week changes of a year, oldest first
+func (h *Handler) getWorkWeekChanges(ctx context.Context, year int) []models.WorkWeekChange {
+	changes, err := h.store.Configs.WorkWeekChanges(ctx, year)
+	if err != nil {
+		return []models.WorkWeekChange{}
+	}
+	return changes
+}
@@ -1,22 +1,31 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"log"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 
 	"github.com/bruno.lopes/calendar/backend/internal/api/handlers"
+	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/rpc"
+	"github.com/bruno.lopes/calendar/backend/internal/web"
 )
 
 // Version is set at build time
 var Version = "dev"
 
 type Server struct {
-	db     *sql.DB
-	router *gin.Engine
+	db      *sql.DB
+	router  *gin.Engine
+	handler *handlers.Handler
 }
 
 func NewServer(db *sql.DB) *Server {
@@ -25,21 +34,40 @@ func NewServer(db *sql.DB) *Server {
 		router: gin.Default(),
 	}
 
+	// The client IP, which failed login attempts are counted by, is only
+	// taken from X-Forwarded-For when the request comes through one of the
+	// proxies in TRUSTED_PROXIES; otherwise any client could pick its own
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	if err := s.router.SetTrustedProxies(proxies); err != nil {
+		log.Printf("Ignoring TRUSTED_PROXIES: %v", err)
+		s.router.SetTrustedProxies(nil)
+	}
+
 	// Configure CORS
 	config := cors.DefaultConfig()
 	config.AllowAllOrigins = true
 	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", "X-Client-ID", "X-Tenant", "If-Match", "Idempotency-Key"}
+	config.ExposeHeaders = []string{"ETag", "Idempotent-Replayed"}
 	s.router.Use(cors.New(config))
 
 	s.setupRoutes()
+	s.setupFrontend()
 	return s
 }
 
 func (s *Server) setupRoutes() {
 	h := handlers.NewHandler(s.db)
+	s.handler = h
 
 	api := s.router.Group("/api")
+	// Reject out-of-range years before any handler or edit lock check runs
+	api.Use(h.ValidateYear)
 	{
 		// Health check
 		api.GET("/health", func(c *gin.Context) {
@@ -55,38 +83,186 @@ func (s *Server) setupRoutes() {
 			c.JSON(http.StatusOK, gin.H{"version": version})
 		})
 
+		// Single sign-on, which signed out users reach to get a token
+		api.GET("/auth/oidc/login", h.OIDCLogin)
+		api.GET("/auth/oidc/callback", h.OIDCCallback)
+
+		// Every route below needs an access token once one exists; health
+		// and version stay open for probes
+		api.Use(h.Authenticate)
+		// Retried POST and PUT requests with an Idempotency-Key replay the
+		// first response
+		api.Use(h.Idempotency)
+		api.GET("/access", h.GetAccess)
+		api.GET("/access/tokens", h.RequireRole(models.RoleAdmin), h.GetAccessTokens)
+		api.POST("/access/tokens", h.RequireRole(models.RoleAdmin), h.CreateAccessToken)
+		api.DELETE("/access/tokens/:id", h.RequireRole(models.RoleAdmin), h.DeleteAccessToken)
+		api.GET("/access/audit", h.RequireRole(models.RoleAdmin), h.GetAuditLog)
+		api.GET("/access/lockouts", h.RequireRole(models.RoleAdmin), h.GetLockouts)
+		api.DELETE("/access/lockouts/:client", h.RequireRole(models.RoleAdmin), h.DeleteLockout)
+
 		// Calendar endpoints
+		api.GET("/calendar/next-break", h.GetNextBreak)
+		api.GET("/stats", h.GetHistoricalStats)
+		api.GET("/calendar", h.GetCalendars)
+		api.GET("/calendar/range", h.GetCalendarRange)
+		api.GET("/calendar/live", h.LiveUpdates) // WebSocket
+		api.GET("/calendar/day/:date", h.GetDay)
 		api.GET("/calendar/:year", h.GetCalendar)
-		api.POST("/calendar/:year/optimize", h.OptimizeVacations)
-		api.DELETE("/calendar/:year/optimized", h.ClearOptimizedVacations)
+		api.POST("/calendar/:year/optimize", h.RequireEditLock, h.OptimizeVacations)
+		api.DELETE("/calendar/:year/optimized", h.RequireEditLock, h.ClearOptimizedVacations)
+		api.POST("/calendar/:year/optimized/accept", h.RequireEditLock, h.AcceptOptimizedVacations)
+		api.POST("/calendar/:year/optimized/decline", h.RequireEditLock, h.DeclineOptimizedBlock)
+		api.GET("/calendar/:year/declined", h.GetDeclinedBlocks)
+		api.DELETE("/calendar/:year/declined", h.RequireEditLock, h.ClearDeclinedBlocks)
+		api.DELETE("/calendar/:year/declined/:id", h.RequireEditLock, h.DeleteDeclinedBlock)
 		api.GET("/calendar/:year/suggestions", h.GetVacationSuggestions)
+		api.GET("/calendar/:year/stats", h.GetCalendarStats)
+		api.GET("/calendar/:year/long-weekends", h.GetLongWeekends)
+		api.GET("/calendar/:year/flight-prices", h.GetFlightPrices)
+		api.GET("/calendar/:year/render.png", h.RenderCalendarPNG)
+		api.GET("/calendar/:year/render.svg", h.RenderCalendarSVG)
+		api.GET("/calendar/:year/blocks/:block", h.GetBlockICS) // :block is <blockId>.ics
+		api.PUT("/calendar/:year/blocks/:block", h.RequireEditLock, h.SetBlockLabel)
+		api.DELETE("/calendar/:year/blocks/:block", h.RequireEditLock, h.DeleteBlockLabel)
+		api.POST("/calendar/:year/blocks/:block/out-of-office", h.DraftOutOfOffice)
+
+		// Comments on vacation days and blocks
+		api.GET("/comments/:year", h.GetComments)
+		api.POST("/comments/:year", h.AddComment)
+		api.PUT("/comments/:year/:id", h.UpdateComment)
+		api.DELETE("/comments/:year/:id", h.DeleteComment)
+
+		// Edit lock endpoints
+		api.GET("/calendar/:year/lock", h.GetEditLock)
+		api.POST("/calendar/:year/lock", h.AcquireEditLock)
+		api.DELETE("/calendar/:year/lock", h.ReleaseEditLock)
 
 		// Vacation days endpoints
 		api.GET("/vacations/:year", h.GetVacations)
-		api.POST("/vacations/:year", h.AddVacation)
-		api.DELETE("/vacations/:year/:date", h.RemoveVacation)
-		api.PUT("/vacations/:year/bulk", h.BulkUpdateVacations)
+		api.POST("/vacations/:year", h.RequireEditLock, h.AddVacation)
+		api.DELETE("/vacations/:year/:date", h.RequireEditLock, h.RemoveVacation)
+		api.DELETE("/vacations/:year/range", h.RequireEditLock, h.RemoveVacationRange)
+		api.PUT("/vacations/:year/bulk", h.RequireEditLock, h.BulkUpdateVacations)
+		api.POST("/vacations/:year/move", h.RequireEditLock, h.MoveVacations)
+		api.GET("/vacations/:year/locked", h.GetLockedDates)
+		api.POST("/vacations/:year/locked", h.RequireRole(models.RoleManager), h.LockDates)
+		api.DELETE("/vacations/:year/locked/:date", h.RequireRole(models.RoleManager), h.UnlockDate)
+
+		// Scenario endpoints
+		api.GET("/scenarios/:year", h.GetScenarios)
+		api.POST("/scenarios/:year", h.RequireEditLock, h.CreateScenario)
+		api.POST("/scenarios/:year/:id/activate", h.RequireEditLock, h.ActivateScenario)
+		api.POST("/scenarios/:year/:id/duplicate", h.RequireEditLock, h.DuplicateScenario)
+		api.DELETE("/scenarios/:year/:id", h.RequireEditLock, h.DeleteScenario)
 
 		// Holidays endpoints
 		api.GET("/holidays/:year", h.GetHolidays)
 		api.GET("/holidays/:year/status", h.GetHolidayStatus)
 		api.GET("/holidays/status", h.GetAllHolidayStatuses)
 		api.POST("/holidays/:year/refresh", h.RefreshHolidays)
+		api.GET("/holidays/:year/worked", h.GetWorkedHolidays)
+		api.POST("/holidays/:year/worked", h.RequireEditLock, h.AddWorkedHoliday)
+		api.DELETE("/holidays/:year/worked/:date", h.RequireEditLock, h.RemoveWorkedHoliday)
+		api.GET("/holidays/:year/overrides", h.GetHolidayOverrides)
+		api.PUT("/holidays/:year/overrides/:date", h.RequireEditLock, h.SetHolidayOverride)
+		api.DELETE("/holidays/:year/overrides/:date", h.RequireEditLock, h.RemoveHolidayOverride)
+		api.GET("/holidays/:year/optional", h.GetOptionalHolidays)
+		api.PUT("/holidays/:year/optional/:key", h.RequireEditLock, h.SetOptionalHoliday)
 		api.GET("/cities", h.GetAvailableCities)
 
+		// Compensation days (time off in lieu)
+		api.GET("/comp-days/:year", h.GetCompDays)
+		api.POST("/comp-days/:year/credit", h.RequireRole(models.RoleManager), h.RequireEditLock, h.CreditCompDay)
+		api.POST("/comp-days/:year/spend", h.RequireEditLock, h.SpendCompDay)
+		api.DELETE("/comp-days/:year/:id", h.RequireEditLock, h.DeleteCompDay)
+		api.POST("/comp-days/:year/adjustments", h.RequireRole(models.RoleManager), h.RequireEditLock, h.CreateBalanceAdjustment)
+		api.DELETE("/comp-days/:year/adjustments/:id", h.RequireRole(models.RoleManager), h.RequireEditLock, h.DeleteBalanceAdjustment)
+
+		// Trip endpoints
+		api.GET("/trips/:year", h.GetTrips)
+		api.POST("/trips/:year", h.RequireEditLock, h.CreateTrip)
+		api.PUT("/trips/:year/:id", h.RequireEditLock, h.UpdateTrip)
+		api.DELETE("/trips/:year/:id", h.RequireEditLock, h.DeleteTrip)
+		api.GET("/trips/:year/budget", h.GetTripBudget)
+		api.GET("/trips/:year/:id/expenses", h.GetTripExpenses)
+		api.POST("/trips/:year/:id/expenses", h.RequireEditLock, h.AddTripExpense)
+		api.PUT("/trips/:year/:id/expenses/:expenseId", h.RequireEditLock, h.UpdateTripExpense)
+		api.DELETE("/trips/:year/:id/expenses/:expenseId", h.RequireEditLock, h.DeleteTripExpense)
+
+		// School holidays endpoints
+		api.GET("/school-holidays/:year", h.GetSchoolHolidays)
+		api.PUT("/school-holidays/:year", h.RequireEditLock, h.UpdateSchoolHolidays)
+		api.DELETE("/school-holidays/:year", h.RequireEditLock, h.ResetSchoolHolidays)
+		api.GET("/school-districts", h.GetSchoolDistricts)
+
+		// Household members and their school or childcare closures
+		api.GET("/family/:year", h.GetFamily)
+		api.POST("/family", h.CreateFamilyMember)
+		api.PUT("/family/:id", h.UpdateFamilyMember)
+		api.DELETE("/family/:id", h.DeleteFamilyMember)
+		api.POST("/family/:id/closures", h.AddFamilyClosure)
+		api.DELETE("/family/:id/closures/:closureId", h.DeleteFamilyClosure)
+
+		// Personal events drawing vacation to their date or keeping it away
+		api.GET("/personal-events/:year", h.GetPersonalEvents)
+		api.POST("/personal-events/:year", h.RequireEditLock, h.CreatePersonalEvent)
+		api.PUT("/personal-events/:year/:id", h.RequireEditLock, h.UpdatePersonalEvent)
+		api.DELETE("/personal-events/:year/:id", h.RequireEditLock, h.DeletePersonalEvent)
+
+		// Booking rules and the vacation days they propose
+		api.GET("/policies", h.GetBookingRules)
+		api.PUT("/policies", h.UpdateBookingRules)
+		api.GET("/policies/:year/proposals", h.GetBookingProposals)
+		api.POST("/policies/:year/evaluate", h.RequireEditLock, h.EvaluateBookingPolicies)
+		api.POST("/policies/:year/proposals/:id/apply", h.RequireEditLock, h.ApplyBookingProposal)
+		api.POST("/policies/:year/proposals/:id/dismiss", h.RequireEditLock, h.DismissBookingProposal)
+
 		// Year config endpoints
 		api.GET("/config/:year", h.GetYearConfig)
-		api.PUT("/config/:year", h.UpdateYearConfig)
-		api.POST("/config/:year/copy-from/:sourceYear", h.CopyYearConfig)
+		api.PUT("/config/:year", h.RequireRole(models.RoleManager), h.RequireEditLock, h.UpdateYearConfig)
+		api.POST("/config/:year/copy-from/:sourceYear", h.RequireRole(models.RoleManager), h.CopyYearConfig)
+		api.GET("/config/:year/entitlement", h.GetEntitlement)
+		api.GET("/config/:year/work-week", h.GetWorkWeekChanges)
+		api.POST("/config/:year/work-week", h.RequireEditLock, h.SetWorkWeekChange)
+		api.DELETE("/config/:year/work-week/:date", h.RequireEditLock, h.RemoveWorkWeekChange)
 
 		// Settings endpoints
 		api.GET("/settings", h.GetSettings)
-		api.PUT("/settings", h.UpdateSettings)
+		api.GET("/settings/schema", h.GetSettingsSchema)
+		api.PUT("/settings", h.RequireRole(models.RoleAdmin), h.UpdateSettings)
+		api.POST("/settings/ai/validate", h.RequireRole(models.RoleAdmin), h.ValidateAI)
 		api.GET("/settings/:key", h.GetSetting)
-		api.PUT("/settings/:key", h.UpdateSetting)
+		api.PUT("/settings/:key", h.RequireRole(models.RoleAdmin), h.UpdateSetting)
+
+		// Webhook endpoints
+		api.GET("/webhooks/deliveries", h.RequireRole(models.RoleAdmin), h.GetWebhookDeliveries)
+		api.POST("/webhooks/test", h.RequireRole(models.RoleAdmin), h.TestWebhooks)
+
+		// HR leave import
+		api.POST("/hr/import/:year", h.RequireRole(models.RoleManager), h.RequireEditLock, h.ImportLeave)
+
+		// On-call schedule import
+		api.POST("/oncall/import/:year", h.RequireRole(models.RoleManager), h.RequireEditLock, h.ImportOnCall)
+
+		// Project milestone import
+		api.POST("/milestones/import/:year", h.RequireRole(models.RoleManager), h.RequireEditLock, h.ImportMilestones)
+
+		// Google Sheets export
+		api.POST("/sheets/:year/export", h.ExportSheet)
+
+		// Notification endpoints
+		api.POST("/notifications/test", h.RequireRole(models.RoleAdmin), h.TestNotifications)
+		api.POST("/notifications/digest", h.SendDigest)
+		api.GET("/notifications/digest/monthly", h.PreviewMonthlyDigest)
+		api.GET("/notifications/preferences", h.GetNotificationPreferences)
+		api.PUT("/notifications/preferences", h.UpdateNotificationPreferences)
+		api.GET("/push/public-key", h.GetPushPublicKey)
+		api.POST("/push/subscriptions", h.SubscribePush)
+		api.DELETE("/push/subscriptions", h.UnsubscribePush)
 
 		// Chat endpoints
-		api.POST("/chat/:year", h.Chat)
+		api.POST("/chat/:year", h.RequireEditLock, h.Chat)
 		api.GET("/chat/:year/history", h.GetChatHistory)
 		api.DELETE("/chat/:year/history", h.ClearChatHistory)
 
@@ -96,9 +272,87 @@ func (s *Server) setupRoutes() {
 		// Work week presets
 		api.GET("/presets/work-week", h.GetWorkWeekPresets)
 		api.GET("/presets/strategies", h.GetOptimizationStrategies)
+		api.GET("/presets/holiday-substitution", h.GetHolidaySubstitutionPolicies)
+		api.GET("/presets/birthday", h.GetBirthdayRules)
+
+		// Seniority rules
+		api.GET("/seniority-rules", h.GetSeniorityRules)
+		api.PUT("/seniority-rules", h.RequireRole(models.RoleManager), h.UpdateSeniorityRules)
+
+		// Background jobs
+		api.GET("/admin/jobs", h.RequireRole(models.RoleAdmin), h.GetJobs)
+		api.POST("/admin/jobs/:name/run", h.RequireRole(models.RoleAdmin), h.RunJob)
+
+		// Stored data, a year at a time
+		api.GET("/admin/data", h.RequireRole(models.RoleAdmin), h.GetStoredYears)
+		api.DELETE("/admin/data/:year", h.RequireRole(models.RoleAdmin), h.PurgeYear)
+		api.POST("/admin/data/archive", h.RequireRole(models.RoleAdmin), h.ArchiveYears)
+
+		// Database backups, taken on the backup_schedule setting or on demand
+		api.GET("/admin/backups", h.RequireRole(models.RoleAdmin), h.GetBackups)
+		api.POST("/admin/backups", h.RequireRole(models.RoleAdmin), h.CreateBackup)
+		api.POST("/admin/backups/:name/restore", h.RequireRole(models.RoleAdmin), h.RestoreBackup)
+
+		// Share links
+		api.GET("/shares", h.RequireRole(models.RoleManager), h.GetShareLinks)
+		api.POST("/shares", h.RequireRole(models.RoleManager), h.CreateShareLink)
+		api.DELETE("/shares/:id", h.RequireRole(models.RoleManager), h.DeleteShareLink)
+	}
+
+	// Public read-only views of shared calendars, outside the API
+	share := s.router.Group("/share")
+	{
+		share.GET("/:token", h.ViewShare)
+		share.GET("/:token/calendar.json", h.GetSharedCalendar)
+	}
+}
+
+// setupFrontend serves the embedded frontend for every path that is not an
+// API route, when the binary was built with it
+func (s *Server) setupFrontend() {
+	if !web.Enabled() {
+		return
 	}
+
+	spa := web.Handler()
+	s.router.NoRoute(func(c *gin.Context) {
+		method := c.Request.Method
+		if strings.HasPrefix(c.Request.URL.Path, "/api/") || (method != http.MethodGet && method != http.MethodHead) {
+			c.Header("Content-Type", models.ProblemContentType)
+			problem := models.NewProblem(http.StatusNotFound, models.CodeNotFound, "Not found")
+			problem.Instance = c.Request.URL.Path
+			c.JSON(http.StatusNotFound, problem)
+			return
+		}
+		// Gin sets 404 before calling NoRoute handlers
+		c.Status(http.StatusOK)
+		spa.ServeHTTP(c.Writer, c.Request)
+	})
+}
+
+// HolidayService returns the holiday service used by the API, so startup
+// pre-fetches share its status and recovery notifications
+func (s *Server) HolidayService() *holidays.HolidayService {
+	return s.handler.HolidayService()
+}
+
+// LoadSettings reads the settings into the cache before the first request
+func (s *Server) LoadSettings(ctx context.Context) error {
+	return s.handler.LoadSettings(ctx)
+}
+
+// GRPCServer returns a gRPC server for the calendar, vacation and optimizer
+// API, sharing the REST API's store, edit locks and events
+func (s *Server) GRPCServer() *grpc.Server {
+	return rpc.NewServer(s.handler)
+}
+
+// Close stops the background work started by the server
+func (s *Server) Close() {
+	s.handler.Close()
 }
 
-func (s *Server) Run(addr string) error {
-	return s.router.Run(addr)
+// Handler returns the HTTP handler serving the API
+func (s *Server) Handler() http.Handler {
+	return s.router
 }
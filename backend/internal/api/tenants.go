@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/bruno.lopes/calendar/backend/internal/database"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/tenants"
+)
+
+// TenantServer hosts several isolated organizations in one process. Each
+// tenant has its own database, and so its own settings, cities, access
+// tokens and AI keys, served by its own Server with its own background jobs.
+// Tenants are opened on their first request.
+type TenantServer struct {
+	dir    string
+	domain string
+
+	mu      sync.Mutex
+	servers map[string]*Server
+}
+
+// NewTenantServer serves the tenants with a database in dir. With domain
+// set, a request to <tenant>.<domain> is for that tenant; otherwise, or for
+// other hosts, the X-Tenant header names it.
+func NewTenantServer(dir, domain string) *TenantServer {
+	return &TenantServer{dir: dir, domain: domain, servers: make(map[string]*Server)}
+}
+
+// ServeHTTP hands a request to its tenant's server
+func (t *TenantServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := tenants.FromRequest(r, t.domain)
+	if name == "" {
+		writeError(w, http.StatusNotFound, models.CodeNotFound, "No tenant given: use a tenant subdomain or the X-Tenant header")
+		return
+	}
+
+	srv, err := t.server(r.Context(), name)
+	if errors.Is(err, tenants.ErrNotFound) {
+		writeError(w, http.StatusNotFound, models.CodeNotFound, "Unknown tenant")
+		return
+	} else if err != nil {
+		log.Printf("Failed to open tenant %s: %v", name, err)
+		writeError(w, http.StatusServiceUnavailable, models.CodeUnavailable, "Tenant is unavailable")
+		return
+	}
+
+	srv.Handler().ServeHTTP(w, r)
+}
+
+// server returns the server of a tenant, opening its database on first use
+func (t *TenantServer) server(ctx context.Context, name string) (*Server, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if srv, ok := t.servers[name]; ok {
+		return srv, nil
+	}
+	if !tenants.Exists(t.dir, name) {
+		return nil, tenants.ErrNotFound
+	}
+
+	db, err := database.Initialize(tenants.Path(t.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	srv := NewServer(db)
+	srv.handler.SetTenant(name)
+	if err := srv.LoadSettings(ctx); err != nil {
+		srv.Close()
+		db.Close()
+		return nil, err
+	}
+
+	log.Printf("Opened tenant %s", name)
+	t.servers[name] = srv
+	return srv, nil
+}
+
+// Ping checks the database of every open tenant, for the readiness probe
+func (t *TenantServer) Ping(ctx context.Context) error {
+	t.mu.Lock()
+	dbs := make([]*sql.DB, 0, len(t.servers))
+	for _, srv := range t.servers {
+		dbs = append(dbs, srv.db)
+	}
+	t.mu.Unlock()
+
+	for _, db := range dbs {
+		if err := db.PingContext(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the background work of every open tenant and closes their
+// databases
+func (t *TenantServer) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for name, srv := range t.servers {
+		srv.Close()
+		srv.db.Close()
+		delete(t.servers, name)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", models.ProblemContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(models.NewProblem(status, code, message))
+}
@@ -0,0 +1,137 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/api"
+	"github.com/bruno.lopes/calendar/backend/internal/database"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/sandbox"
+	"github.com/bruno.lopes/calendar/backend/internal/tenants"
+)
+
+func TestTenantServer(t *testing.T) {
+	sandbox.Enable()
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	for _, name := range []string{"acme", "globex"} {
+		db, err := database.Initialize(tenants.Path(dir, name))
+		if err != nil {
+			t.Fatalf("create tenant %s: %v", name, err)
+		}
+		db.Close()
+	}
+
+	srv := api.NewTenantServer(dir, "planner.test")
+	t.Cleanup(srv.Close)
+
+	do := func(host, tenant, method, path, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Host = host
+		r.Header.Set("Content-Type", "application/json")
+		if tenant != "" {
+			r.Header.Set(tenants.Header, tenant)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := do("acme.planner.test", "", http.MethodPut, "/api/settings/work_city", `{"value": "Porto"}`); w.Code != http.StatusOK {
+		t.Fatalf("set acme's work city: status %d: %s", w.Code, w.Body)
+	}
+
+	for tenant, want := range map[string]string{"acme": "Porto", "globex": ""} {
+		w := do("localhost", tenant, http.MethodGet, "/api/settings/work_city", "")
+		var got map[string]string
+		json.NewDecoder(w.Body).Decode(&got)
+		if w.Code != http.StatusOK || got["work_city"] != want {
+			t.Errorf("%s's work city: status %d, %q, want %q", tenant, w.Code, got["work_city"], want)
+		}
+	}
+
+	if w := do("acme.planner.test", "", http.MethodPut, "/api/settings/calendarific_api_key", `{"value": "key"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("set the instance-wide Calendarific key: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if w := do("initech.planner.test", "", http.MethodGet, "/api/health", ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown tenant: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if w := do("localhost", "", http.MethodGet, "/api/health", ""); w.Code != http.StatusNotFound {
+		t.Errorf("no tenant: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestTenantHolidays(t *testing.T) {
+	sandbox.Enable()
+	gin.SetMode(gin.TestMode)
+
+	// Only acme has fresh holidays of 2040 stored, with a day of its own
+	dir := t.TempDir()
+	for _, name := range []string{"acme", "globex"} {
+		db, err := database.Initialize(tenants.Path(dir, name))
+		if err != nil {
+			t.Fatalf("create tenant %s: %v", name, err)
+		}
+		if name == "acme" {
+			_, err = db.Exec(`INSERT INTO holidays (year, date, name, type, location, fetched_at, source) VALUES (2040, '2040-03-15', 'Acme Day', 'national', '', ?, 'nager')`, time.Now().UTC().Format(time.RFC3339))
+		}
+		db.Close()
+		if err != nil {
+			t.Fatalf("store acme's holiday: %v", err)
+		}
+	}
+
+	srv := api.NewTenantServer(dir, "planner.test")
+	t.Cleanup(srv.Close)
+
+	do := func(tenant, path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Host = tenant + ".planner.test"
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		return w
+	}
+
+	// Open globex first, so acme is the last tenant opened
+	do("globex", "/api/health")
+	do("acme", "/api/health")
+
+	// 2040 is past the years prefetched at startup, so only the calendar loads it
+	for _, tt := range []struct {
+		tenant      string
+		wantAcmeDay bool
+	}{{"globex", false}, {"acme", true}} {
+		w := do(tt.tenant, "/api/calendar/2040")
+		var calendar models.CalendarResponse
+		if err := json.NewDecoder(w.Body).Decode(&calendar); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("%s's calendar: status %d, %v", tt.tenant, w.Code, err)
+		}
+		found := false
+		for _, holiday := range calendar.Holidays {
+			found = found || holiday.Name == "Acme Day"
+		}
+		if found != tt.wantAcmeDay {
+			t.Errorf("%s's calendar has Acme Day = %v, want %v", tt.tenant, found, tt.wantAcmeDay)
+		}
+	}
+
+	// globex stored what it fetched in its own database
+	db, err := database.Open(tenants.Path(dir, "globex"))
+	if err != nil {
+		t.Fatalf("open globex: %v", err)
+	}
+	defer db.Close()
+	var national, acme int
+	db.QueryRow(`SELECT COUNT(*), COALESCE(SUM(name = 'Acme Day'), 0) FROM holidays WHERE year = 2040 AND type = 'national'`).Scan(&national, &acme)
+	if national == 0 || acme != 0 {
+		t.Errorf("globex stored %d national holidays of 2040, %d of them Acme Day; want its own, without Acme Day", national, acme)
+	}
+}
@@ -0,0 +1,186 @@
+// Package auth protects token authentication against guessing: clients
+// presenting wrong tokens are slowed down after each failure and locked out
+// after too many.
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// MaxDelay caps the throttle between failed attempts
+const MaxDelay = 5 * time.Minute
+
+// Limits configures the protection
+type Limits struct {
+	MaxFailures int           // Failures within Window that lock a client out, 0 turns the protection off
+	Window      time.Duration // How long a failure counts
+	Lockout     time.Duration // How long a locked out client is rejected
+	Throttle    time.Duration // Wait after the second failure, doubled after each further one up to MaxDelay
+}
+
+// Enabled reports whether the limits protect anything
+func (l Limits) Enabled() bool {
+	return l.MaxFailures > 0
+}
+
+// delay returns the wait after the given number of failures. A single
+// failure, usually a typo, is not throttled.
+func (l Limits) delay(failures int) time.Duration {
+	if l.Throttle <= 0 || failures < 2 {
+		return 0
+	}
+	d := l.Throttle
+	for i := 2; i < failures && d < MaxDelay; i++ {
+		d *= 2
+	}
+	if d > MaxDelay {
+		d = MaxDelay
+	}
+	return d
+}
+
+// Lockout is a client currently locked out
+type Lockout struct {
+	Client string    `json:"client"`
+	Until  time.Time `json:"until"`
+}
+
+type client struct {
+	failures    []time.Time // Within the window, oldest first
+	lockedUntil time.Time
+}
+
+// Guard tracks failed authentication attempts per client in memory
+type Guard struct {
+	clients map[string]*client
+	mux     sync.Mutex
+	now     func() time.Time
+}
+
+// NewGuard creates a guard without recorded failures
+func NewGuard() *Guard {
+	return &Guard{
+		clients: make(map[string]*client),
+		now:     time.Now,
+	}
+}
+
+// Check returns how long a client must wait before its next attempt is
+// considered, and whether that is because it is locked out. A zero wait
+// lets the attempt through.
+func (g *Guard) Check(key string, limits Limits) (wait time.Duration, locked bool) {
+	if !limits.Enabled() {
+		return 0, false
+	}
+
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	c := g.clients[key]
+	if c == nil {
+		return 0, false
+	}
+	now := g.now()
+	if now.Before(c.lockedUntil) {
+		return c.lockedUntil.Sub(now), true
+	}
+	c.expire(now, limits.Window)
+	if n := len(c.failures); n > 0 {
+		if next := c.failures[n-1].Add(limits.delay(n)); now.Before(next) {
+			return next.Sub(now), false
+		}
+	}
+	return 0, false
+}
+
+// Fail records a failed attempt. It returns true when the failure locks the
+// client out.
+func (g *Guard) Fail(key string, limits Limits) bool {
+	if !limits.Enabled() {
+		return false
+	}
+
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	now := g.now()
+	c := g.clients[key]
+	if c == nil {
+		c = &client{}
+		g.clients[key] = c
+	}
+	c.expire(now, limits.Window)
+	c.failures = append(c.failures, now)
+	if len(c.failures) < limits.MaxFailures {
+		return false
+	}
+
+	c.lockedUntil = now.Add(limits.Lockout)
+	c.failures = nil
+	return true
+}
+
+// Succeed forgets the failures of a client that authenticated
+func (g *Guard) Succeed(key string) {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	delete(g.clients, key)
+}
+
+// Lockouts returns the clients currently locked out
+func (g *Guard) Lockouts() []Lockout {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	now := g.now()
+	lockouts := []Lockout{}
+	for key, c := range g.clients {
+		if now.Before(c.lockedUntil) {
+			lockouts = append(lockouts, Lockout{Client: key, Until: c.lockedUntil})
+		}
+	}
+	return lockouts
+}
+
+// Unlock lifts the lockout and forgets the failures of a client. It reports
+// whether the client had any.
+func (g *Guard) Unlock(key string) bool {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	_, ok := g.clients[key]
+	delete(g.clients, key)
+	return ok
+}
+
+// Prune forgets clients whose lockout ended and whose failures are older
+// than window, returning how many were dropped
+func (g *Guard) Prune(window time.Duration) int {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	now := g.now()
+	pruned := 0
+	for key, c := range g.clients {
+		if now.Before(c.lockedUntil) {
+			continue
+		}
+		c.expire(now, window)
+		if len(c.failures) == 0 {
+			delete(g.clients, key)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// expire drops the failures older than window
+func (c *client) expire(now time.Time, window time.Duration) {
+	i := 0
+	for i < len(c.failures) && now.Sub(c.failures[i]) >= window {
+		i++
+	}
+	c.failures = c.failures[i:]
+}
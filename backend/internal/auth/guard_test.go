@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGuard(t *testing.T) {
+	now := time.Date(2030, 6, 10, 9, 0, 0, 0, time.UTC)
+	g := NewGuard()
+	g.now = func() time.Time { return now }
+	limits := Limits{MaxFailures: 4, Window: 10 * time.Minute, Lockout: time.Hour, Throttle: time.Second}
+
+	// A single failure is let through, later ones wait 1s, 2s, ...
+	g.Fail("10.0.0.1", limits)
+	if wait, _ := g.Check("10.0.0.1", limits); wait != 0 {
+		t.Errorf("wait after one failure = %s, want none", wait)
+	}
+	g.Fail("10.0.0.1", limits)
+	if wait, locked := g.Check("10.0.0.1", limits); wait != time.Second || locked {
+		t.Errorf("wait after two failures = %s, locked %v, want 1s", wait, locked)
+	}
+	now = now.Add(time.Second)
+	if g.Fail("10.0.0.1", limits) {
+		t.Error("locked out after 3 failures, want 4")
+	}
+	if wait, _ := g.Check("10.0.0.1", limits); wait != 2*time.Second {
+		t.Errorf("wait after three failures = %s, want 2s", wait)
+	}
+	if wait, _ := g.Check("10.0.0.2", limits); wait != 0 {
+		t.Errorf("another client waits %s", wait)
+	}
+
+	now = now.Add(2 * time.Second)
+	if !g.Fail("10.0.0.1", limits) {
+		t.Fatal("not locked out after 4 failures")
+	}
+	if wait, locked := g.Check("10.0.0.1", limits); wait != time.Hour || !locked {
+		t.Errorf("Check = %s, %v, want locked out for an hour", wait, locked)
+	}
+	if lockouts := g.Lockouts(); len(lockouts) != 1 || lockouts[0].Client != "10.0.0.1" {
+		t.Errorf("Lockouts = %+v", lockouts)
+	}
+	if g.Prune(limits.Window) != 0 {
+		t.Error("pruned a client still locked out")
+	}
+
+	if !g.Unlock("10.0.0.1") {
+		t.Error("Unlock found no client")
+	}
+	if wait, _ := g.Check("10.0.0.1", limits); wait != 0 {
+		t.Errorf("wait after Unlock = %s", wait)
+	}
+
+	// Failures outside the window are forgotten
+	g.Fail("10.0.0.3", limits)
+	g.Fail("10.0.0.3", limits)
+	now = now.Add(limits.Window)
+	if wait, _ := g.Check("10.0.0.3", limits); wait != 0 {
+		t.Errorf("wait after the window = %s", wait)
+	}
+	if g.Prune(limits.Window) != 1 || g.Unlock("10.0.0.3") {
+		t.Error("Prune kept a client without recent failures")
+	}
+
+	// No limits, no protection
+	off := Limits{}
+	for i := 0; i < 10; i++ {
+		g.Fail("10.0.0.4", off)
+	}
+	if wait, _ := g.Check("10.0.0.4", off); wait != 0 {
+		t.Errorf("wait with the protection off = %s", wait)
+	}
+}
+
+func TestDelayIsCapped(t *testing.T) {
+	limits := Limits{Throttle: time.Minute}
+	if d := limits.delay(100); d != MaxDelay {
+		t.Errorf("delay(100) = %s, want %s", d, MaxDelay)
+	}
+}
@@ -0,0 +1,251 @@
+// Package backup snapshots the SQLite database with SQLite's online backup
+// API and keeps the snapshots in a local directory or an S3-compatible
+// bucket, pruned by count and age.
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Backup targets
+const (
+	TargetNone       = "none"
+	TargetFilesystem = "filesystem"
+	TargetS3         = "s3"
+)
+
+// DefaultPrefix starts the name of a backup outside multi-tenant mode
+const DefaultPrefix = "vacation-planner"
+
+// pagesPerStep is how much the backup API copies before letting writers in
+const pagesPerStep = 256
+
+// ErrNotFound is returned for a backup the target does not hold
+var ErrNotFound = errors.New("backup not found")
+
+// Object is a stored backup
+type Object struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Target stores backup files
+type Target interface {
+	// Put uploads the local file as name
+	Put(ctx context.Context, name, file string) error
+	// Get downloads name to the local file
+	Get(ctx context.Context, name, file string) error
+	// List returns the stored backups named with prefix, newest first
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// Delete removes name
+	Delete(ctx context.Context, name string) error
+}
+
+// Config selects and authenticates a target
+type Config struct {
+	Target    string
+	Dir       string // Directory of the filesystem target
+	Endpoint  string // S3 endpoint, AWS for the region when empty
+	Region    string
+	Bucket    string
+	Prefix    string // Key prefix in the bucket
+	AccessKey string
+	SecretKey string
+}
+
+// New returns the configured target
+func New(cfg Config) (Target, error) {
+	switch cfg.Target {
+	case TargetFilesystem:
+		if cfg.Dir == "" {
+			return nil, errors.New("backup_dir is not set")
+		}
+		return Dir{Path: cfg.Dir}, nil
+	case TargetS3:
+		return newS3(cfg)
+	case "", TargetNone:
+		return nil, errors.New("backups are not configured")
+	}
+	return nil, fmt.Errorf("unknown backup target %q", cfg.Target)
+}
+
+var (
+	// namePattern matches the names given by Name
+	namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*-\d{8}T\d{6}\.\d{3}Z\.db$`)
+	// stampPattern matches what follows the prefix in a name
+	stampPattern = regexp.MustCompile(`^\d{8}T\d{6}\.\d{3}Z\.db$`)
+)
+
+// Name returns the name of a backup taken at t. Names sort by time.
+func Name(prefix string, t time.Time) string {
+	return fmt.Sprintf("%s-%s.db", prefix, t.UTC().Format("20060102T150405.000Z"))
+}
+
+// ValidName returns an error unless name is a backup name, which also keeps
+// names from reaching outside a backup directory
+func ValidName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid backup name %q", name)
+	}
+	return nil
+}
+
+// Snapshot copies the database to a new file with the online backup API.
+// Writers are let in between steps, so the server keeps running; a write
+// during the copy makes SQLite restart it from a consistent state.
+func Snapshot(ctx context.Context, db *sql.DB, file string) error {
+	if _, err := os.Stat(file); err == nil {
+		return fmt.Errorf("%s already exists", file)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+
+	target, err := sql.Open("sqlite3", file)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	if err := copyDatabase(ctx, target, db); err != nil {
+		os.Remove(file)
+		return fmt.Errorf("backup failed: %w", err)
+	}
+	return nil
+}
+
+// Restore replaces the contents of the database with a backup file, after
+// checking it with Check. Open connections see the restored data.
+func Restore(ctx context.Context, db *sql.DB, file string) error {
+	if err := Check(file); err != nil {
+		return err
+	}
+
+	source, err := sql.Open("sqlite3", "file:"+file+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	if err := copyDatabase(ctx, db, source); err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	return nil
+}
+
+// Check verifies that a file is an intact vacation planner database
+func Check(file string) error {
+	if _, err := os.Stat(file); err != nil {
+		return err
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+file+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow(`PRAGMA integrity_check`).Scan(&result); err != nil {
+		return fmt.Errorf("%s is not a database: %w", file, err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("%s is corrupt: %s", file, result)
+	}
+
+	var tables int
+	db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('settings', 'year_config', 'vacation_days')`).Scan(&tables)
+	if tables != 3 {
+		return fmt.Errorf("%s is not a vacation planner database", file)
+	}
+	return nil
+}
+
+// copyDatabase copies the main database of source over the one of target
+func copyDatabase(ctx context.Context, target, source *sql.DB) error {
+	targetConn, err := target.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer targetConn.Close()
+	sourceConn, err := source.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer sourceConn.Close()
+
+	return targetConn.Raw(func(t interface{}) error {
+		return sourceConn.Raw(func(s interface{}) error {
+			to, ok := t.(*sqlite3.SQLiteConn)
+			from, ok2 := s.(*sqlite3.SQLiteConn)
+			if !ok || !ok2 {
+				return errors.New("not a SQLite connection")
+			}
+
+			b, err := to.Backup("main", from, "main")
+			if err != nil {
+				return err
+			}
+			for {
+				done, err := b.Step(pagesPerStep)
+				if err != nil {
+					b.Finish()
+					return err
+				}
+				if done {
+					return b.Finish()
+				}
+				select {
+				case <-ctx.Done():
+					b.Finish()
+					return ctx.Err()
+				case <-time.After(10 * time.Millisecond):
+				}
+			}
+		})
+	})
+}
+
+// Prune deletes the backups named with prefix beyond the newest keep, or
+// older than maxAge. A zero keep or maxAge leaves that limit off, and the
+// newest backup is always kept. It returns the names deleted.
+func Prune(ctx context.Context, target Target, prefix string, keep int, maxAge time.Duration, now time.Time) ([]string, error) {
+	objects, err := target.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Name > objects[j].Name })
+
+	var deleted []string
+	for i, o := range objects {
+		if i == 0 {
+			continue
+		}
+		if (keep > 0 && i >= keep) || (maxAge > 0 && now.Sub(o.CreatedAt) > maxAge) {
+			if err := target.Delete(ctx, o.Name); err != nil {
+				return deleted, err
+			}
+			deleted = append(deleted, o.Name)
+		}
+	}
+	return deleted, nil
+}
+
+// HasPrefix reports whether a stored name is a backup named with prefix.
+// Prefixes may contain dashes, so the rest must be the timestamp.
+func HasPrefix(name, prefix string) bool {
+	stamp, ok := strings.CutPrefix(name, prefix+"-")
+	return ok && stampPattern.MatchString(stamp)
+}
@@ -0,0 +1,107 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Dir keeps backups in a local directory
+type Dir struct {
+	Path string
+}
+
+// Put copies the file into the directory. It is written under a temporary
+// name and renamed, so a failed copy never looks like a backup.
+func (d Dir) Put(ctx context.Context, name, file string) error {
+	if err := ValidName(name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(d.Path, 0755); err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(d.Path, name+".tmp")
+	if err := copyFile(file, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(d.Path, name)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Get copies a backup out of the directory
+func (d Dir) Get(ctx context.Context, name, file string) error {
+	if err := ValidName(name); err != nil {
+		return err
+	}
+	source := filepath.Join(d.Path, name)
+	if _, err := os.Stat(source); errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return copyFile(source, file)
+}
+
+// List returns the backups in the directory, newest first
+func (d Dir) List(ctx context.Context, prefix string) ([]Object, error) {
+	entries, err := os.ReadDir(d.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Object{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	objects := []Object{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, Object{Name: entry.Name(), Size: info.Size(), CreatedAt: info.ModTime().UTC()})
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Name > objects[j].Name })
+	return objects, nil
+}
+
+// Delete removes a backup from the directory
+func (d Dir) Delete(ctx context.Context, name string) error {
+	if err := ValidName(name); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(d.Path, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func copyFile(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
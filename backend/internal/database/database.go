@@ -8,24 +8,42 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// Initialize creates a SQLite database connection
+// Initialize creates a SQLite database connection and migrates the schema
 func Initialize(dbPath string) (*sql.DB, error) {
-	// Ensure directory exists
-	dir := filepath.Dir(dbPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	db, err := Open(dbPath)
+	if err != nil {
 		return nil, err
 	}
 
-	db, err := sql.Open("sqlite3", dbPath)
-	if err != nil {
+	if err := Migrate(db); err != nil {
+		db.Close()
 		return nil, err
 	}
 
-	if err := createTables(db); err != nil {
+	return db, nil
+}
+
+// connectionParams are applied by the driver to every pooled connection:
+// WAL so readers never wait for the writer, a busy timeout instead of
+// immediate "database is locked" errors, foreign key enforcement, NORMAL
+// sync (safe with WAL, a crash loses at most the last commits) and a 16 MB
+// page cache
+const connectionParams = "_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=on&_synchronous=NORMAL&_cache_size=-16000"
+
+// Open creates a SQLite database connection without touching the schema
+func Open(dbPath string) (*sql.DB, error) {
+	// Ensure directory exists
+	dir := filepath.Dir(dbPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, err
 	}
 
-	return db, nil
+	return sql.Open("sqlite3", "file:"+dbPath+"?"+connectionParams)
+}
+
+// Migrate creates missing tables and columns and the default settings
+func Migrate(db *sql.DB) error {
+	return createTables(db)
 }
 
 func createTables(db *sql.DB) error {
@@ -48,6 +66,15 @@ func createTables(db *sql.DB) error {
 		optimization_strategy TEXT DEFAULT 'balanced',
 		work_week TEXT DEFAULT '["monday","tuesday","wednesday","thursday","friday"]',
 		optimizer_notes TEXT DEFAULT '',
+		align_school_breaks BOOLEAN DEFAULT FALSE,
+		accounting_mode TEXT DEFAULT 'days',
+		vacation_hours REAL DEFAULT 0,
+		working_hours TEXT DEFAULT '{}',
+		optional_holidays TEXT DEFAULT '[]',
+		scoring_weights TEXT DEFAULT '{}',
+		blackout_periods TEXT DEFAULT '[]',
+		avoid_periods TEXT DEFAULT '[]',
+		version INTEGER DEFAULT 1,
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	);
@@ -74,6 +101,40 @@ func createTables(db *sql.DB) error {
 		UNIQUE(year, date)
 	);
 
+	-- Dates whose vacation status can't change until unlocked
+	CREATE TABLE IF NOT EXISTS locked_dates (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		year INTEGER NOT NULL,
+		date TEXT NOT NULL,
+		reason TEXT NOT NULL DEFAULT '',
+		locked_by TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(year, date)
+	);
+
+	-- Named vacation plans per year; the active one lives in vacation_days/optimal_vacations
+	CREATE TABLE IF NOT EXISTS scenarios (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		year INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		is_active BOOLEAN DEFAULT FALSE,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(year, name)
+	);
+
+	-- Saved vacation days of inactive scenarios
+	CREATE TABLE IF NOT EXISTS scenario_days (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		scenario_id INTEGER NOT NULL,
+		date TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		note TEXT,
+		block_id INTEGER,
+		consecutive_days INTEGER,
+		UNIQUE(scenario_id, date, kind)
+	);
+
 	-- Portuguese holidays (can vary by year for some)
 	CREATE TABLE IF NOT EXISTS holidays (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -82,30 +143,424 @@ func createTables(db *sql.DB) error {
 		name TEXT NOT NULL,
 		type TEXT DEFAULT 'national',
 		location TEXT DEFAULT '',
+		fetched_at TEXT, -- RFC 3339 in UTC, when the holiday was last loaded from the APIs
+		source TEXT DEFAULT '', -- nager, calendarific or fallback
 		UNIQUE(year, date, type, location)
 	);
 
+	-- The holidays last returned for each year, stamped when they were added,
+	-- changed or removed, so clients can fetch only the changes
+	CREATE TABLE IF NOT EXISTS holiday_changes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		year INTEGER NOT NULL,
+		date TEXT NOT NULL,
+		type TEXT NOT NULL,
+		location TEXT NOT NULL DEFAULT '',
+		name TEXT NOT NULL,
+		observed_for TEXT NOT NULL DEFAULT '',
+		deleted BOOLEAN NOT NULL DEFAULT FALSE, -- a tombstone of a removed holiday
+		changed_at TEXT NOT NULL, -- UTC, fixed width so it compares as text
+		UNIQUE(year, date, type, location)
+	);
+
+	-- School holiday periods per district (calculated or custom)
+	CREATE TABLE IF NOT EXISTS school_holidays (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		year INTEGER NOT NULL,
+		district TEXT NOT NULL,
+		name TEXT NOT NULL,
+		start_date TEXT NOT NULL,
+		end_date TEXT NOT NULL,
+		source TEXT DEFAULT 'calculated',
+		UNIQUE(year, district, start_date)
+	);
+
 	-- Chat history for AI interactions
 	CREATE TABLE IF NOT EXISTS chat_history (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		year INTEGER NOT NULL,
 		role TEXT NOT NULL,
 		content TEXT NOT NULL,
+		model TEXT DEFAULT '', -- Model that answered, empty for the user's messages
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	);
 
+	-- Last AI suggestion per year and language, reused while its inputs are unchanged
+	CREATE TABLE IF NOT EXISTS ai_suggestions (
+		year INTEGER NOT NULL,
+		language TEXT NOT NULL,
+		input_hash TEXT NOT NULL,
+		suggestion TEXT NOT NULL,
+		model TEXT DEFAULT '', -- Model that answered
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (year, language)
+	);
+
+	-- Outgoing webhook calls, retried until delivered or out of attempts
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		url TEXT NOT NULL,
+		event TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT DEFAULT 'pending',
+		attempts INTEGER DEFAULT 0,
+		last_error TEXT DEFAULT '',
+		next_attempt_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Scheduled notifications that were already sent
+	CREATE TABLE IF NOT EXISTS notification_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		ref TEXT NOT NULL,
+		sent_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(kind, ref)
+	);
+
+	-- Notification settings of each user: an access token, or 0 for the
+	-- owner while the API is open
+	CREATE TABLE IF NOT EXISTS notification_preferences (
+		token_id INTEGER PRIMARY KEY,
+		channels TEXT NOT NULL DEFAULT '[]', -- JSON array of teams, email and push, empty for all
+		email TEXT NOT NULL DEFAULT '',
+		kinds TEXT NOT NULL DEFAULT '[]', -- JSON array of notification kinds, empty for all
+		quiet_start TEXT NOT NULL DEFAULT '', -- HH:MM
+		quiet_end TEXT NOT NULL DEFAULT '',
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Browser Web Push subscriptions
+	CREATE TABLE IF NOT EXISTS push_subscriptions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		endpoint TEXT NOT NULL UNIQUE,
+		p256dh TEXT NOT NULL,
+		auth TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Work weeks taking effect during a year (year_config.work_week applies until the first change)
+	CREATE TABLE IF NOT EXISTS work_week_changes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		year INTEGER NOT NULL,
+		effective_from TEXT NOT NULL,
+		work_week TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(year, effective_from)
+	);
+
+	-- Extra vacation days by completed years of service
+	CREATE TABLE IF NOT EXISTS seniority_rules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		years_of_service INTEGER NOT NULL UNIQUE,
+		extra_days INTEGER NOT NULL
+	);
+
+	-- Holidays worked as regular days, each one earns a compensation day
+	CREATE TABLE IF NOT EXISTS worked_holidays (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		year INTEGER NOT NULL,
+		date TEXT NOT NULL,
+		name TEXT NOT NULL,
+		note TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(year, date)
+	);
+
+	-- Corrections to the holidays the APIs return: a holiday moved to another
+	-- date or renamed, or suppressed when the employer doesn't observe it
+	CREATE TABLE IF NOT EXISTS holiday_overrides (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		year INTEGER NOT NULL,
+		date TEXT NOT NULL,
+		action TEXT NOT NULL CHECK (action IN ('correct', 'suppress')),
+		new_date TEXT NOT NULL DEFAULT '',
+		name TEXT NOT NULL DEFAULT '',
+		note TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(year, date)
+	);
+
+	-- Compensation day ledger: days off in lieu earned by working a non-work
+	-- day (credit) and taken as days off (spend). Worked holidays are credited
+	-- through worked_holidays instead.
+	CREATE TABLE IF NOT EXISTS comp_days (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		year INTEGER NOT NULL,
+		date TEXT NOT NULL,
+		kind TEXT NOT NULL CHECK (kind IN ('credit', 'spend')),
+		note TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(year, date, kind)
+	);
+
+	-- Days granted or deducted by hand, such as bonus days, purchased leave
+	-- and corrections. They feed the compensation pool.
+	CREATE TABLE IF NOT EXISTS balance_adjustments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		year INTEGER NOT NULL,
+		date TEXT NOT NULL,
+		days INTEGER NOT NULL, -- positive grants days, negative deducts them
+		kind TEXT NOT NULL CHECK (kind IN ('bonus', 'purchased', 'correction')),
+		reason TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Trips grouping the vacation days of a date range
+	CREATE TABLE IF NOT EXISTS trips (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		year INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		destination TEXT NOT NULL DEFAULT '',
+		start_date TEXT NOT NULL,
+		end_date TEXT NOT NULL,
+		notes TEXT NOT NULL DEFAULT '',
+		budget REAL NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Expenses of a trip, counted against its budget
+	CREATE TABLE IF NOT EXISTS trip_expenses (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		trip_id INTEGER NOT NULL,
+		date TEXT NOT NULL DEFAULT '',
+		description TEXT NOT NULL,
+		category TEXT NOT NULL DEFAULT '',
+		amount REAL NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Personal events that draw vacation to their date or keep it away
+	CREATE TABLE IF NOT EXISTS personal_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		year INTEGER NOT NULL,
+		date TEXT NOT NULL,
+		name TEXT NOT NULL,
+		notes TEXT NOT NULL DEFAULT '',
+		weight INTEGER NOT NULL DEFAULT 0, -- positive attracts vacation, negative repels it
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Household members planned around, such as children, and the days
+	-- their school or childcare is closed
+	CREATE TABLE IF NOT EXISTS family_members (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		school_district TEXT NOT NULL DEFAULT '', -- district whose school breaks apply, empty for none
+		plan_around INTEGER NOT NULL DEFAULT 1, -- whether the optimizer prefers their days off
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS family_closures (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		member_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		start_date TEXT NOT NULL,
+		end_date TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Standing booking rules and the vacation days they propose
+	CREATE TABLE IF NOT EXISTS booking_rules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		kind TEXT NOT NULL, -- bridge or max_consecutive_weeks
+		max_gap INTEGER NOT NULL DEFAULT 0,
+		holiday_weekdays TEXT NOT NULL DEFAULT '[]', -- JSON array of weekday names
+		max_weeks INTEGER NOT NULL DEFAULT 0,
+		auto_apply INTEGER NOT NULL DEFAULT 0,
+		enabled INTEGER NOT NULL DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS booking_proposals (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		year INTEGER NOT NULL,
+		date TEXT NOT NULL,
+		rule_id INTEGER NOT NULL,
+		rule_name TEXT NOT NULL,
+		reason TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'pending', -- pending, applied or dismissed
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(year, date)
+	);
+
+	-- Names and colors given to vacation blocks, by the block's first day
+	CREATE TABLE IF NOT EXISTS block_labels (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		year INTEGER NOT NULL,
+		date TEXT NOT NULL,
+		label TEXT NOT NULL DEFAULT '',
+		color TEXT NOT NULL DEFAULT '', -- #rrggbb, empty for the default
+		UNIQUE(year, date)
+	);
+
+	-- Suggested blocks turned down, whose days the optimizer leaves alone
+	CREATE TABLE IF NOT EXISTS declined_blocks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		year INTEGER NOT NULL,
+		start_date TEXT NOT NULL,
+		end_date TEXT NOT NULL,
+		dates TEXT NOT NULL DEFAULT '[]', -- JSON array of the excluded vacation days
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Threaded comments on vacation days and blocks
+	CREATE TABLE IF NOT EXISTS comments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		year INTEGER NOT NULL,
+		date TEXT NOT NULL, -- the day, or the first day of the block
+		target TEXT NOT NULL, -- day or block
+		parent_id INTEGER, -- the comment replied to, NULL for a thread's first comment
+		author TEXT NOT NULL DEFAULT '',
+		body TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Tokens for read-only links to a year's calendar
+	CREATE TABLE IF NOT EXISTS share_links (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		token TEXT NOT NULL UNIQUE,
+		year INTEGER NOT NULL,
+		label TEXT NOT NULL DEFAULT '',
+		expires_at TEXT, -- RFC 3339 in UTC, NULL for links that never expire
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS access_tokens (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		role TEXT NOT NULL, -- admin, manager, member or viewer
+		token_hash TEXT NOT NULL UNIQUE, -- hex SHA-256 of the token, which is only shown once
+		expires_at TEXT, -- RFC 3339 in UTC, NULL for tokens that never expire
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Failed and locked out access token attempts, token changes and sign-ins
+	CREATE TABLE IF NOT EXISTS auth_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event TEXT NOT NULL, -- failure, lockout, unlock, token_created, token_deleted, sso_login or sso_denied
+		client TEXT NOT NULL DEFAULT '', -- IP address of the client
+		token_name TEXT NOT NULL DEFAULT '',
+		detail TEXT NOT NULL DEFAULT '',
+		created_at TEXT NOT NULL -- RFC 3339 in UTC
+	);
+
+	-- Responses of requests sent with an Idempotency-Key, replayed to retries
+	CREATE TABLE IF NOT EXISTS idempotency_keys (
+		scope TEXT NOT NULL, -- Access token ID, empty while the API is open
+		key TEXT NOT NULL,
+		method TEXT NOT NULL,
+		path TEXT NOT NULL,
+		request_hash TEXT NOT NULL,
+		status INTEGER NOT NULL DEFAULT 0, -- 0 while the first request is running
+		content_type TEXT NOT NULL DEFAULT '',
+		body BLOB,
+		created_at TEXT NOT NULL, -- RFC 3339 in UTC
+		PRIMARY KEY (scope, key)
+	);
+
 	-- Insert default settings if not exist
 	INSERT OR IGNORE INTO settings (key, value) VALUES 
 		('openai_api_key', ''),
 		('ai_provider', 'github'),
 		('ai_model', 'openai/gpt-4o-mini'),
+		('ai_fallback_models', ''),
+		('ai_fallback_api_key', ''),
+		('ai_correction_rounds', '2'),
 		('backend_port', '8080'),
 		('frontend_port', '5173'),
+		('optimizer_time_budget_ms', '200'),
 		('default_work_week', '["monday","tuesday","wednesday","thursday","friday"]'),
 		('default_vacation_days', '22'),
 		('default_optimization_strategy', 'balanced'),
 		('work_city', ''),
-		('calendarific_api_key', '');
+		('school_district', ''),
+		('holiday_substitution', 'none'),
+		('holiday_prefetch_years', '2'),
+		('holiday_retry_max_retries', '5'),
+		('holiday_retry_base_seconds', '30'),
+		('holiday_retry_max_delay_seconds', '1800'),
+		('birthday', ''),
+		('birthday_day_off', 'none'),
+		('employment_start_date', ''),
+		('past_edit_protection', 'true'),
+		('past_edit_grace_days', '7'),
+		('timezone', ''),
+		('webhook_urls', ''),
+		('webhook_secret', ''),
+		('teams_webhook_url', ''),
+		('teams_weekly_digest', 'true'),
+		('teams_optimization_results', 'true'),
+		('smtp_host', ''),
+		('smtp_port', '587'),
+		('smtp_username', ''),
+		('smtp_password', ''),
+		('smtp_from', ''),
+		('notification_email', ''),
+		('email_vacation_reminders', 'true'),
+		('reminder_days_before', '3'),
+		('email_carryover_alerts', 'true'),
+		('carryover_expiry', '04-30'),
+		('email_unused_days_alerts', 'true'),
+		('unused_days_share', '30'),
+		('email_holiday_recovery', 'true'),
+		('email_monthly_digest', 'true'),
+		('vapid_subject', ''),
+		('push_holiday_failures', 'true'),
+		('push_vacation_start', 'true'),
+		('calendarific_api_key', ''),
+		('flight_price_provider', 'none'),
+		('flight_api_key', ''),
+		('flight_api_secret', ''),
+		('home_airport', ''),
+		('flight_destination', ''),
+		('google_sheets_credentials', ''),
+		('google_sheets_spreadsheet_id', ''),
+		('google_sheets_layout', 'block'),
+		('google_sheets_sync_on_change', 'false'),
+		('hr_provider', 'none'),
+		('hr_api_key', ''),
+		('hr_api_secret', ''),
+		('hr_company', ''),
+		('hr_employee_id', ''),
+		('oncall_provider', 'none'),
+		('oncall_api_key', ''),
+		('oncall_user', ''),
+		('oncall_schedule_id', ''),
+		('oncall_ics_url', ''),
+		('milestone_provider', 'none'),
+		('milestone_api_key', ''),
+		('milestone_user', ''),
+		('milestone_project', ''),
+		('milestone_url', ''),
+		('backup_target', 'none'),
+		('backup_schedule', '0 2 * * *'),
+		('backup_dir', './data/backups'),
+		('backup_s3_endpoint', ''),
+		('backup_s3_region', 'us-east-1'),
+		('backup_s3_bucket', ''),
+		('backup_s3_prefix', ''),
+		('backup_s3_access_key', ''),
+		('backup_s3_secret_key', ''),
+		('backup_keep_count', '14'),
+		('backup_keep_days', '30'),
+		('auth_max_failures', '10'),
+		('auth_failure_window_minutes', '15'),
+		('auth_lockout_minutes', '15'),
+		('auth_throttle_seconds', '1'),
+		('oidc_issuer', ''),
+		('oidc_client_id', ''),
+		('oidc_client_secret', ''),
+		('oidc_redirect_url', ''),
+		('oidc_groups_claim', 'groups'),
+		('oidc_role_mapping', ''),
+		('oidc_default_role', ''),
+		('oidc_session_hours', '12');
 	`
 
 	_, err := db.Exec(schema)
@@ -121,6 +576,31 @@ func createTables(db *sql.DB) error {
 		`ALTER TABLE year_config ADD COLUMN optimizer_notes TEXT DEFAULT '';`,
 		// Add location column to holidays if it doesn't exist
 		`ALTER TABLE holidays ADD COLUMN location TEXT DEFAULT '';`,
+		// Add align_school_breaks column if it doesn't exist
+		`ALTER TABLE year_config ADD COLUMN align_school_breaks BOOLEAN DEFAULT FALSE;`,
+		// Hours-based accounting for part-timers
+		`ALTER TABLE year_config ADD COLUMN accounting_mode TEXT DEFAULT 'days';`,
+		`ALTER TABLE year_config ADD COLUMN vacation_hours REAL DEFAULT 0;`,
+		`ALTER TABLE year_config ADD COLUMN working_hours TEXT DEFAULT '{}';`,
+		// Fetch time of stored holidays, shared with the in-memory cache
+		`ALTER TABLE holidays ADD COLUMN fetched_at TEXT;`,
+		// Company optional holidays (Carnaval, Christmas Eve) granted per year
+		`ALTER TABLE year_config ADD COLUMN optional_holidays TEXT DEFAULT '[]';`,
+		// Model that answered, which may be a fallback of the configured one
+		`ALTER TABLE chat_history ADD COLUMN model TEXT DEFAULT '';`,
+		`ALTER TABLE ai_suggestions ADD COLUMN model TEXT DEFAULT '';`,
+		// Weights of the custom optimization strategy
+		`ALTER TABLE year_config ADD COLUMN scoring_weights TEXT DEFAULT '{}';`,
+		// Date ranges closed to vacation
+		`ALTER TABLE year_config ADD COLUMN blackout_periods TEXT DEFAULT '[]';`,
+		// Version for optimistic concurrency, bumped by every change
+		`ALTER TABLE year_config ADD COLUMN version INTEGER DEFAULT 1;`,
+		// API each stored holiday came from
+		`ALTER TABLE holidays ADD COLUMN source TEXT DEFAULT '';`,
+		// Date ranges vacation should stay clear of, such as release weeks
+		`ALTER TABLE year_config ADD COLUMN avoid_periods TEXT DEFAULT '[]';`,
+		// Expiry of single sign-on session tokens
+		`ALTER TABLE access_tokens ADD COLUMN expires_at TEXT;`,
 	}
 
 	for _, migration := range migrations {
@@ -128,5 +608,30 @@ func createTables(db *sql.DB) error {
 		db.Exec(migration)
 	}
 
-	return nil
+	// Indexes for the lookups made on every request. They come after the
+	// migrations, which add some of the columns they cover. Lookups of
+	// vacation_days, optimal_vacations and locked_dates by (year, date) use
+	// the index of their UNIQUE(year, date) constraint.
+	indexes := []string{
+		`CREATE INDEX IF NOT EXISTS idx_holidays_year_type_location ON holidays(year, type, location);`,
+		`CREATE INDEX IF NOT EXISTS idx_holiday_changes_year_changed ON holiday_changes(year, changed_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_chat_history_year_created ON chat_history(year, created_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_trips_year ON trips(year, start_date);`,
+		`CREATE INDEX IF NOT EXISTS idx_balance_adjustments_year ON balance_adjustments(year, date);`,
+		`CREATE INDEX IF NOT EXISTS idx_personal_events_year ON personal_events(year, date);`,
+		`CREATE INDEX IF NOT EXISTS idx_trip_expenses_trip ON trip_expenses(trip_id, date);`,
+		`CREATE INDEX IF NOT EXISTS idx_comments_year_date ON comments(year, date);`,
+		`CREATE INDEX IF NOT EXISTS idx_declined_blocks_year ON declined_blocks(year);`,
+		`CREATE INDEX IF NOT EXISTS idx_auth_events_created ON auth_events(created_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_family_closures_member ON family_closures(member_id);`,
+	}
+	for _, index := range indexes {
+		if _, err := db.Exec(index); err != nil {
+			return err
+		}
+	}
+
+	// Refresh the query planner statistics the indexes rely on
+	_, err = db.Exec(`PRAGMA optimize;`)
+	return err
 }
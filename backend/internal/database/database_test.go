@@ -0,0 +1,53 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitialize(t *testing.T) {
+	db, err := Initialize(filepath.Join(t.TempDir(), "calendar.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	pragmas := map[string]string{"journal_mode": "wal", "foreign_keys": "1", "busy_timeout": "5000", "synchronous": "1"}
+	for pragma, want := range pragmas {
+		var got string
+		if err := db.QueryRow(`PRAGMA ` + pragma).Scan(&got); err != nil || got != want {
+			t.Errorf("PRAGMA %s = %q, %v, want %q", pragma, got, err, want)
+		}
+	}
+
+	// The lookups made on every calendar request use an index
+	queries := []string{
+		`SELECT date FROM vacation_days WHERE year = 2030`,
+		`SELECT date FROM optimal_vacations WHERE year = 2030`,
+		`SELECT date FROM holidays WHERE year = 2030 AND type = 'municipal' AND location = 'Lisboa'`,
+		`SELECT content FROM chat_history WHERE year = 2030 ORDER BY created_at DESC LIMIT 10`,
+	}
+	for _, query := range queries {
+		rows, err := db.Query(`EXPLAIN QUERY PLAN ` + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var plan []string
+		for rows.Next() {
+			var id, parent, unused int
+			var detail string
+			rows.Scan(&id, &parent, &unused, &detail)
+			plan = append(plan, detail)
+		}
+		rows.Close()
+		if joined := strings.Join(plan, "; "); !strings.Contains(joined, "INDEX") || strings.Contains(joined, "TEMP B-TREE") {
+			t.Errorf("%s: plan %q, want an index without sorting", query, joined)
+		}
+	}
+
+	// Migrating again is a no-op
+	if err := Migrate(db); err != nil {
+		t.Errorf("second Migrate: %v", err)
+	}
+}
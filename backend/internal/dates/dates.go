@@ -0,0 +1,52 @@
+// Package dates defines how the application handles calendar dates.
+//
+// Vacation days, holidays and every other day in the API are civil dates:
+// YYYY-MM-DD strings with no time of day and no time zone. In Go they are
+// represented as midnight UTC of that date, so they can be compared, added to
+// and formatted without daylight saving time shifting them to another day.
+//
+// Only "today" depends on where the user is. It is the current date in the
+// user's time zone (the timezone setting, or the server time zone when unset),
+// converted to a civil date so it compares correctly with parsed dates.
+package dates
+
+import (
+	"time"
+)
+
+// Layout is the format of a civil date
+const Layout = "2006-01-02"
+
+// Parse parses a YYYY-MM-DD date as midnight UTC
+func Parse(value string) (time.Time, error) {
+	return time.Parse(Layout, value)
+}
+
+// Format formats a civil date as YYYY-MM-DD
+func Format(t time.Time) string {
+	return t.Format(Layout)
+}
+
+// Civil returns the date of t in its own location, as midnight UTC
+func Civil(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Location loads an IANA time zone. An empty or unknown name falls back to
+// the server time zone.
+func Location(name string) *time.Location {
+	if name == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// Today returns the current date in loc, as midnight UTC
+func Today(loc *time.Location) time.Time {
+	return Civil(time.Now().In(loc))
+}
@@ -0,0 +1,50 @@
+package dates
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCivil(t *testing.T) {
+	// 23:30 on New Year's Eve in Lisbon is already New Year's Day in Tokyo
+	instant := time.Date(2025, time.December, 31, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		zone string
+		want string
+	}{
+		{"UTC", "2025-12-31"},
+		{"Europe/Lisbon", "2025-12-31"},
+		{"Asia/Tokyo", "2026-01-01"},
+		{"America/New_York", "2025-12-31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.zone, func(t *testing.T) {
+			got := Civil(instant.In(Location(tt.zone)))
+			if Format(got) != tt.want {
+				t.Errorf("Civil in %s = %s, want %s", tt.zone, Format(got), tt.want)
+			}
+			// Civil dates compare equal to parsed ones
+			parsed, err := Parse(tt.want)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.want, err)
+			}
+			if !got.Equal(parsed) {
+				t.Errorf("Civil in %s = %v, want %v", tt.zone, got, parsed)
+			}
+		})
+	}
+}
+
+func TestLocation(t *testing.T) {
+	if loc := Location(""); loc != time.Local {
+		t.Errorf("Location(\"\") = %v, want the server time zone", loc)
+	}
+	if loc := Location("Not/AZone"); loc != time.Local {
+		t.Errorf("Location of an unknown zone = %v, want the server time zone", loc)
+	}
+	if loc := Location("Europe/Lisbon"); loc.String() != "Europe/Lisbon" {
+		t.Errorf("Location(\"Europe/Lisbon\") = %v", loc)
+	}
+}
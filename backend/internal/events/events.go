@@ -0,0 +1,74 @@
+// Package events is a small in-process publish/subscribe bus used to notify
+// integrations (webhooks, ...) about changes to the calendar.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	VacationAdded         = "vacation.added"
+	VacationRemoved       = "vacation.removed"
+	VacationsCleared      = "vacation.cleared"
+	OptimizationCompleted = "optimization.completed"
+	OptimizationCleared   = "optimization.cleared"
+	ScenarioActivated     = "scenario.activated"
+	YearPurged            = "year.purged"
+	BackupRestored        = "backup.restored"
+	EditLockAcquired      = "editlock.acquired"
+	EditLockReleased      = "editlock.released"
+	HolidaysRefreshed     = "holidays.refreshed"
+	HolidaysRecovered     = "holidays.recovered"
+	HolidaysFailed        = "holidays.failed"
+	Ping                  = "ping"
+)
+
+// Event describes something that happened to a year's calendar
+type Event struct {
+	Type      string      `json:"type"`
+	Year      int         `json:"year"`
+	Data      interface{} `json:"data,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// Handler receives published events. Handlers are called synchronously and
+// should hand off slow work.
+type Handler func(Event)
+
+// Bus dispatches events to subscribers
+type Bus struct {
+	handlers []Handler
+	mu       sync.RWMutex
+}
+
+// NewBus creates an event bus
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers a handler for all events
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish sends an event to all subscribers
+func (b *Bus) Publish(eventType string, year int, data interface{}) {
+	event := Event{
+		Type:      eventType,
+		Year:      year,
+		Data:      data,
+		Timestamp: time.Now().UTC(),
+	}
+
+	b.mu.RLock()
+	handlers := append([]Handler(nil), b.handlers...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
@@ -0,0 +1,129 @@
+// Package flights looks up indicative round-trip flight prices, used to
+// compare vacation blocks that are otherwise equally efficient.
+package flights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/sandbox"
+)
+
+// Flight price providers
+const (
+	ProviderNone    = "none"
+	ProviderAmadeus = "amadeus"
+	ProviderKiwi    = "kiwi"
+)
+
+// ErrNoOffers is returned when a provider has no flights for a route and dates
+var ErrNoOffers = errors.New("no flight offers found")
+
+var airportRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Quote is the cheapest round-trip offer found for a route and dates
+type Quote struct {
+	Origin      string
+	Destination string
+	DepartDate  string
+	ReturnDate  string
+	Price       float64
+	Currency    string
+	Link        string // Booking page, when the provider has one
+}
+
+// Provider looks up round-trip prices
+type Provider interface {
+	RoundTrip(ctx context.Context, origin, destination string, depart, ret time.Time) (Quote, error)
+}
+
+// Config selects and authenticates a provider
+type Config struct {
+	Provider  string
+	APIKey    string
+	APISecret string // Amadeus only
+}
+
+// NormalizeAirport returns an IATA airport or city code in upper case, or an
+// error when it is not three letters
+func NormalizeAirport(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !airportRegex.MatchString(code) {
+		return "", fmt.Errorf("invalid airport code %q, use a three-letter IATA code", code)
+	}
+	return code, nil
+}
+
+// New returns the configured provider. In sandbox mode every provider is
+// replaced by deterministic fake prices.
+func New(cfg Config) (Provider, error) {
+	if cfg.Provider == "" || cfg.Provider == ProviderNone {
+		return nil, errors.New("flight prices are not configured")
+	}
+	if sandbox.Enabled() {
+		return sandboxProvider{}, nil
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	switch cfg.Provider {
+	case ProviderAmadeus:
+		if cfg.APIKey == "" || cfg.APISecret == "" {
+			return nil, errors.New("amadeus needs an API key and secret")
+		}
+		return newAmadeus(client, cfg.APIKey, cfg.APISecret), nil
+	case ProviderKiwi:
+		if cfg.APIKey == "" {
+			return nil, errors.New("kiwi needs an API key")
+		}
+		return &kiwi{client: client, apiKey: cfg.APIKey}, nil
+	}
+	return nil, fmt.Errorf("unknown flight price provider %q", cfg.Provider)
+}
+
+type cacheEntry struct {
+	quote   Quote
+	err     error
+	expires time.Time
+}
+
+// Cache keeps quotes for a while, so reloading a page does not spend the
+// provider's request quota. Routes without offers are cached too.
+type Cache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewCache creates a quote cache whose entries live for ttl
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+// RoundTrip returns a cached quote, asking the provider when there is none.
+// The provider name is part of the key, so switching providers starts afresh.
+func (c *Cache) RoundTrip(ctx context.Context, name string, p Provider, origin, destination string, depart, ret time.Time) (Quote, error) {
+	key := strings.Join([]string{name, origin, destination, depart.Format("2006-01-02"), ret.Format("2006-01-02")}, "|")
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.quote, entry.err
+	}
+
+	quote, err := p.RoundTrip(ctx, origin, destination, depart, ret)
+	if err != nil && !errors.Is(err, ErrNoOffers) {
+		return quote, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = cacheEntry{quote: quote, err: err, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return quote, err
+}
@@ -0,0 +1,53 @@
+package flights
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type countingProvider struct {
+	calls int
+	err   error
+}
+
+func (p *countingProvider) RoundTrip(ctx context.Context, origin, destination string, depart, ret time.Time) (Quote, error) {
+	p.calls++
+	return Quote{Origin: origin, Destination: destination, Price: 100}, p.err
+}
+
+func TestCache(t *testing.T) {
+	cache := NewCache(time.Hour)
+	depart := time.Date(2030, 5, 1, 0, 0, 0, 0, time.UTC)
+	ret := depart.AddDate(0, 0, 4)
+
+	p := &countingProvider{}
+	for i := 0; i < 2; i++ {
+		if _, err := cache.RoundTrip(context.Background(), ProviderKiwi, p, "LIS", "FNC", depart, ret); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if p.calls != 1 {
+		t.Errorf("provider called %d times, want 1", p.calls)
+	}
+
+	// Routes without offers are remembered too
+	empty := &countingProvider{err: ErrNoOffers}
+	for i := 0; i < 2; i++ {
+		cache.RoundTrip(context.Background(), ProviderKiwi, empty, "LIS", "XYZ", depart, ret)
+	}
+	if empty.calls != 1 {
+		t.Errorf("provider without offers called %d times, want 1", empty.calls)
+	}
+}
+
+func TestNormalizeAirport(t *testing.T) {
+	if code, err := NormalizeAirport(" lis "); err != nil || code != "LIS" {
+		t.Errorf("NormalizeAirport(lis) = %q, %v", code, err)
+	}
+	for _, code := range []string{"", "LI", "LISB", "L1S"} {
+		if _, err := NormalizeAirport(code); err == nil {
+			t.Errorf("NormalizeAirport(%q) accepted", code)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package flights
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const kiwiURL = "https://api.tequila.kiwi.com/v2/search"
+
+// kiwi looks up prices with the Kiwi.com Tequila search API
+type kiwi struct {
+	client *http.Client
+	apiKey string
+}
+
+type kiwiResponse struct {
+	Currency string `json:"currency"`
+	Data     []struct {
+		Price    float64 `json:"price"`
+		DeepLink string  `json:"deep_link"`
+	} `json:"data"`
+}
+
+// RoundTrip returns the cheapest offer for one adult, with its booking link
+func (k *kiwi) RoundTrip(ctx context.Context, origin, destination string, depart, ret time.Time) (Quote, error) {
+	// Tequila takes dates as dd/mm/yyyy ranges, a single day here
+	params := url.Values{
+		"fly_from":    {origin},
+		"fly_to":      {destination},
+		"date_from":   {depart.Format("02/01/2006")},
+		"date_to":     {depart.Format("02/01/2006")},
+		"return_from": {ret.Format("02/01/2006")},
+		"return_to":   {ret.Format("02/01/2006")},
+		"adults":      {"1"},
+		"curr":        {"EUR"},
+		"sort":        {"price"},
+		"limit":       {"1"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, kiwiURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return Quote{}, err
+	}
+	req.Header.Set("apikey", k.apiKey)
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return Quote{}, fmt.Errorf("failed to fetch flight offers: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Quote{}, fmt.Errorf("Kiwi API returned status %d", resp.StatusCode)
+	}
+
+	var result kiwiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Quote{}, fmt.Errorf("failed to parse flight offers: %w", err)
+	}
+
+	quote := Quote{
+		Origin:      origin,
+		Destination: destination,
+		DepartDate:  depart.Format("2006-01-02"),
+		ReturnDate:  ret.Format("2006-01-02"),
+	}
+	if len(result.Data) == 0 {
+		return quote, ErrNoOffers
+	}
+
+	quote.Price = result.Data[0].Price
+	quote.Currency = result.Currency
+	quote.Link = result.Data[0].DeepLink
+	return quote, nil
+}
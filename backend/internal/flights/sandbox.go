@@ -0,0 +1,34 @@
+package flights
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"time"
+)
+
+// sandboxProvider makes up prices in sandbox mode. The same route and dates
+// always cost the same; summer, Christmas and longer trips cost more.
+type sandboxProvider struct{}
+
+func (sandboxProvider) RoundTrip(ctx context.Context, origin, destination string, depart, ret time.Time) (Quote, error) {
+	hash := fnv.New32a()
+	hash.Write([]byte(origin + destination + depart.Format("2006-01-02") + ret.Format("2006-01-02")))
+
+	price := 60 + float64(hash.Sum32()%140)
+	switch depart.Month() {
+	case time.July, time.August, time.December:
+		price *= 1.5
+	}
+	nights := int(ret.Sub(depart).Hours() / 24)
+	price += float64(nights) * 4
+
+	return Quote{
+		Origin:      origin,
+		Destination: destination,
+		DepartDate:  depart.Format("2006-01-02"),
+		ReturnDate:  ret.Format("2006-01-02"),
+		Price:       math.Round(price*100) / 100,
+		Currency:    "EUR",
+	}, nil
+}
@@ -0,0 +1,125 @@
+// Package health serves the liveness and readiness probes used by
+// orchestrators such as Kubernetes. The probes answer as soon as the process
+// listens, while startup (migrations, settings) is still running.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// checkTimeout bounds each readiness check, so a stuck database does not
+// hang the probe
+const checkTimeout = 2 * time.Second
+
+type check struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Checker tracks whether the application is ready to serve traffic. It
+// serves /healthz and /readyz itself and passes every other request to the
+// application handler once it is set.
+type Checker struct {
+	mu     sync.RWMutex
+	checks []check
+	app    atomic.Value // http.Handler
+}
+
+// New creates a checker with no checks
+func New() *Checker {
+	return &Checker{}
+}
+
+// Add registers a readiness check
+func (c *Checker) Add(name string, fn func(ctx context.Context) error) {
+	c.mu.Lock()
+	c.checks = append(c.checks, check{name: name, fn: fn})
+	c.mu.Unlock()
+}
+
+// Pending registers a readiness check for a startup step. It fails until the
+// returned function is called.
+func (c *Checker) Pending(name string) func() {
+	var done atomic.Bool
+	c.Add(name, func(ctx context.Context) error {
+		if !done.Load() {
+			return errors.New("not done yet")
+		}
+		return nil
+	})
+	return func() { done.Store(true) }
+}
+
+// SetApp sets the handler for requests other than the probes
+func (c *Checker) SetApp(app http.Handler) {
+	c.app.Store(app)
+}
+
+// ServeHTTP implements http.Handler
+func (c *Checker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/healthz":
+		// The process is alive as long as it answers
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	case "/readyz":
+		c.serveReady(w, r)
+	default:
+		app, _ := c.app.Load().(http.Handler)
+		if app == nil {
+			writeProblem(w, http.StatusServiceUnavailable, models.CodeUnavailable, "Server is starting")
+			return
+		}
+		app.ServeHTTP(w, r)
+	}
+}
+
+// serveReady runs every check and answers 503 if any fails
+func (c *Checker) serveReady(w http.ResponseWriter, r *http.Request) {
+	c.mu.RLock()
+	checks := make([]check, len(c.checks))
+	copy(checks, c.checks)
+	c.mu.RUnlock()
+
+	results := make(map[string]string, len(checks))
+	ready := true
+	for _, ch := range checks {
+		ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+		err := ch.fn(ctx)
+		cancel()
+
+		if err != nil {
+			results[ch.name] = err.Error()
+			ready = false
+		} else {
+			results[ch.name] = "ok"
+		}
+	}
+
+	status := http.StatusOK
+	state := "ready"
+	if !ready {
+		status = http.StatusServiceUnavailable
+		state = "not ready"
+	}
+	writeJSON(w, status, map[string]interface{}{"status": state, "checks": results})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeProblem(w http.ResponseWriter, status int, code, detail string) {
+	w.Header().Set("Content-Type", models.ProblemContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(models.NewProblem(status, code, detail))
+}
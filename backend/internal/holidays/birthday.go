@@ -0,0 +1,113 @@
+package holidays
+
+import (
+	"strings"
+	"time"
+)
+
+// Birthday day off rules
+const (
+	BirthdayNone = "none"
+	BirthdayDay  = "birthday"      // The birthday itself, or the next work day
+	BirthdayWeek = "birthday_week" // The last work day of the birthday week, for a long weekend
+)
+
+// HolidayTypeBirthday marks the generated birthday day off. It is not
+// deducted from the vacation balance.
+const HolidayTypeBirthday = "birthday"
+
+// AddBirthday adds the birthday day off for a year to a holiday list.
+// birthday is "MM-DD" (a full "YYYY-MM-DD" date is accepted too) and
+// isWorkDay tells which days are in the work week. The list is returned
+// unchanged when the rule is off or no work day is free.
+func AddBirthday(holidayList []PortugueseHoliday, year int, birthday, rule string, isWorkDay func(time.Time) bool) []PortugueseHoliday {
+	if rule == "" || rule == BirthdayNone || birthday == "" {
+		return holidayList
+	}
+
+	date, ok := birthdayInYear(year, birthday)
+	if !ok {
+		return holidayList
+	}
+
+	taken := make(map[string]bool)
+	for _, h := range holidayList {
+		taken[h.Date] = true
+	}
+	isFree := func(d time.Time) bool {
+		return d.Year() == year && isWorkDay(d) && !taken[d.Format("2006-01-02")]
+	}
+
+	var dayOff time.Time
+	if rule == BirthdayWeek {
+		// Walk back from Friday to the first free day of the week (Monday)
+		monday := date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+		for d := monday.AddDate(0, 0, 4); !d.Before(monday); d = d.AddDate(0, 0, -1) {
+			if isFree(d) {
+				dayOff = d
+				break
+			}
+		}
+	}
+
+	if dayOff.IsZero() {
+		// The birthday or the next free day, looking back if the year ends first
+		for i := 0; i < 14 && dayOff.IsZero(); i++ {
+			if d := date.AddDate(0, 0, i); isFree(d) {
+				dayOff = d
+			}
+		}
+		for i := 1; i < 14 && dayOff.IsZero(); i++ {
+			if d := date.AddDate(0, 0, -i); isFree(d) {
+				dayOff = d
+			}
+		}
+	}
+
+	if dayOff.IsZero() {
+		return holidayList
+	}
+
+	return append(holidayList, PortugueseHoliday{
+		Date:   dayOff.Format("2006-01-02"),
+		Name:   "Birthday",
+		Type:   HolidayTypeBirthday,
+		Source: SourceCustom,
+	})
+}
+
+// birthdayInYear returns the birthday date in a year. February 29 falls on
+// February 28 in common years.
+func birthdayInYear(year int, birthday string) (time.Time, bool) {
+	parts := strings.Split(birthday, "-")
+	if len(parts) == 3 {
+		parts = parts[1:]
+	}
+	if len(parts) != 2 {
+		return time.Time{}, false
+	}
+
+	date, err := time.Parse("01-02", parts[0]+"-"+parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	day := date.Day()
+	if date.Month() == time.February && day == 29 && !isLeapYear(year) {
+		day = 28
+	}
+	return time.Date(year, date.Month(), day, 0, 0, 0, 0, time.UTC), true
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// GetBirthdayRules returns the available birthday day off rules
+func GetBirthdayRules() []map[string]string {
+	return []map[string]string{
+		{"id": BirthdayNone, "name": "None", "description": "No birthday day off"},
+		{"id": BirthdayDay, "name": "Birthday", "description": "Day off on the birthday, or the next work day"},
+		{"id": BirthdayWeek, "name": "Birthday week", "description": "Day off on the last work day of the birthday week"},
+	}
+}
@@ -0,0 +1,249 @@
+package holidays
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Freshness of fetched holidays, shared by the in-memory cache and the
+// holidays table of HolidayService so both refetch at the same time
+const (
+	// FreshFor is how long fetched holidays are used without asking the
+	// APIs again
+	FreshFor = 24 * time.Hour
+
+	// StaleFor is how long holidays are still served after FreshFor, while
+	// a background fetch revalidates them
+	StaleFor = 7 * 24 * time.Hour
+
+	// retryStaleAfter is how soon a failed revalidation, or a fallback list
+	// cached while the APIs are down, is tried again
+	retryStaleAfter = 15 * time.Minute
+
+	// maxCacheEntries bounds the in-memory cache, which has one entry per
+	// year and city
+	maxCacheEntries = 64
+)
+
+// holidayCache keeps the holidays of each year and city in memory, in
+// front of an optional store. Each HolidayService has its own, backed by its
+// database; calls without one share defaultCache, which only has the APIs.
+type holidayCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedHolidays // key: "year" or "year:city"
+	store   holidayStore               // Nil when holidays are not persisted
+}
+
+// defaultCache serves GetPortugueseHolidaysWithCity
+var defaultCache = newHolidayCache(nil)
+
+func newHolidayCache(store holidayStore) *holidayCache {
+	return &holidayCache{entries: make(map[string]*cachedHolidays), store: store}
+}
+
+// holidayStore keeps fetched holidays across restarts, so a cache miss does
+// not have to call the APIs when stored holidays are still fresh
+type holidayStore interface {
+	// storedHolidays returns the stored holidays of a year for a city and
+	// when the oldest of them was fetched
+	storedHolidays(year int, city string) ([]PortugueseHoliday, time.Time, bool)
+	// saveFetched stores what was loaded from the APIs
+	saveFetched(year int, fetched fetchedHolidays)
+}
+
+type cachedHolidays struct {
+	holidays     []PortugueseHoliday
+	fetchedAt    time.Time
+	checkAt      time.Time // When the entry goes stale and is fetched again
+	revalidating bool
+}
+
+// expired reports whether the entry is past the stale window and can no
+// longer be served
+func (c *cachedHolidays) expired(now time.Time) bool {
+	return now.Sub(c.fetchedAt) > FreshFor+StaleFor
+}
+
+func cacheKey(year int, city string) string {
+	if city == "" {
+		return fmt.Sprintf("%d", year)
+	}
+	return fmt.Sprintf("%d:%s", year, city)
+}
+
+// get returns the holidays of a year for a city. Fresh entries are served
+// as they are; stale ones are served while a single background fetch
+// revalidates them; missing or expired ones are loaded right away.
+func (c *holidayCache) get(year int, city string) []PortugueseHoliday {
+	key := cacheKey(year, city)
+	now := time.Now()
+
+	c.mu.Lock()
+	if cached, found := c.entries[key]; found && !cached.expired(now) {
+		if now.After(cached.checkAt) && !cached.revalidating {
+			cached.revalidating = true
+			go c.revalidate(key, year, city)
+		}
+		holidays := cached.holidays
+		c.mu.Unlock()
+		return holidays
+	}
+	c.mu.Unlock()
+
+	cached := c.load(year, city, now)
+	c.put(key, cached)
+	return cached.holidays
+}
+
+// load loads holidays on a cache miss: from the store when they are still
+// fresh there, otherwise from the APIs. Stored holidays are preferred over
+// the calculated fallback when the APIs fail.
+func (c *holidayCache) load(year int, city string, now time.Time) *cachedHolidays {
+	var stored []PortugueseHoliday
+	if c.store != nil {
+		if holidays, fetchedAt, ok := c.store.storedHolidays(year, city); ok {
+			if now.Sub(fetchedAt) <= FreshFor {
+				return &cachedHolidays{holidays: holidays, fetchedAt: fetchedAt, checkAt: fetchedAt.Add(FreshFor)}
+			}
+			stored = holidays
+		}
+	}
+
+	fetched := fetchHolidays(year, city)
+	if c.store != nil {
+		c.store.saveFetched(year, fetched)
+	}
+
+	if fetched.complete(city) {
+		return &cachedHolidays{holidays: fetched.forCity(city), fetchedAt: now, checkAt: now.Add(FreshFor)}
+	}
+	if stored != nil {
+		return &cachedHolidays{holidays: stored, fetchedAt: now, checkAt: now.Add(retryStaleAfter)}
+	}
+	return &cachedHolidays{holidays: fetched.forCity(city), fetchedAt: now, checkAt: now.Add(retryStaleAfter)}
+}
+
+// revalidate fetches a stale entry again. The entry is only replaced when
+// everything loaded, so a failing API never swaps good data for the fallback.
+func (c *holidayCache) revalidate(key string, year int, city string) {
+	fetched := fetchHolidays(year, city)
+	if c.store != nil {
+		c.store.saveFetched(year, fetched)
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, found := c.entries[key]
+	if !found {
+		// Cleared while fetching
+		return
+	}
+	if fetched.complete(city) {
+		c.entries[key] = &cachedHolidays{holidays: fetched.forCity(city), fetchedAt: now, checkAt: now.Add(FreshFor)}
+		return
+	}
+
+	log.Printf("Holidays for %s could not be revalidated, serving the cached ones", key)
+	cached.revalidating = false
+	cached.checkAt = now.Add(retryStaleAfter)
+}
+
+// put stores an entry, evicting the oldest entries when the cache is full
+func (c *holidayCache) put(key string, cached *cachedHolidays) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, found := c.entries[key]; !found {
+		for len(c.entries) >= maxCacheEntries {
+			c.evictOldest()
+		}
+	}
+	c.entries[key] = cached
+}
+
+// evictOldest drops the entry fetched longest ago. The caller holds the lock.
+func (c *holidayCache) evictOldest() {
+	var oldestKey string
+	var oldest time.Time
+	for key, cached := range c.entries {
+		if oldestKey == "" || cached.fetchedAt.Before(oldest) {
+			oldestKey, oldest = key, cached.fetchedAt
+		}
+	}
+	delete(c.entries, oldestKey)
+}
+
+// clear drops every entry
+func (c *holidayCache) clear() {
+	c.mu.Lock()
+	c.entries = make(map[string]*cachedHolidays)
+	c.mu.Unlock()
+}
+
+// prune drops entries past the stale window and returns how many were dropped
+func (c *holidayCache) prune() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	pruned := 0
+	for key, cached := range c.entries {
+		if cached.expired(now) {
+			delete(c.entries, key)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// clearYear drops the entries of a year, for every city
+func (c *holidayCache) clearYear(year int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	yearPrefix := fmt.Sprintf("%d", year)
+	for key := range c.entries {
+		if key == yearPrefix || strings.HasPrefix(key, yearPrefix+":") {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// clearCity drops the entries of a city, for every year
+func (c *holidayCache) clearCity(city string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	suffix := ":" + city
+	for key := range c.entries {
+		if strings.HasSuffix(key, suffix) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// ClearCache clears the shared holiday cache (useful for testing or forcing refresh)
+func ClearCache() {
+	defaultCache.clear()
+}
+
+// PruneCache drops entries past the stale window from the shared holiday
+// cache and returns how many were dropped
+func PruneCache() int {
+	return defaultCache.prune()
+}
+
+// ClearCacheForYear clears the shared holiday cache for a specific year
+func ClearCacheForYear(year int) {
+	defaultCache.clearYear(year)
+}
+
+// ClearCacheForCity clears the shared holiday cache entries of a city, for every year
+func ClearCacheForCity(city string) {
+	defaultCache.clearCity(city)
+}
@@ -0,0 +1,165 @@
+package holidays
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/sandbox"
+)
+
+func TestMain(m *testing.M) {
+	// Fetches return the canned sandbox holidays instead of calling the APIs
+	sandbox.Enable()
+	os.Exit(m.Run())
+}
+
+// fakeStore is an in-memory holidayStore
+type fakeStore struct {
+	mu        sync.Mutex
+	holidays  []PortugueseHoliday
+	fetchedAt time.Time
+	saved     int
+}
+
+func (f *fakeStore) storedHolidays(year int, city string) ([]PortugueseHoliday, time.Time, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.holidays, f.fetchedAt, f.holidays != nil
+}
+
+func (f *fakeStore) saveFetched(year int, fetched fetchedHolidays) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.saved++
+}
+
+func (f *fakeStore) saves() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.saved
+}
+
+var marker = []PortugueseHoliday{{Date: "2030-01-02", Name: "Cached", Type: "national"}}
+
+func isMarker(holidays []PortugueseHoliday) bool {
+	return len(holidays) == 1 && holidays[0].Name == "Cached"
+}
+
+func TestCacheFreshness(t *testing.T) {
+	tests := []struct {
+		name           string
+		age            time.Duration
+		wantCached     bool // The cached entry is served
+		wantRevalidate bool // and replaced in the background
+	}{
+		{"fresh", time.Hour, true, false},
+		{"stale", FreshFor + time.Hour, true, true},
+		{"expired", FreshFor + StaleFor + time.Hour, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newHolidayCache(nil)
+			fetchedAt := time.Now().Add(-tt.age)
+			cache.put(cacheKey(2030, ""), &cachedHolidays{holidays: marker, fetchedAt: fetchedAt, checkAt: fetchedAt.Add(FreshFor)})
+
+			got := cache.get(2030, "")
+			if isMarker(got) != tt.wantCached {
+				t.Fatalf("served cached entry = %v, want %v", isMarker(got), tt.wantCached)
+			}
+
+			if tt.wantRevalidate {
+				deadline := time.Now().Add(2 * time.Second)
+				for isMarker(cache.get(2030, "")) {
+					if time.Now().After(deadline) {
+						t.Fatal("stale entry was not revalidated")
+					}
+					time.Sleep(10 * time.Millisecond)
+				}
+			} else if tt.wantCached {
+				time.Sleep(20 * time.Millisecond)
+				if !isMarker(cache.get(2030, "")) {
+					t.Error("fresh entry was refetched")
+				}
+			}
+		})
+	}
+}
+
+func TestCacheSizeBound(t *testing.T) {
+	cache := newHolidayCache(nil)
+
+	now := time.Now()
+	for i := 0; i < maxCacheEntries+5; i++ {
+		fetchedAt := now.Add(time.Duration(i) * time.Minute)
+		cache.put(cacheKey(2000+i, ""), &cachedHolidays{holidays: marker, fetchedAt: fetchedAt, checkAt: fetchedAt.Add(FreshFor)})
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if len(cache.entries) != maxCacheEntries {
+		t.Errorf("cache holds %d entries, want %d", len(cache.entries), maxCacheEntries)
+	}
+	// The oldest entries are the ones evicted
+	for i := 0; i < 5; i++ {
+		if _, found := cache.entries[cacheKey(2000+i, "")]; found {
+			t.Errorf("entry for %d was kept, want it evicted", 2000+i)
+		}
+	}
+}
+
+func TestCacheStore(t *testing.T) {
+	tests := []struct {
+		name       string
+		storedAge  time.Duration
+		wantStored bool // The stored holidays are served
+		wantSaves  int  // Fetches written back to the store
+	}{
+		{"fresh in store", time.Hour, true, 0},
+		{"stale in store", FreshFor + time.Hour, false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{holidays: marker, fetchedAt: time.Now().Add(-tt.storedAge)}
+			cache := newHolidayCache(store)
+
+			got := cache.get(2030, "")
+			if isMarker(got) != tt.wantStored {
+				t.Errorf("served stored holidays = %v, want %v", isMarker(got), tt.wantStored)
+			}
+			if store.saves() != tt.wantSaves {
+				t.Errorf("store saved %d times, want %d", store.saves(), tt.wantSaves)
+			}
+
+			// Both layers agree on when the holidays go stale
+			cache.mu.Lock()
+			cached := cache.entries[cacheKey(2030, "")]
+			cache.mu.Unlock()
+			if tt.wantStored && !cached.fetchedAt.Equal(store.fetchedAt) {
+				t.Errorf("cache entry fetched at %v, want the stored %v", cached.fetchedAt, store.fetchedAt)
+			}
+		})
+	}
+}
+
+func TestClearCacheForCity(t *testing.T) {
+	cache := newHolidayCache(nil)
+
+	now := time.Now()
+	for _, key := range []string{"2030", "2030:Lisboa", "2031:Lisboa", "2030:Porto"} {
+		cache.put(key, &cachedHolidays{holidays: marker, fetchedAt: now, checkAt: now.Add(FreshFor)})
+	}
+
+	cache.clearCity("Lisboa")
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	for key, wantKept := range map[string]bool{"2030": true, "2030:Lisboa": false, "2031:Lisboa": false, "2030:Porto": true} {
+		if _, found := cache.entries[key]; found != wantKept {
+			t.Errorf("entry %s kept = %v, want %v", key, found, wantKept)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package holidays
+
+// Company optional holidays: days many Portuguese employers give off although
+// they are not legal holidays. Each year enables the ones its employer grants.
+const (
+	OptionalCarnaval     = "carnaval"
+	OptionalChristmasEve = "christmas_eve"
+	OptionalNewYearsEve  = "new_years_eve"
+)
+
+// HolidayTypeOptional marks an enabled company optional holiday. Like the
+// legal holidays it is not deducted from the vacation balance.
+const HolidayTypeOptional = "optional"
+
+// OptionalHoliday is a company optional holiday of a year
+type OptionalHoliday struct {
+	Key  string `json:"key"`
+	Date string `json:"date"`
+	Name string `json:"name"`
+}
+
+// GetOptionalHolidays returns the company optional holidays of a year, by date
+func GetOptionalHolidays(year int) []OptionalHoliday {
+	// Carnaval Tuesday is 47 days before Easter
+	carnaval := calculateEaster(year).AddDate(0, 0, -47)
+
+	return []OptionalHoliday{
+		{Key: OptionalCarnaval, Date: carnaval.Format("2006-01-02"), Name: "Carnaval"},
+		{Key: OptionalChristmasEve, Date: formatDate(year, 12, 24), Name: "Véspera de Natal"},
+		{Key: OptionalNewYearsEve, Date: formatDate(year, 12, 31), Name: "Véspera de Ano Novo"},
+	}
+}
+
+// IsOptionalHoliday reports whether key names a company optional holiday
+func IsOptionalHoliday(key string) bool {
+	for _, opt := range GetOptionalHolidays(2000) {
+		if opt.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// AddOptional adds the enabled company optional holidays of a year to a
+// holiday list. Dates that are already holidays are left as they are.
+func AddOptional(holidayList []PortugueseHoliday, year int, enabled []string) []PortugueseHoliday {
+	if len(enabled) == 0 {
+		return holidayList
+	}
+
+	on := make(map[string]bool)
+	for _, key := range enabled {
+		on[key] = true
+	}
+	taken := make(map[string]bool)
+	for _, h := range holidayList {
+		taken[h.Date] = true
+	}
+
+	for _, opt := range GetOptionalHolidays(year) {
+		if on[opt.Key] && !taken[opt.Date] {
+			holidayList = append(holidayList, PortugueseHoliday{Date: opt.Date, Name: opt.Name, Type: HolidayTypeOptional, Source: SourceCustom})
+		}
+	}
+	return holidayList
+}
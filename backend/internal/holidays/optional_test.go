@@ -0,0 +1,27 @@
+package holidays
+
+import "testing"
+
+func TestGetOptionalHolidays(t *testing.T) {
+	want := map[int]string{2024: "2024-02-13", 2025: "2025-03-04", 2030: "2030-03-05"}
+	for year, carnaval := range want {
+		for _, opt := range GetOptionalHolidays(year) {
+			if opt.Key == OptionalCarnaval && opt.Date != carnaval {
+				t.Errorf("Carnaval %d = %s, want %s", year, opt.Date, carnaval)
+			}
+		}
+	}
+}
+
+func TestAddOptional(t *testing.T) {
+	list := []PortugueseHoliday{{Date: "2030-12-25", Name: "Natal", Type: "national"}}
+
+	got := AddOptional(list, 2030, []string{OptionalChristmasEve})
+	if len(got) != 2 || got[1].Date != "2030-12-24" || got[1].Type != HolidayTypeOptional {
+		t.Errorf("AddOptional = %+v, want Christmas Eve added", got)
+	}
+
+	if got := AddOptional(list, 2030, nil); len(got) != 1 {
+		t.Errorf("AddOptional with none enabled = %+v, want the list unchanged", got)
+	}
+}
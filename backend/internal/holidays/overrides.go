@@ -0,0 +1,51 @@
+package holidays
+
+// Holiday override actions
+const (
+	OverrideCorrect  = "correct"  // Move the holiday to another date and/or rename it
+	OverrideSuppress = "suppress" // Drop the holiday, a day the employer doesn't observe
+)
+
+// Override corrects or suppresses the holiday on a date, for holidays the
+// APIs get wrong (a municipal holiday on the wrong date) or the employer
+// doesn't give
+type Override struct {
+	Date    string // Date of the holiday to override
+	Action  string
+	NewDate string // Date the holiday is on instead, for corrections
+	Name    string // Name the holiday takes instead, for corrections
+}
+
+// ApplyOverrides corrects and suppresses the holidays of a list. Overrides
+// of dates that are not holidays (the APIs may have been fixed since) are
+// ignored.
+func ApplyOverrides(holidayList []PortugueseHoliday, overrides []Override) []PortugueseHoliday {
+	if len(overrides) == 0 {
+		return holidayList
+	}
+
+	byDate := make(map[string]Override)
+	for _, o := range overrides {
+		byDate[o.Date] = o
+	}
+
+	var result []PortugueseHoliday
+	for _, h := range holidayList {
+		o, ok := byDate[h.Date]
+		switch {
+		case !ok:
+		case o.Action == OverrideSuppress:
+			continue
+		case o.Action == OverrideCorrect:
+			if o.NewDate != "" {
+				h.Date = o.NewDate
+			}
+			if o.Name != "" {
+				h.Name = o.Name
+			}
+			h.Source = SourceOverride
+		}
+		result = append(result, h)
+	}
+	return result
+}
@@ -9,14 +9,39 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/sandbox"
 )
 
 // PortugueseHoliday represents a Portuguese holiday
 type PortugueseHoliday struct {
-	Date     string `json:"date"`
-	Name     string `json:"name"`
-	Type     string `json:"type"`     // "national" or "municipal"
-	Location string `json:"location"` // City/location for municipal holidays
+	Date        string `json:"date"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`                   // "national", "municipal" or "observed"
+	Location    string `json:"location"`               // City/location for municipal holidays
+	ObservedFor string `json:"observed_for,omitempty"` // Original date of an observed holiday
+	Provisional bool   `json:"provisional,omitempty"`  // Calculated locally, not yet confirmed by the holiday APIs
+	Source      string `json:"source,omitempty"`       // Where the holiday comes from, one of the Source constants
+	FetchedAt   string `json:"fetched_at,omitempty"`   // RFC 3339, when it was loaded from its API
+}
+
+// Holiday sources, so users can judge how far to trust a holiday
+const (
+	SourceNager        = "nager"        // Nager.Date API, national holidays
+	SourceCalendarific = "calendarific" // Calendarific API, municipal holidays
+	SourceFallback     = "fallback"     // Calculated locally because the API failed
+	SourceCustom       = "custom"       // Generated by the settings: observed, optional and birthday days off
+	SourceOverride     = "override"     // Corrected by a holiday override
+)
+
+// attribute sets the source and fetch time of holidays loaded from an API
+func attribute(holidayList []PortugueseHoliday, source string, fetchedAt time.Time) []PortugueseHoliday {
+	at := fetchedAt.UTC().Format(time.RFC3339)
+	for i := range holidayList {
+		holidayList[i].Source = source
+		holidayList[i].FetchedAt = at
+	}
+	return holidayList
 }
 
 // NagerHoliday represents a holiday from the Nager.Date API
@@ -54,10 +79,6 @@ type CalendarificHoliday struct {
 }
 
 var (
-	// Cache for API responses
-	holidayCache    = make(map[string][]PortugueseHoliday) // key: "year" or "year:city"
-	holidayCacheMux sync.RWMutex
-
 	// API configuration
 	calendarificAPIKey string
 	apiConfigMux       sync.RWMutex
@@ -84,6 +105,10 @@ func GetCalendarificAPIKey() string {
 
 // fetchNationalHolidays fetches national holidays from the Nager.Date API
 func fetchNationalHolidays(year int) ([]PortugueseHoliday, error) {
+	if sandbox.Enabled() {
+		return attribute(getSandboxNationalHolidays(year), SourceNager, time.Now()), nil
+	}
+
 	url := fmt.Sprintf(nagerAPIURL, year)
 
 	client := &http.Client{Timeout: 10 * time.Second}
@@ -127,11 +152,15 @@ func fetchNationalHolidays(year int) ([]PortugueseHoliday, error) {
 		}
 	}
 
-	return holidays, nil
+	return attribute(holidays, SourceNager, time.Now()), nil
 }
 
 // fetchMunicipalHolidays fetches municipal/local holidays from Calendarific API
 func fetchMunicipalHolidays(year int) ([]PortugueseHoliday, error) {
+	if sandbox.Enabled() {
+		return attribute(getSandboxMunicipalHolidays(year), SourceCalendarific, time.Now()), nil
+	}
+
 	apiKey := GetCalendarificAPIKey()
 	if apiKey == "" {
 		return nil, fmt.Errorf("calendarific API key not configured")
@@ -200,7 +229,7 @@ func fetchMunicipalHolidays(year int) ([]PortugueseHoliday, error) {
 		}
 	}
 
-	return holidays, nil
+	return attribute(holidays, SourceCalendarific, time.Now()), nil
 }
 
 // getFallbackNationalHolidays returns calculated holidays as fallback when API fails
@@ -241,9 +270,18 @@ func getFallbackNationalHolidays(year int) []PortugueseHoliday {
 		Type: "national",
 	})
 
+	for i := range holidays {
+		holidays[i].Source = SourceFallback
+	}
 	return holidays
 }
 
+// CalculatedHolidays returns the national holidays of a year calculated
+// locally, without asking the holiday APIs
+func CalculatedHolidays(year int) []PortugueseHoliday {
+	return getFallbackNationalHolidays(year)
+}
+
 // GetPortugueseHolidays returns all Portuguese national holidays for a given year
 func GetPortugueseHolidays(year int) []PortugueseHoliday {
 	return GetPortugueseHolidaysWithCity(year, "")
@@ -251,66 +289,75 @@ func GetPortugueseHolidays(year int) []PortugueseHoliday {
 
 // GetPortugueseHolidaysWithCity returns all Portuguese holidays including municipal ones for a city
 func GetPortugueseHolidaysWithCity(year int, city string) []PortugueseHoliday {
-	cacheKey := fmt.Sprintf("%d", year)
-	if city != "" {
-		cacheKey = fmt.Sprintf("%d:%s", year, city)
-	}
+	return defaultCache.get(year, city)
+}
+
+// fetchedHolidays is the result of asking the APIs for a year's holidays
+type fetchedHolidays struct {
+	national    []PortugueseHoliday // The calculated fallback when nationalOK is false
+	municipal   []PortugueseHoliday // Every municipality, not only the work city
+	nationalOK  bool
+	municipalOK bool
+}
+
+// complete reports whether everything needed for a city was loaded
+func (f fetchedHolidays) complete(city string) bool {
+	return f.nationalOK && (f.municipalOK || city == "" || !municipalAvailable())
+}
 
-	// Check cache first
-	holidayCacheMux.RLock()
-	cachedHolidays, found := holidayCache[cacheKey]
-	holidayCacheMux.RUnlock()
+// municipalAvailable reports whether municipal holidays can be fetched.
+// Without a Calendarific key there are none to wait for.
+func municipalAvailable() bool {
+	return sandbox.Enabled() || GetCalendarificAPIKey() != ""
+}
 
-	if found {
-		return cachedHolidays
+// forCity returns the national holidays and the municipal holidays of a city
+func (f fetchedHolidays) forCity(city string) []PortugueseHoliday {
+	holidays := make([]PortugueseHoliday, len(f.national))
+	copy(holidays, f.national)
+	if city == "" {
+		return holidays
+	}
+	for _, mh := range f.municipal {
+		if containsCity(mh.Location, city) {
+			holidays = append(holidays, mh)
+		}
 	}
+	return holidays
+}
 
-	// Fetch national holidays
-	nationalHolidays, err := fetchNationalHolidays(year)
+// fetchHolidays asks the APIs for the national holidays of a year and, for
+// a city, the municipal ones
+func fetchHolidays(year int, city string) fetchedHolidays {
+	var fetched fetchedHolidays
+
+	national, err := fetchNationalHolidays(year)
 	if err != nil {
 		fmt.Printf("Warning: Failed to fetch holidays from API: %v. Using fallback.\n", err)
-		nationalHolidays = getFallbackNationalHolidays(year)
+		national = getFallbackNationalHolidays(year)
+	} else {
+		fetched.nationalOK = true
 	}
+	fetched.national = national
 
-	// Create combined holidays list
-	holidays := make([]PortugueseHoliday, len(nationalHolidays))
-	copy(holidays, nationalHolidays)
-
-	// Fetch municipal holidays if city is specified
 	if city != "" {
-		municipalHolidays, err := fetchMunicipalHolidays(year)
+		municipal, err := fetchMunicipalHolidays(year)
 		if err != nil {
 			fmt.Printf("Warning: Failed to fetch municipal holidays: %v\n", err)
 		} else {
-			// Filter for the specific city
-			for _, mh := range municipalHolidays {
-				// Check if this holiday is for the specified city
-				if containsCity(mh.Location, city) {
-					holidays = append(holidays, mh)
-				}
-			}
+			fetched.municipal = municipal
+			fetched.municipalOK = true
 		}
 	}
 
-	// Cache the result
-	holidayCacheMux.Lock()
-	holidayCache[cacheKey] = holidays
-	holidayCacheMux.Unlock()
-
-	return holidays
+	return fetched
 }
 
 // FetchAndCacheHolidays fetches holidays for a year and caches them
 // Call this on app start or when year changes
 func FetchAndCacheHolidays(year int) error {
 	// Clear cache for this year
-	holidayCacheMux.Lock()
-	for key := range holidayCache {
-		if key == fmt.Sprintf("%d", year) || len(key) > 4 && key[:4] == fmt.Sprintf("%d", year) {
-			delete(holidayCache, key)
-		}
-	}
-	holidayCacheMux.Unlock()
+	ClearCacheForYear(year)
 
 	// Fetch national holidays
 	_, err := fetchNationalHolidays(year)
@@ -372,31 +419,17 @@ func GetAvailableCities() []string {
 	return cities
 }
 
-// ClearCache clears the holiday cache (useful for testing or forcing refresh)
-func ClearCache() {
-	holidayCacheMux.Lock()
-	holidayCache = make(map[string][]PortugueseHoliday)
-	holidayCacheMux.Unlock()
-}
-
-// ClearCacheForYear clears the holiday cache for a specific year
-func ClearCacheForYear(year int) {
-	holidayCacheMux.Lock()
-	defer holidayCacheMux.Unlock()
-	
-	yearPrefix := fmt.Sprintf("%d", year)
-	for key := range holidayCache {
-		if key == yearPrefix || (len(key) > len(yearPrefix) && key[:len(yearPrefix)+1] == yearPrefix+":") {
-			delete(holidayCache, key)
-		}
-	}
-}
-
 // normalizeCity normalizes city name for comparison
 func normalizeCity(city string) string {
 	return strings.ToLower(strings.TrimSpace(city))
 }
 
+// InLocation reports whether a holiday location, which may list several
+// cities ("Porto, Braga"), includes a city. Case is ignored.
+func InLocation(holidayLocation, city string) bool {
+	return containsCity(holidayLocation, city)
+}
+
 // containsCity checks if a holiday location matches the city
 func containsCity(holidayLocation, city string) bool {
 	locationLower := strings.ToLower(holidayLocation)
@@ -0,0 +1,54 @@
+package holidays
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls the background retries of failed holiday fetches.
+// The delay doubles after each attempt, starting at BaseDelay and capped at
+// MaxDelay.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// DefaultRetryPolicy is used until the settings are applied
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 5,
+	BaseDelay:  30 * time.Second,
+	MaxDelay:   30 * time.Minute,
+}
+
+// Backoff returns the delay before a retry (1-based), without jitter
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// Delay returns the jittered delay before a retry: between half and all of
+// its backoff, so years that failed together do not retry in lockstep
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	backoff := p.Backoff(retry)
+	half := backoff / 2
+	if half <= 0 {
+		return backoff
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// Schedule returns the backoff of every retry in seconds
+func (p RetryPolicy) Schedule() []int {
+	schedule := make([]int, p.MaxRetries)
+	for i := range schedule {
+		schedule[i] = int(p.Backoff(i+1) / time.Second)
+	}
+	return schedule
+}
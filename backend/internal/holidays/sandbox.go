@@ -0,0 +1,39 @@
+package holidays
+
+// sandboxMunicipalHolidays are canned municipal holidays (month, day) used in
+// sandbox mode instead of the Calendarific API
+var sandboxMunicipalHolidays = []struct {
+	Month    int
+	Day      int
+	Name     string
+	Location string
+}{
+	{6, 13, "Santo António", "Lisboa"},
+	{6, 24, "São João", "Porto"},
+	{6, 24, "São João", "Braga"},
+	{7, 4, "Rainha Santa Isabel", "Coimbra"},
+	{9, 7, "Dia da Cidade", "Faro"},
+	{6, 29, "São Pedro", "Évora"},
+	{5, 18, "Dia da Cidade", "Aveiro"},
+	{9, 15, "Bocage", "Setúbal"},
+	{8, 21, "Dia da Cidade", "Funchal"},
+}
+
+// getSandboxNationalHolidays returns the locally calculated national holidays
+func getSandboxNationalHolidays(year int) []PortugueseHoliday {
+	return getFallbackNationalHolidays(year)
+}
+
+// getSandboxMunicipalHolidays returns canned municipal holidays for a year
+func getSandboxMunicipalHolidays(year int) []PortugueseHoliday {
+	var holidays []PortugueseHoliday
+	for _, mh := range sandboxMunicipalHolidays {
+		holidays = append(holidays, PortugueseHoliday{
+			Date:     formatDate(year, mh.Month, mh.Day),
+			Name:     mh.Name + " (" + mh.Location + ")",
+			Type:     "municipal",
+			Location: mh.Location,
+		})
+	}
+	return holidays
+}
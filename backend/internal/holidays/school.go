@@ -0,0 +1,192 @@
+package holidays
+
+import (
+	"sort"
+	"time"
+)
+
+// SchoolBreak represents a school holiday period (interrupção letiva)
+type SchoolBreak struct {
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	District  string `json:"district"`
+	Source    string `json:"source"` // "calculated" or "custom"
+}
+
+// Contains checks if a date (YYYY-MM-DD) falls within the break
+func (b SchoolBreak) Contains(date string) bool {
+	return date >= b.StartDate && date <= b.EndDate
+}
+
+// GetSchoolDistricts returns the districts (and autonomous regions) that can
+// have their own school calendar
+func GetSchoolDistricts() []string {
+	districts := []string{
+		"Aveiro",
+		"Beja",
+		"Braga",
+		"Bragança",
+		"Castelo Branco",
+		"Coimbra",
+		"Évora",
+		"Faro",
+		"Guarda",
+		"Leiria",
+		"Lisboa",
+		"Portalegre",
+		"Porto",
+		"Santarém",
+		"Setúbal",
+		"Viana do Castelo",
+		"Vila Real",
+		"Viseu",
+		"Açores",
+		"Madeira",
+	}
+	sort.Strings(districts)
+	return districts
+}
+
+// IsSchoolDistrict reports whether a district is one of GetSchoolDistricts
+func IsSchoolDistrict(district string) bool {
+	for _, d := range GetSchoolDistricts() {
+		if d == district {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDefaultSchoolBreaks calculates the usual Portuguese school breaks that fall
+// within a calendar year. The official dates are published yearly by despacho;
+// these follow the usual pattern and can be overridden per district. The
+// autonomous regions close schools on their regional holidays too: Madeira's,
+// on 1 July and 26 December, fall within the summer and Christmas breaks,
+// while the Dia dos Açores adds a day off in the Açores.
+func GetDefaultSchoolBreaks(year int, district string) []SchoolBreak {
+	easter := calculateEaster(year)
+	carnival := easter.AddDate(0, 0, -47)
+
+	breaks := []SchoolBreak{
+		{
+			// End of the Christmas break that started in the previous year
+			Name:      "Férias de Natal",
+			StartDate: formatDate(year, 1, 1),
+			EndDate:   firstWeekdayOnOrAfter(year, 1, 2).AddDate(0, 0, -1).Format("2006-01-02"),
+		},
+		{
+			// Monday to Wednesday around Carnival Tuesday
+			Name:      "Interrupção do Carnaval",
+			StartDate: carnival.AddDate(0, 0, -1).Format("2006-01-02"),
+			EndDate:   carnival.AddDate(0, 0, 1).Format("2006-01-02"),
+		},
+		{
+			// Holy Week through the week after Easter
+			Name:      "Férias da Páscoa",
+			StartDate: easter.AddDate(0, 0, -6).Format("2006-01-02"),
+			EndDate:   easter.AddDate(0, 0, 5).Format("2006-01-02"),
+		},
+	}
+	if district == "Açores" {
+		// The regional holiday on Whit Monday, 50 days after Easter
+		day := easter.AddDate(0, 0, 50).Format("2006-01-02")
+		breaks = append(breaks, SchoolBreak{Name: "Dia dos Açores", StartDate: day, EndDate: day})
+	}
+	breaks = append(breaks, []SchoolBreak{
+		{
+			Name:      "Férias de Verão",
+			StartDate: formatDate(year, 7, 1),
+			EndDate:   formatDate(year, 9, 11),
+		},
+		{
+			// From the Saturday after the first term ends to the end of the year
+			Name:      "Férias de Natal",
+			StartDate: saturdayOnOrAfter(year, 12, 15).Format("2006-01-02"),
+			EndDate:   formatDate(year, 12, 31),
+		},
+	}...)
+
+	for i := range breaks {
+		breaks[i].District = district
+		breaks[i].Source = "calculated"
+	}
+
+	return breaks
+}
+
+// LoadSchoolBreaks loads the custom school breaks of a district from the
+// database, or calculates the default calendar when none are stored
+func (s *HolidayService) LoadSchoolBreaks(year int, district string) ([]SchoolBreak, error) {
+	rows, err := s.db.Query(`SELECT name, start_date, end_date, district, source FROM school_holidays WHERE year = ? AND district = ? ORDER BY start_date`, year, district)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var breaks []SchoolBreak
+	for rows.Next() {
+		var b SchoolBreak
+		if err := rows.Scan(&b.Name, &b.StartDate, &b.EndDate, &b.District, &b.Source); err != nil {
+			return nil, err
+		}
+		breaks = append(breaks, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(breaks) > 0 {
+		return breaks, nil
+	}
+	return GetDefaultSchoolBreaks(year, district), nil
+}
+
+// SaveSchoolBreaks replaces the stored school breaks for a district and year
+func (s *HolidayService) SaveSchoolBreaks(year int, district string, breaks []SchoolBreak) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM school_holidays WHERE year = ? AND district = ?`, year, district); err != nil {
+		return err
+	}
+
+	for _, b := range breaks {
+		source := b.Source
+		if source == "" {
+			source = "custom"
+		}
+		_, err := tx.Exec(`INSERT INTO school_holidays (year, district, name, start_date, end_date, source) VALUES (?, ?, ?, ?, ?, ?)`,
+			year, district, b.Name, b.StartDate, b.EndDate, source)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ResetSchoolBreaks removes stored breaks so the calculated calendar is used again
+func (s *HolidayService) ResetSchoolBreaks(year int, district string) error {
+	_, err := s.db.Exec(`DELETE FROM school_holidays WHERE year = ? AND district = ?`, year, district)
+	return err
+}
+
+func firstWeekdayOnOrAfter(year, month, day int) time.Time {
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	for date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+func saturdayOnOrAfter(year, month, day int) time.Time {
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	for date.Weekday() != time.Saturday {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date
+}
@@ -9,16 +9,18 @@ import (
 
 // HolidayStatus represents the current status of holiday data
 type HolidayStatus struct {
-	Year              int       `json:"year"`
-	NationalLoaded    bool      `json:"national_loaded"`
-	MunicipalLoaded   bool      `json:"municipal_loaded"`
-	NationalError     string    `json:"national_error,omitempty"`
-	MunicipalError    string    `json:"municipal_error,omitempty"`
-	LastUpdated       time.Time `json:"last_updated"`
-	RetryCount        int       `json:"retry_count"`
-	MaxRetries        int       `json:"max_retries"`
-	NextRetry         time.Time `json:"next_retry,omitempty"`
-	IsRetrying        bool      `json:"is_retrying"`
+	Year            int              `json:"year"`
+	NationalLoaded  bool             `json:"national_loaded"`
+	MunicipalLoaded bool             `json:"municipal_loaded"`
+	NationalError   string           `json:"national_error,omitempty"`
+	MunicipalError  string           `json:"municipal_error,omitempty"`
+	LastUpdated     time.Time        `json:"last_updated"`
+	RetryCount      int              `json:"retry_count"`
+	MaxRetries      int              `json:"max_retries"`
+	RetrySchedule   []int            `json:"retry_schedule"` // Backoff of each retry in seconds, before jitter
+	NextRetry       time.Time        `json:"next_retry,omitempty"`
+	IsRetrying      bool             `json:"is_retrying"`
+	Warnings        []HolidayWarning `json:"warnings,omitempty"` // Holidays the providers disagree on
 }
 
 // HolidayService manages holiday data with persistence and background retries
@@ -28,25 +30,66 @@ type HolidayService struct {
 	statusMux       sync.RWMutex
 	stopRetry       map[int]chan struct{}
 	stopRetryMux    sync.Mutex
-	maxRetries      int
-	retryInterval   time.Duration
+	policy          RetryPolicy
+	policyMux       sync.RWMutex
+	onRecovered     func(year int)
+	onFailed        func(year int)
+	refreshing      map[int]bool // Years with a background refresh running
+	refreshingMux   sync.Mutex
+	cache           *holidayCache // In-memory holidays in front of db
 }
 
-// NewHolidayService creates a new HolidayService
+// NewHolidayService creates a new HolidayService. Its database is the store
+// behind its own in-memory holiday cache, so services on different databases
+// never share holidays.
 func NewHolidayService(db *sql.DB) *HolidayService {
-	return &HolidayService{
+	s := &HolidayService{
 		db:            db,
 		status:        make(map[int]*HolidayStatus),
 		stopRetry:     make(map[int]chan struct{}),
-		maxRetries:    5,
-		retryInterval: 30 * time.Second,
+		policy:        DefaultRetryPolicy,
+		refreshing:    make(map[int]bool),
 	}
+	s.cache = newHolidayCache(s)
+	return s
 }
 
-// SetRetryConfig sets the retry configuration
-func (s *HolidayService) SetRetryConfig(maxRetries int, interval time.Duration) {
-	s.maxRetries = maxRetries
-	s.retryInterval = interval
+// Holidays returns the national holidays of a year and the municipal ones
+// of a city from the in-memory cache, loading them from the database or the
+// APIs on a miss
+func (s *HolidayService) Holidays(year int, city string) []PortugueseHoliday {
+	return s.cache.get(year, city)
+}
+
+// PruneCache drops entries past the stale window from the in-memory cache
+// and returns how many were dropped
+func (s *HolidayService) PruneCache() int {
+	return s.cache.prune()
+}
+
+// SetRetryPolicy sets the policy for background retries started from now on
+func (s *HolidayService) SetRetryPolicy(policy RetryPolicy) {
+	s.policyMux.Lock()
+	s.policy = policy
+	s.policyMux.Unlock()
+}
+
+func (s *HolidayService) retryPolicy() RetryPolicy {
+	s.policyMux.RLock()
+	defer s.policyMux.RUnlock()
+	return s.policy
+}
+
+// SetRecoveryHandler sets a function called when a background retry finally
+// loads a year's holidays after failed fetches
+func (s *HolidayService) SetRecoveryHandler(fn func(year int)) {
+	s.onRecovered = fn
+}
+
+// SetFailureHandler sets a function called when background retries for a
+// year's holidays give up
+func (s *HolidayService) SetFailureHandler(fn func(year int)) {
+	s.onFailed = fn
 }
 
 // GetStatus returns the current status for a year
@@ -75,14 +118,16 @@ func (s *HolidayService) GetAllStatuses() map[int]*HolidayStatus {
 // LoadHolidaysForYear loads holidays from DB or fetches from API
 func (s *HolidayService) LoadHolidaysForYear(year int, city string) ([]PortugueseHoliday, error) {
 	// First, try to load from database
-	dbHolidays, hasNational, hasMunicipal := s.loadFromDatabase(year, city)
+	dbHolidays, hasNational, hasMunicipal, fetchedAt := s.loadFromDatabase(year, city)
 	
 	// Initialize status
+	policy := s.retryPolicy()
 	s.statusMux.Lock()
 	if s.status[year] == nil {
 		s.status[year] = &HolidayStatus{
-			Year:       year,
-			MaxRetries: s.maxRetries,
+			Year:          year,
+			MaxRetries:    policy.MaxRetries,
+			RetrySchedule: policy.Schedule(),
 		}
 	}
 	status := s.status[year]
@@ -92,6 +137,7 @@ func (s *HolidayService) LoadHolidaysForYear(year int, city string) ([]Portugues
 	if hasNational {
 		status.NationalLoaded = true
 		status.NationalError = ""
+		s.verify(year, dbHolidays)
 	}
 	
 	// If we have municipal holidays for this city in DB, use them
@@ -102,10 +148,11 @@ func (s *HolidayService) LoadHolidaysForYear(year int, city string) ([]Portugues
 	
 	// If we have data from DB, return it (we'll refresh in background if needed)
 	if len(dbHolidays) > 0 {
-		status.LastUpdated = time.Now()
+		status.LastUpdated = fetchedAt
 		
-		// Start background refresh if data might be stale (older than 24 hours)
-		go s.refreshInBackground(year, city, !hasNational, !hasMunicipal && city != "")
+		// Refresh in background what is missing or no longer fresh
+		stale := time.Since(fetchedAt) > FreshFor
+		go s.refreshInBackground(year, city, !hasNational || stale, (!hasMunicipal || stale) && city != "")
 		
 		return dbHolidays, nil
 	}
@@ -114,25 +161,88 @@ func (s *HolidayService) LoadHolidaysForYear(year int, city string) ([]Portugues
 	return s.fetchAndSave(year, city)
 }
 
-// loadFromDatabase loads holidays from the database
-func (s *HolidayService) loadFromDatabase(year int, city string) ([]PortugueseHoliday, bool, bool) {
+// Prefetch loads the holidays of startYear and the following horizon years,
+// one year after another. Failed years keep retrying in the background.
+func (s *HolidayService) Prefetch(startYear, horizon int, city string) {
+	for year := startYear; year <= startYear+horizon; year++ {
+		if _, err := s.LoadHolidaysForYear(year, city); err != nil {
+			log.Printf("Warning: Failed to pre-fetch holidays for %d: %v (will retry in background)", year, err)
+			continue
+		}
+		log.Printf("Holidays for %d loaded successfully", year)
+	}
+}
+
+// Refresh fetches a year's holidays again and stores them. Unlike
+// ForceRefresh, the stored holidays are kept when the APIs fail.
+func (s *HolidayService) Refresh(year int, city string) error {
+	nationalHolidays, err := fetchNationalHolidays(year)
+	if err != nil {
+		return err
+	}
+	if err := s.saveHolidaysToDatabase(year, nationalHolidays); err != nil {
+		return err
+	}
+	s.verify(year, nationalHolidays)
+
+	if city != "" {
+		municipalHolidays, err := fetchMunicipalHolidays(year)
+		if err != nil {
+			return err
+		}
+		if err := s.saveHolidaysToDatabase(year, municipalHolidays); err != nil {
+			return err
+		}
+	}
+
+	s.cache.clearYear(year)
+
+	s.statusMux.Lock()
+	if status := s.status[year]; status != nil {
+		status.NationalLoaded = true
+		status.NationalError = ""
+		if city != "" {
+			status.MunicipalLoaded = true
+			status.MunicipalError = ""
+		}
+		status.LastUpdated = time.Now()
+	}
+	s.statusMux.Unlock()
+
+	return nil
+}
+
+// loadFromDatabase loads holidays from the database, with the time the
+// oldest of them was fetched (zero when unknown)
+func (s *HolidayService) loadFromDatabase(year int, city string) ([]PortugueseHoliday, bool, bool, time.Time) {
 	var holidays []PortugueseHoliday
 	hasNational := false
 	hasMunicipal := false
+	var oldest time.Time
+	first := true
 	
-	query := `SELECT date, name, type, COALESCE(location, '') as location FROM holidays WHERE year = ?`
+	query := `SELECT date, name, type, COALESCE(location, '') as location, COALESCE(fetched_at, ''), COALESCE(source, '') FROM holidays WHERE year = ?`
 	rows, err := s.db.Query(query, year)
 	if err != nil {
 		log.Printf("Error loading holidays from DB: %v", err)
-		return nil, false, false
+		return nil, false, false, time.Time{}
 	}
 	defer rows.Close()
 	
 	for rows.Next() {
 		var h PortugueseHoliday
-		if err := rows.Scan(&h.Date, &h.Name, &h.Type, &h.Location); err != nil {
+		var fetched string
+		if err := rows.Scan(&h.Date, &h.Name, &h.Type, &h.Location, &fetched, &h.Source); err != nil {
 			continue
 		}
+		h.FetchedAt = fetched
+		if h.Source == "" {
+			// Stored before sources were recorded, most likely from the APIs
+			h.Source = SourceNager
+			if h.Type == "municipal" {
+				h.Source = SourceCalendarific
+			}
+		}
 		
 		if h.Type == "national" {
 			hasNational = true
@@ -142,10 +252,58 @@ func (s *HolidayService) loadFromDatabase(year int, city string) ([]PortugueseHo
 				hasMunicipal = true
 				holidays = append(holidays, h)
 			}
+		} else {
+			continue
+		}
+
+		// Rows stored before fetch times were recorded count as stale
+		fetchedAt, _ := time.Parse(time.RFC3339, fetched)
+		if first || fetchedAt.Before(oldest) {
+			oldest = fetchedAt
+			first = false
 		}
 	}
 	
-	return holidays, hasNational, hasMunicipal
+	return holidays, hasNational, hasMunicipal, oldest
+}
+
+// storedHolidays returns the stored national holidays of a year and the
+// municipal ones of a city, for the in-memory cache
+func (s *HolidayService) storedHolidays(year int, city string) ([]PortugueseHoliday, time.Time, bool) {
+	holidays, hasNational, hasMunicipal, fetchedAt := s.loadFromDatabase(year, city)
+	if !hasNational {
+		return nil, time.Time{}, false
+	}
+	if city != "" && !hasMunicipal && municipalAvailable() {
+		// The city's holidays were never loaded: usable, but not fresh
+		fetchedAt = time.Time{}
+	}
+	if city == "" {
+		// Without a city only national holidays apply
+		national := holidays[:0:0]
+		for _, h := range holidays {
+			if h.Type == "national" {
+				national = append(national, h)
+			}
+		}
+		holidays = national
+	}
+	return holidays, fetchedAt, true
+}
+
+// saveFetched stores the holidays the in-memory cache loaded from the APIs
+func (s *HolidayService) saveFetched(year int, fetched fetchedHolidays) {
+	if fetched.nationalOK {
+		if err := s.saveHolidaysToDatabase(year, fetched.national); err != nil {
+			log.Printf("Error saving holidays to DB: %v", err)
+		}
+		s.verify(year, fetched.national)
+	}
+	if fetched.municipalOK {
+		if err := s.saveHolidaysToDatabase(year, fetched.municipal); err != nil {
+			log.Printf("Error saving holidays to DB: %v", err)
+		}
+	}
 }
 
 // fetchAndSave fetches holidays from API and saves to database
@@ -174,6 +332,7 @@ func (s *HolidayService) fetchAndSave(year int, city string) ([]PortugueseHolida
 		
 		// Save to database
 		s.saveHolidaysToDatabase(year, nationalHolidays)
+		s.verify(year, nationalHolidays)
 	}
 	allHolidays = append(allHolidays, nationalHolidays...)
 	
@@ -217,16 +376,21 @@ func (s *HolidayService) saveHolidaysToDatabase(year int, holidays []PortugueseH
 	defer tx.Rollback()
 	
 	stmt, err := tx.Prepare(`
-		INSERT OR REPLACE INTO holidays (year, date, name, type, location) 
-		VALUES (?, ?, ?, ?, ?)
+		INSERT OR REPLACE INTO holidays (year, date, name, type, location, fetched_at, source) 
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 	
+	now := time.Now().UTC().Format(time.RFC3339)
 	for _, h := range holidays {
-		_, err := stmt.Exec(year, h.Date, h.Name, h.Type, h.Location)
+		fetchedAt := h.FetchedAt
+		if fetchedAt == "" {
+			fetchedAt = now
+		}
+		_, err := stmt.Exec(year, h.Date, h.Name, h.Type, h.Location, fetchedAt, h.Source)
 		if err != nil {
 			log.Printf("Error saving holiday to DB: %v", err)
 		}
@@ -241,7 +405,6 @@ func (s *HolidayService) refreshInBackground(year int, city string, refreshNatio
 		return
 	}
 	
-	// Check if data needs refresh (check last_updated in status)
 	s.statusMux.RLock()
 	status := s.status[year]
 	s.statusMux.RUnlock()
@@ -250,19 +413,33 @@ func (s *HolidayService) refreshInBackground(year int, city string, refreshNatio
 		return
 	}
 	
-	// If last update was less than 1 hour ago, skip
-	if time.Since(status.LastUpdated) < time.Hour {
+	// Run one refresh per year at a time
+	s.refreshingMux.Lock()
+	if s.refreshing[year] {
+		s.refreshingMux.Unlock()
 		return
 	}
+	s.refreshing[year] = true
+	s.refreshingMux.Unlock()
+	defer func() {
+		s.refreshingMux.Lock()
+		delete(s.refreshing, year)
+		s.refreshingMux.Unlock()
+	}()
+
+	refreshed := false
 	
 	if refreshNational {
 		nationalHolidays, err := fetchNationalHolidays(year)
 		if err == nil {
 			s.saveHolidaysToDatabase(year, nationalHolidays)
+			s.verify(year, nationalHolidays)
 			s.statusMux.Lock()
 			status.NationalLoaded = true
 			status.NationalError = ""
+			status.LastUpdated = time.Now()
 			s.statusMux.Unlock()
+			refreshed = true
 			log.Printf("Background refresh: National holidays for %d updated", year)
 		}
 	}
@@ -274,10 +451,17 @@ func (s *HolidayService) refreshInBackground(year int, city string, refreshNatio
 			s.statusMux.Lock()
 			status.MunicipalLoaded = true
 			status.MunicipalError = ""
+			status.LastUpdated = time.Now()
 			s.statusMux.Unlock()
+			refreshed = true
 			log.Printf("Background refresh: Municipal holidays for %d updated", year)
 		}
 	}
+
+	// The in-memory cache reloads the fresh rows on its next read
+	if refreshed {
+		s.cache.clearYear(year)
+	}
 }
 
 // startBackgroundRetry starts background retry for failed API calls
@@ -291,16 +475,28 @@ func (s *HolidayService) startBackgroundRetry(year int, city string, retryNation
 	s.stopRetry[year] = stopChan
 	s.stopRetryMux.Unlock()
 	
+	policy := s.retryPolicy()
+	delay := policy.Delay(1)
+
 	s.statusMux.Lock()
 	status := s.status[year]
 	status.RetryCount = 0
-	status.IsRetrying = true
-	status.NextRetry = time.Now().Add(s.retryInterval)
+	status.MaxRetries = policy.MaxRetries
+	status.RetrySchedule = policy.Schedule()
+	status.IsRetrying = policy.MaxRetries > 0
+	status.NextRetry = time.Now().Add(delay)
 	s.statusMux.Unlock()
+
+	if policy.MaxRetries <= 0 {
+		if s.onFailed != nil {
+			go s.onFailed(year)
+		}
+		return
+	}
 	
 	go func() {
-		ticker := time.NewTicker(s.retryInterval)
-		defer ticker.Stop()
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
 		
 		for {
 			select {
@@ -309,21 +505,13 @@ func (s *HolidayService) startBackgroundRetry(year int, city string, retryNation
 				status.IsRetrying = false
 				s.statusMux.Unlock()
 				return
-			case <-ticker.C:
+			case <-timer.C:
 				s.statusMux.Lock()
 				status.RetryCount++
 				currentRetry := status.RetryCount
 				s.statusMux.Unlock()
 				
-				if currentRetry > s.maxRetries {
-					log.Printf("Max retries reached for year %d, stopping background retry", year)
-					s.statusMux.Lock()
-					status.IsRetrying = false
-					s.statusMux.Unlock()
-					return
-				}
-				
-				log.Printf("Background retry %d/%d for year %d holidays", currentRetry, s.maxRetries, year)
+				log.Printf("Background retry %d/%d for year %d holidays", currentRetry, policy.MaxRetries, year)
 				
 				allSuccess := true
 				
@@ -334,10 +522,10 @@ func (s *HolidayService) startBackgroundRetry(year int, city string, retryNation
 						allSuccess = false
 						s.statusMux.Lock()
 						status.NationalError = err.Error()
-						status.NextRetry = time.Now().Add(s.retryInterval)
 						s.statusMux.Unlock()
 					} else {
 						s.saveHolidaysToDatabase(year, nationalHolidays)
+						s.verify(year, nationalHolidays)
 						s.statusMux.Lock()
 						status.NationalLoaded = true
 						status.NationalError = ""
@@ -354,7 +542,6 @@ func (s *HolidayService) startBackgroundRetry(year int, city string, retryNation
 						allSuccess = false
 						s.statusMux.Lock()
 						status.MunicipalError = err.Error()
-						status.NextRetry = time.Now().Add(s.retryInterval)
 						s.statusMux.Unlock()
 					} else {
 						s.saveHolidaysToDatabase(year, municipalHolidays)
@@ -373,8 +560,29 @@ func (s *HolidayService) startBackgroundRetry(year int, city string, retryNation
 					status.IsRetrying = false
 					status.LastUpdated = time.Now()
 					s.statusMux.Unlock()
+					if s.onRecovered != nil {
+						s.onRecovered(year)
+					}
+					return
+				}
+				
+				if currentRetry >= policy.MaxRetries {
+					log.Printf("Max retries reached for year %d, stopping background retry", year)
+					s.statusMux.Lock()
+					status.IsRetrying = false
+					s.statusMux.Unlock()
+					if s.onFailed != nil {
+						s.onFailed(year)
+					}
 					return
 				}
+				
+				// Back off before the next attempt
+				delay = policy.Delay(currentRetry + 1)
+				s.statusMux.Lock()
+				status.NextRetry = time.Now().Add(delay)
+				s.statusMux.Unlock()
+				timer.Reset(delay)
 			}
 		}
 	}()
@@ -405,6 +613,21 @@ func (s *HolidayService) ClearStatus(year int) {
 	s.stopRetryMux.Unlock()
 }
 
+// ChangeCity forgets what was loaded for the previous work city: its
+// entries in the holiday cache and the municipal status of every year
+func (s *HolidayService) ChangeCity(previousCity string) {
+	if previousCity != "" {
+		s.cache.clearCity(previousCity)
+	}
+
+	s.statusMux.Lock()
+	for _, status := range s.status {
+		status.MunicipalLoaded = false
+		status.MunicipalError = ""
+	}
+	s.statusMux.Unlock()
+}
+
 // ForceRefresh forces a refresh of holidays for a year
 func (s *HolidayService) ForceRefresh(year int, city string) ([]PortugueseHoliday, error) {
 	// Clear existing status and stop any retries
@@ -417,13 +640,15 @@ func (s *HolidayService) ForceRefresh(year int, city string) ([]PortugueseHolida
 	}
 	
 	// Clear memory cache
-	ClearCacheForYear(year)
+	s.cache.clearYear(year)
 	
 	// Initialize new status
+	policy := s.retryPolicy()
 	s.statusMux.Lock()
 	s.status[year] = &HolidayStatus{
-		Year:       year,
-		MaxRetries: s.maxRetries,
+		Year:          year,
+		MaxRetries:    policy.MaxRetries,
+		RetrySchedule: policy.Schedule(),
 	}
 	s.statusMux.Unlock()
 	
@@ -431,6 +656,25 @@ func (s *HolidayService) ForceRefresh(year int, city string) ([]PortugueseHolida
 	return s.fetchAndSave(year, city)
 }
 
+// verify cross-checks the national holidays loaded for a year and records
+// the disagreements in its status
+func (s *HolidayService) verify(year int, holidayList []PortugueseHoliday) {
+	warnings := CrossCheck(year, holidayList)
+	for _, w := range warnings {
+		log.Printf("Warning: holidays of %d: %s", year, w.Message)
+	}
+
+	s.statusMux.Lock()
+	defer s.statusMux.Unlock()
+	status := s.status[year]
+	if status == nil {
+		policy := s.retryPolicy()
+		status = &HolidayStatus{Year: year, MaxRetries: policy.MaxRetries, RetrySchedule: policy.Schedule()}
+		s.status[year] = status
+	}
+	status.Warnings = warnings
+}
+
 // ToJSON returns the status as JSON for API responses
 func (s *HolidayStatus) ToJSON() map[string]interface{} {
 	result := map[string]interface{}{
@@ -440,6 +684,7 @@ func (s *HolidayStatus) ToJSON() map[string]interface{} {
 		"last_updated":     s.LastUpdated.Format(time.RFC3339),
 		"retry_count":      s.RetryCount,
 		"max_retries":      s.MaxRetries,
+		"retry_schedule":   s.RetrySchedule,
 		"is_retrying":      s.IsRetrying,
 	}
 	
@@ -452,6 +697,9 @@ func (s *HolidayStatus) ToJSON() map[string]interface{} {
 	if s.IsRetrying && !s.NextRetry.IsZero() {
 		result["next_retry"] = s.NextRetry.Format(time.RFC3339)
 	}
+	if len(s.Warnings) > 0 {
+		result["warnings"] = s.Warnings
+	}
 	
 	return result
 }
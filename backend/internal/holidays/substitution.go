@@ -0,0 +1,79 @@
+package holidays
+
+import "time"
+
+// Substitution policies for holidays that fall on a weekend
+const (
+	SubstitutionNone           = "none"
+	SubstitutionNextMonday     = "next_monday"     // Saturday and Sunday holidays are observed the following Monday
+	SubstitutionNearestWeekday = "nearest_weekday" // Saturday holidays move to Friday, Sunday holidays to Monday
+)
+
+// HolidayTypeObserved marks a generated day off that replaces a weekend holiday
+const HolidayTypeObserved = "observed"
+
+// ApplySubstitution adds "observed" entries for holidays that fall on a
+// weekend, following the employer's substitution policy. The original
+// holidays are kept. An observed day that would land on another holiday
+// moves to the next free weekday.
+func ApplySubstitution(holidayList []PortugueseHoliday, policy string) []PortugueseHoliday {
+	if policy == "" || policy == SubstitutionNone {
+		return holidayList
+	}
+
+	taken := make(map[string]bool)
+	for _, h := range holidayList {
+		taken[h.Date] = true
+	}
+
+	result := append([]PortugueseHoliday(nil), holidayList...)
+	for _, h := range holidayList {
+		date, err := time.Parse("2006-01-02", h.Date)
+		if err != nil {
+			continue
+		}
+
+		// Easter Sunday is always on a Sunday, there is nothing to substitute
+		if date.Equal(calculateEaster(date.Year())) {
+			continue
+		}
+
+		var observed time.Time
+		switch {
+		case date.Weekday() == time.Saturday && policy == SubstitutionNearestWeekday:
+			observed = date.AddDate(0, 0, -1)
+		case date.Weekday() == time.Saturday:
+			observed = date.AddDate(0, 0, 2)
+		case date.Weekday() == time.Sunday:
+			observed = date.AddDate(0, 0, 1)
+		default:
+			continue
+		}
+
+		for taken[observed.Format("2006-01-02")] || observed.Weekday() == time.Saturday || observed.Weekday() == time.Sunday {
+			observed = observed.AddDate(0, 0, 1)
+		}
+
+		observedStr := observed.Format("2006-01-02")
+		taken[observedStr] = true
+		result = append(result, PortugueseHoliday{
+			Date:        observedStr,
+			Name:        h.Name + " (observed)",
+			Type:        HolidayTypeObserved,
+			Location:    h.Location,
+			ObservedFor: h.Date,
+			Source:      SourceCustom,
+		})
+	}
+
+	return result
+}
+
+// GetSubstitutionPolicies returns the available substitution policies
+func GetSubstitutionPolicies() []map[string]string {
+	return []map[string]string{
+		{"id": SubstitutionNone, "name": "None", "description": "Holidays on weekends are not replaced"},
+		{"id": SubstitutionNextMonday, "name": "Next Monday", "description": "Holidays on Saturday or Sunday are observed the following Monday"},
+		{"id": SubstitutionNearestWeekday, "name": "Nearest weekday", "description": "Holidays on Saturday are observed on Friday, on Sunday the following Monday"},
+	}
+}
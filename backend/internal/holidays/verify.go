@@ -0,0 +1,76 @@
+package holidays
+
+import (
+	"fmt"
+	"sort"
+)
+
+// HolidayWarning flags a national holiday the providers disagree on
+type HolidayWarning struct {
+	Date    string   `json:"date"`
+	Name    string   `json:"name"`
+	Sources []string `json:"sources"` // Providers listing the holiday on that date
+	Message string   `json:"message"`
+}
+
+// CrossCheck compares the national holidays of a year loaded from an API
+// with the local calculation, and returns a warning for each date only one
+// of them lists. Calendarific only provides municipal holidays here, so the
+// calculation is the second opinion for Nager. Holidays that are the
+// calculated fallback already have nothing to be checked against.
+func CrossCheck(year int, holidayList []PortugueseHoliday) []HolidayWarning {
+	fetched := make(map[string]PortugueseHoliday)
+	for _, h := range holidayList {
+		if h.Type == "national" && h.Source != SourceFallback {
+			fetched[h.Date] = h
+		}
+	}
+	if len(fetched) == 0 {
+		return nil
+	}
+	source := SourceNager
+	for _, h := range fetched {
+		if h.Source != "" {
+			source = h.Source
+		}
+		break
+	}
+
+	calculated := make(map[string]PortugueseHoliday)
+	for _, h := range getFallbackNationalHolidays(year) {
+		calculated[h.Date] = h
+	}
+
+	var warnings []HolidayWarning
+	for _, h := range sortedByDate(fetched) {
+		if _, ok := calculated[h.Date]; !ok {
+			warnings = append(warnings, HolidayWarning{
+				Date:    h.Date,
+				Name:    h.Name,
+				Sources: []string{source},
+				Message: fmt.Sprintf("%s lists %s on %s, the local calculation doesn't", source, h.Name, h.Date),
+			})
+		}
+	}
+	for _, h := range sortedByDate(calculated) {
+		if _, ok := fetched[h.Date]; !ok {
+			warnings = append(warnings, HolidayWarning{
+				Date:    h.Date,
+				Name:    h.Name,
+				Sources: []string{SourceFallback},
+				Message: fmt.Sprintf("The local calculation has %s on %s, %s doesn't list it", h.Name, h.Date, source),
+			})
+		}
+	}
+	return warnings
+}
+
+// sortedByDate returns the holidays of a map by date
+func sortedByDate(byDate map[string]PortugueseHoliday) []PortugueseHoliday {
+	list := make([]PortugueseHoliday, 0, len(byDate))
+	for _, h := range byDate {
+		list = append(list, h)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Date < list[j].Date })
+	return list
+}
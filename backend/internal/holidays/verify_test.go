@@ -0,0 +1,31 @@
+package holidays
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCrossCheck(t *testing.T) {
+	nager := attribute(getFallbackNationalHolidays(2030), SourceNager, time.Now())
+	if warnings := CrossCheck(2030, nager); len(warnings) != 0 {
+		t.Errorf("CrossCheck of matching lists = %+v, want none", warnings)
+	}
+
+	// Corpus Christi a week late
+	var moved []PortugueseHoliday
+	for _, h := range nager {
+		if h.Date == "2030-06-20" {
+			h.Date = "2030-06-27"
+		}
+		moved = append(moved, h)
+	}
+	warnings := CrossCheck(2030, moved)
+	if len(warnings) != 2 || warnings[0].Date != "2030-06-27" || warnings[0].Sources[0] != SourceNager ||
+		warnings[1].Date != "2030-06-20" || warnings[1].Sources[0] != SourceFallback {
+		t.Errorf("CrossCheck of a moved holiday = %+v, want one warning per date", warnings)
+	}
+
+	if warnings := CrossCheck(2030, getFallbackNationalHolidays(2030)); warnings != nil {
+		t.Errorf("CrossCheck of the fallback = %+v, want nothing checked", warnings)
+	}
+}
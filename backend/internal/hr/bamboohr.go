@@ -0,0 +1,87 @@
+package hr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const bambooHRURL = "https://api.bamboohr.com/api/gateway.php"
+
+// bambooHR reads approved time off requests with the BambooHR API
+type bambooHR struct {
+	client     *http.Client
+	apiKey     string
+	company    string
+	employeeID string
+}
+
+type bambooHRRequest struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Type  struct {
+		Name string `json:"name"`
+	} `json:"type"`
+	// Days of the request with the amount taken on each, "0" for days the
+	// policy does not count, such as weekends
+	Dates map[string]string `json:"dates"`
+}
+
+// ApprovedLeave returns the approved requests overlapping from..to
+func (b *bambooHR) ApprovedLeave(ctx context.Context, from, to time.Time) ([]Leave, error) {
+	params := url.Values{
+		"start":      {from.Format("2006-01-02")},
+		"end":        {to.Format("2006-01-02")},
+		"status":     {"approved"},
+		"employeeId": {b.employeeID},
+	}
+	endpoint := fmt.Sprintf("%s/%s/v1/time_off/requests/?%s", bambooHRURL, url.PathEscape(b.company), params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(b.apiKey, "x")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch time off from BambooHR: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("BambooHR API returned status %d", resp.StatusCode)
+	}
+
+	var requests []bambooHRRequest
+	if err := json.NewDecoder(resp.Body).Decode(&requests); err != nil {
+		return nil, fmt.Errorf("failed to parse BambooHR time off: %w", err)
+	}
+
+	var leave []Leave
+	for _, r := range requests {
+		var days []string
+		if len(r.Dates) > 0 {
+			for date, amount := range r.Dates {
+				if n, err := strconv.ParseFloat(amount, 64); err == nil && n > 0 {
+					days = append(days, date)
+				}
+			}
+			sort.Strings(days)
+		} else {
+			days, err = dateRange(r.Start, r.End)
+			if err != nil {
+				return nil, fmt.Errorf("invalid BambooHR request dates: %w", err)
+			}
+		}
+		leave = append(leave, Leave{Type: r.Type.Name, Dates: days})
+	}
+
+	return leave, nil
+}
@@ -0,0 +1,146 @@
+package hr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const personioURL = "https://api.personio.de/v1"
+
+// personioPageSize is how many time off periods are read per request
+const personioPageSize = 200
+
+// personio reads approved time off periods with the Personio API
+type personio struct {
+	client       *http.Client
+	clientID     string
+	clientSecret string
+	employeeID   string
+}
+
+type personioTimeOffs struct {
+	Success bool `json:"success"`
+	Data    []struct {
+		Attributes struct {
+			Status      string `json:"status"`
+			StartDate   string `json:"start_date"` // RFC 3339, the date part is the day
+			EndDate     string `json:"end_date"`
+			TimeOffType struct {
+				Attributes struct {
+					Name string `json:"name"`
+				} `json:"attributes"`
+			} `json:"time_off_type"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+// ApprovedLeave returns the approved periods overlapping from..to. Half days
+// are returned as whole days.
+func (p *personio) ApprovedLeave(ctx context.Context, from, to time.Time) ([]Leave, error) {
+	token, err := p.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var leave []Leave
+	for offset := 0; ; offset += personioPageSize {
+		params := url.Values{
+			"start_date":  {from.Format("2006-01-02")},
+			"end_date":    {to.Format("2006-01-02")},
+			"employees[]": {p.employeeID},
+			"limit":       {strconv.Itoa(personioPageSize)},
+			"offset":      {strconv.Itoa(offset)},
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, personioURL+"/company/time-offs?"+params.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/json")
+
+		page, err := p.timeOffs(req)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, period := range page.Data {
+			a := period.Attributes
+			if a.Status != "approved" || len(a.StartDate) < 10 || len(a.EndDate) < 10 {
+				continue
+			}
+			days, err := dateRange(a.StartDate[:10], a.EndDate[:10])
+			if err != nil {
+				return nil, fmt.Errorf("invalid Personio time off dates: %w", err)
+			}
+			leave = append(leave, Leave{Type: a.TimeOffType.Attributes.Name, Dates: days})
+		}
+
+		if len(page.Data) < personioPageSize {
+			return leave, nil
+		}
+	}
+}
+
+func (p *personio) timeOffs(req *http.Request) (personioTimeOffs, error) {
+	var page personioTimeOffs
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return page, fmt.Errorf("failed to fetch time off from Personio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return page, fmt.Errorf("Personio API returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return page, fmt.Errorf("failed to parse Personio time off: %w", err)
+	}
+	return page, nil
+}
+
+// authenticate exchanges the client credentials for a token. Personio
+// tokens are single use, so one is requested per import.
+func (p *personio) authenticate(ctx context.Context) (string, error) {
+	body, err := json.Marshal(map[string]string{"client_id": p.clientID, "client_secret": p.clientSecret})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, personioURL+"/auth", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to authenticate with Personio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Personio authentication returned status %d", resp.StatusCode)
+	}
+
+	var auth struct {
+		Success bool `json:"success"`
+		Data    struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		return "", fmt.Errorf("failed to parse Personio token: %w", err)
+	}
+	if !auth.Success || auth.Data.Token == "" {
+		return "", errors.New("Personio rejected the client credentials")
+	}
+	return auth.Data.Token, nil
+}
@@ -0,0 +1,118 @@
+// Package ics renders vacation blocks as iCalendar (RFC 5545) files that
+// calendar apps can import, and reads the events of calendar feeds.
+package ics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// Event is an all-day event spanning Start to End, both inclusive
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+}
+
+// BlockEvents returns one event per vacation block
+func BlockEvents(blocks []models.VacationBlock) []Event {
+	var events []Event
+	for _, block := range blocks {
+		start, err := time.Parse("2006-01-02", block.StartDate)
+		if err != nil {
+			continue
+		}
+		end, err := time.Parse("2006-01-02", block.EndDate)
+		if err != nil {
+			continue
+		}
+
+		description := fmt.Sprintf("%s off for %s.", plural(block.TotalDays, "day"), plural(block.VacationDaysUsed, "vacation day"))
+		if len(block.Holidays) > 0 {
+			description += " Holidays: " + strings.Join(block.Holidays, ", ") + "."
+		}
+
+		summary := fmt.Sprintf("Vacation (%s off)", plural(block.TotalDays, "day"))
+		if block.Label != "" {
+			summary = fmt.Sprintf("%s (%s off)", block.Label, plural(block.TotalDays, "day"))
+		}
+
+		events = append(events, Event{
+			UID:         fmt.Sprintf("%s-%s@vacation-planner", block.StartDate, block.EndDate),
+			Summary:     summary,
+			Description: description,
+			Start:       start,
+			End:         end,
+		})
+	}
+	return events
+}
+
+// Write renders the events as a calendar with the given name
+func Write(w io.Writer, name string, events []Event) error {
+	bw := bufio.NewWriter(w)
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	line := func(content string) {
+		writeFolded(bw, content)
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Vacation Planner//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:" + escape(name))
+	for _, event := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + escape(event.UID))
+		line("DTSTAMP:" + stamp)
+		line("DTSTART;VALUE=DATE:" + event.Start.Format("20060102"))
+		// DTEND of an all-day event is exclusive
+		line("DTEND;VALUE=DATE:" + event.End.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + escape(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION:" + escape(event.Description))
+		}
+		line("TRANSP:OPAQUE")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	return bw.Flush()
+}
+
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// escape escapes text values
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// writeFolded writes a content line, folding it at 75 octets without
+// splitting UTF-8 characters
+func writeFolded(w *bufio.Writer, content string) {
+	const limit = 75
+	width := 0
+	for _, r := range content {
+		size := len(string(r))
+		if width+size > limit {
+			w.WriteString("\r\n ")
+			width = 1
+		}
+		w.WriteRune(r)
+		width += size
+	}
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,127 @@
+package ics
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// ReadEvents parses the events of an iCalendar file as all-day events over
+// the days they touch, in the time zone they are written in. Cancelled events
+// are left out, and recurring events only give their first occurrence.
+func ReadEvents(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var calendar, inEvent, cancelled bool
+	var event Event
+	var start, end time.Time
+	for _, line := range lines {
+		name, params, value := splitLine(line)
+		switch {
+		case name == "BEGIN" && value == "VCALENDAR":
+			calendar = true
+		case name == "BEGIN" && value == "VEVENT":
+			inEvent, cancelled = true, false
+			event, start, end = Event{}, time.Time{}, time.Time{}
+		case name == "END" && value == "VEVENT":
+			inEvent = false
+			if start.IsZero() || cancelled {
+				continue
+			}
+			// DTEND is exclusive, a missing one ends the event on its first day
+			last := start
+			if end.After(start) {
+				last = end.Add(-time.Nanosecond)
+			}
+			event.Start = day(start)
+			event.End = day(last)
+			events = append(events, event)
+		case !inEvent:
+		case name == "UID":
+			event.UID = value
+		case name == "SUMMARY":
+			event.Summary = unescape(value)
+		case name == "DESCRIPTION":
+			event.Description = unescape(value)
+		case name == "STATUS":
+			cancelled = strings.EqualFold(value, "CANCELLED")
+		case name == "DTSTART":
+			start = parseTime(value, params)
+		case name == "DTEND":
+			end = parseTime(value, params)
+		}
+	}
+
+	if !calendar {
+		return nil, errors.New("not an iCalendar file")
+	}
+	return events, nil
+}
+
+// unfold returns the content lines of a file, joining folded lines
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if n := len(lines); n > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[n-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// splitLine returns the upper-case name, the parameters and the value of a
+// content line such as "DTSTART;TZID=Europe/Lisbon:20250801T090000"
+func splitLine(line string) (string, map[string]string, string) {
+	head, value, _ := strings.Cut(line, ":")
+	parts := strings.Split(head, ";")
+	params := make(map[string]string)
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, value
+}
+
+// parseTime parses a DATE or DATE-TIME value, in UTC, its TZID or else UTC.
+// It returns the zero time for values it can't read.
+func parseTime(value string, params map[string]string) time.Time {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, _ := time.Parse("20060102", value)
+		return t
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, _ := time.Parse("20060102T150405Z", value)
+		return t
+	}
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, _ := time.ParseInLocation("20060102T150405", value, loc)
+	return t
+}
+
+// day returns the day of a time, in its own time zone, at midnight UTC
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// unescape reverses escape
+func unescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
@@ -0,0 +1,67 @@
+package ics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadEvents(t *testing.T) {
+	feed := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:all-day",
+		"SUMMARY:Release 2.0\\, final",
+		"DTSTART;VALUE=DATE:20300311",
+		"DTEND;VALUE=DATE:20300313",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:shift",
+		"SUMMARY:On call: Primary",
+		"DTSTART:20300304T090000Z",
+		"DTEND:20300311T090000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:midnight",
+		"DTSTART;TZID=Europe/Lisbon:20300401T000000",
+		"DTEND;TZID=Europe/Lisbon:20300403T000000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:cancelled",
+		"STATUS:CANCELLED",
+		"DTSTART;VALUE=DATE:20300501",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:folded",
+		"SUMMARY:Sprint",
+		"  review",
+		"DTSTART;VALUE=DATE:20300601",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	events, err := ReadEvents(strings.NewReader(feed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct{ uid, summary, start, end string }{
+		{"all-day", "Release 2.0, final", "2030-03-11", "2030-03-12"},
+		{"shift", "On call: Primary", "2030-03-04", "2030-03-11"},
+		{"midnight", "", "2030-04-01", "2030-04-02"},
+		{"folded", "Sprint review", "2030-06-01", "2030-06-01"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.UID != w.uid || e.Summary != w.summary || e.Start.Format("2006-01-02") != w.start || e.End.Format("2006-01-02") != w.end {
+			t.Errorf("event %d = %s %q %s to %s, want %s %q %s to %s", i, e.UID, e.Summary, e.Start.Format("2006-01-02"), e.End.Format("2006-01-02"), w.uid, w.summary, w.start, w.end)
+		}
+	}
+
+	if _, err := ReadEvents(strings.NewReader("<html></html>")); err == nil {
+		t.Error("read events from a file that isn't a calendar")
+	}
+}
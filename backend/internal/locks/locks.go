@@ -0,0 +1,130 @@
+package locks
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultTTL is how long a lock is held without being renewed
+const DefaultTTL = 2 * time.Minute
+
+// Lock represents an edit lock held by a client on a year's calendar
+type Lock struct {
+	Year       int       `json:"year"`
+	ClientID   string    `json:"client_id"`
+	HolderName string    `json:"holder_name,omitempty"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Manager keeps lightweight, expiring edit locks in memory
+type Manager struct {
+	locks map[int]*Lock
+	mux   sync.Mutex
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+// NewManager creates a new lock manager
+func NewManager(ttl time.Duration) *Manager {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Manager{
+		locks: make(map[int]*Lock),
+		ttl:   ttl,
+		now:   time.Now,
+	}
+}
+
+// Acquire takes or renews the lock for a year. If another client holds a
+// live lock, it is returned together with ok=false.
+func (m *Manager) Acquire(year int, clientID, holderName string) (Lock, bool) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	now := m.now()
+	if current := m.activeLocked(year, now); current != nil && current.ClientID != clientID {
+		return *current, false
+	}
+
+	lock, exists := m.locks[year]
+	if !exists || lock.ClientID != clientID {
+		lock = &Lock{
+			Year:       year,
+			ClientID:   clientID,
+			AcquiredAt: now,
+		}
+		m.locks[year] = lock
+	}
+	if holderName != "" {
+		lock.HolderName = holderName
+	}
+	lock.ExpiresAt = now.Add(m.ttl)
+
+	return *lock, true
+}
+
+// Release drops the lock for a year if it is held by the given client
+func (m *Manager) Release(year int, clientID string) bool {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	lock, exists := m.locks[year]
+	if !exists || lock.ClientID != clientID {
+		return false
+	}
+	delete(m.locks, year)
+	return true
+}
+
+// Get returns the live lock for a year, if any
+func (m *Manager) Get(year int) (Lock, bool) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	lock := m.activeLocked(year, m.now())
+	if lock == nil {
+		return Lock{}, false
+	}
+	return *lock, true
+}
+
+// CanEdit reports whether the client may modify the year's calendar.
+// Edits are allowed when nobody holds the lock or the client holds it.
+func (m *Manager) CanEdit(year int, clientID string) (Lock, bool) {
+	lock, held := m.Get(year)
+	if !held || (clientID != "" && lock.ClientID == clientID) {
+		return lock, true
+	}
+	return lock, false
+}
+
+// Prune drops expired locks and returns how many were dropped
+func (m *Manager) Prune() int {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	now := m.now()
+	pruned := 0
+	for year := range m.locks {
+		if m.activeLocked(year, now) == nil {
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// activeLocked returns the lock for a year, dropping it if expired.
+// The caller must hold m.mux.
+func (m *Manager) activeLocked(year int, now time.Time) *Lock {
+	lock, exists := m.locks[year]
+	if !exists {
+		return nil
+	}
+	if now.After(lock.ExpiresAt) {
+		delete(m.locks, year)
+		return nil
+	}
+	return lock
+}
@@ -0,0 +1,50 @@
+package milestones
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/ics"
+)
+
+// feed reads milestones from an iCalendar feed, such as a team's release
+// calendar. Every event in it is a milestone.
+type feed struct {
+	client *http.Client
+	url    string
+}
+
+// Milestones returns the events of the feed overlapping from..to
+func (f *feed) Milestones(ctx context.Context, from, to time.Time) ([]Milestone, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the milestone calendar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("milestone calendar returned status %d", resp.StatusCode)
+	}
+
+	events, err := ics.ReadEvents(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the milestone calendar: %w", err)
+	}
+
+	var milestones []Milestone
+	for _, event := range events {
+		if event.End.Before(from) || event.Start.After(to) {
+			continue
+		}
+		milestones = append(milestones, Milestone{Name: event.Summary, StartDate: event.Start.Format("2006-01-02"), EndDate: event.End.Format("2006-01-02")})
+	}
+	return milestones, nil
+}
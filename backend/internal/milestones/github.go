@@ -0,0 +1,93 @@
+package milestones
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const gitHubURL = "https://api.github.com"
+
+// gitHubPageSize is how many milestones are read per request
+const gitHubPageSize = 100
+
+// github reads the due dates of a repository's milestones with the GitHub
+// REST API
+type github struct {
+	client *http.Client
+	token  string
+	owner  string
+	repo   string
+	loc    *time.Location
+}
+
+type gitHubMilestone struct {
+	Title string     `json:"title"`
+	DueOn *time.Time `json:"due_on"` // Null when the milestone has no due date
+}
+
+// Milestones returns the open and closed milestones due from..to
+func (g *github) Milestones(ctx context.Context, from, to time.Time) ([]Milestone, error) {
+	first, last := from.Format("2006-01-02"), to.Format("2006-01-02")
+
+	var milestones []Milestone
+	for page := 1; ; page++ {
+		params := url.Values{
+			"state":    {"all"},
+			"per_page": {strconv.Itoa(gitHubPageSize)},
+			"page":     {strconv.Itoa(page)},
+		}
+		endpoint := fmt.Sprintf("%s/repos/%s/%s/milestones?%s", gitHubURL, url.PathEscape(g.owner), url.PathEscape(g.repo), params.Encode())
+		list, err := g.milestones(ctx, endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range list {
+			if m.DueOn == nil {
+				continue
+			}
+			due := m.DueOn.In(g.loc).Format("2006-01-02")
+			if due < first || due > last {
+				continue
+			}
+			milestones = append(milestones, Milestone{Name: m.Title, StartDate: due, EndDate: due})
+		}
+		if len(list) < gitHubPageSize {
+			return milestones, nil
+		}
+	}
+}
+
+// milestones reads one page of milestones
+func (g *github) milestones(ctx context.Context, endpoint string) ([]gitHubMilestone, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch milestones from GitHub: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	var list []gitHubMilestone
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub milestones: %w", err)
+	}
+	return list, nil
+}
@@ -0,0 +1,63 @@
+package milestones
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// jira reads the release dates of a project's versions (the values of
+// fixVersion) with the Jira Cloud REST API
+type jira struct {
+	client  *http.Client
+	site    string
+	user    string
+	token   string
+	project string
+}
+
+type jiraVersion struct {
+	Name        string `json:"name"`
+	ReleaseDate string `json:"releaseDate"` // YYYY-MM-DD, empty when not planned
+	Archived    bool   `json:"archived"`
+}
+
+// Milestones returns the versions released or due from..to, leaving out
+// archived ones
+func (j *jira) Milestones(ctx context.Context, from, to time.Time) ([]Milestone, error) {
+	endpoint := fmt.Sprintf("%s/rest/api/3/project/%s/versions", j.site, url.PathEscape(j.project))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(j.user, j.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch versions from Jira: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Jira API returned status %d", resp.StatusCode)
+	}
+
+	var versions []jiraVersion
+	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+		return nil, fmt.Errorf("failed to parse Jira versions: %w", err)
+	}
+
+	first, last := from.Format("2006-01-02"), to.Format("2006-01-02")
+	var milestones []Milestone
+	for _, v := range versions {
+		if v.Archived || v.ReleaseDate == "" || v.ReleaseDate < first || v.ReleaseDate > last {
+			continue
+		}
+		milestones = append(milestones, Milestone{Name: v.Name, StartDate: v.ReleaseDate, EndDate: v.ReleaseDate})
+	}
+	return milestones, nil
+}
@@ -0,0 +1,114 @@
+// Package milestones reads project deadlines from Jira (fixVersion release
+// dates), GitHub milestones or a calendar feed, so vacation can be kept away
+// from the weeks of releases.
+package milestones
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/sandbox"
+)
+
+// Milestone providers
+const (
+	ProviderNone   = "none"
+	ProviderJira   = "jira"
+	ProviderGitHub = "github"
+	ProviderICS    = "ics"
+)
+
+// Milestone is a project deadline over the days from StartDate to EndDate
+// (YYYY-MM-DD, inclusive), a single day for releases and due dates
+type Milestone struct {
+	Name      string
+	StartDate string
+	EndDate   string
+}
+
+// Reader reads the milestones of the configured project
+type Reader interface {
+	Milestones(ctx context.Context, from, to time.Time) ([]Milestone, error)
+}
+
+// Config selects and authenticates a provider
+type Config struct {
+	Provider string
+	APIKey   string         // Jira API token or GitHub token, optional for public repositories
+	User     string         // Jira account email
+	Project  string         // Jira project key or GitHub owner/repo
+	URL      string         // Jira site, such as https://acme.atlassian.net, or the calendar feed
+	Location *time.Location // Time zone due dates are turned into days in
+}
+
+// ProviderName returns the display name of a provider
+func ProviderName(provider string) string {
+	switch provider {
+	case ProviderJira:
+		return "Jira"
+	case ProviderGitHub:
+		return "GitHub"
+	case ProviderICS:
+		return "calendar feed"
+	}
+	return provider
+}
+
+// New returns the configured reader. In sandbox mode every provider is
+// replaced by canned milestones.
+func New(cfg Config) (Reader, error) {
+	if cfg.Provider == "" || cfg.Provider == ProviderNone {
+		return nil, errors.New("milestone import is not configured")
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+
+	if sandbox.Enabled() {
+		return sandboxReader{}, nil
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	switch cfg.Provider {
+	case ProviderJira:
+		if cfg.URL == "" || cfg.User == "" || cfg.APIKey == "" || cfg.Project == "" {
+			return nil, errors.New("Jira needs the site URL, your account email, an API token and the project key")
+		}
+		return &jira{client: client, site: strings.TrimRight(cfg.URL, "/"), user: cfg.User, token: cfg.APIKey, project: cfg.Project}, nil
+	case ProviderGitHub:
+		owner, repo, ok := strings.Cut(cfg.Project, "/")
+		if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+			return nil, errors.New("GitHub needs the repository as owner/repo")
+		}
+		return &github{client: client, token: cfg.APIKey, owner: owner, repo: repo, loc: cfg.Location}, nil
+	case ProviderICS:
+		if cfg.URL == "" {
+			return nil, errors.New("milestone_url is not set")
+		}
+		return &feed{client: client, url: cfg.URL}, nil
+	}
+	return nil, fmt.Errorf("unknown milestone provider %q", cfg.Provider)
+}
+
+// sandboxReader has a release on the second Thursday of June and of
+// November of each year in range
+type sandboxReader struct{}
+
+func (sandboxReader) Milestones(ctx context.Context, from, to time.Time) ([]Milestone, error) {
+	var milestones []Milestone
+	for year := from.Year(); year <= to.Year(); year++ {
+		for i, month := range []time.Month{time.June, time.November} {
+			day := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+			for day.Weekday() != time.Thursday {
+				day = day.AddDate(0, 0, 1)
+			}
+			date := day.AddDate(0, 0, 7).Format("2006-01-02")
+			milestones = append(milestones, Milestone{Name: fmt.Sprintf("Release %d.%d", year, i+1), StartDate: date, EndDate: date})
+		}
+	}
+	return milestones, nil
+}
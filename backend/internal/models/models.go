@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
 
 // Settings represents application settings
 type Settings struct {
@@ -13,15 +18,183 @@ type Settings struct {
 
 // YearConfig represents configuration for a specific year
 type YearConfig struct {
-	ID                   int64    `json:"id"`
-	Year                 int      `json:"year"`
-	VacationDays         int      `json:"vacation_days"`
-	ReservedDays         int      `json:"reserved_days"`
-	OptimizationStrategy string   `json:"optimization_strategy"`
-	WorkWeek             []string `json:"work_week"`
-	OptimizerNotes       string   `json:"optimizer_notes"`
-	CreatedAt            string   `json:"created_at"`
-	UpdatedAt            string   `json:"updated_at"`
+	ID                   int64            `json:"id"`
+	Year                 int              `json:"year"`
+	VacationDays         int              `json:"vacation_days"`
+	ReservedDays         int              `json:"reserved_days"`
+	OptimizationStrategy string           `json:"optimization_strategy"`
+	WorkWeek             []string         `json:"work_week"`
+	OptimizerNotes       string           `json:"optimizer_notes"`
+	AlignSchoolBreaks    bool             `json:"align_school_breaks"`
+	OptionalHolidays     []string         `json:"optional_holidays"` // Company optional holidays granted this year, by key
+	ScoringWeights       ScoringWeights   `json:"scoring_weights"`   // How the custom strategy ranks blocks
+	BlackoutPeriods      []BlackoutPeriod `json:"blackout_periods"`  // Date ranges closed to vacation
+	AvoidPeriods         []AvoidPeriod    `json:"avoid_periods"`     // Date ranges vacation should stay clear of
+	CreatedAt            string           `json:"created_at"`
+	UpdatedAt            string           `json:"updated_at"`
+	Version              int              `json:"version"` // Bumped by every change, sent as the ETag for If-Match
+
+	// WorkWeekChanges switch to a different work week from a date onwards,
+	// sorted by EffectiveFrom. WorkWeek applies until the first change.
+	WorkWeekChanges []WorkWeekChange `json:"work_week_changes"`
+
+	// Hours mode tracks the entitlement and bookings in hours, using the
+	// working hours of each weekday (e.g. 6h on Fridays for part-timers)
+	AccountingMode string             `json:"accounting_mode"` // "days" or "hours"
+	VacationHours  float64            `json:"vacation_hours"`  // Entitlement in hours mode, 0 derives it from vacation_days
+	WorkingHours   map[string]float64 `json:"working_hours"`   // Hours per weekday, missing days use DefaultWorkingHours
+}
+
+// ScoringWeights weigh what the custom strategy ranks vacation blocks by.
+// All zero uses DefaultScoringWeights.
+type ScoringWeights struct {
+	Efficiency      float64 `json:"efficiency"`       // Days off per vacation day
+	Length          float64 `json:"length"`           // Consecutive days off
+	Distribution    float64 `json:"distribution"`     // Weeks away from the blocks already picked, up to 13
+	MonthPreference float64 `json:"month_preference"` // Days off in the preferred months
+	PreferredMonths []int   `json:"preferred_months"` // 1 for January to 12 for December
+}
+
+// DefaultScoringWeights are the weights of the balanced strategy
+var DefaultScoringWeights = ScoringWeights{Efficiency: 0.6, Length: 0.4}
+
+// IsZero reports whether no weight is set
+func (w ScoringWeights) IsZero() bool {
+	return w.Efficiency == 0 && w.Length == 0 && w.Distribution == 0 && w.MonthPreference == 0
+}
+
+// BlackoutPeriod is a date range of a year when no vacation can be taken,
+// such as a release or a busy season
+type BlackoutPeriod struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Reason    string `json:"reason,omitempty"`
+	Source    string `json:"source,omitempty"` // BlackoutOnCall for imported on-call shifts, empty when entered by hand
+}
+
+// BlackoutOnCall is the source of blackout periods imported from the on-call
+// schedule, which every import replaces
+const BlackoutOnCall = "oncall"
+
+// AvoidPeriod is a date range of a year vacation should stay clear of, such
+// as the week of a release. Unlike a blackout period it can still be booked,
+// but the optimizer weighs each of its days like a personal event of
+// -Weight.
+type AvoidPeriod struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Reason    string `json:"reason,omitempty"`
+	Weight    int    `json:"weight"`           // From 1 to MaxEventWeight, DefaultAvoidWeight when not given
+	Source    string `json:"source,omitempty"` // AvoidMilestone for imported project milestones, empty when entered by hand
+}
+
+// DefaultAvoidWeight is the weight of avoid periods given without one
+const DefaultAvoidWeight = 3
+
+// AvoidMilestone is the source of avoid periods imported from project
+// milestones, which every import replaces
+const AvoidMilestone = "milestone"
+
+// InBlackout reports whether a YYYY-MM-DD date falls in a blackout period
+func (c YearConfig) InBlackout(date string) bool {
+	for _, p := range c.BlackoutPeriods {
+		if date >= p.StartDate && date <= p.EndDate {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultUnusedDaysShare is the percentage of the open work days left in a
+// year that can realistically be taken as vacation
+const DefaultUnusedDaysShare = 30
+
+// SchedulableDays returns how many vacation days can realistically still be
+// booked on the open work days left in a year, a share (percent) of them
+func SchedulableDays(openWorkDays, share int) int {
+	return openWorkDays * share / 100
+}
+
+// Accounting modes for the vacation balance
+const (
+	AccountingDays  = "days"
+	AccountingHours = "hours"
+)
+
+// DefaultWorkingHours is the length of a work day without an hours profile
+const DefaultWorkingHours = 8.0
+
+// HoursMode reports whether the vacation balance is tracked in hours
+func (c YearConfig) HoursMode() bool {
+	return c.AccountingMode == AccountingHours
+}
+
+// HoursOn returns the working hours of a date, 0 when it is not a work day
+func (c YearConfig) HoursOn(date time.Time) float64 {
+	if !c.IsWorkDay(date) {
+		return 0
+	}
+	if hours, ok := c.WorkingHours[strings.ToLower(date.Weekday().String())]; ok {
+		return hours
+	}
+	return DefaultWorkingHours
+}
+
+// AverageWorkingHours returns the mean hours of the work days in the base work week
+func (c YearConfig) AverageWorkingHours() float64 {
+	if len(c.WorkWeek) == 0 {
+		return DefaultWorkingHours
+	}
+	total := 0.0
+	for _, d := range c.WorkWeek {
+		if hours, ok := c.WorkingHours[strings.ToLower(d)]; ok {
+			total += hours
+		} else {
+			total += DefaultWorkingHours
+		}
+	}
+	return total / float64(len(c.WorkWeek))
+}
+
+// EntitlementHours returns the vacation entitlement in hours
+func (c YearConfig) EntitlementHours() float64 {
+	if c.VacationHours > 0 {
+		return c.VacationHours
+	}
+	return float64(c.VacationDays) * c.AverageWorkingHours()
+}
+
+// WorkWeekChange is a work week that takes effect on a date (e.g. a switch
+// to a four-day week from September)
+type WorkWeekChange struct {
+	ID            int64    `json:"id"`
+	Year          int      `json:"year"`
+	EffectiveFrom string   `json:"effective_from"`
+	WorkWeek      []string `json:"work_week"`
+}
+
+// WorkWeekOn returns the work week in effect on a date (YYYY-MM-DD)
+func (c YearConfig) WorkWeekOn(date string) []string {
+	workWeek := c.WorkWeek
+	for _, change := range c.WorkWeekChanges {
+		if change.EffectiveFrom > date {
+			break
+		}
+		workWeek = change.WorkWeek
+	}
+	return workWeek
+}
+
+// IsWorkDay reports whether a date falls on a work day of the schedule in
+// effect on that date (holidays and vacations are not considered)
+func (c YearConfig) IsWorkDay(date time.Time) bool {
+	dayName := strings.ToLower(date.Weekday().String())
+	for _, d := range c.WorkWeekOn(date.Format("2006-01-02")) {
+		if strings.ToLower(d) == dayName {
+			return true
+		}
+	}
+	return false
 }
 
 // VacationDay represents a vacation day
@@ -44,13 +217,407 @@ type OptimalVacation struct {
 	CreatedAt       string `json:"created_at"`
 }
 
+// LockedDate is a date whose vacation status can't change until it is
+// unlocked, such as a day off approved by a manager. The optimizer keeps
+// locked suggested days and never suggests a locked working day.
+type LockedDate struct {
+	ID        int64  `json:"id"`
+	Year      int    `json:"year"`
+	Date      string `json:"date"`
+	Reason    string `json:"reason,omitempty"`
+	LockedBy  string `json:"locked_by,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// BulkItemResult reports the outcome for one date of a bulk operation
+type BulkItemResult struct {
+	Date   string `json:"date"`
+	Action string `json:"action"` // "add", "remove" or "store"
+	Status string `json:"status"` // "applied", "unchanged", "created", "skipped_holiday", "skipped_duplicate", "over_budget", "failed" or "rolled_back"
+	Error  string `json:"error,omitempty"`
+}
+
+// VacationMove reschedules a vacation day to another date
+type VacationMove struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Ways of mapping vacation days onto another year when copying a plan
+const (
+	CopySameWeekday = "same_weekday" // The same weekday in the same week, "same weeks as last year"
+	CopySameDate    = "same_date"    // The same day of the same month
+)
+
+// CopiedVacation reports where a vacation day of a copied plan landed
+type CopiedVacation struct {
+	From    string `json:"from"`
+	To      string `json:"to,omitempty"`
+	Status  string `json:"status"`            // "created", "skipped_holiday", "skipped_non_work_day", "skipped_blackout", "skipped_duplicate", "skipped_locked", "outside_year" or "over_budget"
+	Holiday string `json:"holiday,omitempty"` // Name of the holiday now on the target date
+}
+
+// Scenario is a named vacation plan for a year
+type Scenario struct {
+	ID          int64  `json:"id"`
+	Year        int    `json:"year"`
+	Name        string `json:"name"`
+	IsActive    bool   `json:"is_active"`
+	ManualDays  int    `json:"manual_days"`
+	OptimalDays int    `json:"optimal_days"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// ShareLink gives read-only access to a year's calendar through /share/:token
+type ShareLink struct {
+	ID        int64  `json:"id"`
+	Token     string `json:"token"`
+	Year      int    `json:"year"`
+	Label     string `json:"label,omitempty"`
+	ExpiresAt string `json:"expires_at,omitempty"` // Never expires when empty
+	CreatedAt string `json:"created_at"`
+}
+
+// Booking rule kinds
+const (
+	RuleBridge              = "bridge"                // Propose the work days between a holiday and other days off
+	RuleMaxConsecutiveWeeks = "max_consecutive_weeks" // Drop proposals that would make a longer run of days off
+)
+
+// BookingRule is a standing booking policy evaluated against each year
+type BookingRule struct {
+	ID              int64    `json:"id"`
+	Name            string   `json:"name"`
+	Kind            string   `json:"kind"`
+	MaxGap          int      `json:"max_gap,omitempty"`          // bridge: most work days to bridge, 1 to 3
+	HolidayWeekdays []string `json:"holiday_weekdays,omitempty"` // bridge: only next to holidays on these weekdays, any when empty
+	MaxWeeks        int      `json:"max_weeks,omitempty"`        // max_consecutive_weeks
+	AutoApply       bool     `json:"auto_apply"`                 // Book proposals right away instead of leaving them for review
+	Enabled         bool     `json:"enabled"`
+}
+
+// Booking proposal states
+const (
+	ProposalPending   = "pending"
+	ProposalApplied   = "applied"
+	ProposalDismissed = "dismissed"
+)
+
+// BookingProposal is a vacation day a booking rule calls for
+type BookingProposal struct {
+	ID        int64  `json:"id"`
+	Year      int    `json:"year"`
+	Date      string `json:"date"`
+	RuleID    int64  `json:"rule_id"`
+	RuleName  string `json:"rule_name"`
+	Reason    string `json:"reason"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Comment targets
+const (
+	CommentDay   = "day"   // A vacation day
+	CommentBlock = "block" // A vacation block, by its start date
+)
+
+// Comment is a note on a vacation day or block. Replies name the comment
+// they answer in ParentID and share its target.
+type Comment struct {
+	ID        int64  `json:"id"`
+	Year      int    `json:"year"`
+	Date      string `json:"date"` // The day, or the first day of the block
+	Target    string `json:"target"`
+	ParentID  *int64 `json:"parent_id,omitempty"`
+	Author    string `json:"author"`
+	Body      string `json:"body"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// Access roles, from least to most privileged
+const (
+	RoleViewer  = "viewer"  // Reads calendars
+	RoleMember  = "member"  // Plans vacations
+	RoleManager = "manager" // Also credits comp days, adjusts balances, imports leave and manages year configuration, seniority rules and share links
+	RoleAdmin   = "admin"   // Also changes settings and AI keys and manages access tokens
+)
+
+// AccessToken authenticates API clients with a role. Only its hash is
+// stored, so the token is returned once, when it is created.
+type AccessToken struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	Token     string `json:"token,omitempty"`
+	ExpiresAt string `json:"expires_at,omitempty"` // RFC 3339 in UTC, empty for tokens that never expire
+	CreatedAt string `json:"created_at"`
+}
+
+// Authentication audit events
+const (
+	AuthEventFailure      = "failure" // A request with an unknown access token
+	AuthEventLockout      = "lockout" // A client locked out after too many failures
+	AuthEventUnlock       = "unlock"  // An admin lifted a lockout
+	AuthEventTokenCreated = "token_created"
+	AuthEventTokenDeleted = "token_deleted"
+	AuthEventSSOLogin     = "sso_login"  // A user signed in with single sign-on
+	AuthEventSSODenied    = "sso_denied" // A single sign-on user without a mapped role
+)
+
+// AuthEvent is an entry of the authentication audit log
+type AuthEvent struct {
+	ID        int64  `json:"id"`
+	Event     string `json:"event"`
+	Client    string `json:"client,omitempty"` // IP address
+	TokenName string `json:"token_name,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// IdempotentRequest is a POST or PUT request sent with an Idempotency-Key
+// and, once it succeeded, the response replayed to its retries
+type IdempotentRequest struct {
+	Scope       string // Access token ID, empty while the API is open
+	Key         string
+	Method      string
+	Path        string
+	RequestHash string // SHA-256 of the method, path and body
+	Status      int    // 0 while the first request is still running
+	ContentType string
+	Body        []byte
+	CreatedAt   string
+}
+
+// AccessInfo describes the caller's access
+type AccessInfo struct {
+	AccessControl bool   `json:"access_control"` // False while no tokens exist and the API is open
+	Name          string `json:"name,omitempty"`
+	Role          string `json:"role"`
+}
+
+// SharedCalendar is the part of a calendar shown through a share link:
+// no configuration, balance, notes or settings
+type SharedCalendar struct {
+	Year           int             `json:"year"`
+	Label          string          `json:"label,omitempty"`
+	Days           []CalendarDay   `json:"days"`
+	Holidays       []Holiday       `json:"holidays"`
+	VacationBlocks []VacationBlock `json:"vacation_blocks"`
+}
+
 // Holiday represents a Portuguese holiday
 type Holiday struct {
+	ID          int64  `json:"id"`
+	Year        int    `json:"year"`
+	Date        string `json:"date"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	ObservedFor string `json:"observed_for,omitempty"` // Original date of an observed holiday
+}
+
+// HolidayChange is a holiday added, changed or removed after a point in
+// time, for incremental sync. A removed holiday is a tombstone, Deleted with
+// its last name.
+type HolidayChange struct {
+	Date        string `json:"date"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Location    string `json:"location"`
+	ObservedFor string `json:"observed_for,omitempty"`
+	Deleted     bool   `json:"deleted"`
+	ChangedAt   string `json:"changed_at"`
+}
+
+// SeniorityRule grants extra vacation days from a number of completed years of service
+type SeniorityRule struct {
+	ID             int64 `json:"id"`
+	YearsOfService int   `json:"years_of_service"`
+	ExtraDays      int   `json:"extra_days"`
+}
+
+// Entitlement is the default vacation allowance computed for a year
+type Entitlement struct {
+	Year                int    `json:"year"`
+	EmploymentStartDate string `json:"employment_start_date,omitempty"`
+	YearsOfService      int    `json:"years_of_service"` // Completed on January 1
+	BaseDays            int    `json:"base_days"`
+	ExtraDays           int    `json:"extra_days"`
+	VacationDays        int    `json:"vacation_days"`
+}
+
+// WorkedHoliday is a holiday the user works on, earning a compensation day
+type WorkedHoliday struct {
 	ID   int64  `json:"id"`
 	Year int    `json:"year"`
 	Date string `json:"date"`
 	Name string `json:"name"`
-	Type string `json:"type"`
+	Note string `json:"note,omitempty"`
+}
+
+// HolidayOverride corrects or suppresses a holiday the APIs return for a
+// date, wherever the holidays of its year are used
+type HolidayOverride struct {
+	ID        int64  `json:"id"`
+	Year      int    `json:"year"`
+	Date      string `json:"date"`               // Date of the holiday as the APIs return it
+	Action    string `json:"action"`             // "correct" or "suppress"
+	NewDate   string `json:"new_date,omitempty"` // Date the holiday is on instead
+	Name      string `json:"name,omitempty"`     // Name the holiday takes instead
+	Note      string `json:"note,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+// Trip is travel planned over a date range of a year. It groups the vacation
+// days in the range, so blocks are tied to where they are spent.
+type Trip struct {
+	ID            int64    `json:"id"`
+	Year          int      `json:"year"`
+	Name          string   `json:"name"`
+	Destination   string   `json:"destination"`
+	StartDate     string   `json:"start_date"`
+	EndDate       string   `json:"end_date"`
+	Notes         string   `json:"notes"`
+	Budget        float64  `json:"budget"`         // Estimated cost
+	Spent         float64  `json:"spent"`          // Sum of the trip's expenses
+	VacationDates []string `json:"vacation_dates"` // Manual and optimal vacation days in the range
+	CreatedAt     string   `json:"created_at"`
+	UpdatedAt     string   `json:"updated_at"`
+}
+
+// Contains reports whether a date is within the trip
+func (t Trip) Contains(date string) bool {
+	return date >= t.StartDate && date <= t.EndDate
+}
+
+// PersonalEvent is an important date of the user's, such as an anniversary,
+// a concert or a race. It is not a day off, but a positive weight draws the
+// optimizer's vacation to it and a negative one keeps vacation away.
+type PersonalEvent struct {
+	ID        int64  `json:"id"`
+	Year      int    `json:"year"`
+	Date      string `json:"date"`
+	Name      string `json:"name"`
+	Notes     string `json:"notes"`
+	Weight    int    `json:"weight"` // From -MaxEventWeight (repel) to MaxEventWeight (attract)
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// MaxEventWeight bounds the weight of a personal event either way
+const MaxEventWeight = 5
+
+// TripExpense is money spent on a trip
+type TripExpense struct {
+	ID          int64   `json:"id"`
+	TripID      int64   `json:"trip_id"`
+	Date        string  `json:"date,omitempty"`
+	Description string  `json:"description"`
+	Category    string  `json:"category,omitempty"` // Free text, e.g. "flights" or "lodging"
+	Amount      float64 `json:"amount"`
+	CreatedAt   string  `json:"created_at"`
+}
+
+// TripBudget is the budget and spending of one trip
+type TripBudget struct {
+	TripID    int64   `json:"trip_id"`
+	Name      string  `json:"name"`
+	Budget    float64 `json:"budget"`
+	Spent     float64 `json:"spent"`
+	Remaining float64 `json:"remaining"` // Negative when over budget
+}
+
+// TripBudgetSummary adds up the budgets and expenses of a year's trips
+type TripBudgetSummary struct {
+	Year           int                `json:"year"`
+	Trips          []TripBudget       `json:"trips"`
+	TotalBudget    float64            `json:"total_budget"`
+	TotalSpent     float64            `json:"total_spent"`
+	TotalRemaining float64            `json:"total_remaining"`
+	ByCategory     map[string]float64 `json:"by_category"` // Spending per expense category, "" for uncategorized
+}
+
+// OptionalHoliday is a company optional holiday (Carnaval, Christmas Eve)
+// and whether the year grants it
+type OptionalHoliday struct {
+	Key     string `json:"key"`
+	Date    string `json:"date"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Kinds of compensation day ledger entries
+const (
+	CompCredit = "credit" // A non-work day worked, earning a day off
+	CompSpend  = "spend"  // A work day taken off from the compensation pool
+)
+
+// CompDay is an entry of the compensation day ledger
+type CompDay struct {
+	ID   int64  `json:"id"`
+	Year int    `json:"year"`
+	Date string `json:"date"`
+	Kind string `json:"kind"`
+	Note string `json:"note,omitempty"`
+}
+
+// Kinds of balance adjustments
+const (
+	AdjustmentBonus      = "bonus"      // Extra days granted, such as a reward
+	AdjustmentPurchased  = "purchased"  // Leave bought from the employer
+	AdjustmentCorrection = "correction" // Fixes the balance either way
+)
+
+// BalanceAdjustment grants (positive Days) or deducts (negative Days)
+// vacation days by hand, through the compensation pool
+type BalanceAdjustment struct {
+	ID     int64  `json:"id"`
+	Year   int    `json:"year"`
+	Date   string `json:"date"`
+	Days   int    `json:"days"`
+	Kind   string `json:"kind"`
+	Reason string `json:"reason"`
+}
+
+// CompLedger is the compensation day pool of a year. It is kept apart from
+// the annual leave, which pays for vacation days first; the pool covers the
+// vacation days beyond it.
+type CompLedger struct {
+	Year            int                 `json:"year"`
+	Entries         []CompDay           `json:"entries"`
+	Adjustments     []BalanceAdjustment `json:"adjustments"`
+	WorkedHolidays  int                 `json:"worked_holidays"` // Credited automatically
+	Earned          int                 `json:"earned"`
+	Spent           int                 `json:"spent"`             // Taken as comp days off
+	Adjusted        int                 `json:"adjusted"`          // Net days granted and deducted by hand
+	UsedForVacation int                 `json:"used_for_vacation"` // Vacation days beyond the annual leave
+	Balance         int                 `json:"balance"`           // Negative when deductions exceed the pool
+}
+
+// CoverVacation pays the vacation days used beyond the annual leave from
+// the pool, as far as its balance goes
+func (l *CompLedger) CoverVacation(used, annual int) {
+	available := l.Earned - l.Spent + l.Adjusted
+	l.UsedForVacation = 0
+	if overflow := used - annual; overflow > 0 && available > 0 {
+		l.UsedForVacation = overflow
+		if overflow > available {
+			l.UsedForVacation = available
+		}
+	}
+	l.Balance = available - l.UsedForVacation
+}
+
+// SpentDates returns the dates taken as comp days off
+func (l CompLedger) SpentDates() []string {
+	var spent []string
+	for _, e := range l.Entries {
+		if e.Kind == CompSpend {
+			spent = append(spent, e.Date)
+		}
+	}
+	return spent
 }
 
 // ChatMessage represents a message in the chat history
@@ -59,53 +626,422 @@ type ChatMessage struct {
 	Year      int    `json:"year"`
 	Role      string `json:"role"`
 	Content   string `json:"content"`
+	Model     string `json:"model,omitempty"` // Model that answered
 	CreatedAt string `json:"created_at"`
 }
 
+// AISuggestion is the last AI suggestion for a year in one language, with a
+// hash of the inputs it was generated from
+type AISuggestion struct {
+	Year       int    `json:"year"`
+	Language   string `json:"language"`
+	InputHash  string `json:"input_hash"`
+	Suggestion string `json:"suggestion"`
+	Model      string `json:"model,omitempty"` // Model that answered
+	CreatedAt  string `json:"created_at"`
+}
+
+// AIModel is a model of the configured AI provider, with what its catalog
+// tells about it. Unknown limits are zero and unknown tool support nil.
+type AIModel struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Publisher       string `json:"publisher"`
+	ContextLength   int    `json:"context_length,omitempty"`    // Input tokens
+	MaxOutputTokens int    `json:"max_output_tokens,omitempty"` // Tokens per answer
+	Tier            string `json:"tier,omitempty"`              // Rate limit and pricing tier, such as low or high
+	ToolCalling     *bool  `json:"tool_calling,omitempty"`
+	Suitable        bool   `json:"suitable"`
+	Reason          string `json:"reason,omitempty"` // Why the model can't be used for chat and optimization
+}
+
+// AIValidation is the outcome of a test call to the configured AI provider
+type AIValidation struct {
+	Valid     bool              `json:"valid"`
+	Provider  string            `json:"provider"`
+	Model     string            `json:"model"`
+	LatencyMS int64             `json:"latency_ms"`
+	Status    int               `json:"status,omitempty"` // HTTP status of a failed call, when the provider answered
+	Error     string            `json:"error,omitempty"`
+	Quota     map[string]string `json:"quota,omitempty"` // The provider's x-ratelimit-* headers, without the prefix
+}
+
+// OutOfOfficeDraft is an AI-written out-of-office auto-reply and handover
+// summary for one vacation block
+type OutOfOfficeDraft struct {
+	Year       int    `json:"year"`
+	StartDate  string `json:"start_date"`  // First day of the block
+	EndDate    string `json:"end_date"`    // Last day of the block
+	ReturnDate string `json:"return_date"` // Day after the block
+	Language   string `json:"language"`    // "en" or "pt-PT"
+	Subject    string `json:"subject"`
+	AutoReply  string `json:"auto_reply"`
+	Handover   string `json:"handover"`
+	Model      string `json:"model,omitempty"` // Model that answered
+}
+
 // VacationBlock represents a block of consecutive vacation days
 type VacationBlock struct {
-	StartDate       string   `json:"start_date"`
-	EndDate         string   `json:"end_date"`
-	TotalDays       int      `json:"total_days"`
-	VacationDaysUsed int     `json:"vacation_days_used"`
-	Dates           []string `json:"dates"`
-	Holidays        []string `json:"holidays"`
-	Weekends        []string `json:"weekends"`
+	StartDate        string   `json:"start_date"`
+	EndDate          string   `json:"end_date"`
+	TotalDays        int      `json:"total_days"`
+	VacationDaysUsed int      `json:"vacation_days_used"`
+	Dates            []string `json:"dates"`
+	Holidays         []string `json:"holidays"`
+	Weekends         []string `json:"weekends"`
+	Efficiency       float64  `json:"efficiency"`        // Total days off per vacation day used
+	Source           string   `json:"source,omitempty"`  // "manual", "optimized" or "mixed" when they run into each other
+	TripID           int64    `json:"trip_id,omitempty"` // Trip overlapping the block
+	Label            string   `json:"label,omitempty"`   // Name given to the block, such as "Summer trip"
+	Color            string   `json:"color,omitempty"`   // #rrggbb given to the block
+
+	Rationales map[string]string `json:"rationales,omitempty"` // Why the AI optimizer picked each vacation day, by date
+
+	FlightPrice *FlightQuote `json:"flight_price,omitempty"` // Indicative round trip over the block
+}
+
+// BlockLabel is the name and color given to a vacation block. It stays
+// with the block containing Date, the block's first day when it was set.
+type BlockLabel struct {
+	Year  int    `json:"year"`
+	Date  string `json:"date"`
+	Label string `json:"label"`
+	Color string `json:"color"`
+}
+
+// DeclinedBlock is a suggested vacation block the user turned down. Later
+// optimizations don't take its vacation days.
+type DeclinedBlock struct {
+	ID        int64    `json:"id"`
+	Year      int      `json:"year"`
+	StartDate string   `json:"start_date"`
+	EndDate   string   `json:"end_date"`
+	Dates     []string `json:"dates"` // The optimized vacation days excluded
+	CreatedAt string   `json:"created_at"`
+}
+
+// FlightQuote is the cheapest round-trip flight found for a vacation block,
+// leaving on its first day off and returning on its last
+type FlightQuote struct {
+	Origin      string  `json:"origin"`
+	Destination string  `json:"destination"`
+	DepartDate  string  `json:"depart_date"`
+	ReturnDate  string  `json:"return_date"`
+	Price       float64 `json:"price"`
+	Currency    string  `json:"currency"`
+	Link        string  `json:"link,omitempty"`
+}
+
+// FlightPriceResponse lists the upcoming suggested vacation blocks of a year
+// with their flight prices. Blocks without offers have no flight_price.
+type FlightPriceResponse struct {
+	Year        int             `json:"year"`
+	Provider    string          `json:"provider"`
+	Origin      string          `json:"origin"`
+	Destination string          `json:"destination"`
+	Blocks      []VacationBlock `json:"blocks"`
+}
+
+// BlockEfficiency returns days off gained per vacation day used, rounded to two decimals
+func BlockEfficiency(totalDays, vacationDaysUsed int) float64 {
+	if vacationDaysUsed <= 0 {
+		return 0
+	}
+	return math.Round(float64(totalDays)/float64(vacationDaysUsed)*100) / 100
 }
 
 // CalendarDay represents a single day in the calendar
 type CalendarDay struct {
-	Date        string `json:"date"`
-	DayOfWeek   string `json:"day_of_week"`
-	IsWeekend   bool   `json:"is_weekend"`
-	IsHoliday   bool   `json:"is_holiday"`
-	HolidayName string `json:"holiday_name,omitempty"`
-	IsVacation  bool   `json:"is_vacation"`
-	IsManual    bool   `json:"is_manual"`
-	IsOptimal   bool   `json:"is_optimal"`
-	BlockID     int    `json:"block_id,omitempty"`
+	Date              string   `json:"date"`
+	DayOfWeek         string   `json:"day_of_week"`
+	IsWeekend         bool     `json:"is_weekend"`
+	IsHoliday         bool     `json:"is_holiday"`
+	HolidayName       string   `json:"holiday_name,omitempty"`
+	IsVacation        bool     `json:"is_vacation"`
+	IsManual          bool     `json:"is_manual"`
+	IsOptimal         bool     `json:"is_optimal"`
+	IsCompDay         bool     `json:"is_comp_day"`
+	IsLocked          bool     `json:"is_locked,omitempty"`
+	IsBridgeCandidate bool     `json:"is_bridge_candidate,omitempty"` // Lone work day between a holiday and another day off
+	BlockID           int      `json:"block_id,omitempty"`
+	TripID            int64    `json:"trip_id,omitempty"`
+	FamilyOff         []string `json:"family_off,omitempty"` // Family members whose school or childcare is closed
+	Events            []string `json:"events,omitempty"`     // Names of the personal events on the day
 }
 
 // CalendarResponse represents the full calendar data for a year
 type CalendarResponse struct {
-	Year             int             `json:"year"`
-	Config           YearConfig      `json:"config"`
-	Days             []CalendarDay   `json:"days"`
-	Holidays         []Holiday       `json:"holidays"`
-	VacationBlocks   []VacationBlock `json:"vacation_blocks"`
-	ManualVacations  []VacationDay   `json:"manual_vacations"`
+	Year             int               `json:"year"`
+	Config           YearConfig        `json:"config"`
+	Days             []CalendarDay     `json:"days"`
+	Holidays         []Holiday         `json:"holidays"`
+	VacationBlocks   []VacationBlock   `json:"vacation_blocks"`
+	ManualVacations  []VacationDay     `json:"manual_vacations"`
 	OptimalVacations []OptimalVacation `json:"optimal_vacations"`
-	Summary          CalendarSummary `json:"summary"`
+	Trips            []Trip            `json:"trips"`
+	PersonalEvents   []PersonalEvent   `json:"personal_events"`
+	Family           []FamilyMember    `json:"family"` // With the closures overlapping the year
+	Summary          CalendarSummary   `json:"summary"`
+}
+
+// YearCalendar is one year of a multi-year calendar: its summary and
+// vacation blocks, with the days when asked for
+type YearCalendar struct {
+	Year           int             `json:"year"`
+	Config         YearConfig      `json:"config"`
+	Summary        CalendarSummary `json:"summary"`
+	VacationBlocks []VacationBlock `json:"vacation_blocks"`
+	Days           []CalendarDay   `json:"days,omitempty"`
+}
+
+// Sources of family closures
+const (
+	ClosureCustom = "custom" // Entered by hand
+	ClosureSchool = "school" // A break of the member's school district
+)
+
+// FamilyMember is someone the vacations are planned around, such as a
+// child: no vacation budget, only the days their school or childcare is
+// closed
+type FamilyMember struct {
+	ID             int64           `json:"id"`
+	Name           string          `json:"name"`
+	SchoolDistrict string          `json:"school_district,omitempty"` // Adds the district's school breaks as closures
+	PlanAround     bool            `json:"plan_around"`               // The optimizer prefers vacation during their closures
+	Closures       []FamilyClosure `json:"closures"`
+	CreatedAt      string          `json:"created_at"`
+}
+
+// FamilyClosure is a period a family member's school or childcare is closed
+type FamilyClosure struct {
+	ID        int64  `json:"id,omitempty"` // 0 for school breaks
+	MemberID  int64  `json:"member_id"`
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Source    string `json:"source"`
+}
+
+// Kinds of day off, in the order they take precedence on the same date
+const (
+	DayOffHoliday  = "holiday"
+	DayOffVacation = "vacation"
+	DayOffCompDay  = "comp_day"
+	DayOffWeekend  = "weekend" // Any day outside the work week
+)
+
+// DayOff is an upcoming day off
+type DayOff struct {
+	Date      string `json:"date"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name,omitempty"` // Holiday name
+	DaysUntil int    `json:"days_until"`     // 0 when it is today
+}
+
+// DayWorkDay is the kind of a day that is not off
+const DayWorkDay = "work_day"
+
+// DayStatus classifies a single date of the calendar
+type DayStatus struct {
+	CalendarDay
+	Kind       string         `json:"kind"` // One of the DayOff kinds, or DayWorkDay
+	IsDayOff   bool           `json:"is_day_off"`
+	IsWorkDay  bool           `json:"is_work_day"` // In the work week in effect on the date, holidays and vacations aside
+	InBlackout bool           `json:"in_blackout,omitempty"`
+	Holiday    *Holiday       `json:"holiday,omitempty"`
+	Block      *VacationBlock `json:"block,omitempty"` // Vacation block the day is part of
+}
+
+// NextBreak tells how long until the next day off and the next vacation
+// block, counted from the user's current day
+type NextBreak struct {
+	Today             string         `json:"today"`
+	NextDayOff        *DayOff        `json:"next_day_off"`
+	NextVacation      *VacationBlock `json:"next_vacation"`       // Block in progress or the next one to start
+	DaysUntilVacation int            `json:"days_until_vacation"` // 0 while the block is in progress
+}
+
+// LongWeekend is a stretch of at least 3 days off around a holiday that
+// takes at most one vacation day
+type LongWeekend struct {
+	StartDate    string   `json:"start_date"`
+	EndDate      string   `json:"end_date"`
+	TotalDays    int      `json:"total_days"`
+	VacationDays int      `json:"vacation_days"`           // 0 or 1
+	VacationDate string   `json:"vacation_date,omitempty"` // Work day to take off when vacation_days is 1
+	Holidays     []string `json:"holidays"`                // Names of the holidays in the stretch
 }
 
 // CalendarSummary provides statistics about the calendar
 type CalendarSummary struct {
-	TotalVacationDays    int `json:"total_vacation_days"`
-	UsedVacationDays     int `json:"used_vacation_days"`
-	RemainingVacationDays int `json:"remaining_vacation_days"`
-	TotalHolidays        int `json:"total_holidays"`
-	LongestVacationBlock int `json:"longest_vacation_block"`
-	TotalDaysOff         int `json:"total_days_off"`
+	TotalVacationDays     int              `json:"total_vacation_days"`
+	CompensationDays      int              `json:"compensation_days"` // Days in lieu earned, a separate pool from the total
+	CompDaysUsed          int              `json:"comp_days_used"`    // Taken as comp days off or covering vacation beyond the total
+	CompDaysRemaining     int              `json:"comp_days_remaining"`
+	UsedVacationDays      int              `json:"used_vacation_days"`
+	RemainingVacationDays int              `json:"remaining_vacation_days"`
+	TotalHolidays         int              `json:"total_holidays"`
+	LongestVacationBlock  int              `json:"longest_vacation_block"`
+	TotalDaysOff          int              `json:"total_days_off"`
+	Efficiency            float64          `json:"efficiency"` // Days off in vacation blocks per vacation day used
+	QuarterDistribution   []QuarterSummary `json:"quarter_distribution"`
+	StreakDistribution    []StreakCount    `json:"streak_distribution"` // Streaks of days off by length, shortest first
+
+	// Vacation days that can realistically still be booked this year, and
+	// whether more are left than that
+	SchedulableDays  int  `json:"schedulable_days"`
+	UnusedDaysAtRisk bool `json:"unused_days_at_risk"`
+
+	// Balance in hours, only filled in hours mode
+	TotalVacationHours     float64 `json:"total_vacation_hours"`
+	UsedVacationHours      float64 `json:"used_vacation_hours"`
+	RemainingVacationHours float64 `json:"remaining_vacation_hours"`
+}
+
+// QuarterSummary describes how vacation time is spread over a quarter
+type QuarterSummary struct {
+	Quarter      int     `json:"quarter"`
+	VacationDays int     `json:"vacation_days"`
+	DaysOff      int     `json:"days_off"`
+	Efficiency   float64 `json:"efficiency"`
+}
+
+// StreakCount is how many streaks of consecutive days off of a length a
+// year has
+type StreakCount struct {
+	Days  int `json:"days"`
+	Count int `json:"count"`
+}
+
+// MonthSummary holds the vacation statistics for a single month
+type MonthSummary struct {
+	Month           int `json:"month"`
+	VacationDays    int `json:"vacation_days"`
+	Holidays        int `json:"holidays"`
+	DaysOff         int `json:"days_off"`         // Weekends, holidays and vacation days
+	LongestStreak   int `json:"longest_streak"`   // Longest run of consecutive days off within the month
+	RemainingBudget int `json:"remaining_budget"` // Vacation days left at the end of the month
+}
+
+// CalendarStats is the per-month and per-quarter breakdown of a year
+type CalendarStats struct {
+	Year     int              `json:"year"`
+	Months   []MonthSummary   `json:"months"`
+	Quarters []QuarterSummary `json:"quarters"`
+	Summary  CalendarSummary  `json:"summary"`
+}
+
+// YearHistory is one year of the historical statistics
+type YearHistory struct {
+	Year               int     `json:"year"`
+	VacationDays       int     `json:"vacation_days"` // Annual leave
+	DaysTaken          int     `json:"days_taken"`    // Manual and optimized vacation days
+	Blocks             int     `json:"blocks"`
+	AverageBlockLength float64 `json:"average_block_length"` // Days off per vacation block
+	Efficiency         float64 `json:"efficiency"`
+	UnusedDays         int     `json:"unused_days"`
+	Lost               bool    `json:"lost"` // The unused days expired with the carryover
+}
+
+// MonthCount is how many vacation days were taken in a month over the years
+type MonthCount struct {
+	Month        int `json:"month"`
+	VacationDays int `json:"vacation_days"`
+}
+
+// HistoricalStats aggregates the vacation history across years
+type HistoricalStats struct {
+	Years              []YearHistory `json:"years"`
+	TotalDaysTaken     int           `json:"total_days_taken"`
+	AverageBlockLength float64       `json:"average_block_length"`
+	EfficiencyTrend    float64       `json:"efficiency_trend"`   // Change in efficiency per year
+	MostCommonMonths   []MonthCount  `json:"most_common_months"` // Months with vacation days, most first
+	UnusedDaysLost     int           `json:"unused_days_lost"`
+}
+
+// LeaveImport is the outcome of importing approved leave from an HR system
+type LeaveImport struct {
+	Year     int      `json:"year"`
+	Provider string   `json:"provider"`
+	Imported []string `json:"imported"` // New manual vacation days
+	Existing int      `json:"existing"` // Approved days already planned as manual vacation
+	Skipped  int      `json:"skipped"`  // Approved days that are not work days, or are holidays
+}
+
+// OnCallImport is the outcome of importing the on-call shifts of a year as
+// blackout periods
+type OnCallImport struct {
+	Year      int              `json:"year"`
+	Provider  string           `json:"provider"`
+	Periods   []BlackoutPeriod `json:"periods"`   // On-call periods of the year, replacing the previous ones
+	Conflicts []string         `json:"conflicts"` // Vacation days already booked in them
+}
+
+// MilestoneImport is the outcome of importing the project milestones of a
+// year as avoid periods
+type MilestoneImport struct {
+	Year     int           `json:"year"`
+	Provider string        `json:"provider"`
+	Periods  []AvoidPeriod `json:"periods"` // Weeks of the milestones, replacing the previous ones
+}
+
+// YearData counts the stored rows of a year, per table
+type YearData struct {
+	Year   int              `json:"year"`
+	Tables map[string]int64 `json:"tables"`
+	Total  int64            `json:"total"`
+}
+
+// YearArchive is the content of an archive of past years: every row of
+// their year-scoped tables, by table
+type YearArchive struct {
+	Years      []int                               `json:"years"`
+	ArchivedAt string                              `json:"archived_at"`
+	Tables     map[string][]map[string]interface{} `json:"tables"`
+}
+
+// ProblemContentType is the media type of error responses
+const ProblemContentType = "application/problem+json"
+
+// Error codes of problem responses. Clients branch on the code, which
+// doesn't change; the detail is meant for people and may.
+const (
+	CodeInvalidRequest  = "invalid_request"  // Malformed body or parameter
+	CodeInvalidYear     = "invalid_year"     // Year outside the supported range
+	CodeInvalidDate     = "invalid_date"     // Not a YYYY-MM-DD date, or not in the year
+	CodeHolidayConflict = "holiday_conflict" // A day off requested on a holiday
+	CodeBudgetExceeded  = "budget_exceeded"  // Not enough days left in a pool
+	CodeAIUnconfigured  = "ai_unconfigured"  // No AI provider key set
+	CodeNotConfigured   = "not_configured"   // A setting the feature needs is not set
+	CodeNotFound        = "not_found"
+	CodeConflict        = "conflict"         // The resource's state doesn't allow the change
+	CodeVersionConflict = "version_conflict" // Changed since the version in If-Match was read
+	CodeEditLocked      = "edit_locked"      // Another client holds the year's edit lock
+	CodeDateLocked      = "date_locked"      // The date is locked against changes
+	CodePastDate        = "past_date"        // The vacation day is past the grace period
+	CodeUnauthenticated = "unauthenticated"
+	CodeForbidden       = "forbidden"
+	CodeUpstreamError   = "upstream_error" // An external service failed
+	CodeUnavailable     = "unavailable"    // The server or tenant can't serve requests yet
+	CodeRateLimited     = "rate_limited"   // Retry after the Retry-After header
+	CodeLockedOut       = "locked_out"     // Too many failed access token attempts
+	CodeInternalError   = "internal_error"
+)
+
+// Problem is an RFC 9457 problem details object. Type is about:blank, so
+// Title is the HTTP status text and Code tells problems apart.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
+
+// NewProblem returns the problem for an error status and code
+func NewProblem(status int, code, detail string) Problem {
+	return Problem{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: detail, Code: code}
 }
 
 // OptimizationStrategy constants
@@ -114,6 +1050,9 @@ const (
 	StrategyLongestBlocks  = "longest_blocks"
 	StrategyBalanced       = "balanced"
 	StrategySmart          = "smart"
+	StrategyHybrid         = "hybrid"
+	StrategyLocalSearch    = "local_search"
+	StrategyCustom         = "custom"
 )
 
 // WorkWeek days
@@ -0,0 +1,102 @@
+package notifications
+
+import (
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// maxBridgeDays is the longest run of work days considered a bridge
+const maxBridgeDays = 2
+
+// Bridge is a short run of work days between a holiday and other days off
+// that turns into a long break when taken as vacation
+type Bridge struct {
+	Dates        []string
+	Start        time.Time
+	End          time.Time
+	DaysOff      int
+	VacationDays int
+	Holiday      string
+	Efficiency   float64
+}
+
+// BestBridge finds the bridge between from and until with the most days off
+// per vacation day
+func (n *Notifier) BestBridge(from, until time.Time) (Bridge, bool, error) {
+	from = dates.Civil(from)
+	until = dates.Civil(until)
+	view, err := n.loadCalendarView(from.Year(), until.Year()+1)
+	if err != nil {
+		return Bridge{}, false, err
+	}
+
+	var best Bridge
+	found := false
+
+	for d := from; !d.After(until); d = d.AddDate(0, 0, 1) {
+		// A bridge starts on a work day right after a day off
+		if view.isOff(d) || !view.isOff(d.AddDate(0, 0, -1)) {
+			continue
+		}
+
+		gapEnd := d
+		for gapEnd.Before(until) && !view.isOff(gapEnd.AddDate(0, 0, 1)) {
+			gapEnd = gapEnd.AddDate(0, 0, 1)
+		}
+		gap := int(gapEnd.Sub(d).Hours()/24) + 1
+		if gap > maxBridgeDays {
+			continue
+		}
+
+		before, start, holidayBefore := view.offRun(d.AddDate(0, 0, -1), -1)
+		after, end, holidayAfter := view.offRun(gapEnd.AddDate(0, 0, 1), 1)
+
+		holiday := holidayBefore
+		if holiday == "" {
+			holiday = holidayAfter
+		}
+		if holiday == "" {
+			continue
+		}
+
+		bridge := Bridge{
+			Start:        start,
+			End:          end,
+			DaysOff:      before + gap + after,
+			VacationDays: gap,
+			Holiday:      holiday,
+		}
+		for g := d; !g.After(gapEnd); g = g.AddDate(0, 0, 1) {
+			bridge.Dates = append(bridge.Dates, g.Format("2006-01-02"))
+		}
+		bridge.Efficiency = models.BlockEfficiency(bridge.DaysOff, bridge.VacationDays)
+
+		if !found || bridge.Efficiency > best.Efficiency ||
+			(bridge.Efficiency == best.Efficiency && bridge.DaysOff > best.DaysOff) {
+			best = bridge
+			found = true
+		}
+	}
+
+	return best, found, nil
+}
+
+// offRun counts the consecutive days off from a date in the given direction,
+// returning the count, the last day off and the first holiday found
+func (v calendarView) offRun(from time.Time, step int) (int, time.Time, string) {
+	count := 0
+	last := from
+	holiday := ""
+
+	for d := from; v.isOff(d) && count < 31; d = d.AddDate(0, 0, step) {
+		count++
+		last = d
+		if name, ok := v.holidays[d.Format("2006-01-02")]; ok && holiday == "" {
+			holiday = name
+		}
+	}
+
+	return count, last, holiday
+}
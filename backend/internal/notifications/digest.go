@@ -0,0 +1,161 @@
+package notifications
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+var (
+	digestFuncs = map[string]interface{}{"join": strings.Join}
+
+	monthlyDigestHTML = htmltemplate.Must(htmltemplate.New("monthly_digest.html").Funcs(digestFuncs).ParseFS(templateFS, "templates/monthly_digest.html"))
+	monthlyDigestText = texttemplate.Must(texttemplate.New("monthly_digest.txt").Funcs(digestFuncs).ParseFS(templateFS, "templates/monthly_digest.txt"))
+)
+
+// DigestHoliday is a holiday listed in a digest
+type DigestHoliday struct {
+	Date time.Time
+	Name string
+}
+
+// MonthlyDigest is the data rendered into the monthly digest templates
+type MonthlyDigest struct {
+	Year          int
+	MonthName     string
+	Holidays      []DigestHoliday
+	Vacations     []TimeOff
+	DaysRemaining int
+	DaysTotal     int
+	Bridge        *Bridge
+}
+
+// BuildMonthlyDigest collects the digest data for the month containing date
+func (n *Notifier) BuildMonthlyDigest(date time.Time) (MonthlyDigest, error) {
+	monthStart := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+	year := monthStart.Year()
+
+	digest := MonthlyDigest{
+		Year:      year,
+		MonthName: monthStart.Format("January 2006"),
+	}
+
+	view, err := n.loadCalendarView(year, year)
+	if err != nil {
+		return digest, err
+	}
+	for dateStr, name := range view.holidays {
+		d, err := dates.Parse(dateStr)
+		if err == nil && d.Month() == monthStart.Month() {
+			digest.Holidays = append(digest.Holidays, DigestHoliday{Date: d, Name: name})
+		}
+	}
+	sort.Slice(digest.Holidays, func(i, j int) bool {
+		return digest.Holidays[i].Date.Before(digest.Holidays[j].Date)
+	})
+
+	periods, err := n.UpcomingTimeOff(monthStart, monthEnd)
+	if err != nil {
+		return digest, err
+	}
+	for _, period := range periods {
+		if period.VacationDays > 0 {
+			digest.Vacations = append(digest.Vacations, period)
+		}
+	}
+
+	digest.DaysTotal = n.allowance(year)
+	vacations, err := n.vacationDates(year)
+	if err != nil {
+		return digest, err
+	}
+	digest.DaysRemaining = digest.DaysTotal - len(vacations)
+
+	// Look for bridges from today (or the start of the month, if later)
+	from := dates.Today(n.location())
+	if monthStart.After(from) {
+		from = monthStart
+	}
+	bridge, ok, err := n.BestBridge(from, from.AddDate(0, 6, 0))
+	if err != nil {
+		return digest, err
+	}
+	if ok {
+		digest.Bridge = &bridge
+	}
+
+	return digest, nil
+}
+
+// RenderMonthlyDigest renders the digest as HTML and plain text
+func RenderMonthlyDigest(digest MonthlyDigest) (string, string, error) {
+	var html, text bytes.Buffer
+	if err := monthlyDigestHTML.Execute(&html, digest); err != nil {
+		return "", "", err
+	}
+	if err := monthlyDigestText.Execute(&text, digest); err != nil {
+		return "", "", err
+	}
+	return html.String(), text.String(), nil
+}
+
+// SendMonthlyDigest renders and sends the digest for the month containing date
+func (n *Notifier) SendMonthlyDigest(date time.Time) error {
+	channels := n.channels(KindMonthlyDigest)
+	if len(channels) == 0 {
+		return fmt.Errorf("no notification channels configured for the monthly digest")
+	}
+
+	digest, err := n.BuildMonthlyDigest(date)
+	if err != nil {
+		return err
+	}
+	html, text, err := RenderMonthlyDigest(digest)
+	if err != nil {
+		return err
+	}
+
+	msg := Message{
+		Title: "Your " + digest.MonthName + " vacation digest",
+		Text:  text,
+		HTML:  html,
+	}
+	return n.send(channels, msg)
+}
+
+// allowance returns the vacation days available in a year, including the
+// compensation days left
+func (n *Notifier) allowance(year int) int {
+	var days int
+	if err := n.db.QueryRow(`SELECT vacation_days FROM year_config WHERE year = ?`, year).Scan(&days); err == nil {
+		return days + n.compensationDays(year)
+	}
+	if days, err := strconv.Atoi(n.setting("default_vacation_days")); err == nil {
+		return days + n.compensationDays(year)
+	}
+	return 22 + n.compensationDays(year)
+}
+
+// compensationDays returns the days in lieu earned by working holidays and
+// other non-work days, less the ones taken as comp days off
+func (n *Notifier) compensationDays(year int) int {
+	var worked, credited, spent int
+	n.db.QueryRow(`SELECT COUNT(*) FROM worked_holidays WHERE year = ?`, year).Scan(&worked)
+	n.db.QueryRow(`SELECT COUNT(*) FROM comp_days WHERE year = ? AND kind = 'credit'`, year).Scan(&credited)
+	n.db.QueryRow(`SELECT COUNT(*) FROM comp_days WHERE year = ? AND kind = 'spend'`, year).Scan(&spent)
+	var adjusted int
+	n.db.QueryRow(`SELECT COALESCE(SUM(days), 0) FROM balance_adjustments WHERE year = ?`, year).Scan(&adjusted)
+	return worked + credited - spent + adjusted
+}
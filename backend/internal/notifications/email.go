@@ -0,0 +1,137 @@
+package notifications
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPConfig holds the SMTP server settings
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// EmailChannel sends notifications by email over SMTP
+type EmailChannel struct {
+	config SMTPConfig
+	to     []string
+}
+
+// NewEmailChannel creates an email channel for the given recipients
+func NewEmailChannel(config SMTPConfig, to []string) *EmailChannel {
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	if config.From == "" {
+		config.From = config.Username
+	}
+	return &EmailChannel{config: config, to: to}
+}
+
+// Name returns the channel name
+func (e *EmailChannel) Name() string {
+	return "email"
+}
+
+// Send emails the message, as HTML with a plain text alternative when the
+// message has an HTML version
+func (e *EmailChannel) Send(msg Message) error {
+	if msg.HTML != "" {
+		boundary := fmt.Sprintf("vacation-planner-%d", time.Now().UnixNano())
+		var body strings.Builder
+		body.WriteString("--" + boundary + "\r\n")
+		body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+		body.WriteString(msg.Text + "\r\n")
+		body.WriteString("--" + boundary + "\r\n")
+		body.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+		body.WriteString(msg.HTML + "\r\n")
+		body.WriteString("--" + boundary + "--\r\n")
+		return e.sendMail(msg.Title, "multipart/alternative; boundary=\""+boundary+"\"", body.String())
+	}
+
+	var body strings.Builder
+	body.WriteString(msg.Text)
+	body.WriteString("\r\n")
+	if len(msg.Facts) > 0 {
+		body.WriteString("\r\n")
+		for _, f := range msg.Facts {
+			body.WriteString(fmt.Sprintf("- %s: %s\r\n", f.Name, f.Value))
+		}
+	}
+
+	return e.sendMail(msg.Title, "text/plain", body.String())
+}
+
+// sendMail delivers a message with the given subject and content type
+func (e *EmailChannel) sendMail(subject, contentType, body string) error {
+	var msg strings.Builder
+	msg.WriteString("From: " + e.config.From + "\r\n")
+	msg.WriteString("To: " + strings.Join(e.to, ", ") + "\r\n")
+	msg.WriteString("Subject: " + subject + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	if strings.HasPrefix(contentType, "multipart/") {
+		msg.WriteString("Content-Type: " + contentType + "\r\n")
+	} else {
+		msg.WriteString("Content-Type: " + contentType + "; charset=UTF-8\r\n")
+	}
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+
+	addr := net.JoinHostPort(e.config.Host, e.config.Port)
+
+	var auth smtp.Auth
+	if e.config.Username != "" {
+		auth = smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)
+	}
+
+	// Port 465 uses implicit TLS, other ports upgrade with STARTTLS when offered
+	if e.config.Port != "465" {
+		return smtp.SendMail(addr, auth, e.config.From, e.to, []byte(msg.String()))
+	}
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: e.config.Host})
+	if err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, e.config.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(e.config.From); err != nil {
+		return err
+	}
+	for _, to := range e.to {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(msg.String())); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
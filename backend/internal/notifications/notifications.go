@@ -0,0 +1,622 @@
+// Package notifications sends calendar notifications (digests, optimization
+// results, reminders) to external channels such as Microsoft Teams.
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/events"
+	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// Notification kinds, used to toggle notifications and to log what was sent
+const (
+	KindWeeklyDigest      = "weekly_digest"
+	KindMonthlyDigest     = "monthly_digest"
+	KindOptimization      = "optimization"
+	KindVacationReminder  = "vacation_reminder"
+	KindCarryoverExpiring = "carryover_expiring"
+	KindUnusedDays        = "unused_days"
+	KindHolidaysRecovered = "holidays_recovered"
+	KindHolidaysFailed    = "holidays_failed"
+	KindVacationStart     = "vacation_start"
+	KindTest              = "test"
+)
+
+// Fact is a name/value pair shown in a notification
+type Fact struct {
+	Name  string
+	Value string
+}
+
+// Message is a channel-independent notification. HTML is an optional rich
+// version of Text for channels that support it.
+type Message struct {
+	Title string
+	Text  string
+	HTML  string
+	Facts []Fact
+}
+
+// Channel delivers messages to one destination
+type Channel interface {
+	Name() string
+	Send(msg Message) error
+}
+
+// Notifier builds notifications from calendar data and events and sends them
+// to the channels configured in settings
+type Notifier struct {
+	db                *sql.DB
+	store             *store.Store
+	holidays          HolidaySource
+	onSettingsChanged func()
+}
+
+// HolidaySource returns the holidays of a year as the calendar shows them,
+// with the overrides, substitutions, worked holidays and days off applied
+type HolidaySource func(ctx context.Context, year int) []holidays.PortugueseHoliday
+
+// NewNotifier creates a new notifier. It uses the calculated national
+// holidays until SetHolidaySource is called.
+func NewNotifier(db *sql.DB) *Notifier {
+	return &Notifier{
+		db:    db,
+		store: store.New(db),
+		holidays: func(_ context.Context, year int) []holidays.PortugueseHoliday {
+			return holidays.CalculatedHolidays(year)
+		},
+	}
+}
+
+// SetHolidaySource sets where the notifier gets the holidays of a year
+func (n *Notifier) SetHolidaySource(source HolidaySource) {
+	n.holidays = source
+}
+
+// holidaysFor returns the holidays of a year from the holiday source
+func (n *Notifier) holidaysFor(ctx context.Context, year int) []holidays.PortugueseHoliday {
+	return n.holidays(ctx, year)
+}
+
+// SetSettingsChangedHandler sets a function called after the notifier writes
+// settings itself (the generated VAPID keys)
+func (n *Notifier) SetSettingsChangedHandler(fn func()) {
+	n.onSettingsChanged = fn
+}
+
+// Handle reacts to calendar events. It is meant to be subscribed to the events bus.
+func (n *Notifier) Handle(event events.Event) {
+	switch event.Type {
+	case events.OptimizationCompleted:
+		n.handleOptimization(event)
+	case events.HolidaysRecovered:
+		channels := n.channels(KindHolidaysRecovered)
+		if len(channels) > 0 {
+			go n.send(channels, Message{
+				Title: fmt.Sprintf("Holidays for %d are up to date", event.Year),
+				Text:  fmt.Sprintf("Holiday data for %d was loaded successfully after earlier failed attempts. Your calendar now uses the official dates.", event.Year),
+			})
+		}
+	case events.HolidaysFailed:
+		channels := n.channels(KindHolidaysFailed)
+		if len(channels) > 0 {
+			go n.send(channels, Message{
+				Title: fmt.Sprintf("Holidays for %d failed to refresh", event.Year),
+				Text:  "Holiday data could not be loaded. The calendar uses calculated national holidays until a refresh succeeds.",
+			})
+		}
+	}
+}
+
+func (n *Notifier) handleOptimization(event events.Event) {
+	channels := n.channels(KindOptimization)
+	if len(channels) == 0 {
+		return
+	}
+
+	// Event data is a loose map, round trip it into the fields we need
+	var result struct {
+		Strategy string                 `json:"strategy"`
+		Blocks   []models.VacationBlock `json:"blocks"`
+	}
+	raw, _ := json.Marshal(event.Data)
+	json.Unmarshal(raw, &result)
+
+	go n.send(channels, optimizationMessage(event.Year, result.Strategy, result.Blocks))
+}
+
+// SendTest sends a test message to every configured channel
+func (n *Notifier) SendTest() error {
+	channels := n.channels(KindTest)
+	if len(channels) == 0 {
+		return fmt.Errorf("no notification channels configured")
+	}
+
+	return n.send(channels, Message{
+		Title: "Vacation Planner",
+		Text:  "Notifications are set up correctly.",
+	})
+}
+
+// SendWeeklyDigest sends the "your next days off" digest right away
+func (n *Notifier) SendWeeklyDigest(now time.Time) error {
+	channels := n.channels(KindWeeklyDigest)
+	if len(channels) == 0 {
+		return fmt.Errorf("no notification channels configured for the weekly digest")
+	}
+
+	periods, err := n.UpcomingTimeOff(now, now.AddDate(0, 0, 60))
+	if err != nil {
+		return err
+	}
+	return n.send(channels, weeklyDigestMessage(periods))
+}
+
+// RunScheduled sends the notifications that are due at the given time. It is
+// meant to run hourly; the log of sent notifications keeps each one from
+// going out twice.
+func (n *Notifier) RunScheduled(now time.Time) {
+	// Morning and the current day are the user's, not the server's
+	now = now.In(n.location())
+
+	// Weekly digest goes out on Monday morning
+	if now.Weekday() == time.Monday && now.Hour() >= 8 {
+		year, week := now.ISOWeek()
+		ref := fmt.Sprintf("%d-W%02d", year, week)
+		if !n.wasSent(KindWeeklyDigest, ref) {
+			if err := n.SendWeeklyDigest(now); err != nil {
+				log.Printf("Weekly digest not sent: %v", err)
+			} else {
+				n.markSent(KindWeeklyDigest, ref)
+			}
+		}
+	}
+
+	// Monthly digest goes out on the morning of the first day of the month
+	if now.Day() == 1 && now.Hour() >= 8 {
+		ref := now.Format("2006-01")
+		if !n.wasSent(KindMonthlyDigest, ref) && len(n.channels(KindMonthlyDigest)) > 0 {
+			if err := n.SendMonthlyDigest(now); err != nil {
+				log.Printf("Monthly digest not sent: %v", err)
+			} else {
+				n.markSent(KindMonthlyDigest, ref)
+			}
+		}
+	}
+
+	n.sendVacationReminders(now)
+	n.sendVacationStart(now)
+	n.sendCarryoverAlert(now)
+	n.sendUnusedDaysAlert(now)
+}
+
+// sendVacationStart notifies the day before a break with vacation days starts
+func (n *Notifier) sendVacationStart(now time.Time) {
+	channels := n.channels(KindVacationStart)
+	if len(channels) == 0 {
+		return
+	}
+
+	tomorrow := dates.Civil(now.AddDate(0, 0, 1))
+	periods, err := n.UpcomingTimeOff(tomorrow, tomorrow)
+	if err != nil {
+		log.Printf("Vacation start notification not sent: %v", err)
+		return
+	}
+	for _, period := range periods {
+		if period.VacationDays == 0 || !period.Start.Equal(tomorrow) {
+			continue
+		}
+
+		ref := period.Start.Format("2006-01-02")
+		if n.wasSent(KindVacationStart, ref) {
+			continue
+		}
+
+		msg := Message{
+			Title: "Your vacation starts tomorrow",
+			Text:  fmt.Sprintf("Enjoy your %s! Back at work on %s.", plural(period.Days, "day")+" off", period.End.AddDate(0, 0, 1).Format("Monday, 2 January")),
+		}
+		if n.send(channels, msg) == nil {
+			n.markSent(KindVacationStart, ref)
+		}
+	}
+}
+
+// sendVacationReminders reminds about vacation blocks starting within the
+// configured number of days
+func (n *Notifier) sendVacationReminders(now time.Time) {
+	daysBefore, err := strconv.Atoi(n.setting("reminder_days_before"))
+	if err != nil || daysBefore <= 0 {
+		return
+	}
+
+	channels := n.channels(KindVacationReminder)
+	if len(channels) == 0 {
+		return
+	}
+
+	tomorrow := now.AddDate(0, 0, 1)
+	periods, err := n.UpcomingTimeOff(tomorrow, now.AddDate(0, 0, daysBefore))
+	if err != nil {
+		log.Printf("Vacation reminders not sent: %v", err)
+		return
+	}
+	for _, period := range periods {
+		if period.VacationDays == 0 {
+			continue
+		}
+
+		ref := period.Start.Format("2006-01-02")
+		if n.wasSent(KindVacationReminder, ref) {
+			continue
+		}
+
+		msg := Message{
+			Title: "Your vacation starts " + period.Start.Format("Monday, 2 January"),
+			Text:  fmt.Sprintf("Reminder: %s.", period.Description()),
+			Facts: []Fact{
+				{Name: "From", Value: period.Start.Format("Mon 2 Jan 2006")},
+				{Name: "Back at work", Value: period.End.AddDate(0, 0, 1).Format("Mon 2 Jan 2006")},
+			},
+		}
+		if n.send(channels, msg) == nil {
+			n.markSent(KindVacationReminder, ref)
+		}
+	}
+}
+
+// sendCarryoverAlert warns once a year when vacation days left over from the
+// previous year are about to expire (by default on 30 April, as in Portuguese
+// labour law)
+func (n *Notifier) sendCarryoverAlert(now time.Time) {
+	channels := n.channels(KindCarryoverExpiring)
+	if len(channels) == 0 {
+		return
+	}
+
+	expiry, err := dates.Parse(fmt.Sprintf("%d-%s", now.Year(), n.carryoverExpiry()))
+	if err != nil {
+		return
+	}
+
+	daysLeft := int(expiry.Sub(dates.Civil(now)).Hours() / 24)
+	if daysLeft < 0 || daysLeft > 30 {
+		return
+	}
+
+	ref := strconv.Itoa(now.Year())
+	if n.wasSent(KindCarryoverExpiring, ref) {
+		return
+	}
+
+	previousYear := now.Year() - 1
+	var allowance int
+	if err := n.db.QueryRow(`SELECT vacation_days FROM year_config WHERE year = ?`, previousYear).Scan(&allowance); err != nil {
+		return
+	}
+
+	vacations, err := n.vacationDates(previousYear)
+	if err != nil {
+		log.Printf("Carryover alert not sent: %v", err)
+		return
+	}
+	remaining := allowance + n.compensationDays(previousYear) - len(vacations)
+	if remaining <= 0 {
+		return
+	}
+
+	msg := Message{
+		Title: fmt.Sprintf("%d vacation days from %d expire soon", remaining, previousYear),
+		Text:  fmt.Sprintf("You have %d unused vacation days carried over from %d. They expire on %s.", remaining, previousYear, expiry.Format("2 January 2006")),
+	}
+	if n.send(channels, msg) == nil {
+		n.markSent(KindCarryoverExpiring, ref)
+	}
+}
+
+// sendUnusedDaysAlert warns, at most once a month, when more vacation days
+// are left this year than can realistically be booked on the open work days
+// remaining, outside the blackout periods
+func (n *Notifier) sendUnusedDaysAlert(now time.Time) {
+	channels := n.channels(KindUnusedDays)
+	if len(channels) == 0 {
+		return
+	}
+
+	ref := now.Format("2006-01")
+	if n.wasSent(KindUnusedDays, ref) {
+		return
+	}
+
+	year := now.Year()
+	var allowance int
+	var blackoutJSON string
+	if err := n.db.QueryRow(`SELECT vacation_days, COALESCE(blackout_periods, '[]') FROM year_config WHERE year = ?`, year).Scan(&allowance, &blackoutJSON); err != nil {
+		return
+	}
+	config := models.YearConfig{}
+	json.Unmarshal([]byte(blackoutJSON), &config.BlackoutPeriods)
+
+	vacations, err := n.vacationDates(year)
+	if err != nil {
+		log.Printf("Unused days alert not sent: %v", err)
+		return
+	}
+	remaining := allowance + n.compensationDays(year) - len(vacations)
+	if remaining <= 0 {
+		return
+	}
+
+	share, err := strconv.Atoi(n.setting("unused_days_share"))
+	if err != nil || share <= 0 {
+		share = models.DefaultUnusedDaysShare
+	}
+	open, err := n.openWorkDays(dates.Civil(now), config)
+	if err != nil {
+		log.Printf("Unused days alert not sent: %v", err)
+		return
+	}
+	schedulable := models.SchedulableDays(open, share)
+	if remaining <= schedulable {
+		return
+	}
+
+	msg := Message{
+		Title: fmt.Sprintf("%s at risk of going unused", plural(remaining, "vacation day")),
+		Text: fmt.Sprintf("You have %s left in %d, but only about %d can realistically be booked before the end of the year. Plan them soon or check whether they can be carried over.",
+			plural(remaining, "vacation day"), year, schedulable),
+		Facts: []Fact{
+			{Name: "Days left", Value: strconv.Itoa(remaining)},
+			{Name: "Realistically bookable", Value: strconv.Itoa(schedulable)},
+		},
+	}
+	if n.send(channels, msg) == nil {
+		n.markSent(KindUnusedDays, ref)
+	}
+}
+
+// openWorkDays counts the work days from a date to the end of its year that
+// are not off, not comp days off and not in a blackout period
+func (n *Notifier) openWorkDays(from time.Time, config models.YearConfig) (int, error) {
+	year := from.Year()
+	view, err := n.loadCalendarView(year, year)
+	if err != nil {
+		return 0, err
+	}
+
+	compDaysOff := make(map[string]bool)
+	if rows, err := n.db.Query(`SELECT date FROM comp_days WHERE year = ? AND kind = 'spend'`, year); err == nil {
+		for rows.Next() {
+			var date string
+			if rows.Scan(&date) == nil {
+				compDaysOff[date] = true
+			}
+		}
+		rows.Close()
+	}
+
+	open := 0
+	for d := from; d.Year() == year; d = d.AddDate(0, 0, 1) {
+		dateStr := d.Format("2006-01-02")
+		if !view.isOff(d) && !compDaysOff[dateStr] && !config.InBlackout(dateStr) {
+			open++
+		}
+	}
+	return open, nil
+}
+
+// carryoverExpiry returns the MM-DD date when carried over days expire
+func (n *Notifier) carryoverExpiry() string {
+	if expiry := n.setting("carryover_expiry"); expiry != "" {
+		return expiry
+	}
+	return "04-30"
+}
+
+// channels returns the channels that should receive a kind of notification.
+// Once users have notification preferences, only the channels they want it
+// on outside their quiet hours get it.
+func (n *Notifier) channels(kind string) []Channel {
+	var channels []Channel
+
+	wanted := map[string]bool{ChannelTeams: true, ChannelEmail: true, ChannelPush: true}
+	recipients := splitList(n.setting("notification_email"))
+	if kind != KindTest {
+		if all := n.allPreferences(); len(all) > 0 {
+			wanted, recipients = audience(all, kind, n.Now(), recipients)
+		}
+	}
+
+	teamsURL := n.setting("teams_webhook_url")
+	if teamsURL != "" && wanted[ChannelTeams] {
+		enabled := false
+		switch kind {
+		case KindWeeklyDigest:
+			enabled = n.setting("teams_weekly_digest") != "false"
+		case KindOptimization:
+			enabled = n.setting("teams_optimization_results") != "false"
+		case KindTest:
+			enabled = true
+		}
+		if enabled {
+			channels = append(channels, NewTeamsChannel(teamsURL))
+		}
+	}
+
+	smtpHost := n.setting("smtp_host")
+	if smtpHost != "" && wanted[ChannelEmail] && len(recipients) > 0 {
+		enabled := false
+		switch kind {
+		case KindVacationReminder:
+			enabled = n.setting("email_vacation_reminders") != "false"
+		case KindCarryoverExpiring:
+			enabled = n.setting("email_carryover_alerts") != "false"
+		case KindUnusedDays:
+			enabled = n.setting("email_unused_days_alerts") != "false"
+		case KindHolidaysRecovered:
+			enabled = n.setting("email_holiday_recovery") != "false"
+		case KindMonthlyDigest:
+			enabled = n.setting("email_monthly_digest") != "false"
+		case KindTest:
+			enabled = true
+		}
+		if enabled {
+			channels = append(channels, NewEmailChannel(n.smtpConfig(), recipients))
+		}
+	}
+
+	if wanted[ChannelPush] && n.hasPushSubscriptions() {
+		enabled := false
+		switch kind {
+		case KindHolidaysFailed:
+			enabled = n.setting("push_holiday_failures") != "false"
+		case KindVacationStart:
+			enabled = n.setting("push_vacation_start") != "false"
+		case KindTest:
+			enabled = true
+		}
+		if enabled {
+			if publicKey, err := n.VAPIDPublicKey(); err == nil {
+				channels = append(channels, NewPushChannel(n.db, publicKey, n.setting("vapid_private_key"), n.pushSubject()))
+			}
+		}
+	}
+
+	return channels
+}
+
+// pushSubject returns the VAPID subject, a contact URL for push services
+func (n *Notifier) pushSubject() string {
+	if subject := n.setting("vapid_subject"); subject != "" {
+		return subject
+	}
+	if from := n.setting("smtp_from"); from != "" {
+		return "mailto:" + from
+	}
+	return "mailto:admin@localhost"
+}
+
+// smtpConfig reads the SMTP settings
+func (n *Notifier) smtpConfig() SMTPConfig {
+	return SMTPConfig{
+		Host:     n.setting("smtp_host"),
+		Port:     n.setting("smtp_port"),
+		Username: n.setting("smtp_username"),
+		Password: n.setting("smtp_password"),
+		From:     n.setting("smtp_from"),
+	}
+}
+
+// splitList splits a comma or newline separated setting
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '\n' || r == ';'
+	}) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// send delivers a message to all channels, returning the last error
+func (n *Notifier) send(channels []Channel, msg Message) error {
+	var lastErr error
+	for _, ch := range channels {
+		if err := ch.Send(msg); err != nil {
+			log.Printf("Error sending %s notification: %v", ch.Name(), err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// Now returns the current time in the user's time zone
+func (n *Notifier) Now() time.Time {
+	return time.Now().In(n.location())
+}
+
+// location returns the user's time zone from the timezone setting
+func (n *Notifier) location() *time.Location {
+	return dates.Location(n.setting("timezone"))
+}
+
+func (n *Notifier) setting(key string) string {
+	var value string
+	n.db.QueryRow(`SELECT value FROM settings WHERE key = ?`, key).Scan(&value)
+	return value
+}
+
+// wasSent checks the notification log so scheduled notifications go out once
+func (n *Notifier) wasSent(kind, ref string) bool {
+	var count int
+	n.db.QueryRow(`SELECT COUNT(*) FROM notification_log WHERE kind = ? AND ref = ?`, kind, ref).Scan(&count)
+	return count > 0
+}
+
+func (n *Notifier) markSent(kind, ref string) {
+	n.db.Exec(`INSERT OR IGNORE INTO notification_log (kind, ref) VALUES (?, ?)`, kind, ref)
+}
+
+func optimizationMessage(year int, strategy string, blocks []models.VacationBlock) Message {
+	msg := Message{
+		Title: fmt.Sprintf("Vacation plan optimized for %d", year),
+		Text:  fmt.Sprintf("The %s strategy found %d vacation blocks.", strategy, len(blocks)),
+	}
+
+	for _, block := range blocks {
+		msg.Facts = append(msg.Facts, Fact{
+			Name:  formatRange(block.StartDate, block.EndDate),
+			Value: fmt.Sprintf("%d days off for %d vacation days", block.TotalDays, block.VacationDaysUsed),
+		})
+	}
+
+	return msg
+}
+
+func weeklyDigestMessage(periods []TimeOff) Message {
+	msg := Message{
+		Title: "Your next days off",
+	}
+
+	if len(periods) == 0 {
+		msg.Text = "No holidays or vacation days in the next 60 days."
+		return msg
+	}
+
+	msg.Text = fmt.Sprintf("You have %d breaks coming up in the next 60 days.", len(periods))
+	for _, p := range periods {
+		msg.Facts = append(msg.Facts, Fact{
+			Name:  formatRange(p.Start.Format("2006-01-02"), p.End.Format("2006-01-02")),
+			Value: p.Description(),
+		})
+	}
+
+	return msg
+}
+
+// formatRange renders a date range like "Mon 6 Apr – Fri 10 Apr"
+func formatRange(start, end string) string {
+	s, err1 := dates.Parse(start)
+	e, err2 := dates.Parse(end)
+	if err1 != nil || err2 != nil {
+		return start + " – " + end
+	}
+	if start == end {
+		return s.Format("Mon 2 Jan")
+	}
+	return s.Format("Mon 2 Jan") + " – " + e.Format("Mon 2 Jan")
+}
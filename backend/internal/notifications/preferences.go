@@ -0,0 +1,199 @@
+package notifications
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+)
+
+// Channel names, as in the channels of Preferences
+const (
+	ChannelTeams = "teams"
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+)
+
+// Kinds are the notification kinds a user can choose to receive
+var Kinds = []string{
+	KindWeeklyDigest,
+	KindMonthlyDigest,
+	KindOptimization,
+	KindVacationReminder,
+	KindVacationStart,
+	KindCarryoverExpiring,
+	KindUnusedDays,
+	KindHolidaysRecovered,
+	KindHolidaysFailed,
+}
+
+// Preferences are a user's notification settings. The user is an access
+// token, or token 0 for the owner while the API is open. The global
+// notification settings still decide what each channel may send.
+type Preferences struct {
+	TokenID    int64    `json:"token_id"`
+	Channels   []string `json:"channels"`              // Channels to be notified on, empty for all
+	Email      string   `json:"email,omitempty"`       // Address to email, instead of notification_email
+	Kinds      []string `json:"kinds"`                 // Kinds to receive, empty for all
+	QuietStart string   `json:"quiet_start,omitempty"` // HH:MM when quiet hours start, in the timezone setting
+	QuietEnd   string   `json:"quiet_end,omitempty"`   // HH:MM when they end, may be past midnight
+	UpdatedAt  string   `json:"updated_at,omitempty"`
+}
+
+// Validate returns an error unless the channels and kinds are known, the
+// email is an address and the quiet hours are both set or both empty
+func (p Preferences) Validate() error {
+	for _, channel := range p.Channels {
+		if channel != ChannelTeams && channel != ChannelEmail && channel != ChannelPush {
+			return fmt.Errorf("unknown channel %q, must be %s, %s or %s", channel, ChannelTeams, ChannelEmail, ChannelPush)
+		}
+	}
+	for _, kind := range p.Kinds {
+		if !contains(Kinds, kind) {
+			return fmt.Errorf("unknown notification kind %q", kind)
+		}
+	}
+	if p.Email != "" {
+		if _, err := mail.ParseAddress(p.Email); err != nil {
+			return fmt.Errorf("invalid email %q", p.Email)
+		}
+	}
+	if (p.QuietStart == "") != (p.QuietEnd == "") {
+		return errors.New("quiet_start and quiet_end must be set together")
+	}
+	for _, value := range []string{p.QuietStart, p.QuietEnd} {
+		if _, ok := minuteOfDay(value); value != "" && !ok {
+			return fmt.Errorf("invalid quiet hours time %q, expected HH:MM", value)
+		}
+	}
+	return nil
+}
+
+// wants reports whether the user takes a kind of notification on a channel
+func (p Preferences) wants(kind, channel string) bool {
+	return (len(p.Kinds) == 0 || contains(p.Kinds, kind)) &&
+		(len(p.Channels) == 0 || contains(p.Channels, channel))
+}
+
+// quiet reports whether a time falls in the user's quiet hours
+func (p Preferences) quiet(now time.Time) bool {
+	start, ok1 := minuteOfDay(p.QuietStart)
+	end, ok2 := minuteOfDay(p.QuietEnd)
+	if !ok1 || !ok2 || start == end {
+		return false
+	}
+	minute := now.Hour()*60 + now.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// minuteOfDay parses an HH:MM time into minutes after midnight
+func minuteOfDay(value string) (int, bool) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// Preferences returns the notification settings of a user, the defaults
+// (everything, no quiet hours) when they have none
+func (n *Notifier) Preferences(tokenID int64) (Preferences, error) {
+	prefs := Preferences{TokenID: tokenID, Channels: []string{}, Kinds: []string{}}
+	var channelsJSON, kindsJSON string
+	err := n.db.QueryRow(`SELECT channels, email, kinds, quiet_start, quiet_end, updated_at FROM notification_preferences WHERE token_id = ?`, tokenID).
+		Scan(&channelsJSON, &prefs.Email, &kindsJSON, &prefs.QuietStart, &prefs.QuietEnd, &prefs.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return prefs, nil
+	}
+	if err != nil {
+		return prefs, err
+	}
+	json.Unmarshal([]byte(channelsJSON), &prefs.Channels)
+	json.Unmarshal([]byte(kindsJSON), &prefs.Kinds)
+	return prefs, nil
+}
+
+// SetPreferences saves the notification settings of a user
+func (n *Notifier) SetPreferences(prefs Preferences) error {
+	if prefs.Channels == nil {
+		prefs.Channels = []string{}
+	}
+	if prefs.Kinds == nil {
+		prefs.Kinds = []string{}
+	}
+	channelsJSON, _ := json.Marshal(prefs.Channels)
+	kindsJSON, _ := json.Marshal(prefs.Kinds)
+	_, err := n.db.Exec(`INSERT OR REPLACE INTO notification_preferences (token_id, channels, email, kinds, quiet_start, quiet_end, updated_at) VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		prefs.TokenID, string(channelsJSON), prefs.Email, string(kindsJSON), prefs.QuietStart, prefs.QuietEnd)
+	return err
+}
+
+// allPreferences returns the notification settings of the users that still
+// exist: the owner and the access tokens not deleted
+func (n *Notifier) allPreferences() []Preferences {
+	rows, err := n.db.Query(`SELECT token_id, channels, email, kinds, quiet_start, quiet_end FROM notification_preferences
+		WHERE token_id = 0 OR token_id IN (SELECT id FROM access_tokens)`)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var all []Preferences
+	for rows.Next() {
+		var prefs Preferences
+		var channelsJSON, kindsJSON string
+		if err := rows.Scan(&prefs.TokenID, &channelsJSON, &prefs.Email, &kindsJSON, &prefs.QuietStart, &prefs.QuietEnd); err != nil {
+			continue
+		}
+		json.Unmarshal([]byte(channelsJSON), &prefs.Channels)
+		json.Unmarshal([]byte(kindsJSON), &prefs.Kinds)
+		all = append(all, prefs)
+	}
+	return all
+}
+
+// audience works out from the users' preferences which channels a kind of
+// notification goes to at a time, and the email recipients. Users in their
+// quiet hours are left out; emails go to notification_email for users
+// without an address of their own.
+func audience(all []Preferences, kind string, now time.Time, defaultRecipients []string) (map[string]bool, []string) {
+	wanted := make(map[string]bool)
+	var recipients []string
+	for _, prefs := range all {
+		if prefs.quiet(now) {
+			continue
+		}
+		for _, channel := range []string{ChannelTeams, ChannelEmail, ChannelPush} {
+			if prefs.wants(kind, channel) {
+				wanted[channel] = true
+			}
+		}
+		if !prefs.wants(kind, ChannelEmail) {
+			continue
+		}
+		addresses := defaultRecipients
+		if prefs.Email != "" {
+			addresses = []string{prefs.Email}
+		}
+		for _, address := range addresses {
+			if !contains(recipients, address) {
+				recipients = append(recipients, address)
+			}
+		}
+	}
+	return wanted, recipients
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package notifications
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAudience(t *testing.T) {
+	all := []Preferences{
+		{TokenID: 1, Channels: []string{ChannelEmail}, Email: "night@example.com", QuietStart: "22:00", QuietEnd: "07:00"},
+		{TokenID: 2, Channels: []string{ChannelPush}, Kinds: []string{KindVacationStart}},
+		{TokenID: 3, Channels: []string{ChannelEmail}, Kinds: []string{KindMonthlyDigest}},
+	}
+	defaults := []string{"team@example.com"}
+
+	tests := []struct {
+		name       string
+		kind       string
+		at         string
+		channels   []string
+		recipients []string
+	}{
+		{"reminder by day", KindVacationReminder, "10:00", []string{ChannelEmail}, []string{"night@example.com"}},
+		{"reminder in quiet hours past midnight", KindVacationReminder, "03:00", nil, nil},
+		{"vacation start at night", KindVacationStart, "23:00", []string{ChannelPush}, nil},
+		{"monthly digest", KindMonthlyDigest, "09:00", []string{ChannelEmail}, []string{"night@example.com", "team@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now, _ := time.Parse("15:04", tt.at)
+			wanted, recipients := audience(all, tt.kind, now, defaults)
+
+			for _, channel := range []string{ChannelTeams, ChannelEmail, ChannelPush} {
+				if wanted[channel] != contains(tt.channels, channel) {
+					t.Errorf("%s wanted = %v", channel, wanted[channel])
+				}
+			}
+			if len(recipients) != len(tt.recipients) {
+				t.Fatalf("recipients = %v, want %v", recipients, tt.recipients)
+			}
+			for i := range recipients {
+				if recipients[i] != tt.recipients[i] {
+					t.Errorf("recipients = %v, want %v", recipients, tt.recipients)
+				}
+			}
+		})
+	}
+}
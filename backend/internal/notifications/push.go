@@ -0,0 +1,137 @@
+package notifications
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+)
+
+// PushSubscription is a browser push subscription (PushSubscription.toJSON())
+type PushSubscription struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+	Keys     struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys" binding:"required"`
+}
+
+// PushChannel sends Web Push notifications to all stored subscriptions
+type PushChannel struct {
+	db         *sql.DB
+	publicKey  string
+	privateKey string
+	subject    string
+}
+
+// NewPushChannel creates a Web Push channel signed with the given VAPID keys
+func NewPushChannel(db *sql.DB, publicKey, privateKey, subject string) *PushChannel {
+	return &PushChannel{
+		db:         db,
+		publicKey:  publicKey,
+		privateKey: privateKey,
+		subject:    subject,
+	}
+}
+
+// Name returns the channel name
+func (p *PushChannel) Name() string {
+	return "push"
+}
+
+// Send pushes the message to every subscription. Subscriptions the push
+// service reports as gone are removed.
+func (p *PushChannel) Send(msg Message) error {
+	payload, err := json.Marshal(map[string]string{
+		"title": msg.Title,
+		"body":  msg.Text,
+	})
+	if err != nil {
+		return err
+	}
+
+	rows, err := p.db.Query(`SELECT endpoint, p256dh, auth FROM push_subscriptions`)
+	if err != nil {
+		return err
+	}
+
+	var subscriptions []webpush.Subscription
+	for rows.Next() {
+		var s webpush.Subscription
+		if err := rows.Scan(&s.Endpoint, &s.Keys.P256dh, &s.Keys.Auth); err != nil {
+			continue
+		}
+		subscriptions = append(subscriptions, s)
+	}
+	rows.Close()
+
+	var lastErr error
+	for i := range subscriptions {
+		s := &subscriptions[i]
+		resp, err := webpush.SendNotification(payload, s, &webpush.Options{
+			Subscriber:      p.subject,
+			VAPIDPublicKey:  p.publicKey,
+			VAPIDPrivateKey: p.privateKey,
+			TTL:             24 * 60 * 60,
+		})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+			log.Printf("Removing expired push subscription %s", s.Endpoint)
+			p.db.Exec(`DELETE FROM push_subscriptions WHERE endpoint = ?`, s.Endpoint)
+		case resp.StatusCode >= 300:
+			lastErr = fmt.Errorf("push service returned status %d", resp.StatusCode)
+		}
+	}
+
+	return lastErr
+}
+
+// VAPIDPublicKey returns the public key browsers need to subscribe, generating
+// and storing a key pair on first use
+func (n *Notifier) VAPIDPublicKey() (string, error) {
+	publicKey := n.setting("vapid_public_key")
+	if publicKey != "" && n.setting("vapid_private_key") != "" {
+		return publicKey, nil
+	}
+
+	privateKey, publicKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		return "", err
+	}
+
+	n.db.Exec(`INSERT OR REPLACE INTO settings (key, value, updated_at) VALUES ('vapid_private_key', ?, CURRENT_TIMESTAMP)`, privateKey)
+	n.db.Exec(`INSERT OR REPLACE INTO settings (key, value, updated_at) VALUES ('vapid_public_key', ?, CURRENT_TIMESTAMP)`, publicKey)
+	if n.onSettingsChanged != nil {
+		n.onSettingsChanged()
+	}
+	return publicKey, nil
+}
+
+// Subscribe stores a push subscription
+func (n *Notifier) Subscribe(sub PushSubscription) error {
+	_, err := n.db.Exec(`INSERT OR REPLACE INTO push_subscriptions (endpoint, p256dh, auth) VALUES (?, ?, ?)`,
+		sub.Endpoint, sub.Keys.P256dh, sub.Keys.Auth)
+	return err
+}
+
+// Unsubscribe removes a push subscription
+func (n *Notifier) Unsubscribe(endpoint string) error {
+	_, err := n.db.Exec(`DELETE FROM push_subscriptions WHERE endpoint = ?`, endpoint)
+	return err
+}
+
+// hasPushSubscriptions reports whether any browser is subscribed
+func (n *Notifier) hasPushSubscriptions() bool {
+	var count int
+	n.db.QueryRow(`SELECT COUNT(*) FROM push_subscriptions`).Scan(&count)
+	return count > 0
+}
@@ -0,0 +1,64 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// TeamsChannel posts message cards to a Microsoft Teams incoming webhook
+type TeamsChannel struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewTeamsChannel creates a Teams channel for an incoming webhook URL
+func NewTeamsChannel(webhookURL string) *TeamsChannel {
+	return &TeamsChannel{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns the channel name
+func (t *TeamsChannel) Name() string {
+	return "teams"
+}
+
+// Send posts the message as a MessageCard
+func (t *TeamsChannel) Send(msg Message) error {
+	facts := []map[string]string{}
+	for _, f := range msg.Facts {
+		facts = append(facts, map[string]string{"name": f.Name, "value": f.Value})
+	}
+
+	card := map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "http://schema.org/extensions",
+		"summary":    msg.Title,
+		"themeColor": "0EA5E9",
+		"title":      msg.Title,
+		"text":       msg.Text,
+	}
+	if len(facts) > 0 {
+		card["sections"] = []map[string]interface{}{{"facts": facts}}
+	}
+
+	body, err := json.Marshal(card)
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Post(t.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("teams webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/dates"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+// TimeOff is a run of consecutive days off that includes at least one
+// vacation day or holiday
+type TimeOff struct {
+	Start        time.Time
+	End          time.Time
+	Days         int
+	VacationDays int
+	Holidays     []string
+}
+
+// Description summarizes the period, e.g. "5 days off (3 vacation days, Carnaval)"
+func (t TimeOff) Description() string {
+	var parts []string
+	if t.VacationDays > 0 {
+		parts = append(parts, plural(t.VacationDays, "vacation day"))
+	}
+	parts = append(parts, t.Holidays...)
+	return fmt.Sprintf("%s off (%s)", plural(t.Days, "day"), strings.Join(parts, ", "))
+}
+
+// plural formats a count with a singular or plural noun
+func plural(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// UpcomingTimeOff returns the periods off that start between from and until.
+// A period that is still running at until is followed to its end.
+func (n *Notifier) UpcomingTimeOff(from, until time.Time) ([]TimeOff, error) {
+	from = dates.Civil(from)
+	until = dates.Civil(until)
+	view, err := n.loadCalendarView(from.Year(), until.Year()+1)
+	if err != nil {
+		return nil, err
+	}
+
+	var periods []TimeOff
+	var current *TimeOff
+
+	for d := from; ; d = d.AddDate(0, 0, 1) {
+		if d.After(until) && current == nil {
+			break
+		}
+
+		dateStr := d.Format("2006-01-02")
+		holidayName, isHoliday := view.holidays[dateStr]
+		isVacation := view.vacations[dateStr]
+
+		if !isHoliday && !isVacation && view.isWorkDay(d) {
+			if current != nil {
+				if current.VacationDays > 0 || len(current.Holidays) > 0 {
+					periods = append(periods, *current)
+				}
+				current = nil
+			}
+			continue
+		}
+
+		if current == nil {
+			if d.After(until) {
+				break
+			}
+			current = &TimeOff{Start: d}
+		}
+		current.End = d
+		current.Days++
+		if isHoliday {
+			current.Holidays = append(current.Holidays, holidayName)
+		} else if isVacation {
+			current.VacationDays++
+		}
+	}
+
+	return periods, nil
+}
+
+// calendarView holds the vacation days, holidays and work weeks of a range of years
+type calendarView struct {
+	vacations map[string]bool
+	holidays  map[string]string
+	schedules map[int]models.YearConfig
+}
+
+// loadCalendarView loads the calendar data for the years fromYear to toYear.
+// Holidays come from the holiday source, so they match the calendar's.
+func (n *Notifier) loadCalendarView(fromYear, toYear int) (calendarView, error) {
+	view := calendarView{
+		vacations: make(map[string]bool),
+		holidays:  make(map[string]string),
+		schedules: make(map[int]models.YearConfig),
+	}
+
+	ctx := context.Background()
+	for year := fromYear; year <= toYear; year++ {
+		vacations, err := n.vacationDates(year)
+		if err != nil {
+			return view, err
+		}
+		for _, date := range vacations {
+			view.vacations[date] = true
+		}
+
+		schedule, err := n.workSchedule(year)
+		if err != nil {
+			return view, err
+		}
+		view.schedules[year] = schedule
+
+		for _, hol := range n.holidaysFor(ctx, year) {
+			view.holidays[hol.Date] = hol.Name
+		}
+	}
+
+	return view, nil
+}
+
+// isWorkDay reports whether a date is in the work week in effect on that
+// date (ignoring holidays and vacations)
+func (v calendarView) isWorkDay(d time.Time) bool {
+	schedule, ok := v.schedules[d.Year()]
+	if !ok {
+		return d.Weekday() != time.Saturday && d.Weekday() != time.Sunday
+	}
+	return schedule.IsWorkDay(d)
+}
+
+// isOff reports whether a date is a day off (weekend, holiday or vacation)
+func (v calendarView) isOff(d time.Time) bool {
+	dateStr := d.Format("2006-01-02")
+	_, isHoliday := v.holidays[dateStr]
+	return isHoliday || v.vacations[dateStr] || !v.isWorkDay(d)
+}
+
+// vacationDates returns the manual and optimized vacation days of a year
+func (n *Notifier) vacationDates(year int) ([]string, error) {
+	ctx := context.Background()
+	manual, err := n.store.Vacations.List(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+	optimal, err := n.store.Vacations.ListOptimal(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var result []string
+	for _, v := range manual {
+		seen[v.Date] = true
+		result = append(result, v.Date)
+	}
+	for _, v := range optimal {
+		if !seen[v.Date] {
+			result = append(result, v.Date)
+		}
+	}
+	return result, nil
+}
+
+// workSchedule returns the configured work week of a year and its dated
+// changes, Monday to Friday by default
+func (n *Notifier) workSchedule(year int) (models.YearConfig, error) {
+	ctx := context.Background()
+	schedule, err := n.store.Configs.Get(ctx, year)
+	if errors.Is(err, store.ErrNotFound) {
+		schedule = models.YearConfig{Year: year}
+		schedule.WorkWeekChanges, err = n.store.Configs.WorkWeekChanges(ctx, year)
+	}
+	if err != nil {
+		return models.YearConfig{}, err
+	}
+	if len(schedule.WorkWeek) == 0 {
+		schedule.WorkWeek = []string{"monday", "tuesday", "wednesday", "thursday", "friday"}
+	}
+	return schedule, nil
+}
@@ -0,0 +1,66 @@
+package oidc
+
+import (
+	"sync"
+	"time"
+)
+
+// LoginTTL is how long a user has to sign in at the provider
+const LoginTTL = 10 * time.Minute
+
+type pendingLogin struct {
+	login   Login
+	expires time.Time
+}
+
+// Logins holds the sign-ins in progress in memory, by state
+type Logins struct {
+	pending map[string]pendingLogin
+	mux     sync.Mutex
+	now     func() time.Time
+}
+
+// NewLogins creates an empty set of sign-ins
+func NewLogins() *Logins {
+	return &Logins{
+		pending: make(map[string]pendingLogin),
+		now:     time.Now,
+	}
+}
+
+// Add remembers a sign-in until the user comes back or LoginTTL passes
+func (l *Logins) Add(login Login) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.pending[login.State] = pendingLogin{login: login, expires: l.now().Add(LoginTTL)}
+}
+
+// Take returns and forgets the sign-in with a state, so each can only be
+// completed once
+func (l *Logins) Take(state string) (Login, bool) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	p, ok := l.pending[state]
+	delete(l.pending, state)
+	if !ok || l.now().After(p.expires) {
+		return Login{}, false
+	}
+	return p.login, true
+}
+
+// Prune drops the abandoned sign-ins and returns how many
+func (l *Logins) Prune() int {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	n := 0
+	now := l.now()
+	for state, p := range l.pending {
+		if now.After(p.expires) {
+			delete(l.pending, state)
+			n++
+		}
+	}
+	return n
+}
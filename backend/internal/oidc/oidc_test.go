@@ -0,0 +1,44 @@
+package oidc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2030, 6, 10, 9, 0, 0, 0, time.UTC)
+	p := &Provider{config: Config{Issuer: "https://idp.example.com", ClientID: "planner"}}
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss":   "https://idp.example.com",
+			"aud":   "planner",
+			"exp":   float64(now.Add(time.Minute).Unix()),
+			"nonce": "n-1",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		change  func(map[string]interface{})
+		wantErr bool
+	}{
+		{"valid", func(map[string]interface{}) {}, false},
+		{"audience list", func(c map[string]interface{}) { c["aud"] = []interface{}{"planner", "api"}; c["azp"] = "planner" }, false},
+		{"other issuer", func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" }, true},
+		{"other audience", func(c map[string]interface{}) { c["aud"] = "other-app" }, true},
+		{"issued to another party", func(c map[string]interface{}) { c["aud"] = []interface{}{"planner", "api"}; c["azp"] = "api" }, true},
+		{"expired", func(c map[string]interface{}) { c["exp"] = float64(now.Add(-time.Minute).Unix()) }, true},
+		{"no expiry", func(c map[string]interface{}) { delete(c, "exp") }, true},
+		{"other sign-in", func(c map[string]interface{}) { c["nonce"] = "n-2" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			tt.change(claims)
+			if err := p.verify(claims, "n-1", now); (err != nil) != tt.wantErr {
+				t.Errorf("verify = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/ics"
+)
+
+// feed reads on-call shifts from an iCalendar feed, such as the personal
+// on-call calendar PagerDuty, Opsgenie and most schedulers publish. Every
+// event in it is a shift.
+type feed struct {
+	client *http.Client
+	url    string
+}
+
+// Shifts returns the events of the feed overlapping from..to
+func (f *feed) Shifts(ctx context.Context, from, to time.Time) ([]Shift, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the on-call calendar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("on-call calendar returned status %d", resp.StatusCode)
+	}
+
+	events, err := ics.ReadEvents(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the on-call calendar: %w", err)
+	}
+
+	var shifts []Shift
+	for _, event := range events {
+		if event.End.Before(from) || event.Start.After(to) {
+			continue
+		}
+		shifts = append(shifts, Shift{StartDate: event.Start.Format("2006-01-02"), EndDate: event.End.Format("2006-01-02")})
+	}
+	return shifts, nil
+}
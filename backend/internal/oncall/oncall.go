@@ -0,0 +1,122 @@
+// Package oncall reads on-call shifts from PagerDuty, Opsgenie or a calendar
+// feed, so the weeks on call can be closed to vacation.
+package oncall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/sandbox"
+)
+
+// On-call providers
+const (
+	ProviderNone      = "none"
+	ProviderPagerDuty = "pagerduty"
+	ProviderOpsgenie  = "opsgenie"
+	ProviderICS       = "ics"
+)
+
+// Shift is a stretch on call, over the days from StartDate to EndDate
+// (YYYY-MM-DD, inclusive) it touches
+type Shift struct {
+	StartDate string
+	EndDate   string
+}
+
+// Reader reads the on-call shifts of the configured user
+type Reader interface {
+	Shifts(ctx context.Context, from, to time.Time) ([]Shift, error)
+}
+
+// Config selects and authenticates a provider
+type Config struct {
+	Provider string
+	APIKey   string         // PagerDuty or Opsgenie API key
+	User     string         // PagerDuty user ID or Opsgenie username (email)
+	Schedule string         // Opsgenie schedule ID
+	URL      string         // On-call calendar feed of the ics provider
+	Location *time.Location // Time zone shifts are turned into days in
+}
+
+// ProviderName returns the display name of a provider
+func ProviderName(provider string) string {
+	switch provider {
+	case ProviderPagerDuty:
+		return "PagerDuty"
+	case ProviderOpsgenie:
+		return "Opsgenie"
+	case ProviderICS:
+		return "calendar feed"
+	}
+	return provider
+}
+
+// New returns the configured reader. In sandbox mode every provider is
+// replaced by canned shifts.
+func New(cfg Config) (Reader, error) {
+	if cfg.Provider == "" || cfg.Provider == ProviderNone {
+		return nil, errors.New("on-call import is not configured")
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+
+	if sandbox.Enabled() {
+		return sandboxReader{}, nil
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	switch cfg.Provider {
+	case ProviderPagerDuty:
+		if cfg.APIKey == "" || cfg.User == "" {
+			return nil, errors.New("PagerDuty needs an API key and your user ID")
+		}
+		return &pagerDuty{client: client, apiKey: cfg.APIKey, userID: cfg.User, loc: cfg.Location}, nil
+	case ProviderOpsgenie:
+		if cfg.APIKey == "" || cfg.User == "" || cfg.Schedule == "" {
+			return nil, errors.New("Opsgenie needs an API key, your username and the schedule ID")
+		}
+		return &opsgenie{client: client, apiKey: cfg.APIKey, username: cfg.User, scheduleID: cfg.Schedule, loc: cfg.Location}, nil
+	case ProviderICS:
+		if cfg.URL == "" {
+			return nil, errors.New("oncall_ics_url is not set")
+		}
+		return &feed{client: client, url: cfg.URL}, nil
+	}
+	return nil, fmt.Errorf("unknown on-call provider %q", cfg.Provider)
+}
+
+// shiftDays returns the shift from start up to end, an exclusive instant, as
+// the days it touches in loc
+func shiftDays(start, end time.Time, loc *time.Location) Shift {
+	last := start
+	if end.After(start) {
+		last = end.Add(-time.Nanosecond)
+	}
+	return Shift{
+		StartDate: start.In(loc).Format("2006-01-02"),
+		EndDate:   last.In(loc).Format("2006-01-02"),
+	}
+}
+
+// sandboxReader puts the user on call for the week from the first Monday of
+// March and of October of each year in range
+type sandboxReader struct{}
+
+func (sandboxReader) Shifts(ctx context.Context, from, to time.Time) ([]Shift, error) {
+	var shifts []Shift
+	for year := from.Year(); year <= to.Year(); year++ {
+		for _, month := range []time.Month{time.March, time.October} {
+			start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+			for start.Weekday() != time.Monday {
+				start = start.AddDate(0, 0, 1)
+			}
+			shifts = append(shifts, Shift{StartDate: start.Format("2006-01-02"), EndDate: start.AddDate(0, 0, 6).Format("2006-01-02")})
+		}
+	}
+	return shifts, nil
+}
@@ -0,0 +1,86 @@
+package oncall
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const opsgenieURL = "https://api.opsgenie.com/v2"
+
+// opsgenie reads the user's periods in a schedule's final timeline, with
+// overrides applied, with the Opsgenie API
+type opsgenie struct {
+	client     *http.Client
+	apiKey     string
+	username   string
+	scheduleID string
+	loc        *time.Location
+}
+
+type opsgenieTimeline struct {
+	Data struct {
+		FinalTimeline struct {
+			Rotations []struct {
+				Periods []struct {
+					StartDate time.Time `json:"startDate"`
+					EndDate   time.Time `json:"endDate"`
+					Recipient struct {
+						Type string `json:"type"`
+						Name string `json:"name"` // Username of user recipients
+					} `json:"recipient"`
+				} `json:"periods"`
+			} `json:"rotations"`
+		} `json:"finalTimeline"`
+	} `json:"data"`
+}
+
+// Shifts returns the user's periods in the schedule overlapping from..to
+func (o *opsgenie) Shifts(ctx context.Context, from, to time.Time) ([]Shift, error) {
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+	params := url.Values{
+		"identifierType": {"id"},
+		"interval":       {strconv.Itoa(months)},
+		"intervalUnit":   {"months"},
+		"date":           {time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, o.loc).Format(time.RFC3339)},
+	}
+	endpoint := fmt.Sprintf("%s/schedules/%s/timeline?%s", opsgenieURL, url.PathEscape(o.scheduleID), params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "GenieKey "+o.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the schedule from Opsgenie: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Opsgenie API returned status %d", resp.StatusCode)
+	}
+
+	var timeline opsgenieTimeline
+	if err := json.NewDecoder(resp.Body).Decode(&timeline); err != nil {
+		return nil, fmt.Errorf("failed to parse the Opsgenie schedule: %w", err)
+	}
+
+	var shifts []Shift
+	for _, rotation := range timeline.Data.FinalTimeline.Rotations {
+		for _, period := range rotation.Periods {
+			if period.Recipient.Type != "user" || !strings.EqualFold(period.Recipient.Name, o.username) {
+				continue
+			}
+			shifts = append(shifts, shiftDays(period.StartDate, period.EndDate, o.loc))
+		}
+	}
+	return shifts, nil
+}
@@ -0,0 +1,102 @@
+package oncall
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const pagerDutyURL = "https://api.pagerduty.com"
+
+const (
+	// pagerDutyPageSize is how many on-call entries are read per request
+	pagerDutyPageSize = 100
+	// pagerDutyWindow is the longest range the on-calls API accepts
+	pagerDutyWindow = 90 * 24 * time.Hour
+)
+
+// pagerDuty reads the user's on-call entries with the PagerDuty REST API
+type pagerDuty struct {
+	client *http.Client
+	apiKey string
+	userID string
+	loc    *time.Location
+}
+
+type pagerDutyOnCalls struct {
+	OnCalls []struct {
+		// Both null when the user is always on call for an escalation
+		// policy, with no schedule
+		Start *time.Time `json:"start"`
+		End   *time.Time `json:"end"`
+	} `json:"oncalls"`
+	More bool `json:"more"`
+}
+
+// Shifts returns the scheduled on-call entries overlapping from..to, on any
+// escalation level
+func (p *pagerDuty) Shifts(ctx context.Context, from, to time.Time) ([]Shift, error) {
+	var shifts []Shift
+	until := to.AddDate(0, 0, 1)
+	for since := from; since.Before(until); since = since.Add(pagerDutyWindow) {
+		windowEnd := since.Add(pagerDutyWindow)
+		if windowEnd.After(until) {
+			windowEnd = until
+		}
+
+		for offset := 0; ; offset += pagerDutyPageSize {
+			params := url.Values{
+				"user_ids[]": {p.userID},
+				"since":      {since.Format(time.RFC3339)},
+				"until":      {windowEnd.Format(time.RFC3339)},
+				"limit":      {strconv.Itoa(pagerDutyPageSize)},
+				"offset":     {strconv.Itoa(offset)},
+			}
+			page, err := p.onCalls(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, entry := range page.OnCalls {
+				if entry.Start == nil || entry.End == nil {
+					continue
+				}
+				shifts = append(shifts, shiftDays(*entry.Start, *entry.End, p.loc))
+			}
+			if !page.More {
+				break
+			}
+		}
+	}
+	return shifts, nil
+}
+
+// onCalls reads one page of on-call entries
+func (p *pagerDuty) onCalls(ctx context.Context, params url.Values) (pagerDutyOnCalls, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pagerDutyURL+"/oncalls?"+params.Encode(), nil)
+	if err != nil {
+		return pagerDutyOnCalls{}, err
+	}
+	req.Header.Set("Authorization", "Token token="+p.apiKey)
+	req.Header.Set("Accept", "application/vnd.pagerduty+json;version=2")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return pagerDutyOnCalls{}, fmt.Errorf("failed to fetch on-call shifts from PagerDuty: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return pagerDutyOnCalls{}, fmt.Errorf("PagerDuty API returned status %d", resp.StatusCode)
+	}
+
+	var page pagerDutyOnCalls
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return pagerDutyOnCalls{}, fmt.Errorf("failed to parse PagerDuty on-call shifts: %w", err)
+	}
+	return page, nil
+}
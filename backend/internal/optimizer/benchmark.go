@@ -0,0 +1,60 @@
+package optimizer
+
+import (
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// BenchmarkResult totals the plans of a strategy over the benchmarked years
+// and budgets
+type BenchmarkResult struct {
+	Strategy         string        `json:"strategy"`
+	Runs             int           `json:"runs"`
+	TotalDaysOff     int           `json:"total_days_off"`
+	VacationDaysUsed int           `json:"vacation_days_used"`
+	AverageDaysOff   float64       `json:"average_days_off"` // Days off per run
+	Efficiency       float64       `json:"efficiency"`       // Days off per vacation day, over every run
+	LongestBlock     int           `json:"longest_block"`
+	UnusedDays       int           `json:"unused_days"` // Budgeted days no block used
+	Duration         time.Duration `json:"duration"`
+}
+
+// Benchmark runs every registered strategy for each year from fromYear to toYear and
+// each budget, on that year's calculated national holidays, so changes to
+// the algorithms can be compared on the same layouts
+func Benchmark(fromYear, toYear int, budgets []int, workWeek []string) []BenchmarkResult {
+	ids := StrategyIDs()
+	results := make([]BenchmarkResult, 0, len(ids))
+	for _, strategy := range ids {
+		result := BenchmarkResult{Strategy: strategy}
+		start := time.Now()
+		for year := fromYear; year <= toYear; year++ {
+			holidayList := holidays.CalculatedHolidays(year)
+			for _, budget := range budgets {
+				o := &Optimizer{Year: year, VacationDays: budget, WorkWeek: workWeek, Strategy: strategy, Holidays: holidayList}
+				used := 0
+				for _, block := range o.Optimize() {
+					result.TotalDaysOff += block.TotalDays
+					used += block.VacationDaysUsed
+					if block.TotalDays > result.LongestBlock {
+						result.LongestBlock = block.TotalDays
+					}
+				}
+				result.VacationDaysUsed += used
+				if used < budget {
+					result.UnusedDays += budget - used
+				}
+				result.Runs++
+			}
+		}
+		result.Duration = time.Since(start)
+		if result.Runs > 0 {
+			result.AverageDaysOff = float64(result.TotalDaysOff) / float64(result.Runs)
+		}
+		result.Efficiency = models.BlockEfficiency(result.TotalDaysOff, result.VacationDaysUsed)
+		results = append(results, result)
+	}
+	return results
+}
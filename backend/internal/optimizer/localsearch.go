@@ -0,0 +1,281 @@
+package optimizer
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// DefaultTimeBudget bounds the local search when no time budget is set
+const DefaultTimeBudget = 200 * time.Millisecond
+
+// Local search tuning: the iterations stop early once maxIterations are
+// tried, and the temperature falls linearly from startTemperature to zero
+// over the time budget
+const (
+	maxIterations    = 50000
+	startTemperature = 2.0
+	longBlockBonus   = 0.05 // Score per square day of a block, so long blocks beat several short ones
+	schoolBreakBonus = 0.5  // Score per vacation day inside a school break
+)
+
+// SetTimeBudget sets how long the local search strategy may run. Zero uses
+// DefaultTimeBudget.
+func (o *Optimizer) SetTimeBudget(budget time.Duration) {
+	o.TimeBudget = budget
+}
+
+// searchState is the year seen by the local search: which days are off
+// anyway, which may be taken as vacation and which are taken
+type searchState struct {
+	start     time.Time
+	off       []bool // Weekends and holidays
+	candidate []bool // Work days that may be taken as vacation
+	chosen    []bool
+	inBreak   []bool    // Days inside a school break
+	manual    []bool    // Manual vacation days, which end blocks but count towards maxRun
+	weight    []float64 // Weight of the personal events on each day
+	maxRun    int       // Longest run of days off, 0 for no limit
+}
+
+// localSearch starts from the balanced plan and moves single vacation days
+// around the year to escape its local optima. Worse plans are accepted with
+// a probability that falls as the time budget runs out (simulated
+// annealing), and the best plan seen is returned.
+func (o *Optimizer) localSearch() []models.VacationBlock {
+	state := o.newSearchState()
+	budget := o.VacationDays
+
+	var candidates []int
+	for day, ok := range state.candidate {
+		if ok {
+			candidates = append(candidates, day)
+		}
+	}
+	if len(candidates) == 0 || budget <= 0 {
+		return nil
+	}
+
+	// The greedy plan is the starting point
+	var chosen []int
+	for _, block := range o.balanced() {
+		for _, date := range block.Dates {
+			if day, ok := state.index(date); ok && state.candidate[day] && !state.chosen[day] {
+				state.chosen[day] = true
+				chosen = append(chosen, day)
+			}
+		}
+	}
+
+	timeBudget := o.TimeBudget
+	if timeBudget <= 0 {
+		timeBudget = DefaultTimeBudget
+	}
+	rng := rand.New(rand.NewSource(int64(o.Year)))
+	startedAt := time.Now()
+
+	score := state.score()
+	best := append([]int(nil), chosen...)
+	bestScore := score
+
+	for i := 0; i < maxIterations; i++ {
+		elapsed := time.Since(startedAt)
+		if elapsed >= timeBudget {
+			break
+		}
+		temperature := startTemperature * (1 - float64(elapsed)/float64(timeBudget))
+
+		// Add a day while some of the budget is left, otherwise move one:
+		// next to where it was or anywhere in the year
+		to := candidates[rng.Intn(len(candidates))]
+		from := -1
+		if len(chosen) >= budget || len(chosen) >= len(candidates) {
+			pos := rng.Intn(len(chosen))
+			from = chosen[pos]
+			if rng.Intn(2) == 0 {
+				if shifted := from + rng.Intn(7) - 3; shifted >= 0 && shifted < len(state.candidate) && state.candidate[shifted] {
+					to = shifted
+				}
+			}
+		}
+		if state.chosen[to] {
+			continue
+		}
+
+		if from >= 0 {
+			state.chosen[from] = false
+		}
+		state.chosen[to] = true
+		next := state.score()
+
+		delta := next - score
+		if delta >= 0 || rng.Float64() < math.Exp(delta/temperature) {
+			score = next
+			if from >= 0 {
+				for pos, day := range chosen {
+					if day == from {
+						chosen[pos] = to
+						break
+					}
+				}
+			} else {
+				chosen = append(chosen, to)
+			}
+			if score > bestScore {
+				bestScore = score
+				best = append(best[:0], chosen...)
+			}
+		} else {
+			state.chosen[to] = false
+			if from >= 0 {
+				state.chosen[from] = true
+			}
+		}
+	}
+
+	for day := range state.chosen {
+		state.chosen[day] = false
+	}
+	for _, day := range best {
+		state.chosen[day] = true
+	}
+	return o.searchBlocks(state)
+}
+
+// newSearchState lays out the days of the year for the local search
+func (o *Optimizer) newSearchState() *searchState {
+	start := time.Date(o.Year, 1, 1, 0, 0, 0, 0, time.UTC)
+	days := start.AddDate(1, 0, 0).Sub(start).Hours() / 24
+	state := &searchState{
+		start:     start,
+		off:       make([]bool, int(days)),
+		candidate: make([]bool, int(days)),
+		chosen:    make([]bool, int(days)),
+		inBreak:   make([]bool, int(days)),
+		manual:    make([]bool, int(days)),
+		weight:    make([]float64, int(days)),
+		maxRun:    o.MaxConsecutiveDays,
+	}
+
+	excluded := make(map[string]bool)
+	for _, date := range o.ExcludedDates {
+		excluded[date] = true
+	}
+
+	for day := range state.off {
+		date := start.AddDate(0, 0, day)
+		dateStr := date.Format("2006-01-02")
+		if dateStr < o.StartDate {
+			// Days before the start neither join nor extend blocks
+			continue
+		}
+		// Manual vacation days end blocks, like in the other strategies
+		isHoliday, _ := holidays.IsHoliday(date, o.Holidays)
+		switch {
+		case o.isManualVacation(dateStr):
+			state.manual[day] = true
+		case o.isWeekend(date) || isHoliday:
+			state.off[day] = true
+		case !excluded[dateStr]:
+			state.candidate[day] = true
+		}
+		state.weight[day] = o.EventWeights[dateStr]
+		for _, b := range o.SchoolBreaks {
+			if b.Contains(dateStr) {
+				state.inBreak[day] = true
+				break
+			}
+		}
+	}
+	return state
+}
+
+// index returns the day of the year of a date
+func (s *searchState) index(date string) (int, bool) {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return 0, false
+	}
+	day := int(t.Sub(s.start).Hours() / 24)
+	return day, day >= 0 && day < len(s.off)
+}
+
+// score rates the chosen days: the days off of every block holding a
+// vacation day, with a bonus for long blocks and for days in school breaks,
+// plus the weight of the personal events in those blocks.
+// Plans making a run of days off longer than maxRun score -Inf, so the
+// search never moves to them.
+func (s *searchState) score() float64 {
+	if s.maxRun > 0 && s.longestRun() > s.maxRun {
+		return math.Inf(-1)
+	}
+
+	score := 0.0
+	length, vacation, weight := 0, false, 0.0
+	closeBlock := func() {
+		if vacation {
+			score += float64(length) + longBlockBonus*float64(length*length) + weight
+		}
+		length, vacation, weight = 0, false, 0
+	}
+
+	for day := range s.off {
+		switch {
+		case s.chosen[day]:
+			length++
+			weight += s.weight[day]
+			vacation = true
+			if s.inBreak[day] {
+				score += schoolBreakBonus
+			}
+		case s.off[day]:
+			length++
+			weight += s.weight[day]
+		default:
+			closeBlock()
+		}
+	}
+	closeBlock()
+	return score
+}
+
+// longestRun returns the longest run of days off holding a chosen day, with
+// the manual vacations in it
+func (s *searchState) longestRun() int {
+	longest, length, vacation := 0, 0, false
+	for day := 0; day <= len(s.off); day++ {
+		if day < len(s.off) && (s.off[day] || s.chosen[day] || s.manual[day]) {
+			length++
+			vacation = vacation || s.chosen[day]
+			continue
+		}
+		if vacation && length > longest {
+			longest = length
+		}
+		length, vacation = 0, false
+	}
+	return longest
+}
+
+// searchBlocks turns the chosen days into the blocks of days off they form
+func (o *Optimizer) searchBlocks(state *searchState) []models.VacationBlock {
+	var blocks []models.VacationBlock
+	blockStart, vacation := -1, false
+	for day := 0; day <= len(state.off); day++ {
+		if day < len(state.off) && (state.off[day] || state.chosen[day]) {
+			if blockStart < 0 {
+				blockStart = day
+			}
+			vacation = vacation || state.chosen[day]
+			continue
+		}
+		if blockStart >= 0 && vacation {
+			blocks = append(blocks, o.calculateBlock(state.start.AddDate(0, 0, blockStart), state.start.AddDate(0, 0, day-1)))
+		}
+		blockStart, vacation = -1, false
+	}
+	return blocks
+}
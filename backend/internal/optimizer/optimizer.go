@@ -1,6 +1,7 @@
 package optimizer
 
 import (
+	"math"
 	"sort"
 	"time"
 
@@ -13,9 +14,17 @@ type Optimizer struct {
 	Year                 int
 	VacationDays         int
 	WorkWeek             []string
+	WorkWeekChanges      []models.WorkWeekChange
 	Strategy             string
 	Holidays             []holidays.PortugueseHoliday
 	ManualVacations      []string
+	SchoolBreaks         []holidays.SchoolBreak
+	ExcludedDates        []string
+	StartDate            string
+	TimeBudget           time.Duration         // How long the local search may run
+	ScoringWeights       models.ScoringWeights // How the custom strategy ranks blocks
+	MaxConsecutiveDays   int                   // Longest run of days off a plan may make, 0 for no limit
+	EventWeights         map[string]float64    // Weight of the personal events by date
 }
 
 // NewOptimizer creates a new optimizer
@@ -39,18 +48,71 @@ func (o *Optimizer) SetManualVacations(vacations []string) {
 	o.ManualVacations = vacations
 }
 
-// Optimize calculates optimal vacation days based on strategy
+// SetHolidays replaces the holidays used by the optimizer (e.g. to include
+// observed holidays)
+func (o *Optimizer) SetHolidays(holidayList []holidays.PortugueseHoliday) {
+	o.Holidays = holidayList
+}
+
+// SetWorkWeekChanges sets work weeks that take effect during the year
+func (o *Optimizer) SetWorkWeekChanges(changes []models.WorkWeekChange) {
+	o.WorkWeekChanges = changes
+}
+
+// SetExcludedDates sets work days that must not be taken as vacation, such
+// as those of declined blocks
+func (o *Optimizer) SetExcludedDates(dates []string) {
+	o.ExcludedDates = dates
+}
+
+// SetStartDate sets the first day blocks may start on, such as today when
+// past days can't change. Empty allows the whole year.
+func (o *Optimizer) SetStartDate(date string) {
+	o.StartDate = date
+}
+
+// SetSchoolBreaks sets school break periods that vacation blocks should align with
+func (o *Optimizer) SetSchoolBreaks(breaks []holidays.SchoolBreak) {
+	o.SchoolBreaks = breaks
+}
+
+// SetScoringWeights sets the weights the custom strategy ranks blocks by
+func (o *Optimizer) SetScoringWeights(weights models.ScoringWeights) {
+	o.ScoringWeights = weights
+}
+
+// SetMaxConsecutiveDays caps the run of days off a block may make together
+// with the weekends, holidays and vacations next to it. Zero lifts the cap.
+func (o *Optimizer) SetMaxConsecutiveDays(days int) {
+	o.MaxConsecutiveDays = days
+}
+
+// SetEventWeights sets the weight of the personal events by date: blocks
+// holding dates of positive weight are picked first and those holding dates
+// of negative weight last
+func (o *Optimizer) SetEventWeights(weights map[string]float64) {
+	o.EventWeights = weights
+}
+
+// Optimize calculates optimal vacation days with the registered strategy,
+// or the balanced one when it is unknown
 func (o *Optimizer) Optimize() []models.VacationBlock {
-	switch o.Strategy {
-	case models.StrategyBridgeHolidays:
-		return o.bridgeHolidays()
-	case models.StrategyLongestBlocks:
-		return o.longestBlocks()
-	case models.StrategyBalanced:
-		return o.balanced()
-	default:
-		return o.balanced()
+	if strategy, ok := LookupStrategy(o.Strategy); ok {
+		return strategy.Plan(o)
 	}
+	return o.balanced()
+}
+
+// Opportunities returns the candidate blocks around the holidays: bridges
+// and the weeks around them, for strategies to rank
+func (o *Optimizer) Opportunities() []models.VacationBlock {
+	return o.findAllOpportunities()
+}
+
+// SelectBlocks picks ranked blocks, best first, that fit the budget without
+// overlapping each other, the manual vacations or the excluded dates
+func (o *Optimizer) SelectBlocks(ranked []models.VacationBlock) []models.VacationBlock {
+	return o.selectBlocks(ranked)
 }
 
 // bridgeHolidays focuses on creating bridges between holidays and weekends
@@ -81,21 +143,120 @@ func (o *Optimizer) longestBlocks() []models.VacationBlock {
 
 // balanced combines both strategies
 func (o *Optimizer) balanced() []models.VacationBlock {
+	return o.weighted(models.DefaultScoringWeights)
+}
+
+// custom ranks blocks by the configured scoring weights
+func (o *Optimizer) custom() []models.VacationBlock {
+	weights := o.ScoringWeights
+	if weights.IsZero() {
+		weights = models.DefaultScoringWeights
+	}
+	return o.weighted(weights)
+}
+
+// weighted ranks the opportunities by their score under the weights. The
+// distribution term depends on the blocks already picked, so with it the
+// blocks are picked one at a time, ranking the rest again after each.
+func (o *Optimizer) weighted(weights models.ScoringWeights) []models.VacationBlock {
 	opportunities := o.findAllOpportunities()
-	
-	// Score based on both efficiency and total days
-	sort.Slice(opportunities, func(i, j int) bool {
-		effI := float64(opportunities[i].TotalDays) / float64(opportunities[i].VacationDaysUsed)
-		effJ := float64(opportunities[j].TotalDays) / float64(opportunities[j].VacationDaysUsed)
-		
-		// Weight: 60% efficiency, 40% total days
-		scoreI := effI*0.6 + float64(opportunities[i].TotalDays)*0.4
-		scoreJ := effJ*0.6 + float64(opportunities[j].TotalDays)*0.4
-		
-		return scoreI > scoreJ
-	})
 
-	return o.selectBlocks(opportunities)
+	if weights.Distribution == 0 {
+		sort.Slice(opportunities, func(i, j int) bool {
+			return scoreBlock(opportunities[i], weights, nil) > scoreBlock(opportunities[j], weights, nil)
+		})
+		return o.selectBlocks(opportunities)
+	}
+
+	var picked, selected []models.VacationBlock
+	for range opportunities {
+		ranked := append([]models.VacationBlock{}, opportunities...)
+		sort.SliceStable(ranked, func(i, j int) bool {
+			return scoreBlock(ranked[i], weights, picked) > scoreBlock(ranked[j], weights, picked)
+		})
+
+		// Blocks picked so far keep their place ahead of the rest
+		selected = o.selectBlocks(append(append([]models.VacationBlock{}, picked...), ranked...))
+		next := -1
+		for i, block := range selected {
+			if !containsBlock(picked, block) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			break
+		}
+		picked = append(picked, selected[next])
+	}
+	return selected
+}
+
+// maxDistributionWeeks caps how far apart blocks count as spread out
+const maxDistributionWeeks = 13
+
+// scoreBlock scores a block under the weights, given the blocks picked
+// before it
+func scoreBlock(block models.VacationBlock, weights models.ScoringWeights, picked []models.VacationBlock) float64 {
+	efficiency := float64(block.TotalDays) / float64(block.VacationDaysUsed)
+	score := efficiency*weights.Efficiency + float64(block.TotalDays)*weights.Length
+
+	if weights.Distribution != 0 {
+		score += weeksApart(block, picked) * weights.Distribution
+	}
+	if weights.MonthPreference != 0 && len(weights.PreferredMonths) > 0 {
+		inMonths := 0
+		for _, date := range block.Dates {
+			day, err := time.Parse("2006-01-02", date)
+			if err == nil && containsMonth(weights.PreferredMonths, int(day.Month())) {
+				inMonths++
+			}
+		}
+		score += float64(inMonths) * weights.MonthPreference
+	}
+	return score
+}
+
+// weeksApart returns the weeks between a block and the nearest picked one,
+// up to maxDistributionWeeks
+func weeksApart(block models.VacationBlock, picked []models.VacationBlock) float64 {
+	start, _ := time.Parse("2006-01-02", block.StartDate)
+	end, _ := time.Parse("2006-01-02", block.EndDate)
+
+	nearest := float64(maxDistributionWeeks)
+	for _, other := range picked {
+		otherStart, _ := time.Parse("2006-01-02", other.StartDate)
+		otherEnd, _ := time.Parse("2006-01-02", other.EndDate)
+
+		gap := 0.0
+		if otherEnd.Before(start) {
+			gap = start.Sub(otherEnd).Hours() / 24 / 7
+		} else if otherStart.After(end) {
+			gap = otherStart.Sub(end).Hours() / 24 / 7
+		}
+		nearest = math.Min(nearest, gap)
+	}
+	return nearest
+}
+
+// containsBlock reports whether a block with the same dates is in the list
+func containsBlock(blocks []models.VacationBlock, block models.VacationBlock) bool {
+	for _, b := range blocks {
+		if b.StartDate == block.StartDate && b.EndDate == block.EndDate {
+			return true
+		}
+	}
+	return false
+}
+
+// containsMonth reports whether a month is in the list
+func containsMonth(months []int, month int) bool {
+	for _, m := range months {
+		if m == month {
+			return true
+		}
+	}
+	return false
 }
 
 // findBridgeOpportunities finds opportunities to bridge holidays with weekends
@@ -177,6 +338,14 @@ func (o *Optimizer) findAllOpportunities() []models.VacationBlock {
 
 // calculateBlock calculates vacation block details between two dates
 func (o *Optimizer) calculateBlock(start, end time.Time) models.VacationBlock {
+	// Days outside the year belong to another year's budget
+	if yearStart := time.Date(o.Year, 1, 1, 0, 0, 0, 0, time.UTC); start.Before(yearStart) {
+		start = yearStart
+	}
+	if yearEnd := time.Date(o.Year, 12, 31, 0, 0, 0, 0, time.UTC); end.After(yearEnd) {
+		end = yearEnd
+	}
+
 	block := models.VacationBlock{
 		StartDate: start.Format("2006-01-02"),
 		EndDate:   end.Format("2006-01-02"),
@@ -199,6 +368,8 @@ func (o *Optimizer) calculateBlock(start, end time.Time) models.VacationBlock {
 		current = current.AddDate(0, 0, 1)
 	}
 	
+	block.Efficiency = models.BlockEfficiency(block.TotalDays, block.VacationDaysUsed)
+	
 	return block
 }
 
@@ -207,11 +378,26 @@ func (o *Optimizer) selectBlocks(opportunities []models.VacationBlock) []models.
 	var selected []models.VacationBlock
 	usedDays := 0 // Start from 0 since VacationDays already accounts for manual/reserved
 	usedDates := make(map[string]bool)
+	takenDates := make(map[string]bool) // Days off of the selected blocks
 	
-	// Mark manual vacation dates as used to prevent overlap
+	// Prefer blocks that fall within school breaks when aligning with them
+	if len(o.SchoolBreaks) > 0 {
+		opportunities = o.preferSchoolBreaks(opportunities)
+	}
+	
+	// Personal events come after, as they are about single dates
+	if len(o.EventWeights) > 0 {
+		opportunities = o.preferEvents(opportunities)
+	}
+	
+	// Mark manual vacation dates as used to prevent overlap, and excluded
+	// dates so no block takes them
 	for _, v := range o.ManualVacations {
 		usedDates[v] = true
 	}
+	for _, d := range o.ExcludedDates {
+		usedDates[d] = true
+	}
 	
 	for _, block := range opportunities {
 		// Check if we have enough days left
@@ -219,6 +405,10 @@ func (o *Optimizer) selectBlocks(opportunities []models.VacationBlock) []models.
 			continue
 		}
 		
+		if block.StartDate < o.StartDate {
+			continue
+		}
+		
 		// Check for overlapping dates
 		hasOverlap := false
 		for _, date := range block.Dates {
@@ -232,11 +422,16 @@ func (o *Optimizer) selectBlocks(opportunities []models.VacationBlock) []models.
 			continue
 		}
 		
+		if o.MaxConsecutiveDays > 0 && o.runLength(block, takenDates) > o.MaxConsecutiveDays {
+			continue
+		}
+		
 		// Add block
 		selected = append(selected, block)
 		usedDays += block.VacationDaysUsed
 		for _, date := range block.Dates {
 			usedDates[date] = true
+			takenDates[date] = true
 		}
 		
 		if usedDays >= o.VacationDays {
@@ -247,17 +442,147 @@ func (o *Optimizer) selectBlocks(opportunities []models.VacationBlock) []models.
 	return selected
 }
 
-// Helper functions
-func (o *Optimizer) isWeekend(date time.Time) bool {
-	day := date.Weekday()
-	dayName := weekdayToString(day)
-	
-	for _, workDay := range o.WorkWeek {
-		if workDay == dayName {
+// runLength returns the days off in a row a block makes with the weekends,
+// holidays, manual vacations and taken days around it, within the year
+func (o *Optimizer) runLength(block models.VacationBlock, taken map[string]bool) int {
+	isOff := func(date time.Time) bool {
+		if date.Year() != o.Year {
 			return false
 		}
+		dateStr := date.Format("2006-01-02")
+		isHoliday, _ := holidays.IsHoliday(date, o.Holidays)
+		return o.isWeekend(date) || isHoliday || taken[dateStr] || o.isManualVacation(dateStr)
+	}
+
+	start, _ := time.Parse("2006-01-02", block.StartDate)
+	end, _ := time.Parse("2006-01-02", block.EndDate)
+	length := block.TotalDays
+	for day := start.AddDate(0, 0, -1); isOff(day); day = day.AddDate(0, 0, -1) {
+		length++
+	}
+	for day := end.AddDate(0, 0, 1); isOff(day); day = day.AddDate(0, 0, 1) {
+		length++
+	}
+	return length
+}
+
+// preferEvents adds opportunities around the events that attract vacation,
+// then orders the blocks by the weight of the events they hold, keeping the
+// strategy's order among blocks of equal weight
+func (o *Optimizer) preferEvents(opportunities []models.VacationBlock) []models.VacationBlock {
+	var added []models.VacationBlock
+	for date, weight := range o.EventWeights {
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil || weight <= 0 || day.Year() != o.Year {
+			continue
+		}
+
+		// The days off around the event, and the week holding it
+		start, end := day, day
+		for o.isDayOff(start.AddDate(0, 0, -1)) {
+			start = start.AddDate(0, 0, -1)
+		}
+		for o.isDayOff(end.AddDate(0, 0, 1)) {
+			end = end.AddDate(0, 0, 1)
+		}
+		weekStart := o.findWeekStart(day)
+		for _, block := range []models.VacationBlock{o.calculateBlock(start, end), o.calculateBlock(weekStart, weekStart.AddDate(0, 0, 6))} {
+			if block.VacationDaysUsed > 0 {
+				added = append(added, block)
+			}
+		}
+	}
+	// Map order is random, so the added blocks are sorted for stable plans
+	sort.Slice(added, func(i, j int) bool {
+		if added[i].StartDate != added[j].StartDate {
+			return added[i].StartDate < added[j].StartDate
+		}
+		return added[i].EndDate < added[j].EndDate
+	})
+
+	ranked := o.deduplicateBlocks(append(append([]models.VacationBlock{}, opportunities...), added...))
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return o.eventWeight(ranked[i]) > o.eventWeight(ranked[j])
+	})
+	return ranked
+}
+
+// eventWeight sums the weights of the personal events during a block
+func (o *Optimizer) eventWeight(block models.VacationBlock) float64 {
+	weight := 0.0
+	for _, date := range block.Dates {
+		weight += o.EventWeights[date]
+	}
+	return weight
+}
+
+// isDayOff reports whether a day of the year is a weekend or holiday
+func (o *Optimizer) isDayOff(date time.Time) bool {
+	if date.Year() != o.Year {
+		return false
+	}
+	isHoliday, _ := holidays.IsHoliday(date, o.Holidays)
+	return o.isWeekend(date) || isHoliday
+}
+
+// preferSchoolBreaks adds week-long opportunities inside school breaks and moves
+// blocks overlapping a break ahead of the rest, keeping the strategy's order otherwise
+func (o *Optimizer) preferSchoolBreaks(opportunities []models.VacationBlock) []models.VacationBlock {
+	yearStart := time.Date(o.Year, 1, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := time.Date(o.Year, 12, 31, 0, 0, 0, 0, time.UTC)
+	
+	var breakWeeks []models.VacationBlock
+	for _, b := range o.SchoolBreaks {
+		start, err := time.Parse("2006-01-02", b.StartDate)
+		if err != nil {
+			continue
+		}
+		end, err := time.Parse("2006-01-02", b.EndDate)
+		if err != nil {
+			continue
+		}
+		
+		for weekStart := o.findWeekStart(start); !weekStart.After(end); weekStart = weekStart.AddDate(0, 0, 7) {
+			weekEnd := weekStart.AddDate(0, 0, 6)
+			if weekStart.Before(yearStart) || weekEnd.After(yearEnd) {
+				continue
+			}
+			block := o.calculateBlock(weekStart, weekEnd)
+			if block.VacationDaysUsed > 0 {
+				breakWeeks = append(breakWeeks, block)
+			}
+		}
 	}
-	return true
+	
+	candidates := o.deduplicateBlocks(append(opportunities, breakWeeks...))
+	
+	var aligned, others []models.VacationBlock
+	for _, block := range candidates {
+		if o.overlapsSchoolBreak(block) {
+			aligned = append(aligned, block)
+		} else {
+			others = append(others, block)
+		}
+	}
+	
+	return append(aligned, others...)
+}
+
+func (o *Optimizer) overlapsSchoolBreak(block models.VacationBlock) bool {
+	for _, date := range block.Dates {
+		for _, b := range o.SchoolBreaks {
+			if b.Contains(date) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Helper functions
+func (o *Optimizer) isWeekend(date time.Time) bool {
+	schedule := models.YearConfig{WorkWeek: o.WorkWeek, WorkWeekChanges: o.WorkWeekChanges}
+	return !schedule.IsWorkDay(date)
 }
 
 func (o *Optimizer) isWorkDay(date time.Time) bool {
@@ -0,0 +1,382 @@
+package optimizer
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/sandbox"
+)
+
+func TestMain(m *testing.M) {
+	// Use the built-in holiday calendar instead of the holiday APIs
+	sandbox.Enable()
+	os.Exit(m.Run())
+}
+
+var workWeek = []string{"monday", "tuesday", "wednesday", "thursday", "friday"}
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		strategy string
+		budget   int
+		manual   []string
+	}{
+		{models.StrategyBridgeHolidays, 5, nil},
+		{models.StrategyBridgeHolidays, 22, nil},
+		{models.StrategyLongestBlocks, 10, nil},
+		{models.StrategyLongestBlocks, 22, []string{"2025-08-04", "2025-08-05"}},
+		{models.StrategyBalanced, 1, nil},
+		{models.StrategyBalanced, 22, []string{"2025-04-17", "2025-12-26"}},
+		{models.StrategyLocalSearch, 3, nil},
+		{models.StrategyLocalSearch, 22, []string{"2025-08-04", "2025-08-05"}},
+		{models.StrategyCustom, 10, nil},
+		{models.StrategyCustom, 22, []string{"2025-08-04", "2025-08-05"}},
+		{"unknown", 10, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy+"/"+strconv.Itoa(tt.budget), func(t *testing.T) {
+			o := NewOptimizer(2025, tt.budget, workWeek, tt.strategy)
+			o.SetManualVacations(tt.manual)
+			blocks := o.Optimize()
+
+			if len(blocks) == 0 {
+				t.Fatal("no blocks selected")
+			}
+
+			manual := make(map[string]bool)
+			for _, date := range tt.manual {
+				manual[date] = true
+			}
+
+			used := 0
+			seen := make(map[string]bool)
+			for _, block := range blocks {
+				used += block.VacationDaysUsed
+
+				want := 0
+				for _, date := range block.Dates {
+					day, err := time.Parse("2006-01-02", date)
+					if err != nil || day.Year() != 2025 {
+						t.Errorf("block %s..%s has date %s outside the year", block.StartDate, block.EndDate, date)
+						continue
+					}
+					if manual[date] {
+						t.Errorf("block %s..%s overlaps manual vacation %s", block.StartDate, block.EndDate, date)
+					}
+					if seen[date] {
+						t.Errorf("date %s is in more than one block", date)
+					}
+					seen[date] = true
+
+					if isHoliday, _ := holidays.IsHoliday(day, o.Holidays); !o.isWeekend(day) && !isHoliday {
+						want++
+					}
+				}
+				if block.VacationDaysUsed != want {
+					t.Errorf("block %s..%s uses %d vacation days, want %d", block.StartDate, block.EndDate, block.VacationDaysUsed, want)
+				}
+			}
+
+			if used > tt.budget {
+				t.Errorf("blocks use %d vacation days, budget is %d", used, tt.budget)
+			}
+		})
+	}
+}
+
+func TestCalculateBlock(t *testing.T) {
+	tests := []struct {
+		name         string
+		start, end   string
+		workWeek     []string
+		wantTotal    int
+		wantUsed     int
+		wantHolidays int
+	}{
+		{"full work week", "2025-03-03", "2025-03-07", workWeek, 5, 5, 0},
+		{"week with weekend", "2025-03-03", "2025-03-09", workWeek, 7, 5, 0},
+		// Christmas falls on a Thursday
+		{"christmas bridge", "2025-12-25", "2025-12-28", workWeek, 4, 1, 1},
+		{"four day work week", "2025-03-03", "2025-03-09", workWeek[:4], 7, 4, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewOptimizer(2025, 22, tt.workWeek, models.StrategyBalanced)
+			start, _ := time.Parse("2006-01-02", tt.start)
+			end, _ := time.Parse("2006-01-02", tt.end)
+
+			block := o.calculateBlock(start, end)
+			if block.TotalDays != tt.wantTotal || block.VacationDaysUsed != tt.wantUsed || len(block.Holidays) != tt.wantHolidays {
+				t.Errorf("got %d days, %d used, %d holidays; want %d, %d, %d",
+					block.TotalDays, block.VacationDaysUsed, len(block.Holidays),
+					tt.wantTotal, tt.wantUsed, tt.wantHolidays)
+			}
+		})
+	}
+}
+
+func TestCalculateBlockYearEdges(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		wantStart  string
+		wantEnd    string
+		wantUsed   int
+	}{
+		// New Year's Day falls on a Wednesday
+		{"from the previous year", "2024-12-29", "2025-01-05", "2025-01-01", "2025-01-05", 2},
+		{"into the next year", "2025-12-29", "2026-01-02", "2025-12-29", "2025-12-31", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewOptimizer(2025, 22, workWeek, models.StrategyBalanced)
+			start, _ := time.Parse("2006-01-02", tt.start)
+			end, _ := time.Parse("2006-01-02", tt.end)
+
+			// Days outside the year are another year's budget
+			block := o.calculateBlock(start, end)
+			if block.StartDate != tt.wantStart || block.EndDate != tt.wantEnd || block.VacationDaysUsed != tt.wantUsed {
+				t.Errorf("got %s..%s using %d days; want %s..%s using %d",
+					block.StartDate, block.EndDate, block.VacationDaysUsed, tt.wantStart, tt.wantEnd, tt.wantUsed)
+			}
+		})
+	}
+}
+
+func TestExcludedDates(t *testing.T) {
+	first := NewOptimizer(2025, 5, workWeek, models.StrategyBridgeHolidays).Optimize()
+	if len(first) == 0 {
+		t.Fatal("no blocks selected")
+	}
+
+	// Decline the first block's vacation days
+	var excluded []string
+	for _, date := range first[0].Dates {
+		if !contains(first[0].Weekends, date) && !contains(first[0].Holidays, date) {
+			excluded = append(excluded, date)
+		}
+	}
+
+	o := NewOptimizer(2025, 5, workWeek, models.StrategyBridgeHolidays)
+	o.SetExcludedDates(excluded)
+	for _, block := range o.Optimize() {
+		for _, date := range excluded {
+			if contains(block.Dates, date) {
+				t.Errorf("block %s..%s takes excluded %s", block.StartDate, block.EndDate, date)
+			}
+		}
+	}
+}
+
+func TestStartDate(t *testing.T) {
+	o := NewOptimizer(2025, 10, workWeek, models.StrategyBalanced)
+	o.SetStartDate("2025-07-01")
+	blocks := o.Optimize()
+	if len(blocks) == 0 {
+		t.Fatal("no blocks selected")
+	}
+	for _, block := range blocks {
+		if block.StartDate < "2025-07-01" {
+			t.Errorf("block %s..%s starts before the start date", block.StartDate, block.EndDate)
+		}
+	}
+}
+
+func TestLocalSearch(t *testing.T) {
+	daysOff := func(strategy string) int {
+		o := NewOptimizer(2025, 22, workWeek, strategy)
+		o.SetTimeBudget(50 * time.Millisecond)
+		total := 0
+		for _, block := range o.Optimize() {
+			total += block.TotalDays
+		}
+		return total
+	}
+
+	// The search starts from the balanced plan and only keeps better ones
+	if got, greedy := daysOff(models.StrategyLocalSearch), daysOff(models.StrategyBalanced); got < greedy {
+		t.Errorf("local search found %d days off, fewer than the %d of the balanced plan it starts from", got, greedy)
+	}
+}
+
+func TestCustomStrategy(t *testing.T) {
+	plan := func(weights models.ScoringWeights) []models.VacationBlock {
+		o := NewOptimizer(2025, 22, workWeek, models.StrategyCustom)
+		o.SetScoringWeights(weights)
+		return o.Optimize()
+	}
+	daysIn := func(blocks []models.VacationBlock, month string) int {
+		n := 0
+		for _, block := range blocks {
+			for _, date := range block.Dates {
+				if date[5:7] == month {
+					n++
+				}
+			}
+		}
+		return n
+	}
+
+	// Without weights the custom strategy plans what the balanced one does
+	balanced := NewOptimizer(2025, 22, workWeek, models.StrategyBalanced).Optimize()
+	if got := plan(models.ScoringWeights{}); !reflect.DeepEqual(got, balanced) {
+		t.Errorf("custom plan without weights differs from the balanced plan")
+	}
+
+	preferAugust := plan(models.ScoringWeights{Efficiency: 0.6, Length: 0.4, MonthPreference: 1, PreferredMonths: []int{8}})
+	if got, base := daysIn(preferAugust, "08"), daysIn(balanced, "08"); got <= base {
+		t.Errorf("preferring August planned %d days off in it, want more than the %d of the balanced plan", got, base)
+	}
+
+	// Spreading the blocks out moves the two longest ones further apart
+	longestGap := func(blocks []models.VacationBlock) float64 {
+		ranked := append([]models.VacationBlock{}, blocks...)
+		sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].TotalDays > ranked[j].TotalDays })
+		return weeksApart(ranked[0], ranked[1:2])
+	}
+	spread := plan(models.ScoringWeights{Efficiency: 0.6, Length: 0.4, Distribution: 5})
+	if got, base := longestGap(spread), longestGap(balanced); got <= base {
+		t.Errorf("spread out plan has its longest blocks %.1f weeks apart, want more than the %.1f of the balanced plan", got, base)
+	}
+}
+
+func TestMaxConsecutiveDays(t *testing.T) {
+	manual := []string{"2025-08-04", "2025-08-05"}
+	for _, strategy := range StrategyIDs() {
+		t.Run(strategy, func(t *testing.T) {
+			o := NewOptimizer(2025, 22, workWeek, strategy)
+			o.SetManualVacations(manual)
+			o.SetMaxConsecutiveDays(5)
+			blocks := o.Optimize()
+			if len(blocks) == 0 {
+				t.Fatal("no blocks selected")
+			}
+
+			off := make(map[string]bool)
+			for _, date := range manual {
+				off[date] = true
+			}
+			for _, block := range blocks {
+				for _, date := range block.Dates {
+					off[date] = true
+				}
+			}
+
+			// Every run of days off holding a planned day fits the cap
+			run, planned := 0, false
+			for day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC); day.Year() == 2025; day = day.AddDate(0, 0, 1) {
+				date := day.Format("2006-01-02")
+				if isHoliday, _ := holidays.IsHoliday(day, o.Holidays); o.isWeekend(day) || isHoliday || off[date] {
+					run++
+					planned = planned || (off[date] && !contains(manual, date))
+					if planned && run > 5 {
+						t.Fatalf("%d days off in a row up to %s, want at most 5", run, date)
+					}
+					continue
+				}
+				run, planned = 0, false
+			}
+		})
+	}
+}
+
+func TestEventWeights(t *testing.T) {
+	plan := func(weights map[string]float64) []models.VacationBlock {
+		o := NewOptimizer(2025, 5, workWeek, models.StrategyBalanced)
+		o.SetEventWeights(weights)
+		return o.Optimize()
+	}
+	holds := func(blocks []models.VacationBlock, date string) bool {
+		for _, block := range blocks {
+			if contains(block.Dates, date) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// A race on a Wednesday far from any holiday draws a block to it
+	if blocks := plan(map[string]float64{"2025-10-15": 3}); !holds(blocks, "2025-10-15") {
+		t.Errorf("no block holds the attracting event on 2025-10-15")
+	}
+
+	// A date the plan takes is left out once an event repels vacation
+	baseline := plan(nil)
+	if len(baseline) == 0 {
+		t.Fatal("no blocks selected")
+	}
+	first := baseline[0]
+	var taken string
+	for _, date := range first.Dates {
+		if !contains(first.Weekends, date) && !contains(first.Holidays, date) {
+			taken = date
+			break
+		}
+	}
+	if blocks := plan(map[string]float64{taken: -5}); holds(blocks, taken) {
+		t.Errorf("a block still holds %s, which a personal event repels", taken)
+	}
+}
+
+func TestRegisterStrategy(t *testing.T) {
+	// A strategy taking the first bridge only, ranked by the optimizer's
+	// own opportunities
+	Register("test_first_bridge", "First bridge", "Takes the first bridge of the year", StrategyFunc(func(o *Optimizer) []models.VacationBlock {
+		blocks := o.SelectBlocks(o.Opportunities())
+		if len(blocks) > 1 {
+			blocks = blocks[:1]
+		}
+		return blocks
+	}))
+
+	if !contains(StrategyIDs(), "test_first_bridge") {
+		t.Fatalf("strategies %v, want test_first_bridge registered", StrategyIDs())
+	}
+	if blocks := NewOptimizer(2025, 22, workWeek, "test_first_bridge").Optimize(); len(blocks) != 1 {
+		t.Errorf("registered strategy planned %d blocks, want 1", len(blocks))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a strategy id twice didn't panic")
+		}
+	}()
+	Register(models.StrategyBalanced, "Balanced again", "", StrategyFunc((*Optimizer).balanced))
+}
+
+func TestBenchmark(t *testing.T) {
+	results := Benchmark(2024, 2026, []int{5, 22}, workWeek)
+	ids := StrategyIDs()
+	if len(results) != len(ids) {
+		t.Fatalf("%d results, want one per strategy", len(results))
+	}
+	for i, result := range results {
+		if result.Strategy != ids[i] {
+			t.Errorf("result %d is %s, want %s", i, result.Strategy, ids[i])
+		}
+		if result.Runs != 6 {
+			t.Errorf("%s: %d runs, want 3 years times 2 budgets", result.Strategy, result.Runs)
+		}
+		if result.VacationDaysUsed+result.UnusedDays < 6*5 || result.TotalDaysOff <= result.VacationDaysUsed || result.Efficiency <= 1 {
+			t.Errorf("%s: %+v, want more days off than vacation days used", result.Strategy, result)
+		}
+	}
+}
+
+func contains(dates []string, date string) bool {
+	for _, d := range dates {
+		if d == date {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package optimizer
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// Strategy plans the vacation blocks of an optimizer's year within its
+// budget. New strategies register themselves with Register, usually from an
+// init function in this package, and are picked by their id.
+type Strategy interface {
+	Plan(o *Optimizer) []models.VacationBlock
+}
+
+// StrategyFunc adapts a function to a Strategy
+type StrategyFunc func(o *Optimizer) []models.VacationBlock
+
+// Plan calls f
+func (f StrategyFunc) Plan(o *Optimizer) []models.VacationBlock {
+	return f(o)
+}
+
+// StrategyInfo describes a registered strategy
+type StrategyInfo struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Strategy    Strategy `json:"-"`
+}
+
+var (
+	strategiesMu sync.RWMutex
+	strategies   []StrategyInfo
+)
+
+// Register adds a strategy. It panics when the id is empty or already
+// registered, like registering a database driver twice.
+func Register(id, name, description string, strategy Strategy) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+
+	if id == "" || strategy == nil {
+		panic("optimizer: Register needs an id and a strategy")
+	}
+	for _, s := range strategies {
+		if s.ID == id {
+			panic(fmt.Sprintf("optimizer: strategy %s registered twice", id))
+		}
+	}
+	strategies = append(strategies, StrategyInfo{ID: id, Name: name, Description: description, Strategy: strategy})
+}
+
+// Strategies returns the registered strategies, in the order they were
+// registered
+func Strategies() []StrategyInfo {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	return append([]StrategyInfo(nil), strategies...)
+}
+
+// StrategyIDs returns the ids of the registered strategies
+func StrategyIDs() []string {
+	var ids []string
+	for _, s := range Strategies() {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+// LookupStrategy returns the strategy registered with an id
+func LookupStrategy(id string) (Strategy, bool) {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	for _, s := range strategies {
+		if s.ID == id {
+			return s.Strategy, true
+		}
+	}
+	return nil, false
+}
+
+func init() {
+	Register(models.StrategyBridgeHolidays, "Bridge Holidays", "Focus on creating bridges between holidays and weekends for efficient use of vacation days",
+		StrategyFunc((*Optimizer).bridgeHolidays))
+	Register(models.StrategyLongestBlocks, "Longest Blocks", "Focus on creating the longest possible consecutive vacation periods",
+		StrategyFunc((*Optimizer).longestBlocks))
+	Register(models.StrategyBalanced, "Balanced", "Balance between efficiency and length of vacation blocks",
+		StrategyFunc((*Optimizer).balanced))
+	Register(models.StrategyLocalSearch, "Local Search", "Start from the balanced plan and keep moving days around the year while it finds more days off, within a time budget",
+		StrategyFunc((*Optimizer).localSearch))
+	Register(models.StrategyCustom, "Custom", "Rank blocks by your own weights for efficiency, length, spread over the year and preferred months",
+		StrategyFunc((*Optimizer).custom))
+}
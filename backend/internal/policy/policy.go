@@ -0,0 +1,165 @@
+// Package policy evaluates standing booking rules, such as "take the bridge
+// day after a Thursday holiday" or "never exceed 2 consecutive weeks off",
+// against a year's calendar and proposes the vacation days they call for.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// Limits of the rule parameters
+const (
+	MaxBridgeGap = 3
+	MaxWeeks     = 52
+)
+
+// Proposal is a work day a rule proposes to take off
+type Proposal struct {
+	Date   string
+	RuleID int64
+	Reason string
+}
+
+// Validate returns an error unless a rule is complete and its parameters are
+// in range
+func Validate(rule models.BookingRule) error {
+	if strings.TrimSpace(rule.Name) == "" {
+		return errors.New("name is required")
+	}
+
+	switch rule.Kind {
+	case models.RuleBridge:
+		if rule.MaxGap < 1 || rule.MaxGap > MaxBridgeGap {
+			return fmt.Errorf("max_gap must be between 1 and %d", MaxBridgeGap)
+		}
+		for _, day := range rule.HolidayWeekdays {
+			if _, ok := weekdays[strings.ToLower(day)]; !ok {
+				return fmt.Errorf("invalid weekday %q in holiday_weekdays", day)
+			}
+		}
+	case models.RuleMaxConsecutiveWeeks:
+		if rule.MaxWeeks < 1 || rule.MaxWeeks > MaxWeeks {
+			return fmt.Errorf("max_weeks must be between 1 and %d", MaxWeeks)
+		}
+	default:
+		return fmt.Errorf("unknown rule kind %q, must be %s or %s", rule.Kind, models.RuleBridge, models.RuleMaxConsecutiveWeeks)
+	}
+	return nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// Evaluate returns the days the enabled rules propose to take off, by date.
+// days is a year's calendar in date order; weekends, holidays, vacation and
+// comp days are off. Bridge rules propose the work days between a holiday
+// and other days off, in rule order, and max_consecutive_weeks rules drop
+// the bridges that would make a longer run of days off.
+func Evaluate(days []models.CalendarDay, rules []models.BookingRule) []Proposal {
+	off := make([]bool, len(days))
+	for i, day := range days {
+		off[i] = day.IsWeekend || day.IsHoliday || day.IsVacation || day.IsCompDay
+	}
+
+	maxRun := MaxConsecutiveDays(rules)
+
+	var proposals []Proposal
+	for _, rule := range rules {
+		if !rule.Enabled || rule.Kind != models.RuleBridge {
+			continue
+		}
+
+		for start := 0; start < len(days); {
+			if off[start] {
+				start++
+				continue
+			}
+			end := start
+			for end < len(days) && !off[end] {
+				end++
+			}
+
+			// Work days start..end-1, with days off on both sides in the year
+			if start > 0 && end < len(days) && end-start <= rule.MaxGap {
+				holiday, ok := adjacentHoliday(days, start-1, end, rule.HolidayWeekdays)
+				if ok && (maxRun == 0 || runLength(off, start, end) <= maxRun) {
+					for i := start; i < end; i++ {
+						off[i] = true
+						proposals = append(proposals, Proposal{
+							Date:   days[i].Date,
+							RuleID: rule.ID,
+							Reason: fmt.Sprintf("Bridges %s on %s", holiday.HolidayName, holiday.Date),
+						})
+					}
+				}
+			}
+			start = end
+		}
+	}
+
+	sort.Slice(proposals, func(i, j int) bool { return proposals[i].Date < proposals[j].Date })
+	return proposals
+}
+
+// MaxConsecutiveDays returns the longest run of days off the enabled
+// max_consecutive_weeks rules allow, or 0 when none limits it. The strictest
+// limit applies.
+func MaxConsecutiveDays(rules []models.BookingRule) int {
+	maxRun := 0
+	for _, rule := range rules {
+		if rule.Enabled && rule.Kind == models.RuleMaxConsecutiveWeeks && (maxRun == 0 || rule.MaxWeeks*7 < maxRun) {
+			maxRun = rule.MaxWeeks * 7
+		}
+	}
+	return maxRun
+}
+
+// adjacentHoliday returns the holiday right before or after a run of work
+// days, on one of the weekdays when any are given
+func adjacentHoliday(days []models.CalendarDay, before, after int, onWeekdays []string) (models.CalendarDay, bool) {
+	for _, i := range []int{before, after} {
+		day := days[i]
+		if !day.IsHoliday {
+			continue
+		}
+		if len(onWeekdays) == 0 {
+			return day, true
+		}
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			continue
+		}
+		for _, name := range onWeekdays {
+			if weekdays[strings.ToLower(name)] == date.Weekday() {
+				return day, true
+			}
+		}
+	}
+	return models.CalendarDay{}, false
+}
+
+// runLength returns the length of the run of days off that filling the work
+// days start..end-1 would make
+func runLength(off []bool, start, end int) int {
+	first, last := start, end-1
+	for first > 0 && off[first-1] {
+		first--
+	}
+	for last < len(off)-1 && off[last+1] {
+		last++
+	}
+	return last - first + 1
+}
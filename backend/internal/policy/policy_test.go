@@ -0,0 +1,164 @@
+package policy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// december builds the calendar of December 2025, with Christmas on a
+// Thursday, Saturdays and Sundays off and the given vacation days
+func december(vacation ...string) []models.CalendarDay {
+	var days []models.CalendarDay
+	for d := time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC); d.Month() == time.December; d = d.AddDate(0, 0, 1) {
+		day := models.CalendarDay{
+			Date:      d.Format("2006-01-02"),
+			IsWeekend: d.Weekday() == time.Saturday || d.Weekday() == time.Sunday,
+		}
+		if day.Date == "2025-12-08" || day.Date == "2025-12-25" {
+			day.IsHoliday = true
+			day.HolidayName = "Holiday " + day.Date
+		}
+		for _, v := range vacation {
+			if v == day.Date {
+				day.IsVacation = true
+			}
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+func dates(proposals []Proposal) []string {
+	var out []string
+	for _, p := range proposals {
+		out = append(out, p.Date)
+	}
+	return out
+}
+
+func TestEvaluate(t *testing.T) {
+	bridge := models.BookingRule{ID: 1, Name: "Bridges", Kind: models.RuleBridge, MaxGap: 1, Enabled: true}
+	thursdays := models.BookingRule{ID: 2, Name: "Thursday bridges", Kind: models.RuleBridge, MaxGap: 1, HolidayWeekdays: []string{"thursday"}, Enabled: true}
+	oneWeek := models.BookingRule{ID: 3, Name: "One week", Kind: models.RuleMaxConsecutiveWeeks, MaxWeeks: 1, Enabled: true}
+
+	tests := []struct {
+		name     string
+		days     []models.CalendarDay
+		rules    []models.BookingRule
+		want     []string
+		wantRule int64
+	}{
+		{
+			name:  "bridges next to any holiday",
+			days:  december(),
+			rules: []models.BookingRule{bridge},
+			// Monday the 8th has no gap to bridge; Friday the 26th does
+			want:     []string{"2025-12-26"},
+			wantRule: 1,
+		},
+		{
+			name:     "only thursday holidays",
+			days:     december(),
+			rules:    []models.BookingRule{thursdays},
+			want:     []string{"2025-12-26"},
+			wantRule: 2,
+		},
+		{
+			name:  "other weekdays",
+			days:  december(),
+			rules: []models.BookingRule{{ID: 4, Name: "Tuesday bridges", Kind: models.RuleBridge, MaxGap: 1, HolidayWeekdays: []string{"tuesday"}, Enabled: true}},
+		},
+		{
+			name:  "disabled rule",
+			days:  december(),
+			rules: []models.BookingRule{{ID: 1, Name: "Off", Kind: models.RuleBridge, MaxGap: 1}},
+		},
+		{
+			name: "run longer than the limit",
+			// With the week before Christmas off, bridging the 26th makes
+			// a run of 15 days
+			days:  december("2025-12-15", "2025-12-16", "2025-12-17", "2025-12-18", "2025-12-19", "2025-12-22", "2025-12-23", "2025-12-24"),
+			rules: []models.BookingRule{bridge, oneWeek},
+		},
+		{
+			name:     "run within the limit",
+			days:     december(),
+			rules:    []models.BookingRule{bridge, oneWeek},
+			want:     []string{"2025-12-26"},
+			wantRule: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Evaluate(tt.days, tt.rules)
+			if !reflect.DeepEqual(dates(got), tt.want) {
+				t.Fatalf("Evaluate = %v, want %v", dates(got), tt.want)
+			}
+			for _, p := range got {
+				if p.RuleID != tt.wantRule {
+					t.Errorf("proposal for %s by rule %d, want %d", p.Date, p.RuleID, tt.wantRule)
+				}
+				if p.Reason != "Bridges Holiday 2025-12-25 on 2025-12-25" {
+					t.Errorf("reason = %q", p.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  models.BookingRule
+		valid bool
+	}{
+		{"bridge", models.BookingRule{Name: "b", Kind: models.RuleBridge, MaxGap: 2, HolidayWeekdays: []string{"thursday", "tuesday"}}, true},
+		{"bridge gap too wide", models.BookingRule{Name: "b", Kind: models.RuleBridge, MaxGap: 4}, false},
+		{"bridge bad weekday", models.BookingRule{Name: "b", Kind: models.RuleBridge, MaxGap: 1, HolidayWeekdays: []string{"thu"}}, false},
+		{"max weeks", models.BookingRule{Name: "m", Kind: models.RuleMaxConsecutiveWeeks, MaxWeeks: 2}, true},
+		{"max weeks missing", models.BookingRule{Name: "m", Kind: models.RuleMaxConsecutiveWeeks}, false},
+		{"no name", models.BookingRule{Kind: models.RuleMaxConsecutiveWeeks, MaxWeeks: 2}, false},
+		{"unknown kind", models.BookingRule{Name: "x", Kind: "always"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.rule)
+			if (err == nil) != tt.valid {
+				t.Errorf("Validate = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestMaxConsecutiveDays(t *testing.T) {
+	twoWeeks := models.BookingRule{Name: "Two weeks", Kind: models.RuleMaxConsecutiveWeeks, MaxWeeks: 2, Enabled: true}
+	oneWeek := models.BookingRule{Name: "One week", Kind: models.RuleMaxConsecutiveWeeks, MaxWeeks: 1, Enabled: true}
+	disabled := oneWeek
+	disabled.Enabled = false
+	bridge := models.BookingRule{Name: "Bridges", Kind: models.RuleBridge, MaxGap: 1, Enabled: true}
+
+	tests := []struct {
+		name  string
+		rules []models.BookingRule
+		want  int
+	}{
+		{"no rules", nil, 0},
+		{"bridges only", []models.BookingRule{bridge}, 0},
+		{"one limit", []models.BookingRule{bridge, twoWeeks}, 14},
+		{"strictest limit", []models.BookingRule{twoWeeks, oneWeek}, 7},
+		{"disabled limit", []models.BookingRule{twoWeeks, disabled}, 14},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaxConsecutiveDays(tt.rules); got != tt.want {
+				t.Errorf("MaxConsecutiveDays = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// MaxScale is the largest PNG scale, for print-quality images
+const MaxScale = 4
+
+var (
+	fontsOnce             sync.Once
+	regularFont, boldFont *opentype.Font
+	fontsErr              error
+)
+
+func loadFonts() error {
+	fontsOnce.Do(func() {
+		if regularFont, fontsErr = opentype.Parse(goregular.TTF); fontsErr != nil {
+			return
+		}
+		boldFont, fontsErr = opentype.Parse(gobold.TTF)
+	})
+	return fontsErr
+}
+
+// PNG writes the calendar as a PNG image, scale times the size of the SVG
+func PNG(w io.Writer, cal models.CalendarResponse, scale int) error {
+	if scale < 1 || scale > MaxScale {
+		return fmt.Errorf("scale must be between 1 and %d", MaxScale)
+	}
+	if err := loadFonts(); err != nil {
+		return err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, Width*scale, Height*scale))
+	faces := map[[2]int]font.Face{}
+	defer func() {
+		for _, face := range faces {
+			face.Close()
+		}
+	}()
+
+	for _, s := range layout(cal) {
+		if s.Text == "" {
+			rect := image.Rect(s.X*scale, s.Y*scale, (s.X+s.W)*scale, (s.Y+s.H)*scale)
+			draw.Draw(img, rect, image.NewUniform(s.Fill), image.Point{}, draw.Src)
+			continue
+		}
+
+		bold := 0
+		if s.Bold {
+			bold = 1
+		}
+		key := [2]int{s.Size, bold}
+		face, ok := faces[key]
+		if !ok {
+			f := regularFont
+			if s.Bold {
+				f = boldFont
+			}
+			var err error
+			face, err = opentype.NewFace(f, &opentype.FaceOptions{Size: float64(s.Size * scale), DPI: 72, Hinting: font.HintingFull})
+			if err != nil {
+				return err
+			}
+			faces[key] = face
+		}
+
+		d := &font.Drawer{Dst: img, Src: image.NewUniform(s.Fill), Face: face}
+		x := fixed.I(s.X * scale)
+		if s.Anchor == anchorMiddle {
+			x -= d.MeasureString(s.Text) / 2
+		}
+		d.Dot = fixed.Point26_6{X: x, Y: fixed.I(s.Y * scale)}
+		d.DrawString(s.Text)
+	}
+
+	return png.Encode(w, img)
+}
@@ -0,0 +1,146 @@
+// Package render draws a year's calendar as a grid of months with holidays,
+// weekends and vacation days colored, as SVG or PNG. Both formats are drawn
+// from the same list of shapes, so they look the same.
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// Colors of the app's light theme
+var (
+	colorBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	colorText       = color.RGBA{0x1e, 0x29, 0x3b, 0xff}
+	colorMuted      = color.RGBA{0x64, 0x74, 0x8b, 0xff}
+	colorWeekend    = color.RGBA{0xee, 0xef, 0xf1, 0xff}
+	colorHoliday    = color.RGBA{0xb7, 0x1c, 0x1c, 0xff}
+	colorManual     = color.RGBA{0x1a, 0x5f, 0x2a, 0xff}
+	colorOptimized  = color.RGBA{0x0d, 0x94, 0x88, 0xff}
+)
+
+// Grid geometry in pixels at scale 1
+const (
+	cell       = 26
+	cellGap    = 2
+	monthGap   = 24
+	margin     = 24
+	titleSize  = 22
+	monthSize  = 14
+	daySize    = 11
+	monthCols  = 4
+	monthRows  = 3
+	weekRows   = 6
+	headerRows = 2 // Month name and weekday initials
+)
+
+var (
+	monthWidth  = 7*cell + 6*cellGap
+	monthHeight = (headerRows+weekRows)*cell + (headerRows+weekRows-1)*cellGap
+	titleHeight = 48
+	legendSpace = 40
+
+	// Width and Height of the drawing at scale 1
+	Width  = 2*margin + monthCols*monthWidth + (monthCols-1)*monthGap
+	Height = 2*margin + titleHeight + monthRows*monthHeight + (monthRows-1)*monthGap + legendSpace
+)
+
+type anchor int
+
+const (
+	anchorStart anchor = iota
+	anchorMiddle
+)
+
+// shape is a filled rectangle, or a line of text when Text is set
+type shape struct {
+	X, Y, W, H int // Text is drawn with its baseline at Y
+	Fill       color.RGBA
+	Text       string
+	Size       int
+	Bold       bool
+	Anchor     anchor
+	Title      string // Tooltip, SVG only
+}
+
+// layout returns the shapes of a calendar
+func layout(cal models.CalendarResponse) []shape {
+	shapes := []shape{{X: 0, Y: 0, W: Width, H: Height, Fill: colorBackground}}
+	shapes = append(shapes, shape{X: margin, Y: margin + titleSize, Text: fmt.Sprintf("Vacation plan %d", cal.Year), Size: titleSize, Bold: true, Fill: colorText})
+
+	days := make(map[string]models.CalendarDay, len(cal.Days))
+	for _, day := range cal.Days {
+		days[day.Date] = day
+	}
+
+	for month := time.January; month <= time.December; month++ {
+		col := int(month-1) % monthCols
+		row := int(month-1) / monthCols
+		x0 := margin + col*(monthWidth+monthGap)
+		y0 := margin + titleHeight + row*(monthHeight+monthGap)
+
+		shapes = append(shapes, shape{X: x0, Y: y0 + monthSize, Text: month.String(), Size: monthSize, Bold: true, Fill: colorText})
+		for i, initial := range []string{"M", "T", "W", "T", "F", "S", "S"} {
+			shapes = append(shapes, shape{
+				X: x0 + i*(cell+cellGap) + cell/2, Y: y0 + (cell + cellGap) + cell/2 + daySize/2 - 1,
+				Text: initial, Size: daySize, Fill: colorMuted, Anchor: anchorMiddle,
+			})
+		}
+
+		first := time.Date(cal.Year, month, 1, 0, 0, 0, 0, time.UTC)
+		offset := (int(first.Weekday()) + 6) % 7 // Weeks start on Monday
+		for date := first; date.Month() == month; date = date.AddDate(0, 0, 1) {
+			slot := offset + date.Day() - 1
+			x := x0 + (slot%7)*(cell+cellGap)
+			y := y0 + (headerRows+slot/7)*(cell+cellGap)
+
+			day := days[date.Format("2006-01-02")]
+			fill, text, title := dayStyle(day)
+			if fill != colorBackground {
+				shapes = append(shapes, shape{X: x, Y: y, W: cell, H: cell, Fill: fill, Title: title})
+			}
+			shapes = append(shapes, shape{
+				X: x + cell/2, Y: y + cell/2 + daySize/2 - 1,
+				Text: fmt.Sprint(date.Day()), Size: daySize, Fill: text, Anchor: anchorMiddle, Title: title,
+			})
+		}
+	}
+
+	// Legend
+	x := margin
+	y := Height - margin - cell/2
+	for _, item := range []struct {
+		label string
+		fill  color.RGBA
+	}{
+		{"Holiday", colorHoliday},
+		{"Vacation", colorManual},
+		{"Optimized vacation", colorOptimized},
+		{"Weekend", colorWeekend},
+	} {
+		shapes = append(shapes, shape{X: x, Y: y - 12, W: 14, H: 14, Fill: item.fill})
+		shapes = append(shapes, shape{X: x + 20, Y: y, Text: item.label, Size: monthSize - 1, Fill: colorText})
+		x += 20 + 7*len(item.label) + 24
+	}
+
+	return shapes
+}
+
+// dayStyle returns the cell fill, text color and tooltip of a day
+func dayStyle(day models.CalendarDay) (fill, text color.RGBA, title string) {
+	switch {
+	case day.IsHoliday:
+		return colorHoliday, colorBackground, day.HolidayName
+	case day.IsManual:
+		return colorManual, colorBackground, "Vacation"
+	case day.IsOptimal:
+		return colorOptimized, colorBackground, "Optimized vacation"
+	case day.IsWeekend:
+		return colorWeekend, colorMuted, ""
+	default:
+		return colorBackground, colorText, ""
+	}
+}
@@ -0,0 +1,56 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// SVG writes the calendar as an SVG image
+func SVG(w io.Writer, cal models.CalendarResponse) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Go, -apple-system, 'Segoe UI', Roboto, sans-serif">`+"\n", Width, Height, Width, Height)
+	fmt.Fprintf(bw, "<title>Vacation plan %d</title>\n", cal.Year)
+	for _, s := range layout(cal) {
+		if s.Text == "" {
+			fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" rx="%d" fill="%s">%s</rect>`+"\n", s.X, s.Y, s.W, s.H, radius(s), hex(s.Fill), svgTitle(s.Title))
+			continue
+		}
+
+		attrs := ""
+		if s.Anchor == anchorMiddle {
+			attrs += ` text-anchor="middle"`
+		}
+		if s.Bold {
+			attrs += ` font-weight="bold"`
+		}
+		fmt.Fprintf(bw, `<text x="%d" y="%d" font-size="%d" fill="%s"%s>%s%s</text>`+"\n", s.X, s.Y, s.Size, hex(s.Fill), attrs, html.EscapeString(s.Text), svgTitle(s.Title))
+	}
+	bw.WriteString("</svg>\n")
+
+	return bw.Flush()
+}
+
+// radius rounds the corners of day cells but not of the background
+func radius(s shape) int {
+	if s.W > cell {
+		return 0
+	}
+	return 4
+}
+
+func svgTitle(title string) string {
+	if title == "" {
+		return ""
+	}
+	return "<title>" + html.EscapeString(title) + "</title>"
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,126 @@
+package rpc
+
+import (
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	pb "github.com/bruno.lopes/calendar/backend/proto/vacationplanner/v1"
+)
+
+func calendarToProto(c models.CalendarResponse) *pb.Calendar {
+	calendar := &pb.Calendar{
+		Year:            int32(c.Year),
+		Config:          configToProto(c.Config),
+		VacationBlocks:  blocksToProto(c.VacationBlocks),
+		ManualVacations: vacationsToProto(c.ManualVacations),
+		Summary:         summaryToProto(c.Summary),
+	}
+	for _, day := range c.Days {
+		calendar.Days = append(calendar.Days, &pb.CalendarDay{
+			Date:        day.Date,
+			DayOfWeek:   day.DayOfWeek,
+			IsWeekend:   day.IsWeekend,
+			IsHoliday:   day.IsHoliday,
+			HolidayName: day.HolidayName,
+			IsVacation:  day.IsVacation,
+			IsManual:    day.IsManual,
+			IsOptimal:   day.IsOptimal,
+			BlockId:     int32(day.BlockID),
+		})
+	}
+	for _, holiday := range c.Holidays {
+		calendar.Holidays = append(calendar.Holidays, &pb.Holiday{
+			Date:        holiday.Date,
+			Name:        holiday.Name,
+			Type:        holiday.Type,
+			ObservedFor: holiday.ObservedFor,
+		})
+	}
+	for _, v := range c.OptimalVacations {
+		calendar.OptimalVacations = append(calendar.OptimalVacations, &pb.OptimalVacation{
+			Date:            v.Date,
+			BlockId:         int32(v.BlockID),
+			ConsecutiveDays: int32(v.ConsecutiveDays),
+		})
+	}
+	return calendar
+}
+
+func configToProto(c models.YearConfig) *pb.YearConfig {
+	config := &pb.YearConfig{
+		Year:                 int32(c.Year),
+		VacationDays:         int32(c.VacationDays),
+		ReservedDays:         int32(c.ReservedDays),
+		OptimizationStrategy: c.OptimizationStrategy,
+		WorkWeek:             c.WorkWeek,
+		OptimizerNotes:       c.OptimizerNotes,
+		AlignSchoolBreaks:    c.AlignSchoolBreaks,
+		AccountingMode:       c.AccountingMode,
+		VacationHours:        c.VacationHours,
+		WorkingHours:         c.WorkingHours,
+	}
+	for _, change := range c.WorkWeekChanges {
+		config.WorkWeekChanges = append(config.WorkWeekChanges, &pb.WorkWeekChange{
+			EffectiveFrom: change.EffectiveFrom,
+			WorkWeek:      change.WorkWeek,
+		})
+	}
+	return config
+}
+
+func summaryToProto(s models.CalendarSummary) *pb.CalendarSummary {
+	summary := &pb.CalendarSummary{
+		TotalVacationDays:      int32(s.TotalVacationDays),
+		CompensationDays:       int32(s.CompensationDays),
+		UsedVacationDays:       int32(s.UsedVacationDays),
+		RemainingVacationDays:  int32(s.RemainingVacationDays),
+		TotalHolidays:          int32(s.TotalHolidays),
+		LongestVacationBlock:   int32(s.LongestVacationBlock),
+		TotalDaysOff:           int32(s.TotalDaysOff),
+		Efficiency:             s.Efficiency,
+		TotalVacationHours:     s.TotalVacationHours,
+		UsedVacationHours:      s.UsedVacationHours,
+		RemainingVacationHours: s.RemainingVacationHours,
+	}
+	for _, q := range s.QuarterDistribution {
+		summary.QuarterDistribution = append(summary.QuarterDistribution, &pb.QuarterSummary{
+			Quarter:      int32(q.Quarter),
+			VacationDays: int32(q.VacationDays),
+			DaysOff:      int32(q.DaysOff),
+			Efficiency:   q.Efficiency,
+		})
+	}
+	return summary
+}
+
+func blocksToProto(blocks []models.VacationBlock) []*pb.VacationBlock {
+	var result []*pb.VacationBlock
+	for _, b := range blocks {
+		result = append(result, &pb.VacationBlock{
+			StartDate:        b.StartDate,
+			EndDate:          b.EndDate,
+			TotalDays:        int32(b.TotalDays),
+			VacationDaysUsed: int32(b.VacationDaysUsed),
+			Dates:            b.Dates,
+			Holidays:         b.Holidays,
+			Weekends:         b.Weekends,
+			Efficiency:       b.Efficiency,
+			Source:           b.Source,
+		})
+	}
+	return result
+}
+
+func vacationsToProto(vacations []models.VacationDay) []*pb.VacationDay {
+	var result []*pb.VacationDay
+	for _, v := range vacations {
+		result = append(result, &pb.VacationDay{Date: v.Date, Note: v.Note, CreatedAt: v.CreatedAt})
+	}
+	return result
+}
+
+func resultsToProto(results []models.BulkItemResult) []*pb.ItemResult {
+	var result []*pb.ItemResult
+	for _, r := range results {
+		result = append(result, &pb.ItemResult{Date: r.Date, Action: r.Action, Status: r.Status, Error: r.Error})
+	}
+	return result
+}
@@ -0,0 +1,209 @@
+// Package rpc serves the calendar, vacation and optimizer API over gRPC. It
+// calls the same handler methods as the REST API, so both share the store,
+// edit locks and calendar events.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/bruno.lopes/calendar/backend/internal/api/handlers"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	pb "github.com/bruno.lopes/calendar/backend/proto/vacationplanner/v1"
+)
+
+// clientIDKey is the metadata key identifying the client for edit locks, the
+// gRPC counterpart of the X-Client-ID header
+const clientIDKey = "x-client-id"
+
+// authorizationKey carries the access token as "Bearer <token>", like the
+// REST API's Authorization header
+const authorizationKey = "authorization"
+
+// readMethods are the RPCs open to viewers; the others need the member role
+var readMethods = map[string]bool{
+	"GetCalendar":   true,
+	"ListVacations": true,
+}
+
+// Service implements the VacationPlannerService
+type Service struct {
+	pb.UnimplementedVacationPlannerServiceServer
+
+	h *handlers.Handler
+}
+
+// NewServer returns a gRPC server with the vacation planner service and
+// server reflection registered
+func NewServer(h *handlers.Handler) *grpc.Server {
+	srv := grpc.NewServer(grpc.UnaryInterceptor(authorize(h)))
+	pb.RegisterVacationPlannerServiceServer(srv, &Service{h: h})
+	reflection.Register(srv)
+	return srv
+}
+
+// GetCalendar returns the full calendar for a year
+func (s *Service) GetCalendar(ctx context.Context, req *pb.GetCalendarRequest) (*pb.GetCalendarResponse, error) {
+	calendar, err := s.h.Calendar(ctx, int(req.GetYear()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.GetCalendarResponse{Calendar: calendarToProto(calendar)}, nil
+}
+
+// ListVacations returns the manual vacation days of a year
+func (s *Service) ListVacations(ctx context.Context, req *pb.ListVacationsRequest) (*pb.ListVacationsResponse, error) {
+	vacations, err := s.h.Vacations(ctx, int(req.GetYear()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.ListVacationsResponse{Vacations: vacationsToProto(vacations)}, nil
+}
+
+// AddVacation adds a manual vacation day
+func (s *Service) AddVacation(ctx context.Context, req *pb.AddVacationRequest) (*pb.AddVacationResponse, error) {
+	year := int(req.GetYear())
+	if err := s.checkEditLock(ctx, year); err != nil {
+		return nil, err
+	}
+	if err := s.h.AddVacationDay(ctx, year, req.GetDate(), req.GetNote()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.AddVacationResponse{}, nil
+}
+
+// RemoveVacation removes a manual vacation day
+func (s *Service) RemoveVacation(ctx context.Context, req *pb.RemoveVacationRequest) (*pb.RemoveVacationResponse, error) {
+	year := int(req.GetYear())
+	if err := s.checkEditLock(ctx, year); err != nil {
+		return nil, err
+	}
+	if err := s.h.RemoveVacationDay(ctx, year, req.GetDate()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.RemoveVacationResponse{}, nil
+}
+
+// UpdateVacations adds and removes manual vacation days in one transaction
+func (s *Service) UpdateVacations(ctx context.Context, req *pb.UpdateVacationsRequest) (*pb.UpdateVacationsResponse, error) {
+	year := int(req.GetYear())
+	if err := s.checkEditLock(ctx, year); err != nil {
+		return nil, err
+	}
+	results, err := s.h.UpdateVacationDays(ctx, year, req.GetAdd(), req.GetRemove())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.UpdateVacationsResponse{Results: resultsToProto(results)}, nil
+}
+
+// Optimize runs the year's optimization strategy and stores the result
+func (s *Service) Optimize(ctx context.Context, req *pb.OptimizeRequest) (*pb.OptimizeResponse, error) {
+	year := int(req.GetYear())
+	if err := s.checkEditLock(ctx, year); err != nil {
+		return nil, err
+	}
+	blocks, results, err := s.h.Optimize(ctx, year)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.OptimizeResponse{Blocks: blocksToProto(blocks), Results: resultsToProto(results)}, nil
+}
+
+// ClearOptimizedVacations removes the optimized vacation days of a year
+func (s *Service) ClearOptimizedVacations(ctx context.Context, req *pb.ClearOptimizedVacationsRequest) (*pb.ClearOptimizedVacationsResponse, error) {
+	year := int(req.GetYear())
+	if err := s.checkEditLock(ctx, year); err != nil {
+		return nil, err
+	}
+	if err := s.h.ClearOptimized(ctx, year); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.ClearOptimizedVacationsResponse{}, nil
+}
+
+// authorize checks the access token of each call with the same roles as the
+// REST API, while access control is on
+func authorize(h *handlers.Handler) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		role := models.RoleMember
+		if readMethods[path.Base(info.FullMethod)] {
+			role = models.RoleViewer
+		}
+
+		var token string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(authorizationKey); len(values) > 0 && len(values[0]) > 7 && strings.EqualFold(values[0][:7], "Bearer ") {
+				token = strings.TrimSpace(values[0][7:])
+			}
+		}
+
+		var client string
+		if p, ok := peer.FromContext(ctx); ok {
+			client = p.Addr.String()
+			if host, _, err := net.SplitHostPort(client); err == nil {
+				client = host
+			}
+		}
+
+		if _, _, err := h.Authorize(ctx, client, token, role); err != nil {
+			return nil, toStatus(err)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// checkEditLock rejects changes while another client holds the year's edit
+// lock, like the REST API does
+func (s *Service) checkEditLock(ctx context.Context, year int) error {
+	var clientID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(clientIDKey); len(values) > 0 {
+			clientID = values[0]
+		}
+	}
+
+	if lock, ok := s.h.CanEdit(year, clientID); !ok {
+		return status.Errorf(codes.FailedPrecondition, "Calendar is being edited by someone else (%s)", holderOf(lock.HolderName))
+	}
+	return nil
+}
+
+func holderOf(name string) string {
+	if name == "" {
+		return "another client"
+	}
+	return name
+}
+
+// toStatus maps handler errors to gRPC status codes
+func toStatus(err error) error {
+	switch {
+	case handlers.IsInvalidInput(err):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, handlers.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, handlers.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, handlers.ErrTooManyAttempts):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, handlers.ErrDateLocked), errors.Is(err, handlers.ErrPastDate):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
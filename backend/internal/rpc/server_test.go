@@ -0,0 +1,146 @@
+package rpc_test
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/bruno.lopes/calendar/backend/internal/api/handlers"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/rpc"
+	"github.com/bruno.lopes/calendar/backend/internal/sandbox"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+	"github.com/bruno.lopes/calendar/backend/internal/testutil"
+	pb "github.com/bruno.lopes/calendar/backend/proto/vacationplanner/v1"
+)
+
+// newClient serves the gRPC API over an in-memory connection
+func newClient(t *testing.T) pb.VacationPlannerServiceClient {
+	t.Helper()
+	sandbox.Enable()
+	return serve(t, testutil.NewDB(t))
+}
+
+// serve serves the gRPC API on a database over an in-memory connection
+func serve(t *testing.T, db *sql.DB) pb.VacationPlannerServiceClient {
+	t.Helper()
+
+	h := handlers.NewHandler(db)
+	srv := rpc.NewServer(h)
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		srv.Stop()
+		h.Close()
+	})
+	return pb.NewVacationPlannerServiceClient(conn)
+}
+
+func TestAddVacation(t *testing.T) {
+	client := newClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		year     int32
+		date     string
+		wantCode codes.Code
+	}{
+		{"work day", 2025, "2025-03-04", codes.OK},
+		{"date in another year", 2025, "2026-03-04", codes.InvalidArgument},
+		{"national holiday", 2025, "2025-12-25", codes.InvalidArgument},
+		{"year out of range", 1800, "1800-03-04", codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.AddVacation(ctx, &pb.AddVacationRequest{Year: tt.year, Date: tt.date})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("AddVacation(%s): code %s, want %s (%v)", tt.date, code, tt.wantCode, err)
+			}
+		})
+	}
+
+	calendar, err := client.GetCalendar(ctx, &pb.GetCalendarRequest{Year: 2025})
+	if err != nil {
+		t.Fatalf("GetCalendar: %v", err)
+	}
+	if got := calendar.GetCalendar().GetSummary().GetUsedVacationDays(); got != 1 {
+		t.Errorf("used vacation days = %d, want 1", got)
+	}
+}
+
+func TestOptimize(t *testing.T) {
+	client := newClient(t)
+	ctx := context.Background()
+
+	update, err := client.UpdateVacations(ctx, &pb.UpdateVacationsRequest{Year: 2025, Add: []string{"2025-08-04", "2025-08-05"}})
+	if err != nil {
+		t.Fatalf("UpdateVacations: %v", err)
+	}
+	if len(update.GetResults()) != 2 {
+		t.Errorf("UpdateVacations returned %d results, want 2", len(update.GetResults()))
+	}
+
+	resp, err := client.Optimize(ctx, &pb.OptimizeRequest{Year: 2025})
+	if err != nil {
+		t.Fatalf("Optimize: %v", err)
+	}
+	if len(resp.GetBlocks()) == 0 {
+		t.Fatal("Optimize returned no blocks")
+	}
+
+	calendar, err := client.GetCalendar(ctx, &pb.GetCalendarRequest{Year: 2025})
+	if err != nil {
+		t.Fatalf("GetCalendar: %v", err)
+	}
+	for _, v := range calendar.GetCalendar().GetOptimalVacations() {
+		if v.GetDate() == "2025-08-04" || v.GetDate() == "2025-08-05" {
+			t.Errorf("optimized day %s is already a manual vacation day", v.GetDate())
+		}
+	}
+
+	// Nobody holds the edit lock, so any client may change the year
+	if _, err := client.ClearOptimizedVacations(metadata.AppendToOutgoingContext(ctx, "x-client-id", "script"), &pb.ClearOptimizedVacationsRequest{Year: 2025}); err != nil {
+		t.Errorf("ClearOptimizedVacations: %v", err)
+	}
+}
+
+func TestAccessControl(t *testing.T) {
+	sandbox.Enable()
+	db := testutil.NewDB(t)
+	viewer, err := store.New(db).Tokens.Create(context.Background(), "viewer", models.RoleViewer)
+	if err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+	client := serve(t, db)
+
+	if _, err := client.GetCalendar(context.Background(), &pb.GetCalendarRequest{Year: 2025}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("GetCalendar without a token: %v, want Unauthenticated", err)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+viewer.Token)
+	if _, err := client.GetCalendar(ctx, &pb.GetCalendarRequest{Year: 2025}); err != nil {
+		t.Errorf("GetCalendar as viewer: %v", err)
+	}
+	if _, err := client.AddVacation(ctx, &pb.AddVacationRequest{Year: 2025, Date: "2025-03-04"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("AddVacation as viewer: %v, want PermissionDenied", err)
+	}
+}
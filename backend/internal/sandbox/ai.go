@@ -0,0 +1,235 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+var (
+	holidayLineRegex = regexp.MustCompile(`(?m)^- (\d{4}-\d{2}-\d{2}) \(\w+\): `)
+	exactCountRegex  = regexp.MustCompile(`Return EXACTLY (\d+) dates`)
+	scheduledRegex   = regexp.MustCompile(`(?m)^Already scheduled vacation days.*: (.*)$`)
+	bridgeLineRegex  = regexp.MustCompile(`(?m)^- Take .*$`)
+	dateOnlyRegex    = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+	baselineRegex    = regexp.MustCompile(`(?m)^BASELINE PLAN.*: (.*)$`)
+	awayRegex        = regexp.MustCompile(`(?m)^- Away from (\S+) to (\S+), back at work on (\S+)$`)
+	contactRegex     = regexp.MustCompile(`(?m)^- Contact while away: (.*)$`)
+)
+
+// FakeAI answers chat completion requests deterministically without calling
+// any provider. Responses are derived from the prompt so the UI flows
+// (chat, smart optimization, suggestions) behave like the real thing.
+type FakeAI struct{}
+
+// NewFakeAI creates a fake AI client
+func NewFakeAI() *FakeAI {
+	return &FakeAI{}
+}
+
+// CreateChatCompletion returns a canned response for the request
+func (f *FakeAI) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	prompt := lastUserMessage(request.Messages)
+
+	// Corrections of the smart optimizer are answered from its first prompt,
+	// which has the holidays
+	var content string
+	switch {
+	case len(request.Messages) > 0 && strings.Contains(request.Messages[0].Content, "BASELINE PLAN"):
+		content = baselineResponse(request.Messages[0].Content)
+	case len(request.Messages) > 0 && strings.Contains(request.Messages[0].Content, "JSON object of vacation days"):
+		content = bridgeDatesResponse(request.Messages[0].Content, request.ResponseFormat != nil)
+	case strings.Contains(prompt, "out-of-office auto-reply"):
+		content = outOfOfficeResponse(prompt)
+	case strings.Contains(prompt, "PRE-CALCULATED BRIDGE OPPORTUNITIES"):
+		content = suggestionsResponse(prompt)
+	default:
+		content = chatResponse(prompt)
+	}
+
+	return openai.ChatCompletionResponse{
+		ID:      "sandbox",
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   request.Model,
+		Choices: []openai.ChatCompletionChoice{
+			{
+				Message: openai.ChatCompletionMessage{
+					Role:    openai.ChatMessageRoleAssistant,
+					Content: content,
+				},
+				FinishReason: openai.FinishReasonStop,
+			},
+		},
+	}, nil
+}
+
+// Models returns the model catalog exposed in sandbox mode
+func Models() []models.AIModel {
+	tools := false
+	return []models.AIModel{
+		{ID: "sandbox/fake-model", Name: "Sandbox Fake Model", Publisher: "Sandbox", ContextLength: 128000, MaxOutputTokens: 4096, Tier: "low", ToolCalling: &tools, Suitable: true},
+	}
+}
+
+func lastUserMessage(messages []openai.ChatCompletionMessage) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == openai.ChatMessageRoleUser {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+// bridgeDatesResponse picks the classic bridge days (Monday before a Tuesday
+// holiday, Friday after a Thursday holiday, ...) from the holidays in the
+// prompt, as the structured days object or a bare array of dates
+func bridgeDatesResponse(prompt string, structured bool) string {
+	limit := -1
+	if match := exactCountRegex.FindStringSubmatch(prompt); match != nil {
+		limit, _ = strconv.Atoi(match[1])
+	}
+
+	excluded := make(map[string]bool)
+	if match := scheduledRegex.FindStringSubmatch(prompt); match != nil {
+		for _, d := range dateOnlyRegex.FindAllString(match[1], -1) {
+			excluded[d] = true
+		}
+	}
+
+	for _, match := range holidayLineRegex.FindAllStringSubmatch(prompt, -1) {
+		excluded[match[1]] = true
+	}
+
+	var dates []string
+	rationales := make(map[string]string)
+	for _, match := range holidayLineRegex.FindAllStringSubmatch(prompt, -1) {
+		holiday, err := time.Parse("2006-01-02", match[1])
+		if err != nil {
+			continue
+		}
+
+		var bridge time.Time
+		switch holiday.Weekday() {
+		case time.Tuesday:
+			bridge = holiday.AddDate(0, 0, -1)
+		case time.Thursday:
+			bridge = holiday.AddDate(0, 0, 1)
+		default:
+			continue
+		}
+
+		dateStr := bridge.Format("2006-01-02")
+		if !excluded[dateStr] {
+			excluded[dateStr] = true
+			dates = append(dates, dateStr)
+			rationales[dateStr] = fmt.Sprintf("Bridges the %s holiday on %s to the weekend", holiday.Weekday(), match[1])
+		}
+	}
+
+	sort.Strings(dates)
+	if limit >= 0 && len(dates) > limit {
+		dates = dates[:limit]
+	}
+	if dates == nil {
+		dates = []string{}
+	}
+
+	if !structured {
+		result, _ := json.Marshal(dates)
+		return string(result)
+	}
+
+	type day struct {
+		Date      string `json:"date"`
+		Rationale string `json:"rationale"`
+	}
+	days := make([]day, 0, len(dates))
+	for _, date := range dates {
+		days = append(days, day{Date: date, Rationale: rationales[date]})
+	}
+	result, _ := json.Marshal(map[string][]day{"days": days})
+	return string(result)
+}
+
+// baselineResponse keeps the days of the baseline plan in the prompt, as a
+// hybrid refinement that found nothing to change
+func baselineResponse(prompt string) string {
+	type day struct {
+		Date      string `json:"date"`
+		Rationale string `json:"rationale"`
+	}
+	days := []day{}
+	if match := baselineRegex.FindStringSubmatch(prompt); match != nil {
+		for _, date := range dateOnlyRegex.FindAllString(match[1], -1) {
+			days = append(days, day{Date: date, Rationale: "Kept from the baseline plan"})
+		}
+	}
+	result, _ := json.Marshal(map[string][]day{"days": days})
+	return string(result)
+}
+
+// outOfOfficeResponse fills a fixed out-of-office draft with the dates and
+// contact in the prompt, in Portuguese when it is asked for
+func outOfOfficeResponse(prompt string) string {
+	var from, to, back string
+	if match := awayRegex.FindStringSubmatch(prompt); match != nil {
+		from, to, back = match[1], match[2], match[3]
+	}
+	contact := "my team"
+	if match := contactRegex.FindStringSubmatch(prompt); match != nil {
+		contact = match[1]
+	}
+
+	draft := map[string]string{
+		"subject":    fmt.Sprintf("Out of office until %s", back),
+		"auto_reply": fmt.Sprintf("Thank you for your message. I am on vacation from %s to %s and back on %s. Meanwhile, please contact %s.", from, to, back, contact),
+		"handover":   fmt.Sprintf("Sandbox handover: %s covers while I am away from %s to %s.", contact, from, to),
+	}
+	if strings.Contains(prompt, "Portuguese (Portugal)") {
+		if contact == "my team" {
+			contact = "a minha equipa"
+		}
+		draft = map[string]string{
+			"subject":    fmt.Sprintf("Ausente até %s", back),
+			"auto_reply": fmt.Sprintf("Obrigado pela sua mensagem. Estou de férias de %s a %s e regresso a %s. Entretanto, contacte %s.", from, to, back, contact),
+			"handover":   fmt.Sprintf("Passagem de pasta de teste: %s assegura o trabalho de %s a %s.", contact, from, to),
+		}
+	}
+	result, _ := json.Marshal(draft)
+	return string(result)
+}
+
+func suggestionsResponse(prompt string) string {
+	var sb strings.Builder
+	sb.WriteString("Sandbox suggestion: your vacation days are placed reasonably. ")
+	sb.WriteString("These bridge opportunities would give you longer breaks:\n")
+
+	lines := bridgeLineRegex.FindAllString(prompt, 3)
+	if len(lines) == 0 {
+		sb.WriteString("- No bridge opportunities found for the rest of the year.\n")
+	}
+	for _, line := range lines {
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func chatResponse(message string) string {
+	lower := strings.ToLower(message)
+	if strings.Contains(lower, "optimi") {
+		return "Sure, I'll optimize your vacation days now!\n{\"action\": \"optimize\"}"
+	}
+	return fmt.Sprintf("Sandbox assistant here. You said: %q. This is a canned response, no AI provider was called.", message)
+}
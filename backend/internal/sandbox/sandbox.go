@@ -0,0 +1,24 @@
+// Package sandbox provides a developer mode that replaces external AI and
+// holiday providers with deterministic fakes, so the app can run offline.
+package sandbox
+
+import "sync"
+
+var (
+	enabled    bool
+	enabledMux sync.RWMutex
+)
+
+// Enable turns sandbox mode on for the whole process
+func Enable() {
+	enabledMux.Lock()
+	defer enabledMux.Unlock()
+	enabled = true
+}
+
+// Enabled reports whether sandbox mode is on
+func Enabled() bool {
+	enabledMux.RLock()
+	defer enabledMux.RUnlock()
+	return enabled
+}
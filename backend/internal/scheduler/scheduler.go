@@ -0,0 +1,213 @@
+// Package scheduler runs the periodic background jobs (holiday refreshes,
+// cache pruning, digest notifications) on cron schedules and keeps the
+// outcome of each job's last run.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	// ErrUnknownJob is returned when no job has the given name
+	ErrUnknownJob = errors.New("unknown job")
+	// ErrJobRunning is returned when a job is started while it is running
+	ErrJobRunning = errors.New("job is already running")
+)
+
+// Job is a named task run on a cron schedule
+type Job struct {
+	Name        string
+	Description string
+	Schedule    string // Standard 5-field cron expression or descriptor such as "@hourly"
+	Run         func(ctx context.Context) error
+}
+
+// Status describes a job and its last run
+type Status struct {
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	Schedule       string     `json:"schedule"`
+	NextRun        *time.Time `json:"next_run,omitempty"`
+	LastRun        *time.Time `json:"last_run,omitempty"`
+	LastDurationMs int64      `json:"last_duration_ms"`
+	LastError      string     `json:"last_error,omitempty"`
+	Running        bool       `json:"running"`
+	Runs           int        `json:"runs"`
+	Failures       int        `json:"failures"`
+}
+
+type entry struct {
+	job    Job
+	id     cron.EntryID
+	status Status
+}
+
+// Scheduler runs jobs in the background. A job never overlaps itself: a run
+// that comes due while the previous one is still going is skipped.
+type Scheduler struct {
+	cron    *cron.Cron
+	ctx     context.Context
+	cancel  context.CancelFunc
+	entries map[string]*entry
+	order   []string
+	mux     sync.Mutex
+}
+
+// New creates a scheduler using the local time zone
+func New() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		cron:    cron.New(),
+		ctx:     ctx,
+		cancel:  cancel,
+		entries: make(map[string]*entry),
+	}
+}
+
+// Add registers a job. Jobs added after Start are scheduled right away.
+func (s *Scheduler) Add(job Job) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if _, exists := s.entries[job.Name]; exists {
+		return fmt.Errorf("job %q is already registered", job.Name)
+	}
+
+	e := &entry{
+		job: job,
+		status: Status{
+			Name:        job.Name,
+			Description: job.Description,
+			Schedule:    job.Schedule,
+		},
+	}
+	id, err := s.cron.AddFunc(job.Schedule, func() { s.run(e) })
+	if err != nil {
+		return fmt.Errorf("job %q: invalid schedule: %w", job.Name, err)
+	}
+	e.id = id
+
+	s.entries[job.Name] = e
+	s.order = append(s.order, job.Name)
+	return nil
+}
+
+// Reschedule changes the schedule of a job. A run in progress is not
+// interrupted.
+func (s *Scheduler) Reschedule(name, schedule string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	e, exists := s.entries[name]
+	if !exists {
+		return ErrUnknownJob
+	}
+	if e.job.Schedule == schedule {
+		return nil
+	}
+
+	id, err := s.cron.AddFunc(schedule, func() { s.run(e) })
+	if err != nil {
+		return fmt.Errorf("job %q: invalid schedule: %w", name, err)
+	}
+	s.cron.Remove(e.id)
+	e.id = id
+	e.job.Schedule = schedule
+	e.status.Schedule = schedule
+	return nil
+}
+
+// Start runs the scheduler in the background
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop ends the scheduler and cancels running jobs
+func (s *Scheduler) Stop() {
+	s.cancel()
+	<-s.cron.Stop().Done()
+}
+
+// Jobs returns the status of every job, in the order they were added
+func (s *Scheduler) Jobs() []Status {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	statuses := make([]Status, 0, len(s.order))
+	for _, name := range s.order {
+		e := s.entries[name]
+		status := e.status
+		if next := s.cron.Entry(e.id).Next; !next.IsZero() {
+			status.NextRun = &next
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// RunNow starts a job in the background outside its schedule
+func (s *Scheduler) RunNow(name string) error {
+	s.mux.Lock()
+	e, exists := s.entries[name]
+	running := exists && e.status.Running
+	s.mux.Unlock()
+
+	if !exists {
+		return ErrUnknownJob
+	}
+	if running {
+		return ErrJobRunning
+	}
+
+	go s.run(e)
+	return nil
+}
+
+// run executes a job unless it is already running and records the outcome
+func (s *Scheduler) run(e *entry) {
+	s.mux.Lock()
+	if e.status.Running {
+		s.mux.Unlock()
+		log.Printf("Job %s is still running, skipping this run", e.job.Name)
+		return
+	}
+	e.status.Running = true
+	s.mux.Unlock()
+
+	started := time.Now()
+	err := s.safeRun(e.job)
+	duration := time.Since(started)
+
+	s.mux.Lock()
+	e.status.Running = false
+	e.status.LastRun = &started
+	e.status.LastDurationMs = duration.Milliseconds()
+	e.status.Runs++
+	e.status.LastError = ""
+	if err != nil {
+		e.status.LastError = err.Error()
+		e.status.Failures++
+	}
+	s.mux.Unlock()
+
+	if err != nil {
+		log.Printf("Job %s failed after %s: %v", e.job.Name, duration.Round(time.Millisecond), err)
+	}
+}
+
+// safeRun turns a panicking job into a failed run
+func (s *Scheduler) safeRun(job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(s.ctx)
+}
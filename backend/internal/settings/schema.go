@@ -0,0 +1,353 @@
+// Package settings describes the application settings stored as key/value
+// pairs: their types, allowed values and defaults, and a typed view over the
+// stored values.
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/bruno.lopes/calendar/backend/internal/backup"
+	"github.com/bruno.lopes/calendar/backend/internal/flights"
+	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/hr"
+	"github.com/bruno.lopes/calendar/backend/internal/milestones"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/oncall"
+	"github.com/bruno.lopes/calendar/backend/internal/optimizer"
+)
+
+// Setting value types
+const (
+	TypeString   = "string"
+	TypeInteger  = "integer"
+	TypeBoolean  = "boolean"
+	TypeEnum     = "enum"
+	TypeDate     = "date"      // YYYY-MM-DD
+	TypeMonthDay = "month_day" // MM-DD
+	TypeWorkWeek = "work_week" // JSON array of weekday names
+	TypeTimezone = "timezone"  // IANA time zone name, such as Europe/Lisbon
+	TypeAirport  = "airport"   // Three-letter IATA airport or city code
+	TypeCron     = "cron"      // Standard 5-field cron expression or descriptor such as "@daily"
+	TypeModels   = "models"    // Comma or newline separated AI models, each optionally prefixed by its provider as in "openai:gpt-4o"
+	TypeRoles    = "roles"     // Comma or newline separated group=role pairs, such as "planner-admins=admin"
+)
+
+// Setting groups, used to lay out the settings form
+const (
+	GroupAI            = "ai"
+	GroupGeneral       = "general"
+	GroupHolidays      = "holidays"
+	GroupWebhooks      = "webhooks"
+	GroupTeams         = "teams"
+	GroupEmail         = "email"
+	GroupPush          = "push"
+	GroupNotifications = "notifications"
+	GroupTravel        = "travel"
+	GroupSheets        = "sheets"
+	GroupHR            = "hr"
+	GroupOnCall        = "oncall"
+	GroupMilestones    = "milestones"
+	GroupBackups       = "backups"
+	GroupSecurity      = "security"
+	GroupSSO           = "sso"
+)
+
+// Definition describes one setting
+type Definition struct {
+	Key         string   `json:"key"`
+	Type        string   `json:"type"`
+	Group       string   `json:"group"`
+	Description string   `json:"description"`
+	Default     string   `json:"default"`
+	Options     []string `json:"options,omitempty"` // Allowed values of an enum
+	Min         *int     `json:"min,omitempty"`
+	Max         *int     `json:"max,omitempty"`
+	Secret      bool     `json:"secret,omitempty"`    // Keys and passwords the UI should mask
+	ReadOnly    bool     `json:"read_only,omitempty"` // Managed by the server
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// Schema lists every setting, in the order they are shown
+var Schema = []Definition{
+	{Key: "ai_provider", Type: TypeEnum, Group: GroupAI, Description: "AI provider", Default: "github", Options: []string{"github", "openai"}},
+	{Key: "openai_api_key", Type: TypeString, Group: GroupAI, Description: "API key for the AI provider", Secret: true},
+	{Key: "ai_model", Type: TypeString, Group: GroupAI, Description: "AI model to use", Default: "openai/gpt-4o-mini"},
+	{Key: "ai_fallback_models", Type: TypeModels, Group: GroupAI, Description: "Models tried in order when the AI model fails, such as \"openai/gpt-4.1-mini, openai:gpt-4o\" (without a provider prefix, the AI provider's)"},
+	{Key: "ai_fallback_api_key", Type: TypeString, Group: GroupAI, Description: "API key for fallback models of the other provider", Secret: true},
+	{Key: "ai_correction_rounds", Type: TypeInteger, Group: GroupAI, Description: "Times the smart optimizer sends invalid days back to the AI model to correct (0 keeps the first answer)", Default: "2", Min: intPtr(0), Max: intPtr(5)},
+
+	{Key: "backend_port", Type: TypeInteger, Group: GroupGeneral, Description: "Backend server port", Default: "8080", Min: intPtr(1), Max: intPtr(65535)},
+	{Key: "frontend_port", Type: TypeInteger, Group: GroupGeneral, Description: "Frontend dev server port", Default: "5173", Min: intPtr(1), Max: intPtr(65535)},
+	{Key: "default_work_week", Type: TypeWorkWeek, Group: GroupGeneral, Description: "Work week for new years", Default: `["monday","tuesday","wednesday","thursday","friday"]`},
+	{Key: "default_vacation_days", Type: TypeInteger, Group: GroupGeneral, Description: "Vacation days for new years", Default: "22", Min: intPtr(0), Max: intPtr(366)},
+	{Key: "default_optimization_strategy", Type: TypeEnum, Group: GroupGeneral, Description: "Optimization strategy for new years", Default: models.StrategyBalanced,
+		Options: append(optimizer.StrategyIDs(), models.StrategySmart, models.StrategyHybrid)},
+	{Key: "optimizer_time_budget_ms", Type: TypeInteger, Group: GroupGeneral, Description: "Milliseconds the local search strategy may spend improving a plan", Default: "200", Min: intPtr(10), Max: intPtr(10000)},
+	{Key: "timezone", Type: TypeTimezone, Group: GroupGeneral, Description: "Time zone that decides the current day (IANA name such as Europe/Lisbon, empty for the server time zone)"},
+	{Key: "employment_start_date", Type: TypeDate, Group: GroupGeneral, Description: "Start date used by the seniority rules"},
+	{Key: "birthday", Type: TypeString, Group: GroupGeneral, Description: "Birthday as MM-DD (or a full YYYY-MM-DD date)"},
+	{Key: "past_edit_protection", Type: TypeBoolean, Group: GroupGeneral, Description: "Reject changes to vacation days already in the past", Default: "true"},
+	{Key: "past_edit_grace_days", Type: TypeInteger, Group: GroupGeneral, Description: "Days back that can still be changed when past days are protected", Default: "7", Min: intPtr(0), Max: intPtr(365)},
+	{Key: "birthday_day_off", Type: TypeEnum, Group: GroupGeneral, Description: "Birthday day off rule", Default: holidays.BirthdayNone,
+		Options: []string{holidays.BirthdayNone, holidays.BirthdayDay, holidays.BirthdayWeek}},
+
+	{Key: "work_city", Type: TypeString, Group: GroupHolidays, Description: "City for municipal holidays"},
+	{Key: "school_district", Type: TypeEnum, Group: GroupHolidays, Description: "District for the school holiday calendar", Options: holidays.GetSchoolDistricts()},
+	{Key: "holiday_substitution", Type: TypeEnum, Group: GroupHolidays, Description: "Policy for holidays on weekends", Default: holidays.SubstitutionNone,
+		Options: []string{holidays.SubstitutionNone, holidays.SubstitutionNextMonday, holidays.SubstitutionNearestWeekday}},
+	{Key: "holiday_prefetch_years", Type: TypeInteger, Group: GroupHolidays, Description: "Future years whose holidays are loaded on startup", Default: "2", Min: intPtr(0), Max: intPtr(10)},
+	{Key: "holiday_retry_max_retries", Type: TypeInteger, Group: GroupHolidays, Description: "Background retries after a failed holiday fetch", Default: "5", Min: intPtr(0), Max: intPtr(20)},
+	{Key: "holiday_retry_base_seconds", Type: TypeInteger, Group: GroupHolidays, Description: "Delay before the first retry in seconds, doubled after each retry", Default: "30", Min: intPtr(1), Max: intPtr(3600)},
+	{Key: "holiday_retry_max_delay_seconds", Type: TypeInteger, Group: GroupHolidays, Description: "Longest delay between retries in seconds", Default: "1800", Min: intPtr(1), Max: intPtr(86400)},
+	{Key: "calendarific_api_key", Type: TypeString, Group: GroupHolidays, Description: "External holiday API key", Secret: true},
+
+	{Key: "webhook_urls", Type: TypeString, Group: GroupWebhooks, Description: "Webhook URLs notified about calendar events (comma or newline separated)"},
+	{Key: "webhook_secret", Type: TypeString, Group: GroupWebhooks, Description: "Secret used to sign webhook payloads", Secret: true},
+
+	{Key: "teams_webhook_url", Type: TypeString, Group: GroupTeams, Description: "Microsoft Teams incoming webhook", Secret: true},
+	{Key: "teams_weekly_digest", Type: TypeBoolean, Group: GroupTeams, Description: "Send the weekly next days off digest", Default: "true"},
+	{Key: "teams_optimization_results", Type: TypeBoolean, Group: GroupTeams, Description: "Post optimization results", Default: "true"},
+
+	{Key: "smtp_host", Type: TypeString, Group: GroupEmail, Description: "SMTP server host"},
+	{Key: "smtp_port", Type: TypeInteger, Group: GroupEmail, Description: "SMTP server port (465 uses implicit TLS)", Default: "587", Min: intPtr(1), Max: intPtr(65535)},
+	{Key: "smtp_username", Type: TypeString, Group: GroupEmail, Description: "SMTP username"},
+	{Key: "smtp_password", Type: TypeString, Group: GroupEmail, Description: "SMTP password", Secret: true},
+	{Key: "smtp_from", Type: TypeString, Group: GroupEmail, Description: "Sender address"},
+	{Key: "notification_email", Type: TypeString, Group: GroupEmail, Description: "Recipients for email notifications (comma separated)"},
+
+	{Key: "email_vacation_reminders", Type: TypeBoolean, Group: GroupNotifications, Description: "Email a reminder before each vacation block", Default: "true"},
+	{Key: "reminder_days_before", Type: TypeInteger, Group: GroupNotifications, Description: "Days before a vacation block the reminder is sent", Default: "3", Min: intPtr(0), Max: intPtr(60)},
+	{Key: "email_carryover_alerts", Type: TypeBoolean, Group: GroupNotifications, Description: "Email when carried over days are about to expire", Default: "true"},
+	{Key: "carryover_expiry", Type: TypeMonthDay, Group: GroupNotifications, Description: "Date when carried over days expire", Default: "04-30"},
+	{Key: "email_unused_days_alerts", Type: TypeBoolean, Group: GroupNotifications, Description: "Email when more vacation days are left than can realistically be booked this year", Default: "true"},
+	{Key: "unused_days_share", Type: TypeInteger, Group: GroupNotifications, Description: "Percentage of the open work days left in the year that can realistically be taken as vacation", Default: "30", Min: intPtr(1), Max: intPtr(100)},
+	{Key: "email_holiday_recovery", Type: TypeBoolean, Group: GroupNotifications, Description: "Email when holiday data loads after failed fetches", Default: "true"},
+	{Key: "email_monthly_digest", Type: TypeBoolean, Group: GroupNotifications, Description: "Email a monthly digest on the 1st", Default: "true"},
+
+	{Key: "vapid_subject", Type: TypeString, Group: GroupPush, Description: "Contact (mailto: or URL) sent to push services"},
+	{Key: "vapid_public_key", Type: TypeString, Group: GroupPush, Description: "VAPID public key, generated on first use", ReadOnly: true},
+	{Key: "vapid_private_key", Type: TypeString, Group: GroupPush, Description: "VAPID private key, generated on first use", Secret: true, ReadOnly: true},
+	{Key: "push_holiday_failures", Type: TypeBoolean, Group: GroupPush, Description: "Push a notification when holiday data fails to refresh", Default: "true"},
+	{Key: "push_vacation_start", Type: TypeBoolean, Group: GroupPush, Description: "Push a notification the day before a vacation starts", Default: "true"},
+
+	{Key: "flight_price_provider", Type: TypeEnum, Group: GroupTravel, Description: "Source of indicative flight prices for suggested vacation blocks", Default: flights.ProviderNone,
+		Options: []string{flights.ProviderNone, flights.ProviderAmadeus, flights.ProviderKiwi}},
+	{Key: "flight_api_key", Type: TypeString, Group: GroupTravel, Description: "Flight price API key (Amadeus client ID or Kiwi Tequila key)", Secret: true},
+	{Key: "flight_api_secret", Type: TypeString, Group: GroupTravel, Description: "Amadeus client secret", Secret: true},
+	{Key: "home_airport", Type: TypeAirport, Group: GroupTravel, Description: "Airport flights depart from"},
+	{Key: "flight_destination", Type: TypeAirport, Group: GroupTravel, Description: "Default destination airport for flight prices"},
+
+	{Key: "google_sheets_credentials", Type: TypeString, Group: GroupSheets, Description: "Google service account key (JSON) with access to the spreadsheet", Secret: true},
+	{Key: "google_sheets_spreadsheet_id", Type: TypeString, Group: GroupSheets, Description: "Spreadsheet the year plans are written to, one sheet per year"},
+	{Key: "google_sheets_layout", Type: TypeEnum, Group: GroupSheets, Description: "One row per vacation block or per day of the year", Default: "block", Options: []string{"block", "day"}},
+	{Key: "google_sheets_sync_on_change", Type: TypeBoolean, Group: GroupSheets, Description: "Rewrite the year's sheet whenever its vacation days change", Default: "false"},
+
+	{Key: "hr_provider", Type: TypeEnum, Group: GroupHR, Description: "HR system approved leave is imported from every night", Default: hr.ProviderNone,
+		Options: []string{hr.ProviderNone, hr.ProviderBambooHR, hr.ProviderPersonio}},
+	{Key: "hr_api_key", Type: TypeString, Group: GroupHR, Description: "BambooHR API key or Personio client ID", Secret: true},
+	{Key: "hr_api_secret", Type: TypeString, Group: GroupHR, Description: "Personio client secret", Secret: true},
+	{Key: "hr_company", Type: TypeString, Group: GroupHR, Description: "BambooHR company subdomain"},
+	{Key: "hr_employee_id", Type: TypeString, Group: GroupHR, Description: "Your employee ID in the HR system"},
+
+	{Key: "oncall_provider", Type: TypeEnum, Group: GroupOnCall, Description: "On-call schedule your shifts are closed to vacation from every night", Default: oncall.ProviderNone,
+		Options: []string{oncall.ProviderNone, oncall.ProviderPagerDuty, oncall.ProviderOpsgenie, oncall.ProviderICS}},
+	{Key: "oncall_api_key", Type: TypeString, Group: GroupOnCall, Description: "PagerDuty or Opsgenie API key", Secret: true},
+	{Key: "oncall_user", Type: TypeString, Group: GroupOnCall, Description: "Your PagerDuty user ID or Opsgenie username"},
+	{Key: "oncall_schedule_id", Type: TypeString, Group: GroupOnCall, Description: "Opsgenie schedule you are on"},
+	{Key: "oncall_ics_url", Type: TypeString, Group: GroupOnCall, Description: "Your on-call calendar feed (iCalendar) URL", Secret: true},
+
+	{Key: "milestone_provider", Type: TypeEnum, Group: GroupMilestones, Description: "Where project milestones are imported from every night, to keep vacation away from their weeks", Default: milestones.ProviderNone,
+		Options: []string{milestones.ProviderNone, milestones.ProviderJira, milestones.ProviderGitHub, milestones.ProviderICS}},
+	{Key: "milestone_api_key", Type: TypeString, Group: GroupMilestones, Description: "Jira API token or GitHub token", Secret: true},
+	{Key: "milestone_user", Type: TypeString, Group: GroupMilestones, Description: "Your Jira account email"},
+	{Key: "milestone_project", Type: TypeString, Group: GroupMilestones, Description: "Jira project key or GitHub repository (owner/repo)"},
+	{Key: "milestone_url", Type: TypeString, Group: GroupMilestones, Description: "Jira site URL or the milestone calendar feed (iCalendar) URL", Secret: true},
+
+	{Key: "backup_target", Type: TypeEnum, Group: GroupBackups, Description: "Where scheduled database backups are kept", Default: backup.TargetNone,
+		Options: []string{backup.TargetNone, backup.TargetFilesystem, backup.TargetS3}},
+	{Key: "backup_schedule", Type: TypeCron, Group: GroupBackups, Description: "When backups are taken, as a cron expression in the server time zone", Default: "0 2 * * *"},
+	{Key: "backup_dir", Type: TypeString, Group: GroupBackups, Description: "Directory of the filesystem target", Default: "./data/backups"},
+	{Key: "backup_s3_endpoint", Type: TypeString, Group: GroupBackups, Description: "S3-compatible endpoint URL, empty for AWS"},
+	{Key: "backup_s3_region", Type: TypeString, Group: GroupBackups, Description: "Bucket region", Default: "us-east-1"},
+	{Key: "backup_s3_bucket", Type: TypeString, Group: GroupBackups, Description: "Bucket backups are uploaded to"},
+	{Key: "backup_s3_prefix", Type: TypeString, Group: GroupBackups, Description: "Key prefix in the bucket, such as backups/"},
+	{Key: "backup_s3_access_key", Type: TypeString, Group: GroupBackups, Description: "S3 access key ID", Secret: true},
+	{Key: "backup_s3_secret_key", Type: TypeString, Group: GroupBackups, Description: "S3 secret access key", Secret: true},
+	{Key: "backup_keep_count", Type: TypeInteger, Group: GroupBackups, Description: "Backups kept, the oldest are deleted (0 for no limit)", Default: "14", Min: intPtr(0), Max: intPtr(1000)},
+	{Key: "backup_keep_days", Type: TypeInteger, Group: GroupBackups, Description: "Days a backup is kept (0 for no limit)", Default: "30", Min: intPtr(0), Max: intPtr(3650)},
+
+	{Key: "auth_max_failures", Type: TypeInteger, Group: GroupSecurity, Description: "Failed access token attempts from one client that lock it out (0 turns the protection off)", Default: "10", Min: intPtr(0), Max: intPtr(1000)},
+	{Key: "auth_failure_window_minutes", Type: TypeInteger, Group: GroupSecurity, Description: "Minutes a failed attempt counts towards the lockout", Default: "15", Min: intPtr(1), Max: intPtr(1440)},
+	{Key: "auth_lockout_minutes", Type: TypeInteger, Group: GroupSecurity, Description: "Minutes a locked out client is rejected", Default: "15", Min: intPtr(1), Max: intPtr(10080)},
+	{Key: "auth_throttle_seconds", Type: TypeInteger, Group: GroupSecurity, Description: "Seconds a client waits after its second failed attempt, doubled after each further one up to 5 minutes (0 for no wait)", Default: "1", Min: intPtr(0), Max: intPtr(60)},
+
+	{Key: "oidc_issuer", Type: TypeString, Group: GroupSSO, Description: "OpenID Connect issuer URL, such as https://login.microsoftonline.com/<tenant>/v2.0 (empty turns single sign-on off)"},
+	{Key: "oidc_client_id", Type: TypeString, Group: GroupSSO, Description: "Client ID of the app registered with the identity provider"},
+	{Key: "oidc_client_secret", Type: TypeString, Group: GroupSSO, Description: "Client secret of the registered app", Secret: true},
+	{Key: "oidc_redirect_url", Type: TypeString, Group: GroupSSO, Description: "Redirect URL registered with the provider, such as https://planner.example.com/api/auth/oidc/callback"},
+	{Key: "oidc_groups_claim", Type: TypeString, Group: GroupSSO, Description: "ID token claim listing the user's groups", Default: "groups"},
+	{Key: "oidc_role_mapping", Type: TypeRoles, Group: GroupSSO, Description: "Groups and the role they give, such as \"planner-admins=admin, hr=manager, staff=member\"; a user in several gets the highest"},
+	{Key: "oidc_default_role", Type: TypeEnum, Group: GroupSSO, Description: "Role of users in none of the mapped groups (empty refuses them)", Options: []string{models.RoleViewer, models.RoleMember, models.RoleManager, models.RoleAdmin}},
+	{Key: "oidc_session_hours", Type: TypeInteger, Group: GroupSSO, Description: "Hours a single sign-on session lasts before signing in again", Default: "12", Min: intPtr(1), Max: intPtr(720)},
+}
+
+// Lookup returns the definition of a setting
+func Lookup(key string) (Definition, bool) {
+	for _, d := range Schema {
+		if d.Key == key {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Validate checks a value for a setting. An empty value is always accepted
+// and means the setting is unset.
+func Validate(key, value string) error {
+	d, ok := Lookup(key)
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	if value == "" {
+		return nil
+	}
+
+	switch d.Type {
+	case TypeInteger:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be an integer", key)
+		}
+		if d.Min != nil && n < *d.Min {
+			return fmt.Errorf("%s must be at least %d", key, *d.Min)
+		}
+		if d.Max != nil && n > *d.Max {
+			return fmt.Errorf("%s must be at most %d", key, *d.Max)
+		}
+	case TypeBoolean:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s must be true or false", key)
+		}
+	case TypeEnum:
+		for _, option := range d.Options {
+			if value == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of: %s", key, strings.Join(d.Options, ", "))
+	case TypeDate:
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return fmt.Errorf("%s must be a YYYY-MM-DD date", key)
+		}
+	case TypeMonthDay:
+		if _, err := time.Parse("01-02", value); err != nil {
+			return fmt.Errorf("%s must be a MM-DD date", key)
+		}
+	case TypeTimezone:
+		if _, err := time.LoadLocation(value); err != nil {
+			return fmt.Errorf("%s must be an IANA time zone such as Europe/Lisbon", key)
+		}
+	case TypeAirport:
+		if _, err := flights.NormalizeAirport(value); err != nil {
+			return fmt.Errorf("%s must be a three-letter IATA code such as LIS", key)
+		}
+	case TypeCron:
+		if _, err := cron.ParseStandard(value); err != nil {
+			return fmt.Errorf("%s must be a cron expression such as \"0 2 * * *\"", key)
+		}
+	case TypeModels:
+		if _, err := ParseModels(value); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	case TypeRoles:
+		if _, err := ParseRoleMapping(value); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	case TypeWorkWeek:
+		var days []string
+		if err := json.Unmarshal([]byte(value), &days); err != nil {
+			return fmt.Errorf("%s must be a JSON array of weekdays", key)
+		}
+		for _, day := range days {
+			if !isWeekDay(day) {
+				return fmt.Errorf("%s has an invalid weekday %q", key, day)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ModelRef is an AI model, with the provider serving it when not the
+// configured one
+type ModelRef struct {
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model"`
+}
+
+// ParseModels splits a list of AI models (comma or newline separated), each
+// optionally prefixed by "github:" or "openai:"
+func ParseModels(value string) ([]ModelRef, error) {
+	var refs []ModelRef
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		var ref ModelRef
+		if provider, model, ok := strings.Cut(field, ":"); ok {
+			if provider != "github" && provider != "openai" {
+				return nil, fmt.Errorf("unknown provider %q, must be github or openai", provider)
+			}
+			ref.Provider, field = provider, strings.TrimSpace(model)
+		}
+		if field == "" {
+			return nil, fmt.Errorf("missing model after %q", ref.Provider+":")
+		}
+		ref.Model = field
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// ParseRoleMapping splits a list of group=role pairs (comma or newline
+// separated) into the role of each group
+func ParseRoleMapping(value string) (map[string]string, error) {
+	mapping := map[string]string{}
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		group, role, ok := strings.Cut(field, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" {
+			return nil, fmt.Errorf("%q must be a group=role pair", field)
+		}
+		switch role {
+		case models.RoleViewer, models.RoleMember, models.RoleManager, models.RoleAdmin:
+		default:
+			return nil, fmt.Errorf("unknown role %q for group %q, must be admin, manager, member or viewer", role, group)
+		}
+		mapping[group] = role
+	}
+	return mapping, nil
+}
+
+func isWeekDay(day string) bool {
+	for _, d := range models.AllWeekDays {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
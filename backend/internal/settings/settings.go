@@ -0,0 +1,262 @@
+package settings
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// Settings is the typed view of the stored settings. Unset or invalid values
+// fall back to the schema defaults.
+type Settings struct {
+	AIProvider   string `json:"ai_provider"`
+	OpenAIAPIKey string `json:"openai_api_key"`
+	AIModel      string `json:"ai_model"`
+
+	AIFallbackModels   []ModelRef `json:"ai_fallback_models"`
+	AIFallbackAPIKey   string     `json:"ai_fallback_api_key"`
+	AICorrectionRounds int        `json:"ai_correction_rounds"`
+
+	BackendPort                 int      `json:"backend_port"`
+	FrontendPort                int      `json:"frontend_port"`
+	DefaultWorkWeek             []string `json:"default_work_week"`
+	OptimizerTimeBudgetMS       int      `json:"optimizer_time_budget_ms"`
+	DefaultVacationDays         int      `json:"default_vacation_days"`
+	DefaultOptimizationStrategy string   `json:"default_optimization_strategy"`
+	Timezone                    string   `json:"timezone"`
+	EmploymentStartDate         string   `json:"employment_start_date"`
+	Birthday                    string   `json:"birthday"`
+	BirthdayDayOff              string   `json:"birthday_day_off"`
+	PastEditProtection          bool     `json:"past_edit_protection"`
+	PastEditGraceDays           int      `json:"past_edit_grace_days"`
+
+	WorkCity             string `json:"work_city"`
+	SchoolDistrict       string `json:"school_district"`
+	HolidaySubstitution  string `json:"holiday_substitution"`
+	HolidayPrefetchYears int    `json:"holiday_prefetch_years"`
+
+	HolidayRetryMaxRetries      int    `json:"holiday_retry_max_retries"`
+	HolidayRetryBaseSeconds     int    `json:"holiday_retry_base_seconds"`
+	HolidayRetryMaxDelaySeconds int    `json:"holiday_retry_max_delay_seconds"`
+	CalendarificAPIKey          string `json:"calendarific_api_key"`
+
+	WebhookURLs   string `json:"webhook_urls"`
+	WebhookSecret string `json:"webhook_secret"`
+
+	TeamsWebhookURL          string `json:"teams_webhook_url"`
+	TeamsWeeklyDigest        bool   `json:"teams_weekly_digest"`
+	TeamsOptimizationResults bool   `json:"teams_optimization_results"`
+
+	SMTPHost          string `json:"smtp_host"`
+	SMTPPort          int    `json:"smtp_port"`
+	SMTPUsername      string `json:"smtp_username"`
+	SMTPPassword      string `json:"smtp_password"`
+	SMTPFrom          string `json:"smtp_from"`
+	NotificationEmail string `json:"notification_email"`
+
+	EmailVacationReminders bool   `json:"email_vacation_reminders"`
+	ReminderDaysBefore     int    `json:"reminder_days_before"`
+	EmailCarryoverAlerts   bool   `json:"email_carryover_alerts"`
+	CarryoverExpiry        string `json:"carryover_expiry"`
+	EmailUnusedDaysAlerts  bool   `json:"email_unused_days_alerts"`
+	UnusedDaysShare        int    `json:"unused_days_share"`
+	EmailHolidayRecovery   bool   `json:"email_holiday_recovery"`
+	EmailMonthlyDigest     bool   `json:"email_monthly_digest"`
+
+	VAPIDSubject        string `json:"vapid_subject"`
+	VAPIDPublicKey      string `json:"vapid_public_key"`
+	VAPIDPrivateKey     string `json:"vapid_private_key"`
+	PushHolidayFailures bool   `json:"push_holiday_failures"`
+	PushVacationStart   bool   `json:"push_vacation_start"`
+
+	FlightPriceProvider string `json:"flight_price_provider"`
+	FlightAPIKey        string `json:"flight_api_key"`
+	FlightAPISecret     string `json:"flight_api_secret"`
+	HomeAirport         string `json:"home_airport"`
+	FlightDestination   string `json:"flight_destination"`
+
+	GoogleSheetsCredentials   string `json:"google_sheets_credentials"`
+	GoogleSheetsSpreadsheetID string `json:"google_sheets_spreadsheet_id"`
+	GoogleSheetsLayout        string `json:"google_sheets_layout"`
+	GoogleSheetsSyncOnChange  bool   `json:"google_sheets_sync_on_change"`
+
+	HRProvider   string `json:"hr_provider"`
+	HRAPIKey     string `json:"hr_api_key"`
+	HRAPISecret  string `json:"hr_api_secret"`
+	HRCompany    string `json:"hr_company"`
+	HREmployeeID string `json:"hr_employee_id"`
+
+	OnCallProvider   string `json:"oncall_provider"`
+	OnCallAPIKey     string `json:"oncall_api_key"`
+	OnCallUser       string `json:"oncall_user"`
+	OnCallScheduleID string `json:"oncall_schedule_id"`
+	OnCallICSURL     string `json:"oncall_ics_url"`
+
+	MilestoneProvider string `json:"milestone_provider"`
+	MilestoneAPIKey   string `json:"milestone_api_key"`
+	MilestoneUser     string `json:"milestone_user"`
+	MilestoneProject  string `json:"milestone_project"`
+	MilestoneURL      string `json:"milestone_url"`
+
+	BackupTarget      string `json:"backup_target"`
+	BackupSchedule    string `json:"backup_schedule"`
+	BackupDir         string `json:"backup_dir"`
+	BackupS3Endpoint  string `json:"backup_s3_endpoint"`
+	BackupS3Region    string `json:"backup_s3_region"`
+	BackupS3Bucket    string `json:"backup_s3_bucket"`
+	BackupS3Prefix    string `json:"backup_s3_prefix"`
+	BackupS3AccessKey string `json:"backup_s3_access_key"`
+	BackupS3SecretKey string `json:"backup_s3_secret_key"`
+	BackupKeepCount   int    `json:"backup_keep_count"`
+	BackupKeepDays    int    `json:"backup_keep_days"`
+
+	AuthMaxFailures          int `json:"auth_max_failures"`
+	AuthFailureWindowMinutes int `json:"auth_failure_window_minutes"`
+	AuthLockoutMinutes       int `json:"auth_lockout_minutes"`
+	AuthThrottleSeconds      int `json:"auth_throttle_seconds"`
+
+	OIDCIssuer       string            `json:"oidc_issuer"`
+	OIDCClientID     string            `json:"oidc_client_id"`
+	OIDCClientSecret string            `json:"oidc_client_secret"`
+	OIDCRedirectURL  string            `json:"oidc_redirect_url"`
+	OIDCGroupsClaim  string            `json:"oidc_groups_claim"`
+	OIDCRoleMapping  map[string]string `json:"oidc_role_mapping"`
+	OIDCDefaultRole  string            `json:"oidc_default_role"`
+	OIDCSessionHours int               `json:"oidc_session_hours"`
+}
+
+// Parse builds the typed settings from stored key/value pairs
+func Parse(values map[string]string) Settings {
+	v := func(key string) string {
+		if value, ok := values[key]; ok && value != "" && Validate(key, value) == nil {
+			return value
+		}
+		d, _ := Lookup(key)
+		return d.Default
+	}
+	integer := func(key string) int {
+		n, _ := strconv.Atoi(v(key))
+		return n
+	}
+	boolean := func(key string) bool {
+		return v(key) == "true"
+	}
+
+	s := Settings{
+		AIProvider:   v("ai_provider"),
+		OpenAIAPIKey: v("openai_api_key"),
+		AIModel:      v("ai_model"),
+
+		AIFallbackAPIKey:   v("ai_fallback_api_key"),
+		AICorrectionRounds: integer("ai_correction_rounds"),
+
+		BackendPort:                 integer("backend_port"),
+		FrontendPort:                integer("frontend_port"),
+		DefaultVacationDays:         integer("default_vacation_days"),
+		OptimizerTimeBudgetMS:       integer("optimizer_time_budget_ms"),
+		DefaultOptimizationStrategy: v("default_optimization_strategy"),
+		Timezone:                    v("timezone"),
+		EmploymentStartDate:         v("employment_start_date"),
+		Birthday:                    v("birthday"),
+		BirthdayDayOff:              v("birthday_day_off"),
+		PastEditProtection:          boolean("past_edit_protection"),
+		PastEditGraceDays:           integer("past_edit_grace_days"),
+
+		WorkCity:             v("work_city"),
+		SchoolDistrict:       v("school_district"),
+		HolidaySubstitution:  v("holiday_substitution"),
+		HolidayPrefetchYears: integer("holiday_prefetch_years"),
+
+		HolidayRetryMaxRetries:      integer("holiday_retry_max_retries"),
+		HolidayRetryBaseSeconds:     integer("holiday_retry_base_seconds"),
+		HolidayRetryMaxDelaySeconds: integer("holiday_retry_max_delay_seconds"),
+		CalendarificAPIKey:          v("calendarific_api_key"),
+
+		WebhookURLs:   v("webhook_urls"),
+		WebhookSecret: v("webhook_secret"),
+
+		TeamsWebhookURL:          v("teams_webhook_url"),
+		TeamsWeeklyDigest:        boolean("teams_weekly_digest"),
+		TeamsOptimizationResults: boolean("teams_optimization_results"),
+
+		SMTPHost:          v("smtp_host"),
+		SMTPPort:          integer("smtp_port"),
+		SMTPUsername:      v("smtp_username"),
+		SMTPPassword:      v("smtp_password"),
+		SMTPFrom:          v("smtp_from"),
+		NotificationEmail: v("notification_email"),
+
+		EmailVacationReminders: boolean("email_vacation_reminders"),
+		ReminderDaysBefore:     integer("reminder_days_before"),
+		EmailCarryoverAlerts:   boolean("email_carryover_alerts"),
+		CarryoverExpiry:        v("carryover_expiry"),
+		EmailUnusedDaysAlerts:  boolean("email_unused_days_alerts"),
+		UnusedDaysShare:        integer("unused_days_share"),
+		EmailHolidayRecovery:   boolean("email_holiday_recovery"),
+		EmailMonthlyDigest:     boolean("email_monthly_digest"),
+
+		VAPIDSubject:        v("vapid_subject"),
+		VAPIDPublicKey:      v("vapid_public_key"),
+		VAPIDPrivateKey:     v("vapid_private_key"),
+		PushHolidayFailures: boolean("push_holiday_failures"),
+		PushVacationStart:   boolean("push_vacation_start"),
+
+		FlightPriceProvider: v("flight_price_provider"),
+		FlightAPIKey:        v("flight_api_key"),
+		FlightAPISecret:     v("flight_api_secret"),
+		HomeAirport:         v("home_airport"),
+		FlightDestination:   v("flight_destination"),
+
+		GoogleSheetsCredentials:   v("google_sheets_credentials"),
+		GoogleSheetsSpreadsheetID: v("google_sheets_spreadsheet_id"),
+		GoogleSheetsLayout:        v("google_sheets_layout"),
+		GoogleSheetsSyncOnChange:  boolean("google_sheets_sync_on_change"),
+
+		HRProvider:   v("hr_provider"),
+		HRAPIKey:     v("hr_api_key"),
+		HRAPISecret:  v("hr_api_secret"),
+		HRCompany:    v("hr_company"),
+		HREmployeeID: v("hr_employee_id"),
+
+		OnCallProvider:   v("oncall_provider"),
+		OnCallAPIKey:     v("oncall_api_key"),
+		OnCallUser:       v("oncall_user"),
+		OnCallScheduleID: v("oncall_schedule_id"),
+		OnCallICSURL:     v("oncall_ics_url"),
+
+		MilestoneProvider: v("milestone_provider"),
+		MilestoneAPIKey:   v("milestone_api_key"),
+		MilestoneUser:     v("milestone_user"),
+		MilestoneProject:  v("milestone_project"),
+		MilestoneURL:      v("milestone_url"),
+
+		BackupTarget:      v("backup_target"),
+		BackupSchedule:    v("backup_schedule"),
+		BackupDir:         v("backup_dir"),
+		BackupS3Endpoint:  v("backup_s3_endpoint"),
+		BackupS3Region:    v("backup_s3_region"),
+		BackupS3Bucket:    v("backup_s3_bucket"),
+		BackupS3Prefix:    v("backup_s3_prefix"),
+		BackupS3AccessKey: v("backup_s3_access_key"),
+		BackupS3SecretKey: v("backup_s3_secret_key"),
+		BackupKeepCount:   integer("backup_keep_count"),
+		BackupKeepDays:    integer("backup_keep_days"),
+
+		AuthMaxFailures:          integer("auth_max_failures"),
+		AuthFailureWindowMinutes: integer("auth_failure_window_minutes"),
+		AuthLockoutMinutes:       integer("auth_lockout_minutes"),
+		AuthThrottleSeconds:      integer("auth_throttle_seconds"),
+
+		OIDCIssuer:       v("oidc_issuer"),
+		OIDCClientID:     v("oidc_client_id"),
+		OIDCClientSecret: v("oidc_client_secret"),
+		OIDCRedirectURL:  v("oidc_redirect_url"),
+		OIDCGroupsClaim:  v("oidc_groups_claim"),
+		OIDCDefaultRole:  v("oidc_default_role"),
+		OIDCSessionHours: integer("oidc_session_hours"),
+	}
+	json.Unmarshal([]byte(v("default_work_week")), &s.DefaultWorkWeek)
+	s.AIFallbackModels, _ = ParseModels(v("ai_fallback_models"))
+	s.OIDCRoleMapping, _ = ParseRoleMapping(v("oidc_role_mapping"))
+
+	return s
+}
@@ -0,0 +1,274 @@
+// Package sheets writes tables into Google Sheets with a service account,
+// for teams that track leave in a shared spreadsheet.
+package sheets
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/sandbox"
+)
+
+const (
+	apiURL          = "https://sheets.googleapis.com/v4/spreadsheets"
+	defaultTokenURL = "https://oauth2.googleapis.com/token"
+	scope           = "https://www.googleapis.com/auth/spreadsheets"
+)
+
+// Writer replaces the contents of a sheet
+type Writer interface {
+	// WriteSheet clears a sheet of a spreadsheet, creating it when missing,
+	// and writes rows from its first cell
+	WriteSheet(ctx context.Context, spreadsheetID, sheet string, rows [][]string) error
+}
+
+// New returns a writer authenticated with a service account key (the JSON
+// file downloaded from Google Cloud). The spreadsheet must be shared with the
+// service account's email. In sandbox mode nothing is sent to Google.
+func New(credentialsJSON string) (Writer, error) {
+	if sandbox.Enabled() {
+		return sandboxWriter{}, nil
+	}
+	if strings.TrimSpace(credentialsJSON) == "" {
+		return nil, errors.New("google_sheets_credentials is not set")
+	}
+	return newClient(credentialsJSON, apiURL)
+}
+
+// serviceAccount holds the fields of a service account key used here
+type serviceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// Client calls the Sheets API with an OAuth token obtained from a signed
+// JWT assertion
+type Client struct {
+	http    *http.Client
+	apiURL  string
+	account serviceAccount
+	key     *rsa.PrivateKey
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func newClient(credentialsJSON, api string) (*Client, error) {
+	var account serviceAccount
+	if err := json.Unmarshal([]byte(credentialsJSON), &account); err != nil {
+		return nil, fmt.Errorf("invalid service account key: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, errors.New("service account key needs client_email and private_key")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = defaultTokenURL
+	}
+
+	block, _ := pem.Decode([]byte(account.PrivateKey))
+	if block == nil {
+		return nil, errors.New("service account private_key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service account private_key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("service account private_key is not an RSA key")
+	}
+
+	return &Client{
+		http:    &http.Client{Timeout: 15 * time.Second},
+		apiURL:  api,
+		account: account,
+		key:     key,
+	}, nil
+}
+
+// WriteSheet clears a sheet, creating it when missing, and writes rows from A1
+func (c *Client) WriteSheet(ctx context.Context, spreadsheetID, sheet string, rows [][]string) error {
+	base := c.apiURL + "/" + url.PathEscape(spreadsheetID)
+
+	var meta struct {
+		Sheets []struct {
+			Properties struct {
+				Title string `json:"title"`
+			} `json:"properties"`
+		} `json:"sheets"`
+	}
+	if err := c.call(ctx, http.MethodGet, base+"?fields=sheets.properties.title", nil, &meta); err != nil {
+		return err
+	}
+
+	exists := false
+	for _, s := range meta.Sheets {
+		if s.Properties.Title == sheet {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		addSheet := map[string]interface{}{
+			"requests": []interface{}{
+				map[string]interface{}{"addSheet": map[string]interface{}{"properties": map[string]string{"title": sheet}}},
+			},
+		}
+		if err := c.call(ctx, http.MethodPost, base+":batchUpdate", addSheet, nil); err != nil {
+			return err
+		}
+	}
+
+	sheetRange := url.PathEscape("'" + strings.ReplaceAll(sheet, "'", "''") + "'")
+	if err := c.call(ctx, http.MethodPost, base+"/values/"+sheetRange+":clear", map[string]string{}, nil); err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{"majorDimension": "ROWS", "values": rows}
+	return c.call(ctx, http.MethodPut, base+"/values/"+sheetRange+"?valueInputOption=USER_ENTERED", values, nil)
+}
+
+// call sends a JSON request to the Sheets API and decodes the response into out
+func (c *Client) call(ctx context.Context, method, endpoint string, body, out interface{}) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Google Sheets: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Google Sheets API returned status %d", resp.StatusCode)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to parse Google Sheets response: %w", err)
+		}
+	}
+	return nil
+}
+
+// accessToken returns the current token, exchanging a new signed assertion
+// shortly before it expires
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+
+	assertion, err := c.assertion(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to authenticate with Google: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Google authentication returned status %d", resp.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to parse Google token: %w", err)
+	}
+
+	c.token = token.AccessToken
+	c.expires = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return c.token, nil
+}
+
+// assertion builds the RS256 signed JWT exchanged for an access token
+func (c *Client) assertion(now time.Time) (string, error) {
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	claims := map[string]interface{}{
+		"iss":   c.account.ClientEmail,
+		"scope": scope,
+		"aud":   c.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+
+	var parts []string
+	for _, part := range []interface{}{header, claims} {
+		encoded, err := json.Marshal(part)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, base64.RawURLEncoding.EncodeToString(encoded))
+	}
+
+	signingInput := strings.Join(parts, ".")
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// sandboxWriter accepts every write without calling Google
+type sandboxWriter struct{}
+
+func (sandboxWriter) WriteSheet(ctx context.Context, spreadsheetID, sheet string, rows [][]string) error {
+	return nil
+}
@@ -0,0 +1,71 @@
+package sheets
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteSheet(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+
+	var calls []string
+	var written struct {
+		Values [][]string `json:"values"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch {
+		case r.URL.Path == "/token":
+			if r.FormValue("assertion") == "" {
+				t.Error("token request without an assertion")
+			}
+			w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+			return
+		case r.Header.Get("Authorization") != "Bearer token":
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`{"sheets":[{"properties":{"title":"Sheet1"}}]}`))
+			return
+		case r.Method == http.MethodPut:
+			json.NewDecoder(r.Body).Decode(&written)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	credentials, _ := json.Marshal(serviceAccount{
+		ClientEmail: "planner@example.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:    srv.URL + "/token",
+	})
+	client, err := newClient(string(credentials), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := [][]string{{"Date", "Type"}, {"2030-08-05", "vacation"}}
+	if err := client.WriteSheet(context.Background(), "sheet-id", "2030", rows); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"POST /token", "GET /sheet-id", "POST /sheet-id:batchUpdate", "POST /sheet-id/values/'2030':clear", "PUT /sheet-id/values/'2030'"}
+	if strings.Join(calls, ", ") != strings.Join(want, ", ") {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	if len(written.Values) != 2 || written.Values[1][1] != "vacation" {
+		t.Errorf("written values = %v", written.Values)
+	}
+}
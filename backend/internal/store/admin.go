@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"sort"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// AdminStore looks after the stored data as a whole, a year at a time
+type AdminStore struct {
+	q DBTX
+}
+
+// yearTable is a table whose rows belong to one year, directly or through
+// their parent row
+type yearTable struct {
+	name string
+	from string // The table as r, joined to its parent when needed
+	year string // The column holding the year in from
+	del  string // Deletes the rows of the year given as the only argument
+}
+
+func directYearTable(name string) yearTable {
+	return yearTable{name: name, from: name + " r", year: "r.year", del: "DELETE FROM " + name + " WHERE year = ?"}
+}
+
+// yearTables lists the tables holding a year's data. Rows tied to a year
+// through their parent come before the parent, so purging deletes them first.
+var yearTables = []yearTable{
+	{
+		name: "scenario_days",
+		from: "scenario_days r JOIN scenarios p ON p.id = r.scenario_id",
+		year: "p.year",
+		del:  "DELETE FROM scenario_days WHERE scenario_id IN (SELECT id FROM scenarios WHERE year = ?)",
+	},
+	{
+		name: "trip_expenses",
+		from: "trip_expenses r JOIN trips p ON p.id = r.trip_id",
+		year: "p.year",
+		del:  "DELETE FROM trip_expenses WHERE trip_id IN (SELECT id FROM trips WHERE year = ?)",
+	},
+	directYearTable("year_config"),
+	directYearTable("vacation_days"),
+	directYearTable("optimal_vacations"),
+	directYearTable("locked_dates"),
+	directYearTable("scenarios"),
+	directYearTable("holidays"),
+	directYearTable("holiday_changes"),
+	directYearTable("school_holidays"),
+	directYearTable("chat_history"),
+	directYearTable("ai_suggestions"),
+	directYearTable("work_week_changes"),
+	directYearTable("worked_holidays"),
+	directYearTable("comp_days"),
+	directYearTable("balance_adjustments"),
+	directYearTable("trips"),
+	directYearTable("personal_events"),
+	directYearTable("booking_proposals"),
+	directYearTable("block_labels"),
+	directYearTable("declined_blocks"),
+	directYearTable("comments"),
+	directYearTable("share_links"),
+}
+
+// Years returns the years with stored data, in order, with their row count
+// per table. Tables without rows for a year are left out.
+func (s *AdminStore) Years(ctx context.Context) ([]models.YearData, error) {
+	byYear := make(map[int]*models.YearData)
+	for _, t := range yearTables {
+		rows, err := s.q.QueryContext(ctx, `SELECT `+t.year+`, COUNT(*) FROM `+t.from+` GROUP BY `+t.year)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var year int
+			var count int64
+			if err := rows.Scan(&year, &count); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			data, ok := byYear[year]
+			if !ok {
+				data = &models.YearData{Year: year, Tables: map[string]int64{}}
+				byYear[year] = data
+			}
+			data.Tables[t.name] = count
+			data.Total += count
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	years := []models.YearData{}
+	for _, data := range byYear {
+		years = append(years, *data)
+	}
+	sort.Slice(years, func(i, j int) bool { return years[i].Year < years[j].Year })
+	return years, nil
+}
+
+// Export returns every row of a year by table, as column/value maps.
+// Tables without rows for the year are left out.
+func (s *AdminStore) Export(ctx context.Context, year int) (map[string][]map[string]interface{}, error) {
+	tables := make(map[string][]map[string]interface{})
+	for _, t := range yearTables {
+		rows, err := s.q.QueryContext(ctx, `SELECT r.* FROM `+t.from+` WHERE `+t.year+` = ?`, year)
+		if err != nil {
+			return nil, err
+		}
+		columns, err := rows.Columns()
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		for rows.Next() {
+			values := make([]interface{}, len(columns))
+			pointers := make([]interface{}, len(columns))
+			for i := range values {
+				pointers[i] = &values[i]
+			}
+			if err := rows.Scan(pointers...); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			row := make(map[string]interface{}, len(columns))
+			for i, column := range columns {
+				// Text comes back as bytes, which JSON would encode as base64
+				if b, ok := values[i].([]byte); ok {
+					values[i] = string(b)
+				}
+				row[column] = values[i]
+			}
+			tables[t.name] = append(tables[t.name], row)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// Purge deletes every row of a year and returns the count deleted per table.
+// Run it in a transaction so a failure keeps the year whole.
+func (s *AdminStore) Purge(ctx context.Context, year int) (map[string]int64, error) {
+	deleted := make(map[string]int64)
+	for _, t := range yearTables {
+		result, err := s.q.ExecContext(ctx, t.del, year)
+		if err != nil {
+			return nil, err
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			deleted[t.name] = n
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,48 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// AuditStore holds the authentication audit log
+type AuditStore struct {
+	q DBTX
+}
+
+// Add appends an event, stamped with the current time
+func (s *AuditStore) Add(ctx context.Context, e models.AuthEvent) error {
+	_, err := s.q.ExecContext(ctx, `INSERT INTO auth_events (event, client, token_name, detail, created_at) VALUES (?, ?, ?, ?, ?)`,
+		e.Event, e.Client, e.TokenName, e.Detail, time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// List returns the latest events, newest first
+func (s *AuditStore) List(ctx context.Context, limit int) ([]models.AuthEvent, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id, event, client, token_name, detail, created_at FROM auth_events ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.AuthEvent{}
+	for rows.Next() {
+		var e models.AuthEvent
+		if err := rows.Scan(&e.ID, &e.Event, &e.Client, &e.TokenName, &e.Detail, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// DeleteBefore removes the events older than t and returns how many
+func (s *AuditStore) DeleteBefore(ctx context.Context, t time.Time) (int64, error) {
+	result, err := s.q.ExecContext(ctx, `DELETE FROM auth_events WHERE created_at < ?`, t.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// BlockStore holds the names and colors given to vacation blocks and the
+// suggested blocks declined
+type BlockStore struct {
+	q DBTX
+}
+
+// Labels returns the block labels of a year by date
+func (s *BlockStore) Labels(ctx context.Context, year int) ([]models.BlockLabel, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT year, date, label, color FROM block_labels WHERE year = ? ORDER BY date`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	labels := []models.BlockLabel{}
+	for rows.Next() {
+		var l models.BlockLabel
+		if err := rows.Scan(&l.Year, &l.Date, &l.Label, &l.Color); err != nil {
+			return nil, err
+		}
+		labels = append(labels, l)
+	}
+	return labels, rows.Err()
+}
+
+// SetLabel stores the label of the block starting on l.Date, replacing the
+// labels dated l.Date..to, the block's other days. Run it in a transaction.
+func (s *BlockStore) SetLabel(ctx context.Context, l models.BlockLabel, to string) error {
+	if err := s.DeleteLabels(ctx, l.Year, l.Date, to); err != nil {
+		return err
+	}
+	_, err := s.q.ExecContext(ctx, `INSERT INTO block_labels (year, date, label, color) VALUES (?, ?, ?, ?)`,
+		l.Year, l.Date, l.Label, l.Color)
+	return err
+}
+
+// DeleteLabels removes the labels of a year dated from..to, both inclusive
+func (s *BlockStore) DeleteLabels(ctx context.Context, year int, from, to string) error {
+	_, err := s.q.ExecContext(ctx, `DELETE FROM block_labels WHERE year = ? AND date BETWEEN ? AND ?`, year, from, to)
+	return err
+}
+
+// Declined returns the declined blocks of a year by start date
+func (s *BlockStore) Declined(ctx context.Context, year int) ([]models.DeclinedBlock, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id, year, start_date, end_date, dates, created_at FROM declined_blocks
+		WHERE year = ? ORDER BY start_date, id`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	declined := []models.DeclinedBlock{}
+	for rows.Next() {
+		var d models.DeclinedBlock
+		var datesJSON string
+		if err := rows.Scan(&d.ID, &d.Year, &d.StartDate, &d.EndDate, &datesJSON, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(datesJSON), &d.Dates)
+		declined = append(declined, d)
+	}
+	return declined, rows.Err()
+}
+
+// Decline stores a declined block and returns its id
+func (s *BlockStore) Decline(ctx context.Context, d models.DeclinedBlock) (int64, error) {
+	datesJSON, _ := json.Marshal(d.Dates)
+	result, err := s.q.ExecContext(ctx, `INSERT INTO declined_blocks (year, start_date, end_date, dates) VALUES (?, ?, ?, ?)`,
+		d.Year, d.StartDate, d.EndDate, string(datesJSON))
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// DeleteDeclined removes a declined block, returning ErrNotFound when the
+// year has none with that id
+func (s *BlockStore) DeleteDeclined(ctx context.Context, year int, id int64) error {
+	result, err := s.q.ExecContext(ctx, `DELETE FROM declined_blocks WHERE year = ? AND id = ?`, year, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ClearDeclined removes the declined blocks of a year
+func (s *BlockStore) ClearDeclined(ctx context.Context, year int) error {
+	_, err := s.q.ExecContext(ctx, `DELETE FROM declined_blocks WHERE year = ?`, year)
+	return err
+}
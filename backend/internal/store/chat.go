@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// ChatStore holds the AI chat history and the last AI suggestion of each year
+type ChatStore struct {
+	q DBTX
+}
+
+// Add appends a message to the chat history of a year, with the model that
+// answered it, empty for the user's messages
+func (s *ChatStore) Add(ctx context.Context, year int, role, content, model string) error {
+	_, err := s.q.ExecContext(ctx, `INSERT INTO chat_history (year, role, content, model) VALUES (?, ?, ?, ?)`, year, role, content, model)
+	return err
+}
+
+// History returns the chat history of a year, oldest first
+func (s *ChatStore) History(ctx context.Context, year int) ([]models.ChatMessage, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id, year, role, content, COALESCE(model, ''), created_at FROM chat_history WHERE year = ? ORDER BY created_at ASC`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []models.ChatMessage
+	for rows.Next() {
+		var msg models.ChatMessage
+		if err := rows.Scan(&msg.ID, &msg.Year, &msg.Role, &msg.Content, &msg.Model, &msg.CreatedAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
+
+// Recent returns the last limit messages of a year, oldest first
+func (s *ChatStore) Recent(ctx context.Context, year, limit int) ([]models.ChatMessage, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id, year, role, content, COALESCE(model, ''), created_at FROM chat_history WHERE year = ? ORDER BY created_at DESC LIMIT ?`, year, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []models.ChatMessage
+	for rows.Next() {
+		var msg models.ChatMessage
+		if err := rows.Scan(&msg.ID, &msg.Year, &msg.Role, &msg.Content, &msg.Model, &msg.CreatedAt); err != nil {
+			return nil, err
+		}
+		messages = append([]models.ChatMessage{msg}, messages...)
+	}
+
+	return messages, rows.Err()
+}
+
+// Clear deletes the chat history of a year
+func (s *ChatStore) Clear(ctx context.Context, year int) error {
+	_, err := s.q.ExecContext(ctx, `DELETE FROM chat_history WHERE year = ?`, year)
+	return err
+}
+
+// Suggestion returns the stored AI suggestion of a year in a language
+func (s *ChatStore) Suggestion(ctx context.Context, year int, language string) (models.AISuggestion, error) {
+	var suggestion models.AISuggestion
+	err := s.q.QueryRowContext(ctx, `SELECT year, language, input_hash, suggestion, COALESCE(model, ''), created_at FROM ai_suggestions WHERE year = ? AND language = ?`, year, language).
+		Scan(&suggestion.Year, &suggestion.Language, &suggestion.InputHash, &suggestion.Suggestion, &suggestion.Model, &suggestion.CreatedAt)
+	return suggestion, notFound(err)
+}
+
+// SaveSuggestion stores an AI suggestion, replacing the previous one for the
+// same year and language
+func (s *ChatStore) SaveSuggestion(ctx context.Context, suggestion models.AISuggestion) error {
+	_, err := s.q.ExecContext(ctx, `INSERT OR REPLACE INTO ai_suggestions (year, language, input_hash, suggestion, model) VALUES (?, ?, ?, ?, ?)`,
+		suggestion.Year, suggestion.Language, suggestion.InputHash, suggestion.Suggestion, suggestion.Model)
+	return err
+}
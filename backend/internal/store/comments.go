@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// CommentStore holds the comments on vacation days and blocks
+type CommentStore struct {
+	q DBTX
+}
+
+const commentColumns = `id, year, date, target, parent_id, author, body, created_at, updated_at`
+
+func scanComment(row rowScanner) (models.Comment, error) {
+	var c models.Comment
+	var parentID sql.NullInt64
+	err := row.Scan(&c.ID, &c.Year, &c.Date, &c.Target, &parentID, &c.Author, &c.Body, &c.CreatedAt, &c.UpdatedAt)
+	if parentID.Valid {
+		c.ParentID = &parentID.Int64
+	}
+	return c, err
+}
+
+// List returns the comments of a year, or of one date when date is not
+// empty, oldest first
+func (s *CommentStore) List(ctx context.Context, year int, date string) ([]models.Comment, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT `+commentColumns+` FROM comments
+		WHERE year = ? AND (? = '' OR date = ?) ORDER BY date, id`, year, date, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []models.Comment{}
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// Get returns a comment of a year, or ErrNotFound
+func (s *CommentStore) Get(ctx context.Context, year int, id int64) (models.Comment, error) {
+	c, err := scanComment(s.q.QueryRowContext(ctx, `SELECT `+commentColumns+` FROM comments WHERE year = ? AND id = ?`, year, id))
+	return c, notFound(err)
+}
+
+// Add stores a comment and returns it with its id and timestamps
+func (s *CommentStore) Add(ctx context.Context, c models.Comment) (models.Comment, error) {
+	result, err := s.q.ExecContext(ctx, `INSERT INTO comments (year, date, target, parent_id, author, body) VALUES (?, ?, ?, ?, ?, ?)`,
+		c.Year, c.Date, c.Target, c.ParentID, c.Author, c.Body)
+	if err != nil {
+		return c, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return c, err
+	}
+	return s.Get(ctx, c.Year, id)
+}
+
+// UpdateBody replaces the text of a comment, returning ErrNotFound when the
+// year has none with that id
+func (s *CommentStore) UpdateBody(ctx context.Context, year int, id int64, body string) (models.Comment, error) {
+	result, err := s.q.ExecContext(ctx, `UPDATE comments SET body = ?, updated_at = CURRENT_TIMESTAMP WHERE year = ? AND id = ?`, body, year, id)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return models.Comment{}, ErrNotFound
+	}
+	return s.Get(ctx, year, id)
+}
+
+// Delete removes a comment and the replies under it, returning how many
+// comments were removed, or ErrNotFound when the year has none with that id
+func (s *CommentStore) Delete(ctx context.Context, year int, id int64) (int64, error) {
+	result, err := s.q.ExecContext(ctx, `WITH RECURSIVE thread(id) AS (
+			SELECT id FROM comments WHERE year = ? AND id = ?
+			UNION SELECT c.id FROM comments c JOIN thread t ON c.parent_id = t.id
+		)
+		DELETE FROM comments WHERE id IN (SELECT id FROM thread)`, year, id)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return 0, ErrNotFound
+	}
+	return n, nil
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// CompStore holds the compensation day ledger
+type CompStore struct {
+	q DBTX
+}
+
+// List returns the ledger entries of a year, by date
+func (s *CompStore) List(ctx context.Context, year int) ([]models.CompDay, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id, year, date, kind, COALESCE(note, '') FROM comp_days WHERE year = ? ORDER BY date, kind`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.CompDay{}
+	for rows.Next() {
+		var e models.CompDay
+		if err := rows.Scan(&e.ID, &e.Year, &e.Date, &e.Kind, &e.Note); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// Add records a ledger entry, replacing the note of an existing one for the
+// same date and kind, and returns its ID
+func (s *CompStore) Add(ctx context.Context, e models.CompDay) (int64, error) {
+	result, err := s.q.ExecContext(ctx, `INSERT OR REPLACE INTO comp_days (year, date, kind, note) VALUES (?, ?, ?, ?)`,
+		e.Year, e.Date, e.Kind, e.Note)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// Delete removes a ledger entry of a year, returning ErrNotFound when there
+// is none with that ID
+func (s *CompStore) Delete(ctx context.Context, year int, id int64) error {
+	result, err := s.q.ExecContext(ctx, `DELETE FROM comp_days WHERE year = ? AND id = ?`, year, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Adjustments returns the balance adjustments of a year, by date
+func (s *CompStore) Adjustments(ctx context.Context, year int) ([]models.BalanceAdjustment, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id, year, date, days, kind, reason FROM balance_adjustments WHERE year = ? ORDER BY date, id`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	adjustments := []models.BalanceAdjustment{}
+	for rows.Next() {
+		var a models.BalanceAdjustment
+		if err := rows.Scan(&a.ID, &a.Year, &a.Date, &a.Days, &a.Kind, &a.Reason); err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, a)
+	}
+
+	return adjustments, rows.Err()
+}
+
+// AddAdjustment records a balance adjustment and returns its ID
+func (s *CompStore) AddAdjustment(ctx context.Context, a models.BalanceAdjustment) (int64, error) {
+	result, err := s.q.ExecContext(ctx, `INSERT INTO balance_adjustments (year, date, days, kind, reason) VALUES (?, ?, ?, ?, ?)`,
+		a.Year, a.Date, a.Days, a.Kind, a.Reason)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// DeleteAdjustment removes a balance adjustment of a year, returning
+// ErrNotFound when there is none with that ID
+func (s *CompStore) DeleteAdjustment(ctx context.Context, year int, id int64) error {
+	result, err := s.q.ExecContext(ctx, `DELETE FROM balance_adjustments WHERE year = ? AND id = ?`, year, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
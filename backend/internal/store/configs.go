@@ -0,0 +1,204 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// ConfigStore holds the per-year configuration, its dated work week changes
+// and the seniority rules for the vacation allowance
+type ConfigStore struct {
+	q DBTX
+}
+
+// configFields are the year_config columns that can be set one at a time
+var configFields = map[string]bool{
+	"vacation_days":         true,
+	"reserved_days":         true,
+	"optimization_strategy": true,
+	"work_week":             true,
+}
+
+// Get returns the config of a year with its work week changes, or
+// ErrNotFound when the year has none
+func (s *ConfigStore) Get(ctx context.Context, year int) (models.YearConfig, error) {
+	var config models.YearConfig
+	var workWeekJSON, workingHoursJSON, optionalJSON, weightsJSON, blackoutJSON, avoidJSON string
+
+	err := s.q.QueryRowContext(ctx, `SELECT id, year, vacation_days, COALESCE(reserved_days, 0), optimization_strategy, work_week, COALESCE(optimizer_notes, ''), COALESCE(align_school_breaks, FALSE), COALESCE(accounting_mode, 'days'), COALESCE(vacation_hours, 0), COALESCE(working_hours, '{}'), COALESCE(optional_holidays, '[]'), COALESCE(scoring_weights, '{}'), COALESCE(blackout_periods, '[]'), COALESCE(avoid_periods, '[]'), COALESCE(created_at, ''), COALESCE(updated_at, ''), COALESCE(version, 1) FROM year_config WHERE year = ?`, year).
+		Scan(&config.ID, &config.Year, &config.VacationDays, &config.ReservedDays, &config.OptimizationStrategy, &workWeekJSON, &config.OptimizerNotes, &config.AlignSchoolBreaks, &config.AccountingMode, &config.VacationHours, &workingHoursJSON, &optionalJSON, &weightsJSON, &blackoutJSON, &avoidJSON, &config.CreatedAt, &config.UpdatedAt, &config.Version)
+	if err != nil {
+		return config, notFound(err)
+	}
+
+	json.Unmarshal([]byte(workWeekJSON), &config.WorkWeek)
+	json.Unmarshal([]byte(workingHoursJSON), &config.WorkingHours)
+	json.Unmarshal([]byte(optionalJSON), &config.OptionalHolidays)
+	json.Unmarshal([]byte(weightsJSON), &config.ScoringWeights)
+	json.Unmarshal([]byte(blackoutJSON), &config.BlackoutPeriods)
+	json.Unmarshal([]byte(avoidJSON), &config.AvoidPeriods)
+
+	config.WorkWeekChanges, err = s.WorkWeekChanges(ctx, year)
+	if err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// Create stores the config of a year that has none yet
+func (s *ConfigStore) Create(ctx context.Context, config models.YearConfig) error {
+	workWeekJSON, _ := json.Marshal(config.WorkWeek)
+	workingHoursJSON, _ := json.Marshal(config.WorkingHours)
+	optionalJSON := optionalHolidaysJSON(config.OptionalHolidays)
+	weightsJSON, _ := json.Marshal(config.ScoringWeights)
+	blackoutJSON := blackoutPeriodsJSON(config.BlackoutPeriods)
+	avoidJSON := avoidPeriodsJSON(config.AvoidPeriods)
+	_, err := s.q.ExecContext(ctx, `INSERT INTO year_config (year, vacation_days, reserved_days, optimization_strategy, work_week, optimizer_notes, align_school_breaks, accounting_mode, vacation_hours, working_hours, optional_holidays, scoring_weights, blackout_periods, avoid_periods) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		config.Year, config.VacationDays, config.ReservedDays, config.OptimizationStrategy, string(workWeekJSON), config.OptimizerNotes, config.AlignSchoolBreaks, config.AccountingMode, config.VacationHours, string(workingHoursJSON), optionalJSON, string(weightsJSON), blackoutJSON, avoidJSON)
+	return err
+}
+
+// Update saves all the editable fields of a year's config and bumps its
+// version. A config with a version is only saved while the stored one still
+// has it, otherwise ErrVersionConflict is returned.
+func (s *ConfigStore) Update(ctx context.Context, config models.YearConfig) error {
+	workWeekJSON, _ := json.Marshal(config.WorkWeek)
+	workingHoursJSON, _ := json.Marshal(config.WorkingHours)
+	optionalJSON := optionalHolidaysJSON(config.OptionalHolidays)
+	weightsJSON, _ := json.Marshal(config.ScoringWeights)
+	blackoutJSON := blackoutPeriodsJSON(config.BlackoutPeriods)
+	avoidJSON := avoidPeriodsJSON(config.AvoidPeriods)
+	res, err := s.q.ExecContext(ctx, `UPDATE year_config SET vacation_days = ?, reserved_days = ?, optimization_strategy = ?, work_week = ?, optimizer_notes = ?, align_school_breaks = ?, accounting_mode = ?, vacation_hours = ?, working_hours = ?, optional_holidays = ?, scoring_weights = ?, blackout_periods = ?, avoid_periods = ?, version = COALESCE(version, 1) + 1, updated_at = CURRENT_TIMESTAMP WHERE year = ? AND (? = 0 OR COALESCE(version, 1) = ?)`,
+		config.VacationDays, config.ReservedDays, config.OptimizationStrategy, string(workWeekJSON), config.OptimizerNotes, config.AlignSchoolBreaks, config.AccountingMode, config.VacationHours, string(workingHoursJSON), optionalJSON, string(weightsJSON), blackoutJSON, avoidJSON, config.Year, config.Version, config.Version)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 && config.Version != 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// SetField updates a single config column of a year. Only the allowance,
+// reserved days, strategy and work week can be set this way.
+func (s *ConfigStore) SetField(ctx context.Context, year int, field string, value interface{}) error {
+	if !configFields[field] {
+		return fmt.Errorf("unknown config field %q", field)
+	}
+	_, err := s.q.ExecContext(ctx, fmt.Sprintf(`UPDATE year_config SET %s = ?, version = COALESCE(version, 1) + 1, updated_at = CURRENT_TIMESTAMP WHERE year = ?`, field), value, year)
+	return err
+}
+
+// Copy replaces the config of a year with the allowance, strategy, scoring
+// weights, work week, hours settings and optional holidays of another year's
+// config. The other fields are reset, and the version moves on from the
+// replaced config's.
+func (s *ConfigStore) Copy(ctx context.Context, year int, source models.YearConfig) error {
+	workWeekJSON, _ := json.Marshal(source.WorkWeek)
+	workingHoursJSON, _ := json.Marshal(source.WorkingHours)
+	optionalJSON := optionalHolidaysJSON(source.OptionalHolidays)
+	weightsJSON, _ := json.Marshal(source.ScoringWeights)
+	_, err := s.q.ExecContext(ctx, `INSERT OR REPLACE INTO year_config (year, vacation_days, optimization_strategy, work_week, accounting_mode, vacation_hours, working_hours, optional_holidays, scoring_weights, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, COALESCE((SELECT version FROM year_config WHERE year = ?), 0) + 1)`,
+		year, source.VacationDays, source.OptimizationStrategy, string(workWeekJSON), source.AccountingMode, source.VacationHours, string(workingHoursJSON), optionalJSON, string(weightsJSON), year)
+	return err
+}
+
+// blackoutPeriodsJSON encodes blackout periods, with none as []
+func blackoutPeriodsJSON(periods []models.BlackoutPeriod) string {
+	if periods == nil {
+		periods = []models.BlackoutPeriod{}
+	}
+	encoded, _ := json.Marshal(periods)
+	return string(encoded)
+}
+
+// avoidPeriodsJSON encodes avoid periods, with none as []
+func avoidPeriodsJSON(periods []models.AvoidPeriod) string {
+	if periods == nil {
+		periods = []models.AvoidPeriod{}
+	}
+	encoded, _ := json.Marshal(periods)
+	return string(encoded)
+}
+
+// optionalHolidaysJSON encodes enabled optional holidays, with none as []
+func optionalHolidaysJSON(keys []string) string {
+	if keys == nil {
+		keys = []string{}
+	}
+	encoded, _ := json.Marshal(keys)
+	return string(encoded)
+}
+
+// WorkWeekChanges returns the work week changes of a year, oldest first
+func (s *ConfigStore) WorkWeekChanges(ctx context.Context, year int) ([]models.WorkWeekChange, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id, year, effective_from, work_week FROM work_week_changes WHERE year = ? ORDER BY effective_from`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []models.WorkWeekChange{}
+	for rows.Next() {
+		var change models.WorkWeekChange
+		var workWeekJSON string
+		if err := rows.Scan(&change.ID, &change.Year, &change.EffectiveFrom, &workWeekJSON); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(workWeekJSON), &change.WorkWeek)
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+// SetWorkWeekChange stores a work week change, replacing one on the same date
+func (s *ConfigStore) SetWorkWeekChange(ctx context.Context, change models.WorkWeekChange) error {
+	workWeekJSON, _ := json.Marshal(change.WorkWeek)
+	_, err := s.q.ExecContext(ctx, `INSERT OR REPLACE INTO work_week_changes (year, effective_from, work_week) VALUES (?, ?, ?)`,
+		change.Year, change.EffectiveFrom, string(workWeekJSON))
+	return err
+}
+
+// RemoveWorkWeekChange deletes the work week change starting on a date
+func (s *ConfigStore) RemoveWorkWeekChange(ctx context.Context, year int, effectiveFrom string) error {
+	_, err := s.q.ExecContext(ctx, `DELETE FROM work_week_changes WHERE year = ? AND effective_from = ?`, year, effectiveFrom)
+	return err
+}
+
+// SeniorityRules returns the seniority rules, fewest years first
+func (s *ConfigStore) SeniorityRules(ctx context.Context) ([]models.SeniorityRule, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id, years_of_service, extra_days FROM seniority_rules ORDER BY years_of_service`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.SeniorityRule{}
+	for rows.Next() {
+		var r models.SeniorityRule
+		if err := rows.Scan(&r.ID, &r.YearsOfService, &r.ExtraDays); err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, rows.Err()
+}
+
+// ReplaceSeniorityRules deletes the seniority rules and stores new ones. Run
+// it in a transaction so a failure keeps the old rules.
+func (s *ConfigStore) ReplaceSeniorityRules(ctx context.Context, rules []models.SeniorityRule) error {
+	if _, err := s.q.ExecContext(ctx, `DELETE FROM seniority_rules`); err != nil {
+		return err
+	}
+	for _, r := range rules {
+		if _, err := s.q.ExecContext(ctx, `INSERT INTO seniority_rules (years_of_service, extra_days) VALUES (?, ?)`, r.YearsOfService, r.ExtraDays); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// EventStore holds the personal events of each year
+type EventStore struct {
+	q DBTX
+}
+
+const eventColumns = `id, year, date, name, notes, weight, created_at, updated_at`
+
+func scanEvent(row interface{ Scan(...interface{}) error }) (models.PersonalEvent, error) {
+	var e models.PersonalEvent
+	err := row.Scan(&e.ID, &e.Year, &e.Date, &e.Name, &e.Notes, &e.Weight, &e.CreatedAt, &e.UpdatedAt)
+	return e, err
+}
+
+// List returns the personal events of a year, by date
+func (s *EventStore) List(ctx context.Context, year int) ([]models.PersonalEvent, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT `+eventColumns+` FROM personal_events WHERE year = ? ORDER BY date, id`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.PersonalEvent{}
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// Get returns a personal event of a year, or ErrNotFound
+func (s *EventStore) Get(ctx context.Context, year int, id int64) (models.PersonalEvent, error) {
+	e, err := scanEvent(s.q.QueryRowContext(ctx, `SELECT `+eventColumns+` FROM personal_events WHERE year = ? AND id = ?`, year, id))
+	return e, notFound(err)
+}
+
+// Create stores a new personal event and returns it with its id
+func (s *EventStore) Create(ctx context.Context, e models.PersonalEvent) (models.PersonalEvent, error) {
+	result, err := s.q.ExecContext(ctx, `INSERT INTO personal_events (year, date, name, notes, weight) VALUES (?, ?, ?, ?, ?)`,
+		e.Year, e.Date, e.Name, e.Notes, e.Weight)
+	if err != nil {
+		return e, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return e, err
+	}
+	return s.Get(ctx, e.Year, id)
+}
+
+// Update saves the editable fields of a personal event, returning
+// ErrNotFound when the year has no event with its id
+func (s *EventStore) Update(ctx context.Context, e models.PersonalEvent) (models.PersonalEvent, error) {
+	result, err := s.q.ExecContext(ctx, `UPDATE personal_events SET date = ?, name = ?, notes = ?, weight = ?, updated_at = CURRENT_TIMESTAMP WHERE year = ? AND id = ?`,
+		e.Date, e.Name, e.Notes, e.Weight, e.Year, e.ID)
+	if err != nil {
+		return e, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return e, ErrNotFound
+	}
+	return s.Get(ctx, e.Year, e.ID)
+}
+
+// Delete removes a personal event of a year, returning ErrNotFound when
+// there is no event with that id
+func (s *EventStore) Delete(ctx context.Context, year int, id int64) error {
+	result, err := s.q.ExecContext(ctx, `DELETE FROM personal_events WHERE year = ? AND id = ?`, year, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"context"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// FamilyStore holds the household members and their school or childcare
+// closures
+type FamilyStore struct {
+	q DBTX
+}
+
+const memberColumns = `id, name, school_district, plan_around, created_at`
+
+func scanMember(row rowScanner) (models.FamilyMember, error) {
+	var m models.FamilyMember
+	err := row.Scan(&m.ID, &m.Name, &m.SchoolDistrict, &m.PlanAround, &m.CreatedAt)
+	return m, err
+}
+
+// Members returns the family members, oldest first, without their closures
+func (s *FamilyStore) Members(ctx context.Context) ([]models.FamilyMember, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT `+memberColumns+` FROM family_members ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.FamilyMember{}
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// GetMember returns a family member without closures, or ErrNotFound
+func (s *FamilyStore) GetMember(ctx context.Context, id int64) (models.FamilyMember, error) {
+	m, err := scanMember(s.q.QueryRowContext(ctx, `SELECT `+memberColumns+` FROM family_members WHERE id = ?`, id))
+	return m, notFound(err)
+}
+
+// CreateMember stores a family member and returns it with its id
+func (s *FamilyStore) CreateMember(ctx context.Context, m models.FamilyMember) (models.FamilyMember, error) {
+	result, err := s.q.ExecContext(ctx, `INSERT INTO family_members (name, school_district, plan_around) VALUES (?, ?, ?)`,
+		m.Name, m.SchoolDistrict, m.PlanAround)
+	if err != nil {
+		return m, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return m, err
+	}
+	return s.GetMember(ctx, id)
+}
+
+// UpdateMember saves the editable fields of a family member, returning
+// ErrNotFound when there is none with its id
+func (s *FamilyStore) UpdateMember(ctx context.Context, m models.FamilyMember) (models.FamilyMember, error) {
+	result, err := s.q.ExecContext(ctx, `UPDATE family_members SET name = ?, school_district = ?, plan_around = ? WHERE id = ?`,
+		m.Name, m.SchoolDistrict, m.PlanAround, m.ID)
+	if err != nil {
+		return m, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return m, ErrNotFound
+	}
+	return s.GetMember(ctx, m.ID)
+}
+
+// DeleteMember removes a family member and their closures, returning
+// ErrNotFound when there is none with that id
+func (s *FamilyStore) DeleteMember(ctx context.Context, id int64) error {
+	result, err := s.q.ExecContext(ctx, `DELETE FROM family_members WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	_, err = s.q.ExecContext(ctx, `DELETE FROM family_closures WHERE member_id = ?`, id)
+	return err
+}
+
+// Closures returns the closures of every member overlapping from..to, both
+// YYYY-MM-DD, by start date
+func (s *FamilyStore) Closures(ctx context.Context, from, to string) ([]models.FamilyClosure, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id, member_id, name, start_date, end_date FROM family_closures
+		WHERE start_date <= ? AND end_date >= ? ORDER BY start_date, id`, to, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	closures := []models.FamilyClosure{}
+	for rows.Next() {
+		c := models.FamilyClosure{Source: models.ClosureCustom}
+		if err := rows.Scan(&c.ID, &c.MemberID, &c.Name, &c.StartDate, &c.EndDate); err != nil {
+			return nil, err
+		}
+		closures = append(closures, c)
+	}
+	return closures, rows.Err()
+}
+
+// AddClosure stores a closure of a family member and returns it with its id
+func (s *FamilyStore) AddClosure(ctx context.Context, c models.FamilyClosure) (models.FamilyClosure, error) {
+	result, err := s.q.ExecContext(ctx, `INSERT INTO family_closures (member_id, name, start_date, end_date) VALUES (?, ?, ?, ?)`,
+		c.MemberID, c.Name, c.StartDate, c.EndDate)
+	if err != nil {
+		return c, err
+	}
+	c.ID, err = result.LastInsertId()
+	c.Source = models.ClosureCustom
+	return c, err
+}
+
+// DeleteClosure removes a closure of a family member, returning ErrNotFound
+// when the member has none with that id
+func (s *FamilyStore) DeleteClosure(ctx context.Context, memberID, id int64) error {
+	result, err := s.q.ExecContext(ctx, `DELETE FROM family_closures WHERE member_id = ? AND id = ?`, memberID, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
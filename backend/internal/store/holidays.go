@@ -0,0 +1,180 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/holidays"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// HolidayStore holds the cached holidays, the holidays marked as worked and
+// the holiday overrides
+type HolidayStore struct {
+	q DBTX
+}
+
+// Cache stores holidays of a year that are not stored yet
+func (s *HolidayStore) Cache(ctx context.Context, year int, holidayList []holidays.PortugueseHoliday) error {
+	for _, hol := range holidayList {
+		if _, err := s.q.ExecContext(ctx, `INSERT OR IGNORE INTO holidays (year, date, name, type, source) VALUES (?, ?, ?, ?, ?)`,
+			year, hol.Date, hol.Name, hol.Type, hol.Source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ChangeStampFormat stamps holiday changes. Unlike RFC 3339 with fractional
+// seconds it has a fixed width, so stamps compare as text.
+const ChangeStampFormat = "2006-01-02T15:04:05.000000Z"
+
+// RecordChanges compares the holidays of a year with the ones last recorded
+// and stamps those added, changed or removed with now. Removed holidays are
+// kept as tombstones. Run it in a transaction so concurrent calls don't
+// stamp the same change twice.
+func (s *HolidayStore) RecordChanges(ctx context.Context, year int, holidayList []holidays.PortugueseHoliday, now time.Time) error {
+	type key struct{ date, typ, location string }
+	rows, err := s.q.QueryContext(ctx, `SELECT date, type, location, name, observed_for, deleted FROM holiday_changes WHERE year = ?`, year)
+	if err != nil {
+		return err
+	}
+	recorded := make(map[key]models.HolidayChange)
+	for rows.Next() {
+		var c models.HolidayChange
+		if err := rows.Scan(&c.Date, &c.Type, &c.Location, &c.Name, &c.ObservedFor, &c.Deleted); err != nil {
+			rows.Close()
+			return err
+		}
+		recorded[key{c.Date, c.Type, c.Location}] = c
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	stamp := now.UTC().Format(ChangeStampFormat)
+	current := make(map[key]bool)
+	for _, hol := range holidayList {
+		k := key{hol.Date, hol.Type, hol.Location}
+		current[k] = true
+		if c, ok := recorded[k]; ok && !c.Deleted && c.Name == hol.Name && c.ObservedFor == hol.ObservedFor {
+			continue
+		}
+		if _, err := s.q.ExecContext(ctx, `INSERT INTO holiday_changes (year, date, type, location, name, observed_for, deleted, changed_at)
+			VALUES (?, ?, ?, ?, ?, ?, FALSE, ?)
+			ON CONFLICT(year, date, type, location) DO UPDATE SET name = excluded.name, observed_for = excluded.observed_for, deleted = FALSE, changed_at = excluded.changed_at`,
+			year, hol.Date, hol.Type, hol.Location, hol.Name, hol.ObservedFor, stamp); err != nil {
+			return err
+		}
+	}
+
+	for k, c := range recorded {
+		if current[k] || c.Deleted {
+			continue
+		}
+		if _, err := s.q.ExecContext(ctx, `UPDATE holiday_changes SET deleted = TRUE, changed_at = ? WHERE year = ? AND date = ? AND type = ? AND location = ?`,
+			stamp, year, k.date, k.typ, k.location); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ChangesSince returns the holidays of a year added, changed or removed
+// after since, by date
+func (s *HolidayStore) ChangesSince(ctx context.Context, year int, since time.Time) ([]models.HolidayChange, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT date, type, location, name, observed_for, deleted, changed_at FROM holiday_changes
+		WHERE year = ? AND changed_at > ? ORDER BY date, type, location`, year, since.UTC().Format(ChangeStampFormat))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []models.HolidayChange{}
+	for rows.Next() {
+		var c models.HolidayChange
+		if err := rows.Scan(&c.Date, &c.Type, &c.Location, &c.Name, &c.ObservedFor, &c.Deleted, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// Worked returns the holidays marked as worked in a year, by date
+func (s *HolidayStore) Worked(ctx context.Context, year int) ([]models.WorkedHoliday, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id, year, date, name, COALESCE(note, '') FROM worked_holidays WHERE year = ? ORDER BY date`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	worked := []models.WorkedHoliday{}
+	for rows.Next() {
+		var w models.WorkedHoliday
+		if err := rows.Scan(&w.ID, &w.Year, &w.Date, &w.Name, &w.Note); err != nil {
+			return nil, err
+		}
+		worked = append(worked, w)
+	}
+
+	return worked, rows.Err()
+}
+
+// CountWorked returns the number of holidays worked in a year
+func (s *HolidayStore) CountWorked(ctx context.Context, year int) (int, error) {
+	var count int
+	err := s.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM worked_holidays WHERE year = ?`, year).Scan(&count)
+	return count, err
+}
+
+// AddWorked marks a holiday as worked, replacing the note of an existing one
+func (s *HolidayStore) AddWorked(ctx context.Context, w models.WorkedHoliday) error {
+	_, err := s.q.ExecContext(ctx, `INSERT OR REPLACE INTO worked_holidays (year, date, name, note) VALUES (?, ?, ?, ?)`,
+		w.Year, w.Date, w.Name, w.Note)
+	return err
+}
+
+// RemoveWorked unmarks a worked holiday
+func (s *HolidayStore) RemoveWorked(ctx context.Context, year int, date string) error {
+	_, err := s.q.ExecContext(ctx, `DELETE FROM worked_holidays WHERE year = ? AND date = ?`, year, date)
+	return err
+}
+
+// Overrides returns the holiday overrides of a year, by date
+func (s *HolidayStore) Overrides(ctx context.Context, year int) ([]models.HolidayOverride, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id, year, date, action, new_date, name, note, created_at FROM holiday_overrides WHERE year = ? ORDER BY date`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := []models.HolidayOverride{}
+	for rows.Next() {
+		var o models.HolidayOverride
+		if err := rows.Scan(&o.ID, &o.Year, &o.Date, &o.Action, &o.NewDate, &o.Name, &o.Note, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, o)
+	}
+	return overrides, rows.Err()
+}
+
+// SetOverride stores the override of a holiday, replacing an existing one
+func (s *HolidayStore) SetOverride(ctx context.Context, o models.HolidayOverride) error {
+	_, err := s.q.ExecContext(ctx, `INSERT OR REPLACE INTO holiday_overrides (year, date, action, new_date, name, note) VALUES (?, ?, ?, ?, ?, ?)`,
+		o.Year, o.Date, o.Action, o.NewDate, o.Name, o.Note)
+	return err
+}
+
+// RemoveOverride drops the override of a holiday, reporting whether there
+// was one
+func (s *HolidayStore) RemoveOverride(ctx context.Context, year int, date string) (bool, error) {
+	result, err := s.q.ExecContext(ctx, `DELETE FROM holiday_overrides WHERE year = ? AND date = ?`, year, date)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// PolicyStore holds the booking rules and the vacation days they propose
+type PolicyStore struct {
+	q DBTX
+}
+
+// Rules returns the booking rules in the order they were saved
+func (s *PolicyStore) Rules(ctx context.Context) ([]models.BookingRule, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id, name, kind, max_gap, holiday_weekdays, max_weeks, auto_apply, enabled
+		FROM booking_rules ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.BookingRule{}
+	for rows.Next() {
+		var r models.BookingRule
+		var weekdaysJSON string
+		if err := rows.Scan(&r.ID, &r.Name, &r.Kind, &r.MaxGap, &weekdaysJSON, &r.MaxWeeks, &r.AutoApply, &r.Enabled); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(weekdaysJSON), &r.HolidayWeekdays)
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+// ReplaceRules deletes the booking rules and stores new ones. Run it in a
+// transaction so a failure keeps the old rules.
+func (s *PolicyStore) ReplaceRules(ctx context.Context, rules []models.BookingRule) error {
+	if _, err := s.q.ExecContext(ctx, `DELETE FROM booking_rules`); err != nil {
+		return err
+	}
+	for _, r := range rules {
+		weekdays := r.HolidayWeekdays
+		if weekdays == nil {
+			weekdays = []string{}
+		}
+		weekdaysJSON, _ := json.Marshal(weekdays)
+		if _, err := s.q.ExecContext(ctx, `INSERT INTO booking_rules (name, kind, max_gap, holiday_weekdays, max_weeks, auto_apply, enabled)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			r.Name, r.Kind, r.MaxGap, string(weekdaysJSON), r.MaxWeeks, r.AutoApply, r.Enabled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const proposalColumns = `id, year, date, rule_id, rule_name, reason, status, created_at`
+
+func scanProposal(row rowScanner) (models.BookingProposal, error) {
+	var p models.BookingProposal
+	err := row.Scan(&p.ID, &p.Year, &p.Date, &p.RuleID, &p.RuleName, &p.Reason, &p.Status, &p.CreatedAt)
+	return p, err
+}
+
+// Proposals returns the proposals of a year by date, of every status
+func (s *PolicyStore) Proposals(ctx context.Context, year int) ([]models.BookingProposal, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT `+proposalColumns+` FROM booking_proposals WHERE year = ? ORDER BY date`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	proposals := []models.BookingProposal{}
+	for rows.Next() {
+		p, err := scanProposal(rows)
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, p)
+	}
+	return proposals, rows.Err()
+}
+
+// GetProposal returns a proposal of a year, or ErrNotFound
+func (s *PolicyStore) GetProposal(ctx context.Context, year int, id int64) (models.BookingProposal, error) {
+	p, err := scanProposal(s.q.QueryRowContext(ctx, `SELECT `+proposalColumns+` FROM booking_proposals WHERE year = ? AND id = ?`, year, id))
+	return p, notFound(err)
+}
+
+// AddProposal stores a pending proposal unless the year already has one for
+// its date, whatever its status, and reports whether it was added
+func (s *PolicyStore) AddProposal(ctx context.Context, p models.BookingProposal) (bool, error) {
+	result, err := s.q.ExecContext(ctx, `INSERT INTO booking_proposals (year, date, rule_id, rule_name, reason, status)
+		VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT(year, date) DO NOTHING`,
+		p.Year, p.Date, p.RuleID, p.RuleName, p.Reason, models.ProposalPending)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// SetProposalStatus changes the status of a proposal, returning ErrNotFound
+// when the year has none with that id
+func (s *PolicyStore) SetProposalStatus(ctx context.Context, year int, id int64, status string) error {
+	result, err := s.q.ExecContext(ctx, `UPDATE booking_proposals SET status = ? WHERE year = ? AND id = ?`, status, year, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteProposal removes a proposal
+func (s *PolicyStore) DeleteProposal(ctx context.Context, id int64) error {
+	_, err := s.q.ExecContext(ctx, `DELETE FROM booking_proposals WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// maxPrepared caps the statements kept prepared. The stores use a fixed set
+// of queries, so the cap is only reached if a caller builds queries from
+// data; those then run unprepared.
+const maxPrepared = 256
+
+// preparedDB runs queries through statements prepared on first use, so the
+// queries behind every calendar request are parsed and planned once instead
+// of on each call. database/sql prepares them again on each pooled
+// connection as needed.
+type preparedDB struct {
+	db    *sql.DB
+	mu    sync.RWMutex
+	stmts map[string]*sql.Stmt
+}
+
+func newPreparedDB(db *sql.DB) *preparedDB {
+	return &preparedDB{db: db, stmts: make(map[string]*sql.Stmt)}
+}
+
+// stmt returns the prepared statement of a query, or nil when it can't be
+// prepared, in which case the caller runs the query directly and gets the
+// error from there
+func (p *preparedDB) stmt(ctx context.Context, query string) *sql.Stmt {
+	p.mu.RLock()
+	stmt := p.stmts[query]
+	p.mu.RUnlock()
+	if stmt != nil {
+		return stmt
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if stmt := p.stmts[query]; stmt != nil {
+		return stmt
+	}
+	if len(p.stmts) >= maxPrepared {
+		return nil
+	}
+	stmt, err := p.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil
+	}
+	p.stmts[query] = stmt
+	return stmt
+}
+
+func (p *preparedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if stmt := p.stmt(ctx, query); stmt != nil {
+		return stmt.ExecContext(ctx, args...)
+	}
+	return p.db.ExecContext(ctx, query, args...)
+}
+
+func (p *preparedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if stmt := p.stmt(ctx, query); stmt != nil {
+		return stmt.QueryContext(ctx, args...)
+	}
+	return p.db.QueryContext(ctx, query, args...)
+}
+
+func (p *preparedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if stmt := p.stmt(ctx, query); stmt != nil {
+		return stmt.QueryRowContext(ctx, args...)
+	}
+	return p.db.QueryRowContext(ctx, query, args...)
+}
+
+// Close closes the prepared statements
+func (p *preparedDB) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for query, stmt := range p.stmts {
+		stmt.Close()
+		delete(p.stmts, query)
+	}
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// RequestStore holds the requests sent with an Idempotency-Key and their
+// responses
+type RequestStore struct {
+	q DBTX
+}
+
+// Begin records a request about to run under its key and reports true. When
+// the key is taken, it returns the request recorded under it and false
+// instead. Finished requests from before expired and unfinished ones from
+// before abandoned no longer hold their key.
+func (s *RequestStore) Begin(ctx context.Context, r models.IdempotentRequest, expired, abandoned time.Time) (models.IdempotentRequest, bool, error) {
+	_, err := s.q.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE scope = ? AND key = ? AND (created_at < ? OR (status = 0 AND created_at < ?))`,
+		r.Scope, r.Key, expired.UTC().Format(time.RFC3339), abandoned.UTC().Format(time.RFC3339))
+	if err != nil {
+		return r, false, err
+	}
+
+	result, err := s.q.ExecContext(ctx, `INSERT OR IGNORE INTO idempotency_keys (scope, key, method, path, request_hash, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		r.Scope, r.Key, r.Method, r.Path, r.RequestHash, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return r, false, err
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		return r, true, nil
+	}
+
+	var existing models.IdempotentRequest
+	err = s.q.QueryRowContext(ctx, `SELECT scope, key, method, path, request_hash, status, content_type, COALESCE(body, ''), created_at FROM idempotency_keys WHERE scope = ? AND key = ?`, r.Scope, r.Key).
+		Scan(&existing.Scope, &existing.Key, &existing.Method, &existing.Path, &existing.RequestHash, &existing.Status, &existing.ContentType, &existing.Body, &existing.CreatedAt)
+	return existing, false, notFound(err)
+}
+
+// Complete stores the response of a request begun under a key
+func (s *RequestStore) Complete(ctx context.Context, scope, key string, status int, contentType string, body []byte) error {
+	_, err := s.q.ExecContext(ctx, `UPDATE idempotency_keys SET status = ?, content_type = ?, body = ? WHERE scope = ? AND key = ?`,
+		status, contentType, body, scope, key)
+	return err
+}
+
+// Release frees a key, so a retry runs the request again
+func (s *RequestStore) Release(ctx context.Context, scope, key string) error {
+	_, err := s.q.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE scope = ? AND key = ?`, scope, key)
+	return err
+}
+
+// DeleteBefore removes the requests older than t and returns how many
+func (s *RequestStore) DeleteBefore(ctx context.Context, t time.Time) (int64, error) {
+	result, err := s.q.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE created_at < ?`, t.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
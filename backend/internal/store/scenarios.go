@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// ScenarioStore holds the named plans of each year. The active plan lives in
+// the vacation_days and optimal_vacations tables; the others are snapshots in
+// scenario_days.
+type ScenarioStore struct {
+	q DBTX
+}
+
+// Count returns the number of scenarios of a year
+func (s *ScenarioStore) Count(ctx context.Context, year int) (int, error) {
+	var count int
+	err := s.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM scenarios WHERE year = ?`, year).Scan(&count)
+	return count, err
+}
+
+// Create stores a new scenario and returns its id
+func (s *ScenarioStore) Create(ctx context.Context, year int, name string, active bool) (int64, error) {
+	result, err := s.q.ExecContext(ctx, `INSERT INTO scenarios (year, name, is_active) VALUES (?, ?, ?)`, year, name, active)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// EnsureActive creates an active scenario with the given name for a year
+// that has no scenarios yet
+func (s *ScenarioStore) EnsureActive(ctx context.Context, year int, name string) error {
+	count, err := s.Count(ctx, year)
+	if err != nil || count > 0 {
+		return err
+	}
+
+	_, err = s.q.ExecContext(ctx, `INSERT OR IGNORE INTO scenarios (year, name, is_active) VALUES (?, ?, TRUE)`, year, name)
+	return err
+}
+
+// List returns the scenarios of a year with their day counts, oldest first
+func (s *ScenarioStore) List(ctx context.Context, year int) ([]models.Scenario, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id FROM scenarios WHERE year = ? ORDER BY id`, year)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	scenarios := []models.Scenario{}
+	for _, id := range ids {
+		scenario, err := s.Get(ctx, year, id)
+		if err != nil {
+			return nil, err
+		}
+		scenarios = append(scenarios, scenario)
+	}
+
+	return scenarios, nil
+}
+
+// Get returns a scenario of a year with its day counts, or ErrNotFound
+func (s *ScenarioStore) Get(ctx context.Context, year int, id int64) (models.Scenario, error) {
+	var sc models.Scenario
+	err := s.q.QueryRowContext(ctx, `SELECT id, year, name, is_active, created_at, updated_at FROM scenarios WHERE year = ? AND id = ?`, year, id).
+		Scan(&sc.ID, &sc.Year, &sc.Name, &sc.IsActive, &sc.CreatedAt, &sc.UpdatedAt)
+	if err != nil {
+		return sc, notFound(err)
+	}
+
+	if sc.IsActive {
+		s.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM vacation_days WHERE year = ?`, year).Scan(&sc.ManualDays)
+		s.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM optimal_vacations WHERE year = ?`, year).Scan(&sc.OptimalDays)
+	} else {
+		s.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM scenario_days WHERE scenario_id = ? AND kind = 'manual'`, id).Scan(&sc.ManualDays)
+		s.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM scenario_days WHERE scenario_id = ? AND kind = 'optimal'`, id).Scan(&sc.OptimalDays)
+	}
+
+	return sc, nil
+}
+
+// ActiveID returns the id of the active scenario of a year, or ErrNotFound
+func (s *ScenarioStore) ActiveID(ctx context.Context, year int) (int64, error) {
+	var id int64
+	err := s.q.QueryRowContext(ctx, `SELECT id FROM scenarios WHERE year = ? AND is_active = TRUE`, year).Scan(&id)
+	return id, notFound(err)
+}
+
+// SetActive marks a scenario as the only active one of its year
+func (s *ScenarioStore) SetActive(ctx context.Context, year int, id int64) error {
+	_, err := s.q.ExecContext(ctx, `UPDATE scenarios SET is_active = (id = ?), updated_at = CURRENT_TIMESTAMP WHERE year = ?`, id, year)
+	return err
+}
+
+// Delete removes a scenario and its snapshot
+func (s *ScenarioStore) Delete(ctx context.Context, id int64) error {
+	if _, err := s.q.ExecContext(ctx, `DELETE FROM scenario_days WHERE scenario_id = ?`, id); err != nil {
+		return err
+	}
+	_, err := s.q.ExecContext(ctx, `DELETE FROM scenarios WHERE id = ?`, id)
+	return err
+}
+
+// CopyDays copies the snapshot of one scenario into another
+func (s *ScenarioStore) CopyDays(ctx context.Context, fromID, toID int64) error {
+	_, err := s.q.ExecContext(ctx, `INSERT INTO scenario_days (scenario_id, date, kind, note, block_id, consecutive_days)
+		SELECT ?, date, kind, note, block_id, consecutive_days FROM scenario_days WHERE scenario_id = ?`, toID, fromID)
+	return err
+}
+
+// Snapshot saves the working vacation days of a year into a scenario
+func (s *ScenarioStore) Snapshot(ctx context.Context, year int, id int64) error {
+	if _, err := s.q.ExecContext(ctx, `DELETE FROM scenario_days WHERE scenario_id = ?`, id); err != nil {
+		return err
+	}
+
+	if _, err := s.q.ExecContext(ctx, `INSERT INTO scenario_days (scenario_id, date, kind, note)
+		SELECT ?, date, 'manual', note FROM vacation_days WHERE year = ?`, id, year); err != nil {
+		return err
+	}
+
+	_, err := s.q.ExecContext(ctx, `INSERT INTO scenario_days (scenario_id, date, kind, block_id, consecutive_days)
+		SELECT ?, date, 'optimal', block_id, consecutive_days FROM optimal_vacations WHERE year = ?`, id, year)
+	return err
+}
+
+// Restore replaces the working vacation days of a year from a date on, ""
+// for the whole year, with a scenario's snapshot. Locked days are kept as
+// they are.
+func (s *ScenarioStore) Restore(ctx context.Context, year int, id int64, from string) error {
+	if _, err := s.q.ExecContext(ctx, `DELETE FROM vacation_days WHERE year = ? AND date >= ?
+		AND date NOT IN (SELECT date FROM locked_dates WHERE year = ?)`, year, from, year); err != nil {
+		return err
+	}
+	if _, err := s.q.ExecContext(ctx, `DELETE FROM optimal_vacations WHERE year = ? AND date >= ?
+		AND date NOT IN (SELECT date FROM locked_dates WHERE year = ?)`, year, from, year); err != nil {
+		return err
+	}
+
+	if _, err := s.q.ExecContext(ctx, `INSERT INTO vacation_days (year, date, is_manual, note)
+		SELECT ?, date, TRUE, note FROM scenario_days WHERE scenario_id = ? AND kind = 'manual' AND date >= ?
+		AND date NOT IN (SELECT date FROM locked_dates WHERE year = ?)`, year, id, from, year); err != nil {
+		return err
+	}
+
+	_, err := s.q.ExecContext(ctx, `INSERT INTO optimal_vacations (year, date, block_id, consecutive_days)
+		SELECT ?, date, block_id, consecutive_days FROM scenario_days WHERE scenario_id = ? AND kind = 'optimal' AND date >= ?
+		AND date NOT IN (SELECT date FROM locked_dates WHERE year = ?)`, year, id, from, year)
+	return err
+}
@@ -0,0 +1,137 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// settingsCacheTTL bounds how long values written outside the store (by
+// another process) can stay stale
+const settingsCacheTTL = time.Minute
+
+// settingsCache keeps every setting in memory, since handlers read settings
+// on nearly every request
+type settingsCache struct {
+	mu         sync.RWMutex
+	values     map[string]string
+	loadedAt   time.Time
+	generation uint64 // Bumped on invalidation, so a load that raced a write is not cached
+}
+
+// get returns the cached values, or the current generation to pass to set
+// after loading them
+func (c *settingsCache) get() (map[string]string, uint64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.values == nil || time.Since(c.loadedAt) > settingsCacheTTL {
+		return nil, c.generation, false
+	}
+	return c.values, c.generation, true
+}
+
+func (c *settingsCache) set(values map[string]string, generation uint64) {
+	c.mu.Lock()
+	if generation == c.generation {
+		c.values = values
+		c.loadedAt = time.Now()
+	}
+	c.mu.Unlock()
+}
+
+func (c *settingsCache) invalidate() {
+	c.mu.Lock()
+	c.values = nil
+	c.generation++
+	c.mu.Unlock()
+}
+
+// SettingsStore holds the application settings as key/value pairs. Reads
+// outside a transaction are served from a cache that is dropped on every
+// write.
+type SettingsStore struct {
+	q     DBTX
+	cache *settingsCache
+	inTx  bool
+	dirty bool // Written in this transaction, drop the cache after commit
+}
+
+// All returns every setting
+func (s *SettingsStore) All(ctx context.Context) (map[string]string, error) {
+	values, err := s.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Callers may modify the map
+	settings := make(map[string]string, len(values))
+	for key, value := range values {
+		settings[key] = value
+	}
+	return settings, nil
+}
+
+func (s *SettingsStore) all(ctx context.Context) (map[string]string, error) {
+	values, generation, ok := s.cache.get()
+	if ok && !s.inTx {
+		return values, nil
+	}
+
+	rows, err := s.q.QueryContext(ctx, `SELECT key, value FROM settings`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values = make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if !s.inTx {
+		s.cache.set(values, generation)
+	}
+	return values, nil
+}
+
+// Get returns a setting, or ErrNotFound when it is not set
+func (s *SettingsStore) Get(ctx context.Context, key string) (string, error) {
+	values, err := s.all(ctx)
+	if err != nil {
+		return "", err
+	}
+	value, ok := values[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// Value returns a setting, or "" when it is not set or cannot be read
+func (s *SettingsStore) Value(ctx context.Context, key string) string {
+	value, _ := s.Get(ctx, key)
+	return value
+}
+
+// Set stores a setting
+func (s *SettingsStore) Set(ctx context.Context, key, value string) error {
+	_, err := s.q.ExecContext(ctx, `INSERT OR REPLACE INTO settings (key, value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)`, key, value)
+	s.cache.invalidate()
+	if s.inTx {
+		s.dirty = true
+	}
+	return err
+}
+
+// Invalidate drops the cached settings, for callers that write settings
+// without going through the store
+func (s *SettingsStore) Invalidate() {
+	s.cache.invalidate()
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// ShareStore holds the tokens of read-only share links
+type ShareStore struct {
+	q DBTX
+}
+
+const shareColumns = `id, token, year, label, COALESCE(expires_at, ''), created_at`
+
+// Create stores a share link and returns it with its id and creation time.
+// ExpiresAt is an RFC 3339 time in UTC, empty for no expiry.
+func (s *ShareStore) Create(ctx context.Context, link models.ShareLink) (models.ShareLink, error) {
+	var expiresAt interface{}
+	if link.ExpiresAt != "" {
+		expiresAt = link.ExpiresAt
+	}
+
+	result, err := s.q.ExecContext(ctx, `INSERT INTO share_links (token, year, label, expires_at) VALUES (?, ?, ?, ?)`,
+		link.Token, link.Year, link.Label, expiresAt)
+	if err != nil {
+		return link, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return link, err
+	}
+
+	return scanShare(s.q.QueryRowContext(ctx, `SELECT `+shareColumns+` FROM share_links WHERE id = ?`, id))
+}
+
+// List returns all share links, newest first
+func (s *ShareStore) List(ctx context.Context) ([]models.ShareLink, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT `+shareColumns+` FROM share_links ORDER BY id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []models.ShareLink{}
+	for rows.Next() {
+		link, err := scanShare(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// Active returns the share link with a token unless it has expired, or ErrNotFound
+func (s *ShareStore) Active(ctx context.Context, token string) (models.ShareLink, error) {
+	link, err := scanShare(s.q.QueryRowContext(ctx, `SELECT `+shareColumns+` FROM share_links
+		WHERE token = ? AND (expires_at IS NULL OR expires_at > strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))`, token))
+	return link, notFound(err)
+}
+
+// Delete revokes a share link and reports whether it existed
+func (s *ShareStore) Delete(ctx context.Context, id int64) (bool, error) {
+	result, err := s.q.ExecContext(ctx, `DELETE FROM share_links WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteExpired removes the links that have expired and returns how many
+func (s *ShareStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := s.q.ExecContext(ctx, `DELETE FROM share_links WHERE expires_at IS NOT NULL AND expires_at <= strftime('%Y-%m-%dT%H:%M:%SZ', 'now')`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanShare(row rowScanner) (models.ShareLink, error) {
+	var link models.ShareLink
+	err := row.Scan(&link.ID, &link.Token, &link.Year, &link.Label, &link.ExpiresAt, &link.CreatedAt)
+	return link, err
+}
@@ -0,0 +1,126 @@
+// Package store holds the SQL behind the API handlers. Each table group has a
+// typed store with context-aware methods, so handlers never build queries and
+// the storage can change without touching them.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrNotFound is returned when a looked up row does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrVersionConflict is returned when a row changed since the version a
+// write expects was read
+var ErrVersionConflict = errors.New("changed since it was read, reload and try again")
+
+// DBTX is the subset of *sql.DB and *sql.Tx the stores need
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Store groups the typed stores over one database handle
+type Store struct {
+	db       *sql.DB // nil when the store is bound to a transaction
+	prepared *preparedDB
+
+	Vacations *VacationStore
+	Configs   *ConfigStore
+	Settings  *SettingsStore
+	Chat      *ChatStore
+	Scenarios *ScenarioStore
+	Holidays  *HolidayStore
+	Comp      *CompStore
+	Trips     *TripStore
+	Shares    *ShareStore
+	Tokens    *TokenStore
+	Family    *FamilyStore
+	Events    *EventStore
+	Policies  *PolicyStore
+	Comments  *CommentStore
+	Blocks    *BlockStore
+	Admin     *AdminStore
+	Audit     *AuditStore
+	Requests  *RequestStore
+}
+
+// New creates a store over a database. Queries outside transactions run as
+// prepared statements.
+func New(db *sql.DB) *Store {
+	prepared := newPreparedDB(db)
+	s := newStore(prepared, &SettingsStore{q: prepared, cache: &settingsCache{}})
+	s.db = db
+	s.prepared = prepared
+	return s
+}
+
+// Close releases the prepared statements, before the database is closed
+func (s *Store) Close() {
+	if s.prepared != nil {
+		s.prepared.Close()
+	}
+}
+
+func newStore(q DBTX, settings *SettingsStore) *Store {
+	return &Store{
+		Vacations: &VacationStore{q: q},
+		Configs:   &ConfigStore{q: q},
+		Settings:  settings,
+		Chat:      &ChatStore{q: q},
+		Scenarios: &ScenarioStore{q: q},
+		Holidays:  &HolidayStore{q: q},
+		Comp:      &CompStore{q: q},
+		Trips:     &TripStore{q: q},
+		Shares:    &ShareStore{q: q},
+		Tokens:    &TokenStore{q: q},
+		Family:    &FamilyStore{q: q},
+		Events:    &EventStore{q: q},
+		Policies:  &PolicyStore{q: q},
+		Comments:  &CommentStore{q: q},
+		Blocks:    &BlockStore{q: q},
+		Admin:     &AdminStore{q: q},
+		Audit:     &AuditStore{q: q},
+		Requests:  &RequestStore{q: q},
+	}
+}
+
+// InTx runs fn with stores bound to one transaction. The transaction is
+// committed when fn returns nil and rolled back otherwise. Calling InTx on a
+// store that is already in a transaction runs fn in that transaction.
+func (s *Store) InTx(ctx context.Context, fn func(tx *Store) error) error {
+	if s.db == nil {
+		return fn(s)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	txStore := newStore(tx, &SettingsStore{q: tx, cache: s.Settings.cache, inTx: true})
+	if err := fn(txStore); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Readers may have cached the old values while the transaction was open
+	if txStore.Settings.dirty {
+		s.Settings.Invalidate()
+	}
+	return nil
+}
+
+// notFound maps sql.ErrNoRows to ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,128 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// TokenStore holds the API access tokens. Only a hash of each token is
+// stored, so a leaked database does not leak working tokens.
+type TokenStore struct {
+	q DBTX
+}
+
+const tokenColumns = `id, name, role, COALESCE(expires_at, ''), created_at`
+
+// unexpired matches the tokens that have not expired
+const unexpired = `(expires_at IS NULL OR expires_at > strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))`
+
+// Create generates a token with a name and role. The returned token is the
+// only copy of it.
+func (s *TokenStore) Create(ctx context.Context, name, role string) (models.AccessToken, error) {
+	return s.create(ctx, name, role, nil)
+}
+
+// CreateExpiring generates a token that stops working at expires, as for
+// single sign-on sessions
+func (s *TokenStore) CreateExpiring(ctx context.Context, name, role string, expires time.Time) (models.AccessToken, error) {
+	return s.create(ctx, name, role, expires.UTC().Format(time.RFC3339))
+}
+
+func (s *TokenStore) create(ctx context.Context, name, role string, expiresAt interface{}) (models.AccessToken, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return models.AccessToken{}, err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+
+	result, err := s.q.ExecContext(ctx, `INSERT INTO access_tokens (name, role, token_hash, expires_at) VALUES (?, ?, ?, ?)`, name, role, hashToken(secret), expiresAt)
+	if err != nil {
+		return models.AccessToken{}, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return models.AccessToken{}, err
+	}
+
+	token, err := s.Get(ctx, id)
+	token.Token = secret
+	return token, err
+}
+
+// Get returns a token by id, or ErrNotFound
+func (s *TokenStore) Get(ctx context.Context, id int64) (models.AccessToken, error) {
+	token, err := scanToken(s.q.QueryRowContext(ctx, `SELECT `+tokenColumns+` FROM access_tokens WHERE id = ?`, id))
+	return token, notFound(err)
+}
+
+// Lookup returns the token matching a secret unless it has expired, or
+// ErrNotFound
+func (s *TokenStore) Lookup(ctx context.Context, secret string) (models.AccessToken, error) {
+	token, err := scanToken(s.q.QueryRowContext(ctx, `SELECT `+tokenColumns+` FROM access_tokens WHERE token_hash = ? AND `+unexpired, hashToken(secret)))
+	return token, notFound(err)
+}
+
+// List returns all tokens, oldest first
+func (s *TokenStore) List(ctx context.Context) ([]models.AccessToken, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT `+tokenColumns+` FROM access_tokens ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []models.AccessToken{}
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// Count returns how many unexpired tokens have a role, or how many exist
+// when role is empty
+func (s *TokenStore) Count(ctx context.Context, role string) (int, error) {
+	var n int
+	err := s.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM access_tokens WHERE (? = '' OR role = ?) AND `+unexpired, role, role).Scan(&n)
+	return n, err
+}
+
+// Delete revokes a token and reports whether it existed
+func (s *TokenStore) Delete(ctx context.Context, id int64) (bool, error) {
+	result, err := s.q.ExecContext(ctx, `DELETE FROM access_tokens WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteExpired removes the tokens that have expired and returns how many
+func (s *TokenStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := s.q.ExecContext(ctx, `DELETE FROM access_tokens WHERE NOT `+unexpired)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func scanToken(row rowScanner) (models.AccessToken, error) {
+	var token models.AccessToken
+	err := row.Scan(&token.ID, &token.Name, &token.Role, &token.ExpiresAt, &token.CreatedAt)
+	return token, err
+}
+
+// hashToken returns the hex SHA-256 of a token. Tokens are random, so a
+// plain hash is enough.
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// TripStore holds the trips planned in each year and their expenses
+type TripStore struct {
+	q DBTX
+}
+
+const tripColumns = `id, year, name, destination, start_date, end_date, notes, budget,
+	(SELECT COALESCE(SUM(amount), 0) FROM trip_expenses WHERE trip_id = trips.id), created_at, updated_at`
+
+func scanTrip(row interface{ Scan(...interface{}) error }) (models.Trip, error) {
+	var t models.Trip
+	err := row.Scan(&t.ID, &t.Year, &t.Name, &t.Destination, &t.StartDate, &t.EndDate, &t.Notes, &t.Budget, &t.Spent, &t.CreatedAt, &t.UpdatedAt)
+	return t, err
+}
+
+// List returns the trips of a year, by start date
+func (s *TripStore) List(ctx context.Context, year int) ([]models.Trip, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT `+tripColumns+` FROM trips WHERE year = ? ORDER BY start_date, id`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trips := []models.Trip{}
+	for rows.Next() {
+		t, err := scanTrip(rows)
+		if err != nil {
+			return nil, err
+		}
+		trips = append(trips, t)
+	}
+
+	return trips, rows.Err()
+}
+
+// Get returns a trip of a year, or ErrNotFound
+func (s *TripStore) Get(ctx context.Context, year int, id int64) (models.Trip, error) {
+	t, err := scanTrip(s.q.QueryRowContext(ctx, `SELECT `+tripColumns+` FROM trips WHERE year = ? AND id = ?`, year, id))
+	return t, notFound(err)
+}
+
+// Create stores a new trip and returns it with its id
+func (s *TripStore) Create(ctx context.Context, t models.Trip) (models.Trip, error) {
+	result, err := s.q.ExecContext(ctx, `INSERT INTO trips (year, name, destination, start_date, end_date, notes, budget) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		t.Year, t.Name, t.Destination, t.StartDate, t.EndDate, t.Notes, t.Budget)
+	if err != nil {
+		return t, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return t, err
+	}
+	return s.Get(ctx, t.Year, id)
+}
+
+// Update saves the editable fields of a trip, returning ErrNotFound when the
+// year has no trip with its id
+func (s *TripStore) Update(ctx context.Context, t models.Trip) (models.Trip, error) {
+	result, err := s.q.ExecContext(ctx, `UPDATE trips SET name = ?, destination = ?, start_date = ?, end_date = ?, notes = ?, budget = ?, updated_at = CURRENT_TIMESTAMP WHERE year = ? AND id = ?`,
+		t.Name, t.Destination, t.StartDate, t.EndDate, t.Notes, t.Budget, t.Year, t.ID)
+	if err != nil {
+		return t, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return t, ErrNotFound
+	}
+	return s.Get(ctx, t.Year, t.ID)
+}
+
+// Delete removes a trip of a year and its expenses, returning ErrNotFound
+// when there is no trip with that id
+func (s *TripStore) Delete(ctx context.Context, year int, id int64) error {
+	result, err := s.q.ExecContext(ctx, `DELETE FROM trips WHERE year = ? AND id = ?`, year, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	_, err = s.q.ExecContext(ctx, `DELETE FROM trip_expenses WHERE trip_id = ?`, id)
+	return err
+}
+
+const expenseColumns = `id, trip_id, date, description, category, amount, created_at`
+
+func scanExpense(row interface{ Scan(...interface{}) error }) (models.TripExpense, error) {
+	var e models.TripExpense
+	err := row.Scan(&e.ID, &e.TripID, &e.Date, &e.Description, &e.Category, &e.Amount, &e.CreatedAt)
+	return e, err
+}
+
+// Expenses returns the expenses of a trip, by date
+func (s *TripStore) Expenses(ctx context.Context, tripID int64) ([]models.TripExpense, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT `+expenseColumns+` FROM trip_expenses WHERE trip_id = ? ORDER BY date, id`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	expenses := []models.TripExpense{}
+	for rows.Next() {
+		e, err := scanExpense(rows)
+		if err != nil {
+			return nil, err
+		}
+		expenses = append(expenses, e)
+	}
+
+	return expenses, rows.Err()
+}
+
+// YearExpenses returns the expenses of all the trips of a year
+func (s *TripStore) YearExpenses(ctx context.Context, year int) ([]models.TripExpense, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT `+expenseColumns+` FROM trip_expenses WHERE trip_id IN (SELECT id FROM trips WHERE year = ?) ORDER BY date, id`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	expenses := []models.TripExpense{}
+	for rows.Next() {
+		e, err := scanExpense(rows)
+		if err != nil {
+			return nil, err
+		}
+		expenses = append(expenses, e)
+	}
+
+	return expenses, rows.Err()
+}
+
+// AddExpense stores an expense of a trip and returns it with its id
+func (s *TripStore) AddExpense(ctx context.Context, e models.TripExpense) (models.TripExpense, error) {
+	result, err := s.q.ExecContext(ctx, `INSERT INTO trip_expenses (trip_id, date, description, category, amount) VALUES (?, ?, ?, ?, ?)`,
+		e.TripID, e.Date, e.Description, e.Category, e.Amount)
+	if err != nil {
+		return e, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return e, err
+	}
+	return s.expense(ctx, e.TripID, id)
+}
+
+// UpdateExpense saves an expense of a trip, returning ErrNotFound when the
+// trip has no expense with its id
+func (s *TripStore) UpdateExpense(ctx context.Context, e models.TripExpense) (models.TripExpense, error) {
+	result, err := s.q.ExecContext(ctx, `UPDATE trip_expenses SET date = ?, description = ?, category = ?, amount = ? WHERE trip_id = ? AND id = ?`,
+		e.Date, e.Description, e.Category, e.Amount, e.TripID, e.ID)
+	if err != nil {
+		return e, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return e, ErrNotFound
+	}
+	return s.expense(ctx, e.TripID, e.ID)
+}
+
+// DeleteExpense removes an expense of a trip, returning ErrNotFound when
+// there is none with that id
+func (s *TripStore) DeleteExpense(ctx context.Context, tripID, id int64) error {
+	result, err := s.q.ExecContext(ctx, `DELETE FROM trip_expenses WHERE trip_id = ? AND id = ?`, tripID, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *TripStore) expense(ctx context.Context, tripID, id int64) (models.TripExpense, error) {
+	e, err := scanExpense(s.q.QueryRowContext(ctx, `SELECT `+expenseColumns+` FROM trip_expenses WHERE trip_id = ? AND id = ?`, tripID, id))
+	return e, notFound(err)
+}
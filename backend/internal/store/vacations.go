@@ -0,0 +1,168 @@
+package store
+
+import (
+	"context"
+
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+)
+
+// VacationStore holds the manual and optimized vacation days of the active plan
+type VacationStore struct {
+	q DBTX
+}
+
+// List returns the manual vacation days of a year
+func (s *VacationStore) List(ctx context.Context, year int) ([]models.VacationDay, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id, year, date, is_manual, COALESCE(note, '') FROM vacation_days WHERE year = ?`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var vacations []models.VacationDay
+	for rows.Next() {
+		var v models.VacationDay
+		if err := rows.Scan(&v.ID, &v.Year, &v.Date, &v.IsManual, &v.Note); err != nil {
+			return nil, err
+		}
+		vacations = append(vacations, v)
+	}
+
+	return vacations, rows.Err()
+}
+
+// Add stores a manual vacation day, replacing the note of an existing one
+func (s *VacationStore) Add(ctx context.Context, year int, date, note string) error {
+	_, err := s.q.ExecContext(ctx, `INSERT OR REPLACE INTO vacation_days (year, date, is_manual, note) VALUES (?, ?, TRUE, ?)`,
+		year, date, note)
+	return err
+}
+
+// Remove deletes a manual vacation day and reports whether it existed
+func (s *VacationStore) Remove(ctx context.Context, year int, date string) (bool, error) {
+	res, err := s.q.ExecContext(ctx, `DELETE FROM vacation_days WHERE year = ? AND date = ?`, year, date)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Clear deletes all manual vacation days of a year
+func (s *VacationStore) Clear(ctx context.Context, year int) error {
+	_, err := s.q.ExecContext(ctx, `DELETE FROM vacation_days WHERE year = ?`, year)
+	return err
+}
+
+// ClearUnlocked deletes the manual vacation days of a year from a date on,
+// "" for the whole year, that are not locked
+func (s *VacationStore) ClearUnlocked(ctx context.Context, year int, from string) error {
+	_, err := s.q.ExecContext(ctx, `DELETE FROM vacation_days WHERE year = ? AND date >= ?
+		AND date NOT IN (SELECT date FROM locked_dates WHERE year = ?)`, year, from, year)
+	return err
+}
+
+// ListOptimal returns the optimized vacation days of a year
+func (s *VacationStore) ListOptimal(ctx context.Context, year int) ([]models.OptimalVacation, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id, year, date, block_id, consecutive_days FROM optimal_vacations WHERE year = ?`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var vacations []models.OptimalVacation
+	for rows.Next() {
+		var v models.OptimalVacation
+		if err := rows.Scan(&v.ID, &v.Year, &v.Date, &v.BlockID, &v.ConsecutiveDays); err != nil {
+			return nil, err
+		}
+		vacations = append(vacations, v)
+	}
+
+	return vacations, rows.Err()
+}
+
+// AddOptimal stores an optimized vacation day
+func (s *VacationStore) AddOptimal(ctx context.Context, v models.OptimalVacation) error {
+	_, err := s.q.ExecContext(ctx, `INSERT OR REPLACE INTO optimal_vacations (year, date, block_id, consecutive_days) VALUES (?, ?, ?, ?)`,
+		v.Year, v.Date, v.BlockID, v.ConsecutiveDays)
+	return err
+}
+
+// RemoveOptimal deletes an optimized vacation day
+func (s *VacationStore) RemoveOptimal(ctx context.Context, year int, date string) error {
+	_, err := s.q.ExecContext(ctx, `DELETE FROM optimal_vacations WHERE year = ? AND date = ?`, year, date)
+	return err
+}
+
+// ClearOptimal deletes all optimized vacation days of a year
+func (s *VacationStore) ClearOptimal(ctx context.Context, year int) error {
+	_, err := s.q.ExecContext(ctx, `DELETE FROM optimal_vacations WHERE year = ?`, year)
+	return err
+}
+
+// ClearOptimalUnlocked deletes the optimized vacation days of a year from a
+// date on, "" for the whole year, that are not locked
+func (s *VacationStore) ClearOptimalUnlocked(ctx context.Context, year int, from string) error {
+	_, err := s.q.ExecContext(ctx, `DELETE FROM optimal_vacations WHERE year = ? AND date >= ?
+		AND date NOT IN (SELECT date FROM locked_dates WHERE year = ?)`, year, from, year)
+	return err
+}
+
+// Locked returns the locked dates of a year in order
+func (s *VacationStore) Locked(ctx context.Context, year int) ([]models.LockedDate, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT id, year, date, reason, locked_by, created_at
+		FROM locked_dates WHERE year = ? ORDER BY date`, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locked := []models.LockedDate{}
+	for rows.Next() {
+		var l models.LockedDate
+		if err := rows.Scan(&l.ID, &l.Year, &l.Date, &l.Reason, &l.LockedBy, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		locked = append(locked, l)
+	}
+	return locked, rows.Err()
+}
+
+// Lock locks a date, replacing the reason of one already locked
+func (s *VacationStore) Lock(ctx context.Context, l models.LockedDate) error {
+	_, err := s.q.ExecContext(ctx, `INSERT INTO locked_dates (year, date, reason, locked_by) VALUES (?, ?, ?, ?)
+		ON CONFLICT(year, date) DO UPDATE SET reason = excluded.reason, locked_by = excluded.locked_by`,
+		l.Year, l.Date, l.Reason, l.LockedBy)
+	return err
+}
+
+// Unlock unlocks a date and reports whether it was locked
+func (s *VacationStore) Unlock(ctx context.Context, year int, date string) (bool, error) {
+	res, err := s.q.ExecContext(ctx, `DELETE FROM locked_dates WHERE year = ? AND date = ?`, year, date)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Years returns the years that have manual or optimized vacation days, in order
+func (s *VacationStore) Years(ctx context.Context) ([]int, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT year FROM vacation_days UNION SELECT year FROM optimal_vacations ORDER BY year`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var years []int
+	for rows.Next() {
+		var year int
+		if err := rows.Scan(&year); err != nil {
+			return nil, err
+		}
+		years = append(years, year)
+	}
+
+	return years, rows.Err()
+}
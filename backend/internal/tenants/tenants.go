@@ -0,0 +1,79 @@
+// Package tenants lays out the databases of a multi-tenant instance: one
+// SQLite file per organization in a directory, so tenants share no settings,
+// access tokens or data.
+package tenants
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Header selects the tenant of a request when the host does not
+const Header = "X-Tenant"
+
+// ErrNotFound is returned for a tenant without a database
+var ErrNotFound = errors.New("tenant not found")
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// ValidName returns an error unless name can be a tenant name: lowercase
+// letters, digits and dashes, as used in a subdomain
+func ValidName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid tenant name %q: use up to 63 lowercase letters, digits and dashes", name)
+	}
+	return nil
+}
+
+// Path returns the database file of a tenant
+func Path(dir, name string) string {
+	return filepath.Join(dir, name+".db")
+}
+
+// Exists reports whether a tenant has a database
+func Exists(dir, name string) bool {
+	if ValidName(name) != nil {
+		return false
+	}
+	info, err := os.Stat(Path(dir, name))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// List returns the tenants with a database, sorted by name
+func List(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.db"))
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".db")
+		if ValidName(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// FromRequest returns the tenant a request is for: the subdomain of domain
+// in its host when domain is set and matches, or else the X-Tenant header
+func FromRequest(r *http.Request, domain string) string {
+	if domain != "" {
+		host := r.Host
+		if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+			host = host[:i]
+		}
+		if name, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(domain)); ok && !strings.Contains(name, ".") {
+			return name
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(r.Header.Get(Header)))
+}
@@ -0,0 +1,48 @@
+package tenants
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFromRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		host   string
+		header string
+		domain string
+		want   string
+	}{
+		{"subdomain", "acme.planner.example.com", "", "planner.example.com", "acme"},
+		{"subdomain with port", "acme.planner.example.com:8080", "", "planner.example.com", "acme"},
+		{"nested subdomain", "a.acme.planner.example.com", "", "planner.example.com", ""},
+		{"header on another host", "localhost:8080", "Acme", "planner.example.com", "acme"},
+		{"header without domain", "acme.planner.example.com", "globex", "", "globex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/health", nil)
+			r.Host = tt.host
+			if tt.header != "" {
+				r.Header.Set(Header, tt.header)
+			}
+			if got := FromRequest(r, tt.domain); got != tt.want {
+				t.Errorf("FromRequest(%s, %s) = %q, want %q", tt.host, tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidName(t *testing.T) {
+	for _, name := range []string{"acme", "acme-2", "0"} {
+		if err := ValidName(name); err != nil {
+			t.Errorf("ValidName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "-acme", "Acme", "../acme", "acme.db"} {
+		if ValidName(name) == nil {
+			t.Errorf("ValidName(%q) accepted an invalid name", name)
+		}
+	}
+}
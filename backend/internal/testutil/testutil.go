@@ -0,0 +1,200 @@
+// Package testutil runs the full API server against an in-memory SQLite
+// database for tests. Sandbox mode is turned on, so no test reaches the
+// holiday APIs or an AI provider.
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bruno.lopes/calendar/backend/internal/api"
+	"github.com/bruno.lopes/calendar/backend/internal/database"
+	"github.com/bruno.lopes/calendar/backend/internal/models"
+	"github.com/bruno.lopes/calendar/backend/internal/sandbox"
+	"github.com/bruno.lopes/calendar/backend/internal/store"
+)
+
+var dbCount atomic.Int64
+
+// Fixture stores test data before the server starts
+type Fixture func(ctx context.Context, st *store.Store) error
+
+// WithSetting stores a setting
+func WithSetting(key, value string) Fixture {
+	return func(ctx context.Context, st *store.Store) error {
+		return st.Settings.Set(ctx, key, value)
+	}
+}
+
+// WithYearConfig stores the configuration of a year
+func WithYearConfig(config models.YearConfig) Fixture {
+	return func(ctx context.Context, st *store.Store) error {
+		if config.WorkWeek == nil {
+			config.WorkWeek = []string{"monday", "tuesday", "wednesday", "thursday", "friday"}
+		}
+		if config.OptimizationStrategy == "" {
+			config.OptimizationStrategy = models.StrategyBalanced
+		}
+		if config.AccountingMode == "" {
+			config.AccountingMode = models.AccountingDays
+		}
+		return st.Configs.Create(ctx, config)
+	}
+}
+
+// WithVacations stores manual vacation days
+func WithVacations(year int, dates ...string) Fixture {
+	return func(ctx context.Context, st *store.Store) error {
+		for _, date := range dates {
+			if err := st.Vacations.Add(ctx, year, date, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// WithOptimalVacations stores optimized vacation days as one block
+func WithOptimalVacations(year int, dates ...string) Fixture {
+	return func(ctx context.Context, st *store.Store) error {
+		for _, date := range dates {
+			if err := st.Vacations.AddOptimal(ctx, models.OptimalVacation{Year: year, Date: date, BlockID: 1, ConsecutiveDays: len(dates)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// NewDB opens a migrated in-memory database that is closed when the test ends.
+// Every call returns a separate database. Past vacation days are not
+// protected, so tests don't depend on today's date; turn on
+// past_edit_protection to test it.
+func NewDB(t testing.TB) *sql.DB {
+	t.Helper()
+
+	// A named shared-cache database is visible to every connection of the
+	// pool, unlike a plain :memory: one
+	dsn := fmt.Sprintf("file:testdb%d?mode=memory&cache=shared&_busy_timeout=5000", dbCount.Add(1))
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+
+	// The database lives as long as one connection stays open
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		db.Close()
+	})
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	if err := store.New(db).Settings.Set(context.Background(), "past_edit_protection", "false"); err != nil {
+		t.Fatalf("disable past edit protection: %v", err)
+	}
+	return db
+}
+
+// Server is the API served over HTTP for a test
+type Server struct {
+	*httptest.Server
+	DB    *sql.DB
+	Store *store.Store
+	// Token is sent as a bearer token when set
+	Token string
+	// ClientID is sent as the edit lock client ID when set
+	ClientID string
+
+	t testing.TB
+}
+
+// NewServer starts the full API server on a fresh in-memory database with
+// the fixtures applied. It is shut down when the test ends.
+func NewServer(t testing.TB, fixtures ...Fixture) *Server {
+	t.Helper()
+
+	sandbox.Enable()
+	gin.SetMode(gin.TestMode)
+
+	db := NewDB(t)
+	st := store.New(db)
+	ctx := context.Background()
+	for _, fixture := range fixtures {
+		if err := fixture(ctx, st); err != nil {
+			t.Fatalf("apply fixture: %v", err)
+		}
+	}
+
+	app := api.NewServer(db)
+	srv := httptest.NewServer(app.Handler())
+	// Cleanups run last-in first-out: stop serving before the database closes
+	t.Cleanup(func() {
+		srv.Close()
+		app.Close()
+	})
+
+	return &Server{Server: srv, DB: db, Store: st, t: t}
+}
+
+// Do sends a request with body encoded as JSON, when not nil
+func (s *Server) Do(method, path string, body interface{}) *http.Response {
+	s.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatalf("encode request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, s.URL+path, reader)
+	if err != nil {
+		s.t.Fatalf("build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+	if s.ClientID != "" {
+		req.Header.Set("X-Client-ID", s.ClientID)
+	}
+
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		s.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	return resp
+}
+
+// JSON sends a request and decodes the JSON response into out, when not
+// nil. It returns the response status.
+func (s *Server) JSON(method, path string, body, out interface{}) int {
+	s.t.Helper()
+
+	resp := s.Do(method, path, body)
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			s.t.Fatalf("%s %s: decode response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
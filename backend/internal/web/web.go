@@ -0,0 +1,69 @@
+// Package web serves the frontend build embedded in the binary, so the API
+// and the app share one port without a separate static file server. The
+// build copies frontend/dist into dist/ before compiling; without it the
+// server runs API only.
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+//go:embed all:dist
+var dist embed.FS
+
+// files is the frontend build with dist/ stripped from the paths
+var files, _ = fs.Sub(dist, "dist")
+
+// Enabled reports whether a frontend build is embedded
+func Enabled() bool {
+	_, err := fs.Stat(files, "index.html")
+	return err == nil
+}
+
+// Handler serves the embedded files. Paths without a file extension that do
+// not match a file get index.html, so client-side routes survive a reload.
+func Handler() http.Handler {
+	fileServer := http.FileServer(http.FS(files))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if name == "" || name == "index.html" {
+			serveIndex(w, r)
+			return
+		}
+
+		info, err := fs.Stat(files, name)
+		if err != nil || info.IsDir() {
+			if path.Ext(name) != "" {
+				http.NotFound(w, r)
+				return
+			}
+			serveIndex(w, r)
+			return
+		}
+
+		// Vite puts a content hash in the asset file names
+		if strings.HasPrefix(name, "assets/") {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
+
+// serveIndex serves index.html, which must not be cached so a new build is
+// picked up right away
+func serveIndex(w http.ResponseWriter, r *http.Request) {
+	index, err := fs.ReadFile(files, "index.html")
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(index)
+}
@@ -0,0 +1,244 @@
+// Package webhooks delivers calendar events to external URLs. Payloads are
+// signed with HMAC-SHA256 and failed deliveries are retried from a queue
+// stored in the database.
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bruno.lopes/calendar/backend/internal/events"
+)
+
+// SignatureHeader carries the HMAC-SHA256 signature of the request body
+const SignatureHeader = "X-Webhook-Signature"
+
+// timeFormat matches SQLite's CURRENT_TIMESTAMP so queued rows compare correctly
+const timeFormat = "2006-01-02 15:04:05"
+
+// Delivery is a queued or completed webhook call
+type Delivery struct {
+	ID            int64  `json:"id"`
+	URL           string `json:"url"`
+	Event         string `json:"event"`
+	Status        string `json:"status"` // "pending", "delivered" or "failed"
+	Attempts      int    `json:"attempts"`
+	LastError     string `json:"last_error,omitempty"`
+	NextAttemptAt string `json:"next_attempt_at"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// Dispatcher queues events for the configured webhook URLs and delivers them
+// in the background
+type Dispatcher struct {
+	db            *sql.DB
+	client        *http.Client
+	maxAttempts   int
+	retryInterval time.Duration
+	pollInterval  time.Duration
+	wake          chan struct{}
+	stop          chan struct{}
+	stopOnce      sync.Once
+}
+
+// NewDispatcher creates a new webhook dispatcher
+func NewDispatcher(db *sql.DB) *Dispatcher {
+	return &Dispatcher{
+		db:            db,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		maxAttempts:   5,
+		retryInterval: 30 * time.Second,
+		pollInterval:  10 * time.Second,
+		wake:          make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+	}
+}
+
+// SetRetryConfig sets how many times a delivery is attempted and the base
+// interval between attempts (doubled after every failure)
+func (d *Dispatcher) SetRetryConfig(maxAttempts int, interval time.Duration) {
+	d.maxAttempts = maxAttempts
+	d.retryInterval = interval
+}
+
+// Start runs the delivery loop in the background
+func (d *Dispatcher) Start() {
+	go func() {
+		ticker := time.NewTicker(d.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+			case <-d.wake:
+			}
+			d.processDue()
+		}
+	}()
+}
+
+// Stop ends the delivery loop
+func (d *Dispatcher) Stop() {
+	d.stopOnce.Do(func() { close(d.stop) })
+}
+
+// Handle queues an event for every configured webhook URL. It is meant to be
+// subscribed to the events bus.
+func (d *Dispatcher) Handle(event events.Event) {
+	var urlSetting string
+	d.db.QueryRow(`SELECT value FROM settings WHERE key = 'webhook_urls'`).Scan(&urlSetting)
+
+	urls := ParseURLs(urlSetting)
+	if len(urls) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Error encoding webhook payload: %v", err)
+		return
+	}
+
+	for _, url := range urls {
+		_, err := d.db.Exec(`INSERT INTO webhook_deliveries (url, event, payload, next_attempt_at) VALUES (?, ?, ?, ?)`,
+			url, event.Type, string(payload), time.Now().UTC().Format(timeFormat))
+		if err != nil {
+			log.Printf("Error queueing webhook for %s: %v", url, err)
+		}
+	}
+
+	// Deliver right away instead of waiting for the next poll
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Deliveries returns the most recent deliveries, newest first
+func (d *Dispatcher) Deliveries(limit int) ([]Delivery, error) {
+	rows, err := d.db.Query(`SELECT id, url, event, status, attempts, last_error, next_attempt_at, created_at
+		FROM webhook_deliveries ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []Delivery{}
+	for rows.Next() {
+		var del Delivery
+		if err := rows.Scan(&del.ID, &del.URL, &del.Event, &del.Status, &del.Attempts, &del.LastError, &del.NextAttemptAt, &del.CreatedAt); err != nil {
+			continue
+		}
+		deliveries = append(deliveries, del)
+	}
+
+	return deliveries, nil
+}
+
+// processDue attempts all pending deliveries whose retry time has come
+func (d *Dispatcher) processDue() {
+	now := time.Now().UTC().Format(timeFormat)
+	rows, err := d.db.Query(`SELECT id, url, event, payload, attempts FROM webhook_deliveries
+		WHERE status = 'pending' AND next_attempt_at <= ? ORDER BY id LIMIT 50`, now)
+	if err != nil {
+		log.Printf("Error loading webhook queue: %v", err)
+		return
+	}
+
+	type queued struct {
+		id       int64
+		url      string
+		event    string
+		payload  string
+		attempts int
+	}
+
+	var due []queued
+	for rows.Next() {
+		var q queued
+		if err := rows.Scan(&q.id, &q.url, &q.event, &q.payload, &q.attempts); err != nil {
+			continue
+		}
+		due = append(due, q)
+	}
+	rows.Close()
+
+	for _, q := range due {
+		attempts := q.attempts + 1
+		if err := d.deliver(q.id, q.url, q.event, []byte(q.payload)); err != nil {
+			status := "pending"
+			if attempts >= d.maxAttempts {
+				status = "failed"
+				log.Printf("Webhook %d to %s failed permanently: %v", q.id, q.url, err)
+			}
+			next := time.Now().UTC().Add(d.retryInterval * time.Duration(1<<(attempts-1)))
+			d.db.Exec(`UPDATE webhook_deliveries SET status = ?, attempts = ?, last_error = ?, next_attempt_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+				status, attempts, err.Error(), next.Format(timeFormat), q.id)
+			continue
+		}
+
+		d.db.Exec(`UPDATE webhook_deliveries SET status = 'delivered', attempts = ?, last_error = '', updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+			attempts, q.id)
+	}
+}
+
+// deliver POSTs a signed payload to a webhook URL
+func (d *Dispatcher) deliver(id int64, url, event string, payload []byte) error {
+	var secret string
+	d.db.QueryRow(`SELECT value FROM settings WHERE key = 'webhook_secret'`).Scan(&secret)
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", event)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(id, 10))
+	if secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(secret, payload))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of a payload
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseURLs splits the webhook_urls setting (comma or newline separated)
+func ParseURLs(value string) []string {
+	var urls []string
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '\n' || r == ' ' || r == '\t' || r == '\r'
+	}) {
+		urls = append(urls, field)
+	}
+	return urls
+}
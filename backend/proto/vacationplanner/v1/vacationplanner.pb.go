@@ -0,0 +1,2401 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: vacationplanner/v1/vacationplanner.proto
+
+// Calendar, vacation and optimizer API, mirroring the REST endpoints under
+// /api/calendar and /api/vacations. Dates are YYYY-MM-DD strings, as in the
+// REST API.
+
+package vacationplannerv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetCalendarRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Year int32 `protobuf:"varint,1,opt,name=year,proto3" json:"year,omitempty"`
+}
+
+func (x *GetCalendarRequest) Reset() {
+	*x = GetCalendarRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetCalendarRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCalendarRequest) ProtoMessage() {}
+
+func (x *GetCalendarRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCalendarRequest.ProtoReflect.Descriptor instead.
+func (*GetCalendarRequest) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetCalendarRequest) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+type GetCalendarResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Calendar *Calendar `protobuf:"bytes,1,opt,name=calendar,proto3" json:"calendar,omitempty"`
+}
+
+func (x *GetCalendarResponse) Reset() {
+	*x = GetCalendarResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetCalendarResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCalendarResponse) ProtoMessage() {}
+
+func (x *GetCalendarResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCalendarResponse.ProtoReflect.Descriptor instead.
+func (*GetCalendarResponse) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetCalendarResponse) GetCalendar() *Calendar {
+	if x != nil {
+		return x.Calendar
+	}
+	return nil
+}
+
+type Calendar struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Year             int32              `protobuf:"varint,1,opt,name=year,proto3" json:"year,omitempty"`
+	Config           *YearConfig        `protobuf:"bytes,2,opt,name=config,proto3" json:"config,omitempty"`
+	Days             []*CalendarDay     `protobuf:"bytes,3,rep,name=days,proto3" json:"days,omitempty"`
+	Holidays         []*Holiday         `protobuf:"bytes,4,rep,name=holidays,proto3" json:"holidays,omitempty"`
+	VacationBlocks   []*VacationBlock   `protobuf:"bytes,5,rep,name=vacation_blocks,json=vacationBlocks,proto3" json:"vacation_blocks,omitempty"`
+	ManualVacations  []*VacationDay     `protobuf:"bytes,6,rep,name=manual_vacations,json=manualVacations,proto3" json:"manual_vacations,omitempty"`
+	OptimalVacations []*OptimalVacation `protobuf:"bytes,7,rep,name=optimal_vacations,json=optimalVacations,proto3" json:"optimal_vacations,omitempty"`
+	Summary          *CalendarSummary   `protobuf:"bytes,8,opt,name=summary,proto3" json:"summary,omitempty"`
+}
+
+func (x *Calendar) Reset() {
+	*x = Calendar{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Calendar) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Calendar) ProtoMessage() {}
+
+func (x *Calendar) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Calendar.ProtoReflect.Descriptor instead.
+func (*Calendar) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Calendar) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+func (x *Calendar) GetConfig() *YearConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *Calendar) GetDays() []*CalendarDay {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+func (x *Calendar) GetHolidays() []*Holiday {
+	if x != nil {
+		return x.Holidays
+	}
+	return nil
+}
+
+func (x *Calendar) GetVacationBlocks() []*VacationBlock {
+	if x != nil {
+		return x.VacationBlocks
+	}
+	return nil
+}
+
+func (x *Calendar) GetManualVacations() []*VacationDay {
+	if x != nil {
+		return x.ManualVacations
+	}
+	return nil
+}
+
+func (x *Calendar) GetOptimalVacations() []*OptimalVacation {
+	if x != nil {
+		return x.OptimalVacations
+	}
+	return nil
+}
+
+func (x *Calendar) GetSummary() *CalendarSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+type YearConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Year                 int32             `protobuf:"varint,1,opt,name=year,proto3" json:"year,omitempty"`
+	VacationDays         int32             `protobuf:"varint,2,opt,name=vacation_days,json=vacationDays,proto3" json:"vacation_days,omitempty"`
+	ReservedDays         int32             `protobuf:"varint,3,opt,name=reserved_days,json=reservedDays,proto3" json:"reserved_days,omitempty"`
+	OptimizationStrategy string            `protobuf:"bytes,4,opt,name=optimization_strategy,json=optimizationStrategy,proto3" json:"optimization_strategy,omitempty"`
+	WorkWeek             []string          `protobuf:"bytes,5,rep,name=work_week,json=workWeek,proto3" json:"work_week,omitempty"`
+	OptimizerNotes       string            `protobuf:"bytes,6,opt,name=optimizer_notes,json=optimizerNotes,proto3" json:"optimizer_notes,omitempty"`
+	AlignSchoolBreaks    bool              `protobuf:"varint,7,opt,name=align_school_breaks,json=alignSchoolBreaks,proto3" json:"align_school_breaks,omitempty"`
+	WorkWeekChanges      []*WorkWeekChange `protobuf:"bytes,8,rep,name=work_week_changes,json=workWeekChanges,proto3" json:"work_week_changes,omitempty"`
+	// "days" or "hours"
+	AccountingMode string             `protobuf:"bytes,9,opt,name=accounting_mode,json=accountingMode,proto3" json:"accounting_mode,omitempty"`
+	VacationHours  float64            `protobuf:"fixed64,10,opt,name=vacation_hours,json=vacationHours,proto3" json:"vacation_hours,omitempty"`
+	WorkingHours   map[string]float64 `protobuf:"bytes,11,rep,name=working_hours,json=workingHours,proto3" json:"working_hours,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+}
+
+func (x *YearConfig) Reset() {
+	*x = YearConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *YearConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*YearConfig) ProtoMessage() {}
+
+func (x *YearConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use YearConfig.ProtoReflect.Descriptor instead.
+func (*YearConfig) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *YearConfig) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+func (x *YearConfig) GetVacationDays() int32 {
+	if x != nil {
+		return x.VacationDays
+	}
+	return 0
+}
+
+func (x *YearConfig) GetReservedDays() int32 {
+	if x != nil {
+		return x.ReservedDays
+	}
+	return 0
+}
+
+func (x *YearConfig) GetOptimizationStrategy() string {
+	if x != nil {
+		return x.OptimizationStrategy
+	}
+	return ""
+}
+
+func (x *YearConfig) GetWorkWeek() []string {
+	if x != nil {
+		return x.WorkWeek
+	}
+	return nil
+}
+
+func (x *YearConfig) GetOptimizerNotes() string {
+	if x != nil {
+		return x.OptimizerNotes
+	}
+	return ""
+}
+
+func (x *YearConfig) GetAlignSchoolBreaks() bool {
+	if x != nil {
+		return x.AlignSchoolBreaks
+	}
+	return false
+}
+
+func (x *YearConfig) GetWorkWeekChanges() []*WorkWeekChange {
+	if x != nil {
+		return x.WorkWeekChanges
+	}
+	return nil
+}
+
+func (x *YearConfig) GetAccountingMode() string {
+	if x != nil {
+		return x.AccountingMode
+	}
+	return ""
+}
+
+func (x *YearConfig) GetVacationHours() float64 {
+	if x != nil {
+		return x.VacationHours
+	}
+	return 0
+}
+
+func (x *YearConfig) GetWorkingHours() map[string]float64 {
+	if x != nil {
+		return x.WorkingHours
+	}
+	return nil
+}
+
+type WorkWeekChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EffectiveFrom string   `protobuf:"bytes,1,opt,name=effective_from,json=effectiveFrom,proto3" json:"effective_from,omitempty"`
+	WorkWeek      []string `protobuf:"bytes,2,rep,name=work_week,json=workWeek,proto3" json:"work_week,omitempty"`
+}
+
+func (x *WorkWeekChange) Reset() {
+	*x = WorkWeekChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WorkWeekChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkWeekChange) ProtoMessage() {}
+
+func (x *WorkWeekChange) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkWeekChange.ProtoReflect.Descriptor instead.
+func (*WorkWeekChange) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WorkWeekChange) GetEffectiveFrom() string {
+	if x != nil {
+		return x.EffectiveFrom
+	}
+	return ""
+}
+
+func (x *WorkWeekChange) GetWorkWeek() []string {
+	if x != nil {
+		return x.WorkWeek
+	}
+	return nil
+}
+
+type CalendarDay struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Date        string `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	DayOfWeek   string `protobuf:"bytes,2,opt,name=day_of_week,json=dayOfWeek,proto3" json:"day_of_week,omitempty"`
+	IsWeekend   bool   `protobuf:"varint,3,opt,name=is_weekend,json=isWeekend,proto3" json:"is_weekend,omitempty"`
+	IsHoliday   bool   `protobuf:"varint,4,opt,name=is_holiday,json=isHoliday,proto3" json:"is_holiday,omitempty"`
+	HolidayName string `protobuf:"bytes,5,opt,name=holiday_name,json=holidayName,proto3" json:"holiday_name,omitempty"`
+	IsVacation  bool   `protobuf:"varint,6,opt,name=is_vacation,json=isVacation,proto3" json:"is_vacation,omitempty"`
+	IsManual    bool   `protobuf:"varint,7,opt,name=is_manual,json=isManual,proto3" json:"is_manual,omitempty"`
+	IsOptimal   bool   `protobuf:"varint,8,opt,name=is_optimal,json=isOptimal,proto3" json:"is_optimal,omitempty"`
+	BlockId     int32  `protobuf:"varint,9,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+}
+
+func (x *CalendarDay) Reset() {
+	*x = CalendarDay{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CalendarDay) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalendarDay) ProtoMessage() {}
+
+func (x *CalendarDay) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalendarDay.ProtoReflect.Descriptor instead.
+func (*CalendarDay) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CalendarDay) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *CalendarDay) GetDayOfWeek() string {
+	if x != nil {
+		return x.DayOfWeek
+	}
+	return ""
+}
+
+func (x *CalendarDay) GetIsWeekend() bool {
+	if x != nil {
+		return x.IsWeekend
+	}
+	return false
+}
+
+func (x *CalendarDay) GetIsHoliday() bool {
+	if x != nil {
+		return x.IsHoliday
+	}
+	return false
+}
+
+func (x *CalendarDay) GetHolidayName() string {
+	if x != nil {
+		return x.HolidayName
+	}
+	return ""
+}
+
+func (x *CalendarDay) GetIsVacation() bool {
+	if x != nil {
+		return x.IsVacation
+	}
+	return false
+}
+
+func (x *CalendarDay) GetIsManual() bool {
+	if x != nil {
+		return x.IsManual
+	}
+	return false
+}
+
+func (x *CalendarDay) GetIsOptimal() bool {
+	if x != nil {
+		return x.IsOptimal
+	}
+	return false
+}
+
+func (x *CalendarDay) GetBlockId() int32 {
+	if x != nil {
+		return x.BlockId
+	}
+	return 0
+}
+
+type Holiday struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Date string `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// "national", "municipal" or "observed"
+	Type string `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	// Original date of an observed holiday
+	ObservedFor string `protobuf:"bytes,4,opt,name=observed_for,json=observedFor,proto3" json:"observed_for,omitempty"`
+}
+
+func (x *Holiday) Reset() {
+	*x = Holiday{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Holiday) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Holiday) ProtoMessage() {}
+
+func (x *Holiday) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Holiday.ProtoReflect.Descriptor instead.
+func (*Holiday) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Holiday) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *Holiday) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Holiday) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Holiday) GetObservedFor() string {
+	if x != nil {
+		return x.ObservedFor
+	}
+	return ""
+}
+
+type VacationBlock struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StartDate        string   `protobuf:"bytes,1,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate          string   `protobuf:"bytes,2,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	TotalDays        int32    `protobuf:"varint,3,opt,name=total_days,json=totalDays,proto3" json:"total_days,omitempty"`
+	VacationDaysUsed int32    `protobuf:"varint,4,opt,name=vacation_days_used,json=vacationDaysUsed,proto3" json:"vacation_days_used,omitempty"`
+	Dates            []string `protobuf:"bytes,5,rep,name=dates,proto3" json:"dates,omitempty"`
+	Holidays         []string `protobuf:"bytes,6,rep,name=holidays,proto3" json:"holidays,omitempty"`
+	Weekends         []string `protobuf:"bytes,7,rep,name=weekends,proto3" json:"weekends,omitempty"`
+	// Total days off per vacation day used
+	Efficiency float64 `protobuf:"fixed64,8,opt,name=efficiency,proto3" json:"efficiency,omitempty"`
+	// "manual" or "optimized"
+	Source string `protobuf:"bytes,9,opt,name=source,proto3" json:"source,omitempty"`
+}
+
+func (x *VacationBlock) Reset() {
+	*x = VacationBlock{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VacationBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VacationBlock) ProtoMessage() {}
+
+func (x *VacationBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VacationBlock.ProtoReflect.Descriptor instead.
+func (*VacationBlock) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *VacationBlock) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *VacationBlock) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+func (x *VacationBlock) GetTotalDays() int32 {
+	if x != nil {
+		return x.TotalDays
+	}
+	return 0
+}
+
+func (x *VacationBlock) GetVacationDaysUsed() int32 {
+	if x != nil {
+		return x.VacationDaysUsed
+	}
+	return 0
+}
+
+func (x *VacationBlock) GetDates() []string {
+	if x != nil {
+		return x.Dates
+	}
+	return nil
+}
+
+func (x *VacationBlock) GetHolidays() []string {
+	if x != nil {
+		return x.Holidays
+	}
+	return nil
+}
+
+func (x *VacationBlock) GetWeekends() []string {
+	if x != nil {
+		return x.Weekends
+	}
+	return nil
+}
+
+func (x *VacationBlock) GetEfficiency() float64 {
+	if x != nil {
+		return x.Efficiency
+	}
+	return 0
+}
+
+func (x *VacationBlock) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+type VacationDay struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Date      string `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Note      string `protobuf:"bytes,2,opt,name=note,proto3" json:"note,omitempty"`
+	CreatedAt string `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+}
+
+func (x *VacationDay) Reset() {
+	*x = VacationDay{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VacationDay) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VacationDay) ProtoMessage() {}
+
+func (x *VacationDay) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VacationDay.ProtoReflect.Descriptor instead.
+func (*VacationDay) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *VacationDay) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *VacationDay) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *VacationDay) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type OptimalVacation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Date            string `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	BlockId         int32  `protobuf:"varint,2,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	ConsecutiveDays int32  `protobuf:"varint,3,opt,name=consecutive_days,json=consecutiveDays,proto3" json:"consecutive_days,omitempty"`
+}
+
+func (x *OptimalVacation) Reset() {
+	*x = OptimalVacation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OptimalVacation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptimalVacation) ProtoMessage() {}
+
+func (x *OptimalVacation) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptimalVacation.ProtoReflect.Descriptor instead.
+func (*OptimalVacation) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *OptimalVacation) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *OptimalVacation) GetBlockId() int32 {
+	if x != nil {
+		return x.BlockId
+	}
+	return 0
+}
+
+func (x *OptimalVacation) GetConsecutiveDays() int32 {
+	if x != nil {
+		return x.ConsecutiveDays
+	}
+	return 0
+}
+
+type CalendarSummary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TotalVacationDays int32 `protobuf:"varint,1,opt,name=total_vacation_days,json=totalVacationDays,proto3" json:"total_vacation_days,omitempty"`
+	// Days in lieu for worked holidays, included in the total
+	CompensationDays      int32             `protobuf:"varint,2,opt,name=compensation_days,json=compensationDays,proto3" json:"compensation_days,omitempty"`
+	UsedVacationDays      int32             `protobuf:"varint,3,opt,name=used_vacation_days,json=usedVacationDays,proto3" json:"used_vacation_days,omitempty"`
+	RemainingVacationDays int32             `protobuf:"varint,4,opt,name=remaining_vacation_days,json=remainingVacationDays,proto3" json:"remaining_vacation_days,omitempty"`
+	TotalHolidays         int32             `protobuf:"varint,5,opt,name=total_holidays,json=totalHolidays,proto3" json:"total_holidays,omitempty"`
+	LongestVacationBlock  int32             `protobuf:"varint,6,opt,name=longest_vacation_block,json=longestVacationBlock,proto3" json:"longest_vacation_block,omitempty"`
+	TotalDaysOff          int32             `protobuf:"varint,7,opt,name=total_days_off,json=totalDaysOff,proto3" json:"total_days_off,omitempty"`
+	Efficiency            float64           `protobuf:"fixed64,8,opt,name=efficiency,proto3" json:"efficiency,omitempty"`
+	QuarterDistribution   []*QuarterSummary `protobuf:"bytes,9,rep,name=quarter_distribution,json=quarterDistribution,proto3" json:"quarter_distribution,omitempty"`
+	// Balance in hours, only set in hours mode
+	TotalVacationHours     float64 `protobuf:"fixed64,10,opt,name=total_vacation_hours,json=totalVacationHours,proto3" json:"total_vacation_hours,omitempty"`
+	UsedVacationHours      float64 `protobuf:"fixed64,11,opt,name=used_vacation_hours,json=usedVacationHours,proto3" json:"used_vacation_hours,omitempty"`
+	RemainingVacationHours float64 `protobuf:"fixed64,12,opt,name=remaining_vacation_hours,json=remainingVacationHours,proto3" json:"remaining_vacation_hours,omitempty"`
+}
+
+func (x *CalendarSummary) Reset() {
+	*x = CalendarSummary{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CalendarSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalendarSummary) ProtoMessage() {}
+
+func (x *CalendarSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalendarSummary.ProtoReflect.Descriptor instead.
+func (*CalendarSummary) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CalendarSummary) GetTotalVacationDays() int32 {
+	if x != nil {
+		return x.TotalVacationDays
+	}
+	return 0
+}
+
+func (x *CalendarSummary) GetCompensationDays() int32 {
+	if x != nil {
+		return x.CompensationDays
+	}
+	return 0
+}
+
+func (x *CalendarSummary) GetUsedVacationDays() int32 {
+	if x != nil {
+		return x.UsedVacationDays
+	}
+	return 0
+}
+
+func (x *CalendarSummary) GetRemainingVacationDays() int32 {
+	if x != nil {
+		return x.RemainingVacationDays
+	}
+	return 0
+}
+
+func (x *CalendarSummary) GetTotalHolidays() int32 {
+	if x != nil {
+		return x.TotalHolidays
+	}
+	return 0
+}
+
+func (x *CalendarSummary) GetLongestVacationBlock() int32 {
+	if x != nil {
+		return x.LongestVacationBlock
+	}
+	return 0
+}
+
+func (x *CalendarSummary) GetTotalDaysOff() int32 {
+	if x != nil {
+		return x.TotalDaysOff
+	}
+	return 0
+}
+
+func (x *CalendarSummary) GetEfficiency() float64 {
+	if x != nil {
+		return x.Efficiency
+	}
+	return 0
+}
+
+func (x *CalendarSummary) GetQuarterDistribution() []*QuarterSummary {
+	if x != nil {
+		return x.QuarterDistribution
+	}
+	return nil
+}
+
+func (x *CalendarSummary) GetTotalVacationHours() float64 {
+	if x != nil {
+		return x.TotalVacationHours
+	}
+	return 0
+}
+
+func (x *CalendarSummary) GetUsedVacationHours() float64 {
+	if x != nil {
+		return x.UsedVacationHours
+	}
+	return 0
+}
+
+func (x *CalendarSummary) GetRemainingVacationHours() float64 {
+	if x != nil {
+		return x.RemainingVacationHours
+	}
+	return 0
+}
+
+type QuarterSummary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Quarter      int32   `protobuf:"varint,1,opt,name=quarter,proto3" json:"quarter,omitempty"`
+	VacationDays int32   `protobuf:"varint,2,opt,name=vacation_days,json=vacationDays,proto3" json:"vacation_days,omitempty"`
+	DaysOff      int32   `protobuf:"varint,3,opt,name=days_off,json=daysOff,proto3" json:"days_off,omitempty"`
+	Efficiency   float64 `protobuf:"fixed64,4,opt,name=efficiency,proto3" json:"efficiency,omitempty"`
+}
+
+func (x *QuarterSummary) Reset() {
+	*x = QuarterSummary{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QuarterSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuarterSummary) ProtoMessage() {}
+
+func (x *QuarterSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuarterSummary.ProtoReflect.Descriptor instead.
+func (*QuarterSummary) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *QuarterSummary) GetQuarter() int32 {
+	if x != nil {
+		return x.Quarter
+	}
+	return 0
+}
+
+func (x *QuarterSummary) GetVacationDays() int32 {
+	if x != nil {
+		return x.VacationDays
+	}
+	return 0
+}
+
+func (x *QuarterSummary) GetDaysOff() int32 {
+	if x != nil {
+		return x.DaysOff
+	}
+	return 0
+}
+
+func (x *QuarterSummary) GetEfficiency() float64 {
+	if x != nil {
+		return x.Efficiency
+	}
+	return 0
+}
+
+// Outcome for one date of a transactional update
+type ItemResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Date string `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	// "add", "remove" or "store"
+	Action string `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	// "applied", "unchanged", "created", "skipped_holiday", "skipped_duplicate",
+	// "over_budget", "failed" or "rolled_back"
+	Status string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Error  string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *ItemResult) Reset() {
+	*x = ItemResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ItemResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItemResult) ProtoMessage() {}
+
+func (x *ItemResult) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ItemResult.ProtoReflect.Descriptor instead.
+func (*ItemResult) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ItemResult) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *ItemResult) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ItemResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ItemResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListVacationsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Year int32 `protobuf:"varint,1,opt,name=year,proto3" json:"year,omitempty"`
+}
+
+func (x *ListVacationsRequest) Reset() {
+	*x = ListVacationsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListVacationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVacationsRequest) ProtoMessage() {}
+
+func (x *ListVacationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVacationsRequest.ProtoReflect.Descriptor instead.
+func (*ListVacationsRequest) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListVacationsRequest) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+type ListVacationsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Vacations []*VacationDay `protobuf:"bytes,1,rep,name=vacations,proto3" json:"vacations,omitempty"`
+}
+
+func (x *ListVacationsResponse) Reset() {
+	*x = ListVacationsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListVacationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVacationsResponse) ProtoMessage() {}
+
+func (x *ListVacationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVacationsResponse.ProtoReflect.Descriptor instead.
+func (*ListVacationsResponse) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListVacationsResponse) GetVacations() []*VacationDay {
+	if x != nil {
+		return x.Vacations
+	}
+	return nil
+}
+
+type AddVacationRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Year int32  `protobuf:"varint,1,opt,name=year,proto3" json:"year,omitempty"`
+	Date string `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+	Note string `protobuf:"bytes,3,opt,name=note,proto3" json:"note,omitempty"`
+}
+
+func (x *AddVacationRequest) Reset() {
+	*x = AddVacationRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddVacationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddVacationRequest) ProtoMessage() {}
+
+func (x *AddVacationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddVacationRequest.ProtoReflect.Descriptor instead.
+func (*AddVacationRequest) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *AddVacationRequest) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+func (x *AddVacationRequest) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *AddVacationRequest) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type AddVacationResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *AddVacationResponse) Reset() {
+	*x = AddVacationResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddVacationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddVacationResponse) ProtoMessage() {}
+
+func (x *AddVacationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddVacationResponse.ProtoReflect.Descriptor instead.
+func (*AddVacationResponse) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{16}
+}
+
+type RemoveVacationRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Year int32  `protobuf:"varint,1,opt,name=year,proto3" json:"year,omitempty"`
+	Date string `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+}
+
+func (x *RemoveVacationRequest) Reset() {
+	*x = RemoveVacationRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveVacationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveVacationRequest) ProtoMessage() {}
+
+func (x *RemoveVacationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveVacationRequest.ProtoReflect.Descriptor instead.
+func (*RemoveVacationRequest) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RemoveVacationRequest) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+func (x *RemoveVacationRequest) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+type RemoveVacationResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *RemoveVacationResponse) Reset() {
+	*x = RemoveVacationResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveVacationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveVacationResponse) ProtoMessage() {}
+
+func (x *RemoveVacationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveVacationResponse.ProtoReflect.Descriptor instead.
+func (*RemoveVacationResponse) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{18}
+}
+
+type UpdateVacationsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Year   int32    `protobuf:"varint,1,opt,name=year,proto3" json:"year,omitempty"`
+	Add    []string `protobuf:"bytes,2,rep,name=add,proto3" json:"add,omitempty"`
+	Remove []string `protobuf:"bytes,3,rep,name=remove,proto3" json:"remove,omitempty"`
+}
+
+func (x *UpdateVacationsRequest) Reset() {
+	*x = UpdateVacationsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateVacationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateVacationsRequest) ProtoMessage() {}
+
+func (x *UpdateVacationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateVacationsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateVacationsRequest) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *UpdateVacationsRequest) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+func (x *UpdateVacationsRequest) GetAdd() []string {
+	if x != nil {
+		return x.Add
+	}
+	return nil
+}
+
+func (x *UpdateVacationsRequest) GetRemove() []string {
+	if x != nil {
+		return x.Remove
+	}
+	return nil
+}
+
+type UpdateVacationsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Results []*ItemResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *UpdateVacationsResponse) Reset() {
+	*x = UpdateVacationsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateVacationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateVacationsResponse) ProtoMessage() {}
+
+func (x *UpdateVacationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateVacationsResponse.ProtoReflect.Descriptor instead.
+func (*UpdateVacationsResponse) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *UpdateVacationsResponse) GetResults() []*ItemResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type OptimizeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Year int32 `protobuf:"varint,1,opt,name=year,proto3" json:"year,omitempty"`
+}
+
+func (x *OptimizeRequest) Reset() {
+	*x = OptimizeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OptimizeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptimizeRequest) ProtoMessage() {}
+
+func (x *OptimizeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptimizeRequest.ProtoReflect.Descriptor instead.
+func (*OptimizeRequest) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *OptimizeRequest) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+type OptimizeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Blocks  []*VacationBlock `protobuf:"bytes,1,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	Results []*ItemResult    `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *OptimizeResponse) Reset() {
+	*x = OptimizeResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OptimizeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptimizeResponse) ProtoMessage() {}
+
+func (x *OptimizeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptimizeResponse.ProtoReflect.Descriptor instead.
+func (*OptimizeResponse) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *OptimizeResponse) GetBlocks() []*VacationBlock {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+func (x *OptimizeResponse) GetResults() []*ItemResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ClearOptimizedVacationsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Year int32 `protobuf:"varint,1,opt,name=year,proto3" json:"year,omitempty"`
+}
+
+func (x *ClearOptimizedVacationsRequest) Reset() {
+	*x = ClearOptimizedVacationsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClearOptimizedVacationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearOptimizedVacationsRequest) ProtoMessage() {}
+
+func (x *ClearOptimizedVacationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearOptimizedVacationsRequest.ProtoReflect.Descriptor instead.
+func (*ClearOptimizedVacationsRequest) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ClearOptimizedVacationsRequest) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+type ClearOptimizedVacationsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ClearOptimizedVacationsResponse) Reset() {
+	*x = ClearOptimizedVacationsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClearOptimizedVacationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearOptimizedVacationsResponse) ProtoMessage() {}
+
+func (x *ClearOptimizedVacationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vacationplanner_v1_vacationplanner_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearOptimizedVacationsResponse.ProtoReflect.Descriptor instead.
+func (*ClearOptimizedVacationsResponse) Descriptor() ([]byte, []int) {
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP(), []int{24}
+}
+
+var File_vacationplanner_v1_vacationplanner_proto protoreflect.FileDescriptor
+
+var file_vacationplanner_v1_vacationplanner_proto_rawDesc = []byte{
+	0x0a, 0x28, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65,
+	0x72, 0x2f, 0x76, 0x31, 0x2f, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61,
+	0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x12, 0x76, 0x61, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x22, 0x28,
+	0x0a, 0x12, 0x47, 0x65, 0x74, 0x43, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x79, 0x65, 0x61, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x04, 0x79, 0x65, 0x61, 0x72, 0x22, 0x4f, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x43,
+	0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x38, 0x0a, 0x08, 0x63, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1c, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e,
+	0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x52,
+	0x08, 0x63, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x22, 0xed, 0x03, 0x0a, 0x08, 0x43, 0x61,
+	0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x79, 0x65, 0x61, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x79, 0x65, 0x61, 0x72, 0x12, 0x36, 0x0a, 0x06, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x76, 0x61, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x59, 0x65, 0x61, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x12, 0x33, 0x0a, 0x04, 0x64, 0x61, 0x79, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1f, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e,
+	0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x44, 0x61,
+	0x79, 0x52, 0x04, 0x64, 0x61, 0x79, 0x73, 0x12, 0x37, 0x0a, 0x08, 0x68, 0x6f, 0x6c, 0x69, 0x64,
+	0x61, 0x79, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x76, 0x61, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x48,
+	0x6f, 0x6c, 0x69, 0x64, 0x61, 0x79, 0x52, 0x08, 0x68, 0x6f, 0x6c, 0x69, 0x64, 0x61, 0x79, 0x73,
+	0x12, 0x4a, 0x0a, 0x0f, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x76, 0x61, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x56,
+	0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x0e, 0x76, 0x61,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x4a, 0x0a, 0x10,
+	0x6d, 0x61, 0x6e, 0x75, 0x61, 0x6c, 0x5f, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x79, 0x52, 0x0f, 0x6d, 0x61, 0x6e, 0x75, 0x61, 0x6c, 0x56,
+	0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x50, 0x0a, 0x11, 0x6f, 0x70, 0x74, 0x69,
+	0x6d, 0x61, 0x6c, 0x5f, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x07, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c,
+	0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70, 0x74, 0x69, 0x6d, 0x61, 0x6c,
+	0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x10, 0x6f, 0x70, 0x74, 0x69, 0x6d, 0x61,
+	0x6c, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x3d, 0x0a, 0x07, 0x73, 0x75,
+	0x6d, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x76, 0x61,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79,
+	0x52, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x22, 0xcd, 0x04, 0x0a, 0x0a, 0x59, 0x65,
+	0x61, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x79, 0x65, 0x61, 0x72,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x79, 0x65, 0x61, 0x72, 0x12, 0x23, 0x0a, 0x0d,
+	0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0c, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x79,
+	0x73, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x5f, 0x64, 0x61,
+	0x79, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x64, 0x44, 0x61, 0x79, 0x73, 0x12, 0x33, 0x0a, 0x15, 0x6f, 0x70, 0x74, 0x69, 0x6d, 0x69,
+	0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x6f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x77,
+	0x6f, 0x72, 0x6b, 0x5f, 0x77, 0x65, 0x65, 0x6b, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08,
+	0x77, 0x6f, 0x72, 0x6b, 0x57, 0x65, 0x65, 0x6b, 0x12, 0x27, 0x0a, 0x0f, 0x6f, 0x70, 0x74, 0x69,
+	0x6d, 0x69, 0x7a, 0x65, 0x72, 0x5f, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0e, 0x6f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x65, 0x72, 0x4e, 0x6f, 0x74, 0x65,
+	0x73, 0x12, 0x2e, 0x0a, 0x13, 0x61, 0x6c, 0x69, 0x67, 0x6e, 0x5f, 0x73, 0x63, 0x68, 0x6f, 0x6f,
+	0x6c, 0x5f, 0x62, 0x72, 0x65, 0x61, 0x6b, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11,
+	0x61, 0x6c, 0x69, 0x67, 0x6e, 0x53, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x42, 0x72, 0x65, 0x61, 0x6b,
+	0x73, 0x12, 0x4e, 0x0a, 0x11, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x77, 0x65, 0x65, 0x6b, 0x5f, 0x63,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x76,
+	0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76,
+	0x31, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x57, 0x65, 0x65, 0x6b, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x52, 0x0f, 0x77, 0x6f, 0x72, 0x6b, 0x57, 0x65, 0x65, 0x6b, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x73, 0x12, 0x27, 0x0a, 0x0f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x69, 0x6e, 0x67, 0x5f,
+	0x6d, 0x6f, 0x64, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x61, 0x63, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x69, 0x6e, 0x67, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x76, 0x61,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x73, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x0d, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x6f, 0x75, 0x72,
+	0x73, 0x12, 0x55, 0x0a, 0x0d, 0x77, 0x6f, 0x72, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x68, 0x6f, 0x75,
+	0x72, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x30, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x59, 0x65,
+	0x61, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x69, 0x6e, 0x67,
+	0x48, 0x6f, 0x75, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c, 0x77, 0x6f, 0x72, 0x6b,
+	0x69, 0x6e, 0x67, 0x48, 0x6f, 0x75, 0x72, 0x73, 0x1a, 0x3f, 0x0a, 0x11, 0x57, 0x6f, 0x72, 0x6b,
+	0x69, 0x6e, 0x67, 0x48, 0x6f, 0x75, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x54, 0x0a, 0x0e, 0x57, 0x6f, 0x72,
+	0x6b, 0x57, 0x65, 0x65, 0x6b, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x65,
+	0x66, 0x66, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0d, 0x65, 0x66, 0x66, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x46, 0x72,
+	0x6f, 0x6d, 0x12, 0x1b, 0x0a, 0x09, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x77, 0x65, 0x65, 0x6b, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x77, 0x6f, 0x72, 0x6b, 0x57, 0x65, 0x65, 0x6b, 0x22,
+	0x9a, 0x02, 0x0a, 0x0b, 0x43, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x44, 0x61, 0x79, 0x12,
+	0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64,
+	0x61, 0x74, 0x65, 0x12, 0x1e, 0x0a, 0x0b, 0x64, 0x61, 0x79, 0x5f, 0x6f, 0x66, 0x5f, 0x77, 0x65,
+	0x65, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x64, 0x61, 0x79, 0x4f, 0x66, 0x57,
+	0x65, 0x65, 0x6b, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x73, 0x5f, 0x77, 0x65, 0x65, 0x6b, 0x65, 0x6e,
+	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x69, 0x73, 0x57, 0x65, 0x65, 0x6b, 0x65,
+	0x6e, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x73, 0x5f, 0x68, 0x6f, 0x6c, 0x69, 0x64, 0x61, 0x79,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x69, 0x73, 0x48, 0x6f, 0x6c, 0x69, 0x64, 0x61,
+	0x79, 0x12, 0x21, 0x0a, 0x0c, 0x68, 0x6f, 0x6c, 0x69, 0x64, 0x61, 0x79, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x68, 0x6f, 0x6c, 0x69, 0x64, 0x61, 0x79,
+	0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x73, 0x5f, 0x76, 0x61, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x69, 0x73, 0x56, 0x61, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x73, 0x5f, 0x6d, 0x61, 0x6e, 0x75,
+	0x61, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69, 0x73, 0x4d, 0x61, 0x6e, 0x75,
+	0x61, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x73, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6d, 0x61, 0x6c,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x69, 0x73, 0x4f, 0x70, 0x74, 0x69, 0x6d, 0x61,
+	0x6c, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x22, 0x68, 0x0a, 0x07,
+	0x48, 0x6f, 0x6c, 0x69, 0x64, 0x61, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x5f,
+	0x66, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6f, 0x62, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x64, 0x46, 0x6f, 0x72, 0x22, 0x9c, 0x02, 0x0a, 0x0d, 0x56, 0x61, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x44, 0x61, 0x74, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x5f, 0x64,
+	0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x65, 0x6e, 0x64, 0x44, 0x61,
+	0x74, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x64, 0x61, 0x79, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x44, 0x61, 0x79,
+	0x73, 0x12, 0x2c, 0x0a, 0x12, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x61,
+	0x79, 0x73, 0x5f, 0x75, 0x73, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x76,
+	0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x79, 0x73, 0x55, 0x73, 0x65, 0x64, 0x12,
+	0x14, 0x0a, 0x05, 0x64, 0x61, 0x74, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05,
+	0x64, 0x61, 0x74, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x6c, 0x69, 0x64, 0x61, 0x79,
+	0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x68, 0x6f, 0x6c, 0x69, 0x64, 0x61, 0x79,
+	0x73, 0x12, 0x1a, 0x0a, 0x08, 0x77, 0x65, 0x65, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x18, 0x07, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x08, 0x77, 0x65, 0x65, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x12, 0x1e, 0x0a,
+	0x0a, 0x65, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0a, 0x65, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x22, 0x54, 0x0a, 0x0b, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x44, 0x61, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x6f, 0x74, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x12, 0x1d, 0x0a, 0x0a,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0x6b, 0x0a, 0x0f, 0x4f,
+	0x70, 0x74, 0x69, 0x6d, 0x61, 0x6c, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12,
+	0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61,
+	0x74, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x29, 0x0a,
+	0x10, 0x63, 0x6f, 0x6e, 0x73, 0x65, 0x63, 0x75, 0x74, 0x69, 0x76, 0x65, 0x5f, 0x64, 0x61, 0x79,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x63, 0x6f, 0x6e, 0x73, 0x65, 0x63, 0x75,
+	0x74, 0x69, 0x76, 0x65, 0x44, 0x61, 0x79, 0x73, 0x22, 0xea, 0x04, 0x0a, 0x0f, 0x43, 0x61, 0x6c,
+	0x65, 0x6e, 0x64, 0x61, 0x72, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x2e, 0x0a, 0x13,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64,
+	0x61, 0x79, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x11, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x79, 0x73, 0x12, 0x2b, 0x0a, 0x11,
+	0x63, 0x6f, 0x6d, 0x70, 0x65, 0x6e, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x79,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x63, 0x6f, 0x6d, 0x70, 0x65, 0x6e, 0x73,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x79, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x75, 0x73, 0x65,
+	0x64, 0x5f, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x75, 0x73, 0x65, 0x64, 0x56, 0x61, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x44, 0x61, 0x79, 0x73, 0x12, 0x36, 0x0a, 0x17, 0x72, 0x65, 0x6d, 0x61, 0x69,
+	0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x61,
+	0x79, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x15, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e,
+	0x69, 0x6e, 0x67, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x79, 0x73, 0x12,
+	0x25, 0x0a, 0x0e, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x68, 0x6f, 0x6c, 0x69, 0x64, 0x61, 0x79,
+	0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x48, 0x6f,
+	0x6c, 0x69, 0x64, 0x61, 0x79, 0x73, 0x12, 0x34, 0x0a, 0x16, 0x6c, 0x6f, 0x6e, 0x67, 0x65, 0x73,
+	0x74, 0x5f, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x14, 0x6c, 0x6f, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x56,
+	0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x24, 0x0a, 0x0e,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x5f, 0x6f, 0x66, 0x66, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x44, 0x61, 0x79, 0x73, 0x4f,
+	0x66, 0x66, 0x12, 0x1e, 0x0a, 0x0a, 0x65, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65, 0x6e, 0x63, 0x79,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x65, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65, 0x6e,
+	0x63, 0x79, 0x12, 0x55, 0x0a, 0x14, 0x71, 0x75, 0x61, 0x72, 0x74, 0x65, 0x72, 0x5f, 0x64, 0x69,
+	0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x22, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e,
+	0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x61, 0x72, 0x74, 0x65, 0x72, 0x53, 0x75, 0x6d,
+	0x6d, 0x61, 0x72, 0x79, 0x52, 0x13, 0x71, 0x75, 0x61, 0x72, 0x74, 0x65, 0x72, 0x44, 0x69, 0x73,
+	0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x30, 0x0a, 0x14, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x5f, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x68, 0x6f, 0x75, 0x72,
+	0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x01, 0x52, 0x12, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x56, 0x61,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x6f, 0x75, 0x72, 0x73, 0x12, 0x2e, 0x0a, 0x13, 0x75,
+	0x73, 0x65, 0x64, 0x5f, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x68, 0x6f, 0x75,
+	0x72, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x11, 0x75, 0x73, 0x65, 0x64, 0x56, 0x61,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x6f, 0x75, 0x72, 0x73, 0x12, 0x38, 0x0a, 0x18, 0x72,
+	0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x01, 0x52, 0x16, 0x72,
+	0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x48, 0x6f, 0x75, 0x72, 0x73, 0x22, 0x8a, 0x01, 0x0a, 0x0e, 0x51, 0x75, 0x61, 0x72, 0x74, 0x65,
+	0x72, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x71, 0x75, 0x61, 0x72,
+	0x74, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x71, 0x75, 0x61, 0x72, 0x74,
+	0x65, 0x72, 0x12, 0x23, 0x0a, 0x0d, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64,
+	0x61, 0x79, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x76, 0x61, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x44, 0x61, 0x79, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x64, 0x61, 0x79, 0x73, 0x5f,
+	0x6f, 0x66, 0x66, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x64, 0x61, 0x79, 0x73, 0x4f,
+	0x66, 0x66, 0x12, 0x1e, 0x0a, 0x0a, 0x65, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65, 0x6e, 0x63, 0x79,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x65, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65, 0x6e,
+	0x63, 0x79, 0x22, 0x66, 0x0a, 0x0a, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x64, 0x61, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x2a, 0x0a, 0x14, 0x4c, 0x69,
+	0x73, 0x74, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x79, 0x65, 0x61, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x04, 0x79, 0x65, 0x61, 0x72, 0x22, 0x56, 0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x61,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x3d, 0x0a, 0x09, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61,
+	0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x44, 0x61, 0x79, 0x52, 0x09, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x50,
+	0x0a, 0x12, 0x41, 0x64, 0x64, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x79, 0x65, 0x61, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x04, 0x79, 0x65, 0x61, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x6e, 0x6f, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x6f, 0x74, 0x65,
+	0x22, 0x15, 0x0a, 0x13, 0x41, 0x64, 0x64, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x3f, 0x0a, 0x15, 0x52, 0x65, 0x6d, 0x6f, 0x76,
+	0x65, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x12, 0x0a, 0x04, 0x79, 0x65, 0x61, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04,
+	0x79, 0x65, 0x61, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x22, 0x18, 0x0a, 0x16, 0x52, 0x65, 0x6d, 0x6f,
+	0x76, 0x65, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x56, 0x0a, 0x16, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x56, 0x61, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x79, 0x65, 0x61, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x79, 0x65, 0x61, 0x72,
+	0x12, 0x10, 0x0a, 0x03, 0x61, 0x64, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x03, 0x61,
+	0x64, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x22, 0x53, 0x0a, 0x17, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x38, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x74, 0x65, 0x6d,
+	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x22,
+	0x25, 0x0a, 0x0f, 0x4f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x79, 0x65, 0x61, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x04, 0x79, 0x65, 0x61, 0x72, 0x22, 0x87, 0x01, 0x0a, 0x10, 0x4f, 0x70, 0x74, 0x69, 0x6d,
+	0x69, 0x7a, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x39, 0x0a, 0x06, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x76, 0x61,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x06,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x38, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x74, 0x65,
+	0x6d, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73,
+	0x22, 0x34, 0x0a, 0x1e, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x4f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a,
+	0x65, 0x64, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x79, 0x65, 0x61, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x04, 0x79, 0x65, 0x61, 0x72, 0x22, 0x21, 0x0a, 0x1f, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x4f,
+	0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x65, 0x64, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xef, 0x05, 0x0a, 0x16, 0x56, 0x61,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x12, 0x5e, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x43, 0x61, 0x6c, 0x65, 0x6e,
+	0x64, 0x61, 0x72, 0x12, 0x26, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c,
+	0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x61, 0x6c, 0x65,
+	0x6e, 0x64, 0x61, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x76, 0x61,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x47, 0x65, 0x74, 0x43, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x64, 0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x61, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x28, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x56,
+	0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x29, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5e, 0x0a, 0x0b, 0x41, 0x64,
+	0x64, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x26, 0x2e, 0x76, 0x61, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x41,
+	0x64, 0x64, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x27, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e,
+	0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x64, 0x64, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x67, 0x0a, 0x0e, 0x52, 0x65,
+	0x6d, 0x6f, 0x76, 0x65, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x29, 0x2e, 0x76,
+	0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76,
+	0x31, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2a, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6d,
+	0x6f, 0x76, 0x65, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x6a, 0x0a, 0x0f, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x56, 0x61, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x2a, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x2b, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61,
+	0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x56, 0x61,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x55, 0x0a, 0x08, 0x4f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x65, 0x12, 0x23, 0x2e, 0x76, 0x61,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x4f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x24, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e,
+	0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x82, 0x01, 0x0a, 0x17, 0x43, 0x6c, 0x65, 0x61, 0x72,
+	0x4f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x65, 0x64, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x32, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61,
+	0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x4f, 0x70, 0x74,
+	0x69, 0x6d, 0x69, 0x7a, 0x65, 0x64, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x33, 0x2e, 0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x65, 0x61,
+	0x72, 0x4f, 0x70, 0x74, 0x69, 0x6d, 0x69, 0x7a, 0x65, 0x64, 0x56, 0x61, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x54, 0x5a, 0x52, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x62, 0x72, 0x75, 0x6e, 0x6f, 0x2e,
+	0x6c, 0x6f, 0x70, 0x65, 0x73, 0x2f, 0x63, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x2f, 0x62,
+	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x61, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x2f, 0x76, 0x31, 0x3b,
+	0x76, 0x61, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x72, 0x76,
+	0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_vacationplanner_v1_vacationplanner_proto_rawDescOnce sync.Once
+	file_vacationplanner_v1_vacationplanner_proto_rawDescData = file_vacationplanner_v1_vacationplanner_proto_rawDesc
+)
+
+func file_vacationplanner_v1_vacationplanner_proto_rawDescGZIP() []byte {
+	file_vacationplanner_v1_vacationplanner_proto_rawDescOnce.Do(func() {
+		file_vacationplanner_v1_vacationplanner_proto_rawDescData = protoimpl.X.CompressGZIP(file_vacationplanner_v1_vacationplanner_proto_rawDescData)
+	})
+	return file_vacationplanner_v1_vacationplanner_proto_rawDescData
+}
+
+var file_vacationplanner_v1_vacationplanner_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
+var file_vacationplanner_v1_vacationplanner_proto_goTypes = []any{
+	(*GetCalendarRequest)(nil),              // 0: vacationplanner.v1.GetCalendarRequest
+	(*GetCalendarResponse)(nil),             // 1: vacationplanner.v1.GetCalendarResponse
+	(*Calendar)(nil),                        // 2: vacationplanner.v1.Calendar
+	(*YearConfig)(nil),                      // 3: vacationplanner.v1.YearConfig
+	(*WorkWeekChange)(nil),                  // 4: vacationplanner.v1.WorkWeekChange
+	(*CalendarDay)(nil),                     // 5: vacationplanner.v1.CalendarDay
+	(*Holiday)(nil),                         // 6: vacationplanner.v1.Holiday
+	(*VacationBlock)(nil),                   // 7: vacationplanner.v1.VacationBlock
+	(*VacationDay)(nil),                     // 8: vacationplanner.v1.VacationDay
+	(*OptimalVacation)(nil),                 // 9: vacationplanner.v1.OptimalVacation
+	(*CalendarSummary)(nil),                 // 10: vacationplanner.v1.CalendarSummary
+	(*QuarterSummary)(nil),                  // 11: vacationplanner.v1.QuarterSummary
+	(*ItemResult)(nil),                      // 12: vacationplanner.v1.ItemResult
+	(*ListVacationsRequest)(nil),            // 13: vacationplanner.v1.ListVacationsRequest
+	(*ListVacationsResponse)(nil),           // 14: vacationplanner.v1.ListVacationsResponse
+	(*AddVacationRequest)(nil),              // 15: vacationplanner.v1.AddVacationRequest
+	(*AddVacationResponse)(nil),             // 16: vacationplanner.v1.AddVacationResponse
+	(*RemoveVacationRequest)(nil),           // 17: vacationplanner.v1.RemoveVacationRequest
+	(*RemoveVacationResponse)(nil),          // 18: vacationplanner.v1.RemoveVacationResponse
+	(*UpdateVacationsRequest)(nil),          // 19: vacationplanner.v1.UpdateVacationsRequest
+	(*UpdateVacationsResponse)(nil),         // 20: vacationplanner.v1.UpdateVacationsResponse
+	(*OptimizeRequest)(nil),                 // 21: vacationplanner.v1.OptimizeRequest
+	(*OptimizeResponse)(nil),                // 22: vacationplanner.v1.OptimizeResponse
+	(*ClearOptimizedVacationsRequest)(nil),  // 23: vacationplanner.v1.ClearOptimizedVacationsRequest
+	(*ClearOptimizedVacationsResponse)(nil), // 24: vacationplanner.v1.ClearOptimizedVacationsResponse
+	nil,                                     // 25: vacationplanner.v1.YearConfig.WorkingHoursEntry
+}
+var file_vacationplanner_v1_vacationplanner_proto_depIdxs = []int32{
+	2,  // 0: vacationplanner.v1.GetCalendarResponse.calendar:type_name -> vacationplanner.v1.Calendar
+	3,  // 1: vacationplanner.v1.Calendar.config:type_name -> vacationplanner.v1.YearConfig
+	5,  // 2: vacationplanner.v1.Calendar.days:type_name -> vacationplanner.v1.CalendarDay
+	6,  // 3: vacationplanner.v1.Calendar.holidays:type_name -> vacationplanner.v1.Holiday
+	7,  // 4: vacationplanner.v1.Calendar.vacation_blocks:type_name -> vacationplanner.v1.VacationBlock
+	8,  // 5: vacationplanner.v1.Calendar.manual_vacations:type_name -> vacationplanner.v1.VacationDay
+	9,  // 6: vacationplanner.v1.Calendar.optimal_vacations:type_name -> vacationplanner.v1.OptimalVacation
+	10, // 7: vacationplanner.v1.Calendar.summary:type_name -> vacationplanner.v1.CalendarSummary
+	4,  // 8: vacationplanner.v1.YearConfig.work_week_changes:type_name -> vacationplanner.v1.WorkWeekChange
+	25, // 9: vacationplanner.v1.YearConfig.working_hours:type_name -> vacationplanner.v1.YearConfig.WorkingHoursEntry
+	11, // 10: vacationplanner.v1.CalendarSummary.quarter_distribution:type_name -> vacationplanner.v1.QuarterSummary
+	8,  // 11: vacationplanner.v1.ListVacationsResponse.vacations:type_name -> vacationplanner.v1.VacationDay
+	12, // 12: vacationplanner.v1.UpdateVacationsResponse.results:type_name -> vacationplanner.v1.ItemResult
+	7,  // 13: vacationplanner.v1.OptimizeResponse.blocks:type_name -> vacationplanner.v1.VacationBlock
+	12, // 14: vacationplanner.v1.OptimizeResponse.results:type_name -> vacationplanner.v1.ItemResult
+	0,  // 15: vacationplanner.v1.VacationPlannerService.GetCalendar:input_type -> vacationplanner.v1.GetCalendarRequest
+	13, // 16: vacationplanner.v1.VacationPlannerService.ListVacations:input_type -> vacationplanner.v1.ListVacationsRequest
+	15, // 17: vacationplanner.v1.VacationPlannerService.AddVacation:input_type -> vacationplanner.v1.AddVacationRequest
+	17, // 18: vacationplanner.v1.VacationPlannerService.RemoveVacation:input_type -> vacationplanner.v1.RemoveVacationRequest
+	19, // 19: vacationplanner.v1.VacationPlannerService.UpdateVacations:input_type -> vacationplanner.v1.UpdateVacationsRequest
+	21, // 20: vacationplanner.v1.VacationPlannerService.Optimize:input_type -> vacationplanner.v1.OptimizeRequest
+	23, // 21: vacationplanner.v1.VacationPlannerService.ClearOptimizedVacations:input_type -> vacationplanner.v1.ClearOptimizedVacationsRequest
+	1,  // 22: vacationplanner.v1.VacationPlannerService.GetCalendar:output_type -> vacationplanner.v1.GetCalendarResponse
+	14, // 23: vacationplanner.v1.VacationPlannerService.ListVacations:output_type -> vacationplanner.v1.ListVacationsResponse
+	16, // 24: vacationplanner.v1.VacationPlannerService.AddVacation:output_type -> vacationplanner.v1.AddVacationResponse
+	18, // 25: vacationplanner.v1.VacationPlannerService.RemoveVacation:output_type -> vacationplanner.v1.RemoveVacationResponse
+	20, // 26: vacationplanner.v1.VacationPlannerService.UpdateVacations:output_type -> vacationplanner.v1.UpdateVacationsResponse
+	22, // 27: vacationplanner.v1.VacationPlannerService.Optimize:output_type -> vacationplanner.v1.OptimizeResponse
+	24, // 28: vacationplanner.v1.VacationPlannerService.ClearOptimizedVacations:output_type -> vacationplanner.v1.ClearOptimizedVacationsResponse
+	22, // [22:29] is the sub-list for method output_type
+	15, // [15:22] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_vacationplanner_v1_vacationplanner_proto_init() }
+func file_vacationplanner_v1_vacationplanner_proto_init() {
+	if File_vacationplanner_v1_vacationplanner_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*GetCalendarRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*GetCalendarResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*Calendar); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*YearConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*WorkWeekChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*CalendarDay); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*Holiday); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*VacationBlock); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*VacationDay); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*OptimalVacation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*CalendarSummary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*QuarterSummary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*ItemResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*ListVacationsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[14].Exporter = func(v any, i int) any {
+			switch v := v.(*ListVacationsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[15].Exporter = func(v any, i int) any {
+			switch v := v.(*AddVacationRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[16].Exporter = func(v any, i int) any {
+			switch v := v.(*AddVacationResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[17].Exporter = func(v any, i int) any {
+			switch v := v.(*RemoveVacationRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[18].Exporter = func(v any, i int) any {
+			switch v := v.(*RemoveVacationResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[19].Exporter = func(v any, i int) any {
+			switch v := v.(*UpdateVacationsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[20].Exporter = func(v any, i int) any {
+			switch v := v.(*UpdateVacationsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[21].Exporter = func(v any, i int) any {
+			switch v := v.(*OptimizeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[22].Exporter = func(v any, i int) any {
+			switch v := v.(*OptimizeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[23].Exporter = func(v any, i int) any {
+			switch v := v.(*ClearOptimizedVacationsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vacationplanner_v1_vacationplanner_proto_msgTypes[24].Exporter = func(v any, i int) any {
+			switch v := v.(*ClearOptimizedVacationsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_vacationplanner_v1_vacationplanner_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   26,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_vacationplanner_v1_vacationplanner_proto_goTypes,
+		DependencyIndexes: file_vacationplanner_v1_vacationplanner_proto_depIdxs,
+		MessageInfos:      file_vacationplanner_v1_vacationplanner_proto_msgTypes,
+	}.Build()
+	File_vacationplanner_v1_vacationplanner_proto = out.File
+	file_vacationplanner_v1_vacationplanner_proto_rawDesc = nil
+	file_vacationplanner_v1_vacationplanner_proto_goTypes = nil
+	file_vacationplanner_v1_vacationplanner_proto_depIdxs = nil
+}
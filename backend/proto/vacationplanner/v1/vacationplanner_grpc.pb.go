@@ -0,0 +1,362 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: vacationplanner/v1/vacationplanner.proto
+
+// Calendar, vacation and optimizer API, mirroring the REST endpoints under
+// /api/calendar and /api/vacations. Dates are YYYY-MM-DD strings, as in the
+// REST API.
+
+package vacationplannerv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	VacationPlannerService_GetCalendar_FullMethodName             = "/vacationplanner.v1.VacationPlannerService/GetCalendar"
+	VacationPlannerService_ListVacations_FullMethodName           = "/vacationplanner.v1.VacationPlannerService/ListVacations"
+	VacationPlannerService_AddVacation_FullMethodName             = "/vacationplanner.v1.VacationPlannerService/AddVacation"
+	VacationPlannerService_RemoveVacation_FullMethodName          = "/vacationplanner.v1.VacationPlannerService/RemoveVacation"
+	VacationPlannerService_UpdateVacations_FullMethodName         = "/vacationplanner.v1.VacationPlannerService/UpdateVacations"
+	VacationPlannerService_Optimize_FullMethodName                = "/vacationplanner.v1.VacationPlannerService/Optimize"
+	VacationPlannerService_ClearOptimizedVacations_FullMethodName = "/vacationplanner.v1.VacationPlannerService/ClearOptimizedVacations"
+)
+
+// VacationPlannerServiceClient is the client API for VacationPlannerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type VacationPlannerServiceClient interface {
+	// Full calendar of a year with holidays, vacations and summary
+	GetCalendar(ctx context.Context, in *GetCalendarRequest, opts ...grpc.CallOption) (*GetCalendarResponse, error)
+	// Manual vacation days of a year
+	ListVacations(ctx context.Context, in *ListVacationsRequest, opts ...grpc.CallOption) (*ListVacationsResponse, error)
+	AddVacation(ctx context.Context, in *AddVacationRequest, opts ...grpc.CallOption) (*AddVacationResponse, error)
+	RemoveVacation(ctx context.Context, in *RemoveVacationRequest, opts ...grpc.CallOption) (*RemoveVacationResponse, error)
+	// Adds and removes manual vacation days in one transaction
+	UpdateVacations(ctx context.Context, in *UpdateVacationsRequest, opts ...grpc.CallOption) (*UpdateVacationsResponse, error)
+	// Runs the year's optimization strategy and stores the optimized days
+	Optimize(ctx context.Context, in *OptimizeRequest, opts ...grpc.CallOption) (*OptimizeResponse, error)
+	ClearOptimizedVacations(ctx context.Context, in *ClearOptimizedVacationsRequest, opts ...grpc.CallOption) (*ClearOptimizedVacationsResponse, error)
+}
+
+type vacationPlannerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVacationPlannerServiceClient(cc grpc.ClientConnInterface) VacationPlannerServiceClient {
+	return &vacationPlannerServiceClient{cc}
+}
+
+func (c *vacationPlannerServiceClient) GetCalendar(ctx context.Context, in *GetCalendarRequest, opts ...grpc.CallOption) (*GetCalendarResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCalendarResponse)
+	err := c.cc.Invoke(ctx, VacationPlannerService_GetCalendar_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vacationPlannerServiceClient) ListVacations(ctx context.Context, in *ListVacationsRequest, opts ...grpc.CallOption) (*ListVacationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListVacationsResponse)
+	err := c.cc.Invoke(ctx, VacationPlannerService_ListVacations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vacationPlannerServiceClient) AddVacation(ctx context.Context, in *AddVacationRequest, opts ...grpc.CallOption) (*AddVacationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddVacationResponse)
+	err := c.cc.Invoke(ctx, VacationPlannerService_AddVacation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vacationPlannerServiceClient) RemoveVacation(ctx context.Context, in *RemoveVacationRequest, opts ...grpc.CallOption) (*RemoveVacationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveVacationResponse)
+	err := c.cc.Invoke(ctx, VacationPlannerService_RemoveVacation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vacationPlannerServiceClient) UpdateVacations(ctx context.Context, in *UpdateVacationsRequest, opts ...grpc.CallOption) (*UpdateVacationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateVacationsResponse)
+	err := c.cc.Invoke(ctx, VacationPlannerService_UpdateVacations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vacationPlannerServiceClient) Optimize(ctx context.Context, in *OptimizeRequest, opts ...grpc.CallOption) (*OptimizeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OptimizeResponse)
+	err := c.cc.Invoke(ctx, VacationPlannerService_Optimize_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vacationPlannerServiceClient) ClearOptimizedVacations(ctx context.Context, in *ClearOptimizedVacationsRequest, opts ...grpc.CallOption) (*ClearOptimizedVacationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClearOptimizedVacationsResponse)
+	err := c.cc.Invoke(ctx, VacationPlannerService_ClearOptimizedVacations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// VacationPlannerServiceServer is the server API for VacationPlannerService service.
+// All implementations must embed UnimplementedVacationPlannerServiceServer
+// for forward compatibility.
+type VacationPlannerServiceServer interface {
+	// Full calendar of a year with holidays, vacations and summary
+	GetCalendar(context.Context, *GetCalendarRequest) (*GetCalendarResponse, error)
+	// Manual vacation days of a year
+	ListVacations(context.Context, *ListVacationsRequest) (*ListVacationsResponse, error)
+	AddVacation(context.Context, *AddVacationRequest) (*AddVacationResponse, error)
+	RemoveVacation(context.Context, *RemoveVacationRequest) (*RemoveVacationResponse, error)
+	// Adds and removes manual vacation days in one transaction
+	UpdateVacations(context.Context, *UpdateVacationsRequest) (*UpdateVacationsResponse, error)
+	// Runs the year's optimization strategy and stores the optimized days
+	Optimize(context.Context, *OptimizeRequest) (*OptimizeResponse, error)
+	ClearOptimizedVacations(context.Context, *ClearOptimizedVacationsRequest) (*ClearOptimizedVacationsResponse, error)
+	mustEmbedUnimplementedVacationPlannerServiceServer()
+}
+
+// UnimplementedVacationPlannerServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedVacationPlannerServiceServer struct{}
+
+func (UnimplementedVacationPlannerServiceServer) GetCalendar(context.Context, *GetCalendarRequest) (*GetCalendarResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCalendar not implemented")
+}
+func (UnimplementedVacationPlannerServiceServer) ListVacations(context.Context, *ListVacationsRequest) (*ListVacationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVacations not implemented")
+}
+func (UnimplementedVacationPlannerServiceServer) AddVacation(context.Context, *AddVacationRequest) (*AddVacationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddVacation not implemented")
+}
+func (UnimplementedVacationPlannerServiceServer) RemoveVacation(context.Context, *RemoveVacationRequest) (*RemoveVacationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveVacation not implemented")
+}
+func (UnimplementedVacationPlannerServiceServer) UpdateVacations(context.Context, *UpdateVacationsRequest) (*UpdateVacationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateVacations not implemented")
+}
+func (UnimplementedVacationPlannerServiceServer) Optimize(context.Context, *OptimizeRequest) (*OptimizeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Optimize not implemented")
+}
+func (UnimplementedVacationPlannerServiceServer) ClearOptimizedVacations(context.Context, *ClearOptimizedVacationsRequest) (*ClearOptimizedVacationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearOptimizedVacations not implemented")
+}
+func (UnimplementedVacationPlannerServiceServer) mustEmbedUnimplementedVacationPlannerServiceServer() {
+}
+func (UnimplementedVacationPlannerServiceServer) testEmbeddedByValue() {}
+
+// UnsafeVacationPlannerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VacationPlannerServiceServer will
+// result in compilation errors.
+type UnsafeVacationPlannerServiceServer interface {
+	mustEmbedUnimplementedVacationPlannerServiceServer()
+}
+
+func RegisterVacationPlannerServiceServer(s grpc.ServiceRegistrar, srv VacationPlannerServiceServer) {
+	// If the following call pancis, it indicates UnimplementedVacationPlannerServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&VacationPlannerService_ServiceDesc, srv)
+}
+
+func _VacationPlannerService_GetCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCalendarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VacationPlannerServiceServer).GetCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VacationPlannerService_GetCalendar_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VacationPlannerServiceServer).GetCalendar(ctx, req.(*GetCalendarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VacationPlannerService_ListVacations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListVacationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VacationPlannerServiceServer).ListVacations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VacationPlannerService_ListVacations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VacationPlannerServiceServer).ListVacations(ctx, req.(*ListVacationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VacationPlannerService_AddVacation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddVacationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VacationPlannerServiceServer).AddVacation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VacationPlannerService_AddVacation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VacationPlannerServiceServer).AddVacation(ctx, req.(*AddVacationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VacationPlannerService_RemoveVacation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveVacationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VacationPlannerServiceServer).RemoveVacation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VacationPlannerService_RemoveVacation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VacationPlannerServiceServer).RemoveVacation(ctx, req.(*RemoveVacationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VacationPlannerService_UpdateVacations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateVacationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VacationPlannerServiceServer).UpdateVacations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VacationPlannerService_UpdateVacations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VacationPlannerServiceServer).UpdateVacations(ctx, req.(*UpdateVacationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VacationPlannerService_Optimize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OptimizeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VacationPlannerServiceServer).Optimize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VacationPlannerService_Optimize_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VacationPlannerServiceServer).Optimize(ctx, req.(*OptimizeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VacationPlannerService_ClearOptimizedVacations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearOptimizedVacationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VacationPlannerServiceServer).ClearOptimizedVacations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VacationPlannerService_ClearOptimizedVacations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VacationPlannerServiceServer).ClearOptimizedVacations(ctx, req.(*ClearOptimizedVacationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// VacationPlannerService_ServiceDesc is the grpc.ServiceDesc for VacationPlannerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var VacationPlannerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "vacationplanner.v1.VacationPlannerService",
+	HandlerType: (*VacationPlannerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCalendar",
+			Handler:    _VacationPlannerService_GetCalendar_Handler,
+		},
+		{
+			MethodName: "ListVacations",
+			Handler:    _VacationPlannerService_ListVacations_Handler,
+		},
+		{
+			MethodName: "AddVacation",
+			Handler:    _VacationPlannerService_AddVacation_Handler,
+		},
+		{
+			MethodName: "RemoveVacation",
+			Handler:    _VacationPlannerService_RemoveVacation_Handler,
+		},
+		{
+			MethodName: "UpdateVacations",
+			Handler:    _VacationPlannerService_UpdateVacations_Handler,
+		},
+		{
+			MethodName: "Optimize",
+			Handler:    _VacationPlannerService_Optimize_Handler,
+		},
+		{
+			MethodName: "ClearOptimizedVacations",
+			Handler:    _VacationPlannerService_ClearOptimizedVacations_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "vacationplanner/v1/vacationplanner.proto",
+}
@@ -0,0 +1,22 @@
+// Service worker for Web Push notifications sent by the backend
+self.addEventListener('push', (event) => {
+  let data = { title: 'Vacation Planner', body: '' };
+  if (event.data) {
+    try {
+      data = event.data.json();
+    } catch {
+      data.body = event.data.text();
+    }
+  }
+
+  event.waitUntil(
+    self.registration.showNotification(data.title, {
+      body: data.body,
+    })
+  );
+});
+
+self.addEventListener('notificationclick', (event) => {
+  event.notification.close();
+  event.waitUntil(self.clients.openWindow('/'));
+});
@@ -5,6 +5,7 @@ import {
   LinearProgress,
   Grid,
   Chip,
+  Alert,
   alpha,
   useTheme,
 } from '@mui/material';
@@ -15,7 +16,7 @@ import {
   TrendingUp as TrendingIcon,
 } from '@mui/icons-material';
 import { CalendarSummary as CalendarSummaryType } from '../types';
-import { useTranslations } from '../i18n';
+import { useTranslations, interpolate } from '../i18n';
 
 interface CalendarSummaryProps {
   summary: CalendarSummaryType;
@@ -142,6 +143,11 @@ const CalendarSummary: React.FC<CalendarSummaryProps> = ({ summary }) => {
             },
           }}
         />
+        {summary.unused_days_at_risk && (
+          <Alert severity="warning" sx={{ mt: 2 }}>
+            {interpolate(t.summary.unusedDaysAtRisk, [summary.schedulable_days])}
+          </Alert>
+        )}
       </Box>
 
       <Box sx={{ mt: 3, display: 'flex', gap: 1, flexWrap: 'wrap' }}>
@@ -62,6 +62,8 @@ const YearCalendar: React.FC<YearCalendarProps> = ({ year, days, onDayClick }) =
     if (day.is_manual) parts.push(t.calendar.tooltipManualVacation);
     if (day.is_optimal) parts.push(`${t.calendar.tooltipOptimizedVacation} (Block ${day.block_id})`);
     if (day.is_weekend) parts.push(t.calendar.weekend);
+    if (day.is_bridge_candidate) parts.push(t.calendar.tooltipBridgeCandidate);
+    day.events?.forEach((name) => parts.push(`📌 ${name}`));
     return parts.join('\n');
   };
 
@@ -151,6 +153,7 @@ const YearCalendar: React.FC<YearCalendarProps> = ({ year, days, onDayClick }) =
                     backgroundColor: getDayColor(calendarDay),
                     color: getDayTextColor(calendarDay),
                     borderRadius: '50%',
+                    border: calendarDay?.is_bridge_candidate ? `1px dashed ${colors.holiday}` : 'none',
                     cursor: 'pointer',
                     fontSize: '0.75rem',
                     fontWeight: isSpecialDay ? 600 : 400,
@@ -22,10 +22,21 @@ import {
 } from '@mui/material';
 import { Save as SaveIcon, DeleteSweep as ClearIcon } from '@mui/icons-material';
 import { useCalendar } from '../context/CalendarContext';
-import { OptimizationStrategy, ALL_WEEKDAYS, WORK_WEEK_PRESETS } from '../types';
+import { OptimizationStrategy, ScoringWeights, ALL_WEEKDAYS, WORK_WEEK_PRESETS } from '../types';
 import * as api from '../services/api';
 import { useTranslations, interpolate } from '../i18n';
 
+// Weights of the balanced strategy, which the custom one starts from
+const DEFAULT_SCORING_WEIGHTS: ScoringWeights = {
+  efficiency: 0.6,
+  length: 0.4,
+  distribution: 0,
+  month_preference: 0,
+  preferred_months: [],
+};
+
+const WEIGHT_FIELDS = ['efficiency', 'length', 'distribution', 'month_preference'] as const;
+
 const YearConfigPanel: React.FC = () => {
   const { calendar, updateConfig, clearOptimized, year } = useCalendar();
   const t = useTranslations();
@@ -36,6 +47,7 @@ const YearConfigPanel: React.FC = () => {
   const [workWeek, setWorkWeek] = useState<string[]>(['monday', 'tuesday', 'wednesday', 'thursday', 'friday']);
   const [workWeekPreset, setWorkWeekPreset] = useState<string>('standard');
   const [optimizerNotes, setOptimizerNotes] = useState<string>('');
+  const [scoringWeights, setScoringWeights] = useState<ScoringWeights>(DEFAULT_SCORING_WEIGHTS);
   const [saved, setSaved] = useState(false);
 
   useEffect(() => {
@@ -49,6 +61,9 @@ const YearConfigPanel: React.FC = () => {
       setStrategy(calendar.config.optimization_strategy);
       setWorkWeek(calendar.config.work_week);
       setOptimizerNotes(calendar.config.optimizer_notes || '');
+      const weights = calendar.config.scoring_weights;
+      const weighted = weights && WEIGHT_FIELDS.some((field) => weights[field] !== 0);
+      setScoringWeights(weighted ? { ...weights, preferred_months: weights.preferred_months || [] } : DEFAULT_SCORING_WEIGHTS);
       
       // Determine preset
       const preset = Object.entries(WORK_WEEK_PRESETS).find(
@@ -82,11 +97,27 @@ const YearConfigPanel: React.FC = () => {
       optimization_strategy: strategy,
       work_week: workWeek,
       optimizer_notes: optimizerNotes,
+      ...(strategy === 'custom' && { scoring_weights: scoringWeights }),
     });
     setSaved(true);
     setTimeout(() => setSaved(false), 3000);
   };
 
+  const handlePreferredMonthToggle = (month: number) => {
+    const months = scoringWeights.preferred_months;
+    setScoringWeights({
+      ...scoringWeights,
+      preferred_months: months.includes(month) ? months.filter(m => m !== month) : [...months, month].sort((a, b) => a - b),
+    });
+  };
+
+  const weightLabels: Record<typeof WEIGHT_FIELDS[number], string> = {
+    efficiency: t.config.weightEfficiency,
+    length: t.config.weightLength,
+    distribution: t.config.weightDistribution,
+    month_preference: t.config.weightMonthPreference,
+  };
+
   const capitalizeFirst = (str: string) => str.charAt(0).toUpperCase() + str.slice(1);
   const theme = useTheme();
 
@@ -171,7 +202,7 @@ const YearConfigPanel: React.FC = () => {
           </Select>
         </FormControl>
 
-        {strategy === 'smart' && (
+        {(strategy === 'smart' || strategy === 'hybrid') && (
           <TextField
             label={t.config.smartOptimizerNotes}
             value={optimizerNotes}
@@ -189,6 +220,49 @@ const YearConfigPanel: React.FC = () => {
           />
         )}
 
+        {strategy === 'custom' && (
+          <Box sx={{ display: 'flex', flexDirection: 'column', gap: 1.5 }}>
+            <Typography variant="subtitle2" sx={{ fontWeight: 600, color: 'text.secondary' }}>
+              {t.config.scoringWeights}
+            </Typography>
+            <Box sx={{ display: 'grid', gridTemplateColumns: '1fr 1fr', gap: 1.5 }}>
+              {WEIGHT_FIELDS.map((field) => (
+                <TextField
+                  key={field}
+                  label={weightLabels[field]}
+                  type="number"
+                  value={scoringWeights[field]}
+                  onChange={(e) => setScoringWeights({ ...scoringWeights, [field]: Math.max(0, parseFloat(e.target.value) || 0) })}
+                  inputProps={{ min: 0, step: 0.1 }}
+                  size="small"
+                  sx={{
+                    '& .MuiOutlinedInput-root': {
+                      borderRadius: 2,
+                    },
+                  }}
+                />
+              ))}
+            </Box>
+            {scoringWeights.month_preference > 0 && (
+              <Box sx={{ display: 'flex', gap: 0.5, flexWrap: 'wrap', alignItems: 'center' }}>
+                <Typography variant="body2" color="text.secondary" sx={{ fontWeight: 500, mr: 0.5 }}>
+                  {t.config.preferredMonths}:
+                </Typography>
+                {t.calendar.monthsShort.map((name, index) => (
+                  <Chip
+                    key={name}
+                    label={name}
+                    size="small"
+                    color={scoringWeights.preferred_months.includes(index + 1) ? 'primary' : 'default'}
+                    onClick={() => handlePreferredMonthToggle(index + 1)}
+                    sx={{ fontWeight: 500 }}
+                  />
+                ))}
+              </Box>
+            )}
+          </Box>
+        )}
+
         <Divider sx={{ my: 0.5 }} />
 
         <Typography 
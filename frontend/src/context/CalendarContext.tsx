@@ -1,4 +1,4 @@
-import React, { createContext, useContext, useState, useCallback, ReactNode } from 'react';
+import React, { createContext, useContext, useState, useCallback, useEffect, ReactNode } from 'react';
 import {
   CalendarResponse,
   YearConfig,
@@ -124,12 +124,17 @@ export const CalendarProvider: React.FC<CalendarProviderProps> = ({ children })
 
   const updateConfig = useCallback(async (config: Partial<YearConfig>) => {
     try {
-      await api.updateYearConfig(year, config);
+      // Send the version shown so a change made meanwhile, e.g. by the chat,
+      // isn't overwritten
+      await api.updateYearConfig(year, { version: calendar?.config?.version, ...config });
       await loadCalendar(year);
     } catch (err) {
+      if (api.getProblem(err)?.code === 'version_conflict') {
+        await loadCalendar(year);
+      }
       setError(err instanceof Error ? err.message : 'Failed to update config');
     }
-  }, [year, loadCalendar]);
+  }, [year, calendar, loadCalendar]);
 
   const loadChatHistory = useCallback(async () => {
     try {
@@ -196,6 +201,19 @@ export const CalendarProvider: React.FC<CalendarProviderProps> = ({ children })
     }
   }, [year]);
 
+  // Refresh the calendar in the background when another tab, the chat or a
+  // sync job changes the year being shown, or a backup restore changes them all
+  useEffect(() => {
+    return api.subscribeToLiveUpdates(year, (event) => {
+      if (event.year !== year && event.year !== 0) {
+        return;
+      }
+      api.getCalendar(year)
+        .then(setCalendar)
+        .catch((err) => console.error('Failed to refresh calendar:', err));
+    });
+  }, [year]);
+
   // Fetch AI suggestions with caching
   const fetchSuggestions = useCallback(async () => {
     const currentFingerprint = getCalendarFingerprint(calendar, year, language);
@@ -54,6 +54,7 @@ export const en: Translations = {
     isAHoliday: 'is a holiday',
     tooltipManualVacation: '📅 Manual vacation day',
     tooltipOptimizedVacation: '✨ Optimized vacation',
+    tooltipBridgeCandidate: '🌉 Bridge day: one vacation day joins the holiday to the weekend',
     holidaysListTitle: '🇵🇹 Portuguese Holidays',
     dayIsVacation: 'This day is currently marked as a vacation day.',
     dayIsVacationManual: '(manually set)',
@@ -67,6 +68,7 @@ export const en: Translations = {
     longestBlock: 'Longest Block',
     totalDaysOff: 'Total Days Off',
     vacationDaysUsage: 'Vacation Days Usage',
+    unusedDaysAtRisk: 'Only about {0} vacation days can realistically still be booked this year. Plan the rest soon.',
   },
   chat: {
     title: 'AI Assistant',
@@ -85,6 +87,12 @@ export const en: Translations = {
     smartOptimizerNotes: 'Smart Optimizer Notes',
     smartOptimizerNotesPlaceholder: 'e.g., I want a week off in August for beach vacation, avoid December...',
     smartOptimizerNotesHelp: 'Add preferences or constraints for the AI optimizer',
+    scoringWeights: 'Scoring Weights',
+    weightEfficiency: 'Efficiency',
+    weightLength: 'Block length',
+    weightDistribution: 'Spread over the year',
+    weightMonthPreference: 'Preferred months',
+    preferredMonths: 'Months to prefer',
     workWeekConfig: 'Work Week Configuration',
     preset: 'Preset',
     presetStandard: 'Standard (Mon-Fri)',
@@ -54,6 +54,7 @@ export const ptPT: Translations = {
     isAHoliday: 'é feriado',
     tooltipManualVacation: '📅 Dia de férias manual',
     tooltipOptimizedVacation: '✨ Férias otimizadas',
+    tooltipBridgeCandidate: '🌉 Ponte: um dia de férias junta o feriado ao fim de semana',
     holidaysListTitle: '🇵🇹 Feriados Portugueses',
     dayIsVacation: 'Este dia está marcado como dia de férias.',
     dayIsVacationManual: '(definido manualmente)',
@@ -67,6 +68,7 @@ export const ptPT: Translations = {
     longestBlock: 'Bloco Mais Longo',
     totalDaysOff: 'Total de Dias de Folga',
     vacationDaysUsage: 'Utilização de Dias de Férias',
+    unusedDaysAtRisk: 'Só cerca de {0} dias de férias podem ainda ser marcados este ano. Planeie os restantes em breve.',
   },
   chat: {
     title: 'Assistente IA',
@@ -85,6 +87,12 @@ export const ptPT: Translations = {
     smartOptimizerNotes: 'Notas do Otimizador Inteligente',
     smartOptimizerNotesPlaceholder: 'Ex: Quero uma semana de folga em agosto para férias na praia, evitar dezembro...',
     smartOptimizerNotesHelp: 'Adicione preferências ou restrições para o otimizador IA',
+    scoringWeights: 'Pesos da Pontuação',
+    weightEfficiency: 'Eficiência',
+    weightLength: 'Duração do bloco',
+    weightDistribution: 'Distribuição no ano',
+    weightMonthPreference: 'Meses preferidos',
+    preferredMonths: 'Meses a preferir',
     workWeekConfig: 'Configuração da Semana de Trabalho',
     preset: 'Predefinição',
     presetStandard: 'Normal (Seg-Sex)',
@@ -63,6 +63,7 @@ export interface Translations {
     isAHoliday: string;
     tooltipManualVacation: string;
     tooltipOptimizedVacation: string;
+    tooltipBridgeCandidate: string;
     holidaysListTitle: string;
     dayIsVacation: string;
     dayIsVacationManual: string;
@@ -78,6 +79,7 @@ export interface Translations {
     longestBlock: string;
     totalDaysOff: string;
     vacationDaysUsage: string;
+    unusedDaysAtRisk: string;
   };
 
   // Chat
@@ -100,6 +102,12 @@ export interface Translations {
     smartOptimizerNotes: string;
     smartOptimizerNotesPlaceholder: string;
     smartOptimizerNotesHelp: string;
+    scoringWeights: string;
+    weightEfficiency: string;
+    weightLength: string;
+    weightDistribution: string;
+    weightMonthPreference: string;
+    preferredMonths: string;
     workWeekConfig: string;
     preset: string;
     presetStandard: string;
@@ -238,6 +238,8 @@ const SettingsPage: React.FC = () => {
                     models.map((model) => (
                       <MenuItem key={model.id} value={model.id}>
                         {model.name} ({model.publisher})
+                        {model.context_length ? ` · ${Math.round(model.context_length / 1000)}k` : ''}
+                        {model.tier ? ` · ${model.tier}` : ''}
                       </MenuItem>
                     ))
                   ) : (
@@ -1,25 +1,131 @@
 import axios from 'axios';
 import {
   CalendarResponse,
+  CalendarDay,
+  YearCalendar,
   YearConfig,
+  WorkWeekChange,
   VacationDay,
+  BulkItemResult,
+  CopiedVacation,
+  VacationCopyMode,
   Holiday,
+  HolidaySync,
+  WorkedHoliday,
+  HolidayOverride,
+  CompDay,
+  OptionalHoliday,
+  Trip,
+  TripInput,
+  TripExpense,
+  TripExpenseInput,
+  TripBudgetSummary,
+  CompLedger,
+  BalanceAdjustment,
   ChatMessage,
   Settings,
+  SettingDefinition,
   OptimizationStrategy,
   VacationBlock,
+  FlightPriceResponse,
+  NextBreak,
+  DayStatus,
+  HistoricalStats,
+  LeaveImport,
+  OnCallImport,
+  MilestoneImport,
+  OutOfOfficeDraft,
+  FamilyMember,
+  FamilyMemberInput,
+  PersonalEvent,
+  LongWeekend,
+  PersonalEventInput,
+  FamilyClosure,
+  BookingRule,
+  BookingProposal,
+  Comment,
+  CommentInput,
+  DeclinedBlock,
+  LockedDate,
+  Problem,
 } from '../types';
 
 const api = axios.create({
   baseURL: '/api',
 });
 
+// Access token sent with every request once the server has access control on
+const ACCESS_TOKEN_KEY = 'accessToken';
+
+export const setAccessToken = (token: string | null): void => {
+  if (token) {
+    localStorage.setItem(ACCESS_TOKEN_KEY, token);
+  } else {
+    localStorage.removeItem(ACCESS_TOKEN_KEY);
+  }
+};
+
+// Single sign-on redirects back with the session token in the URL fragment,
+// which never reaches the server or its logs
+const ssoToken = new URLSearchParams(window.location.hash.slice(1)).get('access_token');
+if (ssoToken) {
+  setAccessToken(ssoToken);
+  window.history.replaceState(null, '', window.location.pathname + window.location.search);
+}
+
+api.interceptors.request.use((config) => {
+  const token = localStorage.getItem(ACCESS_TOKEN_KEY);
+  if (token) {
+    config.headers.Authorization = `Bearer ${token}`;
+  }
+  return config;
+});
+
+// The problem details of a failed request, to branch on its code
+export const getProblem = (error: unknown): Problem | null => {
+  if (axios.isAxiosError(error) && error.response?.data?.code) {
+    return error.response.data as Problem;
+  }
+  return null;
+};
+
 // Calendar
 export const getCalendar = async (year: number): Promise<CalendarResponse> => {
   const response = await api.get<CalendarResponse>(`/calendar/${year}`);
   return response.data;
 };
 
+// Summaries of several years at once, for the year switcher
+export const getCalendars = async (from: number, to: number, days = false): Promise<YearCalendar[]> => {
+  const response = await api.get<{ years: YearCalendar[] }>('/calendar', { params: { from, to, days: days || undefined } });
+  return response.data.years;
+};
+
+export const getCalendarRange = async (
+  start: string,
+  end: string
+): Promise<{ days: CalendarDay[]; vacation_blocks: VacationBlock[] }> => {
+  const response = await api.get<{ days: CalendarDay[]; vacation_blocks: VacationBlock[] }>('/calendar/range', {
+    params: { start, end },
+  });
+  return response.data;
+};
+
+export const getNextBreak = async (): Promise<NextBreak> => {
+  const response = await api.get<NextBreak>('/calendar/next-break');
+  return response.data;
+};
+
+export const getDay = async (date: string): Promise<DayStatus> => {
+  const response = await api.get<DayStatus>(`/calendar/day/${date}`);
+  return response.data;
+};
+
+export const getLongWeekends = async (year: number): Promise<LongWeekend[]> => {
+  const response = await api.get<LongWeekend[]>(`/calendar/${year}/long-weekends`);
+  return response.data;
+};
+
 export const optimizeVacations = async (
   year: number
 ): Promise<{ blocks: VacationBlock[]; message: string }> => {
@@ -31,13 +137,67 @@ export const clearOptimizedVacations = async (year: number): Promise<void> => {
   await api.delete(`/calendar/${year}/optimized`);
 };
 
-export const getVacationSuggestions = async (year: number, language: string = 'en'): Promise<{ suggestion: string }> => {
-  const response = await api.get<{ suggestion: string }>(`/calendar/${year}/suggestions`, {
-    params: { language }
+// Keep optimized days as manual ones, all of them or one block's
+export const acceptOptimizedVacations = async (
+  year: number,
+  block?: number | string
+): Promise<{ accepted: string[]; message: string }> => {
+  const response = await api.post(`/calendar/${year}/optimized/accept`, undefined, {
+    params: block !== undefined ? { block } : undefined,
+  });
+  return response.data;
+};
+
+// Turn down a suggested block and optimize again without its days
+export const declineOptimizedBlock = async (
+  year: number,
+  block: number | string
+): Promise<{ declined: DeclinedBlock; blocks: VacationBlock[]; message: string }> => {
+  const response = await api.post(`/calendar/${year}/optimized/decline`, undefined, {
+    params: { block },
+  });
+  return response.data;
+};
+
+export const getDeclinedBlocks = async (year: number): Promise<DeclinedBlock[]> => {
+  const response = await api.get<DeclinedBlock[]>(`/calendar/${year}/declined`);
+  return response.data;
+};
+
+export const deleteDeclinedBlock = async (year: number, id: number): Promise<void> => {
+  await api.delete(`/calendar/${year}/declined/${id}`);
+};
+
+export const clearDeclinedBlocks = async (year: number): Promise<void> => {
+  await api.delete(`/calendar/${year}/declined`);
+};
+
+export interface VacationSuggestion {
+  suggestion: string;
+  cached?: boolean;
+  created_at?: string;
+}
+
+export const getVacationSuggestions = async (year: number, language: string = 'en', force: boolean = false): Promise<VacationSuggestion> => {
+  const response = await api.get<VacationSuggestion>(`/calendar/${year}/suggestions`, {
+    params: force ? { language, force: true } : { language }
+  });
+  return response.data;
+};
+
+export const getFlightPrices = async (year: number, destination?: string): Promise<FlightPriceResponse> => {
+  const response = await api.get<FlightPriceResponse>(`/calendar/${year}/flight-prices`, {
+    params: destination ? { destination } : undefined,
   });
   return response.data;
 };
 
+// Statistics
+export const getHistoricalStats = async (): Promise<HistoricalStats> => {
+  const response = await api.get<HistoricalStats>('/stats');
+  return response.data;
+};
+
 // Vacations
 export const getVacations = async (year: number): Promise<VacationDay[]> => {
   const response = await api.get<VacationDay[]>(`/vacations/${year}`);
@@ -59,17 +219,81 @@ export const removeVacation = async (
   await api.delete(`/vacations/${year}/${date}`);
 };
 
+export const removeVacationRange = async (
+  year: number,
+  start: string,
+  end: string
+): Promise<{ removed: string[]; removed_optimized: string[] }> => {
+  const response = await api.delete(`/vacations/${year}/range`, { params: { start, end } });
+  return response.data;
+};
+
+export interface VacationMove {
+  from: string;
+  to: string;
+}
+
+// Moves days, or shifts dates by shift_days, all or none
+export const moveVacations = async (
+  year: number,
+  move: { moves: VacationMove[] } | { dates: string[]; shift_days: number }
+): Promise<{ moved: VacationMove[] }> => {
+  const response = await api.post(`/vacations/${year}/move`, move);
+  return response.data;
+};
+
+// Adds on holidays, already booked days and past the days left are skipped,
+// as reported per date in results
 export const bulkUpdateVacations = async (
   year: number,
   add: string[],
   remove: string[]
-): Promise<void> => {
-  await api.put(`/vacations/${year}/bulk`, { add, remove });
+): Promise<{ results: BulkItemResult[]; counts: Record<string, number>; version: string }> => {
+  const response = await api.put(`/vacations/${year}/bulk`, { add, remove });
+  return response.data;
+};
+
+// Locked dates, by date or a block's position (1 for the first) or first day
+export const getLockedDates = async (year: number): Promise<LockedDate[]> => {
+  const response = await api.get<LockedDate[]>(`/vacations/${year}/locked`);
+  return response.data;
+};
+
+export const lockDates = async (
+  year: number,
+  lock: { dates?: string[]; block?: number | string; reason?: string }
+): Promise<LockedDate[]> => {
+  const response = await api.post<LockedDate[]>(`/vacations/${year}/locked`, {
+    ...lock,
+    block: lock.block !== undefined ? String(lock.block) : undefined,
+  });
+  return response.data;
+};
+
+export const unlockDate = async (year: number, date: string): Promise<void> => {
+  await api.delete(`/vacations/${year}/locked/${date}`);
 };
 
 // Holidays
-export const getHolidays = async (year: number): Promise<Holiday[]> => {
-  const response = await api.get<Holiday[]>(`/holidays/${year}`);
+export interface HolidayFilter {
+  type?: string; // national, municipal or custom, comma separated
+  from?: string;
+  to?: string;
+  location?: string;
+}
+
+export const getHolidays = async (year: number, filter?: HolidayFilter): Promise<Holiday[]> => {
+  const response = await api.get<Holiday[]>(`/holidays/${year}`, { params: filter });
+  return response.data;
+};
+
+export const getHolidaysPreview = async (year: number): Promise<Holiday[]> => {
+  const response = await api.get<Holiday[]>(`/holidays/${year}`, { params: { preview: true } });
+  return response.data;
+};
+
+export const getHolidayChanges = async (year: number, since: string): Promise<HolidaySync> => {
+  const response = await api.get<HolidaySync>(`/holidays/${year}`, { params: { since } });
   return response.data;
 };
 
@@ -78,6 +302,78 @@ export const refreshHolidays = async (year: number): Promise<{ message: string;
   return response.data;
 };
 
+export const getWorkedHolidays = async (year: number): Promise<WorkedHoliday[]> => {
+  const response = await api.get<WorkedHoliday[]>(`/holidays/${year}/worked`);
+  return response.data;
+};
+
+export const addWorkedHoliday = async (year: number, date: string, note?: string): Promise<void> => {
+  await api.post(`/holidays/${year}/worked`, { date, note });
+};
+
+export const removeWorkedHoliday = async (year: number, date: string): Promise<void> => {
+  await api.delete(`/holidays/${year}/worked/${date}`);
+};
+
+export const getHolidayOverrides = async (year: number): Promise<HolidayOverride[]> => {
+  const response = await api.get<HolidayOverride[]>(`/holidays/${year}/overrides`);
+  return response.data;
+};
+
+export const setHolidayOverride = async (
+  year: number,
+  date: string,
+  override: Pick<HolidayOverride, 'action' | 'new_date' | 'name' | 'note'>
+): Promise<void> => {
+  await api.put(`/holidays/${year}/overrides/${date}`, override);
+};
+
+export const removeHolidayOverride = async (year: number, date: string): Promise<void> => {
+  await api.delete(`/holidays/${year}/overrides/${date}`);
+};
+
+export const getOptionalHolidays = async (year: number): Promise<OptionalHoliday[]> => {
+  const response = await api.get<OptionalHoliday[]>(`/holidays/${year}/optional`);
+  return response.data;
+};
+
+export const setOptionalHoliday = async (year: number, key: string, enabled: boolean): Promise<OptionalHoliday[]> => {
+  const response = await api.put<OptionalHoliday[]>(`/holidays/${year}/optional/${key}`, { enabled });
+  return response.data;
+};
+
+// Compensation days
+export const getCompDays = async (year: number): Promise<CompLedger> => {
+  const response = await api.get<CompLedger>(`/comp-days/${year}`);
+  return response.data;
+};
+
+export const creditCompDay = async (year: number, date: string, note?: string): Promise<CompDay> => {
+  const response = await api.post<CompDay>(`/comp-days/${year}/credit`, { date, note });
+  return response.data;
+};
+
+export const spendCompDay = async (year: number, date: string, note?: string): Promise<CompDay> => {
+  const response = await api.post<CompDay>(`/comp-days/${year}/spend`, { date, note });
+  return response.data;
+};
+
+export const deleteCompDay = async (year: number, id: number): Promise<void> => {
+  await api.delete(`/comp-days/${year}/${id}`);
+};
+
+export const createBalanceAdjustment = async (
+  year: number,
+  adjustment: Pick<BalanceAdjustment, 'date' | 'days' | 'kind' | 'reason'>
+): Promise<BalanceAdjustment> => {
+  const response = await api.post<BalanceAdjustment>(`/comp-days/${year}/adjustments`, adjustment);
+  return response.data;
+};
+
+export const deleteBalanceAdjustment = async (year: number, id: number): Promise<void> => {
+  await api.delete(`/comp-days/${year}/adjustments/${id}`);
+};
+
 // Holiday status
 export interface HolidayStatus {
   year: number;
@@ -88,9 +384,18 @@ export interface HolidayStatus {
   last_updated: string;
   retry_count: number;
   max_retries: number;
+  retry_schedule: number[];
   is_retrying: boolean;
   next_retry?: string;
   has_errors: boolean;
+  warnings?: HolidayWarning[]; // Holidays the providers disagree on
+}
+
+export interface HolidayWarning {
+  date: string;
+  name: string;
+  sources: string[];
+  message: string;
 }
 
 export const getHolidayStatus = async (year: number): Promise<HolidayStatus> => {
@@ -119,9 +424,30 @@ export const updateYearConfig = async (
 
 export const copyYearConfig = async (
   year: number,
-  sourceYear: number
-): Promise<void> => {
-  await api.post(`/config/${year}/copy-from/${sourceYear}`);
+  sourceYear: number,
+  vacations?: VacationCopyMode
+): Promise<{ vacations?: CopiedVacation[]; counts?: Record<string, number> }> => {
+  const response = await api.post(`/config/${year}/copy-from/${sourceYear}`, undefined, {
+    params: vacations ? { vacations } : undefined,
+  });
+  return response.data;
+};
+
+export const setWorkWeekChange = async (
+  year: number,
+  effectiveFrom: string,
+  workWeek: string[]
+): Promise<WorkWeekChange[]> => {
+  const response = await api.post<WorkWeekChange[]>(`/config/${year}/work-week`, {
+    effective_from: effectiveFrom,
+    work_week: workWeek,
+  });
+  return response.data;
+};
+
+export const removeWorkWeekChange = async (year: number, effectiveFrom: string): Promise<WorkWeekChange[]> => {
+  const response = await api.delete<WorkWeekChange[]>(`/config/${year}/work-week/${effectiveFrom}`);
+  return response.data;
 };
 
 // Settings
@@ -136,6 +462,11 @@ export const updateSettings = async (
   await api.put('/settings', settings);
 };
 
+export const getSettingsSchema = async (): Promise<SettingDefinition[]> => {
+  const response = await api.get<SettingDefinition[]>('/settings/schema');
+  return response.data;
+};
+
 export const getSetting = async (key: string): Promise<string> => {
   const response = await api.get<Record<string, string>>(`/settings/${key}`);
   return response.data[key];
@@ -175,10 +506,31 @@ export interface AIModel {
   id: string;
   name: string;
   publisher: string;
+  context_length?: number; // Input tokens, when the catalog tells
+  max_output_tokens?: number;
+  tier?: string; // Rate limit and pricing tier, such as low or high
+  tool_calling?: boolean;
+  suitable?: boolean;
+  reason?: string; // Why the model can't be used, with all
 }
 
-export const getAvailableModels = async (): Promise<AIModel[]> => {
-  const response = await api.get<AIModel[]>('/models');
+export const getAvailableModels = async (all = false): Promise<AIModel[]> => {
+  const response = await api.get<AIModel[]>('/models', { params: { all: all || undefined } });
+  return response.data;
+};
+
+export interface AIValidation {
+  valid: boolean;
+  provider: string;
+  model: string;
+  latency_ms: number;
+  status?: number; // HTTP status of a failed call
+  error?: string;
+  quota?: Record<string, string>; // Rate limit headers, such as remaining-requests
+}
+
+export const validateAI = async (): Promise<AIValidation> => {
+  const response = await api.post<AIValidation>('/settings/ai/validate');
   return response.data;
 };
 
@@ -214,3 +566,488 @@ export const getVersion = async (): Promise<string> => {
     return import.meta.env.VITE_APP_VERSION || 'dev';
   }
 };
+
+// HR leave import
+export const importLeave = async (year: number): Promise<LeaveImport> => {
+  const response = await api.post<LeaveImport>(`/hr/import/${year}`);
+  return response.data;
+};
+
+// On-call schedule import
+export const importOnCall = async (year: number): Promise<OnCallImport> => {
+  const response = await api.post<OnCallImport>(`/oncall/import/${year}`);
+  return response.data;
+};
+
+// Project milestone import
+export const importMilestones = async (year: number): Promise<MilestoneImport> => {
+  const response = await api.post<MilestoneImport>(`/milestones/import/${year}`);
+  return response.data;
+};
+
+// Google Sheets export
+export const exportToSheet = async (
+  year: number,
+  layout?: 'block' | 'day'
+): Promise<{ message: string; sheet: string; rows: number }> => {
+  const response = await api.post(`/sheets/${year}/export`, null, {
+    params: layout ? { layout } : undefined,
+  });
+  return response.data;
+};
+
+// Live calendar updates: calendar events pushed over a WebSocket as other
+// tabs, the chat or sync jobs change the plan. Reconnects until closed.
+export interface LiveEvent {
+  type: string;
+  year: number;
+  data?: unknown;
+  timestamp: string;
+}
+
+export const subscribeToLiveUpdates = (
+  year: number,
+  onEvent: (event: LiveEvent) => void
+): (() => void) => {
+  let socket: WebSocket | null = null;
+  let retry: ReturnType<typeof setTimeout> | undefined;
+  let closed = false;
+
+  const connect = () => {
+    const params = new URLSearchParams({ year: String(year) });
+    const token = localStorage.getItem(ACCESS_TOKEN_KEY);
+    if (token) {
+      params.set('access_token', token);
+    }
+    const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
+    socket = new WebSocket(`${protocol}//${window.location.host}/api/calendar/live?${params}`);
+    socket.onmessage = (message) => onEvent(JSON.parse(message.data));
+    socket.onclose = () => {
+      if (!closed) {
+        retry = setTimeout(connect, 5000);
+      }
+    };
+  };
+
+  connect();
+  return () => {
+    closed = true;
+    clearTimeout(retry);
+    socket?.close();
+  };
+};
+
+// Web Push notifications
+const urlBase64ToUint8Array = (base64: string): Uint8Array => {
+  const padding = '='.repeat((4 - (base64.length % 4)) % 4);
+  const raw = atob((base64 + padding).replace(/-/g, '+').replace(/_/g, '/'));
+  return Uint8Array.from(raw, (c) => c.charCodeAt(0));
+};
+
+export const subscribeToPush = async (): Promise<boolean> => {
+  if (!('serviceWorker' in navigator) || !('PushManager' in window)) {
+    return false;
+  }
+
+  const permission = await Notification.requestPermission();
+  if (permission !== 'granted') {
+    return false;
+  }
+
+  const registration = await navigator.serviceWorker.register('/sw.js');
+  const { data } = await api.get<{ public_key: string }>('/push/public-key');
+  const subscription = await registration.pushManager.subscribe({
+    userVisibleOnly: true,
+    applicationServerKey: urlBase64ToUint8Array(data.public_key),
+  });
+
+  await api.post('/push/subscriptions', subscription.toJSON());
+  return true;
+};
+
+export const unsubscribeFromPush = async (): Promise<void> => {
+  const registration = await navigator.serviceWorker?.getRegistration('/sw.js');
+  const subscription = await registration?.pushManager.getSubscription();
+  if (!subscription) {
+    return;
+  }
+
+  await api.delete('/push/subscriptions', { data: { endpoint: subscription.endpoint } });
+  await subscription.unsubscribe();
+};
+
+// Notification preferences
+export interface NotificationPreferences {
+  token_id: number;
+  channels: Array<'teams' | 'email' | 'push'>;
+  email?: string;
+  kinds: string[];
+  quiet_start?: string;
+  quiet_end?: string;
+  updated_at?: string;
+}
+
+export const getNotificationPreferences = async (): Promise<NotificationPreferences> => {
+  const response = await api.get('/notifications/preferences');
+  return response.data;
+};
+
+export const updateNotificationPreferences = async (
+  prefs: Omit<NotificationPreferences, 'token_id' | 'updated_at'>
+): Promise<NotificationPreferences> => {
+  const response = await api.put('/notifications/preferences', prefs);
+  return response.data;
+};
+
+// Background jobs
+export interface ScheduledJob {
+  name: string;
+  description: string;
+  schedule: string;
+  next_run?: string;
+  last_run?: string;
+  last_duration_ms: number;
+  last_error?: string;
+  running: boolean;
+  runs: number;
+  failures: number;
+}
+
+export const getJobs = async (): Promise<ScheduledJob[]> => {
+  const response = await api.get<ScheduledJob[]>('/admin/jobs');
+  return response.data;
+};
+
+export const runJob = async (name: string): Promise<void> => {
+  await api.post(`/admin/jobs/${name}/run`);
+};
+
+// Stored data per year, with the row count of each table
+export interface StoredYear {
+  year: number;
+  tables: Record<string, number>;
+  total: number;
+}
+
+export const getStoredYears = async (): Promise<StoredYear[]> => {
+  const response = await api.get<StoredYear[]>('/admin/data');
+  return response.data;
+};
+
+export const purgeYear = async (year: number): Promise<{ deleted: Record<string, number> }> => {
+  const response = await api.delete(`/admin/data/${year}`);
+  return response.data;
+};
+
+// Database backups in the configured backup target
+export interface Backup {
+  name: string;
+  size: number;
+  created_at: string;
+}
+
+export const getBackups = async (): Promise<Backup[]> => {
+  const response = await api.get<Backup[]>('/admin/backups');
+  return response.data;
+};
+
+export const createBackup = async (): Promise<{ backup: Backup; pruned: string[] }> => {
+  const response = await api.post('/admin/backups');
+  return response.data;
+};
+
+// Replace the database with a backup; the current one is backed up first
+export const restoreBackup = async (name: string): Promise<{ message: string; previous: Backup }> => {
+  const response = await api.post(`/admin/backups/${encodeURIComponent(name)}/restore`);
+  return response.data;
+};
+
+// Download past years as gzipped JSON and remove them from the database
+export const archiveYears = async (years: { years?: number[]; before?: number }): Promise<Blob> => {
+  const response = await api.post('/admin/data/archive', years, { responseType: 'blob' });
+  return response.data;
+};
+
+// Personal events weighing where vacation goes
+export const getPersonalEvents = async (year: number): Promise<PersonalEvent[]> => {
+  const response = await api.get<PersonalEvent[]>(`/personal-events/${year}`);
+  return response.data;
+};
+
+export const createPersonalEvent = async (year: number, event: PersonalEventInput): Promise<PersonalEvent> => {
+  const response = await api.post<PersonalEvent>(`/personal-events/${year}`, event);
+  return response.data;
+};
+
+export const updatePersonalEvent = async (year: number, id: number, event: PersonalEventInput): Promise<PersonalEvent> => {
+  const response = await api.put<PersonalEvent>(`/personal-events/${year}/${id}`, event);
+  return response.data;
+};
+
+export const deletePersonalEvent = async (year: number, id: number): Promise<void> => {
+  await api.delete(`/personal-events/${year}/${id}`);
+};
+
+// Family members and their school or childcare closures
+export const getFamily = async (year: number): Promise<FamilyMember[]> => {
+  const response = await api.get<FamilyMember[]>(`/family/${year}`);
+  return response.data;
+};
+
+export const createFamilyMember = async (member: FamilyMemberInput): Promise<FamilyMember> => {
+  const response = await api.post<FamilyMember>('/family', member);
+  return response.data;
+};
+
+export const updateFamilyMember = async (id: number, member: FamilyMemberInput): Promise<FamilyMember> => {
+  const response = await api.put<FamilyMember>(`/family/${id}`, member);
+  return response.data;
+};
+
+export const deleteFamilyMember = async (id: number): Promise<void> => {
+  await api.delete(`/family/${id}`);
+};
+
+export const addFamilyClosure = async (
+  memberId: number,
+  closure: { name: string; start_date: string; end_date: string }
+): Promise<FamilyClosure> => {
+  const response = await api.post<FamilyClosure>(`/family/${memberId}/closures`, closure);
+  return response.data;
+};
+
+export const deleteFamilyClosure = async (memberId: number, id: number): Promise<void> => {
+  await api.delete(`/family/${memberId}/closures/${id}`);
+};
+
+// Name and color a vacation block, by position (1 for the first) or first day
+export const setBlockLabel = async (
+  year: number,
+  blockId: number | string,
+  label: { label: string; color?: string }
+): Promise<VacationBlock> => {
+  const response = await api.put<VacationBlock>(`/calendar/${year}/blocks/${blockId}`, label);
+  return response.data;
+};
+
+export const deleteBlockLabel = async (year: number, blockId: number | string): Promise<void> => {
+  await api.delete(`/calendar/${year}/blocks/${blockId}`);
+};
+
+// Calendar file of one vacation block, by position (1 for the first) or first day
+export const getBlockICSUrl = (year: number, blockId: number | string): string =>
+  `/api/calendar/${year}/blocks/${blockId}.ics`;
+
+// AI out-of-office auto-reply and handover summary of a vacation block
+export const draftOutOfOffice = async (
+  year: number,
+  blockId: number | string,
+  input: { language?: 'en' | 'pt-PT'; name?: string; contact?: string; notes?: string } = {}
+): Promise<OutOfOfficeDraft> => {
+  const response = await api.post<OutOfOfficeDraft>(`/calendar/${year}/blocks/${blockId}/out-of-office`, input);
+  return response.data;
+};
+
+// Comments on vacation days and blocks
+export const getComments = async (year: number, date?: string): Promise<Comment[]> => {
+  const response = await api.get<Comment[]>(`/comments/${year}`, { params: date ? { date } : undefined });
+  return response.data;
+};
+
+export const addComment = async (year: number, comment: CommentInput): Promise<Comment> => {
+  const response = await api.post<Comment>(`/comments/${year}`, comment);
+  return response.data;
+};
+
+export const updateComment = async (year: number, id: number, body: string): Promise<Comment> => {
+  const response = await api.put<Comment>(`/comments/${year}/${id}`, { body });
+  return response.data;
+};
+
+export const deleteComment = async (year: number, id: number): Promise<void> => {
+  await api.delete(`/comments/${year}/${id}`);
+};
+
+// Booking rules and the vacation days they propose
+export const getBookingRules = async (): Promise<BookingRule[]> => {
+  const response = await api.get<BookingRule[]>('/policies');
+  return response.data;
+};
+
+export const updateBookingRules = async (rules: BookingRule[]): Promise<BookingRule[]> => {
+  const response = await api.put<BookingRule[]>('/policies', rules);
+  return response.data;
+};
+
+export const getBookingProposals = async (year: number): Promise<BookingProposal[]> => {
+  const response = await api.get<BookingProposal[]>(`/policies/${year}/proposals`);
+  return response.data;
+};
+
+export const evaluateBookingPolicies = async (year: number): Promise<BookingProposal[]> => {
+  const response = await api.post<BookingProposal[]>(`/policies/${year}/evaluate`);
+  return response.data;
+};
+
+export const applyBookingProposal = async (year: number, id: number): Promise<BookingProposal> => {
+  const response = await api.post<BookingProposal>(`/policies/${year}/proposals/${id}/apply`);
+  return response.data;
+};
+
+export const dismissBookingProposal = async (year: number, id: number): Promise<BookingProposal> => {
+  const response = await api.post<BookingProposal>(`/policies/${year}/proposals/${id}/dismiss`);
+  return response.data;
+};
+
+// Trips
+export const getTrips = async (year: number): Promise<Trip[]> => {
+  const response = await api.get<Trip[]>(`/trips/${year}`);
+  return response.data;
+};
+
+export const createTrip = async (year: number, trip: TripInput): Promise<Trip> => {
+  const response = await api.post<Trip>(`/trips/${year}`, trip);
+  return response.data;
+};
+
+export const updateTrip = async (year: number, id: number, trip: TripInput): Promise<Trip> => {
+  const response = await api.put<Trip>(`/trips/${year}/${id}`, trip);
+  return response.data;
+};
+
+export const deleteTrip = async (year: number, id: number): Promise<void> => {
+  await api.delete(`/trips/${year}/${id}`);
+};
+
+export const getTripBudget = async (year: number): Promise<TripBudgetSummary> => {
+  const response = await api.get<TripBudgetSummary>(`/trips/${year}/budget`);
+  return response.data;
+};
+
+export const getTripExpenses = async (year: number, tripId: number): Promise<TripExpense[]> => {
+  const response = await api.get<TripExpense[]>(`/trips/${year}/${tripId}/expenses`);
+  return response.data;
+};
+
+export const addTripExpense = async (year: number, tripId: number, expense: TripExpenseInput): Promise<TripExpense> => {
+  const response = await api.post<TripExpense>(`/trips/${year}/${tripId}/expenses`, expense);
+  return response.data;
+};
+
+export const updateTripExpense = async (
+  year: number,
+  tripId: number,
+  id: number,
+  expense: TripExpenseInput
+): Promise<TripExpense> => {
+  const response = await api.put<TripExpense>(`/trips/${year}/${tripId}/expenses/${id}`, expense);
+  return response.data;
+};
+
+export const deleteTripExpense = async (year: number, tripId: number, id: number): Promise<void> => {
+  await api.delete(`/trips/${year}/${tripId}/expenses/${id}`);
+};
+
+// Share links
+export interface ShareLink {
+  id: number;
+  token: string;
+  year: number;
+  label?: string;
+  expires_at?: string;
+  created_at: string;
+}
+
+export const getShareLinks = async (): Promise<ShareLink[]> => {
+  const response = await api.get<ShareLink[]>('/shares');
+  return response.data;
+};
+
+export const createShareLink = async (
+  year: number,
+  label?: string,
+  expiresInDays?: number
+): Promise<ShareLink> => {
+  const response = await api.post<ShareLink>('/shares', {
+    year,
+    label,
+    expires_in_days: expiresInDays,
+  });
+  return response.data;
+};
+
+export const deleteShareLink = async (id: number): Promise<void> => {
+  await api.delete(`/shares/${id}`);
+};
+
+export const shareLinkUrl = (link: ShareLink): string =>
+  `${window.location.origin}/share/${link.token}`;
+
+// Access control
+export type Role = 'admin' | 'manager' | 'member' | 'viewer';
+
+export interface AccessInfo {
+  access_control: boolean;
+  name?: string;
+  role: Role;
+}
+
+export interface AccessToken {
+  id: number;
+  name: string;
+  role: Role;
+  token?: string; // Only returned when created
+  expires_at?: string; // Set on single sign-on sessions
+  created_at: string;
+}
+
+export const getAccess = async (): Promise<AccessInfo> => {
+  const response = await api.get<AccessInfo>('/access');
+  return response.data;
+};
+
+export const getAccessTokens = async (): Promise<AccessToken[]> => {
+  const response = await api.get<AccessToken[]>('/access/tokens');
+  return response.data;
+};
+
+export const createAccessToken = async (name: string, role: Role): Promise<AccessToken> => {
+  const response = await api.post<AccessToken>('/access/tokens', { name, role });
+  return response.data;
+};
+
+export const deleteAccessToken = async (id: number): Promise<void> => {
+  await api.delete(`/access/tokens/${id}`);
+};
+
+export interface AuthEvent {
+  id: number;
+  event: 'failure' | 'lockout' | 'unlock' | 'token_created' | 'token_deleted' | 'sso_login' | 'sso_denied';
+  client?: string;
+  token_name?: string;
+  detail?: string;
+  created_at: string;
+}
+
+export interface Lockout {
+  client: string;
+  until: string;
+}
+
+export const getAuditLog = async (limit = 100): Promise<AuthEvent[]> => {
+  const response = await api.get<AuthEvent[]>('/access/audit', { params: { limit } });
+  return response.data;
+};
+
+export const getLockouts = async (): Promise<Lockout[]> => {
+  const response = await api.get<Lockout[]>('/access/lockouts');
+  return response.data;
+};
+
+export const deleteLockout = async (client: string): Promise<void> => {
+  await api.delete(`/access/lockouts/${encodeURIComponent(client)}`);
+};
+
+// Calendar images, for printing or embedding in wikis
+export const calendarImageUrl = (year: number, format: 'png' | 'svg' = 'png', scale = 1): string =>
+  `${window.location.origin}/api/calendar/${year}/render.${format}${format === 'png' && scale > 1 ? `?scale=${scale}` : ''}`;
@@ -6,8 +6,52 @@ export interface YearConfig {
   optimization_strategy: string;
   work_week: string[];
   optimizer_notes: string;
+  work_week_changes?: WorkWeekChange[];
+  accounting_mode?: 'days' | 'hours';
+  vacation_hours?: number;
+  working_hours?: Record<string, number>;
+  optional_holidays?: string[];
+  scoring_weights?: ScoringWeights;
+  blackout_periods?: BlackoutPeriod[];
+  avoid_periods?: AvoidPeriod[];
   created_at?: string;
   updated_at?: string;
+  version?: number;
+}
+
+// Weights the custom strategy ranks vacation blocks by, all zero uses the
+// balanced weighting
+export interface ScoringWeights {
+  efficiency: number;
+  length: number;
+  distribution: number;
+  month_preference: number;
+  preferred_months: number[];
+}
+
+// A date range closed to vacation, such as a release or a busy season
+export interface BlackoutPeriod {
+  start_date: string;
+  end_date: string;
+  reason?: string;
+  source?: 'oncall'; // Imported from the on-call schedule
+}
+
+// A date range vacation should stay clear of, such as a release week. The
+// optimizer weighs its days down but it can still be booked
+export interface AvoidPeriod {
+  start_date: string;
+  end_date: string;
+  reason?: string;
+  weight: number; // 1 to 5, 3 when not given
+  source?: 'milestone'; // Imported from project milestones
+}
+
+export interface WorkWeekChange {
+  id: number;
+  year: number;
+  effective_from: string;
+  work_week: string[];
 }
 
 export interface VacationDay {
@@ -19,6 +63,16 @@ export interface VacationDay {
   created_at?: string;
 }
 
+// Date whose vacation status can't change until it is unlocked
+export interface LockedDate {
+  id: number;
+  year: number;
+  date: string;
+  reason?: string;
+  locked_by?: string;
+  created_at: string;
+}
+
 export interface OptimalVacation {
   id: number;
   year: number;
@@ -34,6 +88,130 @@ export interface Holiday {
   date: string;
   name: string;
   type: string;
+  observed_for?: string;
+  provisional?: boolean; // Calculated locally, not yet confirmed by the holiday APIs
+  source?: 'nager' | 'calendarific' | 'fallback' | 'custom' | 'override';
+  fetched_at?: string; // When it was loaded from its API
+}
+
+export interface HolidayChange {
+  date: string;
+  name: string;
+  type: string;
+  location: string;
+  observed_for?: string;
+  deleted: boolean;
+  changed_at: string;
+}
+
+export interface HolidaySync {
+  year: number;
+  since: string;
+  synced_at: string;
+  holidays: HolidayChange[];
+  tombstones: HolidayChange[];
+}
+
+export interface BulkItemResult {
+  date: string;
+  action: 'add' | 'remove' | 'store';
+  status:
+    | 'applied'
+    | 'unchanged'
+    | 'created'
+    | 'skipped_holiday'
+    | 'skipped_duplicate'
+    | 'over_budget'
+    | 'failed'
+    | 'rolled_back';
+  error?: string;
+}
+
+export type VacationCopyMode = 'same_weekday' | 'same_date';
+
+export interface CopiedVacation {
+  from: string;
+  to?: string;
+  status:
+    | 'created'
+    | 'skipped_holiday'
+    | 'skipped_non_work_day'
+    | 'skipped_blackout'
+    | 'skipped_duplicate'
+    | 'skipped_locked'
+    | 'outside_year'
+    | 'over_budget';
+  holiday?: string;
+}
+
+export interface SeniorityRule {
+  id?: number;
+  years_of_service: number;
+  extra_days: number;
+}
+
+export interface Entitlement {
+  year: number;
+  employment_start_date?: string;
+  years_of_service: number;
+  base_days: number;
+  extra_days: number;
+  vacation_days: number;
+}
+
+export interface WorkedHoliday {
+  id: number;
+  year: number;
+  date: string;
+  name: string;
+  note?: string;
+}
+
+export interface HolidayOverride {
+  id: number;
+  year: number;
+  date: string;
+  action: 'correct' | 'suppress';
+  new_date?: string;
+  name?: string;
+  note?: string;
+  created_at?: string;
+}
+
+export interface OptionalHoliday {
+  key: string;
+  date: string;
+  name: string;
+  enabled: boolean;
+}
+
+export interface CompDay {
+  id: number;
+  year: number;
+  date: string;
+  kind: 'credit' | 'spend';
+  note?: string;
+}
+
+export interface BalanceAdjustment {
+  id: number;
+  year: number;
+  date: string;
+  days: number; // Positive grants days, negative deducts them
+  kind: 'bonus' | 'purchased' | 'correction';
+  reason: string;
+}
+
+export interface CompLedger {
+  year: number;
+  entries: CompDay[];
+  adjustments: BalanceAdjustment[];
+  worked_holidays: number;
+  earned: number;
+  spent: number;
+  adjusted: number;
+  used_for_vacation: number;
+  balance: number;
 }
 
 export interface CalendarDay {
@@ -45,7 +223,24 @@ export interface CalendarDay {
   is_vacation: boolean;
   is_manual: boolean;
   is_optimal: boolean;
+  is_comp_day: boolean;
+  is_locked?: boolean;
+  is_bridge_candidate?: boolean; // Lone work day between a holiday and another day off
   block_id?: number;
+  trip_id?: number;
+  family_off?: string[];
+  events?: string[]; // Names of the personal events on the day
+}
+
+// A stretch of 3 or more days off around a holiday, taking at most one
+// vacation day
+export interface LongWeekend {
+  start_date: string;
+  end_date: string;
+  total_days: number;
+  vacation_days: number;
+  vacation_date?: string;
+  holidays: string[];
 }
 
 export interface VacationBlock {
@@ -56,15 +251,86 @@ export interface VacationBlock {
   dates: string[];
   holidays: string[];
   weekends: string[];
+  efficiency: number;
+  source?: 'manual' | 'optimized' | 'mixed';
+  trip_id?: number;
+  label?: string; // Name given to the block, such as "Summer trip"
+  color?: string; // #rrggbb
+  flight_price?: FlightQuote;
+  rationales?: Record<string, string>; // Why the AI optimizer picked each vacation day, by date
+}
+
+export interface DayOff {
+  date: string;
+  kind: 'holiday' | 'vacation' | 'comp_day' | 'weekend';
+  name?: string;
+  days_until: number;
+}
+
+export interface NextBreak {
+  today: string;
+  next_day_off: DayOff | null;
+  next_vacation: VacationBlock | null;
+  days_until_vacation: number;
+}
+
+export interface DayStatus extends CalendarDay {
+  kind: DayOff['kind'] | 'work_day';
+  is_day_off: boolean;
+  is_work_day: boolean; // In the work week, holidays and vacations aside
+  in_blackout?: boolean;
+  holiday?: Holiday;
+  block?: VacationBlock;
+}
+
+export interface FlightQuote {
+  origin: string;
+  destination: string;
+  depart_date: string;
+  return_date: string;
+  price: number;
+  currency: string;
+  link?: string;
+}
+
+export interface FlightPriceResponse {
+  year: number;
+  provider: string;
+  origin: string;
+  destination: string;
+  blocks: VacationBlock[];
 }
 
 export interface CalendarSummary {
   total_vacation_days: number;
+  compensation_days: number;
+  comp_days_used: number;
+  comp_days_remaining: number;
+  total_vacation_hours: number;
+  used_vacation_hours: number;
+  remaining_vacation_hours: number;
   used_vacation_days: number;
   remaining_vacation_days: number;
   total_holidays: number;
   longest_vacation_block: number;
   total_days_off: number;
+  efficiency: number;
+  quarter_distribution: QuarterSummary[];
+  streak_distribution: StreakCount[]; // Streaks of days off by length, shortest first
+  schedulable_days: number; // Vacation days that can realistically still be booked this year
+  unused_days_at_risk: boolean;
+}
+
+export interface QuarterSummary {
+  quarter: number;
+  vacation_days: number;
+  days_off: number;
+  efficiency: number;
+}
+
+export interface StreakCount {
+  days: number;
+  count: number;
 }
 
 export interface CalendarResponse {
@@ -75,14 +341,166 @@ export interface CalendarResponse {
   vacation_blocks: VacationBlock[];
   manual_vacations: VacationDay[];
   optimal_vacations: OptimalVacation[];
+  trips: Trip[];
+  family: FamilyMember[];
+  personal_events: PersonalEvent[];
   summary: CalendarSummary;
 }
 
+// One year of a multi-year calendar request
+export interface YearCalendar {
+  year: number;
+  config: YearConfig;
+  summary: CalendarSummary;
+  vacation_blocks: VacationBlock[];
+  days?: CalendarDay[];
+}
+
+export interface FamilyClosure {
+  id?: number; // Absent for school breaks
+  member_id: number;
+  name: string;
+  start_date: string;
+  end_date: string;
+  source: 'custom' | 'school';
+}
+
+export interface FamilyMember {
+  id: number;
+  name: string;
+  school_district?: string;
+  plan_around: boolean;
+  closures: FamilyClosure[];
+  created_at: string;
+}
+
+// An important date that is not a day off. A positive weight draws vacation
+// to it and a negative one keeps vacation away, from -5 to 5.
+export interface PersonalEvent {
+  id: number;
+  year: number;
+  date: string;
+  name: string;
+  notes: string;
+  weight: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export type PersonalEventInput = Pick<PersonalEvent, 'date' | 'name' | 'notes' | 'weight'>;
+
+export interface FamilyMemberInput {
+  name: string;
+  school_district?: string;
+  plan_around?: boolean;
+}
+
+// Suggested block the user turned down; the optimizer leaves its dates out
+export interface DeclinedBlock {
+  id: number;
+  year: number;
+  start_date: string;
+  end_date: string;
+  dates: string[];
+  created_at: string;
+}
+
+export interface Comment {
+  id: number;
+  year: number;
+  date: string; // The day, or the first day of the block
+  target: 'day' | 'block';
+  parent_id?: number;
+  author: string;
+  body: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CommentInput {
+  date?: string;
+  target?: 'day' | 'block';
+  parent_id?: number;
+  author?: string;
+  body: string;
+}
+
+export type BookingRuleKind = 'bridge' | 'max_consecutive_weeks';
+
+export interface BookingRule {
+  id?: number;
+  name: string;
+  kind: BookingRuleKind;
+  max_gap?: number; // bridge: 1 to 3
+  holiday_weekdays?: string[]; // bridge: e.g. ['thursday'], any when empty
+  max_weeks?: number; // max_consecutive_weeks
+  auto_apply: boolean;
+  enabled?: boolean;
+}
+
+export interface BookingProposal {
+  id: number;
+  year: number;
+  date: string;
+  rule_id: number;
+  rule_name: string;
+  reason: string;
+  status: 'pending' | 'applied' | 'dismissed';
+  created_at: string;
+}
+
+export interface Trip {
+  id: number;
+  year: number;
+  name: string;
+  destination: string;
+  start_date: string;
+  end_date: string;
+  notes: string;
+  budget: number;
+  spent: number;
+  vacation_dates: string[];
+  created_at: string;
+  updated_at: string;
+}
+
+export type TripInput = Pick<Trip, 'name' | 'destination' | 'start_date' | 'end_date' | 'notes' | 'budget'>;
+
+export interface TripExpense {
+  id: number;
+  trip_id: number;
+  date?: string;
+  description: string;
+  category?: string;
+  amount: number;
+  created_at: string;
+}
+
+export type TripExpenseInput = Pick<TripExpense, 'date' | 'description' | 'category' | 'amount'>;
+
+export interface TripBudget {
+  trip_id: number;
+  name: string;
+  budget: number;
+  spent: number;
+  remaining: number;
+}
+
+export interface TripBudgetSummary {
+  year: number;
+  trips: TripBudget[];
+  total_budget: number;
+  total_spent: number;
+  total_remaining: number;
+  by_category: Record<string, number>;
+}
+
 export interface ChatMessage {
   id: number;
   year: number;
   role: 'user' | 'assistant';
   content: string;
+  model?: string;
   created_at: string;
 }
 
@@ -97,6 +515,19 @@ export interface Settings {
   calendarific_api_key: string;
 }
 
+export interface SettingDefinition {
+  key: string;
+  type: 'string' | 'integer' | 'boolean' | 'enum' | 'date' | 'month_day' | 'work_week' | 'timezone' | 'airport' | 'cron' | 'models' | 'roles';
+  group: string;
+  description: string;
+  default: string;
+  options?: string[];
+  min?: number;
+  max?: number;
+  secret?: boolean;
+  read_only?: boolean;
+}
+
 export interface OptimizationStrategy {
   id: string;
   name: string;
@@ -120,3 +551,98 @@ export const ALL_WEEKDAYS = [
   'saturday',
   'sunday',
 ];
+
+export interface MonthSummary {
+  month: number;
+  vacation_days: number;
+  holidays: number;
+  days_off: number;
+  longest_streak: number;
+  remaining_budget: number;
+}
+
+export interface CalendarStats {
+  year: number;
+  months: MonthSummary[];
+  quarters: QuarterSummary[];
+  summary: CalendarSummary;
+}
+
+export interface YearHistory {
+  year: number;
+  vacation_days: number;
+  days_taken: number;
+  blocks: number;
+  average_block_length: number;
+  efficiency: number;
+  unused_days: number;
+  lost: boolean;
+}
+
+export interface MonthCount {
+  month: number;
+  vacation_days: number;
+}
+
+export interface HistoricalStats {
+  years: YearHistory[];
+  total_days_taken: number;
+  average_block_length: number;
+  efficiency_trend: number;
+  most_common_months: MonthCount[];
+  unused_days_lost: number;
+}
+
+export interface LeaveImport {
+  year: number;
+  provider: string;
+  imported: string[];
+  existing: number;
+  skipped: number;
+}
+
+export interface OnCallImport {
+  year: number;
+  provider: string;
+  periods: BlackoutPeriod[];
+  conflicts: string[];
+}
+
+export interface OutOfOfficeDraft {
+  year: number;
+  start_date: string;
+  end_date: string;
+  return_date: string; // Day after the block
+  language: 'en' | 'pt-PT';
+  subject: string;
+  auto_reply: string;
+  handover: string;
+  model?: string;
+}
+
+export interface MilestoneImport {
+  year: number;
+  provider: string;
+  periods: AvoidPeriod[];
+}
+
+export interface Scenario {
+  id: number;
+  year: number;
+  name: string;
+  is_active: boolean;
+  manual_days: number;
+  optimal_days: number;
+  created_at: string;
+  updated_at: string;
+}
+
+// RFC 9457 problem details returned by failed requests
+export interface Problem {
+  type: string;
+  title: string;
+  status: number;
+  detail?: string;
+  instance?: string;
+  code: string;
+}
@@ -17,6 +17,10 @@ export default defineConfig({
         target: 'http://localhost:8080',
         changeOrigin: true,
       },
+      '/share/': {
+        target: 'http://localhost:8080',
+        changeOrigin: true,
+      },
     },
   },
 })